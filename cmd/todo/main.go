@@ -2,78 +2,204 @@ package main
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
+	"io"
 	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
+	"github.com/go-kit/kit/endpoint"
 	"github.com/go-kit/kit/log"
 	"github.com/go-kit/kit/log/level"
+	kitprometheus "github.com/go-kit/kit/metrics/prometheus"
 	kitgrpc "github.com/go-kit/kit/transport/grpc"
 	stdopentracing "github.com/opentracing/opentracing-go"
 	"github.com/openzipkin/zipkin-go"
 	zipkinhttp "github.com/openzipkin/zipkin-go/reporter/http"
+	stdprometheus "github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/health"
 	healthgrpc "google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/grpc/reflection"
 	"gorm.io/gorm"
 
 	"github.com/cage1016/gokit-todo/internal/app/todo/endpoints"
+	"github.com/cage1016/gokit-todo/internal/app/todo/model"
 	"github.com/cage1016/gokit-todo/internal/app/todo/postgres"
 	"github.com/cage1016/gokit-todo/internal/app/todo/service"
 	transportsgrpc "github.com/cage1016/gokit-todo/internal/app/todo/transports/grpc"
 	transportshttp "github.com/cage1016/gokit-todo/internal/app/todo/transports/http"
+	transportsjsonrpc "github.com/cage1016/gokit-todo/internal/app/todo/transports/jsonrpc"
+	"github.com/cage1016/gokit-todo/internal/pkg/authn"
+	"github.com/cage1016/gokit-todo/internal/pkg/authz"
+	"github.com/cage1016/gokit-todo/internal/pkg/instrumenting"
+	"github.com/cage1016/gokit-todo/internal/pkg/loglevel"
+	"github.com/cage1016/gokit-todo/internal/pkg/ratelimit"
+	"github.com/cage1016/gokit-todo/internal/pkg/tlsconfig"
 	pb "github.com/cage1016/gokit-todo/pb/todo"
 )
 
 const (
-	defZipkinV2URL   = ""
-	defServiceName   = "todo"
-	defLogLevel      = "error"
-	defServiceHost   = "localhost"
-	defHTTPPort      = "10120"
-	defGRPCPort      = "10121"
-	defDBHost        = "localhost"
-	defDBPort        = "5432"
-	defDBUser        = "postgres"
-	defDBPass        = "password"
-	defDBName        = "todo"
-	defDBSSLMode     = "disable"
-	defDBSSLCert     = ""
-	defDBSSLKey      = ""
-	defDBSSLRootCert = ""
-	defDBDriverName  = "postgres" // "postgres" or "cloudsqlpostgres"
-
-	envZipkinV2URL   = "QS_ZIPKIN_V2_URL"
-	envServiceName   = "QS_SERVICE_NAME"
-	envLogLevel      = "QS_LOG_LEVEL"
-	envServiceHost   = "QS_SERVICE_HOST"
-	envHTTPPort      = "QS_HTTP_PORT"
-	envGRPCPort      = "QS_GRPC_PORT"
-	envDBHost        = "QS_DB_HOST"
-	envDBPort        = "QS_DB_PORT"
-	envDBUser        = "QS_DB_USER"
-	envDBPass        = "QS_DB_PASS"
-	envDBName        = "QS_DB"
-	envDBSSLMode     = "QS_DB_SSL_MODE"
-	envDBSSLCert     = "QS_DB_SSL_CERT"
-	envDBSSLKey      = "QS_DB_SSL_KEY"
-	envDBSSLRootCert = "QS_DB_SSL_ROOT_CERT"
-	envDBDriverName  = "QS_DB_DRIVER_NAME"
+	defZipkinV2URL     = ""
+	defServiceName     = "todo"
+	defLogLevel        = loglevel.Info
+	defLogFormat       = "logfmt"
+	defServiceHost     = "localhost"
+	defHTTPPort        = "10120"
+	defGRPCPort        = "10121"
+	defAdminHost       = "127.0.0.1"
+	defAdminPort       = "10122"
+	defDBHost          = "localhost"
+	defDBPort          = "5432"
+	defDBUser          = "postgres"
+	defDBPass          = "password"
+	defDBName          = "todo"
+	defDBSSLMode       = "disable"
+	defDBSSLCert       = ""
+	defDBSSLKey        = ""
+	defDBSSLRootCert   = ""
+	defDBDriverName    = "postgres" // "postgres" or "cloudsqlpostgres"
+	defArchiveAfter    = "720h"
+	defArchiveInterval = "1h"
+	defEventsListen    = "true"
+	defAuthnDisabled   = "false"
+	defJWTSecret       = ""
+	defJWTPublicKey    = ""
+	defJWTJWKS         = ""
+	defJWTRequireExp   = "true"
+	defAuthzPolicy     = ""
+	defAuthzReload     = "10s"
+	defTLSCert         = ""
+	defTLSKey          = ""
+	defTLSCA           = ""
+	defTLSReload       = "10s"
+	defRateLimits      = ""
+	defAuthnFailures   = "0.2:10"
+	defTrustedProxies  = ""
+	defQuotas          = ""
+	defWSOrigins       = ""
+
+	envZipkinV2URL     = "QS_ZIPKIN_V2_URL"
+	envServiceName     = "QS_SERVICE_NAME"
+	envLogLevel        = "QS_LOG_LEVEL"
+	envLogFormat       = "QS_LOG_FORMAT"
+	envServiceHost     = "QS_SERVICE_HOST"
+	envHTTPPort        = "QS_HTTP_PORT"
+	envGRPCPort        = "QS_GRPC_PORT"
+	envAdminHost       = "QS_ADMIN_HOST"
+	envAdminPort       = "QS_ADMIN_PORT"
+	envDBHost          = "QS_DB_HOST"
+	envDBPort          = "QS_DB_PORT"
+	envDBUser          = "QS_DB_USER"
+	envDBPass          = "QS_DB_PASS"
+	envDBName          = "QS_DB"
+	envDBSSLMode       = "QS_DB_SSL_MODE"
+	envDBSSLCert       = "QS_DB_SSL_CERT"
+	envDBSSLKey        = "QS_DB_SSL_KEY"
+	envDBSSLRootCert   = "QS_DB_SSL_ROOT_CERT"
+	envDBDriverName    = "QS_DB_DRIVER_NAME"
+	envArchiveAfter    = "QS_ARCHIVE_AFTER"
+	envArchiveInterval = "QS_ARCHIVE_INTERVAL"
+	envEventsListen    = "QS_EVENTS_LISTEN"
+	envAuthnDisabled   = "QS_AUTHN_DISABLED"
+	envJWTSecret       = "QS_JWT_SECRET"
+	envJWTPublicKey    = "QS_JWT_PUBLIC_KEY"
+	envJWTJWKS         = "QS_JWT_JWKS"
+	envJWTRequireExp   = "QS_JWT_REQUIRE_EXP"
+	envAuthzPolicy     = "QS_AUTHZ_POLICY"
+	envAuthzReload     = "QS_AUTHZ_RELOAD_INTERVAL"
+	envTLSCert         = "QS_TLS_CERT"
+	envTLSKey          = "QS_TLS_KEY"
+	envTLSCA           = "QS_TLS_CA"
+	envTLSReload       = "QS_TLS_RELOAD_INTERVAL"
+	envRateLimits      = "QS_RATE_LIMITS"
+	envAuthnFailures   = "QS_AUTHN_FAILURE_LIMIT"
+	envTrustedProxies  = "QS_TRUSTED_PROXIES"
+	envQuotas          = "QS_QUOTAS"
+	envWSOrigins       = "QS_WS_ORIGINS"
 )
 
 type config struct {
 	dbConfig    postgres.Config
 	serviceName string
+	// logLevel is the level of the logs at startup: debug, info, warn or
+	// error. It can be changed at runtime on the admin port.
 	logLevel    string
 	serviceHost string
 	httpPort    string
 	grpcPort    string
+	// adminHost and adminPort serve the metrics and the log level apart
+	// from the API, without authentication. The host is the loopback by
+	// default, so that only the monitoring on the same host or pod reaches
+	// them, the admin server is disabled without a port.
+	adminHost   string
+	adminPort   string
 	zipkinV2URL string
+	// archiveAfter is how long completed todos are kept before being
+	// archived, archiving is disabled when it is zero.
+	archiveAfter    time.Duration
+	archiveInterval time.Duration
+	// eventsListen feeds the watchers with the changes notified by the
+	// database, made by every replica, instead of the local ones only. The
+	// database only notifies the changes while it is set.
+	eventsListen bool
+	// authnDisabled serves the requests without authenticating them, it has
+	// to be set when there are no jwtKeys.
+	authnDisabled bool
+	// jwtKeys verify the tokens of the requests, which need an expiry unless
+	// jwtRequireExp is unset.
+	jwtKeys       *authn.Keys
+	jwtRequireExp bool
+	// authzPolicy is the policy file authorizing the requests, which is
+	// reloaded every authzReload, authorization is disabled without it and
+	// the API keys cannot be managed.
+	authzPolicy string
+	authzReload time.Duration
+	// tlsConfig serves both listeners over TLS, and mutual TLS with a CA,
+	// the files are reloaded every tlsReload. TLS is disabled without a
+	// certificate.
+	tlsConfig tlsconfig.Config
+	tlsReload time.Duration
+	// rateLimits limit the requests of each caller by endpoint, such as
+	// "*=10:20,add=1:5", rate limiting is disabled without them.
+	rateLimits ratelimit.Limits
+	// authnFailures limit the requests failing authentication of each
+	// address, such as "0.2:10", and so the guessing of credentials. The
+	// address is rejected altogether past them. They are disabled by
+	// "off".
+	authnFailures *ratelimit.Limit
+	// trustedProxies are the reverse proxies, such as "10.0.0.0/8", whose
+	// X-Forwarded-For headers tell the address of the clients to the rate
+	// limits. The address of the peer is used without them.
+	trustedProxies ratelimit.Proxies
+	// quotas limit the todos of each tenant, quotas are disabled without
+	// them.
+	quotas *service.Quotas
+	// wsOrigins are the origins of the pages allowed to use the WebSocket
+	// along with the same-origin ones, such as "https://todo.example.com",
+	// "*" allowing every page.
+	wsOrigins []string
+}
+
+// newLogger returns the logger writing to w in format, json or logfmt. It
+// returns a logfmt one along with the error if format is unknown.
+func newLogger(format string, w io.Writer) (log.Logger, error) {
+	switch format {
+	case "json":
+		return log.NewJSONLogger(log.NewSyncWriter(w)), nil
+	case "logfmt":
+		return log.NewLogfmtLogger(log.NewSyncWriter(w)), nil
+	default:
+		return log.NewLogfmtLogger(log.NewSyncWriter(w)), fmt.Errorf("%q: the format has to be json or logfmt", format)
+	}
 }
 
 // Env reads specified environment variable. If no value has been found,
@@ -88,11 +214,21 @@ func env(key string, fallback string) string {
 func main() {
 	var logger log.Logger
 	{
-		logger = log.NewLogfmtLogger(os.Stderr)
+		var err error
+		logger, err = newLogger(env(envLogFormat, defLogFormat), os.Stderr)
 		logger = log.With(logger, "ts", log.DefaultTimestampUTC)
+		if err != nil {
+			level.Error(logger).Log("env", envLogFormat, "err", err)
+			os.Exit(1)
+		}
 	}
 	cfg := loadConfig(logger)
-	logger = level.NewFilter(logger, level.AllowInfo())
+	logLevel, err := loglevel.New(logger, cfg.logLevel)
+	if err != nil {
+		level.Error(logger).Log("env", envLogLevel, "err", err)
+		os.Exit(1)
+	}
+	logger = logLevel
 	logger = log.With(logger, "caller", log.DefaultCaller)
 	logger = log.With(logger, "service", cfg.serviceName)
 	level.Info(logger).Log("version", service.Version, "commitHash", service.CommitHash, "buildTimeStamp", service.BuildTimeStamp)
@@ -100,21 +236,104 @@ func main() {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
+	listen := cfg.eventsListen && cfg.dbConfig.DriverName == "postgres"
+	if cfg.eventsListen && !listen {
+		level.Warn(logger).Log("listener", "disabled", "msg", "LISTEN is unsupported by the "+cfg.dbConfig.DriverName+" driver, watchers only receive the changes of this replica")
+	}
+	cfg.dbConfig.Events = listen
 	db := connectToDB(cfg.dbConfig, logger)
 	//defer db.Close()
 
 	tracer := initOpentracing()
 	zipkinTracer := initZipkin(cfg.serviceName, cfg.httpPort, cfg.zipkinV2URL, logger)
-	service := NewServer(db, logger)
-	endpoints := endpoints.New(service, logger, tracer, zipkinTracer)
+	repo := postgres.New(db, logger)
+	bus := service.NewEventBus(service.DefaultEventBacklog)
+	busOpt := service.WithEventBus(bus)
+	if listen {
+		busOpt = service.WithSharedEventBus(bus)
+	}
+	apiKeys := service.NewAPIKeyAuthenticator(repo, log.With(logger, "component", "apikeys"))
+	svcOpts := []service.Option{busOpt, initServiceMetrics()}
+	var policy *authz.FileEngine
+	if cfg.authzPolicy == "" {
+		level.Warn(logger).Log("authz", "disabled", "msg", "no policy configured, requests are not authorized and API keys cannot be managed")
+	} else {
+		var err error
+		if policy, err = authz.NewFileEngine(cfg.authzPolicy, log.With(logger, "component", "authz")); err != nil {
+			level.Error(logger).Log("env", envAuthzPolicy, "err", err)
+			os.Exit(1)
+		}
+		svcOpts = append(svcOpts, service.WithAuthorizer(policy))
+	}
+	if cfg.quotas == nil {
+		level.Info(logger).Log("quotas", "disabled")
+	} else {
+		svcOpts = append(svcOpts, service.WithQuotas(cfg.quotas))
+	}
+	service := NewServer(repo, logger, svcOpts...)
+	var epOpts []endpoints.Option
+	if len(cfg.rateLimits) == 0 {
+		level.Info(logger).Log("ratelimit", "disabled")
+	} else {
+		epOpts = append(epOpts, endpoints.WithRateLimit(ratelimit.NewLimiter(cfg.rateLimits, ratelimit.WithTrustedProxies(cfg.trustedProxies)).Middleware))
+	}
+	eps := endpoints.New(service, logger, tracer, zipkinTracer, epOpts...)
+	if policy != nil {
+		eps = endpoints.AuthzMiddleware(authz.NewAuthorizer(policy), eps)
+	}
+	if cfg.authnDisabled {
+		level.Warn(logger).Log("authn", "disabled", "msg", envAuthnDisabled+" is set, requests are not authenticated, API keys included")
+	} else {
+		authnOpts := []authn.ParserOption{authn.WithAPIKeys(apiKeys)}
+		if !cfg.jwtRequireExp {
+			level.Warn(logger).Log("authn", "no expiry", "msg", "tokens without an expiry are accepted")
+			authnOpts = append(authnOpts, authn.WithoutExpiry())
+		}
+		parser := authn.NewParser(cfg.jwtKeys, authnOpts...)
+		if cfg.authnFailures == nil {
+			level.Warn(logger).Log("authn", "failures not limited", "msg", "credentials may be guessed without limit")
+		} else {
+			failures := ratelimit.NewLimiter(ratelimit.Limits{ratelimit.DefaultMethod: *cfg.authnFailures}, ratelimit.WithTrustedProxies(cfg.trustedProxies))
+			parser = endpoint.Chain(failures.FailureMiddleware(authn.Failed), parser)
+		}
+		eps = endpoints.AuthnMiddleware(parser, eps)
+	}
+	eps = endpoints.InstrumentingMiddleware(initEndpointMetrics().Middleware, eps)
+
+	var (
+		certs                        *tlsconfig.Reloader
+		httpTLSConfig, grpcTLSConfig *tls.Config
+	)
+	if !cfg.tlsConfig.Enabled() {
+		level.Warn(logger).Log("tls", "disabled", "msg", "no certificate configured, traffic is plaintext")
+	} else {
+		var err error
+		if certs, err = tlsconfig.NewReloader(cfg.tlsConfig, log.With(logger, "component", "tls")); err != nil {
+			level.Error(logger).Log("env", "QS_TLS_*", "err", err)
+			os.Exit(1)
+		}
+		httpTLSConfig, grpcTLSConfig = certs.ServerConfig("h2", "http/1.1"), certs.ServerConfig("h2")
+	}
 
 	hs := health.NewServer()
 	hs.SetServingStatus(cfg.serviceName, healthgrpc.HealthCheckResponse_SERVING)
 
 	wg := &sync.WaitGroup{}
 
-	go startHTTPServer(ctx, wg, endpoints, tracer, zipkinTracer, cfg.httpPort, logger)
-	go startGRPCServer(ctx, wg, endpoints, tracer, zipkinTracer, cfg.grpcPort, hs, logger)
+	go startHTTPServer(ctx, wg, eps, tracer, zipkinTracer, cfg.httpPort, httpTLSConfig, cfg.wsOrigins, logger)
+	go startGRPCServer(ctx, wg, eps, tracer, zipkinTracer, cfg.grpcPort, grpcTLSConfig, hs, logger)
+	go startAdminServer(ctx, wg, cfg.adminHost, cfg.adminPort, logLevel, logger)
+	go startPoolStats(ctx, wg, db, initPoolMetrics(), logger)
+	go startArchiver(ctx, wg, repo, cfg.archiveAfter, cfg.archiveInterval, logger)
+	if policy != nil {
+		go startPolicyReloader(ctx, wg, policy, cfg.authzReload, logger)
+	}
+	if certs != nil {
+		go startCertReloader(ctx, wg, certs, cfg.tlsReload, logger)
+	}
+	if listen {
+		go startEventListener(ctx, wg, cfg.dbConfig, bus, repo, logger)
+	}
 
 	c := make(chan os.Signal, 1)
 	signal.Notify(c, os.Interrupt)
@@ -132,6 +351,8 @@ func loadConfig(logger log.Logger) (cfg config) {
 	cfg.serviceHost = env(envServiceHost, defServiceHost)
 	cfg.httpPort = env(envHTTPPort, defHTTPPort)
 	cfg.grpcPort = env(envGRPCPort, defGRPCPort)
+	cfg.adminHost = env(envAdminHost, defAdminHost)
+	cfg.adminPort = env(envAdminPort, defAdminPort)
 	cfg.zipkinV2URL = env(envZipkinV2URL, defZipkinV2URL)
 	cfg.dbConfig = postgres.Config{
 		Host:        env(envDBHost, defDBHost),
@@ -145,9 +366,83 @@ func loadConfig(logger log.Logger) (cfg config) {
 		SSLRootCert: env(envDBSSLRootCert, defDBSSLRootCert),
 		DriverName:  env(envDBDriverName, defDBDriverName),
 	}
+	cfg.archiveAfter = parseDuration(envArchiveAfter, defArchiveAfter, logger)
+	cfg.archiveInterval = parseDuration(envArchiveInterval, defArchiveInterval, logger)
+	cfg.eventsListen = parseBool(envEventsListen, defEventsListen, logger)
+	keys, err := authn.LoadKeys(env(envJWTSecret, defJWTSecret), env(envJWTPublicKey, defJWTPublicKey), env(envJWTJWKS, defJWTJWKS))
+	if err != nil {
+		level.Error(logger).Log("env", "QS_JWT_*", "err", err)
+		os.Exit(1)
+	}
+	cfg.authnDisabled = parseBool(envAuthnDisabled, defAuthnDisabled, logger)
+	if keys.Empty() && !cfg.authnDisabled {
+		level.Error(logger).Log("env", "QS_JWT_*", "err", "no JWT keys configured, set "+envAuthnDisabled+"=true to serve unauthenticated requests")
+		os.Exit(1)
+	}
+	cfg.jwtKeys = keys
+	cfg.jwtRequireExp = parseBool(envJWTRequireExp, defJWTRequireExp, logger)
+	cfg.authzPolicy = env(envAuthzPolicy, defAuthzPolicy)
+	cfg.authzReload = parseDuration(envAuthzReload, defAuthzReload, logger)
+	cfg.tlsConfig = tlsconfig.Config{
+		CertFile: env(envTLSCert, defTLSCert),
+		KeyFile:  env(envTLSKey, defTLSKey),
+		CAFile:   env(envTLSCA, defTLSCA),
+	}
+	cfg.tlsReload = parseDuration(envTLSReload, defTLSReload, logger)
+	limits, err := ratelimit.ParseLimits(env(envRateLimits, defRateLimits))
+	if err != nil {
+		level.Error(logger).Log("env", envRateLimits, "err", err)
+		os.Exit(1)
+	}
+	cfg.rateLimits = limits
+	if cfg.trustedProxies, err = ratelimit.ParseProxies(env(envTrustedProxies, defTrustedProxies)); err != nil {
+		level.Error(logger).Log("env", envTrustedProxies, "err", err)
+		os.Exit(1)
+	}
+	if failures := env(envAuthnFailures, defAuthnFailures); failures != "off" {
+		limit, err := ratelimit.ParseLimit(failures)
+		if err != nil {
+			level.Error(logger).Log("env", envAuthnFailures, "err", err)
+			os.Exit(1)
+		}
+		cfg.authnFailures = &limit
+	}
+	if file := env(envQuotas, defQuotas); file != "" {
+		if cfg.quotas, err = service.LoadQuotas(file); err != nil {
+			level.Error(logger).Log("env", envQuotas, "err", err)
+			os.Exit(1)
+		}
+	}
+	for _, o := range strings.Split(env(envWSOrigins, defWSOrigins), ",") {
+		if o = strings.TrimSpace(o); o != "" {
+			cfg.wsOrigins = append(cfg.wsOrigins, o)
+		}
+	}
 	return cfg
 }
 
+// parseDuration reads the specified environment variable as a duration,
+// exiting if it is malformed.
+func parseDuration(key, fallback string, logger log.Logger) time.Duration {
+	d, err := time.ParseDuration(env(key, fallback))
+	if err != nil {
+		level.Error(logger).Log("env", key, "err", err)
+		os.Exit(1)
+	}
+	return d
+}
+
+// parseBool reads the specified environment variable as a boolean, exiting
+// if it is malformed.
+func parseBool(key, fallback string, logger log.Logger) bool {
+	b, err := strconv.ParseBool(env(key, fallback))
+	if err != nil {
+		level.Error(logger).Log("env", key, "err", err)
+		os.Exit(1)
+	}
+	return b
+}
+
 func connectToDB(dbConfig postgres.Config, logger log.Logger) *gorm.DB {
 	db, err := postgres.Connect(dbConfig)
 	if err != nil {
@@ -157,12 +452,98 @@ func connectToDB(dbConfig postgres.Config, logger log.Logger) *gorm.DB {
 	return db
 }
 
-func NewServer(db *gorm.DB, logger log.Logger) service.TodoService {
-	repo := postgres.New(db, logger)
-	service := service.New(repo, logger)
+func NewServer(repo model.TodoRepository, logger log.Logger, opts ...service.Option) service.TodoService {
+	service := service.New(repo, logger, opts...)
 	return service
 }
 
+// metricsNamespace prefixes the names of the metrics, it cannot be the
+// service name which may not be a valid metric name.
+const metricsNamespace = "todo"
+
+// poolStatsInterval is how often the stats of the connection pool of the
+// database are recorded.
+const poolStatsInterval = 10 * time.Second
+
+func initServiceMetrics() service.Option {
+	fieldKeys := []string{"method", "error"}
+	return service.WithInstrumenting(
+		kitprometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Subsystem: "service",
+			Name:      "requests_total",
+			Help:      "Number of calls of the service methods.",
+		}, fieldKeys),
+		kitprometheus.NewHistogramFrom(stdprometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Subsystem: "service",
+			Name:      "request_duration_seconds",
+			Help:      "Duration of the calls of the service methods in seconds.",
+			Buckets:   stdprometheus.DefBuckets,
+		}, fieldKeys),
+	)
+}
+
+func initEndpointMetrics() instrumenting.Endpoint {
+	fieldKeys := []string{"method", "transport"}
+	return instrumenting.Endpoint{
+		Requests: kitprometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Subsystem: "endpoint",
+			Name:      "requests_total",
+			Help:      "Number of requests received.",
+		}, fieldKeys),
+		Errors: kitprometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Subsystem: "endpoint",
+			Name:      "request_errors_total",
+			Help:      "Number of requests which failed.",
+		}, fieldKeys),
+		Duration: kitprometheus.NewHistogramFrom(stdprometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Subsystem: "endpoint",
+			Name:      "request_duration_seconds",
+			Help:      "Duration of the requests in seconds.",
+			Buckets:   stdprometheus.DefBuckets,
+		}, fieldKeys),
+	}
+}
+
+func initPoolMetrics() postgres.PoolMetrics {
+	return postgres.PoolMetrics{
+		Connections: kitprometheus.NewGaugeFrom(stdprometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Subsystem: "db",
+			Name:      "connections",
+			Help:      "Number of open connections to the database by state.",
+		}, []string{"state"}),
+		MaxOpen: kitprometheus.NewGaugeFrom(stdprometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Subsystem: "db",
+			Name:      "max_open_connections",
+			Help:      "Largest number of open connections to the database, 0 if unlimited.",
+		}, []string{}),
+		Waits: kitprometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Subsystem: "db",
+			Name:      "waits_total",
+			Help:      "Number of connections waited for.",
+		}, []string{}),
+		WaitSeconds: kitprometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Subsystem: "db",
+			Name:      "wait_seconds_total",
+			Help:      "Time spent waiting for connections in seconds.",
+		}, []string{}),
+		Closed: kitprometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Subsystem: "db",
+			Name:      "closed_connections_total",
+			Help:      "Number of connections closed by the pool by reason.",
+		}, []string{"reason"}),
+	}
+}
+
 func initOpentracing() stdopentracing.Tracer {
 	return stdopentracing.GlobalTracer()
 }
@@ -187,7 +568,7 @@ func initZipkin(serviceName, httpPort, zipkinV2URL string, logger log.Logger) (z
 	return
 }
 
-func startHTTPServer(ctx context.Context, wg *sync.WaitGroup, endpoints endpoints.Endpoints, tracer stdopentracing.Tracer, zipkinTracer *zipkin.Tracer, port string, logger log.Logger) {
+func startHTTPServer(ctx context.Context, wg *sync.WaitGroup, endpoints endpoints.Endpoints, tracer stdopentracing.Tracer, zipkinTracer *zipkin.Tracer, port string, tlsConfig *tls.Config, wsOrigins []string, logger log.Logger) {
 	wg.Add(1)
 	defer wg.Done()
 
@@ -196,13 +577,32 @@ func startHTTPServer(ctx context.Context, wg *sync.WaitGroup, endpoints endpoint
 		return
 	}
 
+	// the long-lived event streams end when the server shuts down, while
+	// the other requests are left to complete
+	streamCtx, cancelStreams := context.WithCancel(context.Background())
+	defer cancelStreams()
+
+	mux := http.NewServeMux()
+	mux.Handle("/rpc", transportsjsonrpc.NewJSONRPCHandler(endpoints, tracer, zipkinTracer, logger))
+	mux.Handle("/", transportshttp.NewHTTPHandler(endpoints, tracer, zipkinTracer, logger, transportshttp.WithStreamContext(streamCtx), transportshttp.WithAllowedOrigins(wsOrigins...)))
+
 	p := fmt.Sprintf(":%s", port)
 	// create a server
-	srv := &http.Server{Addr: p, Handler: transportshttp.NewHTTPHandler(endpoints, tracer, zipkinTracer, logger)}
-	level.Info(logger).Log("protocol", "HTTP", "exposed", port)
+	srv := &http.Server{
+		Addr:      p,
+		Handler:   mux,
+		TLSConfig: tlsConfig,
+	}
+	srv.RegisterOnShutdown(cancelStreams)
+	level.Info(logger).Log("protocol", "HTTP", "exposed", port, "tls", tlsConfig != nil)
 	go func() {
 		// service connections
-		if err := srv.ListenAndServe(); err != nil {
+		serve := srv.ListenAndServe
+		if tlsConfig != nil {
+			// the certificates are those of tlsConfig
+			serve = func() error { return srv.ListenAndServeTLS("", "") }
+		}
+		if err := serve(); err != nil {
 			level.Info(logger).Log("Listen", err)
 		}
 	}()
@@ -219,7 +619,47 @@ func startHTTPServer(ctx context.Context, wg *sync.WaitGroup, endpoints endpoint
 	level.Info(logger).Log("protocol", "HTTP", "Shutdown", "http server gracefully stopped")
 }
 
-func startGRPCServer(ctx context.Context, wg *sync.WaitGroup, endpoints endpoints.Endpoints, tracer stdopentracing.Tracer, zipkinTracer *zipkin.Tracer, port string, hs *health.Server, logger log.Logger) {
+func startAdminServer(ctx context.Context, wg *sync.WaitGroup, host, port string, logLevel *loglevel.Logger, logger log.Logger) {
+	wg.Add(1)
+	defer wg.Done()
+
+	if port == "" {
+		level.Info(logger).Log("protocol", "admin", "disabled", "no port assigned")
+		return
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+	mux.Handle("/log/level", logLevel.Handler())
+
+	// the admin port is plaintext and unauthenticated, it is meant to be
+	// reachable from the monitoring only
+	addr := net.JoinHostPort(host, port)
+	srv := &http.Server{
+		Addr:    addr,
+		Handler: mux,
+	}
+	level.Info(logger).Log("protocol", "admin", "exposed", addr)
+	if ip := net.ParseIP(host); host != "localhost" && (ip == nil || !ip.IsLoopback()) {
+		level.Warn(logger).Log("protocol", "admin", "msg", "the log level can be changed by anyone reaching "+addr)
+	}
+	go func() {
+		if err := srv.ListenAndServe(); err != nil {
+			level.Info(logger).Log("Listen", err)
+		}
+	}()
+
+	<-ctx.Done()
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	srv.Shutdown(shutdownCtx)
+
+	level.Info(logger).Log("protocol", "admin", "Shutdown", "admin server gracefully stopped")
+}
+
+func startGRPCServer(ctx context.Context, wg *sync.WaitGroup, endpoints endpoints.Endpoints, tracer stdopentracing.Tracer, zipkinTracer *zipkin.Tracer, port string, tlsConfig *tls.Config, hs *health.Server, logger log.Logger) {
 	wg.Add(1)
 	defer wg.Done()
 
@@ -231,9 +671,18 @@ func startGRPCServer(ctx context.Context, wg *sync.WaitGroup, endpoints endpoint
 	}
 
 	var server *grpc.Server
-	level.Info(logger).Log("protocol", "GRPC", "exposed", port)
-	server = grpc.NewServer(grpc.UnaryInterceptor(kitgrpc.Interceptor))
-	pb.RegisterTodoServer(server, transportsgrpc.MakeGRPCServer(endpoints, tracer, zipkinTracer, logger))
+	level.Info(logger).Log("protocol", "GRPC", "exposed", port, "tls", tlsConfig != nil)
+	opts := []grpc.ServerOption{grpc.UnaryInterceptor(kitgrpc.Interceptor)}
+	if tlsConfig != nil {
+		opts = append(opts, grpc.Creds(credentials.NewTLS(tlsConfig)))
+	}
+	// the Watch streams end when the server shuts down, while the other calls
+	// are left to complete
+	streamCtx, cancelStreams := context.WithCancel(context.Background())
+	defer cancelStreams()
+
+	server = grpc.NewServer(opts...)
+	pb.RegisterTodoServer(server, transportsgrpc.MakeGRPCServer(endpoints, tracer, zipkinTracer, logger, transportsgrpc.WithStreamContext(streamCtx)))
 	healthgrpc.RegisterHealthServer(server, hs)
 	reflection.Register(server)
 
@@ -247,8 +696,80 @@ func startGRPCServer(ctx context.Context, wg *sync.WaitGroup, endpoints endpoint
 
 	<-ctx.Done()
 
+	// end the Watch streams, GracefulStop waits for them otherwise
+	cancelStreams()
 	// ignore error since it will be "Err shutting down server : context canceled"
 	server.GracefulStop()
 
 	fmt.Println("grpc server gracefully stopped")
 }
+
+func startArchiver(ctx context.Context, wg *sync.WaitGroup, repo model.TodoRepository, after, interval time.Duration, logger log.Logger) {
+	wg.Add(1)
+	defer wg.Done()
+
+	if after <= 0 || interval <= 0 {
+		level.Info(logger).Log("archiver", "disabled")
+		return
+	}
+
+	level.Info(logger).Log("archiver", "started", "after", after, "interval", interval)
+	service.NewArchiver(repo, after, interval, log.With(logger, "component", "archiver")).Run(ctx)
+	level.Info(logger).Log("archiver", "stopped")
+}
+
+func startPoolStats(ctx context.Context, wg *sync.WaitGroup, db *gorm.DB, m postgres.PoolMetrics, logger log.Logger) {
+	wg.Add(1)
+	defer wg.Done()
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		level.Error(logger).Log("pool stats", "disabled", "err", err)
+		return
+	}
+
+	level.Info(logger).Log("pool stats", "started", "interval", poolStatsInterval)
+	postgres.RecordPoolStats(ctx, sqlDB, m, poolStatsInterval)
+	level.Info(logger).Log("pool stats", "stopped")
+}
+
+func startPolicyReloader(ctx context.Context, wg *sync.WaitGroup, policy *authz.FileEngine, interval time.Duration, logger log.Logger) {
+	wg.Add(1)
+	defer wg.Done()
+
+	if interval <= 0 {
+		level.Info(logger).Log("policy reloader", "disabled")
+		return
+	}
+
+	level.Info(logger).Log("policy reloader", "started", "interval", interval)
+	policy.Run(ctx, interval)
+	level.Info(logger).Log("policy reloader", "stopped")
+}
+
+func startCertReloader(ctx context.Context, wg *sync.WaitGroup, certs *tlsconfig.Reloader, interval time.Duration, logger log.Logger) {
+	wg.Add(1)
+	defer wg.Done()
+
+	if interval <= 0 {
+		level.Info(logger).Log("certificate reloader", "disabled")
+		return
+	}
+
+	level.Info(logger).Log("certificate reloader", "started", "interval", interval)
+	certs.Run(ctx, interval)
+	level.Info(logger).Log("certificate reloader", "stopped")
+}
+
+func startEventListener(ctx context.Context, wg *sync.WaitGroup, dbConfig postgres.Config, bus *service.EventBus, repo model.TodoRepository, logger log.Logger) {
+	wg.Add(1)
+	defer wg.Done()
+
+	level.Info(logger).Log("listener", "started")
+	listener := postgres.NewEventListener(dbConfig, bus, repo, log.With(logger, "component", "listener"))
+	if err := listener.Run(ctx); err != nil {
+		level.Error(logger).Log("listener", "exit", "err", err)
+		return
+	}
+	level.Info(logger).Log("listener", "stopped")
+}
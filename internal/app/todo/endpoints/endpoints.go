@@ -2,6 +2,7 @@ package endpoints
 
 import (
 	"context"
+	"time"
 
 	"github.com/go-kit/kit/endpoint"
 	"github.com/go-kit/kit/log"
@@ -18,18 +19,59 @@ import (
 // meant to be used as a helper struct, to collect all of the endpoints into a
 // single parameter.
 type Endpoints struct {
-	AddEndpoint    endpoint.Endpoint `json:""`
-	DeleteEndpoint endpoint.Endpoint `json:""`
-	UpdateEndpoint endpoint.Endpoint `json:""`
-	ListEndpoint   endpoint.Endpoint `json:""`
+	AddEndpoint          endpoint.Endpoint `json:""`
+	DeleteEndpoint       endpoint.Endpoint `json:""`
+	UpdateEndpoint       endpoint.Endpoint `json:""`
+	ListEndpoint         endpoint.Endpoint `json:""`
+	GetEndpoint          endpoint.Endpoint `json:""`
+	StatsEndpoint        endpoint.Endpoint `json:""`
+	UnarchiveEndpoint    endpoint.Endpoint `json:""`
+	SnoozeEndpoint       endpoint.Endpoint `json:""`
+	WatchEndpoint        endpoint.Endpoint `json:""`
+	SyncEndpoint         endpoint.Endpoint `json:""`
+	PushEndpoint         endpoint.Endpoint `json:""`
+	AddShareEndpoint     endpoint.Endpoint `json:""`
+	DeleteShareEndpoint  endpoint.Endpoint `json:""`
+	ListSharesEndpoint   endpoint.Endpoint `json:""`
+	CreateAPIKeyEndpoint endpoint.Endpoint `json:""`
+	RevokeAPIKeyEndpoint endpoint.Endpoint `json:""`
+	ListAPIKeysEndpoint  endpoint.Endpoint `json:""`
+	UsageEndpoint        endpoint.Endpoint `json:""`
+}
+
+// options are the middlewares of the endpoints returned by New.
+type options struct {
+	rateLimit func(method string) endpoint.Middleware
+}
+
+// nop is the middleware leaving the endpoints as they are.
+func nop(next endpoint.Endpoint) endpoint.Endpoint {
+	return next
+}
+
+// Option configures the endpoints returned by New.
+type Option func(*options)
+
+// WithRateLimit rate limits every endpoint with the middleware r makes for
+// its method, such as ratelimit.Limiter.Middleware.
+func WithRateLimit(r func(method string) endpoint.Middleware) Option {
+	return func(o *options) {
+		o.rateLimit = r
+	}
 }
 
 // New return a new instance of the endpoint that wraps the provided service.
-func New(svc service.TodoService, logger log.Logger, otTracer stdopentracing.Tracer, zipkinTracer *stdzipkin.Tracer) (ep Endpoints) {
+func New(svc service.TodoService, logger log.Logger, otTracer stdopentracing.Tracer, zipkinTracer *stdzipkin.Tracer, opts ...Option) (ep Endpoints) {
+	o := &options{rateLimit: func(string) endpoint.Middleware { return nop }}
+	for _, opt := range opts {
+		opt(o)
+	}
+
 	var addEndpoint endpoint.Endpoint
 	{
 		method := "add"
 		addEndpoint = MakeAddEndpoint(svc)
+		addEndpoint = o.rateLimit(method)(addEndpoint)
 		addEndpoint = opentracing.TraceServer(otTracer, method)(addEndpoint)
 		addEndpoint = zipkin.TraceEndpoint(zipkinTracer, method)(addEndpoint)
 		addEndpoint = LoggingMiddleware(log.With(logger, "method", method))(addEndpoint)
@@ -40,6 +82,7 @@ func New(svc service.TodoService, logger log.Logger, otTracer stdopentracing.Tra
 	{
 		method := "delete"
 		deleteEndpoint = MakeDeleteEndpoint(svc)
+		deleteEndpoint = o.rateLimit(method)(deleteEndpoint)
 		deleteEndpoint = opentracing.TraceServer(otTracer, method)(deleteEndpoint)
 		deleteEndpoint = zipkin.TraceEndpoint(zipkinTracer, method)(deleteEndpoint)
 		deleteEndpoint = LoggingMiddleware(log.With(logger, "method", method))(deleteEndpoint)
@@ -50,6 +93,7 @@ func New(svc service.TodoService, logger log.Logger, otTracer stdopentracing.Tra
 	{
 		method := "update"
 		updateEndpoint = MakeUpdateEndpoint(svc)
+		updateEndpoint = o.rateLimit(method)(updateEndpoint)
 		updateEndpoint = opentracing.TraceServer(otTracer, method)(updateEndpoint)
 		updateEndpoint = zipkin.TraceEndpoint(zipkinTracer, method)(updateEndpoint)
 		updateEndpoint = LoggingMiddleware(log.With(logger, "method", method))(updateEndpoint)
@@ -60,12 +104,167 @@ func New(svc service.TodoService, logger log.Logger, otTracer stdopentracing.Tra
 	{
 		method := "list"
 		listEndpoint = MakeListEndpoint(svc)
+		listEndpoint = o.rateLimit(method)(listEndpoint)
 		listEndpoint = opentracing.TraceServer(otTracer, method)(listEndpoint)
 		listEndpoint = zipkin.TraceEndpoint(zipkinTracer, method)(listEndpoint)
 		listEndpoint = LoggingMiddleware(log.With(logger, "method", method))(listEndpoint)
 		ep.ListEndpoint = listEndpoint
 	}
 
+	var getEndpoint endpoint.Endpoint
+	{
+		method := "get"
+		getEndpoint = MakeGetEndpoint(svc)
+		getEndpoint = o.rateLimit(method)(getEndpoint)
+		getEndpoint = opentracing.TraceServer(otTracer, method)(getEndpoint)
+		getEndpoint = zipkin.TraceEndpoint(zipkinTracer, method)(getEndpoint)
+		getEndpoint = LoggingMiddleware(log.With(logger, "method", method))(getEndpoint)
+		ep.GetEndpoint = getEndpoint
+	}
+
+	var statsEndpoint endpoint.Endpoint
+	{
+		method := "stats"
+		statsEndpoint = MakeStatsEndpoint(svc)
+		statsEndpoint = o.rateLimit(method)(statsEndpoint)
+		statsEndpoint = opentracing.TraceServer(otTracer, method)(statsEndpoint)
+		statsEndpoint = zipkin.TraceEndpoint(zipkinTracer, method)(statsEndpoint)
+		statsEndpoint = LoggingMiddleware(log.With(logger, "method", method))(statsEndpoint)
+		ep.StatsEndpoint = statsEndpoint
+	}
+
+	var unarchiveEndpoint endpoint.Endpoint
+	{
+		method := "unarchive"
+		unarchiveEndpoint = MakeUnarchiveEndpoint(svc)
+		unarchiveEndpoint = o.rateLimit(method)(unarchiveEndpoint)
+		unarchiveEndpoint = opentracing.TraceServer(otTracer, method)(unarchiveEndpoint)
+		unarchiveEndpoint = zipkin.TraceEndpoint(zipkinTracer, method)(unarchiveEndpoint)
+		unarchiveEndpoint = LoggingMiddleware(log.With(logger, "method", method))(unarchiveEndpoint)
+		ep.UnarchiveEndpoint = unarchiveEndpoint
+	}
+
+	var snoozeEndpoint endpoint.Endpoint
+	{
+		method := "snooze"
+		snoozeEndpoint = MakeSnoozeEndpoint(svc)
+		snoozeEndpoint = o.rateLimit(method)(snoozeEndpoint)
+		snoozeEndpoint = opentracing.TraceServer(otTracer, method)(snoozeEndpoint)
+		snoozeEndpoint = zipkin.TraceEndpoint(zipkinTracer, method)(snoozeEndpoint)
+		snoozeEndpoint = LoggingMiddleware(log.With(logger, "method", method))(snoozeEndpoint)
+		ep.SnoozeEndpoint = snoozeEndpoint
+	}
+
+	var watchEndpoint endpoint.Endpoint
+	{
+		method := "watch"
+		watchEndpoint = MakeWatchEndpoint(svc)
+		watchEndpoint = o.rateLimit(method)(watchEndpoint)
+		watchEndpoint = opentracing.TraceServer(otTracer, method)(watchEndpoint)
+		watchEndpoint = zipkin.TraceEndpoint(zipkinTracer, method)(watchEndpoint)
+		watchEndpoint = LoggingMiddleware(log.With(logger, "method", method))(watchEndpoint)
+		ep.WatchEndpoint = watchEndpoint
+	}
+
+	var syncEndpoint endpoint.Endpoint
+	{
+		method := "sync"
+		syncEndpoint = MakeSyncEndpoint(svc)
+		syncEndpoint = o.rateLimit(method)(syncEndpoint)
+		syncEndpoint = opentracing.TraceServer(otTracer, method)(syncEndpoint)
+		syncEndpoint = zipkin.TraceEndpoint(zipkinTracer, method)(syncEndpoint)
+		syncEndpoint = LoggingMiddleware(log.With(logger, "method", method))(syncEndpoint)
+		ep.SyncEndpoint = syncEndpoint
+	}
+
+	var pushEndpoint endpoint.Endpoint
+	{
+		method := "push"
+		pushEndpoint = MakePushEndpoint(svc)
+		pushEndpoint = o.rateLimit(method)(pushEndpoint)
+		pushEndpoint = opentracing.TraceServer(otTracer, method)(pushEndpoint)
+		pushEndpoint = zipkin.TraceEndpoint(zipkinTracer, method)(pushEndpoint)
+		pushEndpoint = LoggingMiddleware(log.With(logger, "method", method))(pushEndpoint)
+		ep.PushEndpoint = pushEndpoint
+	}
+
+	var addShareEndpoint endpoint.Endpoint
+	{
+		method := "addShare"
+		addShareEndpoint = MakeAddShareEndpoint(svc)
+		addShareEndpoint = o.rateLimit(method)(addShareEndpoint)
+		addShareEndpoint = opentracing.TraceServer(otTracer, method)(addShareEndpoint)
+		addShareEndpoint = zipkin.TraceEndpoint(zipkinTracer, method)(addShareEndpoint)
+		addShareEndpoint = LoggingMiddleware(log.With(logger, "method", method))(addShareEndpoint)
+		ep.AddShareEndpoint = addShareEndpoint
+	}
+
+	var deleteShareEndpoint endpoint.Endpoint
+	{
+		method := "deleteShare"
+		deleteShareEndpoint = MakeDeleteShareEndpoint(svc)
+		deleteShareEndpoint = o.rateLimit(method)(deleteShareEndpoint)
+		deleteShareEndpoint = opentracing.TraceServer(otTracer, method)(deleteShareEndpoint)
+		deleteShareEndpoint = zipkin.TraceEndpoint(zipkinTracer, method)(deleteShareEndpoint)
+		deleteShareEndpoint = LoggingMiddleware(log.With(logger, "method", method))(deleteShareEndpoint)
+		ep.DeleteShareEndpoint = deleteShareEndpoint
+	}
+
+	var listSharesEndpoint endpoint.Endpoint
+	{
+		method := "listShares"
+		listSharesEndpoint = MakeListSharesEndpoint(svc)
+		listSharesEndpoint = o.rateLimit(method)(listSharesEndpoint)
+		listSharesEndpoint = opentracing.TraceServer(otTracer, method)(listSharesEndpoint)
+		listSharesEndpoint = zipkin.TraceEndpoint(zipkinTracer, method)(listSharesEndpoint)
+		listSharesEndpoint = LoggingMiddleware(log.With(logger, "method", method))(listSharesEndpoint)
+		ep.ListSharesEndpoint = listSharesEndpoint
+	}
+
+	var createAPIKeyEndpoint endpoint.Endpoint
+	{
+		method := "createAPIKey"
+		createAPIKeyEndpoint = MakeCreateAPIKeyEndpoint(svc)
+		createAPIKeyEndpoint = o.rateLimit(method)(createAPIKeyEndpoint)
+		createAPIKeyEndpoint = opentracing.TraceServer(otTracer, method)(createAPIKeyEndpoint)
+		createAPIKeyEndpoint = zipkin.TraceEndpoint(zipkinTracer, method)(createAPIKeyEndpoint)
+		createAPIKeyEndpoint = LoggingMiddleware(log.With(logger, "method", method))(createAPIKeyEndpoint)
+		ep.CreateAPIKeyEndpoint = createAPIKeyEndpoint
+	}
+
+	var revokeAPIKeyEndpoint endpoint.Endpoint
+	{
+		method := "revokeAPIKey"
+		revokeAPIKeyEndpoint = MakeRevokeAPIKeyEndpoint(svc)
+		revokeAPIKeyEndpoint = o.rateLimit(method)(revokeAPIKeyEndpoint)
+		revokeAPIKeyEndpoint = opentracing.TraceServer(otTracer, method)(revokeAPIKeyEndpoint)
+		revokeAPIKeyEndpoint = zipkin.TraceEndpoint(zipkinTracer, method)(revokeAPIKeyEndpoint)
+		revokeAPIKeyEndpoint = LoggingMiddleware(log.With(logger, "method", method))(revokeAPIKeyEndpoint)
+		ep.RevokeAPIKeyEndpoint = revokeAPIKeyEndpoint
+	}
+
+	var listAPIKeysEndpoint endpoint.Endpoint
+	{
+		method := "listAPIKeys"
+		listAPIKeysEndpoint = MakeListAPIKeysEndpoint(svc)
+		listAPIKeysEndpoint = o.rateLimit(method)(listAPIKeysEndpoint)
+		listAPIKeysEndpoint = opentracing.TraceServer(otTracer, method)(listAPIKeysEndpoint)
+		listAPIKeysEndpoint = zipkin.TraceEndpoint(zipkinTracer, method)(listAPIKeysEndpoint)
+		listAPIKeysEndpoint = LoggingMiddleware(log.With(logger, "method", method))(listAPIKeysEndpoint)
+		ep.ListAPIKeysEndpoint = listAPIKeysEndpoint
+	}
+
+	var usageEndpoint endpoint.Endpoint
+	{
+		method := "usage"
+		usageEndpoint = MakeUsageEndpoint(svc)
+		usageEndpoint = o.rateLimit(method)(usageEndpoint)
+		usageEndpoint = opentracing.TraceServer(otTracer, method)(usageEndpoint)
+		usageEndpoint = zipkin.TraceEndpoint(zipkinTracer, method)(usageEndpoint)
+		usageEndpoint = LoggingMiddleware(log.With(logger, "method", method))(usageEndpoint)
+		ep.UsageEndpoint = usageEndpoint
+	}
+
 	return ep
 }
 
@@ -149,18 +348,358 @@ func MakeListEndpoint(svc service.TodoService) (ep endpoint.Endpoint) {
 		if err := req.validate(); err != nil {
 			return ListResponse{}, err
 		}
-		res, err := svc.List(ctx)
-		return ListResponse{Res: res}, err
+		res, err := svc.List(ctx, req.Query)
+		var fields []string
+		if req.Query != nil {
+			fields = req.Query.Fields
+		}
+		return ListResponse{Res: res, Fields: fields}, err
 	}
 }
 
 // List implements the service interface, so Endpoints may be used as a service.
 // This is primarily useful in the context of a client library.
-func (e Endpoints) List(ctx context.Context) (res []*model.TodoRes, err error) {
-	resp, err := e.ListEndpoint(ctx, ListRequest{})
+func (e Endpoints) List(ctx context.Context, query *model.TodoQuery) (res []*model.TodoRes, err error) {
+	resp, err := e.ListEndpoint(ctx, ListRequest{Query: query})
 	if err != nil {
 		return
 	}
 	response := resp.(ListResponse)
 	return response.Res, nil
 }
+
+// MakeGetEndpoint returns an endpoint that invokes Get on the service.
+// Primarily useful in a server.
+func MakeGetEndpoint(svc service.TodoService) (ep endpoint.Endpoint) {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(GetRequest)
+		if err := req.validate(); err != nil {
+			return GetResponse{}, err
+		}
+		res, err := svc.Get(ctx, req.Id)
+		return GetResponse{Res: res, Fields: req.Fields}, err
+	}
+}
+
+// Get implements the service interface, so Endpoints may be used as a service.
+// This is primarily useful in the context of a client library.
+func (e Endpoints) Get(ctx context.Context, id string) (res *model.TodoRes, err error) {
+	resp, err := e.GetEndpoint(ctx, GetRequest{Id: id})
+	if err != nil {
+		return
+	}
+	response := resp.(GetResponse)
+	return response.Res, nil
+}
+
+// MakeStatsEndpoint returns an endpoint that invokes Stats on the service.
+// Primarily useful in a server.
+func MakeStatsEndpoint(svc service.TodoService) (ep endpoint.Endpoint) {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(StatsRequest)
+		if err := req.validate(); err != nil {
+			return StatsResponse{}, err
+		}
+		res, err := svc.Stats(ctx, req.Days)
+		return StatsResponse{Res: res}, err
+	}
+}
+
+// Stats implements the service interface, so Endpoints may be used as a service.
+// This is primarily useful in the context of a client library.
+func (e Endpoints) Stats(ctx context.Context, days int) (res *model.TodoStats, err error) {
+	resp, err := e.StatsEndpoint(ctx, StatsRequest{Days: days})
+	if err != nil {
+		return
+	}
+	response := resp.(StatsResponse)
+	return response.Res, nil
+}
+
+// MakeUnarchiveEndpoint returns an endpoint that invokes Unarchive on the service.
+// Primarily useful in a server.
+func MakeUnarchiveEndpoint(svc service.TodoService) (ep endpoint.Endpoint) {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(UnarchiveRequest)
+		if err := req.validate(); err != nil {
+			return UnarchiveResponse{}, err
+		}
+		res, err := svc.Unarchive(ctx, req.Id)
+		return UnarchiveResponse{Res: res}, err
+	}
+}
+
+// Unarchive implements the service interface, so Endpoints may be used as a service.
+// This is primarily useful in the context of a client library.
+func (e Endpoints) Unarchive(ctx context.Context, id string) (res *model.TodoRes, err error) {
+	resp, err := e.UnarchiveEndpoint(ctx, UnarchiveRequest{Id: id})
+	if err != nil {
+		return
+	}
+	response := resp.(UnarchiveResponse)
+	return response.Res, nil
+}
+
+// MakeSnoozeEndpoint returns an endpoint that invokes Snooze on the service.
+// Primarily useful in a server.
+func MakeSnoozeEndpoint(svc service.TodoService) (ep endpoint.Endpoint) {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(SnoozeRequest)
+		if err := req.validate(); err != nil {
+			return SnoozeResponse{}, err
+		}
+		res, err := svc.Snooze(ctx, req.Id, req.Until)
+		return SnoozeResponse{Res: res}, err
+	}
+}
+
+// Snooze implements the service interface, so Endpoints may be used as a service.
+// This is primarily useful in the context of a client library.
+func (e Endpoints) Snooze(ctx context.Context, id string, until time.Time) (res *model.TodoRes, err error) {
+	resp, err := e.SnoozeEndpoint(ctx, SnoozeRequest{Id: id, Until: until})
+	if err != nil {
+		return
+	}
+	response := resp.(SnoozeResponse)
+	return response.Res, nil
+}
+
+// MakeWatchEndpoint returns an endpoint that invokes Watch on the service.
+// Primarily useful in a server.
+func MakeWatchEndpoint(svc service.TodoService) (ep endpoint.Endpoint) {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(WatchRequest)
+		if err := req.validate(); err != nil {
+			return WatchResponse{}, err
+		}
+		events, err := svc.Watch(ctx, req.Query)
+		return WatchResponse{Events: events}, err
+	}
+}
+
+// Watch implements the service interface, so Endpoints may be used as a service.
+// This is primarily useful in the context of a client library.
+func (e Endpoints) Watch(ctx context.Context, query *model.EventQuery) (res <-chan *model.TodoEvent, err error) {
+	resp, err := e.WatchEndpoint(ctx, WatchRequest{Query: query})
+	if err != nil {
+		return
+	}
+	response := resp.(WatchResponse)
+	return response.Events, nil
+}
+
+// MakeSyncEndpoint returns an endpoint that invokes Sync on the service.
+// Primarily useful in a server.
+func MakeSyncEndpoint(svc service.TodoService) (ep endpoint.Endpoint) {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(SyncRequest)
+		if err := req.validate(); err != nil {
+			return SyncResponse{}, err
+		}
+		res, err := svc.Sync(ctx, req.Token)
+		return SyncResponse{Res: res}, err
+	}
+}
+
+// Sync implements the service interface, so Endpoints may be used as a service.
+// This is primarily useful in the context of a client library.
+func (e Endpoints) Sync(ctx context.Context, token string) (res *model.Changes, err error) {
+	resp, err := e.SyncEndpoint(ctx, SyncRequest{Token: token})
+	if err != nil {
+		return
+	}
+	response := resp.(SyncResponse)
+	return response.Res, nil
+}
+
+// MakePushEndpoint returns an endpoint that invokes Push on the service.
+// Primarily useful in a server.
+func MakePushEndpoint(svc service.TodoService) (ep endpoint.Endpoint) {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(PushRequest)
+		if err := req.validate(); err != nil {
+			return PushResponse{}, err
+		}
+		res, err := svc.Push(ctx, req.Mutations)
+		return PushResponse{Res: res}, err
+	}
+}
+
+// Push implements the service interface, so Endpoints may be used as a service.
+// This is primarily useful in the context of a client library.
+func (e Endpoints) Push(ctx context.Context, mutations []*model.Mutation) (res []*model.MutationResult, err error) {
+	resp, err := e.PushEndpoint(ctx, PushRequest{Mutations: mutations})
+	if err != nil {
+		return
+	}
+	response := resp.(PushResponse)
+	return response.Res, nil
+}
+
+// MakeAddShareEndpoint returns an endpoint that invokes AddShare on the service.
+// Primarily useful in a server.
+func MakeAddShareEndpoint(svc service.TodoService) (ep endpoint.Endpoint) {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(AddShareRequest)
+		if err := req.validate(); err != nil {
+			return AddShareResponse{}, err
+		}
+		res, err := svc.AddShare(ctx, req.Share)
+		return AddShareResponse{Res: res}, err
+	}
+}
+
+// AddShare implements the service interface, so Endpoints may be used as a service.
+// This is primarily useful in the context of a client library.
+func (e Endpoints) AddShare(ctx context.Context, share *model.ShareReq) (res *model.Share, err error) {
+	resp, err := e.AddShareEndpoint(ctx, AddShareRequest{Share: share})
+	if err != nil {
+		return
+	}
+	response := resp.(AddShareResponse)
+	return response.Res, nil
+}
+
+// MakeDeleteShareEndpoint returns an endpoint that invokes DeleteShare on the service.
+// Primarily useful in a server.
+func MakeDeleteShareEndpoint(svc service.TodoService) (ep endpoint.Endpoint) {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(DeleteShareRequest)
+		if err := req.validate(); err != nil {
+			return DeleteShareResponse{}, err
+		}
+		err := svc.DeleteShare(ctx, req.Id)
+		return DeleteShareResponse{}, err
+	}
+}
+
+// DeleteShare implements the service interface, so Endpoints may be used as a service.
+// This is primarily useful in the context of a client library.
+func (e Endpoints) DeleteShare(ctx context.Context, id string) (err error) {
+	resp, err := e.DeleteShareEndpoint(ctx, DeleteShareRequest{Id: id})
+	if err != nil {
+		return
+	}
+	_ = resp.(DeleteShareResponse)
+	return nil
+}
+
+// MakeListSharesEndpoint returns an endpoint that invokes ListShares on the service.
+// Primarily useful in a server.
+func MakeListSharesEndpoint(svc service.TodoService) (ep endpoint.Endpoint) {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(ListSharesRequest)
+		if err := req.validate(); err != nil {
+			return ListSharesResponse{}, err
+		}
+		res, err := svc.ListShares(ctx)
+		return ListSharesResponse{Res: res}, err
+	}
+}
+
+// ListShares implements the service interface, so Endpoints may be used as a service.
+// This is primarily useful in the context of a client library.
+func (e Endpoints) ListShares(ctx context.Context) (res []*model.Share, err error) {
+	resp, err := e.ListSharesEndpoint(ctx, ListSharesRequest{})
+	if err != nil {
+		return
+	}
+	response := resp.(ListSharesResponse)
+	return response.Res, nil
+}
+
+// MakeCreateAPIKeyEndpoint returns an endpoint that invokes CreateAPIKey on the service.
+// Primarily useful in a server.
+func MakeCreateAPIKeyEndpoint(svc service.TodoService) (ep endpoint.Endpoint) {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(CreateAPIKeyRequest)
+		if err := req.validate(); err != nil {
+			return CreateAPIKeyResponse{}, err
+		}
+		res, err := svc.CreateAPIKey(ctx, req.Key)
+		return CreateAPIKeyResponse{Res: res}, err
+	}
+}
+
+// CreateAPIKey implements the service interface, so Endpoints may be used as a service.
+// This is primarily useful in the context of a client library.
+func (e Endpoints) CreateAPIKey(ctx context.Context, key *model.APIKeyReq) (res *model.CreatedAPIKey, err error) {
+	resp, err := e.CreateAPIKeyEndpoint(ctx, CreateAPIKeyRequest{Key: key})
+	if err != nil {
+		return
+	}
+	response := resp.(CreateAPIKeyResponse)
+	return response.Res, nil
+}
+
+// MakeRevokeAPIKeyEndpoint returns an endpoint that invokes RevokeAPIKey on the service.
+// Primarily useful in a server.
+func MakeRevokeAPIKeyEndpoint(svc service.TodoService) (ep endpoint.Endpoint) {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(RevokeAPIKeyRequest)
+		if err := req.validate(); err != nil {
+			return RevokeAPIKeyResponse{}, err
+		}
+		err := svc.RevokeAPIKey(ctx, req.Id)
+		return RevokeAPIKeyResponse{}, err
+	}
+}
+
+// RevokeAPIKey implements the service interface, so Endpoints may be used as a service.
+// This is primarily useful in the context of a client library.
+func (e Endpoints) RevokeAPIKey(ctx context.Context, id string) (err error) {
+	resp, err := e.RevokeAPIKeyEndpoint(ctx, RevokeAPIKeyRequest{Id: id})
+	if err != nil {
+		return
+	}
+	_ = resp.(RevokeAPIKeyResponse)
+	return nil
+}
+
+// MakeListAPIKeysEndpoint returns an endpoint that invokes ListAPIKeys on the service.
+// Primarily useful in a server.
+func MakeListAPIKeysEndpoint(svc service.TodoService) (ep endpoint.Endpoint) {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(ListAPIKeysRequest)
+		if err := req.validate(); err != nil {
+			return ListAPIKeysResponse{}, err
+		}
+		res, err := svc.ListAPIKeys(ctx)
+		return ListAPIKeysResponse{Res: res}, err
+	}
+}
+
+// ListAPIKeys implements the service interface, so Endpoints may be used as a service.
+// This is primarily useful in the context of a client library.
+func (e Endpoints) ListAPIKeys(ctx context.Context) (res []*model.APIKey, err error) {
+	resp, err := e.ListAPIKeysEndpoint(ctx, ListAPIKeysRequest{})
+	if err != nil {
+		return
+	}
+	response := resp.(ListAPIKeysResponse)
+	return response.Res, nil
+}
+
+// MakeUsageEndpoint returns an endpoint that invokes Usage on the service.
+// Primarily useful in a server.
+func MakeUsageEndpoint(svc service.TodoService) (ep endpoint.Endpoint) {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(UsageRequest)
+		if err := req.validate(); err != nil {
+			return UsageResponse{}, err
+		}
+		res, err := svc.Usage(ctx)
+		return UsageResponse{Res: res}, err
+	}
+}
+
+// Usage implements the service interface, so Endpoints may be used as a service.
+// This is primarily useful in the context of a client library.
+func (e Endpoints) Usage(ctx context.Context) (res *model.Usage, err error) {
+	resp, err := e.UsageEndpoint(ctx, UsageRequest{})
+	if err != nil {
+		return
+	}
+	response := resp.(UsageResponse)
+	return response.Res, nil
+}
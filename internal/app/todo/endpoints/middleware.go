@@ -7,6 +7,8 @@ import (
 	"github.com/go-kit/kit/endpoint"
 	"github.com/go-kit/kit/log"
 	"github.com/go-kit/kit/log/level"
+
+	"github.com/cage1016/gokit-todo/internal/app/todo/service"
 )
 
 // LoggingMiddleware returns an endpoint middleware that logs the
@@ -26,12 +28,88 @@ func LoggingMiddleware(logger log.Logger) endpoint.Middleware {
 	}
 }
 
-// AuthnMiddleware returns an endpoint middleware that apply authentication func
+// AuthnMiddleware applies the authentication middleware n to every endpoint.
 func AuthnMiddleware(n endpoint.Middleware, endpoints Endpoints) Endpoints {
-	return endpoints
+	return Endpoints{
+		AddEndpoint:          n(endpoints.AddEndpoint),
+		DeleteEndpoint:       n(endpoints.DeleteEndpoint),
+		UpdateEndpoint:       n(endpoints.UpdateEndpoint),
+		ListEndpoint:         n(endpoints.ListEndpoint),
+		GetEndpoint:          n(endpoints.GetEndpoint),
+		StatsEndpoint:        n(endpoints.StatsEndpoint),
+		UnarchiveEndpoint:    n(endpoints.UnarchiveEndpoint),
+		SnoozeEndpoint:       n(endpoints.SnoozeEndpoint),
+		WatchEndpoint:        n(endpoints.WatchEndpoint),
+		SyncEndpoint:         n(endpoints.SyncEndpoint),
+		PushEndpoint:         n(endpoints.PushEndpoint),
+		AddShareEndpoint:     n(endpoints.AddShareEndpoint),
+		DeleteShareEndpoint:  n(endpoints.DeleteShareEndpoint),
+		ListSharesEndpoint:   n(endpoints.ListSharesEndpoint),
+		CreateAPIKeyEndpoint: n(endpoints.CreateAPIKeyEndpoint),
+		RevokeAPIKeyEndpoint: n(endpoints.RevokeAPIKeyEndpoint),
+		ListAPIKeysEndpoint:  n(endpoints.ListAPIKeysEndpoint),
+		UsageEndpoint:        n(endpoints.UsageEndpoint),
+	}
 }
 
-// AuthzMiddleware returns an endpoint middleware that apply authorization func (opa rbac)
+// Resource is the resource the todo endpoints act on, as named to the
+// authorization func.
+const Resource = "todo"
+
+// APIKeyResource is the resource the API key endpoints act on, which a policy
+// only grants to the administrators.
+const APIKeyResource = service.APIKeyResource
+
+// AuthzMiddleware applies the middleware z makes for the action of every
+// endpoint, named after its service method, on Resource or APIKeyResource.
+// It has to be applied before AuthnMiddleware, so that it runs once the
+// caller is authenticated.
 func AuthzMiddleware(z func(action string, resource string) endpoint.Middleware, endpoints Endpoints) Endpoints {
-	return endpoints
+	return Endpoints{
+		AddEndpoint:          z("add", Resource)(endpoints.AddEndpoint),
+		DeleteEndpoint:       z("delete", Resource)(endpoints.DeleteEndpoint),
+		UpdateEndpoint:       z("update", Resource)(endpoints.UpdateEndpoint),
+		ListEndpoint:         z("list", Resource)(endpoints.ListEndpoint),
+		GetEndpoint:          z("get", Resource)(endpoints.GetEndpoint),
+		StatsEndpoint:        z("stats", Resource)(endpoints.StatsEndpoint),
+		UnarchiveEndpoint:    z("unarchive", Resource)(endpoints.UnarchiveEndpoint),
+		SnoozeEndpoint:       z("snooze", Resource)(endpoints.SnoozeEndpoint),
+		WatchEndpoint:        z("watch", Resource)(endpoints.WatchEndpoint),
+		SyncEndpoint:         z("sync", Resource)(endpoints.SyncEndpoint),
+		PushEndpoint:         z("push", Resource)(endpoints.PushEndpoint),
+		AddShareEndpoint:     z("addShare", Resource)(endpoints.AddShareEndpoint),
+		DeleteShareEndpoint:  z("deleteShare", Resource)(endpoints.DeleteShareEndpoint),
+		ListSharesEndpoint:   z("listShares", Resource)(endpoints.ListSharesEndpoint),
+		CreateAPIKeyEndpoint: z("createAPIKey", APIKeyResource)(endpoints.CreateAPIKeyEndpoint),
+		RevokeAPIKeyEndpoint: z("revokeAPIKey", APIKeyResource)(endpoints.RevokeAPIKeyEndpoint),
+		ListAPIKeysEndpoint:  z("listAPIKeys", APIKeyResource)(endpoints.ListAPIKeysEndpoint),
+		UsageEndpoint:        z("usage", Resource)(endpoints.UsageEndpoint),
+	}
+}
+
+// InstrumentingMiddleware applies the middleware m makes for the method of
+// every endpoint, such as instrumenting.Endpoint.Middleware. It has to be
+// applied after AuthnMiddleware, so that the requests it rejects are
+// instrumented as well.
+func InstrumentingMiddleware(m func(method string) endpoint.Middleware, endpoints Endpoints) Endpoints {
+	return Endpoints{
+		AddEndpoint:          m("add")(endpoints.AddEndpoint),
+		DeleteEndpoint:       m("delete")(endpoints.DeleteEndpoint),
+		UpdateEndpoint:       m("update")(endpoints.UpdateEndpoint),
+		ListEndpoint:         m("list")(endpoints.ListEndpoint),
+		GetEndpoint:          m("get")(endpoints.GetEndpoint),
+		StatsEndpoint:        m("stats")(endpoints.StatsEndpoint),
+		UnarchiveEndpoint:    m("unarchive")(endpoints.UnarchiveEndpoint),
+		SnoozeEndpoint:       m("snooze")(endpoints.SnoozeEndpoint),
+		WatchEndpoint:        m("watch")(endpoints.WatchEndpoint),
+		SyncEndpoint:         m("sync")(endpoints.SyncEndpoint),
+		PushEndpoint:         m("push")(endpoints.PushEndpoint),
+		AddShareEndpoint:     m("addShare")(endpoints.AddShareEndpoint),
+		DeleteShareEndpoint:  m("deleteShare")(endpoints.DeleteShareEndpoint),
+		ListSharesEndpoint:   m("listShares")(endpoints.ListSharesEndpoint),
+		CreateAPIKeyEndpoint: m("createAPIKey")(endpoints.CreateAPIKeyEndpoint),
+		RevokeAPIKeyEndpoint: m("revokeAPIKey")(endpoints.RevokeAPIKeyEndpoint),
+		ListAPIKeysEndpoint:  m("listAPIKeys")(endpoints.ListAPIKeysEndpoint),
+		UsageEndpoint:        m("usage")(endpoints.UsageEndpoint),
+	}
 }
@@ -1,6 +1,9 @@
 package endpoints
 
 import (
+	"strings"
+	"time"
+
 	"github.com/cage1016/gokit-todo/internal/app/todo/model"
 	"github.com/cage1016/gokit-todo/internal/app/todo/service"
 )
@@ -42,8 +45,190 @@ func (r UpdateRequest) validate() error {
 
 // ListRequest collects the request parameters for the List method.
 type ListRequest struct {
+	Query *model.TodoQuery `json:"query"`
 }
 
 func (r ListRequest) validate() error {
+	if r.Query == nil {
+		return nil
+	}
+	if r.Query.Sort != "" {
+		if _, ok := model.TodoSortFields[strings.TrimPrefix(r.Query.Sort, "-")]; !ok {
+			return service.ErrInvalidQueryParams
+		}
+	}
+	switch r.Query.Status {
+	case "", service.ALL, service.ACTIVE, service.COMPLETE:
+	default:
+		return service.ErrInvalidQueryParams
+	}
+	if r.Query.CompletedAfter != nil && r.Query.CompletedBefore != nil && !r.Query.CompletedAfter.Before(*r.Query.CompletedBefore) {
+		return service.ErrInvalidQueryParams
+	}
+	if r.Query.Offset < 0 || r.Query.Limit < 0 {
+		return service.ErrInvalidQueryParams
+	}
+	return validateFields(r.Query.Fields)
+}
+
+// GetRequest collects the request parameters for the Get method.
+type GetRequest struct {
+	Id     string   `json:"id"`
+	Fields []string `json:"fields"`
+}
+
+func (r GetRequest) validate() error {
+	if r.Id == "" {
+		return service.ErrMalformedEntity
+	}
+	return validateFields(r.Fields)
+}
+
+// StatsRequest collects the request parameters for the Stats method.
+type StatsRequest struct {
+	Days int `json:"days"`
+}
+
+func (r StatsRequest) validate() error {
+	return nil
+}
+
+// UnarchiveRequest collects the request parameters for the Unarchive method.
+type UnarchiveRequest struct {
+	Id string `json:"id"`
+}
+
+func (r UnarchiveRequest) validate() error {
+	if r.Id == "" {
+		return service.ErrMalformedEntity
+	}
+	return nil
+}
+
+// SnoozeRequest collects the request parameters for the Snooze method. A zero
+// Until wakes the todo up.
+type SnoozeRequest struct {
+	Id    string    `json:"id"`
+	Until time.Time `json:"until"`
+}
+
+func (r SnoozeRequest) validate() error {
+	if r.Id == "" {
+		return service.ErrMalformedEntity
+	}
+	return nil
+}
+
+// WatchRequest collects the request parameters for the Watch method.
+type WatchRequest struct {
+	Query *model.EventQuery `json:"query"`
+}
+
+func (r WatchRequest) validate() error {
+	return nil // the event types are checked by the service
+}
+
+// SyncRequest collects the request parameters for the Sync method.
+type SyncRequest struct {
+	Token string `json:"token"`
+}
+
+func (r SyncRequest) validate() error {
+	return nil // the token is parsed by the service
+}
+
+// PushRequest collects the request parameters for the Push method.
+type PushRequest struct {
+	Mutations []*model.Mutation `json:"mutations"`
+}
+
+func (r PushRequest) validate() error {
+	if len(r.Mutations) > service.MaxPushMutations {
+		return service.ErrMalformedEntity
+	}
+	return nil // every mutation is checked by the service
+}
+
+// AddShareRequest collects the request parameters for the AddShare method.
+type AddShareRequest struct {
+	Share *model.ShareReq `json:"share"`
+}
+
+func (r AddShareRequest) validate() error {
+	if r.Share == nil || r.Share.GranteeID == "" {
+		return service.ErrMalformedEntity
+	}
+	switch r.Share.Role {
+	case model.RoleViewer, model.RoleEditor:
+		return nil
+	default:
+		return service.ErrMalformedEntity
+	}
+}
+
+// DeleteShareRequest collects the request parameters for the DeleteShare method.
+type DeleteShareRequest struct {
+	Id string `json:"id"`
+}
+
+func (r DeleteShareRequest) validate() error {
+	if r.Id == "" {
+		return service.ErrMalformedEntity
+	}
+	return nil
+}
+
+// ListSharesRequest collects the request parameters for the ListShares method.
+type ListSharesRequest struct{}
+
+func (r ListSharesRequest) validate() error {
+	return nil
+}
+
+// CreateAPIKeyRequest collects the request parameters for the CreateAPIKey method.
+type CreateAPIKeyRequest struct {
+	Key *model.APIKeyReq `json:"key"`
+}
+
+func (r CreateAPIKeyRequest) validate() error {
+	if r.Key == nil || r.Key.Name == "" {
+		return service.ErrMalformedEntity
+	}
+	return nil // the expiry is checked by the service
+}
+
+// RevokeAPIKeyRequest collects the request parameters for the RevokeAPIKey method.
+type RevokeAPIKeyRequest struct {
+	Id string `json:"id"`
+}
+
+func (r RevokeAPIKeyRequest) validate() error {
+	if r.Id == "" {
+		return service.ErrMalformedEntity
+	}
+	return nil
+}
+
+// ListAPIKeysRequest collects the request parameters for the ListAPIKeys method.
+type ListAPIKeysRequest struct{}
+
+func (r ListAPIKeysRequest) validate() error {
+	return nil
+}
+
+// UsageRequest collects the request parameters for the Usage method.
+type UsageRequest struct{}
+
+func (r UsageRequest) validate() error {
+	return nil
+}
+
+// validateFields checks that every requested field is a selectable todo field.
+func validateFields(fields []string) error {
+	for _, f := range fields {
+		if _, ok := model.TodoFields[f]; !ok {
+			return service.ErrInvalidQueryParams
+		}
+	}
 	return nil
 }
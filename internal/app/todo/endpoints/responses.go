@@ -1,6 +1,7 @@
 package endpoints
 
 import (
+	"encoding/json"
 	"net/http"
 
 	httptransport "github.com/go-kit/kit/transport/http"
@@ -26,6 +27,58 @@ var (
 	_ httptransport.Headerer = (*ListResponse)(nil)
 
 	_ httptransport.StatusCoder = (*ListResponse)(nil)
+
+	_ httptransport.Headerer = (*GetResponse)(nil)
+
+	_ httptransport.StatusCoder = (*GetResponse)(nil)
+
+	_ httptransport.Headerer = (*StatsResponse)(nil)
+
+	_ httptransport.StatusCoder = (*StatsResponse)(nil)
+
+	_ httptransport.Headerer = (*UnarchiveResponse)(nil)
+
+	_ httptransport.StatusCoder = (*UnarchiveResponse)(nil)
+
+	_ httptransport.Headerer = (*SnoozeResponse)(nil)
+
+	_ httptransport.StatusCoder = (*SnoozeResponse)(nil)
+
+	_ httptransport.Headerer = (*SyncResponse)(nil)
+
+	_ httptransport.StatusCoder = (*SyncResponse)(nil)
+
+	_ httptransport.Headerer = (*PushResponse)(nil)
+
+	_ httptransport.StatusCoder = (*PushResponse)(nil)
+
+	_ httptransport.Headerer = (*AddShareResponse)(nil)
+
+	_ httptransport.StatusCoder = (*AddShareResponse)(nil)
+
+	_ httptransport.Headerer = (*DeleteShareResponse)(nil)
+
+	_ httptransport.StatusCoder = (*DeleteShareResponse)(nil)
+
+	_ httptransport.Headerer = (*ListSharesResponse)(nil)
+
+	_ httptransport.StatusCoder = (*ListSharesResponse)(nil)
+
+	_ httptransport.Headerer = (*CreateAPIKeyResponse)(nil)
+
+	_ httptransport.StatusCoder = (*CreateAPIKeyResponse)(nil)
+
+	_ httptransport.Headerer = (*RevokeAPIKeyResponse)(nil)
+
+	_ httptransport.StatusCoder = (*RevokeAPIKeyResponse)(nil)
+
+	_ httptransport.Headerer = (*ListAPIKeysResponse)(nil)
+
+	_ httptransport.StatusCoder = (*ListAPIKeysResponse)(nil)
+
+	_ httptransport.Headerer = (*UsageResponse)(nil)
+
+	_ httptransport.StatusCoder = (*UsageResponse)(nil)
 )
 
 // AddResponse collects the response values for the Add method.
@@ -83,8 +136,9 @@ func (r UpdateResponse) Response() interface{} {
 
 // ListResponse collects the response values for the List method.
 type ListResponse struct {
-	Res []*model.TodoRes `json:"res"`
-	Err error            `json:"-"`
+	Res    []*model.TodoRes `json:"res"`
+	Fields []string         `json:"-"`
+	Err    error            `json:"-"`
 }
 
 func (r ListResponse) StatusCode() int {
@@ -96,9 +150,283 @@ func (r ListResponse) Headers() http.Header {
 }
 
 func (r ListResponse) Response() interface{} {
+	if len(r.Fields) == 0 {
+		return responses.DataRes{APIVersion: service.Version, Data: r.Res}
+	}
+
+	data := make([]interface{}, 0, len(r.Res))
+	for _, todo := range r.Res {
+		data = append(data, project(todo, r.Fields))
+	}
+	return responses.DataRes{APIVersion: service.Version, Data: data}
+}
+
+// GetResponse collects the response values for the Get method.
+type GetResponse struct {
+	Res    *model.TodoRes `json:"res"`
+	Fields []string       `json:"-"`
+	Err    error          `json:"-"`
+}
+
+func (r GetResponse) StatusCode() int {
+	return http.StatusOK // TBA
+}
+
+func (r GetResponse) Headers() http.Header {
+	return http.Header{}
+}
+
+func (r GetResponse) Response() interface{} {
+	return responses.DataRes{APIVersion: service.Version, Data: project(r.Res, r.Fields)}
+}
+
+// StatsResponse collects the response values for the Stats method.
+type StatsResponse struct {
+	Res *model.TodoStats `json:"res"`
+	Err error            `json:"-"`
+}
+
+func (r StatsResponse) StatusCode() int {
+	return http.StatusOK // TBA
+}
+
+func (r StatsResponse) Headers() http.Header {
+	return http.Header{}
+}
+
+func (r StatsResponse) Response() interface{} {
+	return responses.DataRes{APIVersion: service.Version, Data: r.Res}
+}
+
+// UnarchiveResponse collects the response values for the Unarchive method.
+type UnarchiveResponse struct {
+	Res *model.TodoRes `json:"res"`
+	Err error          `json:"-"`
+}
+
+func (r UnarchiveResponse) StatusCode() int {
+	return http.StatusOK // TBA
+}
+
+func (r UnarchiveResponse) Headers() http.Header {
+	return http.Header{}
+}
+
+func (r UnarchiveResponse) Response() interface{} {
+	return responses.DataRes{APIVersion: service.Version, Data: r.Res}
+}
+
+// SnoozeResponse collects the response values for the Snooze method.
+type SnoozeResponse struct {
+	Res *model.TodoRes `json:"res"`
+	Err error          `json:"-"`
+}
+
+func (r SnoozeResponse) StatusCode() int {
+	return http.StatusOK // TBA
+}
+
+func (r SnoozeResponse) Headers() http.Header {
+	return http.Header{}
+}
+
+func (r SnoozeResponse) Response() interface{} {
+	return responses.DataRes{APIVersion: service.Version, Data: r.Res}
+}
+
+// WatchResponse collects the response values for the Watch method. Events
+// is closed once the watch ends, it is streamed by the transports rather
+// than encoded as a single response.
+type WatchResponse struct {
+	Events <-chan *model.TodoEvent `json:"-"`
+	Err    error                   `json:"-"`
+}
+
+// SyncResponse collects the response values for the Sync method.
+type SyncResponse struct {
+	Res *model.Changes `json:"res"`
+	Err error          `json:"-"`
+}
+
+func (r SyncResponse) StatusCode() int {
+	return http.StatusOK // TBA
+}
+
+func (r SyncResponse) Headers() http.Header {
+	return http.Header{}
+}
+
+func (r SyncResponse) Response() interface{} {
 	return responses.DataRes{APIVersion: service.Version, Data: r.Res}
 }
 
+// PushResponse collects the response values for the Push method, the result
+// of every mutation is in the order they were pushed.
+type PushResponse struct {
+	Res []*model.MutationResult `json:"res"`
+	Err error                   `json:"-"`
+}
+
+func (r PushResponse) StatusCode() int {
+	return http.StatusOK // TBA
+}
+
+func (r PushResponse) Headers() http.Header {
+	return http.Header{}
+}
+
+func (r PushResponse) Response() interface{} {
+	return responses.DataRes{APIVersion: service.Version, Data: r.Res}
+}
+
+// AddShareResponse collects the response values for the AddShare method.
+type AddShareResponse struct {
+	Res *model.Share `json:"res"`
+	Err error        `json:"-"`
+}
+
+func (r AddShareResponse) StatusCode() int {
+	return http.StatusCreated
+}
+
+func (r AddShareResponse) Headers() http.Header {
+	return http.Header{}
+}
+
+func (r AddShareResponse) Response() interface{} {
+	return responses.DataRes{APIVersion: service.Version, Data: r.Res}
+}
+
+// DeleteShareResponse collects the response values for the DeleteShare method.
+type DeleteShareResponse struct {
+	Err error `json:"-"`
+}
+
+func (r DeleteShareResponse) StatusCode() int {
+	return http.StatusNoContent
+}
+
+func (r DeleteShareResponse) Headers() http.Header {
+	return http.Header{}
+}
+
+func (r DeleteShareResponse) Response() interface{} {
+	return responses.DataRes{APIVersion: service.Version}
+}
+
+// ListSharesResponse collects the response values for the ListShares method.
+type ListSharesResponse struct {
+	Res []*model.Share `json:"res"`
+	Err error          `json:"-"`
+}
+
+func (r ListSharesResponse) StatusCode() int {
+	return http.StatusOK
+}
+
+func (r ListSharesResponse) Headers() http.Header {
+	return http.Header{}
+}
+
+func (r ListSharesResponse) Response() interface{} {
+	return responses.DataRes{APIVersion: service.Version, Data: r.Res}
+}
+
+// CreateAPIKeyResponse collects the response values for the CreateAPIKey method.
+type CreateAPIKeyResponse struct {
+	Res *model.CreatedAPIKey `json:"res"`
+	Err error                `json:"-"`
+}
+
+func (r CreateAPIKeyResponse) StatusCode() int {
+	return http.StatusCreated
+}
+
+func (r CreateAPIKeyResponse) Headers() http.Header {
+	return http.Header{}
+}
+
+func (r CreateAPIKeyResponse) Response() interface{} {
+	return responses.DataRes{APIVersion: service.Version, Data: r.Res}
+}
+
+// RevokeAPIKeyResponse collects the response values for the RevokeAPIKey method.
+type RevokeAPIKeyResponse struct {
+	Err error `json:"-"`
+}
+
+func (r RevokeAPIKeyResponse) StatusCode() int {
+	return http.StatusNoContent
+}
+
+func (r RevokeAPIKeyResponse) Headers() http.Header {
+	return http.Header{}
+}
+
+func (r RevokeAPIKeyResponse) Response() interface{} {
+	return responses.DataRes{APIVersion: service.Version}
+}
+
+// ListAPIKeysResponse collects the response values for the ListAPIKeys method.
+type ListAPIKeysResponse struct {
+	Res []*model.APIKey `json:"res"`
+	Err error           `json:"-"`
+}
+
+func (r ListAPIKeysResponse) StatusCode() int {
+	return http.StatusOK
+}
+
+func (r ListAPIKeysResponse) Headers() http.Header {
+	return http.Header{}
+}
+
+func (r ListAPIKeysResponse) Response() interface{} {
+	return responses.DataRes{APIVersion: service.Version, Data: r.Res}
+}
+
+// UsageResponse collects the response values for the Usage method.
+type UsageResponse struct {
+	Res *model.Usage `json:"res"`
+	Err error        `json:"-"`
+}
+
+func (r UsageResponse) StatusCode() int {
+	return http.StatusOK
+}
+
+func (r UsageResponse) Headers() http.Header {
+	return http.Header{}
+}
+
+func (r UsageResponse) Response() interface{} {
+	return responses.DataRes{APIVersion: service.Version, Data: r.Res}
+}
+
+// project restricts the JSON representation of todo to the given fields.
+func project(todo *model.TodoRes, fields []string) interface{} {
+	if todo == nil || len(fields) == 0 {
+		return todo
+	}
+
+	b, err := json.Marshal(todo)
+	if err != nil {
+		return todo
+	}
+	all := map[string]json.RawMessage{}
+	if err := json.Unmarshal(b, &all); err != nil {
+		return todo
+	}
+
+	res := make(map[string]json.RawMessage, len(fields))
+	for _, f := range fields {
+		if v, ok := all[f]; ok {
+			res[f] = v
+		}
+	}
+	return res
+}
+
 // CompleteAllResponse collects the response values for the CompleteAll method.
 type CompleteAllResponse struct {
 	Err error `json:"-"`
@@ -0,0 +1,41 @@
+package model
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// APIKey authenticates a service-to-service caller as Subject, within the
+// tenant of the administrator who created it, with its Scopes as roles. Only
+// the hash of the key is stored, the key itself is returned on creation.
+type APIKey struct {
+	ID string `gorm:"primaryKey" json:"id"`
+	// OwnerID and TenantID identify the administrator who created the key.
+	OwnerID   string         `gorm:"not null;default:''" json:"ownerId"`
+	TenantID  string         `gorm:"not null;default:'';index" json:"tenantId,omitempty"`
+	Name      string         `gorm:"not null" json:"name"`
+	Subject   string         `gorm:"not null" json:"subject"`
+	Hash      string         `gorm:"not null;uniqueIndex" json:"-"`
+	Scopes    pq.StringArray `gorm:"type:text[]" json:"scopes"`
+	ExpiresAt *time.Time     `json:"expiresAt"`
+	// LastUsedAt is updated at most once per service.APIKeyTouchInterval.
+	LastUsedAt *time.Time `json:"lastUsedAt"`
+	CreatedAt  time.Time  `json:"createdAt"`
+}
+
+// APIKeyReq describes the API key to create, the key authenticates as the
+// caller unless Subject is set, and never expires unless ExpiresAt is set.
+type APIKeyReq struct {
+	Name      string     `json:"name"`
+	Subject   string     `json:"subject"`
+	Scopes    []string   `json:"scopes"`
+	ExpiresAt *time.Time `json:"expiresAt"`
+}
+
+// CreatedAPIKey is a new API key along with the key itself, which cannot be
+// read again.
+type CreatedAPIKey struct {
+	APIKey
+	Key string `json:"key"`
+}
@@ -0,0 +1,21 @@
+package model
+
+// Quota caps the todos stored by the callers of a tenant, the zero limits are
+// unlimited.
+type Quota struct {
+	// Todos is the maximum number of todos of a caller, archived ones
+	// included.
+	Todos int64 `json:"todos"`
+	// TenantTodos is the maximum number of todos of all the callers of the
+	// tenant.
+	TenantTodos int64 `json:"tenantTodos"`
+	// TextLength is the maximum length of the text of a todo, in characters.
+	TextLength int `json:"textLength"`
+}
+
+// Usage is how much of its Quota a caller uses.
+type Usage struct {
+	Todos       int64 `json:"todos"`
+	TenantTodos int64 `json:"tenantTodos"`
+	Quota       Quota `json:"quota"`
+}
@@ -0,0 +1,37 @@
+package model
+
+import "time"
+
+// The roles a share grants on the shared todos.
+const (
+	// RoleViewer lets the grantee read the shared todos.
+	RoleViewer = "viewer"
+	// RoleEditor also lets the grantee change and delete them.
+	RoleEditor = "editor"
+)
+
+// Share grants a user of the tenant of its owner access to a todo of the
+// owner, or to all of them when TodoID is empty. An owner shares a todo at
+// most once with a grantee, sharing it again changes the role.
+type Share struct {
+	ID        string    `gorm:"primaryKey" json:"id"`
+	OwnerID   string    `gorm:"not null;default:'';uniqueIndex:idx_shares_target,priority:2" json:"ownerId"`
+	TenantID  string    `gorm:"not null;default:'';uniqueIndex:idx_shares_target,priority:1;index:idx_shares_grantee,priority:1" json:"tenantId,omitempty"`
+	TodoID    string    `gorm:"not null;default:'';uniqueIndex:idx_shares_target,priority:3" json:"todoId,omitempty"`
+	GranteeID string    `gorm:"not null;uniqueIndex:idx_shares_target,priority:4;index:idx_shares_grantee,priority:2" json:"granteeId"`
+	Role      string    `gorm:"not null" json:"role"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// Owner returns the owner of the share.
+func (s *Share) Owner() Owner {
+	return Owner{ID: s.OwnerID, TenantID: s.TenantID}
+}
+
+// ShareReq is the share of a todo, or of all the todos of the caller when
+// TodoID is empty, with a grantee.
+type ShareReq struct {
+	TodoID    string `json:"todoId"`
+	GranteeID string `json:"granteeId"`
+	Role      string `json:"role"`
+}
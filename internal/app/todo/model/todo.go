@@ -7,32 +7,68 @@ import (
 )
 
 type Todo struct {
-	ID        string    `gorm:"primaryKey" json:"id"`
-	CreatedAt time.Time `json:"createdAt"`
-	UpdatedAt time.Time `json:"updatedAt"`
-	Text      string    `json:"text"`
-	Completed bool      `json:"completed"`
+	ID string `gorm:"primaryKey" json:"id"`
+	// OwnerID and TenantID identify the Owner of the todo, which is only
+	// visible to them.
+	OwnerID      string     `gorm:"not null;default:'';index:idx_todos_owner,priority:2" json:"ownerId,omitempty"`
+	TenantID     string     `gorm:"not null;default:'';index:idx_todos_owner,priority:1" json:"tenantId,omitempty"`
+	CreatedAt    time.Time  `json:"createdAt"`
+	UpdatedAt    time.Time  `json:"updatedAt"`
+	Text         string     `json:"text"`
+	Completed    bool       `json:"completed"`
+	CompletedAt  *time.Time `gorm:"index" json:"completedAt"`
+	ArchivedAt   *time.Time `gorm:"index" json:"archivedAt"`
+	SnoozedUntil *time.Time `gorm:"index" json:"snoozedUntil"`
+	// Version increases with every change of any todo, it is assigned by
+	// the database and used by clients to sync their changes.
+	Version uint64 `gorm:"default:nextval('todo_changes_seq');index" json:"version"`
+	// TxID is the id of the transaction which made the last change, it is
+	// assigned by the database and orders the changes, see ChangeCursor.
+	TxID uint64 `gorm:"->;not null;default:0;index" json:"-"`
+}
+
+// Cursor returns the position of the last change of the todo.
+func (p *Todo) Cursor() ChangeCursor {
+	return ChangeCursor{TxID: p.TxID, Version: p.Version}
 }
 
 func (p Todo) MarshalJSON() ([]byte, error) {
 	type Alias Todo
 	return json.Marshal(&struct {
 		Alias
-		UpdatedAt string `json:"updatedAt"`
-		CreatedAt string `json:"createdAt"`
+		UpdatedAt    string  `json:"updatedAt"`
+		CreatedAt    string  `json:"createdAt"`
+		CompletedAt  *string `json:"completedAt"`
+		ArchivedAt   *string `json:"archivedAt"`
+		SnoozedUntil *string `json:"snoozedUntil"`
 	}{
-		Alias:     (Alias)(p),
-		UpdatedAt: p.UpdatedAt.Format(time.RFC3339),
-		CreatedAt: p.CreatedAt.Format(time.RFC3339),
+		Alias:        (Alias)(p),
+		UpdatedAt:    p.UpdatedAt.Format(time.RFC3339),
+		CreatedAt:    p.CreatedAt.Format(time.RFC3339),
+		CompletedAt:  formatTime(p.CompletedAt),
+		ArchivedAt:   formatTime(p.ArchivedAt),
+		SnoozedUntil: formatTime(p.SnoozedUntil),
 	})
 }
 
+// formatTime formats an optional time as RFC3339.
+func formatTime(t *time.Time) *string {
+	if t == nil {
+		return nil
+	}
+	s := t.Format(time.RFC3339)
+	return &s
+}
+
 func (t *Todo) UnmarshalJSON(data []byte) error {
 	type Alias Todo
 
 	pr := &struct {
-		CreatedAt string `json:"createdAt"`
-		UpdatedAt string `json:"updatedAt"`
+		CreatedAt    string  `json:"createdAt"`
+		UpdatedAt    string  `json:"updatedAt"`
+		CompletedAt  *string `json:"completedAt"`
+		ArchivedAt   *string `json:"archivedAt"`
+		SnoozedUntil *string `json:"snoozedUntil"`
 		*Alias
 	}{
 		Alias: (*Alias)(t),
@@ -44,17 +80,76 @@ func (t *Todo) UnmarshalJSON(data []byte) error {
 
 	t.UpdatedAt = time.Time{}
 	t.CreatedAt = time.Time{}
+	t.CompletedAt = nil
+	t.ArchivedAt = nil
+	t.SnoozedUntil = nil
 
 	return nil
 }
 
+// Owner identifies the caller owning todos. The zero Owner owns the todos
+// of the unauthenticated callers.
+type Owner struct {
+	ID       string
+	TenantID string
+}
+
+// Owner returns the owner of the todo.
+func (p *Todo) Owner() Owner {
+	return Owner{ID: p.OwnerID, TenantID: p.TenantID}
+}
+
+// TodoRepository stores the todos, their shares and the API keys. Every todo
+// method but Archive is scoped to a single owner, the todos of the others are
+// reported as service.ErrNotFound.
+//
 //go:generate mockgen -destination ../../../../internal/mocks/app/todo/model/todo.go -package=automocks . TodoRepository
 type TodoRepository interface {
+	// Add stores todo for its owner, it returns service.ErrConflict if the
+	// id of todo is taken, be it by a todo of another owner.
 	Add(context.Context, *Todo) error
-	Delete(context.Context, string) error
+	// AddWithin adds todo as Add does unless its owner or their tenant
+	// already have the todos of quota, returning service.ErrQuotaExceeded.
+	// The todos are counted and todo is inserted atomically, so that the
+	// concurrent adds do not go past quota together.
+	AddWithin(ctx context.Context, todo *Todo, quota Quota) error
+	// Delete and Update only apply to the given version of the todo, unless
+	// it is zero, and return service.ErrConflict otherwise. Update applies to
+	// the todo of the owner of todo.
+	Delete(ctx context.Context, owner Owner, todoID string, version uint64) error
 	Update(context.Context, *Todo) error
-	List(context.Context) (res []*Todo, err error)
-	Get(context.Context, string) (res *Todo, err error)
+	List(context.Context, *TodoQuery) (res []*Todo, err error)
+	Get(ctx context.Context, owner Owner, todoID string) (res *Todo, err error)
+	Stats(ctx context.Context, owner Owner, since time.Time) (res *TodoStats, err error)
+	// Usage counts the todos of owner and of their tenant, its Quota is
+	// left empty.
+	Usage(ctx context.Context, owner Owner) (res *Usage, err error)
+	// Archive archives the todos completed before completedBefore, unless
+	// they were updated since, such as unarchived.
+	Archive(ctx context.Context, completedBefore time.Time, archivedAt time.Time) (n int64, err error)
+	// Changes returns, in the order of their cursors, up to limit todos and
+	// up to limit tombstones changed after the since cursor by the
+	// transactions which ended. The changes of the transactions still
+	// running, which may end in any order, are left for a later call.
+	Changes(ctx context.Context, owner Owner, since ChangeCursor, limit int) (todos []*Todo, tombstones []*Tombstone, err error)
+	// AddShare stores share for its owner, or updates the role of the share
+	// of the same todo with the same grantee, and reads back its id and
+	// creation time.
+	AddShare(context.Context, *Share) error
+	DeleteShare(ctx context.Context, owner Owner, shareID string) error
+	// ListShares lists the shares made by owner and the ones made with them.
+	ListShares(ctx context.Context, owner Owner) (res []*Share, err error)
+	// Grants returns the shares giving grantee access to the todo todoID, be
+	// it shared on its own or along with the other todos of its owner.
+	Grants(ctx context.Context, grantee Owner, todoID string) (res []*Share, err error)
+	AddAPIKey(context.Context, *APIKey) error
+	// DeleteAPIKey and ListAPIKeys are scoped to the keys of a tenant.
+	DeleteAPIKey(ctx context.Context, tenantID string, keyID string) error
+	ListAPIKeys(ctx context.Context, tenantID string) (res []*APIKey, err error)
+	// APIKey returns the key of any tenant with the given hash.
+	APIKey(ctx context.Context, hash string) (res *APIKey, err error)
+	// TouchAPIKey records that the key keyID was used at the given time.
+	TouchAPIKey(ctx context.Context, keyID string, at time.Time) error
 }
 
 type TodoReq struct {
@@ -63,3 +158,188 @@ type TodoReq struct {
 }
 
 type TodoRes Todo
+
+// TodoFields maps the selectable field names of a Todo, as they appear in
+// its JSON representation, to their database columns.
+var TodoFields = map[string]string{
+	"id":           "id",
+	"ownerId":      "owner_id",
+	"tenantId":     "tenant_id",
+	"createdAt":    "created_at",
+	"updatedAt":    "updated_at",
+	"text":         "text",
+	"completed":    "completed",
+	"completedAt":  "completed_at",
+	"archivedAt":   "archived_at",
+	"snoozedUntil": "snoozed_until",
+	"version":      "version",
+}
+
+// TodoSortFields maps the todo fields a listing can be sorted by to their
+// database columns. A leading "-" on the sort field sorts in descending order.
+var TodoSortFields = map[string]string{
+	"createdAt":    "created_at",
+	"updatedAt":    "updated_at",
+	"completedAt":  "completed_at",
+	"snoozedUntil": "snoozed_until",
+}
+
+// TodoQuery collects the options used to list todos.
+type TodoQuery struct {
+	// Fields restricts the returned todos to the given fields, all fields
+	// are returned if empty.
+	Fields []string `json:"fields"`
+	// CompletedAfter and CompletedBefore restrict the listing to the todos
+	// completed within [CompletedAfter, CompletedBefore).
+	CompletedAfter  *time.Time `json:"completedAfter"`
+	CompletedBefore *time.Time `json:"completedBefore"`
+	// Sort is one of TodoSortFields, optionally prefixed by "-", the newest
+	// todos are listed first if empty.
+	Sort string `json:"sort"`
+	// IncludeArchived lists the archived todos along with the others, and
+	// IncludeSnoozed the ones snoozed past Now.
+	IncludeArchived bool `json:"includeArchived"`
+	IncludeSnoozed  bool `json:"includeSnoozed"`
+	// Status is one of all, active or complete, all todos are listed if
+	// empty.
+	Status string `json:"status"`
+	// Offset skips that many todos and Limit lists at most that many todos,
+	// all todos are listed if Limit is zero.
+	Offset int `json:"offset"`
+	Limit  int `json:"limit"`
+	// Shared lists the todos the others share with the caller instead of
+	// the todos of the caller.
+	Shared bool `json:"shared"`
+	// Now is the time snoozed todos are compared against, and Owner the
+	// owner of the listed todos, they are set by the service rather than by
+	// the caller.
+	Now   time.Time `json:"-"`
+	Owner Owner     `json:"-"`
+}
+
+// The types of the events published when a todo changes.
+const (
+	EventCreated = "created"
+	EventUpdated = "updated"
+	EventDeleted = "deleted"
+)
+
+// TodoEvent describes a change of a todo. Deleted events only carry the id
+// of the deleted todo.
+type TodoEvent struct {
+	// Seq identifies the event, it is used by watchers to resume after the
+	// last event they received. It increases with every event, but the
+	// events of every replica are delivered in commit order, which may not
+	// be the order of their numbers.
+	Seq  uint64    `json:"seq"`
+	Type string    `json:"type"`
+	Todo *TodoRes  `json:"todo"`
+	Time time.Time `json:"time"`
+}
+
+// EventQuery collects the options used to watch todo events.
+type EventQuery struct {
+	// Types restricts the events to the given types, all events are
+	// watched if empty.
+	Types []string `json:"types"`
+	// Since resumes watching after the event with that sequence number,
+	// only new events are watched if zero.
+	Since uint64 `json:"since"`
+}
+
+// Tombstone records the deletion of a todo, so that the clients syncing
+// after it learn about it.
+type Tombstone struct {
+	ID        string    `gorm:"primaryKey" json:"id"`
+	OwnerID   string    `gorm:"not null;default:'';index:idx_tombstones_owner,priority:2" json:"-"`
+	TenantID  string    `gorm:"not null;default:'';index:idx_tombstones_owner,priority:1" json:"-"`
+	Version   uint64    `gorm:"index" json:"version"`
+	TxID      uint64    `gorm:"not null;default:0;index" json:"-"`
+	DeletedAt time.Time `json:"deletedAt"`
+}
+
+// Cursor returns the position of the deletion.
+func (p *Tombstone) Cursor() ChangeCursor {
+	return ChangeCursor{TxID: p.TxID, Version: p.Version}
+}
+
+// ChangeCursor is the position of a change of the todos. The changes are
+// ordered by the transaction which made them, then by version, so that a
+// change made by a transaction ending late is not ordered before the
+// changes already synced.
+type ChangeCursor struct {
+	TxID    uint64
+	Version uint64
+}
+
+// Before reports whether the change at c comes before the one at d.
+func (c ChangeCursor) Before(d ChangeCursor) bool {
+	return c.TxID < d.TxID || c.TxID == d.TxID && c.Version < d.Version
+}
+
+// Changes collects the todos changed and deleted since a sync token.
+type Changes struct {
+	Todos   []*TodoRes   `json:"todos"`
+	Deleted []*Tombstone `json:"deleted"`
+	// Token is passed to the next sync to get the changes made after these.
+	Token string `json:"token"`
+	// More is set when more changes are available, they are synced again
+	// with Token right away.
+	More bool `json:"more"`
+}
+
+// The operations of a mutation pushed by a client.
+const (
+	MutationCreate = "create"
+	MutationUpdate = "update"
+	MutationDelete = "delete"
+)
+
+// Mutation is a change made by a client while offline.
+type Mutation struct {
+	Op string `json:"op"`
+	// ID is the id of the todo, it is chosen by the client on creation as
+	// 21 characters among A-Z, a-z, 0-9, _ and -, as the service does.
+	ID string `json:"id"`
+	// Version is the version of the todo the client changed, the mutation
+	// conflicts if the todo changed since. When zero, the mutation conflicts
+	// if the todo was updated after UpdatedAt, if any.
+	Version   uint64     `json:"version"`
+	UpdatedAt *time.Time `json:"updatedAt"`
+	Todo      *TodoReq   `json:"todo"`
+}
+
+// The statuses of a pushed mutation.
+const (
+	MutationApplied  = "applied"
+	MutationConflict = "conflict"
+	MutationRejected = "rejected"
+)
+
+// MutationResult tells whether a pushed mutation was applied.
+type MutationResult struct {
+	ID     string `json:"id"`
+	Status string `json:"status"`
+	// Todo is the todo as stored after the mutation was applied, or the one
+	// it conflicted with, it is nil once the todo is deleted.
+	Todo *TodoRes `json:"todo"`
+	// Error tells why a mutation was rejected.
+	Error string `json:"error,omitempty"`
+}
+
+// DayCount is the number of todos for a single day, formatted as YYYY-MM-DD.
+type DayCount struct {
+	Day   string `json:"day"`
+	Count int64  `json:"count"`
+}
+
+// TodoStats collects the aggregated counts and completion trends of todos.
+type TodoStats struct {
+	Total           int64       `json:"total"`
+	Active          int64       `json:"active"`
+	Completed       int64       `json:"completed"`
+	CompletedPerDay []*DayCount `json:"completedPerDay"`
+	// AvgTimeToComplete is the average number of seconds between the
+	// creation and the completion of the completed todos.
+	AvgTimeToComplete float64 `json:"avgTimeToComplete"`
+}
@@ -0,0 +1,60 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/cage1016/gokit-todo/internal/app/todo/model"
+	"github.com/cage1016/gokit-todo/internal/app/todo/service"
+)
+
+func (repo *todoRepository) AddAPIKey(ctx context.Context, key *model.APIKey) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	return repo.db.WithContext(ctx).Create(key).Error
+}
+
+func (repo *todoRepository) DeleteAPIKey(ctx context.Context, tenantID string, keyID string) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	result := repo.db.WithContext(ctx).Where("tenant_id = ?", tenantID).Delete(&model.APIKey{ID: keyID})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return service.ErrNotFound
+	}
+	return nil
+}
+
+func (repo *todoRepository) ListAPIKeys(ctx context.Context, tenantID string) (res []*model.APIKey, err error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	err = repo.db.WithContext(ctx).Where("tenant_id = ?", tenantID).Order("created_at").Find(&res).Error
+	return
+}
+
+func (repo *todoRepository) APIKey(ctx context.Context, hash string) (res *model.APIKey, err error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	res = new(model.APIKey)
+	result := repo.db.WithContext(ctx).Where("hash = ?", hash).Find(res)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, service.ErrNotFound
+	}
+	return res, nil
+}
+
+func (repo *todoRepository) TouchAPIKey(ctx context.Context, keyID string, at time.Time) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	return repo.db.WithContext(ctx).Model(&model.APIKey{ID: keyID}).Update("last_used_at", at).Error
+}
@@ -0,0 +1,155 @@
+// +build !integration
+
+package postgres_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-kit/kit/log"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+
+	"github.com/cage1016/gokit-todo/internal/app/todo/model"
+	psql "github.com/cage1016/gokit-todo/internal/app/todo/postgres"
+	"github.com/cage1016/gokit-todo/internal/app/todo/service"
+)
+
+func TestTodoRepository_DeleteAPIKey(t *testing.T) {
+	type fields struct {
+		mock sqlmock.Sqlmock
+	}
+
+	type args struct {
+		keyID string
+	}
+
+	tests := []struct {
+		name      string
+		prepare   func(f *fields)
+		args      args
+		wantErr   bool
+		checkFunc func(err error)
+	}{
+		{
+			name: "Delete APIKey",
+			prepare: func(f *fields) {
+				f.mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "api_keys" WHERE tenant_id = $1 AND "api_keys"."id" = $2`)).
+					WithArgs(owner.TenantID, "dlyW8C0ypdYd0tiJ0KbcN").WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			args:    args{keyID: "dlyW8C0ypdYd0tiJ0KbcN"},
+			wantErr: false,
+		},
+		{
+			name: "Delete APIKey fail of another tenant",
+			prepare: func(f *fields) {
+				f.mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "api_keys" WHERE tenant_id = $1 AND "api_keys"."id" = $2`)).
+					WithArgs(owner.TenantID, "dlyW8C0ypdYd0tiJ0KbcN").WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			args:    args{keyID: "dlyW8C0ypdYd0tiJ0KbcN"},
+			wantErr: true,
+			checkFunc: func(err error) {
+				assert.Equal(t, service.ErrNotFound, err, fmt.Sprintf("err: expected service.ErrNotFound got %v", err))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+			}
+			defer db.Close()
+
+			f := fields{
+				mock: mock,
+			}
+			if tt.prepare != nil {
+				tt.prepare(&f)
+			}
+			gdb, err := gorm.Open(postgres.New(postgres.Config{Conn: db}), &gorm.Config{})
+			repo := psql.New(gdb, log.NewLogfmtLogger(os.Stderr))
+
+			if err := repo.DeleteAPIKey(context.Background(), owner.TenantID, tt.args.keyID); (err != nil) != tt.wantErr {
+				t.Errorf("DeleteAPIKey(ctx context.Context, tenantID string, keyID string) error = %v, wantErr %v", err, tt.wantErr)
+			} else {
+				if tt.checkFunc != nil {
+					tt.checkFunc(err)
+				}
+			}
+		})
+	}
+}
+
+func TestTodoRepository_APIKey(t *testing.T) {
+	type fields struct {
+		mock sqlmock.Sqlmock
+	}
+
+	tests := []struct {
+		name      string
+		prepare   func(f *fields)
+		wantErr   bool
+		checkFunc func(res *model.APIKey, err error)
+	}{
+		{
+			name: "Get APIKey by hash",
+			prepare: func(f *fields) {
+				f.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "api_keys" WHERE hash = $1`)).
+					WithArgs("7a3e").
+					WillReturnRows(sqlmock.NewRows([]string{"id", "tenant_id", "subject", "hash", "scopes"}).
+						AddRow("dlyW8C0ypdYd0tiJ0KbcN", owner.TenantID, "ci", "7a3e", "{editor,viewer}"))
+			},
+			wantErr: false,
+			checkFunc: func(res *model.APIKey, err error) {
+				assert.Equal(t, "ci", res.Subject, fmt.Sprintf("subject: expected ci got %v", res.Subject))
+				assert.Equal(t, []string{"editor", "viewer"}, []string(res.Scopes), fmt.Sprintf("scopes: expected [editor viewer] got %v", res.Scopes))
+			},
+		},
+		{
+			name: "Get APIKey fail of unknown hash",
+			prepare: func(f *fields) {
+				f.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "api_keys" WHERE hash = $1`)).
+					WithArgs("7a3e").
+					WillReturnRows(sqlmock.NewRows([]string{"id"}))
+			},
+			wantErr: true,
+			checkFunc: func(res *model.APIKey, err error) {
+				assert.Equal(t, service.ErrNotFound, err, fmt.Sprintf("err: expected service.ErrNotFound got %v", err))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+			}
+			defer db.Close()
+
+			f := fields{
+				mock: mock,
+			}
+			if tt.prepare != nil {
+				tt.prepare(&f)
+			}
+			gdb, err := gorm.Open(postgres.New(postgres.Config{Conn: db}), &gorm.Config{})
+			repo := psql.New(gdb, log.NewLogfmtLogger(os.Stderr))
+
+			if res, err := repo.APIKey(context.Background(), "7a3e"); (err != nil) != tt.wantErr {
+				t.Errorf("APIKey(ctx context.Context, hash string) error = %v, wantErr %v", err, tt.wantErr)
+			} else {
+				if tt.checkFunc != nil {
+					tt.checkFunc(res, err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,38 @@
+package postgres
+
+// changesSequenceSQL creates the sequence the todo versions are taken from,
+// it has to exist before the todos table is migrated.
+const changesSequenceSQL = `CREATE SEQUENCE IF NOT EXISTS todo_changes_seq`
+
+// changesTriggerSQL installs the trigger assigning the next version and the
+// id of the transaction to every todo written, and recording a tombstone for
+// every todo deleted, whose own shares are dropped. The versions are taken
+// when written, not in commit order, the transaction ids let the readers skip
+// the changes of the transactions still running, see model.ChangeCursor.
+// txid_current is extended with an epoch, so it does not wrap around.
+const changesTriggerSQL = `
+CREATE OR REPLACE FUNCTION version_todo() RETURNS trigger AS $$
+BEGIN
+	IF TG_OP = 'DELETE' THEN
+		INSERT INTO tombstones (id, owner_id, tenant_id, version, tx_id, deleted_at) VALUES (OLD.id, OLD.owner_id, OLD.tenant_id, nextval('todo_changes_seq'), txid_current(), now())
+			ON CONFLICT (id) DO UPDATE SET owner_id = EXCLUDED.owner_id, tenant_id = EXCLUDED.tenant_id, version = EXCLUDED.version, tx_id = EXCLUDED.tx_id, deleted_at = EXCLUDED.deleted_at;
+		DELETE FROM shares WHERE todo_id = OLD.id;
+		RETURN OLD;
+	END IF;
+	IF TG_OP = 'INSERT' THEN
+		DELETE FROM tombstones WHERE id = NEW.id;
+	END IF;
+	NEW.version := nextval('todo_changes_seq');
+	NEW.tx_id := txid_current();
+	RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS todo_versions ON todos;
+CREATE TRIGGER todo_versions BEFORE INSERT OR UPDATE OR DELETE ON todos
+	FOR EACH ROW EXECUTE PROCEDURE version_todo();
+`
+
+// changesEndedSQL restricts the changes to those of the transactions which
+// ended, the older ones than every transaction still running.
+const changesEndedSQL = `tx_id < txid_snapshot_xmin(txid_current_snapshot())`
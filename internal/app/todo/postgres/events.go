@@ -0,0 +1,170 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/lib/pq"
+
+	"github.com/cage1016/gokit-todo/internal/app/todo/model"
+	"github.com/cage1016/gokit-todo/internal/app/todo/service"
+)
+
+const (
+	// eventsChannel is the channel the todo events are notified on.
+	eventsChannel = "todo_events"
+
+	minReconnectInterval = time.Second
+	maxReconnectInterval = time.Minute
+	// listenerPingInterval is how often the listener connection is checked
+	// while no notification arrives.
+	listenerPingInterval = 90 * time.Second
+)
+
+// eventsTriggerSQL installs the trigger notifying every write to the todos
+// table on eventsChannel. The events are numbered when written, so their
+// numbers are unique but not in commit order, while the notifications are
+// delivered in commit order. A todo too large for a notification is notified
+// by id only and read back by the listener.
+const eventsTriggerSQL = `
+CREATE SEQUENCE IF NOT EXISTS todo_events_seq;
+
+CREATE OR REPLACE FUNCTION notify_todo_event() RETURNS trigger AS $$
+DECLARE
+	rec todos;
+	payload jsonb;
+BEGIN
+	IF TG_OP = 'DELETE' THEN
+		rec := OLD;
+	ELSE
+		rec := NEW;
+	END IF;
+	payload := jsonb_build_object(
+		'seq', nextval('todo_events_seq'),
+		'type', CASE TG_OP WHEN 'INSERT' THEN 'created' WHEN 'UPDATE' THEN 'updated' ELSE 'deleted' END,
+		'time', now(),
+		'todo', CASE TG_OP WHEN 'DELETE' THEN jsonb_build_object('id', rec.id, 'owner_id', rec.owner_id, 'tenant_id', rec.tenant_id) ELSE to_jsonb(rec) END
+	);
+	IF octet_length(payload::text) > 7900 THEN
+		payload := payload || jsonb_build_object('todo', jsonb_build_object('id', rec.id, 'owner_id', rec.owner_id, 'tenant_id', rec.tenant_id), 'partial', true);
+	END IF;
+	PERFORM pg_notify('` + eventsChannel + `', payload::text);
+	RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS todo_events ON todos;
+CREATE TRIGGER todo_events AFTER INSERT OR UPDATE OR DELETE ON todos
+	FOR EACH ROW EXECUTE PROCEDURE notify_todo_event();
+`
+
+// dropEventsTriggerSQL removes the trigger of eventsTriggerSQL, the writes
+// are not notified when no replica listens to them.
+const dropEventsTriggerSQL = `DROP TRIGGER IF EXISTS todo_events ON todos`
+
+// notification is the payload of a todo event notification, the todo is
+// the row of the todos table.
+type notification struct {
+	Seq     uint64    `json:"seq"`
+	Type    string    `json:"type"`
+	Time    time.Time `json:"time"`
+	Partial bool      `json:"partial"`
+	Todo    struct {
+		ID           string     `json:"id"`
+		OwnerID      string     `json:"owner_id"`
+		TenantID     string     `json:"tenant_id"`
+		CreatedAt    time.Time  `json:"created_at"`
+		UpdatedAt    time.Time  `json:"updated_at"`
+		Text         string     `json:"text"`
+		Completed    bool       `json:"completed"`
+		CompletedAt  *time.Time `json:"completed_at"`
+		ArchivedAt   *time.Time `json:"archived_at"`
+		SnoozedUntil *time.Time `json:"snoozed_until"`
+		Version      uint64     `json:"version"`
+		TxID         uint64     `json:"tx_id"`
+	} `json:"todo"`
+}
+
+// EventListener feeds an event bus with the todo events notified by the
+// database, which include the writes made by every replica.
+type EventListener struct {
+	dsn    string
+	bus    *service.EventBus
+	repo   model.TodoRepository
+	logger log.Logger
+}
+
+// NewEventListener returns an EventListener forwarding the events of the
+// database of cfg to bus, repo reads back the todos notified by id only.
+func NewEventListener(cfg Config, bus *service.EventBus, repo model.TodoRepository, logger log.Logger) *EventListener {
+	return &EventListener{
+		dsn:    cfg.dsn(),
+		bus:    bus,
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+// Run listens to the todo events until ctx is cancelled. The connection is
+// re-established whenever it is lost.
+func (l *EventListener) Run(ctx context.Context) error {
+	listener := pq.NewListener(l.dsn, minReconnectInterval, maxReconnectInterval, func(ev pq.ListenerEventType, err error) {
+		if err != nil {
+			level.Error(l.logger).Log("listener", ev, "err", err)
+		}
+	})
+	defer listener.Close()
+
+	if err := listener.Listen(eventsChannel); err != nil {
+		return err
+	}
+
+	ping := time.NewTicker(listenerPingInterval)
+	defer ping.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case n := <-listener.Notify:
+			l.Handle(ctx, n)
+		case <-ping.C:
+			if err := listener.Ping(); err != nil {
+				level.Error(l.logger).Log("listener", "ping", "err", err)
+			}
+		}
+	}
+}
+
+// Handle forwards the event of n to the event bus, the notifications arrive
+// in commit order. A nil notification tells the connection was re-established,
+// events may have been missed meanwhile so the watchers are reset, as they
+// are when the event of n is lost.
+func (l *EventListener) Handle(ctx context.Context, n *pq.Notification) {
+	if n == nil {
+		level.Info(l.logger).Log("listener", "reconnected", "msg", "resetting the watchers")
+		l.bus.Reset()
+		return
+	}
+
+	var p notification
+	if err := json.Unmarshal([]byte(n.Extra), &p); err != nil {
+		level.Error(l.logger).Log("listener", "decode", "err", err, "msg", "resetting the watchers")
+		l.bus.Reset()
+		return
+	}
+
+	todo := model.TodoRes(p.Todo)
+	if p.Partial {
+		if t, err := l.repo.Get(ctx, model.Owner{ID: p.Todo.OwnerID, TenantID: p.Todo.TenantID}, p.Todo.ID); err == nil {
+			todo = model.TodoRes(*t)
+		} else {
+			level.Error(l.logger).Log("listener", "get", "id", p.Todo.ID, "err", err)
+		}
+	}
+
+	l.bus.Forward(&model.TodoEvent{Seq: p.Seq, Type: p.Type, Todo: &todo, Time: p.Time})
+}
@@ -0,0 +1,132 @@
+// +build !integration
+
+package postgres_test
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/golang/mock/gomock"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/cage1016/gokit-todo/internal/app/todo/model"
+	psql "github.com/cage1016/gokit-todo/internal/app/todo/postgres"
+	"github.com/cage1016/gokit-todo/internal/app/todo/service"
+	automocks "github.com/cage1016/gokit-todo/internal/mocks/app/todo/model"
+)
+
+func TestEventListener_Handle(t *testing.T) {
+	type fields struct {
+		repo *automocks.MockTodoRepository
+		bus  *service.EventBus
+	}
+
+	tests := []struct {
+		name          string
+		prepare       func(f *fields)
+		notifications []*pq.Notification
+		checkFunc     func(events <-chan *model.TodoEvent)
+	}{
+		{
+			name: "forward notified events",
+			notifications: []*pq.Notification{
+				{Extra: `{"seq":4,"type":"created","time":"2021-01-01T00:00:00+00:00","todo":{"id":"iKe0KxpurIn0E_6vzUDAr","text":"aa","completed":false,"created_at":"2021-01-01T00:00:00.123456+00:00","updated_at":"2021-01-01T00:00:00.123456+00:00","completed_at":null,"archived_at":null,"snoozed_until":null}}`},
+				{Extra: `{"seq":5,"type":"deleted","time":"2021-01-01T00:00:01+00:00","todo":{"id":"iKe0KxpurIn0E_6vzUDAr"}}`},
+			},
+			checkFunc: func(events <-chan *model.TodoEvent) {
+				ev := <-events
+				assert.Equal(t, uint64(4), ev.Seq)
+				assert.Equal(t, model.EventCreated, ev.Type)
+				assert.Equal(t, "aa", ev.Todo.Text)
+				assert.Equal(t, 123456000, ev.Todo.CreatedAt.Nanosecond())
+				ev = <-events
+				assert.Equal(t, uint64(5), ev.Seq)
+				assert.Equal(t, model.EventDeleted, ev.Type)
+				assert.Equal(t, "iKe0KxpurIn0E_6vzUDAr", ev.Todo.ID)
+			},
+		},
+		{
+			name: "forward partially notified events",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.repo.EXPECT().Get(gomock.Any(), model.Owner{}, "iKe0KxpurIn0E_6vzUDAr").Return(&model.Todo{ID: "iKe0KxpurIn0E_6vzUDAr", Text: "aa"}, nil),
+				)
+			},
+			notifications: []*pq.Notification{
+				{Extra: `{"seq":4,"type":"updated","time":"2021-01-01T00:00:00+00:00","partial":true,"todo":{"id":"iKe0KxpurIn0E_6vzUDAr"}}`},
+			},
+			checkFunc: func(events <-chan *model.TodoEvent) {
+				ev := <-events
+				assert.Equal(t, "aa", ev.Todo.Text)
+			},
+		},
+		{
+			name: "reset the watchers on reconnection",
+			notifications: []*pq.Notification{
+				{Extra: `{"seq":4,"type":"deleted","time":"2021-01-01T00:00:00+00:00","todo":{"id":"iKe0KxpurIn0E_6vzUDAr"}}`},
+				nil,
+			},
+			checkFunc: func(events <-chan *model.TodoEvent) {
+				<-events
+				_, ok := <-events
+				assert.False(t, ok, "watcher: expected to be disconnected")
+			},
+		},
+		{
+			name: "forward events in commit order",
+			notifications: []*pq.Notification{
+				{Extra: `{"seq":5,"type":"deleted","time":"2021-01-01T00:00:00+00:00","todo":{"id":"iKe0KxpurIn0E_6vzUDAr"}}`},
+				{Extra: `{"seq":4,"type":"deleted","time":"2021-01-01T00:00:00+00:00","todo":{"id":"zIYPEK0zEpUc7CoQWIGB2"}}`},
+			},
+			checkFunc: func(events <-chan *model.TodoEvent) {
+				assert.Equal(t, uint64(5), (<-events).Seq)
+				assert.Equal(t, uint64(4), (<-events).Seq)
+			},
+		},
+		{
+			name: "reset the watchers on a lost event",
+			notifications: []*pq.Notification{
+				{Extra: `{"seq":4,"type":"deleted","time":"2021-01-01T00:00:00+00:00","todo":{"id":"iKe0KxpurIn0E_6vzUDAr"}}`},
+				{Extra: `not json`},
+			},
+			checkFunc: func(events <-chan *model.TodoEvent) {
+				<-events
+				_, ok := <-events
+				assert.False(t, ok, "watcher: expected to be disconnected")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			f := fields{
+				repo: automocks.NewMockTodoRepository(ctrl),
+				bus:  service.NewEventBus(service.DefaultEventBacklog),
+			}
+			if tt.prepare != nil {
+				tt.prepare(&f)
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+			defer cancel()
+
+			events, err := f.bus.Subscribe(ctx, 0)
+			if err != nil {
+				t.Fatalf("unable to subscribe: %+v", err)
+			}
+
+			listener := psql.NewEventListener(psql.Config{}, f.bus, f.repo, log.NewLogfmtLogger(os.Stderr))
+			for _, n := range tt.notifications {
+				listener.Handle(ctx, n)
+			}
+			tt.checkFunc(events)
+		})
+	}
+}
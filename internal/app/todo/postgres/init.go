@@ -23,23 +23,48 @@ type Config struct {
 	SSLKey      string
 	SSLRootCert string
 	DriverName  string
+	// Events installs the trigger notifying the writes to the todos for the
+	// EventListener, the trigger is removed otherwise.
+	Events bool
 }
 
 // Connect creates a connection to the PostgreSQL instance and applies any
 // unapplied database migrations. A non-nil error is returned to indicate
 // failure.
 func Connect(cfg Config) (*gorm.DB, error) {
-	dsn := fmt.Sprintf("host=%s port=%s user=%s dbname=%s password=%s sslmode=%s sslcert=%s sslkey=%s sslrootcert=%s", cfg.Host, cfg.Port, cfg.User, cfg.Name, cfg.Pass, cfg.SSLMode, cfg.SSLCert, cfg.SSLKey, cfg.SSLRootCert)
-
 	db, err := gorm.Open(postgres.New(postgres.Config{
 		DriverName: cfg.DriverName,
-		DSN:        dsn,
+		DSN:        cfg.dsn(),
 	}), &gorm.Config{})
 	if err != nil {
 		return nil, err
 	}
 
-	db.AutoMigrate(&model.Todo{})
+	if err := db.Exec(changesSequenceSQL).Error; err != nil {
+		return nil, err
+	}
+	if err := db.AutoMigrate(&model.Todo{}, &model.Tombstone{}, &model.Share{}, &model.APIKey{}); err != nil {
+		return nil, err
+	}
+	events := dropEventsTriggerSQL
+	if cfg.Events {
+		events = eventsTriggerSQL
+	}
+	for _, sql := range []string{
+		// todos completed before completed_at existed are considered
+		// completed at their last update
+		"UPDATE todos SET completed_at = updated_at WHERE completed AND completed_at IS NULL",
+		changesTriggerSQL,
+		events,
+	} {
+		if err := db.Exec(sql).Error; err != nil {
+			return nil, err
+		}
+	}
 
 	return db.Debug(), nil
 }
+
+func (cfg Config) dsn() string {
+	return fmt.Sprintf("host=%s port=%s user=%s dbname=%s password=%s sslmode=%s sslcert=%s sslkey=%s sslrootcert=%s", cfg.Host, cfg.Port, cfg.User, cfg.Name, cfg.Pass, cfg.SSLMode, cfg.SSLCert, cfg.SSLKey, cfg.SSLRootCert)
+}
@@ -0,0 +1,72 @@
+package postgres
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"github.com/cage1016/gokit-todo/internal/app/todo/model"
+	"github.com/cage1016/gokit-todo/internal/app/todo/service"
+)
+
+// AddShare inserts share, or updates the role of the share of the same todo
+// with the same grantee, which keeps its id.
+func (repo *todoRepository) AddShare(ctx context.Context, share *model.Share) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	rows, err := repo.db.WithContext(ctx).Raw(`INSERT INTO shares (id, owner_id, tenant_id, todo_id, grantee_id, role, created_at) VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (tenant_id, owner_id, todo_id, grantee_id) DO UPDATE SET role = EXCLUDED.role
+		RETURNING id, created_at`, share.ID, share.OwnerID, share.TenantID, share.TodoID, share.GranteeID, share.Role, share.CreatedAt).Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		return rows.Err()
+	}
+	return rows.Scan(&share.ID, &share.CreatedAt)
+}
+
+func (repo *todoRepository) DeleteShare(ctx context.Context, owner model.Owner, shareID string) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	result := scope(repo.db.WithContext(ctx), owner).Delete(&model.Share{ID: shareID})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return service.ErrNotFound
+	}
+	return nil
+}
+
+func (repo *todoRepository) ListShares(ctx context.Context, owner model.Owner) (res []*model.Share, err error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	err = repo.db.WithContext(ctx).
+		Where("tenant_id = ?", owner.TenantID).
+		Where("owner_id = ? OR grantee_id = ?", owner.ID, owner.ID).
+		Order("created_at").Find(&res).Error
+	return
+}
+
+func (repo *todoRepository) Grants(ctx context.Context, grantee model.Owner, todoID string) (res []*model.Share, err error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	err = repo.db.WithContext(ctx).Raw(`SELECT shares.* FROM shares
+		JOIN todos ON todos.tenant_id = shares.tenant_id AND todos.owner_id = shares.owner_id
+		WHERE shares.tenant_id = ? AND shares.grantee_id = ? AND todos.id = ? AND shares.todo_id IN ('', todos.id)`,
+		grantee.TenantID, grantee.ID, todoID).Scan(&res).Error
+	return
+}
+
+// sharedWith restricts tx to the todos shared with grantee.
+func sharedWith(tx *gorm.DB, grantee model.Owner) *gorm.DB {
+	return tx.Where("tenant_id = ?", grantee.TenantID).
+		Where("EXISTS (SELECT 1 FROM shares WHERE shares.tenant_id = todos.tenant_id AND shares.owner_id = todos.owner_id AND shares.grantee_id = ? AND shares.todo_id IN ('', todos.id))", grantee.ID)
+}
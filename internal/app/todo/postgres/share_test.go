@@ -0,0 +1,319 @@
+// +build !integration
+
+package postgres_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-kit/kit/log"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+
+	"github.com/cage1016/gokit-todo/internal/app/todo/model"
+	psql "github.com/cage1016/gokit-todo/internal/app/todo/postgres"
+	"github.com/cage1016/gokit-todo/internal/app/todo/service"
+)
+
+func TestTodoRepository_AddShare(t *testing.T) {
+	var (
+		createdAt = time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+		mShare    = func() *model.Share {
+			return &model.Share{
+				ID:        "dlyW8C0ypdYd0tiJ0KbcN",
+				OwnerID:   owner.ID,
+				TenantID:  owner.TenantID,
+				TodoID:    "iKe0KxpurIn0E_6vzUDAr",
+				GranteeID: "bob",
+				Role:      model.RoleEditor,
+				CreatedAt: createdAt.Add(time.Hour),
+			}
+		}
+		query = regexp.QuoteMeta(`INSERT INTO shares (id, owner_id, tenant_id, todo_id, grantee_id, role, created_at) VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (tenant_id, owner_id, todo_id, grantee_id) DO UPDATE SET role = EXCLUDED.role
+		RETURNING id, created_at`)
+	)
+
+	type fields struct {
+		mock sqlmock.Sqlmock
+	}
+
+	type args struct {
+		share *model.Share
+	}
+
+	tests := []struct {
+		name      string
+		prepare   func(f *fields)
+		args      args
+		wantErr   bool
+		checkFunc func(share *model.Share, err error)
+	}{
+		{
+			name: "Add Share",
+			prepare: func(f *fields) {
+				s := mShare()
+				f.mock.ExpectQuery(query).
+					WithArgs(s.ID, s.OwnerID, s.TenantID, s.TodoID, s.GranteeID, s.Role, s.CreatedAt).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(s.ID, s.CreatedAt))
+			},
+			args:    args{share: mShare()},
+			wantErr: false,
+			checkFunc: func(share *model.Share, err error) {
+				assert.Equal(t, "dlyW8C0ypdYd0tiJ0KbcN", share.ID, fmt.Sprintf("id: expected dlyW8C0ypdYd0tiJ0KbcN got %v", share.ID))
+			},
+		},
+		{
+			name: "Add Share already made",
+			prepare: func(f *fields) {
+				s := mShare()
+				f.mock.ExpectQuery(query).
+					WithArgs(s.ID, s.OwnerID, s.TenantID, s.TodoID, s.GranteeID, s.Role, s.CreatedAt).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow("x8Ogcqzw0Oo1DR3TzVDIF", createdAt))
+			},
+			args:    args{share: mShare()},
+			wantErr: false,
+			checkFunc: func(share *model.Share, err error) {
+				assert.Equal(t, "x8Ogcqzw0Oo1DR3TzVDIF", share.ID, fmt.Sprintf("id: expected x8Ogcqzw0Oo1DR3TzVDIF got %v", share.ID))
+				assert.Equal(t, createdAt, share.CreatedAt, fmt.Sprintf("createdAt: expected %v got %v", createdAt, share.CreatedAt))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+			}
+			defer db.Close()
+
+			f := fields{
+				mock: mock,
+			}
+			if tt.prepare != nil {
+				tt.prepare(&f)
+			}
+			gdb, err := gorm.Open(postgres.New(postgres.Config{Conn: db}), &gorm.Config{})
+			repo := psql.New(gdb, log.NewLogfmtLogger(os.Stderr))
+
+			if err := repo.AddShare(context.Background(), tt.args.share); (err != nil) != tt.wantErr {
+				t.Errorf("AddShare(ctx context.Context, share *model.Share) error = %v, wantErr %v", err, tt.wantErr)
+			} else {
+				if tt.checkFunc != nil {
+					tt.checkFunc(tt.args.share, err)
+				}
+			}
+		})
+	}
+}
+
+func TestTodoRepository_DeleteShare(t *testing.T) {
+	type fields struct {
+		mock sqlmock.Sqlmock
+	}
+
+	type args struct {
+		shareID string
+	}
+
+	tests := []struct {
+		name      string
+		prepare   func(f *fields)
+		args      args
+		wantErr   bool
+		checkFunc func(err error)
+	}{
+		{
+			name: "Delete Share",
+			prepare: func(f *fields) {
+				f.mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "shares" WHERE tenant_id = $1 AND owner_id = $2 AND "shares"."id" = $3`)).
+					WithArgs(owner.TenantID, owner.ID, "dlyW8C0ypdYd0tiJ0KbcN").WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			args:    args{shareID: "dlyW8C0ypdYd0tiJ0KbcN"},
+			wantErr: false,
+		},
+		{
+			name: "Delete Share fail of another owner",
+			prepare: func(f *fields) {
+				f.mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "shares" WHERE tenant_id = $1 AND owner_id = $2 AND "shares"."id" = $3`)).
+					WithArgs(owner.TenantID, owner.ID, "dlyW8C0ypdYd0tiJ0KbcN").WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			args:    args{shareID: "dlyW8C0ypdYd0tiJ0KbcN"},
+			wantErr: true,
+			checkFunc: func(err error) {
+				assert.Equal(t, service.ErrNotFound, err, fmt.Sprintf("err: expected service.ErrNotFound got %v", err))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+			}
+			defer db.Close()
+
+			f := fields{
+				mock: mock,
+			}
+			if tt.prepare != nil {
+				tt.prepare(&f)
+			}
+			gdb, err := gorm.Open(postgres.New(postgres.Config{Conn: db}), &gorm.Config{})
+			repo := psql.New(gdb, log.NewLogfmtLogger(os.Stderr))
+
+			if err := repo.DeleteShare(context.Background(), owner, tt.args.shareID); (err != nil) != tt.wantErr {
+				t.Errorf("DeleteShare(ctx context.Context, owner model.Owner, shareID string) error = %v, wantErr %v", err, tt.wantErr)
+			} else {
+				if tt.checkFunc != nil {
+					tt.checkFunc(err)
+				}
+			}
+		})
+	}
+}
+
+func TestTodoRepository_ListShares(t *testing.T) {
+	type fields struct {
+		mock sqlmock.Sqlmock
+	}
+
+	tests := []struct {
+		name      string
+		prepare   func(f *fields)
+		wantErr   bool
+		checkFunc func(res []*model.Share, err error)
+	}{
+		{
+			name: "List Shares made by and with the owner",
+			prepare: func(f *fields) {
+				f.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "shares" WHERE tenant_id = $1 AND (owner_id = $2 OR grantee_id = $3) ORDER BY created_at`)).
+					WithArgs(owner.TenantID, owner.ID, owner.ID).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "owner_id", "tenant_id", "todo_id", "grantee_id", "role"}).
+						AddRow("dlyW8C0ypdYd0tiJ0KbcN", owner.ID, owner.TenantID, "", "bob", model.RoleViewer).
+						AddRow("x8Ogcqzw0Oo1DR3TzVDIF", "bob", owner.TenantID, "iKe0KxpurIn0E_6vzUDAr", owner.ID, model.RoleEditor))
+			},
+			wantErr: false,
+			checkFunc: func(res []*model.Share, err error) {
+				assert.Equal(t, 2, len(res), fmt.Sprintf("count res: expected 2 got %v", len(res)))
+				assert.Equal(t, owner.ID, res[1].GranteeID, fmt.Sprintf("grantee: expected %v got %v", owner.ID, res[1].GranteeID))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+			}
+			defer db.Close()
+
+			f := fields{
+				mock: mock,
+			}
+			if tt.prepare != nil {
+				tt.prepare(&f)
+			}
+			gdb, err := gorm.Open(postgres.New(postgres.Config{Conn: db}), &gorm.Config{})
+			repo := psql.New(gdb, log.NewLogfmtLogger(os.Stderr))
+
+			if res, err := repo.ListShares(context.Background(), owner); (err != nil) != tt.wantErr {
+				t.Errorf("ListShares(ctx context.Context, owner model.Owner) error = %v, wantErr %v", err, tt.wantErr)
+			} else {
+				if tt.checkFunc != nil {
+					tt.checkFunc(res, err)
+				}
+			}
+		})
+	}
+}
+
+func TestTodoRepository_Grants(t *testing.T) {
+	var (
+		grantee = model.Owner{ID: "bob", TenantID: owner.TenantID}
+		query   = regexp.QuoteMeta(`SELECT shares.* FROM shares
+		JOIN todos ON todos.tenant_id = shares.tenant_id AND todos.owner_id = shares.owner_id
+		WHERE shares.tenant_id = $1 AND shares.grantee_id = $2 AND todos.id = $3 AND shares.todo_id IN ('', todos.id)`)
+	)
+
+	type fields struct {
+		mock sqlmock.Sqlmock
+	}
+
+	type args struct {
+		todoID string
+	}
+
+	tests := []struct {
+		name      string
+		prepare   func(f *fields)
+		args      args
+		wantErr   bool
+		checkFunc func(res []*model.Share, err error)
+	}{
+		{
+			name: "Grants of a shared todo",
+			prepare: func(f *fields) {
+				f.mock.ExpectQuery(query).
+					WithArgs(grantee.TenantID, grantee.ID, "iKe0KxpurIn0E_6vzUDAr").
+					WillReturnRows(sqlmock.NewRows([]string{"id", "owner_id", "tenant_id", "todo_id", "grantee_id", "role"}).
+						AddRow("dlyW8C0ypdYd0tiJ0KbcN", owner.ID, owner.TenantID, "", grantee.ID, model.RoleViewer))
+			},
+			args:    args{todoID: "iKe0KxpurIn0E_6vzUDAr"},
+			wantErr: false,
+			checkFunc: func(res []*model.Share, err error) {
+				assert.Equal(t, 1, len(res), fmt.Sprintf("count res: expected 1 got %v", len(res)))
+				assert.Equal(t, owner, res[0].Owner(), fmt.Sprintf("owner: expected %v got %v", owner, res[0].Owner()))
+			},
+		},
+		{
+			name: "Grants of a todo not shared",
+			prepare: func(f *fields) {
+				f.mock.ExpectQuery(query).
+					WithArgs(grantee.TenantID, grantee.ID, "zIYPEK0zEpUc7CoQWIGB2").
+					WillReturnRows(sqlmock.NewRows([]string{"id", "owner_id", "tenant_id", "todo_id", "grantee_id", "role"}))
+			},
+			args:    args{todoID: "zIYPEK0zEpUc7CoQWIGB2"},
+			wantErr: false,
+			checkFunc: func(res []*model.Share, err error) {
+				assert.Equal(t, 0, len(res), fmt.Sprintf("count res: expected 0 got %v", len(res)))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+			}
+			defer db.Close()
+
+			f := fields{
+				mock: mock,
+			}
+			if tt.prepare != nil {
+				tt.prepare(&f)
+			}
+			gdb, err := gorm.Open(postgres.New(postgres.Config{Conn: db}), &gorm.Config{})
+			repo := psql.New(gdb, log.NewLogfmtLogger(os.Stderr))
+
+			if res, err := repo.Grants(context.Background(), grantee, tt.args.todoID); (err != nil) != tt.wantErr {
+				t.Errorf("Grants(ctx context.Context, grantee model.Owner, todoID string) error = %v, wantErr %v", err, tt.wantErr)
+			} else {
+				if tt.checkFunc != nil {
+					tt.checkFunc(res, err)
+				}
+			}
+		})
+	}
+}
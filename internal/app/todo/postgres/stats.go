@@ -0,0 +1,57 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/go-kit/kit/metrics"
+)
+
+// PoolMetrics are the metrics of the connection pool of a database.
+type PoolMetrics struct {
+	// Connections are the open connections, labelled with their state:
+	// in_use or idle.
+	Connections metrics.Gauge
+	// MaxOpen is the largest number of open connections, 0 if unlimited.
+	MaxOpen metrics.Gauge
+	// Waits is the number of connections waited for, and WaitSeconds the
+	// total time spent waiting for them.
+	Waits       metrics.Counter
+	WaitSeconds metrics.Counter
+	// Closed are the connections closed by the pool, labelled with the
+	// reason: max_idle, max_idle_time or max_lifetime.
+	Closed metrics.Counter
+}
+
+// RecordPoolStats records the stats of the connection pool of db to m every
+// interval until ctx is cancelled.
+func RecordPoolStats(ctx context.Context, db *sql.DB, m PoolMetrics, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var last sql.DBStats
+	for {
+		last = m.record(db.Stats(), last)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// record sets the gauges of m to stats, and adds the changes since last to
+// its counters, the stats counting since the pool was opened.
+func (m PoolMetrics) record(stats, last sql.DBStats) sql.DBStats {
+	m.Connections.With("state", "in_use").Set(float64(stats.InUse))
+	m.Connections.With("state", "idle").Set(float64(stats.Idle))
+	m.MaxOpen.Set(float64(stats.MaxOpenConnections))
+	m.Waits.Add(float64(stats.WaitCount - last.WaitCount))
+	m.WaitSeconds.Add((stats.WaitDuration - last.WaitDuration).Seconds())
+	m.Closed.With("reason", "max_idle").Add(float64(stats.MaxIdleClosed - last.MaxIdleClosed))
+	m.Closed.With("reason", "max_idle_time").Add(float64(stats.MaxIdleTimeClosed - last.MaxIdleTimeClosed))
+	m.Closed.With("reason", "max_lifetime").Add(float64(stats.MaxLifetimeClosed - last.MaxLifetimeClosed))
+	return stats
+}
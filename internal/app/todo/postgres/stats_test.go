@@ -0,0 +1,82 @@
+// +build !integration
+
+package postgres_test
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	kitprometheus "github.com/go-kit/kit/metrics/prometheus"
+	stdprometheus "github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+
+	psql "github.com/cage1016/gokit-todo/internal/app/todo/postgres"
+)
+
+func TestRecordPoolStats(t *testing.T) {
+	type vecs struct {
+		connections, maxOpen *stdprometheus.GaugeVec
+		waits, waitSeconds   *stdprometheus.CounterVec
+		closed               *stdprometheus.CounterVec
+	}
+
+	tests := []struct {
+		name      string
+		prepare   func(db *sql.DB)
+		checkFunc func(v vecs)
+	}{
+		{
+			name: "record pool stats",
+			prepare: func(db *sql.DB) {
+				db.SetMaxOpenConns(10)
+			},
+			checkFunc: func(v vecs) {
+				maxOpen := testutil.ToFloat64(v.maxOpen.WithLabelValues())
+				assert.Equal(t, 10.0, maxOpen, fmt.Sprintf("maxOpen: expected 10 got %v", maxOpen))
+				idle := testutil.ToFloat64(v.connections.WithLabelValues("idle"))
+				assert.Equal(t, 1.0, idle, fmt.Sprintf("idle: expected 1 got %v", idle))
+				inUse := testutil.ToFloat64(v.connections.WithLabelValues("in_use"))
+				assert.Equal(t, 0.0, inUse, fmt.Sprintf("inUse: expected 0 got %v", inUse))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, _, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+			}
+			defer db.Close()
+			if tt.prepare != nil {
+				tt.prepare(db)
+			}
+
+			v := vecs{
+				connections: stdprometheus.NewGaugeVec(stdprometheus.GaugeOpts{Name: "connections"}, []string{"state"}),
+				maxOpen:     stdprometheus.NewGaugeVec(stdprometheus.GaugeOpts{Name: "max_open_connections"}, []string{}),
+				waits:       stdprometheus.NewCounterVec(stdprometheus.CounterOpts{Name: "waits_total"}, []string{}),
+				waitSeconds: stdprometheus.NewCounterVec(stdprometheus.CounterOpts{Name: "wait_seconds_total"}, []string{}),
+				closed:      stdprometheus.NewCounterVec(stdprometheus.CounterOpts{Name: "closed_connections_total"}, []string{"reason"}),
+			}
+			m := psql.PoolMetrics{
+				Connections: kitprometheus.NewGauge(v.connections),
+				MaxOpen:     kitprometheus.NewGauge(v.maxOpen),
+				Waits:       kitprometheus.NewCounter(v.waits),
+				WaitSeconds: kitprometheus.NewCounter(v.waitSeconds),
+				Closed:      kitprometheus.NewCounter(v.closed),
+			}
+
+			// the stats are recorded once before ctx is done
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			psql.RecordPoolStats(ctx, db, m, time.Hour)
+			tt.checkFunc(v)
+		})
+	}
+}
@@ -2,76 +2,302 @@ package postgres
 
 import (
 	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/go-kit/kit/log"
+	"github.com/lib/pq"
 	"gorm.io/gorm"
 
 	"github.com/cage1016/gokit-todo/internal/app/todo/model"
+	"github.com/cage1016/gokit-todo/internal/app/todo/service"
 )
 
 var _ model.TodoRepository = (*todoRepository)(nil)
 
+// uniqueViolation is the code of the errors of the writes violating a unique
+// constraint.
+const uniqueViolation = "23505"
+
 type todoRepository struct {
 	mu  sync.RWMutex
 	log log.Logger
 	db  *gorm.DB
 }
 
-func (repo *todoRepository) Get(ctx context.Context, todoID string) (res *model.Todo, err error) {
+func (repo *todoRepository) Get(ctx context.Context, owner model.Owner, todoID string) (res *model.Todo, err error) {
 	repo.mu.Lock()
 	defer repo.mu.Unlock()
 
 	res = new(model.Todo)
-	err = repo.db.WithContext(ctx).Where("id", todoID).Find(res).Error
-	return
+	result := scope(repo.db.WithContext(ctx), owner).Where("id", todoID).Find(res)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, service.ErrNotFound
+	}
+	return res, nil
 }
 
 func (repo *todoRepository) Add(ctx context.Context, todo *model.Todo) error {
 	repo.mu.Lock()
 	defer repo.mu.Unlock()
 
-	if err := repo.db.WithContext(ctx).Create(todo).Error; err != nil {
-		return err
-	}
-	return nil
+	return createError(repo.db.WithContext(ctx).Create(todo).Error)
 }
 
-func (repo *todoRepository) Delete(ctx context.Context, todoID string) error {
+// AddWithin holds an advisory lock on the tenant of todo until the insert is
+// committed, which serializes the adds of the tenant across the instances
+// of the service: the todos counted are still the ones when todo is
+// inserted.
+func (repo *todoRepository) AddWithin(ctx context.Context, todo *model.Todo, quota model.Quota) error {
 	repo.mu.Lock()
 	defer repo.mu.Unlock()
 
-	if err := repo.db.WithContext(ctx).Delete(&model.Todo{ID: todoID}).Error; err != nil {
-		return err
+	err := repo.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(`SELECT pg_advisory_xact_lock(hashtext(?))`, quotaLockPrefix+todo.TenantID).Error; err != nil {
+			return err
+		}
+		usage, err := countUsage(tx, todo.Owner())
+		if err != nil {
+			return err
+		}
+		if err := service.CheckUsage(quota, usage); err != nil {
+			return err
+		}
+		return tx.Create(todo).Error
+	})
+	return createError(err)
+}
+
+// quotaLockPrefix prefixes the tenant ids hashed to the advisory locks of
+// AddWithin.
+const quotaLockPrefix = "quota/"
+
+// createError returns service.ErrConflict for the inserts of a taken id.
+func createError(err error) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == uniqueViolation {
+		return service.ErrConflict
 	}
-	return nil
+	return err
 }
 
-func (repo *todoRepository) Update(ctx context.Context, todo *model.Todo) error {
+func (repo *todoRepository) Delete(ctx context.Context, owner model.Owner, todoID string, version uint64) error {
 	repo.mu.Lock()
 	defer repo.mu.Unlock()
 
-	result := repo.db.WithContext(ctx).Model(&model.Todo{ID: todo.ID}).UpdateColumns(
-		map[string]interface{}{
-			"text":       todo.Text,
-			"completed":  todo.Completed,
-			"updated_at": todo.UpdatedAt,
-		},
-	)
+	tx := scope(repo.db.WithContext(ctx), owner)
+	if version != 0 {
+		tx = tx.Where("version = ?", version)
+	}
+	result := tx.Delete(&model.Todo{ID: todoID})
 	if result.Error != nil {
 		return result.Error
 	}
+	if result.RowsAffected == 0 {
+		if version != 0 {
+			return service.ErrConflict
+		}
+		return service.ErrNotFound
+	}
 	return nil
 }
 
-func (repo *todoRepository) List(ctx context.Context) (res []*model.Todo, err error) {
+// Update writes todo and reads back the version assigned by the database.
+func (repo *todoRepository) Update(ctx context.Context, todo *model.Todo) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	query := `UPDATE todos SET text = ?, completed = ?, completed_at = ?, archived_at = ?, snoozed_until = ?, updated_at = ? WHERE id = ? AND tenant_id = ? AND owner_id = ?`
+	args := []interface{}{todo.Text, todo.Completed, todo.CompletedAt, todo.ArchivedAt, todo.SnoozedUntil, todo.UpdatedAt, todo.ID, todo.TenantID, todo.OwnerID}
+	if todo.Version != 0 {
+		query += " AND version = ?"
+		args = append(args, todo.Version)
+	}
+
+	rows, err := repo.db.WithContext(ctx).Raw(query+" RETURNING version", args...).Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		if todo.Version != 0 {
+			return service.ErrConflict
+		}
+		return service.ErrNotFound
+	}
+	return rows.Scan(&todo.Version)
+}
+
+func (repo *todoRepository) List(ctx context.Context, query *model.TodoQuery) (res []*model.Todo, err error) {
 	repo.mu.RLock()
 	defer repo.mu.RUnlock()
 
-	err = repo.db.WithContext(ctx).Order("created_at desc").Find(&res).Error
+	tx := repo.db.WithContext(ctx)
+	if query.Shared {
+		tx = sharedWith(tx, query.Owner)
+	} else {
+		tx = scope(tx, query.Owner)
+	}
+	if cols := columns(query.Fields); len(cols) > 0 {
+		tx = tx.Select(cols)
+	}
+	if !query.IncludeArchived {
+		tx = tx.Where("archived_at IS NULL")
+	}
+	if !query.IncludeSnoozed {
+		tx = tx.Where("snoozed_until IS NULL OR snoozed_until <= ?", query.Now)
+	}
+	switch query.Status {
+	case service.ACTIVE:
+		tx = tx.Where("NOT completed")
+	case service.COMPLETE:
+		tx = tx.Where("completed")
+	}
+	if query.CompletedAfter != nil {
+		tx = tx.Where("completed_at >= ?", *query.CompletedAfter)
+	}
+	if query.CompletedBefore != nil {
+		tx = tx.Where("completed_at < ?", *query.CompletedBefore)
+	}
+	if query.Offset > 0 {
+		tx = tx.Offset(query.Offset)
+	}
+	if query.Limit > 0 {
+		tx = tx.Limit(query.Limit)
+	}
+	err = tx.Order(order(query.Sort)).Find(&res).Error
 	return
 }
 
+// scope restricts tx to the rows of owner.
+func scope(tx *gorm.DB, owner model.Owner) *gorm.DB {
+	return tx.Where("tenant_id = ?", owner.TenantID).Where("owner_id = ?", owner.ID)
+}
+
+// order maps the sort field of a listing to its ORDER BY clause, the newest
+// todos are listed first by default.
+func order(sort string) string {
+	direction := "asc"
+	if strings.HasPrefix(sort, "-") {
+		sort, direction = sort[1:], "desc"
+	}
+	col, ok := model.TodoSortFields[sort]
+	if !ok {
+		return "created_at desc"
+	}
+	return fmt.Sprintf("%s %s nulls last", col, direction)
+}
+
+// columns maps the requested todo fields to their database columns, unknown
+// fields are ignored.
+func columns(fields []string) (cols []string) {
+	for _, f := range fields {
+		if c, ok := model.TodoFields[f]; ok {
+			cols = append(cols, c)
+		}
+	}
+	return
+}
+
+func (repo *todoRepository) Stats(ctx context.Context, owner model.Owner, since time.Time) (res *model.TodoStats, err error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	var totals struct {
+		Total             int64
+		Completed         int64
+		AvgTimeToComplete float64
+	}
+	err = repo.db.WithContext(ctx).Raw(`SELECT count(*) AS total,
+		count(*) FILTER (WHERE completed) AS completed,
+		coalesce(avg(extract(epoch FROM completed_at - created_at)) FILTER (WHERE completed), 0) AS avg_time_to_complete
+		FROM todos WHERE tenant_id = ? AND owner_id = ?`, owner.TenantID, owner.ID).Scan(&totals).Error
+	if err != nil {
+		return nil, err
+	}
+
+	perDay := []*model.DayCount{}
+	err = repo.db.WithContext(ctx).Raw(`SELECT to_char(date_trunc('day', completed_at), 'YYYY-MM-DD') AS day, count(*) AS count
+		FROM todos WHERE tenant_id = ? AND owner_id = ? AND completed AND completed_at >= ?
+		GROUP BY 1 ORDER BY 1`, owner.TenantID, owner.ID, since).Scan(&perDay).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.TodoStats{
+		Total:             totals.Total,
+		Active:            totals.Total - totals.Completed,
+		Completed:         totals.Completed,
+		CompletedPerDay:   perDay,
+		AvgTimeToComplete: totals.AvgTimeToComplete,
+	}, nil
+}
+
+func (repo *todoRepository) Usage(ctx context.Context, owner model.Owner) (res *model.Usage, err error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	return countUsage(repo.db.WithContext(ctx), owner)
+}
+
+// countUsage counts the todos of owner and of their tenant with db.
+func countUsage(db *gorm.DB, owner model.Owner) (*model.Usage, error) {
+	var counts struct {
+		Todos       int64
+		TenantTodos int64
+	}
+	err := db.Raw(`SELECT count(*) FILTER (WHERE owner_id = ?) AS todos, count(*) AS tenant_todos
+		FROM todos WHERE tenant_id = ?`, owner.ID, owner.TenantID).Scan(&counts).Error
+	if err != nil {
+		return nil, err
+	}
+	return &model.Usage{Todos: counts.Todos, TenantTodos: counts.TenantTodos}, nil
+}
+
+// Archive archives the todos completed before completedBefore and not updated
+// since, which leaves the unarchived todos alone for another window.
+func (repo *todoRepository) Archive(ctx context.Context, completedBefore time.Time, archivedAt time.Time) (int64, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	result := repo.db.WithContext(ctx).Model(&model.Todo{}).
+		Where("completed AND completed_at < ? AND updated_at < ? AND archived_at IS NULL", completedBefore, completedBefore).
+		UpdateColumn("archived_at", archivedAt)
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	return result.RowsAffected, nil
+}
+
+func (repo *todoRepository) Changes(ctx context.Context, owner model.Owner, since model.ChangeCursor, limit int) (todos []*model.Todo, tombstones []*model.Tombstone, err error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	// both are read from the same snapshot, so that no change is skipped by
+	// the cursors of the other
+	err = repo.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := scope(tx, owner).Where("(tx_id, version) > (?, ?)", since.TxID, since.Version).Where(changesEndedSQL).Order("tx_id, version").Limit(limit).Find(&todos).Error; err != nil {
+			return err
+		}
+		return scope(tx, owner).Where("(tx_id, version) > (?, ?)", since.TxID, since.Version).Where(changesEndedSQL).Order("tx_id, version").Limit(limit).Find(&tombstones).Error
+	}, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return nil, nil, err
+	}
+	return todos, tombstones, nil
+}
+
 func New(db *gorm.DB, logger log.Logger) model.TodoRepository {
 	return &todoRepository{
 		mu:  sync.RWMutex{},
@@ -13,18 +13,26 @@ import (
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/go-kit/kit/log"
+	"github.com/lib/pq"
 	"github.com/stretchr/testify/assert"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 
 	"github.com/cage1016/gokit-todo/internal/app/todo/model"
 	psql "github.com/cage1016/gokit-todo/internal/app/todo/postgres"
+	"github.com/cage1016/gokit-todo/internal/app/todo/service"
+	"github.com/cage1016/gokit-todo/internal/pkg/errors"
 )
 
+// owner is the owner of the todos of the tests.
+var owner = model.Owner{ID: "nGVdoNpZs0AN3Xtq9W0Ki", TenantID: "acme"}
+
 func TestTodoRepository_Add(t *testing.T) {
 	var (
 		mTodo = &model.Todo{
 			ID:        "iKe0KxpurIn0E_6vzUDAr",
+			OwnerID:   owner.ID,
+			TenantID:  owner.TenantID,
 			CreatedAt: time.Now(),
 			UpdatedAt: time.Now(),
 			Text:      "aa",
@@ -50,20 +58,22 @@ func TestTodoRepository_Add(t *testing.T) {
 		{
 			name: "Add Todo",
 			prepare: func(f *fields) {
-				f.mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "todos" ("id","created_at","updated_at","text","completed") VALUES ($1,$2,$3,$4,$5)`)).
-					WithArgs(mTodo.ID, mTodo.CreatedAt, mTodo.UpdatedAt, mTodo.Text, mTodo.Completed).
-					WillReturnResult(sqlmock.NewResult(1, 1)).
-					WillReturnError(nil)
+				f.mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "todos" ("id","owner_id","tenant_id","created_at","updated_at","text","completed","completed_at","archived_at","snoozed_until") VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10) RETURNING "version"`)).
+					WithArgs(mTodo.ID, mTodo.OwnerID, mTodo.TenantID, mTodo.CreatedAt, mTodo.UpdatedAt, mTodo.Text, mTodo.Completed, mTodo.CompletedAt, mTodo.ArchivedAt, mTodo.SnoozedUntil).
+					WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(1))
 			},
 			args:    args{todo: mTodo},
 			wantErr: false,
+			checkFunc: func(err error) {
+				assert.Equal(t, uint64(1), mTodo.Version, fmt.Sprintf("version: expected 1 got %d", mTodo.Version))
+			},
 		},
 		{
 			name: "Add Todo Fail without primary key",
 			prepare: func(f *fields) {
-				f.mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "todos" ("id","created_at","updated_at","text","completed") VALUES ($1,$2,$3,$4,$5)`)).
-					WithArgs(mTodo.ID, mTodo.CreatedAt, mTodo.UpdatedAt, mTodo.Text, mTodo.Completed).
-					WillReturnResult(sqlmock.NewResult(0, 0)).
+				mTodo.Version = 0 // assigned by the previous case
+				f.mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "todos" ("id","owner_id","tenant_id","created_at","updated_at","text","completed","completed_at","archived_at","snoozed_until") VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10) RETURNING "version"`)).
+					WithArgs(mTodo.ID, mTodo.OwnerID, mTodo.TenantID, mTodo.CreatedAt, mTodo.UpdatedAt, mTodo.Text, mTodo.Completed, mTodo.CompletedAt, mTodo.ArchivedAt, mTodo.SnoozedUntil).
 					WillReturnError(sql.ErrNoRows)
 			},
 			args: args{todo: func() *model.Todo {
@@ -75,6 +85,19 @@ func TestTodoRepository_Add(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "Add Todo Fail with a taken id",
+			prepare: func(f *fields) {
+				f.mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "todos"`)).
+					WithArgs(mTodo.ID, mTodo.OwnerID, mTodo.TenantID, mTodo.CreatedAt, mTodo.UpdatedAt, mTodo.Text, mTodo.Completed, mTodo.CompletedAt, mTodo.ArchivedAt, mTodo.SnoozedUntil).
+					WillReturnError(&pq.Error{Code: "23505"})
+			},
+			args: args{todo: mTodo},
+			checkFunc: func(err error) {
+				assert.Equal(t, err, service.ErrConflict, fmt.Sprintf("err: expected service.ErrConflict got %v", err))
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -105,6 +128,118 @@ func TestTodoRepository_Add(t *testing.T) {
 	}
 }
 
+func TestTodoRepository_AddWithin(t *testing.T) {
+	var (
+		mTodo = &model.Todo{
+			ID:        "iKe0KxpurIn0E_6vzUDAr",
+			OwnerID:   owner.ID,
+			TenantID:  owner.TenantID,
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+			Text:      "aa",
+			Completed: false,
+		}
+		quota = model.Quota{Todos: 2, TenantTodos: 3}
+	)
+
+	type fields struct {
+		mock sqlmock.Sqlmock
+	}
+
+	tests := []struct {
+		name      string
+		prepare   func(f *fields)
+		checkFunc func(err error)
+		wantErr   bool
+	}{
+		{
+			name: "AddWithin Todo",
+			prepare: func(f *fields) {
+				f.mock.ExpectBegin()
+				f.mock.ExpectExec(regexp.QuoteMeta(`SELECT pg_advisory_xact_lock(hashtext($1))`)).
+					WithArgs("quota/" + owner.TenantID).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				f.mock.ExpectQuery(`SELECT count\(\*\) FILTER \(WHERE owner_id = \$1\) AS todos, count\(\*\) AS tenant_todos FROM todos WHERE tenant_id = \$2`).
+					WithArgs(owner.ID, owner.TenantID).
+					WillReturnRows(sqlmock.NewRows([]string{"todos", "tenant_todos"}).AddRow(1, 2))
+				f.mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "todos"`)).
+					WithArgs(mTodo.ID, mTodo.OwnerID, mTodo.TenantID, mTodo.CreatedAt, mTodo.UpdatedAt, mTodo.Text, mTodo.Completed, mTodo.CompletedAt, mTodo.ArchivedAt, mTodo.SnoozedUntil).
+					WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(1))
+				f.mock.ExpectCommit()
+			},
+			wantErr: false,
+			checkFunc: func(err error) {
+				assert.Equal(t, uint64(1), mTodo.Version, fmt.Sprintf("version: expected 1 got %d", mTodo.Version))
+			},
+		},
+		{
+			name: "AddWithin Todo Fail past the quota",
+			prepare: func(f *fields) {
+				mTodo.Version = 0 // assigned by the previous case
+				f.mock.ExpectBegin()
+				f.mock.ExpectExec(regexp.QuoteMeta(`SELECT pg_advisory_xact_lock(hashtext($1))`)).
+					WithArgs("quota/" + owner.TenantID).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				f.mock.ExpectQuery(`SELECT count\(\*\) FILTER (.+) FROM todos`).
+					WithArgs(owner.ID, owner.TenantID).
+					WillReturnRows(sqlmock.NewRows([]string{"todos", "tenant_todos"}).AddRow(1, 3))
+				f.mock.ExpectRollback()
+			},
+			wantErr: true,
+			checkFunc: func(err error) {
+				assert.True(t, errors.Contains(errors.Cast(err), service.ErrQuotaExceeded), fmt.Sprintf("err: expected service.ErrQuotaExceeded got %v", err))
+			},
+		},
+		{
+			name: "AddWithin Todo Fail with a taken id",
+			prepare: func(f *fields) {
+				f.mock.ExpectBegin()
+				f.mock.ExpectExec(regexp.QuoteMeta(`SELECT pg_advisory_xact_lock(hashtext($1))`)).
+					WithArgs("quota/" + owner.TenantID).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				f.mock.ExpectQuery(`SELECT count\(\*\) FILTER (.+) FROM todos`).
+					WithArgs(owner.ID, owner.TenantID).
+					WillReturnRows(sqlmock.NewRows([]string{"todos", "tenant_todos"}).AddRow(0, 0))
+				f.mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "todos"`)).
+					WillReturnError(&pq.Error{Code: "23505"})
+				f.mock.ExpectRollback()
+			},
+			wantErr: true,
+			checkFunc: func(err error) {
+				assert.Equal(t, err, service.ErrConflict, fmt.Sprintf("err: expected service.ErrConflict got %v", err))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+			}
+			defer db.Close()
+
+			f := fields{
+				mock: mock,
+			}
+			if tt.prepare != nil {
+				tt.prepare(&f)
+			}
+			gdb, err := gorm.Open(postgres.New(postgres.Config{Conn: db}), &gorm.Config{})
+			repo := psql.New(gdb, log.NewLogfmtLogger(os.Stderr))
+
+			if err := repo.AddWithin(context.Background(), mTodo, quota); (err != nil) != tt.wantErr {
+				t.Errorf("AddWithin(ctx context.Context) error = %v, wantErr %v", err, tt.wantErr)
+			} else {
+				if tt.checkFunc != nil {
+					tt.checkFunc(err)
+				}
+			}
+			assert.NoError(t, mock.ExpectationsWereMet(), "all the expected queries should be made")
+		})
+	}
+}
+
 func TestTodoRepository_List(t *testing.T) {
 	var (
 		mTodos = []*model.Todo{
@@ -123,6 +258,7 @@ func TestTodoRepository_List(t *testing.T) {
 				Completed: false,
 			},
 		}
+		now = time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
 	)
 
 	type fields struct {
@@ -130,6 +266,7 @@ func TestTodoRepository_List(t *testing.T) {
 	}
 
 	type args struct {
+		query *model.TodoQuery
 	}
 
 	tests := []struct {
@@ -150,11 +287,120 @@ func TestTodoRepository_List(t *testing.T) {
 					WillReturnRows(rows).
 					WillReturnError(nil)
 			},
+			args:    args{query: &model.TodoQuery{}},
 			wantErr: false,
 			checkFunc: func(res []*model.Todo, err error) {
 				assert.Equal(t, mTodos, res, fmt.Sprintf("models: expected aa got %v", res))
 			},
 		},
+		{
+			name: "List Todo with fields",
+			prepare: func(f *fields) {
+				rows := sqlmock.NewRows([]string{"id", "completed"})
+				for _, m := range mTodos {
+					rows.AddRow(m.ID, m.Completed)
+				}
+				f.mock.ExpectQuery(regexp.QuoteMeta(`SELECT "id","completed" FROM "todos" WHERE tenant_id = $1 AND owner_id = $2 AND archived_at IS NULL AND (snoozed_until IS NULL OR snoozed_until <= $3) ORDER BY created_at desc`)).
+					WillReturnRows(rows).
+					WillReturnError(nil)
+			},
+			args:    args{query: &model.TodoQuery{Fields: []string{"id", "completed", "unknown"}}},
+			wantErr: false,
+			checkFunc: func(res []*model.Todo, err error) {
+				assert.Equal(t, 2, len(res), fmt.Sprintf("count res: expected 2 got %v", len(res)))
+				assert.Equal(t, "", res[0].Text, fmt.Sprintf("text: expected empty got %v", res[0].Text))
+			},
+		},
+		{
+			name: "List Todo page",
+			prepare: func(f *fields) {
+				rows := sqlmock.NewRows([]string{"id"})
+				rows.AddRow(mTodos[1].ID)
+				f.mock.ExpectQuery(regexp.QuoteMeta(`SELECT "id" FROM "todos" WHERE tenant_id = $1 AND owner_id = $2 AND archived_at IS NULL AND (snoozed_until IS NULL OR snoozed_until <= $3) ORDER BY created_at desc LIMIT 1 OFFSET 1`)).
+					WillReturnRows(rows).
+					WillReturnError(nil)
+			},
+			args:    args{query: &model.TodoQuery{Fields: []string{"id"}, Offset: 1, Limit: 1}},
+			wantErr: false,
+			checkFunc: func(res []*model.Todo, err error) {
+				assert.Equal(t, 1, len(res), fmt.Sprintf("count res: expected 1 got %v", len(res)))
+				assert.Equal(t, mTodos[1].ID, res[0].ID, fmt.Sprintf("id: expected %v got %v", mTodos[1].ID, res[0].ID))
+			},
+		},
+		{
+			name: "List Todo completed within a range sorted by completion",
+			prepare: func(f *fields) {
+				f.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "todos" WHERE tenant_id = $1 AND owner_id = $2 AND archived_at IS NULL AND (snoozed_until IS NULL OR snoozed_until <= $3) AND completed_at >= $4 AND completed_at < $5 ORDER BY completed_at desc nulls last`)).
+					WithArgs(owner.TenantID, owner.ID, sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "text", "completed", "created_at", "updated_at"})).
+					WillReturnError(nil)
+			},
+			args: args{query: &model.TodoQuery{
+				Owner:           owner,
+				CompletedAfter:  func() *time.Time { t := time.Now().Add(-time.Hour); return &t }(),
+				CompletedBefore: func() *time.Time { t := time.Now(); return &t }(),
+				Sort:            "-completedAt",
+			}},
+			wantErr: false,
+		},
+		{
+			name: "List active Todo",
+			prepare: func(f *fields) {
+				f.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "todos" WHERE tenant_id = $1 AND owner_id = $2 AND archived_at IS NULL AND (snoozed_until IS NULL OR snoozed_until <= $3) AND NOT completed ORDER BY created_at desc`)).
+					WithArgs(owner.TenantID, owner.ID, now).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "text", "completed", "created_at", "updated_at"})).
+					WillReturnError(nil)
+			},
+			args:    args{query: &model.TodoQuery{Status: service.ACTIVE, Now: now, Owner: owner}},
+			wantErr: false,
+		},
+		{
+			name: "List Todo shared with the owner",
+			prepare: func(f *fields) {
+				f.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "todos" WHERE tenant_id = $1 AND (EXISTS (SELECT 1 FROM shares WHERE shares.tenant_id = todos.tenant_id AND shares.owner_id = todos.owner_id AND shares.grantee_id = $2 AND shares.todo_id IN ('', todos.id))) AND archived_at IS NULL AND (snoozed_until IS NULL OR snoozed_until <= $3) ORDER BY created_at desc`)).
+					WithArgs(owner.TenantID, owner.ID, sqlmock.AnyArg()).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "owner_id", "tenant_id", "text"}).
+						AddRow("iKe0KxpurIn0E_6vzUDAr", "bob", owner.TenantID, "aa")).
+					WillReturnError(nil)
+			},
+			args:    args{query: &model.TodoQuery{Owner: owner, Shared: true}},
+			wantErr: false,
+			checkFunc: func(res []*model.Todo, err error) {
+				assert.Equal(t, 1, len(res), fmt.Sprintf("count res: expected 1 got %v", len(res)))
+				assert.Equal(t, "bob", res[0].OwnerID, fmt.Sprintf("owner: expected bob got %v", res[0].OwnerID))
+			},
+		},
+		{
+			name: "List Todo including snoozed",
+			prepare: func(f *fields) {
+				f.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "todos" WHERE tenant_id = $1 AND owner_id = $2 AND archived_at IS NULL ORDER BY created_at desc`)).
+					WithArgs(owner.TenantID, owner.ID).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "text", "completed", "created_at", "updated_at", "snoozed_until"}).
+						AddRow("iKe0KxpurIn0E_6vzUDAr", "aa", false, time.Now(), time.Now(), now.Add(time.Hour))).
+					WillReturnError(nil)
+			},
+			args:    args{query: &model.TodoQuery{IncludeSnoozed: true, Now: now, Owner: owner}},
+			wantErr: false,
+			checkFunc: func(res []*model.Todo, err error) {
+				assert.Equal(t, 1, len(res), fmt.Sprintf("count res: expected 1 got %v", len(res)))
+				assert.NotNil(t, res[0].SnoozedUntil, "snoozedUntil: expected not nil")
+			},
+		},
+		{
+			name: "List Todo including archived",
+			prepare: func(f *fields) {
+				f.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "todos" WHERE tenant_id = $1 AND owner_id = $2 AND (snoozed_until IS NULL OR snoozed_until <= $3) ORDER BY created_at desc`)).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "text", "completed", "created_at", "updated_at", "archived_at"}).
+						AddRow("iKe0KxpurIn0E_6vzUDAr", "aa", true, time.Now(), time.Now(), time.Now())).
+					WillReturnError(nil)
+			},
+			args:    args{query: &model.TodoQuery{IncludeArchived: true}},
+			wantErr: false,
+			checkFunc: func(res []*model.Todo, err error) {
+				assert.Equal(t, 1, len(res), fmt.Sprintf("count res: expected 1 got %v", len(res)))
+				assert.NotNil(t, res[0].ArchivedAt, "archivedAt: expected not nil")
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -174,8 +420,8 @@ func TestTodoRepository_List(t *testing.T) {
 			gdb, err := gorm.Open(postgres.New(postgres.Config{Conn: db}), &gorm.Config{})
 			repo := psql.New(gdb, log.NewLogfmtLogger(os.Stderr))
 
-			if res, err := repo.List(context.Background()); (err != nil) != tt.wantErr {
-				t.Errorf("List(ctx context.Context) error = %v, wantErr %v", err, tt.wantErr)
+			if res, err := repo.List(context.Background(), tt.args.query); (err != nil) != tt.wantErr {
+				t.Errorf("List(ctx context.Context, query *model.TodoQuery) error = %v, wantErr %v", err, tt.wantErr)
 			} else {
 				if tt.checkFunc != nil {
 					tt.checkFunc(res, err)
@@ -217,8 +463,8 @@ func TestTodoRepository_Get(t *testing.T) {
 				rows := sqlmock.NewRows([]string{"id", "text", "completed", "created_at", "updated_at"})
 				rows.AddRow(mTodo.ID, mTodo.Text, mTodo.Completed, mTodo.CreatedAt, mTodo.UpdatedAt)
 
-				f.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "todos" WHERE "id" = $1`)).
-					WithArgs(sqlmock.AnyArg()).
+				f.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "todos" WHERE tenant_id = $1 AND owner_id = $2 AND "id" = $3`)).
+					WithArgs(owner.TenantID, owner.ID, mTodo.ID).
 					WillReturnRows(rows).
 					WillReturnError(nil)
 			},
@@ -228,6 +474,22 @@ func TestTodoRepository_Get(t *testing.T) {
 
 			},
 		},
+		{
+			name: "Get Todo fail not found",
+			prepare: func(f *fields) {
+				rows := sqlmock.NewRows([]string{"id", "text", "completed", "created_at", "updated_at"})
+
+				f.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "todos" WHERE tenant_id = $1 AND owner_id = $2 AND "id" = $3`)).
+					WithArgs(owner.TenantID, owner.ID, mTodo.ID).
+					WillReturnRows(rows).
+					WillReturnError(nil)
+			},
+			args:    args{todoID: mTodo.ID},
+			wantErr: true,
+			checkFunc: func(res *model.Todo, err error) {
+				assert.Equal(t, err, service.ErrNotFound, fmt.Sprintf("err: expected service.ErrNotFound got %v", err))
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -247,7 +509,7 @@ func TestTodoRepository_Get(t *testing.T) {
 			gdb, err := gorm.Open(postgres.New(postgres.Config{Conn: db}), &gorm.Config{})
 			repo := psql.New(gdb, log.NewLogfmtLogger(os.Stderr))
 
-			if res, err := repo.Get(context.Background(), tt.args.todoID); (err != nil) != tt.wantErr {
+			if res, err := repo.Get(context.Background(), owner, tt.args.todoID); (err != nil) != tt.wantErr {
 				t.Errorf("Get(ctx context.Context id string) error = %v, wantErr %v", err, tt.wantErr)
 			} else {
 				if tt.checkFunc != nil {
@@ -274,7 +536,8 @@ func TestTodoRepository_Delete(t *testing.T) {
 	}
 
 	type args struct {
-		todoID string
+		todoID  string
+		version uint64
 	}
 
 	tests := []struct {
@@ -287,17 +550,29 @@ func TestTodoRepository_Delete(t *testing.T) {
 		{
 			name: "Delete Todo",
 			prepare: func(f *fields) {
-				f.mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "todos" WHERE "todos"."id" = $1`)).
-					WithArgs(sqlmock.AnyArg()).WillReturnResult(sqlmock.NewErrorResult(nil))
+				f.mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "todos" WHERE tenant_id = $1 AND owner_id = $2 AND "todos"."id" = $3`)).
+					WithArgs(owner.TenantID, owner.ID, mTodo.ID).WillReturnResult(sqlmock.NewResult(0, 1))
 			},
 			args:    args{todoID: mTodo.ID},
 			wantErr: false,
 		},
+		{
+			name: "Delete Todo fail of another owner",
+			prepare: func(f *fields) {
+				f.mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "todos" WHERE tenant_id = $1 AND owner_id = $2 AND "todos"."id" = $3`)).
+					WithArgs(owner.TenantID, owner.ID, mTodo.ID).WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			args:    args{todoID: mTodo.ID},
+			wantErr: true,
+			checkFunc: func(err error) {
+				assert.Equal(t, service.ErrNotFound, err, fmt.Sprintf("err: expected service.ErrNotFound got %v", err))
+			},
+		},
 		{
 			name: "Delete Todo fail not found",
 			prepare: func(f *fields) {
-				f.mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "todos" WHERE "todos"."id" = $1`)).
-					WithArgs(sqlmock.AnyArg()).
+				f.mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "todos" WHERE tenant_id = $1 AND owner_id = $2 AND "todos"."id" = $3`)).
+					WithArgs(owner.TenantID, owner.ID, mTodo.ID).
 					WillReturnResult(sqlmock.NewResult(0, 0)).
 					WillReturnError(sql.ErrNoRows)
 			},
@@ -307,6 +582,27 @@ func TestTodoRepository_Delete(t *testing.T) {
 				assert.Equal(t, err, sql.ErrNoRows, fmt.Sprintf("err: expected sql.ErrNoRows got %v", err))
 			},
 		},
+		{
+			name: "Delete Todo at version",
+			prepare: func(f *fields) {
+				f.mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "todos" WHERE tenant_id = $1 AND owner_id = $2 AND version = $3 AND "todos"."id" = $4`)).
+					WithArgs(owner.TenantID, owner.ID, 7, mTodo.ID).WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			args:    args{todoID: mTodo.ID, version: 7},
+			wantErr: false,
+		},
+		{
+			name: "Delete Todo fail with changed version",
+			prepare: func(f *fields) {
+				f.mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "todos" WHERE tenant_id = $1 AND owner_id = $2 AND version = $3 AND "todos"."id" = $4`)).
+					WithArgs(owner.TenantID, owner.ID, 7, mTodo.ID).WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			args:    args{todoID: mTodo.ID, version: 7},
+			wantErr: true,
+			checkFunc: func(err error) {
+				assert.Equal(t, service.ErrConflict, err, fmt.Sprintf("err: expected service.ErrConflict got %v", err))
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -326,8 +622,8 @@ func TestTodoRepository_Delete(t *testing.T) {
 			gdb, err := gorm.Open(postgres.New(postgres.Config{Conn: db}), &gorm.Config{})
 			repo := psql.New(gdb, log.NewLogfmtLogger(os.Stderr))
 
-			if err := repo.Delete(context.Background(), tt.args.todoID); (err != nil) != tt.wantErr {
-				t.Errorf("Delete(ctx context.Context id string) error = %v, wantErr %v", err, tt.wantErr)
+			if err := repo.Delete(context.Background(), owner, tt.args.todoID, tt.args.version); (err != nil) != tt.wantErr {
+				t.Errorf("Delete(ctx context.Context id string version uint64) error = %v, wantErr %v", err, tt.wantErr)
 			} else {
 				if tt.checkFunc != nil {
 					tt.checkFunc(err)
@@ -346,6 +642,7 @@ func TestTodoRepository_Update(t *testing.T) {
 			Text:      "aa",
 			Completed: false,
 		}
+		updated = &model.Todo{ID: "iKe0KxpurIn0E_6vzUDAr", OwnerID: owner.ID, TenantID: owner.TenantID, Text: "aa"}
 	)
 
 	type fields struct {
@@ -366,19 +663,47 @@ func TestTodoRepository_Update(t *testing.T) {
 		{
 			name: "Update Todo",
 			prepare: func(f *fields) {
-				f.mock.ExpectExec(regexp.QuoteMeta(`UPDATE "todos"`)).
-					WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
-					WillReturnResult(sqlmock.NewResult(0, 1))
+				f.mock.ExpectQuery(regexp.QuoteMeta(`UPDATE todos SET text = $1, completed = $2, completed_at = $3, archived_at = $4, snoozed_until = $5, updated_at = $6 WHERE id = $7 AND tenant_id = $8 AND owner_id = $9 RETURNING version`)).
+					WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), updated.ID, owner.TenantID, owner.ID).
+					WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(8))
 			},
-			args:    args{todo: mTodo},
+			args:    args{todo: updated},
 			wantErr: false,
+			checkFunc: func(err error) {
+				assert.Equal(t, uint64(8), updated.Version, fmt.Sprintf("version: expected 8 got %d", updated.Version))
+			},
+		},
+		{
+			name: "Update Todo fail with changed version",
+			prepare: func(f *fields) {
+				f.mock.ExpectQuery(regexp.QuoteMeta(`UPDATE todos SET text = $1, completed = $2, completed_at = $3, archived_at = $4, snoozed_until = $5, updated_at = $6 WHERE id = $7 AND tenant_id = $8 AND owner_id = $9 AND version = $10 RETURNING version`)).
+					WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), "iKe0KxpurIn0E_6vzUDAr", owner.TenantID, owner.ID, 7).
+					WillReturnRows(sqlmock.NewRows([]string{"version"}))
+			},
+			args:    args{todo: &model.Todo{ID: "iKe0KxpurIn0E_6vzUDAr", OwnerID: owner.ID, TenantID: owner.TenantID, Version: 7}},
+			wantErr: true,
+			checkFunc: func(err error) {
+				assert.Equal(t, service.ErrConflict, err, fmt.Sprintf("err: expected service.ErrConflict got %v", err))
+			},
+		},
+		{
+			name: "Update Todo fail of another owner",
+			prepare: func(f *fields) {
+				f.mock.ExpectQuery(regexp.QuoteMeta(`UPDATE todos SET text = $1, completed = $2, completed_at = $3, archived_at = $4, snoozed_until = $5, updated_at = $6 WHERE id = $7 AND tenant_id = $8 AND owner_id = $9 RETURNING version`)).
+					WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), "iKe0KxpurIn0E_6vzUDAr", owner.TenantID, owner.ID).
+					WillReturnRows(sqlmock.NewRows([]string{"version"}))
+			},
+			args:    args{todo: &model.Todo{ID: "iKe0KxpurIn0E_6vzUDAr", OwnerID: owner.ID, TenantID: owner.TenantID}},
+			wantErr: true,
+			checkFunc: func(err error) {
+				assert.Equal(t, service.ErrNotFound, err, fmt.Sprintf("err: expected service.ErrNotFound got %v", err))
+			},
 		},
 		{
 			name: "Update Todo fail with wrong type",
 			prepare: func(f *fields) {
-				f.mock.ExpectExec(regexp.QuoteMeta(`UPDATE "todos"`)).
-					WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
-					WillReturnResult(sqlmock.NewResult(0, 0)).
+				f.mock.ExpectQuery(regexp.QuoteMeta(`UPDATE todos`)).
+					WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
 					WillReturnError(sql.ErrNoRows)
 			},
 			args:    args{todo: mTodo},
@@ -416,3 +741,305 @@ func TestTodoRepository_Update(t *testing.T) {
 		})
 	}
 }
+
+func TestTodoRepository_Stats(t *testing.T) {
+	type fields struct {
+		mock sqlmock.Sqlmock
+	}
+
+	type args struct {
+		since time.Time
+	}
+
+	tests := []struct {
+		name      string
+		prepare   func(f *fields)
+		args      args
+		checkFunc func(res *model.TodoStats, err error)
+		wantErr   bool
+	}{
+		{
+			name: "Stats Todo",
+			prepare: func(f *fields) {
+				f.mock.ExpectQuery(`SELECT count\(\*\) AS total, (.+) FROM todos WHERE tenant_id = \$1 AND owner_id = \$2`).
+					WithArgs(owner.TenantID, owner.ID).
+					WillReturnRows(sqlmock.NewRows([]string{"total", "completed", "avg_time_to_complete"}).AddRow(3, 1, 60.5))
+				f.mock.ExpectQuery(`SELECT (.+) AS day, count\(\*\) AS count (.+) GROUP BY 1 ORDER BY 1`).
+					WithArgs(owner.TenantID, owner.ID, sqlmock.AnyArg()).
+					WillReturnRows(sqlmock.NewRows([]string{"day", "count"}).AddRow("2020-12-30", 1))
+			},
+			args:    args{since: time.Now()},
+			wantErr: false,
+			checkFunc: func(res *model.TodoStats, err error) {
+				assert.Equal(t, int64(3), res.Total, fmt.Sprintf("total: expected 3 got %v", res.Total))
+				assert.Equal(t, int64(2), res.Active, fmt.Sprintf("active: expected 2 got %v", res.Active))
+				assert.Equal(t, int64(1), res.Completed, fmt.Sprintf("completed: expected 1 got %v", res.Completed))
+				assert.Equal(t, 60.5, res.AvgTimeToComplete, fmt.Sprintf("avgTimeToComplete: expected 60.5 got %v", res.AvgTimeToComplete))
+				assert.Equal(t, []*model.DayCount{{Day: "2020-12-30", Count: 1}}, res.CompletedPerDay)
+			},
+		},
+		{
+			name: "Stats Todo fail",
+			prepare: func(f *fields) {
+				f.mock.ExpectQuery(`SELECT count\(\*\) AS total, (.+) FROM todos`).
+					WillReturnError(sql.ErrConnDone)
+			},
+			args:    args{since: time.Now()},
+			wantErr: true,
+			checkFunc: func(res *model.TodoStats, err error) {
+				assert.ErrorIs(t, err, sql.ErrConnDone, fmt.Sprintf("err: expected sql.ErrConnDone got %v", err))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+			}
+			defer db.Close()
+
+			f := fields{
+				mock: mock,
+			}
+			if tt.prepare != nil {
+				tt.prepare(&f)
+			}
+			gdb, err := gorm.Open(postgres.New(postgres.Config{Conn: db}), &gorm.Config{})
+			repo := psql.New(gdb, log.NewLogfmtLogger(os.Stderr))
+
+			if res, err := repo.Stats(context.Background(), owner, tt.args.since); (err != nil) != tt.wantErr {
+				t.Errorf("Stats(ctx context.Context, since time.Time) error = %v, wantErr %v", err, tt.wantErr)
+			} else {
+				if tt.checkFunc != nil {
+					tt.checkFunc(res, err)
+				}
+			}
+		})
+	}
+}
+
+func TestTodoRepository_Usage(t *testing.T) {
+	type fields struct {
+		mock sqlmock.Sqlmock
+	}
+
+	tests := []struct {
+		name      string
+		prepare   func(f *fields)
+		checkFunc func(res *model.Usage, err error)
+		wantErr   bool
+	}{
+		{
+			name: "Usage Todo",
+			prepare: func(f *fields) {
+				f.mock.ExpectQuery(`SELECT count\(\*\) FILTER \(WHERE owner_id = \$1\) AS todos, count\(\*\) AS tenant_todos FROM todos WHERE tenant_id = \$2`).
+					WithArgs(owner.ID, owner.TenantID).
+					WillReturnRows(sqlmock.NewRows([]string{"todos", "tenant_todos"}).AddRow(2, 5))
+			},
+			wantErr: false,
+			checkFunc: func(res *model.Usage, err error) {
+				assert.Equal(t, int64(2), res.Todos, fmt.Sprintf("todos: expected 2 got %v", res.Todos))
+				assert.Equal(t, int64(5), res.TenantTodos, fmt.Sprintf("tenantTodos: expected 5 got %v", res.TenantTodos))
+			},
+		},
+		{
+			name: "Usage Todo fail",
+			prepare: func(f *fields) {
+				f.mock.ExpectQuery(`SELECT count\(\*\) FILTER (.+) FROM todos`).
+					WillReturnError(sql.ErrConnDone)
+			},
+			wantErr: true,
+			checkFunc: func(res *model.Usage, err error) {
+				assert.ErrorIs(t, err, sql.ErrConnDone, fmt.Sprintf("err: expected sql.ErrConnDone got %v", err))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+			}
+			defer db.Close()
+
+			f := fields{
+				mock: mock,
+			}
+			if tt.prepare != nil {
+				tt.prepare(&f)
+			}
+			gdb, err := gorm.Open(postgres.New(postgres.Config{Conn: db}), &gorm.Config{})
+			repo := psql.New(gdb, log.NewLogfmtLogger(os.Stderr))
+
+			if res, err := repo.Usage(context.Background(), owner); (err != nil) != tt.wantErr {
+				t.Errorf("Usage(ctx context.Context, owner model.Owner) error = %v, wantErr %v", err, tt.wantErr)
+			} else {
+				if tt.checkFunc != nil {
+					tt.checkFunc(res, err)
+				}
+			}
+		})
+	}
+}
+
+func TestTodoRepository_Archive(t *testing.T) {
+	type fields struct {
+		mock sqlmock.Sqlmock
+	}
+
+	type args struct {
+		completedBefore time.Time
+		archivedAt      time.Time
+	}
+
+	tests := []struct {
+		name      string
+		prepare   func(f *fields)
+		args      args
+		checkFunc func(n int64, err error)
+		wantErr   bool
+	}{
+		{
+			name: "Archive Todo",
+			prepare: func(f *fields) {
+				f.mock.ExpectExec(regexp.QuoteMeta(`UPDATE "todos" SET "archived_at"=$1 WHERE completed AND completed_at < $2 AND updated_at < $3 AND archived_at IS NULL`)).
+					WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(0, 2))
+			},
+			args:    args{completedBefore: time.Now().Add(-time.Hour), archivedAt: time.Now()},
+			wantErr: false,
+			checkFunc: func(n int64, err error) {
+				assert.Equal(t, int64(2), n, fmt.Sprintf("archived: expected 2 got %v", n))
+			},
+		},
+		{
+			name: "Archive Todo fail",
+			prepare: func(f *fields) {
+				f.mock.ExpectExec(regexp.QuoteMeta(`UPDATE "todos"`)).
+					WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+					WillReturnError(sql.ErrConnDone)
+			},
+			args:    args{completedBefore: time.Now().Add(-time.Hour), archivedAt: time.Now()},
+			wantErr: true,
+			checkFunc: func(n int64, err error) {
+				assert.ErrorIs(t, err, sql.ErrConnDone, fmt.Sprintf("err: expected sql.ErrConnDone got %v", err))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+			}
+			defer db.Close()
+
+			f := fields{
+				mock: mock,
+			}
+			if tt.prepare != nil {
+				tt.prepare(&f)
+			}
+			gdb, err := gorm.Open(postgres.New(postgres.Config{Conn: db}), &gorm.Config{})
+			repo := psql.New(gdb, log.NewLogfmtLogger(os.Stderr))
+
+			if n, err := repo.Archive(context.Background(), tt.args.completedBefore, tt.args.archivedAt); (err != nil) != tt.wantErr {
+				t.Errorf("Archive(ctx context.Context, completedBefore, archivedAt time.Time) error = %v, wantErr %v", err, tt.wantErr)
+			} else {
+				if tt.checkFunc != nil {
+					tt.checkFunc(n, err)
+				}
+			}
+		})
+	}
+}
+
+func TestTodoRepository_Changes(t *testing.T) {
+	type fields struct {
+		mock sqlmock.Sqlmock
+	}
+
+	type args struct {
+		since model.ChangeCursor
+		limit int
+	}
+
+	tests := []struct {
+		name      string
+		prepare   func(f *fields)
+		args      args
+		checkFunc func(todos []*model.Todo, tombstones []*model.Tombstone, err error)
+		wantErr   bool
+	}{
+		{
+			name: "Changes Todo",
+			prepare: func(f *fields) {
+				f.mock.ExpectBegin()
+				f.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "todos" WHERE tenant_id = $1 AND owner_id = $2 AND (tx_id, version) > ($3, $4) AND tx_id < txid_snapshot_xmin(txid_current_snapshot()) ORDER BY tx_id, version LIMIT 2`)).
+					WithArgs(owner.TenantID, owner.ID, 10, 3).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "text", "version"}).AddRow("iKe0KxpurIn0E_6vzUDAr", "aa", 4).AddRow("zIYPEK0zEpUc7CoQWIGB2", "bb", 6))
+				f.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "tombstones" WHERE tenant_id = $1 AND owner_id = $2 AND (tx_id, version) > ($3, $4) AND tx_id < txid_snapshot_xmin(txid_current_snapshot()) ORDER BY tx_id, version LIMIT 2`)).
+					WithArgs(owner.TenantID, owner.ID, 10, 3).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "version", "deleted_at"}).AddRow("b5z2zC5c9O6~Ns_qLVmn~", 5, time.Now()))
+				f.mock.ExpectCommit()
+			},
+			args:    args{since: model.ChangeCursor{TxID: 10, Version: 3}, limit: 2},
+			wantErr: false,
+			checkFunc: func(todos []*model.Todo, tombstones []*model.Tombstone, err error) {
+				assert.Len(t, todos, 2, fmt.Sprintf("todos: expected 2 got %v", len(todos)))
+				assert.Equal(t, uint64(6), todos[1].Version)
+				assert.Len(t, tombstones, 1, fmt.Sprintf("tombstones: expected 1 got %v", len(tombstones)))
+				assert.Equal(t, "b5z2zC5c9O6~Ns_qLVmn~", tombstones[0].ID)
+			},
+		},
+		{
+			name: "Changes Todo fail",
+			prepare: func(f *fields) {
+				f.mock.ExpectBegin()
+				f.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "todos"`)).
+					WithArgs(owner.TenantID, owner.ID, 10, 3).
+					WillReturnError(sql.ErrConnDone)
+				f.mock.ExpectRollback()
+			},
+			args:    args{since: model.ChangeCursor{TxID: 10, Version: 3}, limit: 2},
+			wantErr: true,
+			checkFunc: func(todos []*model.Todo, tombstones []*model.Tombstone, err error) {
+				assert.ErrorIs(t, err, sql.ErrConnDone, fmt.Sprintf("err: expected sql.ErrConnDone got %v", err))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+			}
+			defer db.Close()
+
+			f := fields{
+				mock: mock,
+			}
+			if tt.prepare != nil {
+				tt.prepare(&f)
+			}
+			gdb, err := gorm.Open(postgres.New(postgres.Config{Conn: db}), &gorm.Config{})
+			repo := psql.New(gdb, log.NewLogfmtLogger(os.Stderr))
+
+			if todos, tombstones, err := repo.Changes(context.Background(), owner, tt.args.since, tt.args.limit); (err != nil) != tt.wantErr {
+				t.Errorf("Changes(ctx context.Context, since model.ChangeCursor, limit int) error = %v, wantErr %v", err, tt.wantErr)
+			} else {
+				if tt.checkFunc != nil {
+					tt.checkFunc(todos, tombstones, err)
+				}
+			}
+			if err := f.mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("there were unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,125 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	jwt "github.com/dgrijalva/jwt-go"
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+
+	"github.com/cage1016/gokit-todo/internal/app/todo/model"
+	"github.com/cage1016/gokit-todo/internal/pkg/authn"
+	"github.com/cage1016/gokit-todo/internal/pkg/authz"
+	"github.com/cage1016/gokit-todo/internal/pkg/errors"
+)
+
+var (
+	// ErrAPIKeyExpired is returned for the API keys used past their expiry.
+	ErrAPIKeyExpired = errors.New("API key expired")
+
+	// ErrAPIKeysDisabled denies the API key methods of a service without an
+	// Authorizer, as nothing tells the administrators apart. It is wrapped by
+	// authz.ErrForbidden.
+	ErrAPIKeysDisabled = errors.New("API keys need an authorization policy")
+)
+
+const (
+	// APIKeyResource is the resource of the API keys, as named to the
+	// Authorizer.
+	APIKeyResource = "apikey"
+	// APIKeyAdminAction on APIKeyResource lets a caller create the API keys
+	// of other subjects.
+	APIKeyAdminAction = "admin"
+)
+
+// Authorizer checks the permissions of the callers of the API key methods,
+// as authz.FileEngine does.
+type Authorizer interface {
+	authz.Engine
+	// RolesOf returns the roles of the caller of ctx, which bound the scopes
+	// of the API keys it creates.
+	RolesOf(ctx context.Context) []string
+}
+
+// WithAuthorizer enables the API key methods, which deny every caller
+// without it. The keys of other subjects need APIKeyAdminAction, and the
+// scopes of a key have to be roles of its creator.
+func WithAuthorizer(a Authorizer) Option {
+	return func(s *stubTodoService) {
+		s.authorizer = a
+	}
+}
+
+// checkAPIKey checks that the caller of ctx may create an API key
+// authenticating as subject with scopes.
+func (to *stubTodoService) checkAPIKey(ctx context.Context, subject string, scopes []string) error {
+	if subject != ownerOf(ctx).ID {
+		if err := to.authorizer.Authorize(ctx, APIKeyAdminAction, APIKeyResource); err != nil {
+			return err
+		}
+	}
+
+	roles := map[string]bool{}
+	for _, role := range to.authorizer.RolesOf(ctx) {
+		roles[role] = true
+	}
+	for _, scope := range scopes {
+		if !roles[scope] {
+			return errors.Wrap(authz.ErrForbidden, fmt.Errorf("scope %q is not a role of the caller", scope))
+		}
+	}
+	return nil
+}
+
+// APIKeyTouchInterval is how often the last use of an API key is recorded,
+// so that a busy caller does not write on every request.
+const APIKeyTouchInterval = time.Minute
+
+var _ authn.APIKeys = (*APIKeyAuthenticator)(nil)
+
+// APIKeyAuthenticator authenticates the API keys stored by the repository
+// and records when they are used.
+type APIKeyAuthenticator struct {
+	repo   model.TodoRepository
+	logger log.Logger
+}
+
+// NewAPIKeyAuthenticator returns an APIKeyAuthenticator looking the keys up
+// in repo.
+func NewAPIKeyAuthenticator(repo model.TodoRepository, logger log.Logger) *APIKeyAuthenticator {
+	return &APIKeyAuthenticator{repo: repo, logger: logger}
+}
+
+// Authenticate implements authn.APIKeys, the claims carry the subject, the
+// tenant and the scopes, as roles, of the key, and are marked as the ones of
+// an API key.
+func (a *APIKeyAuthenticator) Authenticate(ctx context.Context, key string) (*authn.Claims, error) {
+	k, err := a.repo.APIKey(ctx, authn.HashAPIKey(key))
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if k.ExpiresAt != nil && !now.Before(*k.ExpiresAt) {
+		return nil, ErrAPIKeyExpired
+	}
+	if k.LastUsedAt == nil || now.Sub(*k.LastUsedAt) >= APIKeyTouchInterval {
+		// failing to record the use of a key does not fail the request
+		if err := a.repo.TouchAPIKey(ctx, k.ID, now); err != nil {
+			level.Warn(a.logger).Log("method", "TouchAPIKey", "id", k.ID, "err", err)
+		}
+	}
+
+	claims := &authn.Claims{
+		StandardClaims: jwt.StandardClaims{Id: k.ID, Subject: k.Subject},
+		Tenant:         k.TenantID,
+		Roles:          k.Scopes,
+		APIKey:         true,
+	}
+	if k.ExpiresAt != nil {
+		claims.ExpiresAt = k.ExpiresAt.Unix()
+	}
+	return claims, nil
+}
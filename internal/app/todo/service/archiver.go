@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+
+	"github.com/cage1016/gokit-todo/internal/app/todo/model"
+)
+
+// Archiver periodically archives the todos completed longer than a
+// retention window ago.
+type Archiver struct {
+	repo     model.TodoRepository
+	after    time.Duration
+	interval time.Duration
+	logger   log.Logger
+	now      func() time.Time
+}
+
+// ArchiverOption configures the Archiver returned by NewArchiver.
+type ArchiverOption func(*Archiver)
+
+// WithArchiverClock makes the archiver read the current time from now
+// instead of time.Now, as WithClock does for the service.
+func WithArchiverClock(now func() time.Time) ArchiverOption {
+	return func(a *Archiver) {
+		a.now = now
+	}
+}
+
+// NewArchiver returns an Archiver that archives, every interval, the todos
+// completed more than after ago.
+func NewArchiver(repo model.TodoRepository, after, interval time.Duration, logger log.Logger, opts ...ArchiverOption) *Archiver {
+	a := &Archiver{
+		repo:     repo,
+		after:    after,
+		interval: interval,
+		logger:   logger,
+		now:      time.Now,
+	}
+	for _, opt := range opts {
+		opt(a)
+	}
+	return a
+}
+
+// Run archives the expired todos every interval until ctx is cancelled.
+func (a *Archiver) Run(ctx context.Context) {
+	ticker := time.NewTicker(a.interval)
+	defer ticker.Stop()
+
+	for {
+		if n, err := a.Archive(ctx); err != nil {
+			level.Error(a.logger).Log("method", "Archive", "err", err)
+		} else if n > 0 {
+			level.Info(a.logger).Log("method", "Archive", "archived", n)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Archive archives the todos completed, and left unchanged, for more than
+// the retention window and returns how many were archived.
+func (a *Archiver) Archive(ctx context.Context) (int64, error) {
+	now := a.now()
+	return a.repo.Archive(ctx, now.Add(-a.after), now)
+}
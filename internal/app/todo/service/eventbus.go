@@ -0,0 +1,140 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/cage1016/gokit-todo/internal/app/todo/model"
+)
+
+const (
+	// DefaultEventBacklog is the number of past events kept by the event bus
+	// for the watchers resuming from a sequence number.
+	DefaultEventBacklog = 1024
+	// eventBuffer is the number of events buffered for every watcher, a
+	// watcher falling further behind is disconnected.
+	eventBuffer = 64
+)
+
+// EventBus publishes the todo events to their watchers. It keeps the last
+// events in a ring buffer so watchers can resume after a disconnection.
+//
+// Events are either numbered by Publish, in memory, or numbered by the
+// database and forwarded with Forward so every replica uses the same
+// sequence numbers. The forwarded events come in commit order, which is not
+// the order of their numbers, so watchers resume after the position of their
+// last event rather than after its number.
+type EventBus struct {
+	mu      sync.Mutex
+	seq     uint64
+	backlog []*model.TodoEvent
+	next    int
+	subs    map[chan *model.TodoEvent]struct{}
+}
+
+// NewEventBus returns an EventBus keeping the last backlog events.
+func NewEventBus(backlog int) *EventBus {
+	if backlog <= 0 {
+		backlog = DefaultEventBacklog
+	}
+	return &EventBus{
+		backlog: make([]*model.TodoEvent, backlog),
+		subs:    make(map[chan *model.TodoEvent]struct{}),
+	}
+}
+
+// Publish assigns the next sequence number to an event of the given type
+// and sends it to every watcher.
+func (b *EventBus) Publish(eventType string, todo *model.TodoRes, at time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.seq++
+	b.send(&model.TodoEvent{Seq: b.seq, Type: eventType, Todo: todo, Time: at})
+}
+
+// Forward sends an event already numbered to every watcher. The events have
+// to be forwarded in order, and Reset called once some may have been
+// missed.
+func (b *EventBus) Forward(ev *model.TodoEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.send(ev)
+}
+
+// Reset forgets the past events and disconnects every watcher, which is
+// needed once events may have been missed. Watchers resuming from a past
+// event get ErrSequenceExpired and have to reload.
+func (b *EventBus) Reset() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.reset()
+}
+
+func (b *EventBus) reset() {
+	for i := range b.backlog {
+		b.backlog[i] = nil
+	}
+	b.next = 0
+	for ch := range b.subs {
+		delete(b.subs, ch)
+		close(ch)
+	}
+}
+
+// send keeps ev in the backlog and sends it to every watcher.
+func (b *EventBus) send(ev *model.TodoEvent) {
+	b.backlog[b.next] = ev
+	b.next = (b.next + 1) % len(b.backlog)
+
+	for ch := range b.subs {
+		select {
+		case ch <- ev:
+		default:
+			// the watcher is too slow, let it resume from its last event
+			delete(b.subs, ch)
+			close(ch)
+		}
+	}
+}
+
+// Subscribe returns the events sent after the one numbered since, or only the
+// new ones if since is zero, until ctx is done. The returned
+// channel is closed when ctx is done or when the watcher falls behind.
+func (b *EventBus) Subscribe(ctx context.Context, since uint64) (<-chan *model.TodoEvent, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var missed []*model.TodoEvent
+	if since > 0 {
+		found := false
+		for i := range b.backlog {
+			ev := b.backlog[(b.next+i)%len(b.backlog)]
+			if found && ev != nil {
+				missed = append(missed, ev)
+			}
+			found = found || ev != nil && ev.Seq == since
+		}
+		if !found {
+			return nil, ErrSequenceExpired
+		}
+	}
+
+	ch := make(chan *model.TodoEvent, len(missed)+eventBuffer)
+	for _, ev := range missed {
+		ch <- ev
+	}
+	b.subs[ch] = struct{}{}
+
+	go func() {
+		<-ctx.Done()
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		if _, ok := b.subs[ch]; ok {
+			delete(b.subs, ch)
+			close(ch)
+		}
+	}()
+	return ch, nil
+}
@@ -0,0 +1,201 @@
+// +build !integration
+
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/cage1016/gokit-todo/internal/app/todo/model"
+	"github.com/cage1016/gokit-todo/internal/app/todo/service"
+)
+
+func TestEventBus_Subscribe(t *testing.T) {
+	now := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		backlog   int
+		published int
+		since     uint64
+		wantErr   error
+		wantSeqs  []uint64
+	}{
+		{
+			name:      "subscribe to new events",
+			backlog:   4,
+			published: 2,
+			since:     0,
+			wantSeqs:  nil,
+		},
+		{
+			name:      "resume from a sequence",
+			backlog:   4,
+			published: 3,
+			since:     1,
+			wantSeqs:  []uint64{2, 3},
+		},
+		{
+			name:      "resume from the last sequence",
+			backlog:   4,
+			published: 3,
+			since:     3,
+			wantSeqs:  nil,
+		},
+		{
+			name:      "resume from a sequence out of the backlog",
+			backlog:   2,
+			published: 5,
+			since:     1,
+			wantErr:   service.ErrSequenceExpired,
+		},
+		{
+			name:      "resume from a sequence not published yet",
+			backlog:   4,
+			published: 1,
+			since:     3,
+			wantErr:   service.ErrSequenceExpired,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bus := service.NewEventBus(tt.backlog)
+			for i := 0; i < tt.published; i++ {
+				bus.Publish(model.EventCreated, &model.TodoRes{ID: "b5z2zC5c9O6~Ns_qLVmn~"}, now)
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			events, err := bus.Subscribe(ctx, tt.since)
+			if tt.wantErr != nil {
+				assert.Equal(t, tt.wantErr, err)
+				return
+			}
+			assert.Nil(t, err)
+
+			var seqs []uint64
+			for len(seqs) < len(tt.wantSeqs) {
+				seqs = append(seqs, (<-events).Seq)
+			}
+			assert.Equal(t, tt.wantSeqs, seqs)
+
+			bus.Publish(model.EventDeleted, &model.TodoRes{ID: "b5z2zC5c9O6~Ns_qLVmn~"}, now)
+			ev := <-events
+			assert.Equal(t, uint64(tt.published+1), ev.Seq)
+			assert.Equal(t, model.EventDeleted, ev.Type)
+
+			cancel()
+			for range events {
+			}
+		})
+	}
+}
+
+func TestEventBus_SlowWatcher(t *testing.T) {
+	bus := service.NewEventBus(service.DefaultEventBacklog)
+	events, err := bus.Subscribe(context.Background(), 0)
+	assert.Nil(t, err)
+
+	for i := 0; i < service.DefaultEventBacklog; i++ {
+		bus.Publish(model.EventUpdated, &model.TodoRes{ID: "b5z2zC5c9O6~Ns_qLVmn~"}, time.Now())
+	}
+
+	n := 0
+	for range events {
+		n++
+	}
+	assert.True(t, n < service.DefaultEventBacklog, "slow watcher: expected to be disconnected")
+}
+
+func TestEventBus_Forward(t *testing.T) {
+	event := func(seq uint64) *model.TodoEvent {
+		return &model.TodoEvent{Seq: seq, Type: model.EventUpdated, Todo: &model.TodoRes{ID: "b5z2zC5c9O6~Ns_qLVmn~"}}
+	}
+
+	tests := []struct {
+		name      string
+		forwarded []uint64
+		since     uint64
+		wantErr   error
+		wantSeqs  []uint64
+	}{
+		{
+			name:      "forward events in sequence",
+			forwarded: []uint64{7, 8, 9},
+			since:     7,
+			wantSeqs:  []uint64{8, 9},
+		},
+		{
+			name:      "forward events in commit order",
+			forwarded: []uint64{8, 7, 10, 9},
+			since:     8,
+			wantSeqs:  []uint64{7, 10, 9},
+		},
+		{
+			name:      "resume from the last forwarded event",
+			forwarded: []uint64{8, 7},
+			since:     7,
+			wantSeqs:  nil,
+		},
+		{
+			name:      "resume from an event not forwarded",
+			forwarded: []uint64{8, 7},
+			since:     9,
+			wantErr:   service.ErrSequenceExpired,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bus := service.NewEventBus(service.DefaultEventBacklog)
+			watcher, err := bus.Subscribe(context.Background(), 0)
+			assert.Nil(t, err)
+
+			for _, seq := range tt.forwarded {
+				bus.Forward(event(seq))
+			}
+
+			var seqs []uint64
+			for range tt.forwarded {
+				seqs = append(seqs, (<-watcher).Seq)
+			}
+			assert.Equal(t, tt.forwarded, seqs)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			events, err := bus.Subscribe(ctx, tt.since)
+			if tt.wantErr != nil {
+				assert.Equal(t, tt.wantErr, err)
+				return
+			}
+			assert.Nil(t, err)
+
+			seqs = nil
+			for len(seqs) < len(tt.wantSeqs) {
+				seqs = append(seqs, (<-events).Seq)
+			}
+			assert.Equal(t, tt.wantSeqs, seqs)
+		})
+	}
+}
+
+func TestEventBus_Reset(t *testing.T) {
+	bus := service.NewEventBus(service.DefaultEventBacklog)
+	bus.Publish(model.EventCreated, &model.TodoRes{ID: "b5z2zC5c9O6~Ns_qLVmn~"}, time.Now())
+
+	events, err := bus.Subscribe(context.Background(), 0)
+	assert.Nil(t, err)
+
+	bus.Reset()
+	_, ok := <-events
+	assert.False(t, ok, "watcher: expected to be disconnected")
+
+	_, err = bus.Subscribe(context.Background(), 1)
+	assert.Equal(t, service.ErrSequenceExpired, err)
+}
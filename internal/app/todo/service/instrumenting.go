@@ -0,0 +1,187 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-kit/kit/metrics"
+
+	"github.com/cage1016/gokit-todo/internal/app/todo/model"
+)
+
+type instrumentingMiddleware struct {
+	requestCount   metrics.Counter   `json:""`
+	requestLatency metrics.Histogram `json:""`
+	next           TodoService       `json:""`
+}
+
+// InstrumentingMiddleware takes a counter and a histogram as dependencies
+// and returns a ServiceMiddleware counting the calls of each method and
+// observing their duration in seconds, labelled with the method and whether
+// it failed.
+func InstrumentingMiddleware(requestCount metrics.Counter, requestLatency metrics.Histogram) Middleware {
+	return func(next TodoService) TodoService {
+		return instrumentingMiddleware{requestCount, requestLatency, next}
+	}
+}
+
+// WithInstrumenting makes the service instrument its methods with
+// InstrumentingMiddleware.
+func WithInstrumenting(requestCount metrics.Counter, requestLatency metrics.Histogram) Option {
+	return func(s *stubTodoService) {
+		s.requestCount = requestCount
+		s.requestLatency = requestLatency
+	}
+}
+
+// observe records a call of method which returned err, begun at begin.
+func (im instrumentingMiddleware) observe(method string, err error, begin time.Time) {
+	lvs := []string{"method", method, "error", fmt.Sprint(err != nil)}
+	im.requestCount.With(lvs...).Add(1)
+	im.requestLatency.With(lvs...).Observe(time.Since(begin).Seconds())
+}
+
+func (im instrumentingMiddleware) Add(ctx context.Context, todo *model.TodoReq) (res *model.TodoRes, err error) {
+	defer func(begin time.Time) {
+		im.observe("Add", err, begin)
+	}(time.Now())
+
+	return im.next.Add(ctx, todo)
+}
+
+func (im instrumentingMiddleware) Delete(ctx context.Context, id string) (err error) {
+	defer func(begin time.Time) {
+		im.observe("Delete", err, begin)
+	}(time.Now())
+
+	return im.next.Delete(ctx, id)
+}
+
+func (im instrumentingMiddleware) Update(ctx context.Context, id string, todo *model.TodoReq) (res *model.TodoRes, err error) {
+	defer func(begin time.Time) {
+		im.observe("Update", err, begin)
+	}(time.Now())
+
+	return im.next.Update(ctx, id, todo)
+}
+
+func (im instrumentingMiddleware) List(ctx context.Context, query *model.TodoQuery) (res []*model.TodoRes, err error) {
+	defer func(begin time.Time) {
+		im.observe("List", err, begin)
+	}(time.Now())
+
+	return im.next.List(ctx, query)
+}
+
+func (im instrumentingMiddleware) Get(ctx context.Context, id string) (res *model.TodoRes, err error) {
+	defer func(begin time.Time) {
+		im.observe("Get", err, begin)
+	}(time.Now())
+
+	return im.next.Get(ctx, id)
+}
+
+func (im instrumentingMiddleware) Stats(ctx context.Context, days int) (res *model.TodoStats, err error) {
+	defer func(begin time.Time) {
+		im.observe("Stats", err, begin)
+	}(time.Now())
+
+	return im.next.Stats(ctx, days)
+}
+
+func (im instrumentingMiddleware) Unarchive(ctx context.Context, id string) (res *model.TodoRes, err error) {
+	defer func(begin time.Time) {
+		im.observe("Unarchive", err, begin)
+	}(time.Now())
+
+	return im.next.Unarchive(ctx, id)
+}
+
+func (im instrumentingMiddleware) Snooze(ctx context.Context, id string, until time.Time) (res *model.TodoRes, err error) {
+	defer func(begin time.Time) {
+		im.observe("Snooze", err, begin)
+	}(time.Now())
+
+	return im.next.Snooze(ctx, id, until)
+}
+
+func (im instrumentingMiddleware) Watch(ctx context.Context, query *model.EventQuery) (res <-chan *model.TodoEvent, err error) {
+	defer func(begin time.Time) {
+		im.observe("Watch", err, begin)
+	}(time.Now())
+
+	return im.next.Watch(ctx, query)
+}
+
+func (im instrumentingMiddleware) Sync(ctx context.Context, token string) (res *model.Changes, err error) {
+	defer func(begin time.Time) {
+		im.observe("Sync", err, begin)
+	}(time.Now())
+
+	return im.next.Sync(ctx, token)
+}
+
+func (im instrumentingMiddleware) Push(ctx context.Context, mutations []*model.Mutation) (res []*model.MutationResult, err error) {
+	defer func(begin time.Time) {
+		im.observe("Push", err, begin)
+	}(time.Now())
+
+	return im.next.Push(ctx, mutations)
+}
+
+func (im instrumentingMiddleware) AddShare(ctx context.Context, share *model.ShareReq) (res *model.Share, err error) {
+	defer func(begin time.Time) {
+		im.observe("AddShare", err, begin)
+	}(time.Now())
+
+	return im.next.AddShare(ctx, share)
+}
+
+func (im instrumentingMiddleware) DeleteShare(ctx context.Context, id string) (err error) {
+	defer func(begin time.Time) {
+		im.observe("DeleteShare", err, begin)
+	}(time.Now())
+
+	return im.next.DeleteShare(ctx, id)
+}
+
+func (im instrumentingMiddleware) ListShares(ctx context.Context) (res []*model.Share, err error) {
+	defer func(begin time.Time) {
+		im.observe("ListShares", err, begin)
+	}(time.Now())
+
+	return im.next.ListShares(ctx)
+}
+
+func (im instrumentingMiddleware) CreateAPIKey(ctx context.Context, key *model.APIKeyReq) (res *model.CreatedAPIKey, err error) {
+	defer func(begin time.Time) {
+		im.observe("CreateAPIKey", err, begin)
+	}(time.Now())
+
+	return im.next.CreateAPIKey(ctx, key)
+}
+
+func (im instrumentingMiddleware) RevokeAPIKey(ctx context.Context, id string) (err error) {
+	defer func(begin time.Time) {
+		im.observe("RevokeAPIKey", err, begin)
+	}(time.Now())
+
+	return im.next.RevokeAPIKey(ctx, id)
+}
+
+func (im instrumentingMiddleware) ListAPIKeys(ctx context.Context) (res []*model.APIKey, err error) {
+	defer func(begin time.Time) {
+		im.observe("ListAPIKeys", err, begin)
+	}(time.Now())
+
+	return im.next.ListAPIKeys(ctx)
+}
+
+func (im instrumentingMiddleware) Usage(ctx context.Context) (res *model.Usage, err error) {
+	defer func(begin time.Time) {
+		im.observe("Usage", err, begin)
+	}(time.Now())
+
+	return im.next.Usage(ctx)
+}
@@ -3,6 +3,9 @@ package service
 import (
 	"context"
 	"fmt"
+	"strconv"
+	"time"
+	"unicode/utf8"
 
 	"github.com/go-kit/kit/log"
 	"github.com/go-kit/kit/log/level"
@@ -12,20 +15,42 @@ import (
 
 type loggingMiddleware struct {
 	logger log.Logger  `json:""`
+	debug  log.Logger  `json:""`
 	next   TodoService `json:""`
 }
 
 // LoggingMiddleware takes a logger as a dependency
-// and returns a ServiceMiddleware.
+// and returns a ServiceMiddleware. The text of the todos is only logged at
+// the debug level.
 func LoggingMiddleware(logger log.Logger) Middleware {
 	return func(next TodoService) TodoService {
-		return loggingMiddleware{level.Info(logger), next}
+		return loggingMiddleware{level.Info(logger), level.Debug(logger), next}
 	}
 }
 
+// formatTodo formats todo, replacing its text with its length unless
+// withText is set.
+func formatTodo(todo *model.TodoReq, withText bool) string {
+	if todo == nil {
+		return "<nil>"
+	}
+	text, completed := "<nil>", "<nil>"
+	if todo.Text != nil {
+		text = strconv.Quote(*todo.Text)
+		if !withText {
+			text = fmt.Sprintf("[%d characters]", utf8.RuneCountInString(*todo.Text))
+		}
+	}
+	if todo.Completed != nil {
+		completed = strconv.FormatBool(*todo.Completed)
+	}
+	return fmt.Sprintf("{Text:%s Completed:%s}", text, completed)
+}
+
 func (lm loggingMiddleware) Add(ctx context.Context, todo *model.TodoReq) (res *model.TodoRes, err error) {
 	defer func() {
-		lm.logger.Log("method", "Add", "todo", fmt.Sprintf("%v", todo), "err", err)
+		lm.logger.Log("method", "Add", "todo", formatTodo(todo, false), "err", err)
+		lm.debug.Log("method", "Add", "todo", formatTodo(todo, true))
 	}()
 
 	return lm.next.Add(ctx, todo)
@@ -41,16 +66,129 @@ func (lm loggingMiddleware) Delete(ctx context.Context, id string) (err error) {
 
 func (lm loggingMiddleware) Update(ctx context.Context, id string, todo *model.TodoReq) (res *model.TodoRes, err error) {
 	defer func() {
-		lm.logger.Log("method", "Update", "id", id, "todo", fmt.Sprintf("%v", todo), "err", err)
+		lm.logger.Log("method", "Update", "id", id, "todo", formatTodo(todo, false), "err", err)
+		lm.debug.Log("method", "Update", "id", id, "todo", formatTodo(todo, true))
 	}()
 
 	return lm.next.Update(ctx, id, todo)
 }
 
-func (lm loggingMiddleware) List(ctx context.Context) (res []*model.TodoRes, err error) {
+func (lm loggingMiddleware) List(ctx context.Context, query *model.TodoQuery) (res []*model.TodoRes, err error) {
+	defer func() {
+		lm.logger.Log("method", "List", "query", fmt.Sprintf("%v", query), "err", err)
+	}()
+
+	return lm.next.List(ctx, query)
+}
+
+func (lm loggingMiddleware) Get(ctx context.Context, id string) (res *model.TodoRes, err error) {
+	defer func() {
+		lm.logger.Log("method", "Get", "id", id, "err", err)
+	}()
+
+	return lm.next.Get(ctx, id)
+}
+
+func (lm loggingMiddleware) Stats(ctx context.Context, days int) (res *model.TodoStats, err error) {
+	defer func() {
+		lm.logger.Log("method", "Stats", "days", days, "err", err)
+	}()
+
+	return lm.next.Stats(ctx, days)
+}
+
+func (lm loggingMiddleware) Unarchive(ctx context.Context, id string) (res *model.TodoRes, err error) {
+	defer func() {
+		lm.logger.Log("method", "Unarchive", "id", id, "err", err)
+	}()
+
+	return lm.next.Unarchive(ctx, id)
+}
+
+func (lm loggingMiddleware) Snooze(ctx context.Context, id string, until time.Time) (res *model.TodoRes, err error) {
+	defer func() {
+		lm.logger.Log("method", "Snooze", "id", id, "until", until, "err", err)
+	}()
+
+	return lm.next.Snooze(ctx, id, until)
+}
+
+func (lm loggingMiddleware) Watch(ctx context.Context, query *model.EventQuery) (res <-chan *model.TodoEvent, err error) {
+	defer func() {
+		lm.logger.Log("method", "Watch", "query", fmt.Sprintf("%v", query), "err", err)
+	}()
+
+	return lm.next.Watch(ctx, query)
+}
+
+func (lm loggingMiddleware) Sync(ctx context.Context, token string) (res *model.Changes, err error) {
+	defer func() {
+		lm.logger.Log("method", "Sync", "token", token, "err", err)
+	}()
+
+	return lm.next.Sync(ctx, token)
+}
+
+func (lm loggingMiddleware) Push(ctx context.Context, mutations []*model.Mutation) (res []*model.MutationResult, err error) {
+	defer func() {
+		lm.logger.Log("method", "Push", "mutations", len(mutations), "err", err)
+	}()
+
+	return lm.next.Push(ctx, mutations)
+}
+
+func (lm loggingMiddleware) AddShare(ctx context.Context, share *model.ShareReq) (res *model.Share, err error) {
+	defer func() {
+		lm.logger.Log("method", "AddShare", "share", fmt.Sprintf("%v", share), "err", err)
+	}()
+
+	return lm.next.AddShare(ctx, share)
+}
+
+func (lm loggingMiddleware) DeleteShare(ctx context.Context, id string) (err error) {
+	defer func() {
+		lm.logger.Log("method", "DeleteShare", "id", id, "err", err)
+	}()
+
+	return lm.next.DeleteShare(ctx, id)
+}
+
+func (lm loggingMiddleware) ListShares(ctx context.Context) (res []*model.Share, err error) {
+	defer func() {
+		lm.logger.Log("method", "ListShares", "err", err)
+	}()
+
+	return lm.next.ListShares(ctx)
+}
+
+func (lm loggingMiddleware) CreateAPIKey(ctx context.Context, key *model.APIKeyReq) (res *model.CreatedAPIKey, err error) {
+	defer func() {
+		lm.logger.Log("method", "CreateAPIKey", "key", fmt.Sprintf("%v", key), "err", err)
+	}()
+
+	return lm.next.CreateAPIKey(ctx, key)
+}
+
+func (lm loggingMiddleware) RevokeAPIKey(ctx context.Context, id string) (err error) {
+	defer func() {
+		lm.logger.Log("method", "RevokeAPIKey", "id", id, "err", err)
+	}()
+
+	return lm.next.RevokeAPIKey(ctx, id)
+}
+
+func (lm loggingMiddleware) ListAPIKeys(ctx context.Context) (res []*model.APIKey, err error) {
+	defer func() {
+		lm.logger.Log("method", "ListAPIKeys", "err", err)
+	}()
+
+	return lm.next.ListAPIKeys(ctx)
+}
+
+func (lm loggingMiddleware) Usage(ctx context.Context) (res *model.Usage, err error) {
 	defer func() {
-		lm.logger.Log("method", "List", "err", err)
+		lm.logger.Log("method", "Usage", "err", err)
 	}()
 
-	return lm.next.List(ctx)
+	return lm.next.Usage(ctx)
 }
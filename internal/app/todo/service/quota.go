@@ -0,0 +1,102 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"unicode/utf8"
+
+	"github.com/cage1016/gokit-todo/internal/app/todo/model"
+	"github.com/cage1016/gokit-todo/internal/pkg/errors"
+)
+
+// ErrQuotaExceeded indicates that a change would take the caller past its
+// quota.
+var ErrQuotaExceeded = errors.New("quota exceeded")
+
+// Quotas are the quotas of the tenants, the ones without a quota of their own
+// have the default one. A tenant quota replaces the default one as a whole.
+//
+// Quotas letting every caller keep 1000 todos of up to 500 characters, but
+// the callers of acme 5000 todos and up to 100000 in total, read:
+//
+//	{
+//	  "default": {"todos": 1000, "textLength": 500},
+//	  "tenants": {"acme": {"todos": 5000, "tenantTodos": 100000, "textLength": 500}}
+//	}
+type Quotas struct {
+	Default model.Quota            `json:"default"`
+	Tenants map[string]model.Quota `json:"tenants"`
+}
+
+// LoadQuotas reads the JSON quotas of file.
+func LoadQuotas(file string) (*Quotas, error) {
+	b, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	q := &Quotas{}
+	if err := json.Unmarshal(b, q); err != nil {
+		return nil, fmt.Errorf("%s: %v", file, err)
+	}
+	for tenant, quota := range q.Tenants {
+		if quota.Todos < 0 || quota.TenantTodos < 0 || quota.TextLength < 0 {
+			return nil, fmt.Errorf("%s: tenant %q: negative quota", file, tenant)
+		}
+	}
+	if d := q.Default; d.Todos < 0 || d.TenantTodos < 0 || d.TextLength < 0 {
+		return nil, fmt.Errorf("%s: default: negative quota", file)
+	}
+	return q, nil
+}
+
+// Of returns the quota of tenant, nil Quotas are unlimited.
+func (q *Quotas) Of(tenant string) model.Quota {
+	if q == nil {
+		return model.Quota{}
+	}
+	if quota, ok := q.Tenants[tenant]; ok {
+		return quota
+	}
+	return q.Default
+}
+
+// WithQuotas makes the service enforce quotas on the todos of the callers.
+func WithQuotas(quotas *Quotas) Option {
+	return func(s *stubTodoService) {
+		s.quotas = quotas
+	}
+}
+
+// checkText returns ErrQuotaExceeded if the text of todo is longer than the
+// quota of the caller of ctx.
+func (to *stubTodoService) checkText(ctx context.Context, todo *model.TodoReq) error {
+	quota := to.quotas.Of(ownerOf(ctx).TenantID)
+	if quota.TextLength > 0 && todo.Text != nil && utf8.RuneCountInString(*todo.Text) > quota.TextLength {
+		return errors.Wrap(ErrQuotaExceeded, fmt.Errorf("text longer than %d characters", quota.TextLength))
+	}
+	return nil
+}
+
+// CheckUsage returns ErrQuotaExceeded if adding a todo to usage would take
+// it past quota, as the repositories check it when adding a todo within it.
+func CheckUsage(quota model.Quota, usage *model.Usage) error {
+	switch {
+	case quota.Todos > 0 && usage.Todos >= quota.Todos:
+		return errors.Wrap(ErrQuotaExceeded, fmt.Errorf("more than %d todos", quota.Todos))
+	case quota.TenantTodos > 0 && usage.TenantTodos >= quota.TenantTodos:
+		return errors.Wrap(ErrQuotaExceeded, fmt.Errorf("more than %d todos in the tenant", quota.TenantTodos))
+	}
+	return nil
+}
+
+// add stores todo within the quota of its tenant, which the repository
+// enforces along with the insert.
+func (to *stubTodoService) add(ctx context.Context, todo *model.Todo) error {
+	quota := to.quotas.Of(todo.TenantID)
+	if quota.Todos == 0 && quota.TenantTodos == 0 {
+		return to.repo.Add(ctx, todo)
+	}
+	return to.repo.AddWithin(ctx, todo, quota)
+}
@@ -2,13 +2,19 @@ package service
 
 import (
 	"context"
+	"regexp"
+	"strconv"
+	"strings"
 	"time"
 
 	gonanoid "github.com/matoous/go-nanoid"
 
 	"github.com/cage1016/gokit-todo/internal/app/todo/model"
+	"github.com/cage1016/gokit-todo/internal/pkg/authn"
+	"github.com/cage1016/gokit-todo/internal/pkg/authz"
 	"github.com/cage1016/gokit-todo/internal/pkg/errors"
 	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/metrics"
 )
 
 var (
@@ -17,6 +23,12 @@ var (
 	ErrNotFound = errors.New("non-existent entity")
 
 	ErrInvalidQueryParams = errors.New("invalid query params")
+
+	ErrSequenceExpired = errors.New("event sequence no longer available")
+
+	ErrConflict = errors.New("entity changed concurrently")
+
+	ErrIDTaken = errors.New("id already in use")
 )
 
 const (
@@ -25,36 +37,142 @@ const (
 	COMPLETE = "complete"
 )
 
+const (
+	// DefaultStatsDays is the window, in days, of the completion trend when
+	// none is requested.
+	DefaultStatsDays = 7
+	// MaxStatsDays is the largest window, in days, of the completion trend.
+	MaxStatsDays = 365
+	// MaxSyncChanges is the largest number of changes returned by a sync.
+	MaxSyncChanges = 500
+	// MaxPushMutations is the largest number of mutations pushed at once.
+	MaxPushMutations = 500
+)
+
 // Middleware describes a service (as opposed to endpoint) middleware.
 type Middleware func(TodoService) TodoService
 
 // Service describes a service that adds things together
 // Implement yor service methods methods.
 // e.x: Foo(ctx context.Context, s string)(rs string, err error)
+//
 //go:generate mockgen -destination ../../../../internal/mocks/app/todo/service/todoservice.go -package=automocks . TodoService
 type TodoService interface {
 	// [method=post,expose=true,router=items]
 	Add(ctx context.Context, todo *model.TodoReq) (res *model.TodoRes, err error)
 	// [method=delete,expose=true,router=items/:id]
 	Delete(ctx context.Context, id string) (err error)
-	// [method=put,expose=true,router=items/:id]
+	// [method=patch,expose=true,router=items/:id]
 	Update(ctx context.Context, id string, todo *model.TodoReq) (res *model.TodoRes, err error)
 	// [method=get,expose=true,router=items]
-	List(ctx context.Context) (res []*model.TodoRes, err error)
+	List(ctx context.Context, query *model.TodoQuery) (res []*model.TodoRes, err error)
+	// [method=get,expose=true,router=items/:id]
+	Get(ctx context.Context, id string) (res *model.TodoRes, err error)
+	// [method=get,expose=true,router=stats]
+	Stats(ctx context.Context, days int) (res *model.TodoStats, err error)
+	// [method=post,expose=true,router=items/:id/unarchive]
+	Unarchive(ctx context.Context, id string) (res *model.TodoRes, err error)
+	// [method=post,expose=true,router=items/:id/snooze]
+	Snooze(ctx context.Context, id string, until time.Time) (res *model.TodoRes, err error)
+	// Watch streams the events of the todos of the caller. The todos the
+	// others share with the caller are left out, as their shares may change
+	// while watching, List reads them with TodoQuery.Shared.
+	// [method=get,expose=true,router=items/events]
+	Watch(ctx context.Context, query *model.EventQuery) (res <-chan *model.TodoEvent, err error)
+	// Sync returns the changes of the todos of the caller since token. As
+	// for Watch, the todos shared with the caller are left out: the tokens
+	// follow the changes of the todos, not of their shares.
+	// [method=get,expose=true,router=sync]
+	Sync(ctx context.Context, token string) (res *model.Changes, err error)
+	// Push applies the mutations one by one, in order, and reports each as
+	// applied, in conflict with the stored todo or rejected. An error fails
+	// the whole push while the mutations before the failing one stay
+	// applied, pushing them again is safe as they then conflict with their
+	// own result or, for deletions, are applied again.
+	// [method=post,expose=true,router=sync]
+	Push(ctx context.Context, mutations []*model.Mutation) (res []*model.MutationResult, err error)
+	// [method=post,expose=true,router=shares]
+	AddShare(ctx context.Context, share *model.ShareReq) (res *model.Share, err error)
+	// [method=delete,expose=true,router=shares/:id]
+	DeleteShare(ctx context.Context, id string) (err error)
+	// [method=get,expose=true,router=shares]
+	ListShares(ctx context.Context) (res []*model.Share, err error)
+	// [method=post,expose=true,router=admin/api-keys]
+	CreateAPIKey(ctx context.Context, key *model.APIKeyReq) (res *model.CreatedAPIKey, err error)
+	// [method=delete,expose=true,router=admin/api-keys/:id]
+	RevokeAPIKey(ctx context.Context, id string) (err error)
+	// [method=get,expose=true,router=admin/api-keys]
+	ListAPIKeys(ctx context.Context) (res []*model.APIKey, err error)
+	// [method=get,expose=true,router=usage]
+	Usage(ctx context.Context) (res *model.Usage, err error)
 }
 
 // the concrete implementation of service interface
 type stubTodoService struct {
 	repo   model.TodoRepository
 	logger log.Logger
+	now    func() time.Time
+	bus    *EventBus
+	// shared is set when the bus is fed with the events of every replica, in
+	// which case the service does not publish its own changes.
+	shared bool
+	// quotas are enforced on the todos added and updated, which are
+	// unlimited without them.
+	quotas *Quotas
+	// authorizer authorizes the API key methods, which are denied without
+	// it.
+	authorizer Authorizer
+	// requestCount and requestLatency instrument the methods of the service,
+	// which is not instrumented without them.
+	requestCount   metrics.Counter
+	requestLatency metrics.Histogram
+}
+
+// Option configures the service returned by New.
+type Option func(*stubTodoService)
+
+// WithClock makes the service read the current time from now instead of
+// time.Now, which is mostly useful to write deterministic tests.
+func WithClock(now func() time.Time) Option {
+	return func(s *stubTodoService) {
+		s.now = now
+	}
+}
+
+// WithEventBus makes the service publish its events to bus instead of a
+// bus of its own.
+func WithEventBus(bus *EventBus) Option {
+	return func(s *stubTodoService) {
+		s.bus = bus
+	}
+}
+
+// WithSharedEventBus makes the service watch the events of bus without
+// publishing its changes to it, bus being fed by an event listener with the
+// changes of every replica, including this one.
+func WithSharedEventBus(bus *EventBus) Option {
+	return func(s *stubTodoService) {
+		s.bus = bus
+		s.shared = true
+	}
 }
 
 // New return a new instance of the service.
 // If you want to add service middleware this is the place to put them.
-func New(repo model.TodoRepository, logger log.Logger) (s TodoService) {
+func New(repo model.TodoRepository, logger log.Logger, opts ...Option) (s TodoService) {
 	var svc TodoService
 	{
-		svc = &stubTodoService{repo: repo, logger: logger}
+		stub := &stubTodoService{repo: repo, logger: logger, now: time.Now}
+		for _, opt := range opts {
+			opt(stub)
+		}
+		if stub.bus == nil {
+			stub.bus = NewEventBus(DefaultEventBacklog)
+		}
+		svc = stub
+		if stub.requestCount != nil && stub.requestLatency != nil {
+			svc = InstrumentingMiddleware(stub.requestCount, stub.requestLatency)(svc)
+		}
 		svc = LoggingMiddleware(logger)(svc)
 	}
 	return svc
@@ -62,57 +180,145 @@ func New(repo model.TodoRepository, logger log.Logger) (s TodoService) {
 
 // Implement the business logic of Add
 func (to *stubTodoService) Add(ctx context.Context, todo *model.TodoReq) (res *model.TodoRes, err error) {
+	if err := to.checkText(ctx, todo); err != nil {
+		return nil, err
+	}
 	id, _ := gonanoid.ID(21)
 
-	t := new(model.Todo)
-	t.ID = id
-	t.CreatedAt = time.Now()
-	t.UpdatedAt = time.Now()
-	if todo.Completed != nil {
-		t.Completed = *todo.Completed
-	}
-	if todo.Text != nil {
-		t.Text = *todo.Text
-	}
-	if err := to.repo.Add(ctx, t); err != nil {
+	now := to.now()
+	t := newTodo(ctx, id, now)
+	applyReq(t, todo, now)
+	if err := to.add(ctx, t); err != nil {
 		return res, err
 	}
+	to.publish(model.EventCreated, t)
 	x := model.TodoRes(*t)
 	return &x, nil
 }
 
 // Implement the business logic of Delete
 func (to *stubTodoService) Delete(ctx context.Context, id string) (err error) {
-	return to.repo.Delete(ctx, id)
+	owner := ownerOf(ctx)
+	err = to.repo.Delete(ctx, owner, id, 0)
+	if err != nil && errors.Contains(errors.Cast(err), ErrNotFound) {
+		// the todo may be shared with the caller
+		var dt *model.Todo
+		if dt, err = to.sharedTodo(ctx, id, true); err == nil {
+			owner = dt.Owner()
+			err = to.repo.Delete(ctx, owner, id, 0)
+		}
+	}
+	if err != nil {
+		return err
+	}
+	to.publish(model.EventDeleted, deleted(owner, id))
+	return nil
 }
 
 // Implement the business logic of Update
 func (to *stubTodoService) Update(ctx context.Context, id string, todo *model.TodoReq) (res *model.TodoRes, err error) {
-	dt, err := to.repo.Get(ctx, id)
+	if err := to.checkText(ctx, todo); err != nil {
+		return nil, err
+	}
+	dt, err := to.get(ctx, id, true)
+	if err != nil {
+		return nil, err
+	}
+
+	applyReq(dt, todo, to.now())
+	if err := to.repo.Update(ctx, dt); err != nil {
+		return nil, err
+	}
+	to.publish(model.EventUpdated, dt)
+	x := model.TodoRes(*dt)
+	return &x, nil
+}
+
+// ownerOf returns the owner identified by the claims of ctx, the zero Owner
+// if the caller is not authenticated.
+func ownerOf(ctx context.Context) model.Owner {
+	claims, ok := authn.FromContext(ctx)
+	if !ok {
+		return model.Owner{}
+	}
+	return model.Owner{ID: claims.Subject, TenantID: claims.Tenant}
+}
+
+// get returns the todo id of the caller of ctx, or the one shared with them,
+// with the editor role if edit is set.
+func (to *stubTodoService) get(ctx context.Context, id string, edit bool) (*model.Todo, error) {
+	dt, err := to.repo.Get(ctx, ownerOf(ctx), id)
+	if err != nil && errors.Contains(errors.Cast(err), ErrNotFound) {
+		return to.sharedTodo(ctx, id, edit)
+	}
+	return dt, err
+}
+
+// sharedTodo returns the todo id the others share with the caller of ctx,
+// with the editor role if edit is set.
+func (to *stubTodoService) sharedTodo(ctx context.Context, id string, edit bool) (*model.Todo, error) {
+	shares, err := to.repo.Grants(ctx, ownerOf(ctx), id)
 	if err != nil {
 		return nil, err
 	}
+	if len(shares) == 0 {
+		return nil, ErrNotFound
+	}
+	if edit && !editable(shares) {
+		return nil, authz.ErrForbidden
+	}
+	return to.repo.Get(ctx, shares[0].Owner(), id)
+}
+
+// editable reports whether one of shares grants the editor role.
+func editable(shares []*model.Share) bool {
+	for _, s := range shares {
+		if s.Role == model.RoleEditor {
+			return true
+		}
+	}
+	return false
+}
+
+// newTodo returns a todo of the caller of ctx created at now.
+func newTodo(ctx context.Context, id string, now time.Time) *model.Todo {
+	owner := ownerOf(ctx)
+	return &model.Todo{ID: id, OwnerID: owner.ID, TenantID: owner.TenantID, CreatedAt: now}
+}
 
-	dt.UpdatedAt = time.Now()
+// deleted returns what the events of deletion carry of the todo of owner.
+func deleted(owner model.Owner, id string) *model.Todo {
+	return &model.Todo{ID: id, OwnerID: owner.ID, TenantID: owner.TenantID}
+}
+
+// applyReq updates dt with the fields set in todo, as changed at now.
+func applyReq(dt *model.Todo, todo *model.TodoReq, now time.Time) {
+	dt.UpdatedAt = now
 	if todo.Completed != nil {
+		switch {
+		case *todo.Completed && !dt.Completed:
+			dt.CompletedAt = &now
+		case !*todo.Completed:
+			dt.CompletedAt = nil
+		}
 		dt.Completed = *todo.Completed
 	}
 	if todo.Text != nil {
 		dt.Text = *todo.Text
 	}
-
-	if err := to.repo.Update(ctx, dt); err != nil {
-		return nil, err
-	}
-	x := model.TodoRes(*dt)
-	return &x, nil
 }
 
 // Implement the business logic of List
-func (to *stubTodoService) List(ctx context.Context) (res []*model.TodoRes, err error) {
+func (to *stubTodoService) List(ctx context.Context, query *model.TodoQuery) (res []*model.TodoRes, err error) {
 	res = make([]*model.TodoRes, 0)
 
-	rr, err := to.repo.List(ctx)
+	q := model.TodoQuery{}
+	if query != nil {
+		q = *query
+	}
+	q.Now = to.now()
+	q.Owner = ownerOf(ctx)
+	rr, err := to.repo.List(ctx, &q)
 	if err != nil {
 		return
 	}
@@ -123,3 +329,480 @@ func (to *stubTodoService) List(ctx context.Context) (res []*model.TodoRes, err
 	}
 	return
 }
+
+// Implement the business logic of Get
+func (to *stubTodoService) Get(ctx context.Context, id string) (res *model.TodoRes, err error) {
+	dt, err := to.get(ctx, id, false)
+	if err != nil {
+		return nil, err
+	}
+	x := model.TodoRes(*dt)
+	return &x, nil
+}
+
+// Implement the business logic of Stats
+func (to *stubTodoService) Stats(ctx context.Context, days int) (res *model.TodoStats, err error) {
+	if days == 0 {
+		days = DefaultStatsDays
+	}
+	if days < 0 || days > MaxStatsDays {
+		return nil, ErrInvalidQueryParams
+	}
+
+	now := to.now().UTC()
+	since := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC).AddDate(0, 0, 1-days)
+
+	res, err = to.repo.Stats(ctx, ownerOf(ctx), since)
+	if err != nil {
+		return nil, err
+	}
+
+	// report every day of the window, including the ones without completions
+	counts := make(map[string]int64, len(res.CompletedPerDay))
+	for _, c := range res.CompletedPerDay {
+		counts[c.Day] = c.Count
+	}
+	perDay := make([]*model.DayCount, 0, days)
+	for d := since; !d.After(now); d = d.AddDate(0, 0, 1) {
+		day := d.Format("2006-01-02")
+		perDay = append(perDay, &model.DayCount{Day: day, Count: counts[day]})
+	}
+	res.CompletedPerDay = perDay
+	return res, nil
+}
+
+// Implement the business logic of Unarchive
+func (to *stubTodoService) Unarchive(ctx context.Context, id string) (res *model.TodoRes, err error) {
+	dt, err := to.get(ctx, id, true)
+	if err != nil {
+		return nil, err
+	}
+
+	if dt.ArchivedAt != nil {
+		// the todo being updated, the archiver leaves it for another
+		// retention window
+		dt.ArchivedAt = nil
+		dt.UpdatedAt = to.now()
+		if err := to.repo.Update(ctx, dt); err != nil {
+			return nil, err
+		}
+		to.publish(model.EventUpdated, dt)
+	}
+	x := model.TodoRes(*dt)
+	return &x, nil
+}
+
+// Implement the business logic of Snooze, a zero until wakes the todo up.
+func (to *stubTodoService) Snooze(ctx context.Context, id string, until time.Time) (res *model.TodoRes, err error) {
+	now := to.now()
+	if !until.IsZero() && !until.After(now) {
+		return nil, ErrMalformedEntity
+	}
+
+	dt, err := to.get(ctx, id, true)
+	if err != nil {
+		return nil, err
+	}
+
+	dt.SnoozedUntil = nil
+	if !until.IsZero() {
+		dt.SnoozedUntil = &until
+	}
+	dt.UpdatedAt = now
+	if err := to.repo.Update(ctx, dt); err != nil {
+		return nil, err
+	}
+	to.publish(model.EventUpdated, dt)
+	x := model.TodoRes(*dt)
+	return &x, nil
+}
+
+// Implement the business logic of Watch
+func (to *stubTodoService) Watch(ctx context.Context, query *model.EventQuery) (res <-chan *model.TodoEvent, err error) {
+	if query == nil {
+		query = &model.EventQuery{}
+	}
+	types := make(map[string]bool, len(query.Types))
+	for _, t := range query.Types {
+		switch t {
+		case model.EventCreated, model.EventUpdated, model.EventDeleted:
+			types[t] = true
+		default:
+			return nil, ErrInvalidQueryParams
+		}
+	}
+
+	events, err := to.bus.Subscribe(ctx, query.Since)
+	if err != nil {
+		return nil, err
+	}
+
+	// the events of the todos of the others are left out, shared or not
+	owner := ownerOf(ctx)
+	filtered := make(chan *model.TodoEvent)
+	go func() {
+		defer close(filtered)
+		for ev := range events {
+			if len(types) > 0 && !types[ev.Type] {
+				continue
+			}
+			if ev.Todo == nil || ev.Todo.OwnerID != owner.ID || ev.Todo.TenantID != owner.TenantID {
+				continue
+			}
+			select {
+			case filtered <- ev:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return filtered, nil
+}
+
+// Implement the business logic of Sync
+func (to *stubTodoService) Sync(ctx context.Context, token string) (res *model.Changes, err error) {
+	since, err := parseSyncToken(token)
+	if err != nil {
+		return nil, err
+	}
+
+	todos, tombstones, err := to.repo.Changes(ctx, ownerOf(ctx), since, MaxSyncChanges)
+	if err != nil {
+		return nil, err
+	}
+
+	// merge both in cursor order, up to MaxSyncChanges
+	res = &model.Changes{Todos: []*model.TodoRes{}, Deleted: []*model.Tombstone{}}
+	last := since
+	for len(res.Todos)+len(res.Deleted) < MaxSyncChanges && len(todos)+len(tombstones) > 0 {
+		if len(tombstones) == 0 || (len(todos) > 0 && todos[0].Cursor().Before(tombstones[0].Cursor())) {
+			x := model.TodoRes(*todos[0])
+			res.Todos = append(res.Todos, &x)
+			last, todos = todos[0].Cursor(), todos[1:]
+		} else {
+			res.Deleted = append(res.Deleted, tombstones[0])
+			last, tombstones = tombstones[0].Cursor(), tombstones[1:]
+		}
+	}
+	res.Token = syncToken(last)
+	res.More = len(res.Todos)+len(res.Deleted) == MaxSyncChanges
+	return res, nil
+}
+
+// syncToken formats the cursor of the last change synced as a sync token.
+func syncToken(c model.ChangeCursor) string {
+	return strconv.FormatUint(c.TxID, 10) + "." + strconv.FormatUint(c.Version, 10)
+}
+
+// parseSyncToken parses a token of syncToken, the empty token syncing from
+// the first change.
+func parseSyncToken(token string) (c model.ChangeCursor, err error) {
+	if token == "" {
+		return c, nil
+	}
+	parts := strings.Split(token, ".")
+	if len(parts) != 2 {
+		return c, ErrInvalidQueryParams
+	}
+	if c.TxID, err = strconv.ParseUint(parts[0], 10, 64); err != nil {
+		return c, errors.Wrap(ErrInvalidQueryParams, err)
+	}
+	if c.Version, err = strconv.ParseUint(parts[1], 10, 64); err != nil {
+		return c, errors.Wrap(ErrInvalidQueryParams, err)
+	}
+	return c, nil
+}
+
+// Implement the business logic of Push
+func (to *stubTodoService) Push(ctx context.Context, mutations []*model.Mutation) (res []*model.MutationResult, err error) {
+	if len(mutations) > MaxPushMutations {
+		return nil, ErrMalformedEntity
+	}
+
+	res = make([]*model.MutationResult, 0, len(mutations))
+	for _, m := range mutations {
+		r, err := to.apply(ctx, m)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, r)
+	}
+	return res, nil
+}
+
+// clientID matches the ids chosen by the clients for the todos they create,
+// formatted as the ones of the service.
+var clientID = regexp.MustCompile(`^[A-Za-z0-9_-]{21}$`)
+
+// apply applies the mutation m unless it conflicts with the stored todo. An
+// error is only returned when the mutation could not be checked.
+func (to *stubTodoService) apply(ctx context.Context, m *model.Mutation) (*model.MutationResult, error) {
+	if m == nil || m.ID == "" {
+		return &model.MutationResult{Status: model.MutationRejected, Error: ErrMalformedEntity.Error()}, nil
+	}
+	res := &model.MutationResult{ID: m.ID}
+	if m.Op == model.MutationCreate && !clientID.MatchString(m.ID) {
+		res.Status, res.Error = model.MutationRejected, ErrMalformedEntity.Error()
+		return res, nil
+	}
+
+	current, err := to.repo.Get(ctx, ownerOf(ctx), m.ID)
+	if err != nil && errors.Contains(errors.Cast(err), ErrNotFound) {
+		current, err = nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	now := to.now()
+	switch {
+	case m.Op == model.MutationCreate && m.Todo != nil:
+		if current != nil {
+			return withTodo(res, model.MutationConflict, current), nil
+		}
+		if err := to.checkText(ctx, m.Todo); err != nil {
+			return rejected(res, err)
+		}
+		t := newTodo(ctx, m.ID, now)
+		applyReq(t, m.Todo, now)
+		if err := to.add(ctx, t); err != nil && errors.Contains(errors.Cast(err), ErrConflict) {
+			return to.taken(ctx, res)
+		} else if err != nil {
+			return rejected(res, err)
+		}
+		to.publish(model.EventCreated, t)
+		return withTodo(res, model.MutationApplied, t), nil
+
+	case m.Op == model.MutationUpdate && m.Todo != nil:
+		if current == nil || conflicts(current, m) {
+			return withTodo(res, model.MutationConflict, current), nil
+		}
+		if err := to.checkText(ctx, m.Todo); err != nil {
+			return rejected(res, err)
+		}
+		applyReq(current, m.Todo, now)
+		if err := to.repo.Update(ctx, current); err != nil && errors.Contains(errors.Cast(err), ErrConflict) {
+			return to.reload(ctx, res)
+		} else if err != nil {
+			return nil, err
+		}
+		to.publish(model.EventUpdated, current)
+		return withTodo(res, model.MutationApplied, current), nil
+
+	case m.Op == model.MutationDelete:
+		if current == nil {
+			// already deleted
+			return withTodo(res, model.MutationApplied, nil), nil
+		}
+		if conflicts(current, m) {
+			return withTodo(res, model.MutationConflict, current), nil
+		}
+		if err := to.repo.Delete(ctx, current.Owner(), m.ID, current.Version); err != nil && errors.Contains(errors.Cast(err), ErrConflict) {
+			return to.reload(ctx, res)
+		} else if err != nil {
+			return nil, err
+		}
+		to.publish(model.EventDeleted, deleted(current.Owner(), m.ID))
+		return withTodo(res, model.MutationApplied, nil), nil
+
+	default:
+		res.Status, res.Error = model.MutationRejected, ErrMalformedEntity.Error()
+		return res, nil
+	}
+}
+
+// conflicts reports whether todo changed since the client made m. Updates are
+// compared to the second, as todos are serialized with that precision.
+func conflicts(todo *model.Todo, m *model.Mutation) bool {
+	switch {
+	case m.Version != 0:
+		return todo.Version != m.Version
+	case m.UpdatedAt != nil:
+		return todo.UpdatedAt.Truncate(time.Second).After(*m.UpdatedAt)
+	default:
+		return false
+	}
+}
+
+// rejected rejects the mutation of res past the quota of the caller, the
+// other errors failing the whole push.
+func rejected(res *model.MutationResult, err error) (*model.MutationResult, error) {
+	if !errors.Contains(errors.Cast(err), ErrQuotaExceeded) {
+		return nil, err
+	}
+	res.Status, res.Error = model.MutationRejected, err.Error()
+	return res, nil
+}
+
+// withTodo sets the status of res along with the todo it ends up with.
+func withTodo(res *model.MutationResult, status string, todo *model.Todo) *model.MutationResult {
+	res.Status = status
+	if todo != nil {
+		x := model.TodoRes(*todo)
+		res.Todo = &x
+	}
+	return res
+}
+
+// reload reports a conflict with the todo written concurrently.
+func (to *stubTodoService) reload(ctx context.Context, res *model.MutationResult) (*model.MutationResult, error) {
+	current, err := to.repo.Get(ctx, ownerOf(ctx), res.ID)
+	if err != nil && errors.Contains(errors.Cast(err), ErrNotFound) {
+		return withTodo(res, model.MutationConflict, nil), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return withTodo(res, model.MutationConflict, current), nil
+}
+
+// taken reports a conflict with the todo of the caller created concurrently
+// with the id of res, or rejects the creation if the id is taken by a todo of
+// another caller, which is not disclosed.
+func (to *stubTodoService) taken(ctx context.Context, res *model.MutationResult) (*model.MutationResult, error) {
+	current, err := to.repo.Get(ctx, ownerOf(ctx), res.ID)
+	if err != nil && errors.Contains(errors.Cast(err), ErrNotFound) {
+		res.Status, res.Error = model.MutationRejected, ErrIDTaken.Error()
+		return res, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return withTodo(res, model.MutationConflict, current), nil
+}
+
+// publish sends an event of the given type about todo to the watchers.
+func (to *stubTodoService) publish(eventType string, todo *model.Todo) {
+	if to.shared {
+		return
+	}
+	x := model.TodoRes(*todo)
+	to.bus.Publish(eventType, &x, to.now())
+}
+
+// Implement the business logic of AddShare
+func (to *stubTodoService) AddShare(ctx context.Context, share *model.ShareReq) (res *model.Share, err error) {
+	owner := ownerOf(ctx)
+	if share == nil || share.GranteeID == "" || share.GranteeID == owner.ID {
+		return nil, ErrMalformedEntity
+	}
+	switch share.Role {
+	case model.RoleViewer, model.RoleEditor:
+	default:
+		return nil, ErrMalformedEntity
+	}
+	// only the todos of the caller are shared
+	if share.TodoID != "" {
+		if _, err := to.repo.Get(ctx, owner, share.TodoID); err != nil {
+			return nil, err
+		}
+	}
+
+	id, _ := gonanoid.ID(21)
+	res = &model.Share{
+		ID:        id,
+		OwnerID:   owner.ID,
+		TenantID:  owner.TenantID,
+		TodoID:    share.TodoID,
+		GranteeID: share.GranteeID,
+		Role:      share.Role,
+		CreatedAt: to.now(),
+	}
+	if err := to.repo.AddShare(ctx, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// Implement the business logic of DeleteShare
+func (to *stubTodoService) DeleteShare(ctx context.Context, id string) (err error) {
+	return to.repo.DeleteShare(ctx, ownerOf(ctx), id)
+}
+
+// Implement the business logic of ListShares
+func (to *stubTodoService) ListShares(ctx context.Context) (res []*model.Share, err error) {
+	res, err = to.repo.ListShares(ctx, ownerOf(ctx))
+	if err != nil {
+		return nil, err
+	}
+	if res == nil {
+		res = []*model.Share{}
+	}
+	return res, nil
+}
+
+// Implement the business logic of CreateAPIKey
+func (to *stubTodoService) CreateAPIKey(ctx context.Context, key *model.APIKeyReq) (res *model.CreatedAPIKey, err error) {
+	now := to.now()
+	if key == nil || key.Name == "" || (key.ExpiresAt != nil && !key.ExpiresAt.After(now)) {
+		return nil, ErrMalformedEntity
+	}
+	if to.authorizer == nil {
+		return nil, errors.Wrap(authz.ErrForbidden, ErrAPIKeysDisabled)
+	}
+	owner := ownerOf(ctx)
+	subject := key.Subject
+	if subject == "" {
+		subject = owner.ID
+	}
+	if err := to.checkAPIKey(ctx, subject, key.Scopes); err != nil {
+		return nil, err
+	}
+
+	secret, err := authn.NewAPIKey()
+	if err != nil {
+		return nil, err
+	}
+	id, _ := gonanoid.ID(21)
+	res = &model.CreatedAPIKey{
+		APIKey: model.APIKey{
+			ID:        id,
+			OwnerID:   owner.ID,
+			TenantID:  owner.TenantID,
+			Name:      key.Name,
+			Subject:   subject,
+			Hash:      authn.HashAPIKey(secret),
+			Scopes:    key.Scopes,
+			ExpiresAt: key.ExpiresAt,
+			CreatedAt: now,
+		},
+		Key: secret,
+	}
+	if err := to.repo.AddAPIKey(ctx, &res.APIKey); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// Implement the business logic of RevokeAPIKey
+func (to *stubTodoService) RevokeAPIKey(ctx context.Context, id string) (err error) {
+	if to.authorizer == nil {
+		return errors.Wrap(authz.ErrForbidden, ErrAPIKeysDisabled)
+	}
+	return to.repo.DeleteAPIKey(ctx, ownerOf(ctx).TenantID, id)
+}
+
+// Implement the business logic of ListAPIKeys
+func (to *stubTodoService) ListAPIKeys(ctx context.Context) (res []*model.APIKey, err error) {
+	if to.authorizer == nil {
+		return nil, errors.Wrap(authz.ErrForbidden, ErrAPIKeysDisabled)
+	}
+	res, err = to.repo.ListAPIKeys(ctx, ownerOf(ctx).TenantID)
+	if err != nil {
+		return nil, err
+	}
+	if res == nil {
+		res = []*model.APIKey{}
+	}
+	return res, nil
+}
+
+// Implement the business logic of Usage
+func (to *stubTodoService) Usage(ctx context.Context) (res *model.Usage, err error) {
+	owner := ownerOf(ctx)
+	if res, err = to.repo.Usage(ctx, owner); err != nil {
+		return nil, err
+	}
+	res.Quota = to.quotas.Of(owner.TenantID)
+	return res, nil
+}
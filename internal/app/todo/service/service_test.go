@@ -7,15 +7,25 @@ import (
 	"database/sql"
 	"fmt"
 	"os"
+	"strings"
 	"testing"
+	"time"
 
+	jwt "github.com/dgrijalva/jwt-go"
 	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	kitprometheus "github.com/go-kit/kit/metrics/prometheus"
 	"github.com/golang/mock/gomock"
+	stdprometheus "github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 
 	"github.com/cage1016/gokit-todo/internal/app/todo/model"
 	"github.com/cage1016/gokit-todo/internal/app/todo/service"
 	automocks "github.com/cage1016/gokit-todo/internal/mocks/app/todo/model"
+	"github.com/cage1016/gokit-todo/internal/pkg/authn"
+	"github.com/cage1016/gokit-todo/internal/pkg/authz"
+	"github.com/cage1016/gokit-todo/internal/pkg/errors"
 )
 
 func TestStubTodoService_Add(t *testing.T) {
@@ -102,8 +112,11 @@ func TestLoggingMiddleware_List(t *testing.T) {
 	}
 
 	type args struct {
+		query *model.TodoQuery
 	}
 
+	now := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+
 	tests := []struct {
 		name      string
 		prepare   func(f *fields)
@@ -115,7 +128,7 @@ func TestLoggingMiddleware_List(t *testing.T) {
 			name: "list todo",
 			prepare: func(f *fields) {
 				gomock.InOrder(
-					f.repo.EXPECT().List(context.Background()).Return([]*model.Todo{
+					f.repo.EXPECT().List(context.Background(), gomock.Any()).Return([]*model.Todo{
 						{
 							ID:        "b5z2zC5c9O6~Ns_qLVmn~",
 							Completed: false,
@@ -130,11 +143,21 @@ func TestLoggingMiddleware_List(t *testing.T) {
 				assert.Equal(t, len(res), 1, fmt.Sprintf("count res: expected 1 got %v", len(res)))
 			},
 		},
+		{
+			name: "list active todo at the service time",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.repo.EXPECT().List(context.Background(), &model.TodoQuery{Status: service.ACTIVE, Now: now}).Return([]*model.Todo{}, nil),
+				)
+			},
+			args:    args{query: &model.TodoQuery{Status: service.ACTIVE}},
+			wantErr: false,
+		},
 		{
 			name: "list todo fial",
 			prepare: func(f *fields) {
 				gomock.InOrder(
-					f.repo.EXPECT().List(context.Background()).Return([]*model.Todo{}, sql.ErrNoRows),
+					f.repo.EXPECT().List(context.Background(), gomock.Any()).Return([]*model.Todo{}, sql.ErrNoRows),
 				)
 			},
 			wantErr: true,
@@ -156,8 +179,8 @@ func TestLoggingMiddleware_List(t *testing.T) {
 				tt.prepare(&f)
 			}
 
-			svc := service.New(f.repo, log.NewLogfmtLogger(os.Stderr))
-			if res, err := svc.List(context.Background()); (err != nil) != tt.wantErr {
+			svc := service.New(f.repo, log.NewLogfmtLogger(os.Stderr), service.WithClock(func() time.Time { return now }))
+			if res, err := svc.List(context.Background(), tt.args.query); (err != nil) != tt.wantErr {
 				t.Errorf("svc.List error = %v, wantErr %v", err, tt.wantErr)
 			} else {
 				if tt.checkFunc != nil {
@@ -192,7 +215,7 @@ func TestLoggingMiddleware_Delete(t *testing.T) {
 			name: "delete todo",
 			prepare: func(f *fields) {
 				gomock.InOrder(
-					f.repo.EXPECT().Delete(context.Background(), gomock.Any()).Return(nil),
+					f.repo.EXPECT().Delete(context.Background(), model.Owner{}, gomock.Any(), uint64(0)).Return(nil),
 				)
 			},
 			args: args{
@@ -207,7 +230,7 @@ func TestLoggingMiddleware_Delete(t *testing.T) {
 			name: "Add todo fail",
 			prepare: func(f *fields) {
 				gomock.InOrder(
-					f.repo.EXPECT().Delete(context.Background(), gomock.Any()).Return(sql.ErrNoRows),
+					f.repo.EXPECT().Delete(context.Background(), model.Owner{}, gomock.Any(), uint64(0)).Return(sql.ErrNoRows),
 				)
 			},
 			args: args{todo: &model.TodoReq{
@@ -257,6 +280,7 @@ func TestLoggingMiddleware_Update(t *testing.T) {
 
 	text := "aa"
 	completed := true
+	uncompleted := false
 
 	tests := []struct {
 		name      string
@@ -269,7 +293,7 @@ func TestLoggingMiddleware_Update(t *testing.T) {
 			name: "Update todo",
 			prepare: func(f *fields) {
 				gomock.InOrder(
-					f.repo.EXPECT().Get(context.Background(), gomock.Any()).Return(&model.Todo{
+					f.repo.EXPECT().Get(context.Background(), model.Owner{}, gomock.Any()).Return(&model.Todo{
 						ID:        "b5z2zC5c9O6~Ns_qLVmn~",
 						Text:      "aa",
 						Completed: true,
@@ -289,11 +313,59 @@ func TestLoggingMiddleware_Update(t *testing.T) {
 				assert.Equal(t, err, nil, fmt.Sprintf("err: expected sql.ErrNoRows got %v", err))
 			},
 		},
+		{
+			name: "Update todo sets completedAt when completed",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.repo.EXPECT().Get(context.Background(), model.Owner{}, gomock.Any()).Return(&model.Todo{
+						ID:        "b5z2zC5c9O6~Ns_qLVmn~",
+						Text:      "aa",
+						Completed: false,
+					}, nil),
+					f.repo.EXPECT().Update(context.Background(), gomock.Any()).Return(nil),
+				)
+			},
+			wantErr: false,
+			args: args{
+				"b5z2zC5c9O6~Ns_qLVmn~",
+				&model.TodoReq{
+					Completed: &completed,
+				},
+			},
+			checkFunc: func(res *model.TodoRes, err error) {
+				assert.NotNil(t, res.CompletedAt, "completedAt: expected a completion time got nil")
+			},
+		},
+		{
+			name: "Update todo clears completedAt when uncompleted",
+			prepare: func(f *fields) {
+				completedAt := time.Now()
+				gomock.InOrder(
+					f.repo.EXPECT().Get(context.Background(), model.Owner{}, gomock.Any()).Return(&model.Todo{
+						ID:          "b5z2zC5c9O6~Ns_qLVmn~",
+						Text:        "aa",
+						Completed:   true,
+						CompletedAt: &completedAt,
+					}, nil),
+					f.repo.EXPECT().Update(context.Background(), gomock.Any()).Return(nil),
+				)
+			},
+			wantErr: false,
+			args: args{
+				"b5z2zC5c9O6~Ns_qLVmn~",
+				&model.TodoReq{
+					Completed: &uncompleted,
+				},
+			},
+			checkFunc: func(res *model.TodoRes, err error) {
+				assert.Nil(t, res.CompletedAt, fmt.Sprintf("completedAt: expected nil got %v", res.CompletedAt))
+			},
+		},
 		{
 			name: "Update todo fail",
 			prepare: func(f *fields) {
 				gomock.InOrder(
-					f.repo.EXPECT().Get(context.Background(), gomock.Any()).Return(nil, sql.ErrNoRows),
+					f.repo.EXPECT().Get(context.Background(), model.Owner{}, gomock.Any()).Return(nil, sql.ErrNoRows),
 					//f.repo.EXPECT().Update(context.Background(), gomock.Any()).Return(nil),
 				)
 			},
@@ -313,7 +385,7 @@ func TestLoggingMiddleware_Update(t *testing.T) {
 			name: "Update todo fail 2",
 			prepare: func(f *fields) {
 				gomock.InOrder(
-					f.repo.EXPECT().Get(context.Background(), gomock.Any()).Return(&model.Todo{
+					f.repo.EXPECT().Get(context.Background(), model.Owner{}, gomock.Any()).Return(&model.Todo{
 						ID:        "b5z2zC5c9O6~Ns_qLVmn~",
 						Text:      "aa",
 						Completed: true,
@@ -358,3 +430,1894 @@ func TestLoggingMiddleware_Update(t *testing.T) {
 		})
 	}
 }
+
+func TestLoggingMiddleware_Text(t *testing.T) {
+	type fields struct {
+		repo *automocks.MockTodoRepository
+	}
+
+	type args struct {
+		allow level.Option
+	}
+
+	text := "buy milk"
+
+	tests := []struct {
+		name      string
+		prepare   func(f *fields)
+		args      args
+		checkFunc func(logs string)
+	}{
+		{
+			name: "add todo at the info level",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.repo.EXPECT().Add(context.Background(), gomock.Any()).Return(nil),
+				)
+			},
+			args: args{level.AllowInfo()},
+			checkFunc: func(logs string) {
+				assert.NotContains(t, logs, text, "logs: expected the text to be redacted")
+				assert.Contains(t, logs, "[8 characters]", "logs: expected the length of the text")
+			},
+		},
+		{
+			name: "add todo at the debug level",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.repo.EXPECT().Add(context.Background(), gomock.Any()).Return(nil),
+				)
+			},
+			args: args{level.AllowDebug()},
+			checkFunc: func(logs string) {
+				assert.Contains(t, logs, text, "logs: expected the text")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			f := fields{
+				repo: automocks.NewMockTodoRepository(ctrl),
+			}
+			if tt.prepare != nil {
+				tt.prepare(&f)
+			}
+
+			var buf strings.Builder
+			svc := service.New(f.repo, level.NewFilter(log.NewLogfmtLogger(&buf), tt.args.allow))
+			if _, err := svc.Add(context.Background(), &model.TodoReq{Text: &text}); err != nil {
+				t.Errorf("svc.Add error = %v", err)
+			}
+			tt.checkFunc(buf.String())
+		})
+	}
+}
+
+func TestLoggingMiddleware_Get(t *testing.T) {
+	type fields struct {
+		repo *automocks.MockTodoRepository
+	}
+
+	type args struct {
+		id string
+	}
+
+	tests := []struct {
+		name      string
+		prepare   func(f *fields)
+		args      args
+		wantErr   bool
+		checkFunc func(res *model.TodoRes, err error)
+	}{
+		{
+			name: "get todo",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.repo.EXPECT().Get(context.Background(), model.Owner{}, "b5z2zC5c9O6~Ns_qLVmn~").Return(&model.Todo{
+						ID:        "b5z2zC5c9O6~Ns_qLVmn~",
+						Text:      "aa",
+						Completed: true,
+					}, nil),
+				)
+			},
+			args:    args{id: "b5z2zC5c9O6~Ns_qLVmn~"},
+			wantErr: false,
+			checkFunc: func(res *model.TodoRes, err error) {
+				assert.Equal(t, "aa", res.Text, fmt.Sprintf("text: expected aa got %v", res.Text))
+			},
+		},
+		{
+			name: "get todo not found",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.repo.EXPECT().Get(context.Background(), model.Owner{}, "b5z2zC5c9O6~Ns_qLVmn~").Return(nil, service.ErrNotFound),
+					f.repo.EXPECT().Grants(context.Background(), model.Owner{}, "b5z2zC5c9O6~Ns_qLVmn~").Return(nil, nil),
+				)
+			},
+			args:    args{id: "b5z2zC5c9O6~Ns_qLVmn~"},
+			wantErr: true,
+			checkFunc: func(res *model.TodoRes, err error) {
+				assert.Equal(t, err, service.ErrNotFound, fmt.Sprintf("err: expected service.ErrNotFound got %v", err))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			f := fields{
+				repo: automocks.NewMockTodoRepository(ctrl),
+			}
+			if tt.prepare != nil {
+				tt.prepare(&f)
+			}
+
+			svc := service.New(f.repo, log.NewLogfmtLogger(os.Stderr))
+			if res, err := svc.Get(context.Background(), tt.args.id); (err != nil) != tt.wantErr {
+				t.Errorf("svc.Get error = %v, wantErr %v", err, tt.wantErr)
+			} else {
+				if tt.checkFunc != nil {
+					tt.checkFunc(res, err)
+				}
+			}
+		})
+	}
+}
+
+func TestLoggingMiddleware_Stats(t *testing.T) {
+	type fields struct {
+		repo *automocks.MockTodoRepository
+	}
+
+	type args struct {
+		days int
+	}
+
+	today := time.Now().UTC().Format("2006-01-02")
+
+	tests := []struct {
+		name      string
+		prepare   func(f *fields)
+		args      args
+		wantErr   bool
+		checkFunc func(res *model.TodoStats, err error)
+	}{
+		{
+			name: "stats todo",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.repo.EXPECT().Stats(context.Background(), model.Owner{}, gomock.Any()).Return(&model.TodoStats{
+						Total:           2,
+						Active:          1,
+						Completed:       1,
+						CompletedPerDay: []*model.DayCount{{Day: today, Count: 1}},
+					}, nil),
+				)
+			},
+			args:    args{days: 0},
+			wantErr: false,
+			checkFunc: func(res *model.TodoStats, err error) {
+				assert.Equal(t, service.DefaultStatsDays, len(res.CompletedPerDay), fmt.Sprintf("days: expected %d got %v", service.DefaultStatsDays, len(res.CompletedPerDay)))
+				assert.Equal(t, today, res.CompletedPerDay[service.DefaultStatsDays-1].Day)
+				assert.Equal(t, int64(1), res.CompletedPerDay[service.DefaultStatsDays-1].Count)
+				assert.Equal(t, int64(0), res.CompletedPerDay[0].Count)
+			},
+		},
+		{
+			name:    "stats todo fail with invalid window",
+			args:    args{days: service.MaxStatsDays + 1},
+			wantErr: true,
+			checkFunc: func(res *model.TodoStats, err error) {
+				assert.Equal(t, err, service.ErrInvalidQueryParams, fmt.Sprintf("err: expected service.ErrInvalidQueryParams got %v", err))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			f := fields{
+				repo: automocks.NewMockTodoRepository(ctrl),
+			}
+			if tt.prepare != nil {
+				tt.prepare(&f)
+			}
+
+			svc := service.New(f.repo, log.NewLogfmtLogger(os.Stderr))
+			if res, err := svc.Stats(context.Background(), tt.args.days); (err != nil) != tt.wantErr {
+				t.Errorf("svc.Stats error = %v, wantErr %v", err, tt.wantErr)
+			} else {
+				if tt.checkFunc != nil {
+					tt.checkFunc(res, err)
+				}
+			}
+		})
+	}
+}
+
+func TestLoggingMiddleware_Unarchive(t *testing.T) {
+	type fields struct {
+		repo *automocks.MockTodoRepository
+	}
+
+	type args struct {
+		id string
+	}
+
+	archivedAt := time.Now().Add(-time.Hour)
+
+	tests := []struct {
+		name      string
+		prepare   func(f *fields)
+		args      args
+		wantErr   bool
+		checkFunc func(res *model.TodoRes, err error)
+	}{
+		{
+			name: "unarchive todo",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.repo.EXPECT().Get(context.Background(), model.Owner{}, "b5z2zC5c9O6~Ns_qLVmn~").Return(&model.Todo{
+						ID:         "b5z2zC5c9O6~Ns_qLVmn~",
+						Text:       "aa",
+						Completed:  true,
+						ArchivedAt: &archivedAt,
+					}, nil),
+					f.repo.EXPECT().Update(context.Background(), gomock.Any()).DoAndReturn(func(_ context.Context, todo *model.Todo) error {
+						assert.Nil(t, todo.ArchivedAt, "archivedAt: expected nil")
+						return nil
+					}),
+				)
+			},
+			args:    args{id: "b5z2zC5c9O6~Ns_qLVmn~"},
+			wantErr: false,
+			checkFunc: func(res *model.TodoRes, err error) {
+				assert.Nil(t, res.ArchivedAt, "archivedAt: expected nil")
+			},
+		},
+		{
+			name: "unarchive todo not archived",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.repo.EXPECT().Get(context.Background(), model.Owner{}, "b5z2zC5c9O6~Ns_qLVmn~").Return(&model.Todo{
+						ID:   "b5z2zC5c9O6~Ns_qLVmn~",
+						Text: "aa",
+					}, nil),
+				)
+			},
+			args:    args{id: "b5z2zC5c9O6~Ns_qLVmn~"},
+			wantErr: false,
+			checkFunc: func(res *model.TodoRes, err error) {
+				assert.Equal(t, "aa", res.Text, fmt.Sprintf("text: expected aa got %v", res.Text))
+			},
+		},
+		{
+			name: "unarchive todo not found",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.repo.EXPECT().Get(context.Background(), model.Owner{}, "b5z2zC5c9O6~Ns_qLVmn~").Return(nil, service.ErrNotFound),
+					f.repo.EXPECT().Grants(context.Background(), model.Owner{}, "b5z2zC5c9O6~Ns_qLVmn~").Return(nil, nil),
+				)
+			},
+			args:    args{id: "b5z2zC5c9O6~Ns_qLVmn~"},
+			wantErr: true,
+			checkFunc: func(res *model.TodoRes, err error) {
+				assert.Equal(t, err, service.ErrNotFound, fmt.Sprintf("err: expected service.ErrNotFound got %v", err))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			f := fields{
+				repo: automocks.NewMockTodoRepository(ctrl),
+			}
+			if tt.prepare != nil {
+				tt.prepare(&f)
+			}
+
+			svc := service.New(f.repo, log.NewLogfmtLogger(os.Stderr))
+			if res, err := svc.Unarchive(context.Background(), tt.args.id); (err != nil) != tt.wantErr {
+				t.Errorf("svc.Unarchive error = %v, wantErr %v", err, tt.wantErr)
+			} else {
+				if tt.checkFunc != nil {
+					tt.checkFunc(res, err)
+				}
+			}
+		})
+	}
+}
+
+func TestArchiver_Archive(t *testing.T) {
+	type fields struct {
+		repo *automocks.MockTodoRepository
+	}
+
+	now := time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		prepare   func(f *fields)
+		wantErr   bool
+		checkFunc func(n int64, err error)
+	}{
+		{
+			name: "archive expired todos",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.repo.EXPECT().Archive(context.Background(), gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, completedBefore, archivedAt time.Time) (int64, error) {
+						assert.Equal(t, now, archivedAt, "archivedAt: expected the time of the clock")
+						assert.Equal(t, now.Add(-24*time.Hour), completedBefore, "completedBefore: expected 24h before the clock")
+						return 2, nil
+					}),
+				)
+			},
+			wantErr: false,
+			checkFunc: func(n int64, err error) {
+				assert.Equal(t, int64(2), n, fmt.Sprintf("archived: expected 2 got %v", n))
+			},
+		},
+		{
+			name: "archive expired todos fail",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.repo.EXPECT().Archive(context.Background(), gomock.Any(), gomock.Any()).Return(int64(0), sql.ErrConnDone),
+				)
+			},
+			wantErr: true,
+			checkFunc: func(n int64, err error) {
+				assert.Equal(t, err, sql.ErrConnDone, fmt.Sprintf("err: expected sql.ErrConnDone got %v", err))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			f := fields{
+				repo: automocks.NewMockTodoRepository(ctrl),
+			}
+			if tt.prepare != nil {
+				tt.prepare(&f)
+			}
+
+			archiver := service.NewArchiver(f.repo, 24*time.Hour, time.Hour, log.NewLogfmtLogger(os.Stderr), service.WithArchiverClock(func() time.Time { return now }))
+			if n, err := archiver.Archive(context.Background()); (err != nil) != tt.wantErr {
+				t.Errorf("archiver.Archive error = %v, wantErr %v", err, tt.wantErr)
+			} else {
+				if tt.checkFunc != nil {
+					tt.checkFunc(n, err)
+				}
+			}
+		})
+	}
+}
+
+func TestLoggingMiddleware_Snooze(t *testing.T) {
+	type fields struct {
+		repo *automocks.MockTodoRepository
+	}
+
+	type args struct {
+		id    string
+		until time.Time
+	}
+
+	now := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		prepare   func(f *fields)
+		args      args
+		wantErr   bool
+		checkFunc func(res *model.TodoRes, err error)
+	}{
+		{
+			name: "snooze todo",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.repo.EXPECT().Get(context.Background(), model.Owner{}, "b5z2zC5c9O6~Ns_qLVmn~").Return(&model.Todo{
+						ID:   "b5z2zC5c9O6~Ns_qLVmn~",
+						Text: "aa",
+					}, nil),
+					f.repo.EXPECT().Update(context.Background(), gomock.Any()).Return(nil),
+				)
+			},
+			args:    args{id: "b5z2zC5c9O6~Ns_qLVmn~", until: now.Add(time.Hour)},
+			wantErr: false,
+			checkFunc: func(res *model.TodoRes, err error) {
+				assert.Equal(t, now.Add(time.Hour), *res.SnoozedUntil, fmt.Sprintf("snoozedUntil: expected %v got %v", now.Add(time.Hour), res.SnoozedUntil))
+				assert.Equal(t, now, res.UpdatedAt, fmt.Sprintf("updatedAt: expected %v got %v", now, res.UpdatedAt))
+			},
+		},
+		{
+			name: "wake todo up",
+			prepare: func(f *fields) {
+				until := now.Add(time.Hour)
+				gomock.InOrder(
+					f.repo.EXPECT().Get(context.Background(), model.Owner{}, "b5z2zC5c9O6~Ns_qLVmn~").Return(&model.Todo{
+						ID:           "b5z2zC5c9O6~Ns_qLVmn~",
+						Text:         "aa",
+						SnoozedUntil: &until,
+					}, nil),
+					f.repo.EXPECT().Update(context.Background(), gomock.Any()).DoAndReturn(func(_ context.Context, todo *model.Todo) error {
+						assert.Nil(t, todo.SnoozedUntil, "snoozedUntil: expected nil")
+						return nil
+					}),
+				)
+			},
+			args:    args{id: "b5z2zC5c9O6~Ns_qLVmn~"},
+			wantErr: false,
+			checkFunc: func(res *model.TodoRes, err error) {
+				assert.Nil(t, res.SnoozedUntil, "snoozedUntil: expected nil")
+			},
+		},
+		{
+			name:    "snooze todo in the past",
+			args:    args{id: "b5z2zC5c9O6~Ns_qLVmn~", until: now},
+			wantErr: true,
+			checkFunc: func(res *model.TodoRes, err error) {
+				assert.Equal(t, err, service.ErrMalformedEntity, fmt.Sprintf("err: expected service.ErrMalformedEntity got %v", err))
+			},
+		},
+		{
+			name: "snooze todo not found",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.repo.EXPECT().Get(context.Background(), model.Owner{}, "b5z2zC5c9O6~Ns_qLVmn~").Return(nil, service.ErrNotFound),
+					f.repo.EXPECT().Grants(context.Background(), model.Owner{}, "b5z2zC5c9O6~Ns_qLVmn~").Return(nil, nil),
+				)
+			},
+			args:    args{id: "b5z2zC5c9O6~Ns_qLVmn~", until: now.Add(time.Hour)},
+			wantErr: true,
+			checkFunc: func(res *model.TodoRes, err error) {
+				assert.Equal(t, err, service.ErrNotFound, fmt.Sprintf("err: expected service.ErrNotFound got %v", err))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			f := fields{
+				repo: automocks.NewMockTodoRepository(ctrl),
+			}
+			if tt.prepare != nil {
+				tt.prepare(&f)
+			}
+
+			svc := service.New(f.repo, log.NewLogfmtLogger(os.Stderr), service.WithClock(func() time.Time { return now }))
+			if res, err := svc.Snooze(context.Background(), tt.args.id, tt.args.until); (err != nil) != tt.wantErr {
+				t.Errorf("svc.Snooze error = %v, wantErr %v", err, tt.wantErr)
+			} else {
+				if tt.checkFunc != nil {
+					tt.checkFunc(res, err)
+				}
+			}
+		})
+	}
+}
+
+func TestLoggingMiddleware_Watch(t *testing.T) {
+	type fields struct {
+		repo *automocks.MockTodoRepository
+	}
+
+	type args struct {
+		query *model.EventQuery
+	}
+
+	text := "aa"
+
+	tests := []struct {
+		name      string
+		prepare   func(f *fields)
+		args      args
+		wantErr   bool
+		checkFunc func(svc service.TodoService, events <-chan *model.TodoEvent, err error)
+	}{
+		{
+			name: "watch todo",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.repo.EXPECT().Add(context.Background(), gomock.Any()).Return(nil),
+					f.repo.EXPECT().Delete(context.Background(), model.Owner{}, "b5z2zC5c9O6~Ns_qLVmn~", uint64(0)).Return(nil),
+				)
+			},
+			args:    args{query: &model.EventQuery{}},
+			wantErr: false,
+			checkFunc: func(svc service.TodoService, events <-chan *model.TodoEvent, err error) {
+				res, _ := svc.Add(context.Background(), &model.TodoReq{Text: &text})
+				_ = svc.Delete(context.Background(), "b5z2zC5c9O6~Ns_qLVmn~")
+
+				ev := <-events
+				assert.Equal(t, model.EventCreated, ev.Type, fmt.Sprintf("type: expected created got %v", ev.Type))
+				assert.Equal(t, res.ID, ev.Todo.ID, fmt.Sprintf("id: expected %v got %v", res.ID, ev.Todo.ID))
+				ev = <-events
+				assert.Equal(t, model.EventDeleted, ev.Type, fmt.Sprintf("type: expected deleted got %v", ev.Type))
+				assert.Equal(t, uint64(2), ev.Seq, fmt.Sprintf("seq: expected 2 got %v", ev.Seq))
+			},
+		},
+		{
+			name: "watch deleted todo",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.repo.EXPECT().Add(context.Background(), gomock.Any()).Return(nil),
+					f.repo.EXPECT().Delete(context.Background(), model.Owner{}, "b5z2zC5c9O6~Ns_qLVmn~", uint64(0)).Return(nil),
+				)
+			},
+			args:    args{query: &model.EventQuery{Types: []string{model.EventDeleted}}},
+			wantErr: false,
+			checkFunc: func(svc service.TodoService, events <-chan *model.TodoEvent, err error) {
+				_, _ = svc.Add(context.Background(), &model.TodoReq{Text: &text})
+				_ = svc.Delete(context.Background(), "b5z2zC5c9O6~Ns_qLVmn~")
+
+				ev := <-events
+				assert.Equal(t, model.EventDeleted, ev.Type, fmt.Sprintf("type: expected deleted got %v", ev.Type))
+				assert.Equal(t, "b5z2zC5c9O6~Ns_qLVmn~", ev.Todo.ID, fmt.Sprintf("id: expected b5z2zC5c9O6~Ns_qLVmn~ got %v", ev.Todo.ID))
+			},
+		},
+		{
+			name:    "watch unknown event type",
+			args:    args{query: &model.EventQuery{Types: []string{"archived"}}},
+			wantErr: true,
+			checkFunc: func(svc service.TodoService, events <-chan *model.TodoEvent, err error) {
+				assert.Equal(t, err, service.ErrInvalidQueryParams, fmt.Sprintf("err: expected service.ErrInvalidQueryParams got %v", err))
+			},
+		},
+		{
+			name:    "watch from an unknown sequence",
+			args:    args{query: &model.EventQuery{Since: 10}},
+			wantErr: true,
+			checkFunc: func(svc service.TodoService, events <-chan *model.TodoEvent, err error) {
+				assert.Equal(t, err, service.ErrSequenceExpired, fmt.Sprintf("err: expected service.ErrSequenceExpired got %v", err))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			f := fields{
+				repo: automocks.NewMockTodoRepository(ctrl),
+			}
+			if tt.prepare != nil {
+				tt.prepare(&f)
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			svc := service.New(f.repo, log.NewLogfmtLogger(os.Stderr))
+			if events, err := svc.Watch(ctx, tt.args.query); (err != nil) != tt.wantErr {
+				t.Errorf("svc.Watch error = %v, wantErr %v", err, tt.wantErr)
+			} else {
+				if tt.checkFunc != nil {
+					tt.checkFunc(svc, events, err)
+				}
+			}
+		})
+	}
+}
+
+func TestLoggingMiddleware_Sync(t *testing.T) {
+	type fields struct {
+		repo *automocks.MockTodoRepository
+	}
+
+	type args struct {
+		token string
+	}
+
+	page := make([]*model.Todo, service.MaxSyncChanges)
+	for i := range page {
+		page[i] = &model.Todo{ID: fmt.Sprintf("todo-%d", i), Version: uint64(i + 1), TxID: 7}
+	}
+
+	tests := []struct {
+		name      string
+		prepare   func(f *fields)
+		args      args
+		wantErr   bool
+		checkFunc func(res *model.Changes, err error)
+	}{
+		{
+			name: "sync changes in commit order",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.repo.EXPECT().Changes(context.Background(), model.Owner{}, model.ChangeCursor{TxID: 10, Version: 3}, service.MaxSyncChanges).Return(
+						[]*model.Todo{{ID: "iKe0KxpurIn0E_6vzUDAr", Version: 6, TxID: 11}, {ID: "zIYPEK0zEpUc7CoQWIGB2", Version: 4, TxID: 12}},
+						[]*model.Tombstone{{ID: "b5z2zC5c9O6~Ns_qLVmn~", Version: 5, TxID: 11}},
+						nil,
+					),
+				)
+			},
+			args:    args{token: "10.3"},
+			wantErr: false,
+			checkFunc: func(res *model.Changes, err error) {
+				assert.Len(t, res.Todos, 2, fmt.Sprintf("todos: expected 2 got %v", len(res.Todos)))
+				assert.Len(t, res.Deleted, 1, fmt.Sprintf("deleted: expected 1 got %v", len(res.Deleted)))
+				assert.Equal(t, "12.4", res.Token, fmt.Sprintf("token: expected 12.4 got %v", res.Token))
+				assert.False(t, res.More)
+			},
+		},
+		{
+			name: "sync without changes",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.repo.EXPECT().Changes(context.Background(), model.Owner{}, model.ChangeCursor{}, service.MaxSyncChanges).Return(nil, nil, nil),
+				)
+			},
+			args:    args{token: ""},
+			wantErr: false,
+			checkFunc: func(res *model.Changes, err error) {
+				assert.Empty(t, res.Todos)
+				assert.Empty(t, res.Deleted)
+				assert.Equal(t, "0.0", res.Token, fmt.Sprintf("token: expected 0.0 got %v", res.Token))
+			},
+		},
+		{
+			name: "sync more changes than a page",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.repo.EXPECT().Changes(context.Background(), model.Owner{}, model.ChangeCursor{}, service.MaxSyncChanges).Return(
+						page,
+						[]*model.Tombstone{{ID: "b5z2zC5c9O6~Ns_qLVmn~", Version: 1, TxID: 8}},
+						nil,
+					),
+				)
+			},
+			args:    args{token: ""},
+			wantErr: false,
+			checkFunc: func(res *model.Changes, err error) {
+				assert.Len(t, res.Todos, service.MaxSyncChanges)
+				assert.Empty(t, res.Deleted)
+				assert.Equal(t, fmt.Sprintf("7.%d", service.MaxSyncChanges), res.Token)
+				assert.True(t, res.More)
+			},
+		},
+		{
+			name:    "sync with malformed token",
+			args:    args{token: "abc"},
+			wantErr: true,
+			checkFunc: func(res *model.Changes, err error) {
+				assert.True(t, errors.Contains(errors.Cast(err), service.ErrInvalidQueryParams), fmt.Sprintf("err: expected service.ErrInvalidQueryParams got %v", err))
+			},
+		},
+		{
+			name:    "sync with a version token",
+			args:    args{token: "3"},
+			wantErr: true,
+			checkFunc: func(res *model.Changes, err error) {
+				assert.True(t, errors.Contains(errors.Cast(err), service.ErrInvalidQueryParams), fmt.Sprintf("err: expected service.ErrInvalidQueryParams got %v", err))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			f := fields{
+				repo: automocks.NewMockTodoRepository(ctrl),
+			}
+			if tt.prepare != nil {
+				tt.prepare(&f)
+			}
+
+			svc := service.New(f.repo, log.NewLogfmtLogger(os.Stderr))
+			if res, err := svc.Sync(context.Background(), tt.args.token); (err != nil) != tt.wantErr {
+				t.Errorf("svc.Sync error = %v, wantErr %v", err, tt.wantErr)
+			} else {
+				if tt.checkFunc != nil {
+					tt.checkFunc(res, err)
+				}
+			}
+		})
+	}
+}
+
+func TestLoggingMiddleware_Push(t *testing.T) {
+	type fields struct {
+		repo *automocks.MockTodoRepository
+	}
+
+	type args struct {
+		mutations []*model.Mutation
+	}
+
+	now := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	text := "bb"
+	id := "zIYPEK0zEpUc7CoQWIGB2"
+
+	tests := []struct {
+		name      string
+		prepare   func(f *fields)
+		args      args
+		wantErr   bool
+		checkFunc func(res []*model.MutationResult, err error)
+	}{
+		{
+			name: "push a created todo",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.repo.EXPECT().Get(context.Background(), model.Owner{}, id).Return(nil, service.ErrNotFound),
+					f.repo.EXPECT().Add(context.Background(), gomock.Any()).Return(nil),
+				)
+			},
+			args:    args{mutations: []*model.Mutation{{Op: model.MutationCreate, ID: id, Todo: &model.TodoReq{Text: &text}}}},
+			wantErr: false,
+			checkFunc: func(res []*model.MutationResult, err error) {
+				assert.Equal(t, model.MutationApplied, res[0].Status)
+				assert.Equal(t, id, res[0].Todo.ID)
+				assert.Equal(t, text, res[0].Todo.Text)
+			},
+		},
+		{
+			name: "push a created todo already existing",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.repo.EXPECT().Get(context.Background(), model.Owner{}, id).Return(&model.Todo{ID: id, Text: "aa", Version: 4}, nil),
+				)
+			},
+			args:    args{mutations: []*model.Mutation{{Op: model.MutationCreate, ID: id, Todo: &model.TodoReq{Text: &text}}}},
+			wantErr: false,
+			checkFunc: func(res []*model.MutationResult, err error) {
+				assert.Equal(t, model.MutationConflict, res[0].Status)
+				assert.Equal(t, "aa", res[0].Todo.Text)
+			},
+		},
+		{
+			name:    "push a created todo with a malformed id",
+			args:    args{mutations: []*model.Mutation{{Op: model.MutationCreate, ID: "b5z2zC5c9O6~Ns_qLVmn~", Todo: &model.TodoReq{Text: &text}}}},
+			wantErr: false,
+			checkFunc: func(res []*model.MutationResult, err error) {
+				assert.Equal(t, model.MutationRejected, res[0].Status)
+				assert.Equal(t, service.ErrMalformedEntity.Error(), res[0].Error)
+			},
+		},
+		{
+			name: "push a created todo with the id of a todo of another owner",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.repo.EXPECT().Get(context.Background(), model.Owner{}, id).Return(nil, service.ErrNotFound),
+					f.repo.EXPECT().Add(context.Background(), gomock.Any()).Return(service.ErrConflict),
+					f.repo.EXPECT().Get(context.Background(), model.Owner{}, id).Return(nil, service.ErrNotFound),
+					f.repo.EXPECT().Get(context.Background(), model.Owner{}, "iKe0KxpurIn0E_6vzUDAr").Return(nil, service.ErrNotFound),
+					f.repo.EXPECT().Add(context.Background(), gomock.Any()).Return(nil),
+				)
+			},
+			args: args{mutations: []*model.Mutation{
+				{Op: model.MutationCreate, ID: id, Todo: &model.TodoReq{Text: &text}},
+				{Op: model.MutationCreate, ID: "iKe0KxpurIn0E_6vzUDAr", Todo: &model.TodoReq{Text: &text}},
+			}},
+			wantErr: false,
+			checkFunc: func(res []*model.MutationResult, err error) {
+				assert.Len(t, res, 2)
+				assert.Equal(t, model.MutationRejected, res[0].Status)
+				assert.Equal(t, service.ErrIDTaken.Error(), res[0].Error)
+				assert.Nil(t, res[0].Todo)
+				assert.Equal(t, model.MutationApplied, res[1].Status)
+			},
+		},
+		{
+			name: "push a created todo created concurrently",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.repo.EXPECT().Get(context.Background(), model.Owner{}, id).Return(nil, service.ErrNotFound),
+					f.repo.EXPECT().Add(context.Background(), gomock.Any()).Return(service.ErrConflict),
+					f.repo.EXPECT().Get(context.Background(), model.Owner{}, id).Return(&model.Todo{ID: id, Text: "aa", Version: 4}, nil),
+				)
+			},
+			args:    args{mutations: []*model.Mutation{{Op: model.MutationCreate, ID: id, Todo: &model.TodoReq{Text: &text}}}},
+			wantErr: false,
+			checkFunc: func(res []*model.MutationResult, err error) {
+				assert.Equal(t, model.MutationConflict, res[0].Status)
+				assert.Equal(t, "aa", res[0].Todo.Text)
+			},
+		},
+		{
+			name: "push an updated todo",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.repo.EXPECT().Get(context.Background(), model.Owner{}, id).Return(&model.Todo{ID: id, Text: "aa", Version: 4}, nil),
+					f.repo.EXPECT().Update(context.Background(), gomock.Any()).DoAndReturn(func(_ context.Context, todo *model.Todo) error {
+						assert.Equal(t, uint64(4), todo.Version, "update: expected to apply to version 4")
+						todo.Version = 5
+						return nil
+					}),
+				)
+			},
+			args:    args{mutations: []*model.Mutation{{Op: model.MutationUpdate, ID: id, Version: 4, Todo: &model.TodoReq{Text: &text}}}},
+			wantErr: false,
+			checkFunc: func(res []*model.MutationResult, err error) {
+				assert.Equal(t, model.MutationApplied, res[0].Status)
+				assert.Equal(t, text, res[0].Todo.Text)
+				assert.Equal(t, uint64(5), res[0].Todo.Version)
+			},
+		},
+		{
+			name: "push an updated todo changed since",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.repo.EXPECT().Get(context.Background(), model.Owner{}, id).Return(&model.Todo{ID: id, Text: "aa", Version: 5}, nil),
+				)
+			},
+			args:    args{mutations: []*model.Mutation{{Op: model.MutationUpdate, ID: id, Version: 4, Todo: &model.TodoReq{Text: &text}}}},
+			wantErr: false,
+			checkFunc: func(res []*model.MutationResult, err error) {
+				assert.Equal(t, model.MutationConflict, res[0].Status)
+				assert.Equal(t, "aa", res[0].Todo.Text)
+			},
+		},
+		{
+			name: "push an updated todo updated after it",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.repo.EXPECT().Get(context.Background(), model.Owner{}, id).Return(&model.Todo{ID: id, Text: "aa", UpdatedAt: now, Version: 5}, nil),
+				)
+			},
+			args: args{mutations: []*model.Mutation{{Op: model.MutationUpdate, ID: id, UpdatedAt: func() *time.Time {
+				t := now.Add(-time.Minute)
+				return &t
+			}(), Todo: &model.TodoReq{Text: &text}}}},
+			wantErr: false,
+			checkFunc: func(res []*model.MutationResult, err error) {
+				assert.Equal(t, model.MutationConflict, res[0].Status)
+			},
+		},
+		{
+			name: "push an updated todo changed concurrently",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.repo.EXPECT().Get(context.Background(), model.Owner{}, id).Return(&model.Todo{ID: id, Text: "aa", Version: 4}, nil),
+					f.repo.EXPECT().Update(context.Background(), gomock.Any()).Return(service.ErrConflict),
+					f.repo.EXPECT().Get(context.Background(), model.Owner{}, id).Return(&model.Todo{ID: id, Text: "cc", Version: 6}, nil),
+				)
+			},
+			args:    args{mutations: []*model.Mutation{{Op: model.MutationUpdate, ID: id, Version: 4, Todo: &model.TodoReq{Text: &text}}}},
+			wantErr: false,
+			checkFunc: func(res []*model.MutationResult, err error) {
+				assert.Equal(t, model.MutationConflict, res[0].Status)
+				assert.Equal(t, "cc", res[0].Todo.Text)
+			},
+		},
+		{
+			name: "push a deleted todo",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.repo.EXPECT().Get(context.Background(), model.Owner{}, id).Return(&model.Todo{ID: id, Version: 4}, nil),
+					f.repo.EXPECT().Delete(context.Background(), model.Owner{}, id, uint64(4)).Return(nil),
+					f.repo.EXPECT().Get(context.Background(), model.Owner{}, "iKe0KxpurIn0E_6vzUDAr").Return(nil, service.ErrNotFound),
+				)
+			},
+			args: args{mutations: []*model.Mutation{
+				{Op: model.MutationDelete, ID: id, Version: 4},
+				{Op: model.MutationDelete, ID: "iKe0KxpurIn0E_6vzUDAr", Version: 2},
+			}},
+			wantErr: false,
+			checkFunc: func(res []*model.MutationResult, err error) {
+				assert.Len(t, res, 2)
+				for _, r := range res {
+					assert.Equal(t, model.MutationApplied, r.Status)
+					assert.Nil(t, r.Todo)
+				}
+			},
+		},
+		{
+			name: "push a malformed mutation",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.repo.EXPECT().Get(context.Background(), model.Owner{}, id).Return(&model.Todo{ID: id, Version: 4}, nil),
+				)
+			},
+			args:    args{mutations: []*model.Mutation{{Op: "rename", ID: id}, {Op: model.MutationCreate}}},
+			wantErr: false,
+			checkFunc: func(res []*model.MutationResult, err error) {
+				assert.Len(t, res, 2)
+				for _, r := range res {
+					assert.Equal(t, model.MutationRejected, r.Status)
+					assert.Equal(t, service.ErrMalformedEntity.Error(), r.Error)
+				}
+			},
+		},
+		{
+			name:    "push too many mutations",
+			args:    args{mutations: make([]*model.Mutation, service.MaxPushMutations+1)},
+			wantErr: true,
+			checkFunc: func(res []*model.MutationResult, err error) {
+				assert.Equal(t, err, service.ErrMalformedEntity, fmt.Sprintf("err: expected service.ErrMalformedEntity got %v", err))
+			},
+		},
+		{
+			name: "push fail",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.repo.EXPECT().Get(context.Background(), model.Owner{}, id).Return(nil, sql.ErrConnDone),
+				)
+			},
+			args:    args{mutations: []*model.Mutation{{Op: model.MutationDelete, ID: id}}},
+			wantErr: true,
+			checkFunc: func(res []*model.MutationResult, err error) {
+				assert.Equal(t, err, sql.ErrConnDone, fmt.Sprintf("err: expected sql.ErrConnDone got %v", err))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			f := fields{
+				repo: automocks.NewMockTodoRepository(ctrl),
+			}
+			if tt.prepare != nil {
+				tt.prepare(&f)
+			}
+
+			svc := service.New(f.repo, log.NewLogfmtLogger(os.Stderr), service.WithClock(func() time.Time { return now }))
+			if res, err := svc.Push(context.Background(), tt.args.mutations); (err != nil) != tt.wantErr {
+				t.Errorf("svc.Push error = %v, wantErr %v", err, tt.wantErr)
+			} else {
+				if tt.checkFunc != nil {
+					tt.checkFunc(res, err)
+				}
+			}
+		})
+	}
+}
+
+func TestStubTodoService_Owners(t *testing.T) {
+	type fields struct {
+		repo *automocks.MockTodoRepository
+	}
+
+	var (
+		alice    = model.Owner{ID: "alice", TenantID: "acme"}
+		aliceCtx = authn.NewContext(context.Background(), &authn.Claims{StandardClaims: jwt.StandardClaims{Subject: "alice"}, Tenant: "acme"})
+		bobCtx   = authn.NewContext(context.Background(), &authn.Claims{StandardClaims: jwt.StandardClaims{Subject: "bob"}, Tenant: "acme"})
+		now      = time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+		id       = "iKe0KxpurIn0E_6vzUDAr"
+		text     = "aa"
+	)
+
+	tests := []struct {
+		name      string
+		prepare   func(f *fields)
+		run       func(svc service.TodoService) error
+		wantErr   bool
+		checkFunc func(err error)
+	}{
+		{
+			name: "add todo of the caller",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.repo.EXPECT().Add(aliceCtx, gomock.Any()).DoAndReturn(func(_ context.Context, todo *model.Todo) error {
+						assert.Equal(t, alice, todo.Owner(), fmt.Sprintf("owner: expected %v got %v", alice, todo.Owner()))
+						return nil
+					}),
+				)
+			},
+			run: func(svc service.TodoService) error {
+				_, err := svc.Add(aliceCtx, &model.TodoReq{Text: &text})
+				return err
+			},
+		},
+		{
+			name: "list todos of the caller",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.repo.EXPECT().List(aliceCtx, &model.TodoQuery{Now: now, Owner: alice}).Return([]*model.Todo{{ID: id, OwnerID: alice.ID, TenantID: alice.TenantID}}, nil),
+				)
+			},
+			run: func(svc service.TodoService) error {
+				res, err := svc.List(aliceCtx, nil)
+				assert.Len(t, res, 1, fmt.Sprintf("count: expected 1 got %v", len(res)))
+				return err
+			},
+		},
+		{
+			name: "get todo of another owner",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.repo.EXPECT().Get(aliceCtx, alice, id).Return(nil, service.ErrNotFound),
+					f.repo.EXPECT().Grants(aliceCtx, alice, id).Return(nil, nil),
+				)
+			},
+			run: func(svc service.TodoService) error {
+				_, err := svc.Get(aliceCtx, id)
+				return err
+			},
+			wantErr: true,
+			checkFunc: func(err error) {
+				assert.Equal(t, service.ErrNotFound, err, fmt.Sprintf("err: expected service.ErrNotFound got %v", err))
+			},
+		},
+		{
+			name: "update todo of another owner",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.repo.EXPECT().Get(aliceCtx, alice, id).Return(nil, service.ErrNotFound),
+					f.repo.EXPECT().Grants(aliceCtx, alice, id).Return(nil, nil),
+				)
+			},
+			run: func(svc service.TodoService) error {
+				_, err := svc.Update(aliceCtx, id, &model.TodoReq{Text: &text})
+				return err
+			},
+			wantErr: true,
+			checkFunc: func(err error) {
+				assert.Equal(t, service.ErrNotFound, err, fmt.Sprintf("err: expected service.ErrNotFound got %v", err))
+			},
+		},
+		{
+			name: "delete todo of another owner",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.repo.EXPECT().Delete(aliceCtx, alice, id, uint64(0)).Return(service.ErrNotFound),
+					f.repo.EXPECT().Grants(aliceCtx, alice, id).Return(nil, nil),
+				)
+			},
+			run: func(svc service.TodoService) error {
+				return svc.Delete(aliceCtx, id)
+			},
+			wantErr: true,
+			checkFunc: func(err error) {
+				assert.Equal(t, service.ErrNotFound, err, fmt.Sprintf("err: expected service.ErrNotFound got %v", err))
+			},
+		},
+		{
+			name: "watch todos of the caller",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.repo.EXPECT().Add(bobCtx, gomock.Any()).Return(nil),
+					f.repo.EXPECT().Delete(bobCtx, model.Owner{ID: "bob", TenantID: "acme"}, id, uint64(0)).Return(nil),
+					f.repo.EXPECT().Add(aliceCtx, gomock.Any()).Return(nil),
+				)
+			},
+			run: func(svc service.TodoService) error {
+				ctx, cancel := context.WithCancel(aliceCtx)
+				defer cancel()
+				events, err := svc.Watch(ctx, nil)
+				if err != nil {
+					return err
+				}
+
+				_, _ = svc.Add(bobCtx, &model.TodoReq{Text: &text})
+				_ = svc.Delete(bobCtx, id)
+				res, _ := svc.Add(aliceCtx, &model.TodoReq{Text: &text})
+
+				ev := <-events
+				assert.Equal(t, res.ID, ev.Todo.ID, fmt.Sprintf("id: expected %v got %v", res.ID, ev.Todo.ID))
+				assert.Equal(t, uint64(3), ev.Seq, fmt.Sprintf("seq: expected 3 got %v", ev.Seq))
+				return nil
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			f := fields{
+				repo: automocks.NewMockTodoRepository(ctrl),
+			}
+			if tt.prepare != nil {
+				tt.prepare(&f)
+			}
+
+			svc := service.New(f.repo, log.NewLogfmtLogger(os.Stderr), service.WithClock(func() time.Time { return now }))
+			if err := tt.run(svc); (err != nil) != tt.wantErr {
+				t.Errorf("svc error = %v, wantErr %v", err, tt.wantErr)
+			} else {
+				if tt.checkFunc != nil {
+					tt.checkFunc(err)
+				}
+			}
+		})
+	}
+}
+
+func TestStubTodoService_Shares(t *testing.T) {
+	type fields struct {
+		repo *automocks.MockTodoRepository
+	}
+
+	var (
+		alice    = model.Owner{ID: "alice", TenantID: "acme"}
+		bob      = model.Owner{ID: "bob", TenantID: "acme"}
+		aliceCtx = authn.NewContext(context.Background(), &authn.Claims{StandardClaims: jwt.StandardClaims{Subject: "alice"}, Tenant: "acme"})
+		now      = time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+		id       = "iKe0KxpurIn0E_6vzUDAr"
+		text     = "aa"
+		todo     = func() *model.Todo { return &model.Todo{ID: id, OwnerID: bob.ID, TenantID: bob.TenantID, Text: "bb"} }
+		viewer   = &model.Share{ID: "s1", OwnerID: bob.ID, TenantID: bob.TenantID, GranteeID: alice.ID, Role: model.RoleViewer}
+		editor   = &model.Share{ID: "s2", OwnerID: bob.ID, TenantID: bob.TenantID, TodoID: id, GranteeID: alice.ID, Role: model.RoleEditor}
+	)
+
+	tests := []struct {
+		name      string
+		prepare   func(f *fields)
+		run       func(svc service.TodoService) error
+		wantErr   bool
+		checkFunc func(err error)
+	}{
+		{
+			name: "get todo shared with the caller",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.repo.EXPECT().Get(aliceCtx, alice, id).Return(nil, service.ErrNotFound),
+					f.repo.EXPECT().Grants(aliceCtx, alice, id).Return([]*model.Share{viewer}, nil),
+					f.repo.EXPECT().Get(aliceCtx, bob, id).Return(todo(), nil),
+				)
+			},
+			run: func(svc service.TodoService) error {
+				res, err := svc.Get(aliceCtx, id)
+				if err == nil {
+					assert.Equal(t, bob.ID, res.OwnerID, fmt.Sprintf("owner: expected %v got %v", bob.ID, res.OwnerID))
+				}
+				return err
+			},
+		},
+		{
+			name: "update todo shared with a viewer",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.repo.EXPECT().Get(aliceCtx, alice, id).Return(nil, service.ErrNotFound),
+					f.repo.EXPECT().Grants(aliceCtx, alice, id).Return([]*model.Share{viewer}, nil),
+				)
+			},
+			run: func(svc service.TodoService) error {
+				_, err := svc.Update(aliceCtx, id, &model.TodoReq{Text: &text})
+				return err
+			},
+			wantErr: true,
+			checkFunc: func(err error) {
+				assert.True(t, errors.Contains(errors.Cast(err), authz.ErrForbidden), fmt.Sprintf("err: expected authz.ErrForbidden got %v", err))
+			},
+		},
+		{
+			name: "update todo shared with an editor",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.repo.EXPECT().Get(aliceCtx, alice, id).Return(nil, service.ErrNotFound),
+					f.repo.EXPECT().Grants(aliceCtx, alice, id).Return([]*model.Share{viewer, editor}, nil),
+					f.repo.EXPECT().Get(aliceCtx, bob, id).Return(todo(), nil),
+					f.repo.EXPECT().Update(aliceCtx, gomock.Any()).DoAndReturn(func(_ context.Context, todo *model.Todo) error {
+						assert.Equal(t, bob, todo.Owner(), fmt.Sprintf("owner: expected %v got %v", bob, todo.Owner()))
+						return nil
+					}),
+				)
+			},
+			run: func(svc service.TodoService) error {
+				res, err := svc.Update(aliceCtx, id, &model.TodoReq{Text: &text})
+				if err == nil {
+					assert.Equal(t, text, res.Text, fmt.Sprintf("text: expected %v got %v", text, res.Text))
+				}
+				return err
+			},
+		},
+		{
+			name: "delete todo shared with an editor",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.repo.EXPECT().Delete(aliceCtx, alice, id, uint64(0)).Return(service.ErrNotFound),
+					f.repo.EXPECT().Grants(aliceCtx, alice, id).Return([]*model.Share{editor}, nil),
+					f.repo.EXPECT().Get(aliceCtx, bob, id).Return(todo(), nil),
+					f.repo.EXPECT().Delete(aliceCtx, bob, id, uint64(0)).Return(nil),
+				)
+			},
+			run: func(svc service.TodoService) error {
+				return svc.Delete(aliceCtx, id)
+			},
+		},
+		{
+			name: "list todos shared with the caller",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.repo.EXPECT().List(aliceCtx, &model.TodoQuery{Now: now, Owner: alice, Shared: true}).Return([]*model.Todo{todo()}, nil),
+				)
+			},
+			run: func(svc service.TodoService) error {
+				res, err := svc.List(aliceCtx, &model.TodoQuery{Shared: true})
+				assert.Len(t, res, 1, fmt.Sprintf("count: expected 1 got %v", len(res)))
+				return err
+			},
+		},
+		{
+			name: "share a todo of the caller",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.repo.EXPECT().Get(aliceCtx, alice, id).Return(&model.Todo{ID: id, OwnerID: alice.ID, TenantID: alice.TenantID}, nil),
+					f.repo.EXPECT().AddShare(aliceCtx, gomock.Any()).DoAndReturn(func(_ context.Context, share *model.Share) error {
+						assert.Equal(t, alice, share.Owner(), fmt.Sprintf("owner: expected %v got %v", alice, share.Owner()))
+						assert.Equal(t, now, share.CreatedAt, fmt.Sprintf("createdAt: expected %v got %v", now, share.CreatedAt))
+						return nil
+					}),
+				)
+			},
+			run: func(svc service.TodoService) error {
+				res, err := svc.AddShare(aliceCtx, &model.ShareReq{TodoID: id, GranteeID: bob.ID, Role: model.RoleEditor})
+				if err == nil {
+					assert.NotEmpty(t, res.ID, "id: expected not empty")
+				}
+				return err
+			},
+		},
+		{
+			name: "share a todo of another owner",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.repo.EXPECT().Get(aliceCtx, alice, id).Return(nil, service.ErrNotFound),
+				)
+			},
+			run: func(svc service.TodoService) error {
+				_, err := svc.AddShare(aliceCtx, &model.ShareReq{TodoID: id, GranteeID: bob.ID, Role: model.RoleViewer})
+				return err
+			},
+			wantErr: true,
+			checkFunc: func(err error) {
+				assert.Equal(t, service.ErrNotFound, err, fmt.Sprintf("err: expected service.ErrNotFound got %v", err))
+			},
+		},
+		{
+			name: "share todos with the caller",
+			run: func(svc service.TodoService) error {
+				_, err := svc.AddShare(aliceCtx, &model.ShareReq{GranteeID: alice.ID, Role: model.RoleViewer})
+				return err
+			},
+			wantErr: true,
+			checkFunc: func(err error) {
+				assert.Equal(t, service.ErrMalformedEntity, err, fmt.Sprintf("err: expected service.ErrMalformedEntity got %v", err))
+			},
+		},
+		{
+			name: "watch leaves out the todos shared with the caller",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.repo.EXPECT().Get(aliceCtx, alice, id).Return(nil, service.ErrNotFound),
+					f.repo.EXPECT().Grants(aliceCtx, alice, id).Return([]*model.Share{editor}, nil),
+					f.repo.EXPECT().Get(aliceCtx, bob, id).Return(todo(), nil),
+					f.repo.EXPECT().Update(aliceCtx, gomock.Any()).Return(nil),
+					f.repo.EXPECT().Add(aliceCtx, gomock.Any()).Return(nil),
+				)
+			},
+			run: func(svc service.TodoService) error {
+				ctx, cancel := context.WithCancel(aliceCtx)
+				defer cancel()
+				events, err := svc.Watch(ctx, nil)
+				if err != nil {
+					return err
+				}
+
+				// the update of the shared todo is not streamed
+				if _, err := svc.Update(aliceCtx, id, &model.TodoReq{Text: &text}); err != nil {
+					return err
+				}
+				res, err := svc.Add(aliceCtx, &model.TodoReq{Text: &text})
+				if err != nil {
+					return err
+				}
+
+				ev := <-events
+				assert.Equal(t, res.ID, ev.Todo.ID, fmt.Sprintf("id: expected %v got %v", res.ID, ev.Todo.ID))
+				assert.Equal(t, alice.ID, ev.Todo.OwnerID, fmt.Sprintf("owner: expected %v got %v", alice.ID, ev.Todo.OwnerID))
+				return nil
+			},
+		},
+		{
+			name: "sync leaves out the todos shared with the caller",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.repo.EXPECT().Changes(aliceCtx, alice, model.ChangeCursor{}, service.MaxSyncChanges).Return(nil, nil, nil),
+				)
+			},
+			run: func(svc service.TodoService) error {
+				res, err := svc.Sync(aliceCtx, "")
+				if err == nil {
+					assert.Empty(t, res.Todos, "todos: expected none")
+				}
+				return err
+			},
+		},
+		{
+			name: "list shares of the caller",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.repo.EXPECT().ListShares(aliceCtx, alice).Return(nil, nil),
+				)
+			},
+			run: func(svc service.TodoService) error {
+				res, err := svc.ListShares(aliceCtx)
+				assert.NotNil(t, res, "shares: expected not nil")
+				return err
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			f := fields{
+				repo: automocks.NewMockTodoRepository(ctrl),
+			}
+			if tt.prepare != nil {
+				tt.prepare(&f)
+			}
+
+			svc := service.New(f.repo, log.NewLogfmtLogger(os.Stderr), service.WithClock(func() time.Time { return now }))
+			if err := tt.run(svc); (err != nil) != tt.wantErr {
+				t.Errorf("svc error = %v, wantErr %v", err, tt.wantErr)
+			} else {
+				if tt.checkFunc != nil {
+					tt.checkFunc(err)
+				}
+			}
+		})
+	}
+}
+
+func TestStubTodoService_APIKeys(t *testing.T) {
+	type fields struct {
+		repo *automocks.MockTodoRepository
+	}
+
+	var (
+		adminCtx = authn.NewContext(context.Background(), &authn.Claims{StandardClaims: jwt.StandardClaims{Subject: "admin"}, Tenant: "acme"})
+		aliceCtx = authn.NewContext(context.Background(), &authn.Claims{StandardClaims: jwt.StandardClaims{Subject: "alice"}, Tenant: "acme"})
+		now      = time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+		later    = now.Add(24 * time.Hour)
+	)
+
+	policy, err := authz.ParsePolicy([]byte(`{
+		"roles": {"viewer": ["todo:list"], "editor": ["todo:*"], "keys": ["apikey:*"]},
+		"subjects": {"acme/admin": ["editor", "keys"]},
+		"default": ["viewer"]
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		prepare   func(f *fields)
+		opts      []service.Option
+		run       func(svc service.TodoService) error
+		wantErr   bool
+		checkFunc func(err error)
+	}{
+		{
+			name: "create API key",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.repo.EXPECT().AddAPIKey(adminCtx, gomock.Any()).DoAndReturn(func(_ context.Context, key *model.APIKey) error {
+						assert.Equal(t, "ci", key.Subject, fmt.Sprintf("subject: expected ci got %v", key.Subject))
+						assert.Equal(t, "acme", key.TenantID, fmt.Sprintf("tenant: expected acme got %v", key.TenantID))
+						assert.Equal(t, "admin", key.OwnerID, fmt.Sprintf("owner: expected admin got %v", key.OwnerID))
+						assert.Equal(t, now, key.CreatedAt, fmt.Sprintf("createdAt: expected %v got %v", now, key.CreatedAt))
+						return nil
+					}),
+				)
+			},
+			run: func(svc service.TodoService) error {
+				res, err := svc.CreateAPIKey(adminCtx, &model.APIKeyReq{Name: "ci", Subject: "ci", Scopes: []string{"editor"}, ExpiresAt: &later})
+				if err == nil {
+					assert.True(t, strings.HasPrefix(res.Key, authn.APIKeyPrefix), fmt.Sprintf("key: expected the %s prefix got %v", authn.APIKeyPrefix, res.Key))
+					assert.Equal(t, authn.HashAPIKey(res.Key), res.Hash, "hash: expected the hash of the key")
+				}
+				return err
+			},
+		},
+		{
+			name: "create API key of the caller",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.repo.EXPECT().AddAPIKey(adminCtx, gomock.Any()).Return(nil),
+				)
+			},
+			run: func(svc service.TodoService) error {
+				res, err := svc.CreateAPIKey(adminCtx, &model.APIKeyReq{Name: "cron"})
+				if err == nil {
+					assert.Equal(t, "admin", res.Subject, fmt.Sprintf("subject: expected admin got %v", res.Subject))
+				}
+				return err
+			},
+		},
+		{
+			name: "create API key of the caller as a viewer",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.repo.EXPECT().AddAPIKey(aliceCtx, gomock.Any()).Return(nil),
+				)
+			},
+			run: func(svc service.TodoService) error {
+				_, err := svc.CreateAPIKey(aliceCtx, &model.APIKeyReq{Name: "cron", Scopes: []string{"viewer"}})
+				return err
+			},
+		},
+		{
+			name: "create API key of another subject without the admin permission",
+			run: func(svc service.TodoService) error {
+				_, err := svc.CreateAPIKey(aliceCtx, &model.APIKeyReq{Name: "ci", Subject: "ci"})
+				return err
+			},
+			wantErr: true,
+			checkFunc: func(err error) {
+				assert.True(t, errors.Contains(errors.Cast(err), authz.ErrForbidden), fmt.Sprintf("err: expected authz.ErrForbidden got %v", err))
+			},
+		},
+		{
+			name: "create API key with a scope the caller does not have",
+			run: func(svc service.TodoService) error {
+				_, err := svc.CreateAPIKey(aliceCtx, &model.APIKeyReq{Name: "cron", Scopes: []string{"editor"}})
+				return err
+			},
+			wantErr: true,
+			checkFunc: func(err error) {
+				assert.True(t, errors.Contains(errors.Cast(err), authz.ErrForbidden), fmt.Sprintf("err: expected authz.ErrForbidden got %v", err))
+			},
+		},
+		{
+			name: "create API key without an authorization policy",
+			opts: []service.Option{service.WithAuthorizer(nil)},
+			run: func(svc service.TodoService) error {
+				_, err := svc.CreateAPIKey(adminCtx, &model.APIKeyReq{Name: "cron"})
+				return err
+			},
+			wantErr: true,
+			checkFunc: func(err error) {
+				assert.True(t, errors.Contains(errors.Cast(err), service.ErrAPIKeysDisabled), fmt.Sprintf("err: expected service.ErrAPIKeysDisabled got %v", err))
+				assert.True(t, errors.Contains(errors.Cast(err), authz.ErrForbidden), fmt.Sprintf("err: expected authz.ErrForbidden got %v", err))
+			},
+		},
+		{
+			name: "list API keys without an authorization policy",
+			opts: []service.Option{service.WithAuthorizer(nil)},
+			run: func(svc service.TodoService) error {
+				_, err := svc.ListAPIKeys(adminCtx)
+				return err
+			},
+			wantErr: true,
+			checkFunc: func(err error) {
+				assert.True(t, errors.Contains(errors.Cast(err), service.ErrAPIKeysDisabled), fmt.Sprintf("err: expected service.ErrAPIKeysDisabled got %v", err))
+				assert.True(t, errors.Contains(errors.Cast(err), authz.ErrForbidden), fmt.Sprintf("err: expected authz.ErrForbidden got %v", err))
+			},
+		},
+		{
+			name: "create API key already expired",
+			run: func(svc service.TodoService) error {
+				_, err := svc.CreateAPIKey(adminCtx, &model.APIKeyReq{Name: "ci", ExpiresAt: &now})
+				return err
+			},
+			wantErr: true,
+			checkFunc: func(err error) {
+				assert.True(t, errors.Contains(errors.Cast(err), service.ErrMalformedEntity), fmt.Sprintf("err: expected service.ErrMalformedEntity got %v", err))
+			},
+		},
+		{
+			name: "revoke API key of another tenant",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.repo.EXPECT().DeleteAPIKey(adminCtx, "acme", "dlyW8C0ypdYd0tiJ0KbcN").Return(service.ErrNotFound),
+				)
+			},
+			run: func(svc service.TodoService) error {
+				return svc.RevokeAPIKey(adminCtx, "dlyW8C0ypdYd0tiJ0KbcN")
+			},
+			wantErr: true,
+			checkFunc: func(err error) {
+				assert.True(t, errors.Contains(errors.Cast(err), service.ErrNotFound), fmt.Sprintf("err: expected service.ErrNotFound got %v", err))
+			},
+		},
+		{
+			name: "list API keys of the tenant",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.repo.EXPECT().ListAPIKeys(adminCtx, "acme").Return(nil, nil),
+				)
+			},
+			run: func(svc service.TodoService) error {
+				res, err := svc.ListAPIKeys(adminCtx)
+				assert.NotNil(t, res, "keys: expected not nil")
+				return err
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			f := fields{
+				repo: automocks.NewMockTodoRepository(ctrl),
+			}
+			if tt.prepare != nil {
+				tt.prepare(&f)
+			}
+
+			opts := append([]service.Option{service.WithClock(func() time.Time { return now }), service.WithAuthorizer(policy)}, tt.opts...)
+			svc := service.New(f.repo, log.NewLogfmtLogger(os.Stderr), opts...)
+			if err := tt.run(svc); (err != nil) != tt.wantErr {
+				t.Errorf("svc error = %v, wantErr %v", err, tt.wantErr)
+			} else {
+				if tt.checkFunc != nil {
+					tt.checkFunc(err)
+				}
+			}
+		})
+	}
+}
+
+func TestStubTodoService_Quotas(t *testing.T) {
+	type fields struct {
+		repo *automocks.MockTodoRepository
+	}
+
+	var (
+		ctx       = authn.NewContext(context.Background(), &authn.Claims{StandardClaims: jwt.StandardClaims{Subject: "alice"}, Tenant: "acme"})
+		globexCtx = authn.NewContext(context.Background(), &authn.Claims{StandardClaims: jwt.StandardClaims{Subject: "alice"}, Tenant: "globex"})
+		owner     = model.Owner{ID: "alice", TenantID: "acme"}
+		text      = "aa"
+		long      = "aaaaaa"
+		quotas    = &service.Quotas{
+			Default: model.Quota{Todos: 1},
+			Tenants: map[string]model.Quota{"acme": {Todos: 2, TenantTodos: 3, TextLength: 5}, "globex": {TextLength: 5}},
+		}
+	)
+
+	tests := []struct {
+		name      string
+		prepare   func(f *fields)
+		run       func(svc service.TodoService) error
+		wantErr   bool
+		checkFunc func(err error)
+	}{
+		{
+			name: "add todo within the quota",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.repo.EXPECT().AddWithin(ctx, gomock.Any(), quotas.Tenants["acme"]).Return(nil),
+				)
+			},
+			run: func(svc service.TodoService) error {
+				_, err := svc.Add(ctx, &model.TodoReq{Text: &text})
+				return err
+			},
+		},
+		{
+			name: "add todo past the quota",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.repo.EXPECT().AddWithin(ctx, gomock.Any(), quotas.Tenants["acme"]).Return(service.CheckUsage(quotas.Tenants["acme"], &model.Usage{Todos: 2, TenantTodos: 2})),
+				)
+			},
+			run: func(svc service.TodoService) error {
+				_, err := svc.Add(ctx, &model.TodoReq{Text: &text})
+				return err
+			},
+			wantErr: true,
+			checkFunc: func(err error) {
+				assert.True(t, errors.Contains(errors.Cast(err), service.ErrQuotaExceeded), fmt.Sprintf("err: expected service.ErrQuotaExceeded got %v", err))
+			},
+		},
+		{
+			name: "add todo without a quota",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.repo.EXPECT().Add(globexCtx, gomock.Any()).Return(nil),
+				)
+			},
+			run: func(svc service.TodoService) error {
+				_, err := svc.Add(globexCtx, &model.TodoReq{Text: &text})
+				return err
+			},
+		},
+		{
+			name: "add todo with a text too long",
+			run: func(svc service.TodoService) error {
+				_, err := svc.Add(ctx, &model.TodoReq{Text: &long})
+				return err
+			},
+			wantErr: true,
+			checkFunc: func(err error) {
+				assert.True(t, errors.Contains(errors.Cast(err), service.ErrQuotaExceeded), fmt.Sprintf("err: expected service.ErrQuotaExceeded got %v", err))
+			},
+		},
+		{
+			name: "update todo with a text too long",
+			run: func(svc service.TodoService) error {
+				_, err := svc.Update(ctx, "iKe0KxpurIn0E_6vzUDAr", &model.TodoReq{Text: &long})
+				return err
+			},
+			wantErr: true,
+			checkFunc: func(err error) {
+				assert.True(t, errors.Contains(errors.Cast(err), service.ErrQuotaExceeded), fmt.Sprintf("err: expected service.ErrQuotaExceeded got %v", err))
+			},
+		},
+		{
+			name: "push creation past the quota",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.repo.EXPECT().Get(ctx, owner, "iKe0KxpurIn0E_6vzUDAr").Return(nil, service.ErrNotFound),
+					f.repo.EXPECT().AddWithin(ctx, gomock.Any(), quotas.Tenants["acme"]).Return(service.CheckUsage(quotas.Tenants["acme"], &model.Usage{Todos: 2, TenantTodos: 2})),
+				)
+			},
+			run: func(svc service.TodoService) error {
+				res, err := svc.Push(ctx, []*model.Mutation{{Op: model.MutationCreate, ID: "iKe0KxpurIn0E_6vzUDAr", Todo: &model.TodoReq{Text: &text}}})
+				if err == nil {
+					assert.Equal(t, model.MutationRejected, res[0].Status, fmt.Sprintf("status: expected %s got %v", model.MutationRejected, res[0].Status))
+				}
+				return err
+			},
+		},
+		{
+			name: "usage of the caller",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.repo.EXPECT().Usage(ctx, owner).Return(&model.Usage{Todos: 1, TenantTodos: 2}, nil),
+				)
+			},
+			run: func(svc service.TodoService) error {
+				res, err := svc.Usage(ctx)
+				if err == nil {
+					assert.Equal(t, quotas.Tenants["acme"], res.Quota, fmt.Sprintf("quota: expected %v got %v", quotas.Tenants["acme"], res.Quota))
+				}
+				return err
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			f := fields{
+				repo: automocks.NewMockTodoRepository(ctrl),
+			}
+			if tt.prepare != nil {
+				tt.prepare(&f)
+			}
+
+			svc := service.New(f.repo, log.NewLogfmtLogger(os.Stderr), service.WithQuotas(quotas))
+			if err := tt.run(svc); (err != nil) != tt.wantErr {
+				t.Errorf("svc error = %v, wantErr %v", err, tt.wantErr)
+			} else {
+				if tt.checkFunc != nil {
+					tt.checkFunc(err)
+				}
+			}
+		})
+	}
+}
+
+func TestCheckUsage(t *testing.T) {
+	quota := model.Quota{Todos: 2, TenantTodos: 3}
+
+	tests := []struct {
+		name    string
+		quota   model.Quota
+		usage   *model.Usage
+		wantErr bool
+	}{
+		{name: "within the quota", quota: quota, usage: &model.Usage{Todos: 1, TenantTodos: 2}},
+		{name: "at the quota of the caller", quota: quota, usage: &model.Usage{Todos: 2, TenantTodos: 2}, wantErr: true},
+		{name: "at the quota of the tenant", quota: quota, usage: &model.Usage{Todos: 0, TenantTodos: 3}, wantErr: true},
+		{name: "unlimited", usage: &model.Usage{Todos: 10, TenantTodos: 10}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := service.CheckUsage(tt.quota, tt.usage)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("CheckUsage() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				assert.True(t, errors.Contains(errors.Cast(err), service.ErrQuotaExceeded), fmt.Sprintf("err: expected service.ErrQuotaExceeded got %v", err))
+			}
+		})
+	}
+}
+
+func TestAPIKeyAuthenticator_Authenticate(t *testing.T) {
+	type fields struct {
+		repo *automocks.MockTodoRepository
+	}
+
+	var (
+		ctx     = context.Background()
+		secret  = "todo_c2VjcmV0"
+		hash    = authn.HashAPIKey(secret)
+		past    = time.Now().Add(-time.Hour)
+		future  = time.Now().Add(time.Hour)
+		recent  = time.Now().Add(-time.Second)
+		mAPIKey = func() *model.APIKey {
+			return &model.APIKey{ID: "dlyW8C0ypdYd0tiJ0KbcN", TenantID: "acme", Subject: "ci", Hash: hash, Scopes: []string{"editor"}}
+		}
+	)
+
+	tests := []struct {
+		name      string
+		prepare   func(f *fields)
+		wantErr   bool
+		checkFunc func(claims *authn.Claims, err error)
+	}{
+		{
+			name: "authenticate API key",
+			prepare: func(f *fields) {
+				key := mAPIKey()
+				key.ExpiresAt = &future
+				gomock.InOrder(
+					f.repo.EXPECT().APIKey(ctx, hash).Return(key, nil),
+					f.repo.EXPECT().TouchAPIKey(ctx, key.ID, gomock.Any()).Return(nil),
+				)
+			},
+			checkFunc: func(claims *authn.Claims, err error) {
+				assert.Equal(t, "ci", claims.Subject, fmt.Sprintf("subject: expected ci got %v", claims.Subject))
+				assert.Equal(t, "acme", claims.Tenant, fmt.Sprintf("tenant: expected acme got %v", claims.Tenant))
+				assert.Equal(t, []string{"editor"}, claims.Roles, fmt.Sprintf("roles: expected [editor] got %v", claims.Roles))
+				assert.True(t, claims.APIKey, "apiKey: expected the claims of an API key")
+			},
+		},
+		{
+			name: "authenticate API key used recently",
+			prepare: func(f *fields) {
+				key := mAPIKey()
+				key.LastUsedAt = &recent
+				gomock.InOrder(
+					f.repo.EXPECT().APIKey(ctx, hash).Return(key, nil),
+				)
+			},
+		},
+		{
+			name: "authenticate API key failing to record its use",
+			prepare: func(f *fields) {
+				key := mAPIKey()
+				gomock.InOrder(
+					f.repo.EXPECT().APIKey(ctx, hash).Return(key, nil),
+					f.repo.EXPECT().TouchAPIKey(ctx, key.ID, gomock.Any()).Return(fmt.Errorf("connection refused")),
+				)
+			},
+		},
+		{
+			name: "authenticate expired API key",
+			prepare: func(f *fields) {
+				key := mAPIKey()
+				key.ExpiresAt = &past
+				gomock.InOrder(
+					f.repo.EXPECT().APIKey(ctx, hash).Return(key, nil),
+				)
+			},
+			wantErr: true,
+			checkFunc: func(claims *authn.Claims, err error) {
+				assert.True(t, errors.Contains(errors.Cast(err), service.ErrAPIKeyExpired), fmt.Sprintf("err: expected service.ErrAPIKeyExpired got %v", err))
+			},
+		},
+		{
+			name: "authenticate revoked API key",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.repo.EXPECT().APIKey(ctx, hash).Return(nil, service.ErrNotFound),
+				)
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			f := fields{
+				repo: automocks.NewMockTodoRepository(ctrl),
+			}
+			if tt.prepare != nil {
+				tt.prepare(&f)
+			}
+
+			a := service.NewAPIKeyAuthenticator(f.repo, log.NewLogfmtLogger(os.Stderr))
+			if claims, err := a.Authenticate(ctx, secret); (err != nil) != tt.wantErr {
+				t.Errorf("Authenticate(ctx context.Context, key string) error = %v, wantErr %v", err, tt.wantErr)
+			} else {
+				if tt.checkFunc != nil {
+					tt.checkFunc(claims, err)
+				}
+			}
+		})
+	}
+}
+
+func TestInstrumentingMiddleware_Delete(t *testing.T) {
+	type fields struct {
+		repo *automocks.MockTodoRepository
+	}
+
+	type args struct {
+		id string
+	}
+
+	tests := []struct {
+		name      string
+		prepare   func(f *fields)
+		args      args
+		wantErr   bool
+		checkFunc func(count *stdprometheus.CounterVec)
+	}{
+		{
+			name: "delete todo",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.repo.EXPECT().Delete(context.Background(), model.Owner{}, "b5z2zC5c9O6~Ns_qLVmn~", uint64(0)).Return(nil),
+				)
+			},
+			args:    args{"b5z2zC5c9O6~Ns_qLVmn~"},
+			wantErr: false,
+			checkFunc: func(count *stdprometheus.CounterVec) {
+				assert.Equal(t, 1.0, testutil.ToFloat64(count.WithLabelValues("Delete", "false")), "count: expected 1 call without error")
+				assert.Equal(t, 0.0, testutil.ToFloat64(count.WithLabelValues("Delete", "true")), "count: expected no call with an error")
+			},
+		},
+		{
+			name: "delete todo fail",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.repo.EXPECT().Delete(context.Background(), model.Owner{}, "b5z2zC5c9O6~Ns_qLVmn~", uint64(0)).Return(sql.ErrNoRows),
+				)
+			},
+			args:    args{"b5z2zC5c9O6~Ns_qLVmn~"},
+			wantErr: true,
+			checkFunc: func(count *stdprometheus.CounterVec) {
+				assert.Equal(t, 1.0, testutil.ToFloat64(count.WithLabelValues("Delete", "true")), "count: expected 1 call with an error")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			f := fields{
+				repo: automocks.NewMockTodoRepository(ctrl),
+			}
+			if tt.prepare != nil {
+				tt.prepare(&f)
+			}
+
+			count := stdprometheus.NewCounterVec(stdprometheus.CounterOpts{Name: "requests_total"}, []string{"method", "error"})
+			latency := stdprometheus.NewHistogramVec(stdprometheus.HistogramOpts{Name: "request_duration_seconds"}, []string{"method", "error"})
+			svc := service.New(f.repo, log.NewLogfmtLogger(os.Stderr), service.WithInstrumenting(kitprometheus.NewCounter(count), kitprometheus.NewHistogram(latency)))
+			if err := svc.Delete(context.Background(), tt.args.id); (err != nil) != tt.wantErr {
+				t.Errorf("svc.Delete error = %v, wantErr %v", err, tt.wantErr)
+			} else {
+				if tt.checkFunc != nil {
+					tt.checkFunc(count)
+				}
+			}
+		})
+	}
+}
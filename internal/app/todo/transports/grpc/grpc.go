@@ -2,6 +2,9 @@ package transports
 
 import (
 	"context"
+	"crypto/tls"
+	"io"
+	"time"
 
 	kitjwt "github.com/go-kit/kit/auth/jwt"
 	"github.com/go-kit/kit/endpoint"
@@ -11,22 +14,48 @@ import (
 	grpctransport "github.com/go-kit/kit/transport/grpc"
 	stdopentracing "github.com/opentracing/opentracing-go"
 	stdzipkin "github.com/openzipkin/zipkin-go"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
 
 	"github.com/cage1016/gokit-todo/internal/app/todo/endpoints"
 	"github.com/cage1016/gokit-todo/internal/app/todo/model"
 	"github.com/cage1016/gokit-todo/internal/app/todo/service"
+	"github.com/cage1016/gokit-todo/internal/pkg/authn"
+	"github.com/cage1016/gokit-todo/internal/pkg/authz"
 	"github.com/cage1016/gokit-todo/internal/pkg/errors"
+	"github.com/cage1016/gokit-todo/internal/pkg/instrumenting"
+	"github.com/cage1016/gokit-todo/internal/pkg/ratelimit"
 	pb "github.com/cage1016/gokit-todo/pb/todo"
 )
 
+// watchStartedHeader is sent by the server once a Watch stream has started.
+const watchStartedHeader = "x-watch-started"
+
 type grpcServer struct {
-	add    grpctransport.Handler `json:""`
-	delete grpctransport.Handler `json:""`
-	update grpctransport.Handler `json:""`
-	list   grpctransport.Handler `json:""`
+	add          grpctransport.Handler `json:""`
+	delete       grpctransport.Handler `json:""`
+	update       grpctransport.Handler `json:""`
+	list         grpctransport.Handler `json:""`
+	get          grpctransport.Handler `json:""`
+	stats        grpctransport.Handler `json:""`
+	unarchive    grpctransport.Handler `json:""`
+	snooze       grpctransport.Handler `json:""`
+	watch        grpctransport.Handler `json:""`
+	sync         grpctransport.Handler `json:""`
+	push         grpctransport.Handler `json:""`
+	addShare     grpctransport.Handler `json:""`
+	deleteShare  grpctransport.Handler `json:""`
+	listShares   grpctransport.Handler `json:""`
+	createAPIKey grpctransport.Handler `json:""`
+	revokeAPIKey grpctransport.Handler `json:""`
+	listAPIKeys  grpctransport.Handler `json:""`
+	usage        grpctransport.Handler `json:""`
+	streams      context.Context
 }
 
 func (s *grpcServer) Add(ctx context.Context, req *pb.AddRequest) (rep *pb.AddResponse, err error) {
@@ -65,8 +94,177 @@ func (s *grpcServer) List(ctx context.Context, req *pb.ListRequest) (rep *pb.Lis
 	return rep, nil
 }
 
+func (s *grpcServer) Get(ctx context.Context, req *pb.GetRequest) (rep *pb.GetResponse, err error) {
+	_, rp, err := s.get.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, grpcEncodeError(errors.Cast(err))
+	}
+	rep = rp.(*pb.GetResponse)
+	return rep, nil
+}
+
+func (s *grpcServer) Stats(ctx context.Context, req *pb.StatsRequest) (rep *pb.StatsResponse, err error) {
+	_, rp, err := s.stats.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, grpcEncodeError(errors.Cast(err))
+	}
+	rep = rp.(*pb.StatsResponse)
+	return rep, nil
+}
+
+func (s *grpcServer) Unarchive(ctx context.Context, req *pb.UnarchiveRequest) (rep *pb.UnarchiveResponse, err error) {
+	_, rp, err := s.unarchive.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, grpcEncodeError(errors.Cast(err))
+	}
+	rep = rp.(*pb.UnarchiveResponse)
+	return rep, nil
+}
+
+func (s *grpcServer) Snooze(ctx context.Context, req *pb.SnoozeRequest) (rep *pb.SnoozeResponse, err error) {
+	_, rp, err := s.snooze.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, grpcEncodeError(errors.Cast(err))
+	}
+	rep = rp.(*pb.SnoozeResponse)
+	return rep, nil
+}
+
+func (s *grpcServer) Sync(ctx context.Context, req *pb.SyncRequest) (rep *pb.SyncResponse, err error) {
+	_, rp, err := s.sync.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, grpcEncodeError(errors.Cast(err))
+	}
+	rep = rp.(*pb.SyncResponse)
+	return rep, nil
+}
+
+func (s *grpcServer) Push(ctx context.Context, req *pb.PushRequest) (rep *pb.PushResponse, err error) {
+	_, rp, err := s.push.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, grpcEncodeError(errors.Cast(err))
+	}
+	rep = rp.(*pb.PushResponse)
+	return rep, nil
+}
+
+func (s *grpcServer) AddShare(ctx context.Context, req *pb.AddShareRequest) (rep *pb.AddShareResponse, err error) {
+	_, rp, err := s.addShare.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, grpcEncodeError(errors.Cast(err))
+	}
+	rep = rp.(*pb.AddShareResponse)
+	return rep, nil
+}
+
+func (s *grpcServer) DeleteShare(ctx context.Context, req *pb.DeleteShareRequest) (rep *pb.DeleteShareResponse, err error) {
+	_, rp, err := s.deleteShare.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, grpcEncodeError(errors.Cast(err))
+	}
+	rep = rp.(*pb.DeleteShareResponse)
+	return rep, nil
+}
+
+func (s *grpcServer) ListShares(ctx context.Context, req *pb.ListSharesRequest) (rep *pb.ListSharesResponse, err error) {
+	_, rp, err := s.listShares.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, grpcEncodeError(errors.Cast(err))
+	}
+	rep = rp.(*pb.ListSharesResponse)
+	return rep, nil
+}
+
+func (s *grpcServer) CreateAPIKey(ctx context.Context, req *pb.CreateAPIKeyRequest) (rep *pb.CreateAPIKeyResponse, err error) {
+	_, rp, err := s.createAPIKey.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, grpcEncodeError(errors.Cast(err))
+	}
+	rep = rp.(*pb.CreateAPIKeyResponse)
+	return rep, nil
+}
+
+func (s *grpcServer) RevokeAPIKey(ctx context.Context, req *pb.RevokeAPIKeyRequest) (rep *pb.RevokeAPIKeyResponse, err error) {
+	_, rp, err := s.revokeAPIKey.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, grpcEncodeError(errors.Cast(err))
+	}
+	rep = rp.(*pb.RevokeAPIKeyResponse)
+	return rep, nil
+}
+
+func (s *grpcServer) ListAPIKeys(ctx context.Context, req *pb.ListAPIKeysRequest) (rep *pb.ListAPIKeysResponse, err error) {
+	_, rp, err := s.listAPIKeys.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, grpcEncodeError(errors.Cast(err))
+	}
+	rep = rp.(*pb.ListAPIKeysResponse)
+	return rep, nil
+}
+
+func (s *grpcServer) Usage(ctx context.Context, req *pb.UsageRequest) (rep *pb.UsageResponse, err error) {
+	_, rp, err := s.usage.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, grpcEncodeError(errors.Cast(err))
+	}
+	rep = rp.(*pb.UsageResponse)
+	return rep, nil
+}
+
+func (s *grpcServer) Watch(req *pb.WatchRequest, stream pb.Todo_WatchServer) error {
+	ctx := stream.Context()
+	_, rp, err := s.watch.ServeGRPC(ctx, req)
+	if err != nil {
+		return grpcEncodeError(errors.Cast(err))
+	}
+	events := rp.(<-chan *model.TodoEvent)
+
+	// tell the client the watch has started before the first event
+	if err := stream.SendHeader(metadata.Pairs(watchStartedHeader, "true")); err != nil {
+		return err
+	}
+	var streams <-chan struct{}
+	if s.streams != nil {
+		streams = s.streams.Done()
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-streams:
+			// the client reconnects to another server and resumes from its
+			// last event
+			return status.Error(codes.Unavailable, "server shutting down, resume from the last received sequence")
+		case ev, ok := <-events:
+			if !ok {
+				return status.Error(codes.Aborted, "watch interrupted, resume from the last received sequence")
+			}
+			if err := stream.Send(ModelEventToPB(ev)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// serverOptions are the optional settings of MakeGRPCServer.
+type serverOptions struct {
+	streams context.Context
+}
+
+// Option sets an optional setting of MakeGRPCServer.
+type Option func(*serverOptions)
+
+// WithStreamContext makes the Watch streams end once ctx is done, such as
+// when the server shuts down, GracefulStop waits for them otherwise. The
+// other calls are left to complete.
+func WithStreamContext(ctx context.Context) Option {
+	return func(o *serverOptions) {
+		o.streams = ctx
+	}
+}
+
 // MakeGRPCServer makes a set of endpoints available as a gRPC server.
-func MakeGRPCServer(endpoints endpoints.Endpoints, otTracer stdopentracing.Tracer, zipkinTracer *stdzipkin.Tracer, logger log.Logger) (req pb.TodoServer) { // Zipkin GRPC Server Trace can either be instantiated per gRPC method with a
+func MakeGRPCServer(endpoints endpoints.Endpoints, otTracer stdopentracing.Tracer, zipkinTracer *stdzipkin.Tracer, logger log.Logger, opts ...Option) (req pb.TodoServer) { // Zipkin GRPC Server Trace can either be instantiated per gRPC method with a
 	// provided operation name or a global tracing service can be instantiated
 	// without an operation name and fed to each Go kit gRPC server as a
 	// ServerOption.
@@ -79,10 +277,17 @@ func MakeGRPCServer(endpoints endpoints.Endpoints, otTracer stdopentracing.Trace
 
 	options := []grpctransport.ServerOption{
 		grpctransport.ServerErrorLogger(logger),
+		grpctransport.ServerBefore(authn.GRPCAPIKeyToContext(), ratelimit.GRPCClientToContext(), instrumenting.GRPCToContext()),
 		zipkinServer,
 	}
 
+	var o serverOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
 	return &grpcServer{
+		streams: o.streams,
 		add: grpctransport.NewServer(
 			endpoints.AddEndpoint,
 			decodeGRPCAddRequest,
@@ -110,6 +315,104 @@ func MakeGRPCServer(endpoints endpoints.Endpoints, otTracer stdopentracing.Trace
 			encodeGRPCListResponse,
 			append(options, grpctransport.ServerBefore(opentracing.GRPCToContext(otTracer, "List", logger), kitjwt.GRPCToContext()))...,
 		),
+
+		get: grpctransport.NewServer(
+			endpoints.GetEndpoint,
+			decodeGRPCGetRequest,
+			encodeGRPCGetResponse,
+			append(options, grpctransport.ServerBefore(opentracing.GRPCToContext(otTracer, "Get", logger), kitjwt.GRPCToContext()))...,
+		),
+
+		stats: grpctransport.NewServer(
+			endpoints.StatsEndpoint,
+			decodeGRPCStatsRequest,
+			encodeGRPCStatsResponse,
+			append(options, grpctransport.ServerBefore(opentracing.GRPCToContext(otTracer, "Stats", logger), kitjwt.GRPCToContext()))...,
+		),
+
+		unarchive: grpctransport.NewServer(
+			endpoints.UnarchiveEndpoint,
+			decodeGRPCUnarchiveRequest,
+			encodeGRPCUnarchiveResponse,
+			append(options, grpctransport.ServerBefore(opentracing.GRPCToContext(otTracer, "Unarchive", logger), kitjwt.GRPCToContext()))...,
+		),
+
+		snooze: grpctransport.NewServer(
+			endpoints.SnoozeEndpoint,
+			decodeGRPCSnoozeRequest,
+			encodeGRPCSnoozeResponse,
+			append(options, grpctransport.ServerBefore(opentracing.GRPCToContext(otTracer, "Snooze", logger), kitjwt.GRPCToContext()))...,
+		),
+
+		watch: grpctransport.NewServer(
+			endpoints.WatchEndpoint,
+			decodeGRPCWatchRequest,
+			encodeGRPCWatchResponse,
+			append(options, grpctransport.ServerBefore(opentracing.GRPCToContext(otTracer, "Watch", logger), kitjwt.GRPCToContext()))...,
+		),
+
+		sync: grpctransport.NewServer(
+			endpoints.SyncEndpoint,
+			decodeGRPCSyncRequest,
+			encodeGRPCSyncResponse,
+			append(options, grpctransport.ServerBefore(opentracing.GRPCToContext(otTracer, "Sync", logger), kitjwt.GRPCToContext()))...,
+		),
+
+		push: grpctransport.NewServer(
+			endpoints.PushEndpoint,
+			decodeGRPCPushRequest,
+			encodeGRPCPushResponse,
+			append(options, grpctransport.ServerBefore(opentracing.GRPCToContext(otTracer, "Push", logger), kitjwt.GRPCToContext()))...,
+		),
+
+		addShare: grpctransport.NewServer(
+			endpoints.AddShareEndpoint,
+			decodeGRPCAddShareRequest,
+			encodeGRPCAddShareResponse,
+			append(options, grpctransport.ServerBefore(opentracing.GRPCToContext(otTracer, "AddShare", logger), kitjwt.GRPCToContext()))...,
+		),
+
+		deleteShare: grpctransport.NewServer(
+			endpoints.DeleteShareEndpoint,
+			decodeGRPCDeleteShareRequest,
+			encodeGRPCDeleteShareResponse,
+			append(options, grpctransport.ServerBefore(opentracing.GRPCToContext(otTracer, "DeleteShare", logger), kitjwt.GRPCToContext()))...,
+		),
+
+		listShares: grpctransport.NewServer(
+			endpoints.ListSharesEndpoint,
+			decodeGRPCListSharesRequest,
+			encodeGRPCListSharesResponse,
+			append(options, grpctransport.ServerBefore(opentracing.GRPCToContext(otTracer, "ListShares", logger), kitjwt.GRPCToContext()))...,
+		),
+
+		createAPIKey: grpctransport.NewServer(
+			endpoints.CreateAPIKeyEndpoint,
+			decodeGRPCCreateAPIKeyRequest,
+			encodeGRPCCreateAPIKeyResponse,
+			append(options, grpctransport.ServerBefore(opentracing.GRPCToContext(otTracer, "CreateAPIKey", logger), kitjwt.GRPCToContext()))...,
+		),
+
+		revokeAPIKey: grpctransport.NewServer(
+			endpoints.RevokeAPIKeyEndpoint,
+			decodeGRPCRevokeAPIKeyRequest,
+			encodeGRPCRevokeAPIKeyResponse,
+			append(options, grpctransport.ServerBefore(opentracing.GRPCToContext(otTracer, "RevokeAPIKey", logger), kitjwt.GRPCToContext()))...,
+		),
+
+		listAPIKeys: grpctransport.NewServer(
+			endpoints.ListAPIKeysEndpoint,
+			decodeGRPCListAPIKeysRequest,
+			encodeGRPCListAPIKeysResponse,
+			append(options, grpctransport.ServerBefore(opentracing.GRPCToContext(otTracer, "ListAPIKeys", logger), kitjwt.GRPCToContext()))...,
+		),
+
+		usage: grpctransport.NewServer(
+			endpoints.UsageEndpoint,
+			decodeGRPCUsageRequest,
+			encodeGRPCUsageResponse,
+			append(options, grpctransport.ServerBefore(opentracing.GRPCToContext(otTracer, "Usage", logger), kitjwt.GRPCToContext()))...,
+		),
 	}
 }
 
@@ -158,8 +461,27 @@ func encodeGRPCUpdateResponse(_ context.Context, grpcReply interface{}) (res int
 // decodeGRPCListRequest is a transport/grpc.DecodeRequestFunc that converts a
 // gRPC request to a user-domain request. Primarily useful in a server.
 func decodeGRPCListRequest(_ context.Context, grpcReq interface{}) (interface{}, error) {
-	_ = grpcReq.(*pb.ListRequest)
-	return endpoints.ListRequest{}, nil
+	req := grpcReq.(*pb.ListRequest)
+	completedAfter, err := parseTime(req.CompletedAfter)
+	if err != nil {
+		return nil, errors.Wrap(service.ErrInvalidQueryParams, err)
+	}
+	completedBefore, err := parseTime(req.CompletedBefore)
+	if err != nil {
+		return nil, errors.Wrap(service.ErrInvalidQueryParams, err)
+	}
+	return endpoints.ListRequest{Query: &model.TodoQuery{
+		Fields:          PBtoModelFields(req.FieldMask),
+		CompletedAfter:  completedAfter,
+		CompletedBefore: completedBefore,
+		Sort:            req.Sort,
+		IncludeArchived: req.IncludeArchived,
+		IncludeSnoozed:  req.IncludeSnoozed,
+		Status:          req.Status,
+		Offset:          int(req.Offset),
+		Limit:           int(req.Limit),
+		Shared:          req.Shared,
+	}}, nil
 }
 
 // encodeGRPCListResponse is a transport/grpc.EncodeResponseFunc that converts a
@@ -172,12 +494,276 @@ func encodeGRPCListResponse(_ context.Context, grpcReply interface{}) (res inter
 
 	todos := []*pb.ModelTodoRes{}
 	for _, todo := range reply.Res {
-		todos = append(todos, ModelResToPB(todo))
+		todos = append(todos, ModelResToMaskedPB(todo, reply.Fields))
 	}
 
 	return &pb.ListResponse{Res: todos}, grpcEncodeError(errors.Cast(reply.Err))
 }
 
+// decodeGRPCGetRequest is a transport/grpc.DecodeRequestFunc that converts a
+// gRPC request to a user-domain request. Primarily useful in a server.
+func decodeGRPCGetRequest(_ context.Context, grpcReq interface{}) (interface{}, error) {
+	req := grpcReq.(*pb.GetRequest)
+	return endpoints.GetRequest{Id: req.Id, Fields: PBtoModelFields(req.FieldMask)}, nil
+}
+
+// encodeGRPCGetResponse is a transport/grpc.EncodeResponseFunc that converts a
+// user-domain response to a gRPC reply. Primarily useful in a server.
+func encodeGRPCGetResponse(_ context.Context, grpcReply interface{}) (res interface{}, err error) {
+	reply := grpcReply.(endpoints.GetResponse)
+	if reply.Err != nil {
+		return &pb.GetResponse{}, grpcEncodeError(errors.Cast(reply.Err))
+	}
+	return &pb.GetResponse{Res: ModelResToMaskedPB(reply.Res, reply.Fields)}, nil
+}
+
+// decodeGRPCStatsRequest is a transport/grpc.DecodeRequestFunc that converts a
+// gRPC request to a user-domain request. Primarily useful in a server.
+func decodeGRPCStatsRequest(_ context.Context, grpcReq interface{}) (interface{}, error) {
+	req := grpcReq.(*pb.StatsRequest)
+	return endpoints.StatsRequest{Days: int(req.Days)}, nil
+}
+
+// encodeGRPCStatsResponse is a transport/grpc.EncodeResponseFunc that converts a
+// user-domain response to a gRPC reply. Primarily useful in a server.
+func encodeGRPCStatsResponse(_ context.Context, grpcReply interface{}) (res interface{}, err error) {
+	reply := grpcReply.(endpoints.StatsResponse)
+	return &pb.StatsResponse{Res: ModelStatsToPB(reply.Res)}, grpcEncodeError(errors.Cast(reply.Err))
+}
+
+// decodeGRPCUnarchiveRequest is a transport/grpc.DecodeRequestFunc that converts a
+// gRPC request to a user-domain request. Primarily useful in a server.
+func decodeGRPCUnarchiveRequest(_ context.Context, grpcReq interface{}) (interface{}, error) {
+	req := grpcReq.(*pb.UnarchiveRequest)
+	return endpoints.UnarchiveRequest{Id: req.Id}, nil
+}
+
+// encodeGRPCUnarchiveResponse is a transport/grpc.EncodeResponseFunc that converts a
+// user-domain response to a gRPC reply. Primarily useful in a server.
+func encodeGRPCUnarchiveResponse(_ context.Context, grpcReply interface{}) (res interface{}, err error) {
+	reply := grpcReply.(endpoints.UnarchiveResponse)
+	if reply.Err != nil {
+		return &pb.UnarchiveResponse{}, grpcEncodeError(errors.Cast(reply.Err))
+	}
+	return &pb.UnarchiveResponse{Res: ModelResToPB(reply.Res)}, nil
+}
+
+// decodeGRPCSnoozeRequest is a transport/grpc.DecodeRequestFunc that converts a
+// gRPC request to a user-domain request. Primarily useful in a server.
+func decodeGRPCSnoozeRequest(_ context.Context, grpcReq interface{}) (interface{}, error) {
+	req := grpcReq.(*pb.SnoozeRequest)
+	until, err := parseTime(req.Until)
+	if err != nil {
+		return nil, errors.Wrap(service.ErrMalformedEntity, err)
+	}
+	if until == nil {
+		return endpoints.SnoozeRequest{Id: req.Id}, nil
+	}
+	return endpoints.SnoozeRequest{Id: req.Id, Until: *until}, nil
+}
+
+// encodeGRPCSnoozeResponse is a transport/grpc.EncodeResponseFunc that converts a
+// user-domain response to a gRPC reply. Primarily useful in a server.
+func encodeGRPCSnoozeResponse(_ context.Context, grpcReply interface{}) (res interface{}, err error) {
+	reply := grpcReply.(endpoints.SnoozeResponse)
+	if reply.Err != nil {
+		return &pb.SnoozeResponse{}, grpcEncodeError(errors.Cast(reply.Err))
+	}
+	return &pb.SnoozeResponse{Res: ModelResToPB(reply.Res)}, nil
+}
+
+// decodeGRPCWatchRequest is a transport/grpc.DecodeRequestFunc that converts a
+// gRPC request to a user-domain request. Primarily useful in a server.
+func decodeGRPCWatchRequest(_ context.Context, grpcReq interface{}) (interface{}, error) {
+	req := grpcReq.(*pb.WatchRequest)
+	return endpoints.WatchRequest{Query: &model.EventQuery{Types: req.Types, Since: req.Since}}, nil
+}
+
+// encodeGRPCWatchResponse is a transport/grpc.EncodeResponseFunc that returns
+// the events of a user-domain response, they are streamed one by one by
+// grpcServer.Watch. Primarily useful in a server.
+func encodeGRPCWatchResponse(_ context.Context, grpcReply interface{}) (res interface{}, err error) {
+	reply := grpcReply.(endpoints.WatchResponse)
+	if reply.Err != nil {
+		return nil, grpcEncodeError(errors.Cast(reply.Err))
+	}
+	return reply.Events, nil
+}
+
+// decodeGRPCSyncRequest is a transport/grpc.DecodeRequestFunc that converts a
+// gRPC request to a user-domain request. Primarily useful in a server.
+func decodeGRPCSyncRequest(_ context.Context, grpcReq interface{}) (interface{}, error) {
+	req := grpcReq.(*pb.SyncRequest)
+	return endpoints.SyncRequest{Token: req.Since}, nil
+}
+
+// encodeGRPCSyncResponse is a transport/grpc.EncodeResponseFunc that converts a
+// user-domain response to a gRPC reply. Primarily useful in a server.
+func encodeGRPCSyncResponse(_ context.Context, grpcReply interface{}) (res interface{}, err error) {
+	reply := grpcReply.(endpoints.SyncResponse)
+	if reply.Err != nil {
+		return &pb.SyncResponse{}, grpcEncodeError(errors.Cast(reply.Err))
+	}
+	return &pb.SyncResponse{Res: ModelChangesToPB(reply.Res)}, nil
+}
+
+// decodeGRPCPushRequest is a transport/grpc.DecodeRequestFunc that converts a
+// gRPC request to a user-domain request. Primarily useful in a server.
+func decodeGRPCPushRequest(_ context.Context, grpcReq interface{}) (interface{}, error) {
+	req := grpcReq.(*pb.PushRequest)
+	mutations := make([]*model.Mutation, 0, len(req.Mutations))
+	for _, m := range req.Mutations {
+		mutation, err := PBtoModelMutation(m)
+		if err != nil {
+			return nil, errors.Wrap(service.ErrMalformedEntity, err)
+		}
+		mutations = append(mutations, mutation)
+	}
+	return endpoints.PushRequest{Mutations: mutations}, nil
+}
+
+// encodeGRPCPushResponse is a transport/grpc.EncodeResponseFunc that converts a
+// user-domain response to a gRPC reply. Primarily useful in a server.
+func encodeGRPCPushResponse(_ context.Context, grpcReply interface{}) (res interface{}, err error) {
+	reply := grpcReply.(endpoints.PushResponse)
+	if reply.Err != nil {
+		return &pb.PushResponse{}, grpcEncodeError(errors.Cast(reply.Err))
+	}
+	results := make([]*pb.ModelMutationResult, 0, len(reply.Res))
+	for _, r := range reply.Res {
+		results = append(results, ModelMutationResultToPB(r))
+	}
+	return &pb.PushResponse{Res: results}, nil
+}
+
+// decodeGRPCAddShareRequest is a transport/grpc.DecodeRequestFunc that converts a
+// gRPC request to a user-domain request. Primarily useful in a server.
+func decodeGRPCAddShareRequest(_ context.Context, grpcReq interface{}) (interface{}, error) {
+	req := grpcReq.(*pb.AddShareRequest)
+	return endpoints.AddShareRequest{Share: PBtoModelShareReq(req.Share)}, nil
+}
+
+// encodeGRPCAddShareResponse is a transport/grpc.EncodeResponseFunc that converts a
+// user-domain response to a gRPC reply. Primarily useful in a server.
+func encodeGRPCAddShareResponse(_ context.Context, grpcReply interface{}) (res interface{}, err error) {
+	reply := grpcReply.(endpoints.AddShareResponse)
+	return &pb.AddShareResponse{Res: ModelShareToPB(reply.Res)}, grpcEncodeError(errors.Cast(reply.Err))
+}
+
+// decodeGRPCDeleteShareRequest is a transport/grpc.DecodeRequestFunc that converts a
+// gRPC request to a user-domain request. Primarily useful in a server.
+func decodeGRPCDeleteShareRequest(_ context.Context, grpcReq interface{}) (interface{}, error) {
+	req := grpcReq.(*pb.DeleteShareRequest)
+	return endpoints.DeleteShareRequest{Id: req.Id}, nil
+}
+
+// encodeGRPCDeleteShareResponse is a transport/grpc.EncodeResponseFunc that converts a
+// user-domain response to a gRPC reply. Primarily useful in a server.
+func encodeGRPCDeleteShareResponse(_ context.Context, grpcReply interface{}) (res interface{}, err error) {
+	reply := grpcReply.(endpoints.DeleteShareResponse)
+	return &pb.DeleteShareResponse{}, grpcEncodeError(errors.Cast(reply.Err))
+}
+
+// decodeGRPCListSharesRequest is a transport/grpc.DecodeRequestFunc that converts a
+// gRPC request to a user-domain request. Primarily useful in a server.
+func decodeGRPCListSharesRequest(_ context.Context, grpcReq interface{}) (interface{}, error) {
+	_ = grpcReq.(*pb.ListSharesRequest)
+	return endpoints.ListSharesRequest{}, nil
+}
+
+// encodeGRPCListSharesResponse is a transport/grpc.EncodeResponseFunc that converts a
+// user-domain response to a gRPC reply. Primarily useful in a server.
+func encodeGRPCListSharesResponse(_ context.Context, grpcReply interface{}) (res interface{}, err error) {
+	reply := grpcReply.(endpoints.ListSharesResponse)
+	if reply.Err != nil {
+		return &pb.ListSharesResponse{}, grpcEncodeError(errors.Cast(reply.Err))
+	}
+	shares := make([]*pb.ModelShare, 0, len(reply.Res))
+	for _, share := range reply.Res {
+		shares = append(shares, ModelShareToPB(share))
+	}
+	return &pb.ListSharesResponse{Res: shares}, nil
+}
+
+// decodeGRPCCreateAPIKeyRequest is a transport/grpc.DecodeRequestFunc that converts a
+// gRPC request to a user-domain request. Primarily useful in a server.
+func decodeGRPCCreateAPIKeyRequest(_ context.Context, grpcReq interface{}) (interface{}, error) {
+	req := grpcReq.(*pb.CreateAPIKeyRequest)
+	key, err := PBtoModelAPIKeyReq(req.Key)
+	if err != nil {
+		return nil, errors.Wrap(service.ErrMalformedEntity, err)
+	}
+	return endpoints.CreateAPIKeyRequest{Key: key}, nil
+}
+
+// encodeGRPCCreateAPIKeyResponse is a transport/grpc.EncodeResponseFunc that converts a
+// user-domain response to a gRPC reply. Primarily useful in a server.
+func encodeGRPCCreateAPIKeyResponse(_ context.Context, grpcReply interface{}) (res interface{}, err error) {
+	reply := grpcReply.(endpoints.CreateAPIKeyResponse)
+	return &pb.CreateAPIKeyResponse{Res: ModelCreatedAPIKeyToPB(reply.Res)}, grpcEncodeError(errors.Cast(reply.Err))
+}
+
+// decodeGRPCRevokeAPIKeyRequest is a transport/grpc.DecodeRequestFunc that converts a
+// gRPC request to a user-domain request. Primarily useful in a server.
+func decodeGRPCRevokeAPIKeyRequest(_ context.Context, grpcReq interface{}) (interface{}, error) {
+	req := grpcReq.(*pb.RevokeAPIKeyRequest)
+	return endpoints.RevokeAPIKeyRequest{Id: req.Id}, nil
+}
+
+// encodeGRPCRevokeAPIKeyResponse is a transport/grpc.EncodeResponseFunc that converts a
+// user-domain response to a gRPC reply. Primarily useful in a server.
+func encodeGRPCRevokeAPIKeyResponse(_ context.Context, grpcReply interface{}) (res interface{}, err error) {
+	reply := grpcReply.(endpoints.RevokeAPIKeyResponse)
+	return &pb.RevokeAPIKeyResponse{}, grpcEncodeError(errors.Cast(reply.Err))
+}
+
+// decodeGRPCListAPIKeysRequest is a transport/grpc.DecodeRequestFunc that converts a
+// gRPC request to a user-domain request. Primarily useful in a server.
+func decodeGRPCListAPIKeysRequest(_ context.Context, grpcReq interface{}) (interface{}, error) {
+	_ = grpcReq.(*pb.ListAPIKeysRequest)
+	return endpoints.ListAPIKeysRequest{}, nil
+}
+
+// encodeGRPCListAPIKeysResponse is a transport/grpc.EncodeResponseFunc that converts a
+// user-domain response to a gRPC reply. Primarily useful in a server.
+func encodeGRPCListAPIKeysResponse(_ context.Context, grpcReply interface{}) (res interface{}, err error) {
+	reply := grpcReply.(endpoints.ListAPIKeysResponse)
+	if reply.Err != nil {
+		return &pb.ListAPIKeysResponse{}, grpcEncodeError(errors.Cast(reply.Err))
+	}
+	keys := make([]*pb.ModelAPIKey, 0, len(reply.Res))
+	for _, key := range reply.Res {
+		keys = append(keys, ModelAPIKeyToPB(key))
+	}
+	return &pb.ListAPIKeysResponse{Res: keys}, nil
+}
+
+// decodeGRPCUsageRequest is a transport/grpc.DecodeRequestFunc that converts a
+// gRPC request to a user-domain request. Primarily useful in a server.
+func decodeGRPCUsageRequest(_ context.Context, grpcReq interface{}) (interface{}, error) {
+	_ = grpcReq.(*pb.UsageRequest)
+	return endpoints.UsageRequest{}, nil
+}
+
+// encodeGRPCUsageResponse is a transport/grpc.EncodeResponseFunc that converts a
+// user-domain response to a gRPC reply. Primarily useful in a server.
+func encodeGRPCUsageResponse(_ context.Context, grpcReply interface{}) (res interface{}, err error) {
+	reply := grpcReply.(endpoints.UsageResponse)
+	if reply.Err != nil {
+		return &pb.UsageResponse{}, grpcEncodeError(errors.Cast(reply.Err))
+	}
+	return &pb.UsageResponse{Res: ModelUsageToPB(reply.Res)}, nil
+}
+
+// DialOption returns the option dialing the conns of NewGRPCClient over TLS
+// with tlsConfig, see tlsconfig.ClientConfig, or in plaintext when it is nil.
+func DialOption(tlsConfig *tls.Config) grpc.DialOption {
+	if tlsConfig == nil {
+		return grpc.WithInsecure()
+	}
+	return grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig))
+}
+
 // NewGRPCClient returns an AddService backed by a gRPC server at the other end
 // of the conn. The caller is responsible for constructing the conn, and
 // eventually closing the underlying transport. We bake-in certain middlewares,
@@ -260,11 +846,245 @@ func NewGRPCClient(conn *grpc.ClientConn, otTracer stdopentracing.Tracer, zipkin
 		listEndpoint = opentracing.TraceClient(otTracer, "List")(listEndpoint)
 	}
 
+	// The Get endpoint is the same thing, with slightly different
+	// middlewares to demonstrate how to specialize per-endpoint.
+	var getEndpoint endpoint.Endpoint
+	{
+		getEndpoint = grpctransport.NewClient(
+			conn,
+			"pb.Todo",
+			"Get",
+			encodeGRPCGetRequest,
+			decodeGRPCGetResponse,
+			pb.GetResponse{},
+			append(options, grpctransport.ClientBefore(opentracing.ContextToGRPC(otTracer, logger), kitjwt.ContextToGRPC()))...,
+		).Endpoint()
+		getEndpoint = opentracing.TraceClient(otTracer, "Get")(getEndpoint)
+	}
+
+	// The Stats endpoint is the same thing, with slightly different
+	// middlewares to demonstrate how to specialize per-endpoint.
+	var statsEndpoint endpoint.Endpoint
+	{
+		statsEndpoint = grpctransport.NewClient(
+			conn,
+			"pb.Todo",
+			"Stats",
+			encodeGRPCStatsRequest,
+			decodeGRPCStatsResponse,
+			pb.StatsResponse{},
+			append(options, grpctransport.ClientBefore(opentracing.ContextToGRPC(otTracer, logger), kitjwt.ContextToGRPC()))...,
+		).Endpoint()
+		statsEndpoint = opentracing.TraceClient(otTracer, "Stats")(statsEndpoint)
+	}
+
+	// The Unarchive endpoint is the same thing, with slightly different
+	// middlewares to demonstrate how to specialize per-endpoint.
+	var unarchiveEndpoint endpoint.Endpoint
+	{
+		unarchiveEndpoint = grpctransport.NewClient(
+			conn,
+			"pb.Todo",
+			"Unarchive",
+			encodeGRPCUnarchiveRequest,
+			decodeGRPCUnarchiveResponse,
+			pb.UnarchiveResponse{},
+			append(options, grpctransport.ClientBefore(opentracing.ContextToGRPC(otTracer, logger), kitjwt.ContextToGRPC()))...,
+		).Endpoint()
+		unarchiveEndpoint = opentracing.TraceClient(otTracer, "Unarchive")(unarchiveEndpoint)
+	}
+
+	// The Snooze endpoint is the same thing, with slightly different
+	// middlewares to demonstrate how to specialize per-endpoint.
+	var snoozeEndpoint endpoint.Endpoint
+	{
+		snoozeEndpoint = grpctransport.NewClient(
+			conn,
+			"pb.Todo",
+			"Snooze",
+			encodeGRPCSnoozeRequest,
+			decodeGRPCSnoozeResponse,
+			pb.SnoozeResponse{},
+			append(options, grpctransport.ClientBefore(opentracing.ContextToGRPC(otTracer, logger), kitjwt.ContextToGRPC()))...,
+		).Endpoint()
+		snoozeEndpoint = opentracing.TraceClient(otTracer, "Snooze")(snoozeEndpoint)
+	}
+
+	// The Sync endpoint is the same thing, with slightly different
+	// middlewares to demonstrate how to specialize per-endpoint.
+	var syncEndpoint endpoint.Endpoint
+	{
+		syncEndpoint = grpctransport.NewClient(
+			conn,
+			"pb.Todo",
+			"Sync",
+			encodeGRPCSyncRequest,
+			decodeGRPCSyncResponse,
+			pb.SyncResponse{},
+			append(options, grpctransport.ClientBefore(opentracing.ContextToGRPC(otTracer, logger), kitjwt.ContextToGRPC()))...,
+		).Endpoint()
+		syncEndpoint = opentracing.TraceClient(otTracer, "Sync")(syncEndpoint)
+	}
+
+	// The Push endpoint is the same thing, with slightly different
+	// middlewares to demonstrate how to specialize per-endpoint.
+	var pushEndpoint endpoint.Endpoint
+	{
+		pushEndpoint = grpctransport.NewClient(
+			conn,
+			"pb.Todo",
+			"Push",
+			encodeGRPCPushRequest,
+			decodeGRPCPushResponse,
+			pb.PushResponse{},
+			append(options, grpctransport.ClientBefore(opentracing.ContextToGRPC(otTracer, logger), kitjwt.ContextToGRPC()))...,
+		).Endpoint()
+		pushEndpoint = opentracing.TraceClient(otTracer, "Push")(pushEndpoint)
+	}
+
+	// The AddShare endpoint is the same thing, with slightly different
+	// middlewares to demonstrate how to specialize per-endpoint.
+	var addShareEndpoint endpoint.Endpoint
+	{
+		addShareEndpoint = grpctransport.NewClient(
+			conn,
+			"pb.Todo",
+			"AddShare",
+			encodeGRPCAddShareRequest,
+			decodeGRPCAddShareResponse,
+			pb.AddShareResponse{},
+			append(options, grpctransport.ClientBefore(opentracing.ContextToGRPC(otTracer, logger), kitjwt.ContextToGRPC()))...,
+		).Endpoint()
+		addShareEndpoint = opentracing.TraceClient(otTracer, "AddShare")(addShareEndpoint)
+	}
+
+	// The DeleteShare endpoint is the same thing, with slightly different
+	// middlewares to demonstrate how to specialize per-endpoint.
+	var deleteShareEndpoint endpoint.Endpoint
+	{
+		deleteShareEndpoint = grpctransport.NewClient(
+			conn,
+			"pb.Todo",
+			"DeleteShare",
+			encodeGRPCDeleteShareRequest,
+			decodeGRPCDeleteShareResponse,
+			pb.DeleteShareResponse{},
+			append(options, grpctransport.ClientBefore(opentracing.ContextToGRPC(otTracer, logger), kitjwt.ContextToGRPC()))...,
+		).Endpoint()
+		deleteShareEndpoint = opentracing.TraceClient(otTracer, "DeleteShare")(deleteShareEndpoint)
+	}
+
+	// The ListShares endpoint is the same thing, with slightly different
+	// middlewares to demonstrate how to specialize per-endpoint.
+	var listSharesEndpoint endpoint.Endpoint
+	{
+		listSharesEndpoint = grpctransport.NewClient(
+			conn,
+			"pb.Todo",
+			"ListShares",
+			encodeGRPCListSharesRequest,
+			decodeGRPCListSharesResponse,
+			pb.ListSharesResponse{},
+			append(options, grpctransport.ClientBefore(opentracing.ContextToGRPC(otTracer, logger), kitjwt.ContextToGRPC()))...,
+		).Endpoint()
+		listSharesEndpoint = opentracing.TraceClient(otTracer, "ListShares")(listSharesEndpoint)
+	}
+
+	// The CreateAPIKey endpoint is the same thing, with slightly different
+	// middlewares to demonstrate how to specialize per-endpoint.
+	var createAPIKeyEndpoint endpoint.Endpoint
+	{
+		createAPIKeyEndpoint = grpctransport.NewClient(
+			conn,
+			"pb.Todo",
+			"CreateAPIKey",
+			encodeGRPCCreateAPIKeyRequest,
+			decodeGRPCCreateAPIKeyResponse,
+			pb.CreateAPIKeyResponse{},
+			append(options, grpctransport.ClientBefore(opentracing.ContextToGRPC(otTracer, logger), kitjwt.ContextToGRPC()))...,
+		).Endpoint()
+		createAPIKeyEndpoint = opentracing.TraceClient(otTracer, "CreateAPIKey")(createAPIKeyEndpoint)
+	}
+
+	// The RevokeAPIKey endpoint is the same thing, with slightly different
+	// middlewares to demonstrate how to specialize per-endpoint.
+	var revokeAPIKeyEndpoint endpoint.Endpoint
+	{
+		revokeAPIKeyEndpoint = grpctransport.NewClient(
+			conn,
+			"pb.Todo",
+			"RevokeAPIKey",
+			encodeGRPCRevokeAPIKeyRequest,
+			decodeGRPCRevokeAPIKeyResponse,
+			pb.RevokeAPIKeyResponse{},
+			append(options, grpctransport.ClientBefore(opentracing.ContextToGRPC(otTracer, logger), kitjwt.ContextToGRPC()))...,
+		).Endpoint()
+		revokeAPIKeyEndpoint = opentracing.TraceClient(otTracer, "RevokeAPIKey")(revokeAPIKeyEndpoint)
+	}
+
+	// The ListAPIKeys endpoint is the same thing, with slightly different
+	// middlewares to demonstrate how to specialize per-endpoint.
+	var listAPIKeysEndpoint endpoint.Endpoint
+	{
+		listAPIKeysEndpoint = grpctransport.NewClient(
+			conn,
+			"pb.Todo",
+			"ListAPIKeys",
+			encodeGRPCListAPIKeysRequest,
+			decodeGRPCListAPIKeysResponse,
+			pb.ListAPIKeysResponse{},
+			append(options, grpctransport.ClientBefore(opentracing.ContextToGRPC(otTracer, logger), kitjwt.ContextToGRPC()))...,
+		).Endpoint()
+		listAPIKeysEndpoint = opentracing.TraceClient(otTracer, "ListAPIKeys")(listAPIKeysEndpoint)
+	}
+
+	// The Usage endpoint is the same thing, with slightly different
+	// middlewares to demonstrate how to specialize per-endpoint.
+	var usageEndpoint endpoint.Endpoint
+	{
+		usageEndpoint = grpctransport.NewClient(
+			conn,
+			"pb.Todo",
+			"Usage",
+			encodeGRPCUsageRequest,
+			decodeGRPCUsageResponse,
+			pb.UsageResponse{},
+			append(options, grpctransport.ClientBefore(opentracing.ContextToGRPC(otTracer, logger), kitjwt.ContextToGRPC()))...,
+		).Endpoint()
+		usageEndpoint = opentracing.TraceClient(otTracer, "Usage")(usageEndpoint)
+	}
+
+	// The Watch endpoint streams its events, which go-kit's gRPC client
+	// does not support, so it is built on the generated client instead.
+	var watchEndpoint endpoint.Endpoint
+	{
+		watchEndpoint = makeGRPCWatchClientEndpoint(
+			pb.NewTodoClient(conn),
+			opentracing.ContextToGRPC(otTracer, logger),
+			kitjwt.ContextToGRPC(),
+		)
+		watchEndpoint = opentracing.TraceClient(otTracer, "Watch")(watchEndpoint)
+	}
+
 	return endpoints.Endpoints{
-		AddEndpoint:    addEndpoint,
-		DeleteEndpoint: deleteEndpoint,
-		UpdateEndpoint: updateEndpoint,
-		ListEndpoint:   listEndpoint,
+		AddEndpoint:          addEndpoint,
+		DeleteEndpoint:       deleteEndpoint,
+		UpdateEndpoint:       updateEndpoint,
+		ListEndpoint:         listEndpoint,
+		GetEndpoint:          getEndpoint,
+		StatsEndpoint:        statsEndpoint,
+		UnarchiveEndpoint:    unarchiveEndpoint,
+		SnoozeEndpoint:       snoozeEndpoint,
+		WatchEndpoint:        watchEndpoint,
+		SyncEndpoint:         syncEndpoint,
+		PushEndpoint:         pushEndpoint,
+		AddShareEndpoint:     addShareEndpoint,
+		DeleteShareEndpoint:  deleteShareEndpoint,
+		ListSharesEndpoint:   listSharesEndpoint,
+		CreateAPIKeyEndpoint: createAPIKeyEndpoint,
+		RevokeAPIKeyEndpoint: revokeAPIKeyEndpoint,
+		ListAPIKeysEndpoint:  listAPIKeysEndpoint,
+		UsageEndpoint:        usageEndpoint,
 	}
 }
 
@@ -313,8 +1133,22 @@ func decodeGRPCUpdateResponse(_ context.Context, grpcReply interface{}) (interfa
 // encodeGRPCListRequest is a transport/grpc.EncodeRequestFunc that converts a
 // user-domain List request to a gRPC List request. Primarily useful in a client.
 func encodeGRPCListRequest(_ context.Context, request interface{}) (interface{}, error) {
-	_ = request.(endpoints.ListRequest)
-	return &pb.ListRequest{}, nil
+	req := request.(endpoints.ListRequest)
+	if req.Query == nil {
+		return &pb.ListRequest{}, nil
+	}
+	return &pb.ListRequest{
+		FieldMask:       ModelFieldsToPB(req.Query.Fields),
+		CompletedAfter:  formatTime(req.Query.CompletedAfter),
+		CompletedBefore: formatTime(req.Query.CompletedBefore),
+		Sort:            req.Query.Sort,
+		IncludeArchived: req.Query.IncludeArchived,
+		IncludeSnoozed:  req.Query.IncludeSnoozed,
+		Status:          req.Query.Status,
+		Offset:          int32(req.Query.Offset),
+		Limit:           int32(req.Query.Limit),
+		Shared:          req.Query.Shared,
+	}, nil
 }
 
 // decodeGRPCListResponse is a transport/grpc.DecodeResponseFunc that converts a
@@ -330,6 +1164,269 @@ func decodeGRPCListResponse(_ context.Context, grpcReply interface{}) (interface
 	return endpoints.ListResponse{Res: todos}, nil
 }
 
+// encodeGRPCGetRequest is a transport/grpc.EncodeRequestFunc that converts a
+// user-domain Get request to a gRPC Get request. Primarily useful in a client.
+func encodeGRPCGetRequest(_ context.Context, request interface{}) (interface{}, error) {
+	req := request.(endpoints.GetRequest)
+	return &pb.GetRequest{Id: req.Id, FieldMask: ModelFieldsToPB(req.Fields)}, nil
+}
+
+// decodeGRPCGetResponse is a transport/grpc.DecodeResponseFunc that converts a
+// gRPC Get reply to a user-domain Get response. Primarily useful in a client.
+func decodeGRPCGetResponse(_ context.Context, grpcReply interface{}) (interface{}, error) {
+	reply := grpcReply.(*pb.GetResponse)
+	return endpoints.GetResponse{Res: PBtoModelRes(reply.Res)}, nil
+}
+
+// encodeGRPCStatsRequest is a transport/grpc.EncodeRequestFunc that converts a
+// user-domain Stats request to a gRPC Stats request. Primarily useful in a client.
+func encodeGRPCStatsRequest(_ context.Context, request interface{}) (interface{}, error) {
+	req := request.(endpoints.StatsRequest)
+	return &pb.StatsRequest{Days: int32(req.Days)}, nil
+}
+
+// decodeGRPCStatsResponse is a transport/grpc.DecodeResponseFunc that converts a
+// gRPC Stats reply to a user-domain Stats response. Primarily useful in a client.
+func decodeGRPCStatsResponse(_ context.Context, grpcReply interface{}) (interface{}, error) {
+	reply := grpcReply.(*pb.StatsResponse)
+	return endpoints.StatsResponse{Res: PBtoModelStats(reply.Res)}, nil
+}
+
+// encodeGRPCUnarchiveRequest is a transport/grpc.EncodeRequestFunc that converts a
+// user-domain Unarchive request to a gRPC Unarchive request. Primarily useful in a client.
+func encodeGRPCUnarchiveRequest(_ context.Context, request interface{}) (interface{}, error) {
+	req := request.(endpoints.UnarchiveRequest)
+	return &pb.UnarchiveRequest{Id: req.Id}, nil
+}
+
+// decodeGRPCUnarchiveResponse is a transport/grpc.DecodeResponseFunc that converts a
+// gRPC Unarchive reply to a user-domain Unarchive response. Primarily useful in a client.
+func decodeGRPCUnarchiveResponse(_ context.Context, grpcReply interface{}) (interface{}, error) {
+	reply := grpcReply.(*pb.UnarchiveResponse)
+	return endpoints.UnarchiveResponse{Res: PBtoModelRes(reply.Res)}, nil
+}
+
+// encodeGRPCSnoozeRequest is a transport/grpc.EncodeRequestFunc that converts a
+// user-domain Snooze request to a gRPC Snooze request. Primarily useful in a client.
+func encodeGRPCSnoozeRequest(_ context.Context, request interface{}) (interface{}, error) {
+	req := request.(endpoints.SnoozeRequest)
+	if req.Until.IsZero() {
+		return &pb.SnoozeRequest{Id: req.Id}, nil
+	}
+	return &pb.SnoozeRequest{Id: req.Id, Until: req.Until.Format(time.RFC3339)}, nil
+}
+
+// decodeGRPCSnoozeResponse is a transport/grpc.DecodeResponseFunc that converts a
+// gRPC Snooze reply to a user-domain Snooze response. Primarily useful in a client.
+func decodeGRPCSnoozeResponse(_ context.Context, grpcReply interface{}) (interface{}, error) {
+	reply := grpcReply.(*pb.SnoozeResponse)
+	return endpoints.SnoozeResponse{Res: PBtoModelRes(reply.Res)}, nil
+}
+
+// encodeGRPCSyncRequest is a transport/grpc.EncodeRequestFunc that converts a
+// user-domain Sync request to a gRPC Sync request. Primarily useful in a client.
+func encodeGRPCSyncRequest(_ context.Context, request interface{}) (interface{}, error) {
+	req := request.(endpoints.SyncRequest)
+	return &pb.SyncRequest{Since: req.Token}, nil
+}
+
+// decodeGRPCSyncResponse is a transport/grpc.DecodeResponseFunc that converts a
+// gRPC Sync reply to a user-domain Sync response. Primarily useful in a client.
+func decodeGRPCSyncResponse(_ context.Context, grpcReply interface{}) (interface{}, error) {
+	reply := grpcReply.(*pb.SyncResponse)
+	return endpoints.SyncResponse{Res: PBtoModelChanges(reply.Res)}, nil
+}
+
+// encodeGRPCPushRequest is a transport/grpc.EncodeRequestFunc that converts a
+// user-domain Push request to a gRPC Push request. Primarily useful in a client.
+func encodeGRPCPushRequest(_ context.Context, request interface{}) (interface{}, error) {
+	req := request.(endpoints.PushRequest)
+	mutations := make([]*pb.ModelMutation, 0, len(req.Mutations))
+	for _, m := range req.Mutations {
+		mutations = append(mutations, ModelMutationToPB(m))
+	}
+	return &pb.PushRequest{Mutations: mutations}, nil
+}
+
+// decodeGRPCPushResponse is a transport/grpc.DecodeResponseFunc that converts a
+// gRPC Push reply to a user-domain Push response. Primarily useful in a client.
+func decodeGRPCPushResponse(_ context.Context, grpcReply interface{}) (interface{}, error) {
+	reply := grpcReply.(*pb.PushResponse)
+	results := make([]*model.MutationResult, 0, len(reply.Res))
+	for _, r := range reply.Res {
+		results = append(results, PBtoModelMutationResult(r))
+	}
+	return endpoints.PushResponse{Res: results}, nil
+}
+
+// encodeGRPCAddShareRequest is a transport/grpc.EncodeRequestFunc that converts a
+// user-domain AddShare request to a gRPC AddShare request. Primarily useful in a client.
+func encodeGRPCAddShareRequest(_ context.Context, request interface{}) (interface{}, error) {
+	req := request.(endpoints.AddShareRequest)
+	return &pb.AddShareRequest{Share: ModelShareReqToPB(req.Share)}, nil
+}
+
+// decodeGRPCAddShareResponse is a transport/grpc.DecodeResponseFunc that converts a
+// gRPC AddShare reply to a user-domain AddShare response. Primarily useful in a client.
+func decodeGRPCAddShareResponse(_ context.Context, grpcReply interface{}) (interface{}, error) {
+	reply := grpcReply.(*pb.AddShareResponse)
+	return endpoints.AddShareResponse{Res: PBtoModelShare(reply.Res)}, nil
+}
+
+// encodeGRPCDeleteShareRequest is a transport/grpc.EncodeRequestFunc that converts a
+// user-domain DeleteShare request to a gRPC DeleteShare request. Primarily useful in a client.
+func encodeGRPCDeleteShareRequest(_ context.Context, request interface{}) (interface{}, error) {
+	req := request.(endpoints.DeleteShareRequest)
+	return &pb.DeleteShareRequest{Id: req.Id}, nil
+}
+
+// decodeGRPCDeleteShareResponse is a transport/grpc.DecodeResponseFunc that converts a
+// gRPC DeleteShare reply to a user-domain DeleteShare response. Primarily useful in a client.
+func decodeGRPCDeleteShareResponse(_ context.Context, grpcReply interface{}) (interface{}, error) {
+	_ = grpcReply.(*pb.DeleteShareResponse)
+	return endpoints.DeleteShareResponse{}, nil
+}
+
+// encodeGRPCListSharesRequest is a transport/grpc.EncodeRequestFunc that converts a
+// user-domain ListShares request to a gRPC ListShares request. Primarily useful in a client.
+func encodeGRPCListSharesRequest(_ context.Context, request interface{}) (interface{}, error) {
+	_ = request.(endpoints.ListSharesRequest)
+	return &pb.ListSharesRequest{}, nil
+}
+
+// decodeGRPCListSharesResponse is a transport/grpc.DecodeResponseFunc that converts a
+// gRPC ListShares reply to a user-domain ListShares response. Primarily useful in a client.
+func decodeGRPCListSharesResponse(_ context.Context, grpcReply interface{}) (interface{}, error) {
+	reply := grpcReply.(*pb.ListSharesResponse)
+	shares := make([]*model.Share, 0, len(reply.Res))
+	for _, share := range reply.Res {
+		shares = append(shares, PBtoModelShare(share))
+	}
+	return endpoints.ListSharesResponse{Res: shares}, nil
+}
+
+// encodeGRPCCreateAPIKeyRequest is a transport/grpc.EncodeRequestFunc that converts a
+// user-domain CreateAPIKey request to a gRPC CreateAPIKey request. Primarily useful in a client.
+func encodeGRPCCreateAPIKeyRequest(_ context.Context, request interface{}) (interface{}, error) {
+	req := request.(endpoints.CreateAPIKeyRequest)
+	return &pb.CreateAPIKeyRequest{Key: ModelAPIKeyReqToPB(req.Key)}, nil
+}
+
+// decodeGRPCCreateAPIKeyResponse is a transport/grpc.DecodeResponseFunc that converts a
+// gRPC CreateAPIKey reply to a user-domain CreateAPIKey response. Primarily useful in a client.
+func decodeGRPCCreateAPIKeyResponse(_ context.Context, grpcReply interface{}) (interface{}, error) {
+	reply := grpcReply.(*pb.CreateAPIKeyResponse)
+	return endpoints.CreateAPIKeyResponse{Res: PBtoModelCreatedAPIKey(reply.Res)}, nil
+}
+
+// encodeGRPCRevokeAPIKeyRequest is a transport/grpc.EncodeRequestFunc that converts a
+// user-domain RevokeAPIKey request to a gRPC RevokeAPIKey request. Primarily useful in a client.
+func encodeGRPCRevokeAPIKeyRequest(_ context.Context, request interface{}) (interface{}, error) {
+	req := request.(endpoints.RevokeAPIKeyRequest)
+	return &pb.RevokeAPIKeyRequest{Id: req.Id}, nil
+}
+
+// decodeGRPCRevokeAPIKeyResponse is a transport/grpc.DecodeResponseFunc that converts a
+// gRPC RevokeAPIKey reply to a user-domain RevokeAPIKey response. Primarily useful in a client.
+func decodeGRPCRevokeAPIKeyResponse(_ context.Context, grpcReply interface{}) (interface{}, error) {
+	_ = grpcReply.(*pb.RevokeAPIKeyResponse)
+	return endpoints.RevokeAPIKeyResponse{}, nil
+}
+
+// encodeGRPCListAPIKeysRequest is a transport/grpc.EncodeRequestFunc that converts a
+// user-domain ListAPIKeys request to a gRPC ListAPIKeys request. Primarily useful in a client.
+func encodeGRPCListAPIKeysRequest(_ context.Context, request interface{}) (interface{}, error) {
+	_ = request.(endpoints.ListAPIKeysRequest)
+	return &pb.ListAPIKeysRequest{}, nil
+}
+
+// decodeGRPCListAPIKeysResponse is a transport/grpc.DecodeResponseFunc that converts a
+// gRPC ListAPIKeys reply to a user-domain ListAPIKeys response. Primarily useful in a client.
+func decodeGRPCListAPIKeysResponse(_ context.Context, grpcReply interface{}) (interface{}, error) {
+	reply := grpcReply.(*pb.ListAPIKeysResponse)
+	keys := make([]*model.APIKey, 0, len(reply.Res))
+	for _, key := range reply.Res {
+		keys = append(keys, PBtoModelAPIKey(key))
+	}
+	return endpoints.ListAPIKeysResponse{Res: keys}, nil
+}
+
+// encodeGRPCUsageRequest is a transport/grpc.EncodeRequestFunc that converts a
+// user-domain Usage request to a gRPC Usage request. Primarily useful in a client.
+func encodeGRPCUsageRequest(_ context.Context, request interface{}) (interface{}, error) {
+	_ = request.(endpoints.UsageRequest)
+	return &pb.UsageRequest{}, nil
+}
+
+// decodeGRPCUsageResponse is a transport/grpc.DecodeResponseFunc that converts a
+// gRPC Usage reply to a user-domain Usage response. Primarily useful in a client.
+func decodeGRPCUsageResponse(_ context.Context, grpcReply interface{}) (interface{}, error) {
+	reply := grpcReply.(*pb.UsageResponse)
+	return endpoints.UsageResponse{Res: PBtoModelUsage(reply.Res)}, nil
+}
+
+// encodeGRPCWatchRequest is a transport/grpc.EncodeRequestFunc that converts a
+// user-domain Watch request to a gRPC Watch request. Primarily useful in a client.
+func encodeGRPCWatchRequest(_ context.Context, request interface{}) (interface{}, error) {
+	req := request.(endpoints.WatchRequest)
+	if req.Query == nil {
+		return &pb.WatchRequest{}, nil
+	}
+	return &pb.WatchRequest{Types: req.Query.Types, Since: req.Query.Since}, nil
+}
+
+// makeGRPCWatchClientEndpoint returns an endpoint that opens a Watch stream
+// and relays its events until the stream or ctx ends. Primarily useful in a
+// client.
+func makeGRPCWatchClientEndpoint(client pb.TodoClient, before ...grpctransport.ClientRequestFunc) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req, err := encodeGRPCWatchRequest(ctx, request)
+		if err != nil {
+			return nil, err
+		}
+
+		md := &metadata.MD{}
+		for _, f := range before {
+			ctx = f(ctx, md)
+		}
+		ctx = metadata.NewOutgoingContext(ctx, *md)
+
+		stream, err := client.Watch(ctx, req.(*pb.WatchRequest))
+		if err != nil {
+			return nil, err
+		}
+		// the server sends its header once the watch has started, otherwise
+		// the stream ends right away with the error of the watch
+		header, err := stream.Header()
+		if err != nil {
+			return nil, err
+		}
+		if len(header.Get(watchStartedHeader)) == 0 {
+			if _, err := stream.Recv(); err != nil && err != io.EOF {
+				return nil, err
+			}
+			return nil, status.Error(codes.Unknown, "watch ended before it started")
+		}
+
+		events := make(chan *model.TodoEvent)
+		go func() {
+			defer close(events)
+			for {
+				ev, err := stream.Recv()
+				if err != nil {
+					return
+				}
+				select {
+				case events <- PBtoModelEvent(ev):
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+		return endpoints.WatchResponse{Events: events}, nil
+	}
+}
+
 func grpcEncodeError(err errors.Error) error {
 	if err == nil {
 		return nil
@@ -340,15 +1437,38 @@ func grpcEncodeError(err errors.Error) error {
 		return status.Error(st.Code(), st.Message())
 	}
 
+	// the authentication errors may wrap the errors of the service
 	switch {
+	case errors.Contains(err, authn.ErrUnauthorized):
+		return status.Error(codes.Unauthenticated, err.Error())
+	case errors.Contains(err, authz.ErrForbidden):
+		return status.Error(codes.PermissionDenied, err.Error())
+	case errors.Contains(err, ratelimit.ErrLimited):
+		return rateLimitStatus(err)
+	case errors.Contains(err, service.ErrQuotaExceeded):
+		return status.Error(codes.ResourceExhausted, err.Error())
 	case errors.Contains(err, service.ErrInvalidQueryParams),
 		errors.Contains(err, service.ErrMalformedEntity):
 		return status.Error(codes.InvalidArgument, err.Error())
 	case errors.Contains(err, service.ErrNotFound):
 		return status.Error(codes.NotFound, err.Error())
-	case errors.Contains(err, kitjwt.ErrTokenContextMissing):
-		return status.Error(codes.Unauthenticated, err.Error())
+	case errors.Contains(err, service.ErrSequenceExpired):
+		return status.Error(codes.OutOfRange, err.Error())
+	case errors.Contains(err, service.ErrConflict):
+		return status.Error(codes.Aborted, err.Error())
 	default:
 		return status.Error(codes.Internal, "internal server error")
 	}
 }
+
+// rateLimitStatus returns the ResourceExhausted status of err, telling when
+// to retry with its RetryInfo detail.
+func rateLimitStatus(err errors.Error) error {
+	st := status.New(codes.ResourceExhausted, err.Error())
+	if e, ok := err.(*ratelimit.Error); ok {
+		if d, derr := st.WithDetails(&errdetails.RetryInfo{RetryDelay: durationpb.New(e.RetryAfter)}); derr == nil {
+			st = d
+		}
+	}
+	return st.Err()
+}
@@ -4,22 +4,43 @@ package transports_test
 
 import (
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"math/big"
 	"net"
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
+	jwt "github.com/dgrijalva/jwt-go"
+	kitjwt "github.com/go-kit/kit/auth/jwt"
 	"github.com/go-kit/kit/log"
 	"github.com/golang/mock/gomock"
 	"github.com/opentracing/opentracing-go"
 	"github.com/openzipkin/zipkin-go"
 	"github.com/stretchr/testify/assert"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/status"
 
 	"github.com/cage1016/gokit-todo/internal/app/todo/endpoints"
 	"github.com/cage1016/gokit-todo/internal/app/todo/model"
+	"github.com/cage1016/gokit-todo/internal/app/todo/service"
 	transports "github.com/cage1016/gokit-todo/internal/app/todo/transports/grpc"
 	automocks "github.com/cage1016/gokit-todo/internal/mocks/app/todo/service"
+	"github.com/cage1016/gokit-todo/internal/pkg/authn"
+	"github.com/cage1016/gokit-todo/internal/pkg/authz"
+	"github.com/cage1016/gokit-todo/internal/pkg/ratelimit"
+	"github.com/cage1016/gokit-todo/internal/pkg/tlsconfig"
 	pb "github.com/cage1016/gokit-todo/pb/todo"
 )
 
@@ -214,6 +235,7 @@ func TestGrpcServer_List(t *testing.T) {
 	}
 
 	type args struct {
+		query *model.TodoQuery
 	}
 
 	tests := []struct {
@@ -227,7 +249,7 @@ func TestGrpcServer_List(t *testing.T) {
 			name: "grpc list todo",
 			prepare: func(f *fields) {
 				gomock.InOrder(
-					f.svc.EXPECT().List(gomock.Any()).Return([]*model.TodoRes{{
+					f.svc.EXPECT().List(gomock.Any(), gomock.Any()).Return([]*model.TodoRes{{
 						ID:        "iKe0KxpurIn0E_6vzUDAr",
 						CreatedAt: time.Now(),
 						UpdatedAt: time.Now(),
@@ -240,6 +262,44 @@ func TestGrpcServer_List(t *testing.T) {
 				assert.Equal(t, len(res), 1)
 			},
 		},
+		{
+			name: "grpc list todo with field mask",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.svc.EXPECT().List(gomock.Any(), &model.TodoQuery{Fields: []string{"id", "completed"}}).Return([]*model.TodoRes{{
+						ID:        "iKe0KxpurIn0E_6vzUDAr",
+						CreatedAt: time.Now(),
+						UpdatedAt: time.Now(),
+						Text:      "aa",
+						Completed: true,
+					}}, nil),
+				)
+			},
+			args: args{query: &model.TodoQuery{Fields: []string{"id", "completed"}}},
+			checkFunc: func(res []*model.TodoRes, err error) {
+				assert.Equal(t, len(res), 1)
+				assert.Equal(t, "iKe0KxpurIn0E_6vzUDAr", res[0].ID)
+				assert.Equal(t, true, res[0].Completed)
+				assert.Equal(t, "", res[0].Text)
+			},
+		},
+		{
+			name: "grpc list todo shared with the caller",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.svc.EXPECT().List(gomock.Any(), &model.TodoQuery{Shared: true}).Return([]*model.TodoRes{{
+						ID:      "iKe0KxpurIn0E_6vzUDAr",
+						OwnerID: "bob",
+						Text:    "aa",
+					}}, nil),
+				)
+			},
+			args: args{query: &model.TodoQuery{Shared: true}},
+			checkFunc: func(res []*model.TodoRes, err error) {
+				assert.Equal(t, len(res), 1)
+				assert.Equal(t, "bob", res[0].OwnerID)
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -279,7 +339,7 @@ func TestGrpcServer_List(t *testing.T) {
 			}
 			svc := transports.NewGRPCClient(cc, tracer, zkt, logger)
 
-			if res, err := svc.List(context.Background()); (err != nil) != tt.wantErr {
+			if res, err := svc.List(context.Background(), tt.args.query); (err != nil) != tt.wantErr {
 				t.Errorf("svc.Update error = %v, wantErr %v", err, tt.wantErr)
 			} else {
 				if tt.checkFunc != nil {
@@ -367,3 +427,651 @@ func TestGrpcServer_Delete(t *testing.T) {
 		})
 	}
 }
+
+func TestGrpcServer_Watch(t *testing.T) {
+	type fields struct {
+		svc *automocks.MockTodoService
+	}
+
+	type args struct {
+		query *model.EventQuery
+	}
+
+	streams, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name      string
+		prepare   func(f *fields)
+		opts      []transports.Option
+		args      args
+		wantErr   bool
+		checkFunc func(res <-chan *model.TodoEvent, err error)
+	}{
+		{
+			name: "grpc watch todo",
+			prepare: func(f *fields) {
+				events := make(chan *model.TodoEvent, 2)
+				events <- &model.TodoEvent{Seq: 3, Type: model.EventCreated, Todo: &model.TodoRes{ID: "iKe0KxpurIn0E_6vzUDAr", Text: "aa"}, Time: time.Now()}
+				events <- &model.TodoEvent{Seq: 4, Type: model.EventDeleted, Todo: &model.TodoRes{ID: "iKe0KxpurIn0E_6vzUDAr"}, Time: time.Now()}
+				gomock.InOrder(
+					f.svc.EXPECT().Watch(gomock.Any(), &model.EventQuery{Types: []string{"created", "deleted"}, Since: 2}).Return(events, nil),
+				)
+			},
+			args: args{query: &model.EventQuery{Types: []string{"created", "deleted"}, Since: 2}},
+			checkFunc: func(res <-chan *model.TodoEvent, err error) {
+				ev := <-res
+				assert.Equal(t, uint64(3), ev.Seq)
+				assert.Equal(t, "aa", ev.Todo.Text)
+				ev = <-res
+				assert.Equal(t, uint64(4), ev.Seq)
+				assert.Equal(t, model.EventDeleted, ev.Type)
+			},
+		},
+		{
+			name: "grpc watch todo from an expired sequence",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.svc.EXPECT().Watch(gomock.Any(), gomock.Any()).Return(nil, service.ErrSequenceExpired),
+				)
+			},
+			args:    args{query: &model.EventQuery{Since: 2}},
+			wantErr: true,
+			checkFunc: func(res <-chan *model.TodoEvent, err error) {
+				assert.Equal(t, codes.OutOfRange, status.Code(err))
+			},
+		},
+		{
+			name: "grpc watch todo until the streams end",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.svc.EXPECT().Watch(gomock.Any(), gomock.Any()).Return(make(<-chan *model.TodoEvent), nil),
+				)
+			},
+			opts: []transports.Option{transports.WithStreamContext(streams)},
+			args: args{query: &model.EventQuery{}},
+			checkFunc: func(res <-chan *model.TodoEvent, err error) {
+				select {
+				case _, ok := <-res:
+					assert.False(t, ok, "events should be closed")
+				case <-time.After(time.Second):
+					t.Error("events should be closed once the streams end")
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			f := fields{
+				svc: automocks.NewMockTodoService(ctrl),
+			}
+			if tt.prepare != nil {
+				tt.prepare(&f)
+			}
+
+			logger := log.NewLogfmtLogger(os.Stderr)
+			zkt, _ := zipkin.NewTracer(nil, zipkin.WithNoopTracer(true))
+			tracer := opentracing.GlobalTracer()
+
+			// server
+			server := grpc.NewServer()
+			eps := endpoints.New(f.svc, logger, tracer, zkt)
+			sc, err := net.Listen("tcp", hostPort)
+			if err != nil {
+				t.Fatalf("unable to listen: %+v", err)
+			}
+			defer server.Stop()
+
+			go func() {
+				pb.RegisterTodoServer(server, transports.MakeGRPCServer(eps, tracer, zkt, logger, tt.opts...))
+				_ = server.Serve(sc)
+			}()
+
+			// client
+			cc, err := grpc.Dial(hostPort, grpc.WithInsecure())
+			if err != nil {
+				t.Fatalf("unable to Dial: %+v", err)
+			}
+			svc := transports.NewGRPCClient(cc, tracer, zkt, logger)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			if res, err := svc.Watch(ctx, tt.args.query); (err != nil) != tt.wantErr {
+				t.Errorf("svc.Watch error = %v, wantErr %v", err, tt.wantErr)
+			} else {
+				if tt.checkFunc != nil {
+					tt.checkFunc(res, err)
+				}
+			}
+		})
+	}
+}
+
+// keys verify the tokens signed with the "secret" secret.
+func keys(t *testing.T) *authn.Keys {
+	keys, err := authn.LoadKeys("secret", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return keys
+}
+
+func TestGrpcServer_Authn(t *testing.T) {
+	type fields struct {
+		svc *automocks.MockTodoService
+	}
+
+	type args struct {
+		token string
+	}
+
+	sign := func(secret string) string {
+		s, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.StandardClaims{Subject: "alice", ExpiresAt: time.Now().Add(time.Hour).Unix()}).SignedString([]byte(secret))
+		return s
+	}
+
+	tests := []struct {
+		name      string
+		prepare   func(f *fields)
+		args      args
+		wantErr   bool
+		checkFunc func(err error)
+	}{
+		{
+			name: "grpc delete todo with a token",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.svc.EXPECT().Delete(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, id string) error {
+						claims, ok := authn.FromContext(ctx)
+						assert.True(t, ok, "claims should be on the context")
+						assert.Equal(t, "alice", claims.Subject)
+						return nil
+					}),
+				)
+			},
+			args: args{token: sign("secret")},
+			checkFunc: func(err error) {
+				assert.Nil(t, err)
+			},
+		},
+		{
+			name: "grpc delete todo with an API key",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.svc.EXPECT().Delete(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, id string) error {
+						claims, ok := authn.FromContext(ctx)
+						assert.True(t, ok, "claims should be on the context")
+						assert.Equal(t, "ci", claims.Subject)
+						return nil
+					}),
+				)
+			},
+			args: args{token: "todo_ci"},
+			checkFunc: func(err error) {
+				assert.Nil(t, err)
+			},
+		},
+		{
+			name:    "grpc delete todo with an unknown API key",
+			args:    args{token: "todo_other"},
+			wantErr: true,
+			checkFunc: func(err error) {
+				assert.Equal(t, codes.Unauthenticated, status.Code(err))
+			},
+		},
+		{
+			name:    "grpc delete todo without a token",
+			wantErr: true,
+			checkFunc: func(err error) {
+				assert.Equal(t, codes.Unauthenticated, status.Code(err))
+			},
+		},
+		{
+			name:    "grpc delete todo with an invalid token",
+			args:    args{token: sign("other")},
+			wantErr: true,
+			checkFunc: func(err error) {
+				assert.Equal(t, codes.Unauthenticated, status.Code(err))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			f := fields{
+				svc: automocks.NewMockTodoService(ctrl),
+			}
+			if tt.prepare != nil {
+				tt.prepare(&f)
+			}
+
+			logger := log.NewLogfmtLogger(os.Stderr)
+			zkt, _ := zipkin.NewTracer(nil, zipkin.WithNoopTracer(true))
+			tracer := opentracing.GlobalTracer()
+
+			// server
+			server := grpc.NewServer()
+			eps := endpoints.AuthnMiddleware(authn.NewParser(keys(t), authn.WithAPIKeys(apiKeys{"todo_ci": {StandardClaims: jwt.StandardClaims{Subject: "ci"}}})), endpoints.New(f.svc, logger, tracer, zkt))
+			sc, err := net.Listen("tcp", hostPort)
+			if err != nil {
+				t.Fatalf("unable to listen: %+v", err)
+			}
+			defer server.GracefulStop()
+
+			go func() {
+				pb.RegisterTodoServer(server, transports.MakeGRPCServer(eps, tracer, zkt, logger))
+				_ = server.Serve(sc)
+			}()
+
+			// client
+			cc, err := grpc.Dial(hostPort, grpc.WithInsecure())
+			if err != nil {
+				t.Fatalf("unable to Dial: %+v", err)
+			}
+			svc := transports.NewGRPCClient(cc, tracer, zkt, logger)
+
+			ctx := context.Background()
+			if tt.args.token != "" {
+				ctx = context.WithValue(ctx, kitjwt.JWTTokenContextKey, tt.args.token)
+			}
+			if err := svc.Delete(ctx, "iKe0KxpurIn0E_6vzUDAr"); (err != nil) != tt.wantErr {
+				t.Errorf("svc.Delete error = %v, wantErr %v", err, tt.wantErr)
+			} else {
+				if tt.checkFunc != nil {
+					tt.checkFunc(err)
+				}
+			}
+		})
+	}
+}
+
+// apiKeys authenticates the API keys it maps to their claims.
+type apiKeys map[string]*authn.Claims
+
+func (k apiKeys) Authenticate(_ context.Context, key string) (*authn.Claims, error) {
+	if claims, ok := k[key]; ok {
+		return claims, nil
+	}
+	return nil, service.ErrNotFound
+}
+
+func TestGrpcServer_Authz(t *testing.T) {
+	type fields struct {
+		svc *automocks.MockTodoService
+	}
+
+	type args struct {
+		roles []string
+	}
+
+	policy, err := authz.ParsePolicy([]byte(`{"roles": {"viewer": ["todo:list"], "editor": ["todo:*"]}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		prepare   func(f *fields)
+		args      args
+		wantErr   bool
+		checkFunc func(err error)
+	}{
+		{
+			name: "grpc delete todo as an editor",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.svc.EXPECT().Delete(gomock.Any(), gomock.Any()).Return(nil),
+				)
+			},
+			args: args{roles: []string{"editor"}},
+			checkFunc: func(err error) {
+				assert.Nil(t, err)
+			},
+		},
+		{
+			name:    "grpc delete todo as a viewer",
+			args:    args{roles: []string{"viewer"}},
+			wantErr: true,
+			checkFunc: func(err error) {
+				assert.Equal(t, codes.PermissionDenied, status.Code(err))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			f := fields{
+				svc: automocks.NewMockTodoService(ctrl),
+			}
+			if tt.prepare != nil {
+				tt.prepare(&f)
+			}
+
+			logger := log.NewLogfmtLogger(os.Stderr)
+			zkt, _ := zipkin.NewTracer(nil, zipkin.WithNoopTracer(true))
+			tracer := opentracing.GlobalTracer()
+
+			// server
+			server := grpc.NewServer()
+			eps := endpoints.AuthzMiddleware(authz.NewAuthorizer(policy), endpoints.New(f.svc, logger, tracer, zkt))
+			eps = endpoints.AuthnMiddleware(authn.NewParser(keys(t)), eps)
+			sc, err := net.Listen("tcp", hostPort)
+			if err != nil {
+				t.Fatalf("unable to listen: %+v", err)
+			}
+			defer server.GracefulStop()
+
+			go func() {
+				pb.RegisterTodoServer(server, transports.MakeGRPCServer(eps, tracer, zkt, logger))
+				_ = server.Serve(sc)
+			}()
+
+			// client
+			cc, err := grpc.Dial(hostPort, grpc.WithInsecure())
+			if err != nil {
+				t.Fatalf("unable to Dial: %+v", err)
+			}
+			svc := transports.NewGRPCClient(cc, tracer, zkt, logger)
+
+			token, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, &authn.Claims{StandardClaims: jwt.StandardClaims{Subject: "alice", ExpiresAt: time.Now().Add(time.Hour).Unix()}, Roles: tt.args.roles}).SignedString([]byte("secret"))
+			ctx := context.WithValue(context.Background(), kitjwt.JWTTokenContextKey, token)
+			if err := svc.Delete(ctx, "iKe0KxpurIn0E_6vzUDAr"); (err != nil) != tt.wantErr {
+				t.Errorf("svc.Delete error = %v, wantErr %v", err, tt.wantErr)
+			} else {
+				if tt.checkFunc != nil {
+					tt.checkFunc(err)
+				}
+			}
+		})
+	}
+}
+
+func TestGrpcServer_TLS(t *testing.T) {
+	type fields struct {
+		svc *automocks.MockTodoService
+	}
+
+	type args struct {
+		clientCert bool
+		// renew replaces the certificate of the server by one of another CA,
+		// which only the client trusts, before the call.
+		renew bool
+	}
+
+	tests := []struct {
+		name      string
+		prepare   func(f *fields)
+		args      args
+		wantErr   bool
+		checkFunc func(err error)
+	}{
+		{
+			name: "grpc delete todo over mutual TLS",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.svc.EXPECT().Delete(gomock.Any(), "iKe0KxpurIn0E_6vzUDAr").Return(nil),
+				)
+			},
+			args: args{clientCert: true},
+		},
+		{
+			name: "grpc delete todo over mutual TLS after the certificate is renewed",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.svc.EXPECT().Delete(gomock.Any(), "iKe0KxpurIn0E_6vzUDAr").Return(nil),
+				)
+			},
+			args: args{clientCert: true, renew: true},
+		},
+		{
+			name:    "grpc delete todo without a client certificate",
+			args:    args{clientCert: false},
+			wantErr: true,
+			checkFunc: func(err error) {
+				assert.Equal(t, codes.Unavailable, status.Code(err), "code: expected Unavailable")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			f := fields{
+				svc: automocks.NewMockTodoService(ctrl),
+			}
+			if tt.prepare != nil {
+				tt.prepare(&f)
+			}
+
+			dir, err := ioutil.TempDir("", "tls")
+			if err != nil {
+				t.Fatalf("unable to create the certificates directory: %+v", err)
+			}
+			defer os.RemoveAll(dir)
+
+			ca, caKey := writeCert(t, dir, "ca", nil, nil)
+			writeCert(t, dir, "server", ca, caKey)
+			writeCert(t, dir, "client", ca, caKey)
+
+			logger := log.NewLogfmtLogger(os.Stderr)
+			zkt, _ := zipkin.NewTracer(nil, zipkin.WithNoopTracer(true))
+			tracer := opentracing.GlobalTracer()
+
+			// server
+			certs, err := tlsconfig.NewReloader(tlsconfig.Config{
+				CertFile: filepath.Join(dir, "server.pem"),
+				KeyFile:  filepath.Join(dir, "server-key.pem"),
+				CAFile:   filepath.Join(dir, "ca.pem"),
+			}, logger)
+			if err != nil {
+				t.Fatalf("unable to load the certificates: %+v", err)
+			}
+			server := grpc.NewServer(grpc.Creds(credentials.NewTLS(certs.ServerConfig("h2"))))
+			eps := endpoints.New(f.svc, logger, tracer, zkt)
+			sc, err := net.Listen("tcp", hostPort)
+			if err != nil {
+				t.Fatalf("unable to listen: %+v", err)
+			}
+			defer server.GracefulStop()
+
+			go func() {
+				pb.RegisterTodoServer(server, transports.MakeGRPCServer(eps, tracer, zkt, logger))
+				_ = server.Serve(sc)
+			}()
+
+			caFile := "ca.pem"
+			if tt.args.renew {
+				renewed, renewedKey := writeCert(t, dir, "renewed-ca", nil, nil)
+				writeCert(t, dir, "server", renewed, renewedKey)
+				// the modification time may be the same as the one of the
+				// replaced file, the size is not
+				if reloaded, err := certs.Reload(); err != nil || !reloaded {
+					t.Fatalf("unable to reload the certificates: %v %+v", reloaded, err)
+				}
+				caFile = "renewed-ca.pem"
+			}
+
+			// client
+			clientConfig := tlsconfig.Config{CAFile: filepath.Join(dir, caFile)}
+			if tt.args.clientCert {
+				clientConfig.CertFile, clientConfig.KeyFile = filepath.Join(dir, "client.pem"), filepath.Join(dir, "client-key.pem")
+			}
+			tlsConfig, err := tlsconfig.ClientConfig(clientConfig, "localhost")
+			if err != nil {
+				t.Fatalf("unable to load the client certificates: %+v", err)
+			}
+			cc, err := grpc.Dial(hostPort, transports.DialOption(tlsConfig))
+			if err != nil {
+				t.Fatalf("unable to Dial: %+v", err)
+			}
+			defer cc.Close()
+			svc := transports.NewGRPCClient(cc, tracer, zkt, logger)
+
+			if err := svc.Delete(context.Background(), "iKe0KxpurIn0E_6vzUDAr"); (err != nil) != tt.wantErr {
+				t.Errorf("svc.Delete error = %v, wantErr %v", err, tt.wantErr)
+			} else {
+				if tt.checkFunc != nil {
+					tt.checkFunc(err)
+				}
+			}
+		})
+	}
+}
+
+func TestGrpcServer_RateLimit(t *testing.T) {
+	type fields struct {
+		svc *automocks.MockTodoService
+	}
+
+	type args struct {
+		calls int
+	}
+
+	tests := []struct {
+		name      string
+		prepare   func(f *fields)
+		args      args
+		wantErr   bool
+		checkFunc func(err error)
+	}{
+		{
+			name: "grpc delete todo within the limit",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.svc.EXPECT().Delete(gomock.Any(), "iKe0KxpurIn0E_6vzUDAr").Return(nil),
+				)
+			},
+			args: args{calls: 1},
+		},
+		{
+			name: "grpc delete todo past the limit",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.svc.EXPECT().Delete(gomock.Any(), "iKe0KxpurIn0E_6vzUDAr").Return(nil),
+				)
+			},
+			args:    args{calls: 2},
+			wantErr: true,
+			checkFunc: func(err error) {
+				st := status.Convert(err)
+				assert.Equal(t, codes.ResourceExhausted, st.Code(), "code: expected ResourceExhausted")
+				if assert.Len(t, st.Details(), 1, "details: expected a RetryInfo") {
+					info, ok := st.Details()[0].(*errdetails.RetryInfo)
+					assert.True(t, ok, "details: expected a RetryInfo")
+					assert.True(t, ok && info.RetryDelay.AsDuration() > time.Hour, fmt.Sprintf("retry delay: expected about a day got %v", info))
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			f := fields{
+				svc: automocks.NewMockTodoService(ctrl),
+			}
+			if tt.prepare != nil {
+				tt.prepare(&f)
+			}
+
+			logger := log.NewLogfmtLogger(os.Stderr)
+			zkt, _ := zipkin.NewTracer(nil, zipkin.WithNoopTracer(true))
+			tracer := opentracing.GlobalTracer()
+
+			// server, the callers may delete a todo a day
+			limiter := ratelimit.NewLimiter(ratelimit.Limits{"delete": {Rate: 1.0 / 86400, Burst: 1}})
+			server := grpc.NewServer()
+			eps := endpoints.New(f.svc, logger, tracer, zkt, endpoints.WithRateLimit(limiter.Middleware))
+			sc, err := net.Listen("tcp", hostPort)
+			if err != nil {
+				t.Fatalf("unable to listen: %+v", err)
+			}
+			defer server.GracefulStop()
+
+			go func() {
+				pb.RegisterTodoServer(server, transports.MakeGRPCServer(eps, tracer, zkt, logger))
+				_ = server.Serve(sc)
+			}()
+
+			// client
+			cc, err := grpc.Dial(hostPort, grpc.WithInsecure())
+			if err != nil {
+				t.Fatalf("unable to Dial: %+v", err)
+			}
+			defer cc.Close()
+			client := pb.NewTodoClient(cc)
+
+			for i := 0; i < tt.args.calls; i++ {
+				_, err = client.Delete(context.Background(), &pb.DeleteRequest{Id: "iKe0KxpurIn0E_6vzUDAr"})
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("client.Delete error = %v, wantErr %v", err, tt.wantErr)
+			} else {
+				if tt.checkFunc != nil {
+					tt.checkFunc(err)
+				}
+			}
+		})
+	}
+}
+
+// writeCert writes the PEM-encoded certificate of localhost signed by parent,
+// or of a CA when parent is nil, to dir/name.pem and its key to
+// dir/name-key.pem.
+func writeCert(t *testing.T, dir, name string, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("unable to generate a key: %+v", err)
+	}
+	serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	if err != nil {
+		t.Fatalf("unable to generate a serial number: %+v", err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		DNSNames:     []string{"localhost"},
+	}
+	if parent == nil {
+		tmpl.IsCA, tmpl.BasicConstraintsValid = true, true
+		parent, parentKey = tmpl, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		t.Fatalf("unable to create the certificate: %+v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("unable to marshal the key: %+v", err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, name+".pem"), pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600); err != nil {
+		t.Fatalf("unable to write the certificate: %+v", err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, name+"-key.pem"), pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600); err != nil {
+		t.Fatalf("unable to write the key: %+v", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("unable to parse the certificate: %+v", err)
+	}
+	return cert, key
+}
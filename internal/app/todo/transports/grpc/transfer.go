@@ -3,6 +3,8 @@ package transports
 import (
 	"time"
 
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+
 	pb "github.com/cage1016/gokit-todo/pb/todo"
 
 	"github.com/cage1016/gokit-todo/internal/app/todo/model"
@@ -24,17 +26,25 @@ func PBtoModelReq(todo *pb.ModelTodoReq) *model.TodoReq {
 
 func ModelResToPB(todo *model.TodoRes) *pb.ModelTodoRes {
 	return &pb.ModelTodoRes{
-		Id:        todo.ID,
-		CreatedAt: todo.CreatedAt.Format(time.RFC3339),
-		UpdatedAt: todo.UpdatedAt.Format(time.RFC3339),
-		Text:      todo.Text,
-		Completed: todo.Completed,
+		Id:           todo.ID,
+		OwnerId:      todo.OwnerID,
+		TenantId:     todo.TenantID,
+		CreatedAt:    todo.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:    todo.UpdatedAt.Format(time.RFC3339),
+		Text:         todo.Text,
+		Completed:    todo.Completed,
+		CompletedAt:  formatTime(todo.CompletedAt),
+		ArchivedAt:   formatTime(todo.ArchivedAt),
+		SnoozedUntil: formatTime(todo.SnoozedUntil),
+		Version:      todo.Version,
 	}
 }
 
 func PBtoModelRes(todo *pb.ModelTodoRes) *model.TodoRes {
 	return &model.TodoRes{
 		ID:        todo.Id,
+		OwnerID:   todo.OwnerId,
+		TenantID:  todo.TenantId,
 		Completed: todo.Completed,
 		Text:      todo.Text,
 		CreatedAt: func() time.Time {
@@ -51,5 +61,428 @@ func PBtoModelRes(todo *pb.ModelTodoRes) *model.TodoRes {
 			}
 			return t
 		}(),
+		CompletedAt: func() *time.Time {
+			t, _ := parseTime(todo.CompletedAt)
+			return t
+		}(),
+		ArchivedAt: func() *time.Time {
+			t, _ := parseTime(todo.ArchivedAt)
+			return t
+		}(),
+		SnoozedUntil: func() *time.Time {
+			t, _ := parseTime(todo.SnoozedUntil)
+			return t
+		}(),
+		Version: todo.Version,
+	}
+}
+
+// formatTime formats an optional time as RFC3339, an unset time is empty.
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+// parseTime parses an optional RFC3339 time, an empty string is unset.
+func parseTime(s string) (*time.Time, error) {
+	if s == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// pbFields maps the field mask paths of a pb.ModelTodoRes to the todo field names.
+var pbFields = map[string]string{
+	"id":            "id",
+	"owner_id":      "ownerId",
+	"tenant_id":     "tenantId",
+	"created_at":    "createdAt",
+	"updated_at":    "updatedAt",
+	"text":          "text",
+	"completed":     "completed",
+	"completed_at":  "completedAt",
+	"archived_at":   "archivedAt",
+	"snoozed_until": "snoozedUntil",
+	"version":       "version",
+}
+
+func PBtoModelFields(mask *fieldmaskpb.FieldMask) (fields []string) {
+	for _, path := range mask.GetPaths() {
+		if f, ok := pbFields[path]; ok {
+			fields = append(fields, f)
+		} else {
+			fields = append(fields, path)
+		}
+	}
+	return
+}
+
+func ModelFieldsToPB(fields []string) *fieldmaskpb.FieldMask {
+	if len(fields) == 0 {
+		return nil
+	}
+
+	mask := &fieldmaskpb.FieldMask{}
+	for _, f := range fields {
+		path := f
+		for p, v := range pbFields {
+			if v == f {
+				path = p
+				break
+			}
+		}
+		mask.Paths = append(mask.Paths, path)
+	}
+	return mask
+}
+
+// ModelResToMaskedPB converts todo and clears every field not listed in fields.
+func ModelResToMaskedPB(todo *model.TodoRes, fields []string) *pb.ModelTodoRes {
+	res := ModelResToPB(todo)
+	if len(fields) == 0 {
+		return res
+	}
+
+	masked := &pb.ModelTodoRes{}
+	for _, f := range fields {
+		switch f {
+		case "id":
+			masked.Id = res.Id
+		case "ownerId":
+			masked.OwnerId = res.OwnerId
+		case "tenantId":
+			masked.TenantId = res.TenantId
+		case "createdAt":
+			masked.CreatedAt = res.CreatedAt
+		case "updatedAt":
+			masked.UpdatedAt = res.UpdatedAt
+		case "text":
+			masked.Text = res.Text
+		case "completed":
+			masked.Completed = res.Completed
+		case "completedAt":
+			masked.CompletedAt = res.CompletedAt
+		case "archivedAt":
+			masked.ArchivedAt = res.ArchivedAt
+		case "snoozedUntil":
+			masked.SnoozedUntil = res.SnoozedUntil
+		case "version":
+			masked.Version = res.Version
+		}
+	}
+	return masked
+}
+
+func ModelStatsToPB(stats *model.TodoStats) *pb.ModelTodoStats {
+	if stats == nil {
+		return nil
+	}
+
+	perDay := []*pb.ModelDayCount{}
+	for _, c := range stats.CompletedPerDay {
+		perDay = append(perDay, &pb.ModelDayCount{Day: c.Day, Count: c.Count})
+	}
+	return &pb.ModelTodoStats{
+		Total:             stats.Total,
+		Active:            stats.Active,
+		Completed:         stats.Completed,
+		CompletedPerDay:   perDay,
+		AvgTimeToComplete: stats.AvgTimeToComplete,
+	}
+}
+
+func PBtoModelStats(stats *pb.ModelTodoStats) *model.TodoStats {
+	if stats == nil {
+		return nil
+	}
+
+	perDay := []*model.DayCount{}
+	for _, c := range stats.CompletedPerDay {
+		perDay = append(perDay, &model.DayCount{Day: c.Day, Count: c.Count})
+	}
+	return &model.TodoStats{
+		Total:             stats.Total,
+		Active:            stats.Active,
+		Completed:         stats.Completed,
+		CompletedPerDay:   perDay,
+		AvgTimeToComplete: stats.AvgTimeToComplete,
+	}
+}
+
+func ModelUsageToPB(usage *model.Usage) *pb.ModelUsage {
+	if usage == nil {
+		return nil
+	}
+
+	return &pb.ModelUsage{
+		Todos:       usage.Todos,
+		TenantTodos: usage.TenantTodos,
+		Quota: &pb.ModelQuota{
+			Todos:       usage.Quota.Todos,
+			TenantTodos: usage.Quota.TenantTodos,
+			TextLength:  int32(usage.Quota.TextLength),
+		},
+	}
+}
+
+func PBtoModelUsage(usage *pb.ModelUsage) *model.Usage {
+	if usage == nil {
+		return nil
+	}
+
+	res := &model.Usage{
+		Todos:       usage.Todos,
+		TenantTodos: usage.TenantTodos,
+	}
+	if q := usage.Quota; q != nil {
+		res.Quota = model.Quota{
+			Todos:       q.Todos,
+			TenantTodos: q.TenantTodos,
+			TextLength:  int(q.TextLength),
+		}
+	}
+	return res
+}
+
+func ModelEventToPB(ev *model.TodoEvent) *pb.TodoEvent {
+	return &pb.TodoEvent{
+		Seq:  ev.Seq,
+		Type: ev.Type,
+		Todo: ModelResToPB(ev.Todo),
+		Time: ev.Time.Format(time.RFC3339),
+	}
+}
+
+func PBtoModelEvent(ev *pb.TodoEvent) *model.TodoEvent {
+	return &model.TodoEvent{
+		Seq:  ev.Seq,
+		Type: ev.Type,
+		Todo: PBtoModelRes(ev.Todo),
+		Time: func() time.Time {
+			t, _ := time.Parse(time.RFC3339, ev.Time)
+			return t
+		}(),
+	}
+}
+
+func ModelChangesToPB(changes *model.Changes) *pb.ModelChanges {
+	if changes == nil {
+		return nil
+	}
+
+	res := &pb.ModelChanges{Token: changes.Token, More: changes.More}
+	for _, todo := range changes.Todos {
+		res.Todos = append(res.Todos, ModelResToPB(todo))
+	}
+	for _, t := range changes.Deleted {
+		res.Deleted = append(res.Deleted, &pb.ModelTombstone{
+			Id:        t.ID,
+			Version:   t.Version,
+			DeletedAt: t.DeletedAt.Format(time.RFC3339),
+		})
+	}
+	return res
+}
+
+func PBtoModelChanges(changes *pb.ModelChanges) *model.Changes {
+	if changes == nil {
+		return nil
+	}
+
+	res := &model.Changes{Todos: []*model.TodoRes{}, Deleted: []*model.Tombstone{}, Token: changes.Token, More: changes.More}
+	for _, todo := range changes.Todos {
+		res.Todos = append(res.Todos, PBtoModelRes(todo))
+	}
+	for _, t := range changes.Deleted {
+		deletedAt, _ := time.Parse(time.RFC3339, t.DeletedAt)
+		res.Deleted = append(res.Deleted, &model.Tombstone{ID: t.Id, Version: t.Version, DeletedAt: deletedAt})
+	}
+	return res
+}
+
+func ModelMutationToPB(m *model.Mutation) *pb.ModelMutation {
+	res := &pb.ModelMutation{
+		Op:        m.Op,
+		Id:        m.ID,
+		Version:   m.Version,
+		UpdatedAt: formatTime(m.UpdatedAt),
+	}
+	if m.Todo != nil {
+		res.Todo = &pb.ModelTodoReq{}
+		if m.Todo.Text != nil {
+			res.Todo.Text = *m.Todo.Text
+		}
+		if m.Todo.Completed != nil {
+			res.Todo.Completed = *m.Todo.Completed
+		}
+	}
+	return res
+}
+
+func PBtoModelMutation(m *pb.ModelMutation) (*model.Mutation, error) {
+	updatedAt, err := parseTime(m.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	res := &model.Mutation{
+		Op:        m.Op,
+		ID:        m.Id,
+		Version:   m.Version,
+		UpdatedAt: updatedAt,
+	}
+	if m.Todo != nil {
+		res.Todo = PBtoModelReq(m.Todo)
+	}
+	return res, nil
+}
+
+func ModelMutationResultToPB(r *model.MutationResult) *pb.ModelMutationResult {
+	res := &pb.ModelMutationResult{Id: r.ID, Status: r.Status, Error: r.Error}
+	if r.Todo != nil {
+		res.Todo = ModelResToPB(r.Todo)
+	}
+	return res
+}
+
+func PBtoModelMutationResult(r *pb.ModelMutationResult) *model.MutationResult {
+	res := &model.MutationResult{ID: r.Id, Status: r.Status, Error: r.Error}
+	if r.Todo != nil {
+		res.Todo = PBtoModelRes(r.Todo)
+	}
+	return res
+}
+
+func ModelShareReqToPB(share *model.ShareReq) *pb.ModelShareReq {
+	return &pb.ModelShareReq{
+		TodoId:    share.TodoID,
+		GranteeId: share.GranteeID,
+		Role:      share.Role,
+	}
+}
+
+func PBtoModelShareReq(share *pb.ModelShareReq) *model.ShareReq {
+	if share == nil {
+		return nil
+	}
+	return &model.ShareReq{
+		TodoID:    share.TodoId,
+		GranteeID: share.GranteeId,
+		Role:      share.Role,
+	}
+}
+
+func ModelShareToPB(share *model.Share) *pb.ModelShare {
+	if share == nil {
+		return nil
+	}
+	return &pb.ModelShare{
+		Id:        share.ID,
+		OwnerId:   share.OwnerID,
+		TenantId:  share.TenantID,
+		TodoId:    share.TodoID,
+		GranteeId: share.GranteeID,
+		Role:      share.Role,
+		CreatedAt: share.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+func PBtoModelShare(share *pb.ModelShare) *model.Share {
+	if share == nil {
+		return nil
+	}
+	createdAt, _ := time.Parse(time.RFC3339, share.CreatedAt)
+	return &model.Share{
+		ID:        share.Id,
+		OwnerID:   share.OwnerId,
+		TenantID:  share.TenantId,
+		TodoID:    share.TodoId,
+		GranteeID: share.GranteeId,
+		Role:      share.Role,
+		CreatedAt: createdAt,
+	}
+}
+
+func ModelAPIKeyReqToPB(key *model.APIKeyReq) *pb.ModelAPIKeyReq {
+	return &pb.ModelAPIKeyReq{
+		Name:      key.Name,
+		Subject:   key.Subject,
+		Scopes:    key.Scopes,
+		ExpiresAt: formatTime(key.ExpiresAt),
+	}
+}
+
+func PBtoModelAPIKeyReq(key *pb.ModelAPIKeyReq) (*model.APIKeyReq, error) {
+	if key == nil {
+		return nil, nil
+	}
+	expiresAt, err := parseTime(key.ExpiresAt)
+	if err != nil {
+		return nil, err
+	}
+	return &model.APIKeyReq{
+		Name:      key.Name,
+		Subject:   key.Subject,
+		Scopes:    key.Scopes,
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+func ModelAPIKeyToPB(key *model.APIKey) *pb.ModelAPIKey {
+	if key == nil {
+		return nil
+	}
+	return &pb.ModelAPIKey{
+		Id:         key.ID,
+		OwnerId:    key.OwnerID,
+		TenantId:   key.TenantID,
+		Name:       key.Name,
+		Subject:    key.Subject,
+		Scopes:     key.Scopes,
+		ExpiresAt:  formatTime(key.ExpiresAt),
+		LastUsedAt: formatTime(key.LastUsedAt),
+		CreatedAt:  key.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+func PBtoModelAPIKey(key *pb.ModelAPIKey) *model.APIKey {
+	if key == nil {
+		return nil
+	}
+	createdAt, _ := time.Parse(time.RFC3339, key.CreatedAt)
+	expiresAt, _ := parseTime(key.ExpiresAt)
+	lastUsedAt, _ := parseTime(key.LastUsedAt)
+	return &model.APIKey{
+		ID:         key.Id,
+		OwnerID:    key.OwnerId,
+		TenantID:   key.TenantId,
+		Name:       key.Name,
+		Subject:    key.Subject,
+		Scopes:     key.Scopes,
+		ExpiresAt:  expiresAt,
+		LastUsedAt: lastUsedAt,
+		CreatedAt:  createdAt,
+	}
+}
+
+// ModelCreatedAPIKeyToPB converts a new API key, along with the key itself.
+func ModelCreatedAPIKeyToPB(key *model.CreatedAPIKey) *pb.ModelAPIKey {
+	if key == nil {
+		return nil
+	}
+	res := ModelAPIKeyToPB(&key.APIKey)
+	res.Key = key.Key
+	return res
+}
+
+func PBtoModelCreatedAPIKey(key *pb.ModelAPIKey) *model.CreatedAPIKey {
+	if key == nil {
+		return nil
 	}
+	return &model.CreatedAPIKey{APIKey: *PBtoModelAPIKey(key), Key: key.Key}
 }
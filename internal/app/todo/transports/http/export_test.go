@@ -0,0 +1,6 @@
+// +build !integration
+
+package transports
+
+// NewHTTPMux exposes the routes of NewHTTPHandler to the tests.
+var NewHTTPMux = newHTTPMux
@@ -0,0 +1,141 @@
+package transports
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-zoo/bone"
+	"github.com/golang/protobuf/proto"
+	"github.com/grpc-ecosystem/grpc-gateway/runtime"
+	stdopentracing "github.com/opentracing/opentracing-go"
+	stdzipkin "github.com/openzipkin/zipkin-go"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/status"
+
+	"github.com/cage1016/gokit-todo/internal/app/todo/endpoints"
+	"github.com/cage1016/gokit-todo/internal/app/todo/service"
+	transportsgrpc "github.com/cage1016/gokit-todo/internal/app/todo/transports/grpc"
+	"github.com/cage1016/gokit-todo/internal/pkg/authn"
+	"github.com/cage1016/gokit-todo/internal/pkg/errors"
+	"github.com/cage1016/gokit-todo/internal/pkg/instrumenting"
+	"github.com/cage1016/gokit-todo/internal/pkg/responses"
+	pb "github.com/cage1016/gokit-todo/pb/todo"
+)
+
+// GatewayPrefix is the path the HTTP bindings of todo.proto are served under,
+// alongside the REST API.
+const GatewayPrefix = "/v1"
+
+// GatewayHandler serves the HTTP bindings of todo.proto under GatewayPrefix,
+// the requests are run by the gRPC server. The responses are those of the REST
+// API: the same status codes, the res of the gRPC responses in a DataRes and
+// the errors in an ErrorRes.
+func GatewayHandler(m *bone.Mux, endpoints endpoints.Endpoints, otTracer stdopentracing.Tracer, zipkinTracer *stdzipkin.Tracer, logger log.Logger) {
+	gw := runtime.NewServeMux(
+		// the field names and the zero values are written as the REST API does
+		runtime.WithMarshalerOption(runtime.MIMEWildcard, gatewayMarshaler{&runtime.JSONPb{EmitDefaults: true}}),
+		runtime.WithIncomingHeaderMatcher(gatewayHeader),
+		runtime.WithForwardResponseOption(gatewayStatus),
+		runtime.WithProtoErrorHandler(gatewayError),
+	)
+	// the gRPC server is called in process, the gRPC port may be disabled
+	server := transportsgrpc.MakeGRPCServer(endpoints, otTracer, zipkinTracer, logger)
+	if err := pb.RegisterTodoHandlerServer(context.Background(), gw, server); err != nil {
+		panic(err)
+	}
+	m.Handle(GatewayPrefix+"/*", http.StripPrefix(GatewayPrefix, gatewayTransport(gw)))
+}
+
+// gatewayTransport labels the requests of h with the Gateway transport,
+// before the gRPC server labels them with its own.
+func gatewayTransport(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h.ServeHTTP(w, r.WithContext(instrumenting.NewContext(r.Context(), instrumenting.Gateway)))
+	})
+}
+
+// gatewayHeader forwards the API key header to the gRPC server along with
+// the headers the gateway forwards by default.
+func gatewayHeader(key string) (string, bool) {
+	if http.CanonicalHeaderKey(key) == http.CanonicalHeaderKey(authn.APIKeyHeader) {
+		return strings.ToLower(authn.APIKeyHeader), true
+	}
+	return runtime.DefaultHeaderMatcher(key)
+}
+
+// gatewayMarshaler writes the res of the gRPC responses in a DataRes, the
+// responses without res have no content.
+type gatewayMarshaler struct {
+	*runtime.JSONPb
+}
+
+func (m gatewayMarshaler) Marshal(v interface{}) ([]byte, error) {
+	res := reflect.Indirect(reflect.ValueOf(v)).FieldByName("Res")
+	if !res.IsValid() {
+		return nil, nil
+	}
+	data, err := m.JSONPb.Marshal(res.Interface())
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(responses.DataRes{APIVersion: service.Version, Data: json.RawMessage(data)})
+}
+
+func (m gatewayMarshaler) ContentType() string {
+	return "application/json; charset=utf-8"
+}
+
+// gatewayStatus writes the status code of the REST API for resp, ahead of
+// its body.
+func gatewayStatus(_ context.Context, w http.ResponseWriter, resp proto.Message) error {
+	var code int
+	switch resp.(type) {
+	case *pb.AddResponse:
+		code = endpoints.AddResponse{}.StatusCode()
+	case *pb.DeleteResponse:
+		code = endpoints.DeleteResponse{}.StatusCode()
+	case *pb.AddShareResponse:
+		code = endpoints.AddShareResponse{}.StatusCode()
+	case *pb.DeleteShareResponse:
+		code = endpoints.DeleteShareResponse{}.StatusCode()
+	case *pb.CreateAPIKeyResponse:
+		code = endpoints.CreateAPIKeyResponse{}.StatusCode()
+	case *pb.RevokeAPIKeyResponse:
+		code = endpoints.RevokeAPIKeyResponse{}.StatusCode()
+	default:
+		return nil
+	}
+	w.WriteHeader(code)
+	return nil
+}
+
+// gatewayError writes the status of the gRPC server, or of the gateway, as
+// the REST API writes the error it comes from: the errors of the service keep
+// their status code, and a rejected request tells when to retry.
+func gatewayError(ctx context.Context, _ *runtime.ServeMux, _ runtime.Marshaler, w http.ResponseWriter, _ *http.Request, err error) {
+	s := status.Convert(err)
+	for _, d := range s.Details() {
+		if info, ok := d.(*errdetails.RetryInfo); ok {
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(info.GetRetryDelay().AsDuration().Seconds()))))
+		}
+	}
+
+	// the message of the status lists the errors the service wrapped
+	errs := errors.FromError(s.Message())
+	e := errors.New(errs[len(errs)-1].Message)
+	for i := len(errs) - 2; i >= 0; i-- {
+		e = errors.Wrap(errors.New(errs[i].Message), e)
+	}
+	responses.ErrorEncodeJSONResponse(func(errorVal errors.Error) int {
+		if code := CustomErrorEncoder(errorVal); code != 0 {
+			return code
+		}
+		return responses.HTTPStatusFromCode(s.Code())
+	})(ctx, e, w)
+}
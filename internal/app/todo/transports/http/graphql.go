@@ -0,0 +1,590 @@
+package transports
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	kitjwt "github.com/go-kit/kit/auth/jwt"
+	"github.com/go-kit/kit/endpoint"
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/tracing/opentracing"
+	httptransport "github.com/go-kit/kit/transport/http"
+	"github.com/go-zoo/bone"
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+	stdopentracing "github.com/opentracing/opentracing-go"
+
+	"github.com/cage1016/gokit-todo/internal/app/todo/endpoints"
+	"github.com/cage1016/gokit-todo/internal/app/todo/model"
+	"github.com/cage1016/gokit-todo/internal/app/todo/service"
+	"github.com/cage1016/gokit-todo/internal/pkg/errors"
+)
+
+// MaxGraphQLTodos is the largest number of todos a GraphQL operation lists or
+// changes in all, however many todos and toggleAll fields it has: each field
+// takes what is left after the previous ones, a todos field lists at most its
+// limit out of it, and a field finding none left fails.
+const MaxGraphQLTodos = 100
+
+// graphQLRequest is a GraphQL operation, as sent in the body of a POST request
+// or in the query parameters of a GET request.
+type graphQLRequest struct {
+	Query         string                 `json:"query"`
+	Variables     map[string]interface{} `json:"variables"`
+	OperationName string                 `json:"operationName"`
+
+	method string
+}
+
+// todoBudget is the number of todos left for the fields of a GraphQL operation
+// to list or change, see MaxGraphQLTodos.
+type todoBudget struct {
+	mu   sync.Mutex
+	left int
+}
+
+type todoBudgetKey struct{}
+
+// errTodoBudget is the error of a field finding no todos left in the budget of
+// its operation.
+var errTodoBudget = graphQLError{code: http.StatusBadRequest, msg: fmt.Sprintf("the operation lists or changes more than %d todos", MaxGraphQLTodos)}
+
+// withTodoBudget returns a copy of ctx holding the MaxGraphQLTodos todos of an
+// operation.
+func withTodoBudget(ctx context.Context) context.Context {
+	return context.WithValue(ctx, todoBudgetKey{}, &todoBudget{left: MaxGraphQLTodos})
+}
+
+// takeTodos takes up to n todos, all that are left when n is 0, from the
+// budget of the operation of ctx, or from a whole one when ctx has none, such
+// as when the schema is run outside of makeGraphQLEndpoint, and returns how
+// many it took.
+func takeTodos(ctx context.Context, n int) int {
+	b, ok := ctx.Value(todoBudgetKey{}).(*todoBudget)
+	if !ok {
+		b = &todoBudget{left: MaxGraphQLTodos}
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if n == 0 || n > b.left {
+		n = b.left
+	}
+	b.left -= n
+	return n
+}
+
+// returnTodos gives back to the budget of the operation of ctx the n todos a
+// field took but did not list or change.
+func returnTodos(ctx context.Context, n int) {
+	if b, ok := ctx.Value(todoBudgetKey{}).(*todoBudget); ok {
+		b.mu.Lock()
+		b.left += n
+		b.mu.Unlock()
+	}
+}
+
+// graphQLStream is the response of a subscription, each result is sent to the
+// client as it is produced.
+type graphQLStream <-chan *graphql.Result
+
+// graphQLError is a resolver error along with the HTTP status the REST API
+// replies with for the same error.
+type graphQLError struct {
+	code int
+	msg  string
+}
+
+func (e graphQLError) Error() string {
+	return e.msg
+}
+
+func (e graphQLError) Extensions() map[string]interface{} {
+	return map[string]interface{}{"code": e.code}
+}
+
+// uint64Type holds versions and event sequence numbers, which do not fit the
+// 32 bits of the Int type.
+var uint64Type = graphql.NewScalar(graphql.ScalarConfig{
+	Name:        "Uint64",
+	Description: "An unsigned 64-bit integer, serialized as a string.",
+	Serialize: func(value interface{}) interface{} {
+		if v, ok := value.(uint64); ok {
+			return strconv.FormatUint(v, 10)
+		}
+		return nil
+	},
+	ParseValue: func(value interface{}) interface{} {
+		switch v := value.(type) {
+		case string:
+			return parseUint64(v)
+		case float64:
+			if v >= 0 && v <= 1<<53 && v == math.Trunc(v) {
+				return uint64(v)
+			}
+		}
+		return nil
+	},
+	ParseLiteral: func(value ast.Value) interface{} {
+		switch v := value.(type) {
+		case *ast.StringValue:
+			return parseUint64(v.Value)
+		case *ast.IntValue:
+			return parseUint64(v.Value)
+		}
+		return nil
+	},
+})
+
+func parseUint64(s string) interface{} {
+	v, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		return nil
+	}
+	return v
+}
+
+// NewGraphQLSchema returns the GraphQL schema of the todos, its fields are
+// resolved by the endpoints so that their middlewares apply.
+func NewGraphQLSchema(eps endpoints.Endpoints) (graphql.Schema, error) {
+	todoType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Todo",
+		Fields: graphql.Fields{
+			"id":           &graphql.Field{Type: graphql.NewNonNull(graphql.ID)},
+			"createdAt":    &graphql.Field{Type: graphql.NewNonNull(graphql.DateTime)},
+			"updatedAt":    &graphql.Field{Type: graphql.NewNonNull(graphql.DateTime)},
+			"text":         &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"completed":    &graphql.Field{Type: graphql.NewNonNull(graphql.Boolean)},
+			"completedAt":  &graphql.Field{Type: graphql.DateTime},
+			"archivedAt":   &graphql.Field{Type: graphql.DateTime},
+			"snoozedUntil": &graphql.Field{Type: graphql.DateTime},
+			"version":      &graphql.Field{Type: graphql.NewNonNull(uint64Type)},
+		},
+	})
+	todoList := graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(todoType)))
+
+	eventType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "TodoEvent",
+		Fields: graphql.Fields{
+			"seq":  &graphql.Field{Type: graphql.NewNonNull(uint64Type)},
+			"type": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"todo": &graphql.Field{Type: todoType},
+			"time": &graphql.Field{Type: graphql.NewNonNull(graphql.DateTime)},
+		},
+	})
+
+	query := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"todo": &graphql.Field{
+				Type: todoType,
+				Args: graphql.FieldConfigArgument{
+					"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					req := endpoints.GetRequest{Id: p.Args["id"].(string), Fields: selectedFields(p.Info)}
+					resp, err := eps.GetEndpoint(p.Context, req)
+					if err != nil && errors.Contains(errors.Cast(err), service.ErrNotFound) {
+						return nil, nil
+					}
+					if err != nil {
+						return nil, encodeGraphQLError(err)
+					}
+					return resp.(endpoints.GetResponse).Res, nil
+				},
+			},
+			"todos": &graphql.Field{
+				Type: todoList,
+				Args: graphql.FieldConfigArgument{
+					"status":          &graphql.ArgumentConfig{Type: graphql.String},
+					"sort":            &graphql.ArgumentConfig{Type: graphql.String},
+					"includeArchived": &graphql.ArgumentConfig{Type: graphql.Boolean},
+					"includeSnoozed":  &graphql.ArgumentConfig{Type: graphql.Boolean},
+					"completedAfter":  &graphql.ArgumentConfig{Type: graphql.DateTime},
+					"completedBefore": &graphql.ArgumentConfig{Type: graphql.DateTime},
+					"offset":          &graphql.ArgumentConfig{Type: graphql.Int},
+					"limit":           &graphql.ArgumentConfig{Type: graphql.Int, Description: fmt.Sprintf("Lists at most that many todos, and at most %d along with the other todos and toggleAll fields of the operation.", MaxGraphQLTodos)},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					q := &model.TodoQuery{Fields: selectedFields(p.Info)}
+					q.Status, _ = p.Args["status"].(string)
+					q.Sort, _ = p.Args["sort"].(string)
+					q.IncludeArchived, _ = p.Args["includeArchived"].(bool)
+					q.IncludeSnoozed, _ = p.Args["includeSnoozed"].(bool)
+					q.Offset, _ = p.Args["offset"].(int)
+					limit, _ := p.Args["limit"].(int)
+					if limit < 0 {
+						limit = 0
+					}
+					if q.Limit = takeTodos(p.Context, limit); q.Limit == 0 {
+						return nil, errTodoBudget
+					}
+					if t, ok := p.Args["completedAfter"].(time.Time); ok {
+						q.CompletedAfter = &t
+					}
+					if t, ok := p.Args["completedBefore"].(time.Time); ok {
+						q.CompletedBefore = &t
+					}
+					resp, err := eps.ListEndpoint(p.Context, endpoints.ListRequest{Query: q})
+					if err != nil {
+						returnTodos(p.Context, q.Limit)
+						return nil, encodeGraphQLError(err)
+					}
+					res := resp.(endpoints.ListResponse).Res
+					returnTodos(p.Context, q.Limit-len(res))
+					return res, nil
+				},
+			},
+		},
+	})
+
+	mutation := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Mutation",
+		Fields: graphql.Fields{
+			"addTodo": &graphql.Field{
+				Type: graphql.NewNonNull(todoType),
+				Args: graphql.FieldConfigArgument{
+					"text":      &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+					"completed": &graphql.ArgumentConfig{Type: graphql.Boolean},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					resp, err := eps.AddEndpoint(p.Context, endpoints.AddRequest{Todo: todoReq(p.Args)})
+					if err != nil {
+						return nil, encodeGraphQLError(err)
+					}
+					return resp.(endpoints.AddResponse).Res, nil
+				},
+			},
+			"updateTodo": &graphql.Field{
+				Type: graphql.NewNonNull(todoType),
+				Args: graphql.FieldConfigArgument{
+					"id":        &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)},
+					"text":      &graphql.ArgumentConfig{Type: graphql.String},
+					"completed": &graphql.ArgumentConfig{Type: graphql.Boolean},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					req := endpoints.UpdateRequest{Id: p.Args["id"].(string), Todo: todoReq(p.Args)}
+					resp, err := eps.UpdateEndpoint(p.Context, req)
+					if err != nil {
+						return nil, encodeGraphQLError(err)
+					}
+					return resp.(endpoints.UpdateResponse).Res, nil
+				},
+			},
+			"deleteTodo": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.ID),
+				Args: graphql.FieldConfigArgument{
+					"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					id := p.Args["id"].(string)
+					if _, err := eps.DeleteEndpoint(p.Context, endpoints.DeleteRequest{Id: id}); err != nil {
+						return nil, encodeGraphQLError(err)
+					}
+					return id, nil
+				},
+			},
+			"toggleAll": &graphql.Field{
+				Type:        todoList,
+				Description: fmt.Sprintf("Marks up to %d todos completed or active, along with the other todos and toggleAll fields of the operation, and returns the todos it changed. It is called again until it changes none to mark them all.", MaxGraphQLTodos),
+				Args: graphql.FieldConfigArgument{
+					"completed": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.Boolean)},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					completed := p.Args["completed"].(bool)
+					q := &model.TodoQuery{Fields: []string{"id"}, Status: service.ACTIVE, Limit: takeTodos(p.Context, 0)}
+					if q.Limit == 0 {
+						return nil, errTodoBudget
+					}
+					if !completed {
+						q.Status = service.COMPLETE
+					}
+					resp, err := eps.ListEndpoint(p.Context, endpoints.ListRequest{Query: q})
+					if err != nil {
+						returnTodos(p.Context, q.Limit)
+						return nil, encodeGraphQLError(err)
+					}
+					// the todos it lists are the ones it changes
+					returnTodos(p.Context, q.Limit-len(resp.(endpoints.ListResponse).Res))
+
+					res := []*model.TodoRes{}
+					for _, todo := range resp.(endpoints.ListResponse).Res {
+						req := endpoints.UpdateRequest{Id: todo.ID, Todo: &model.TodoReq{Completed: &completed}}
+						resp, err := eps.UpdateEndpoint(p.Context, req)
+						if err != nil {
+							return nil, encodeGraphQLError(err)
+						}
+						res = append(res, resp.(endpoints.UpdateResponse).Res)
+					}
+					return res, nil
+				},
+			},
+		},
+	})
+
+	subscription := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Subscription",
+		Fields: graphql.Fields{
+			"todoEvents": &graphql.Field{
+				Type: graphql.NewNonNull(eventType),
+				Args: graphql.FieldConfigArgument{
+					"types": &graphql.ArgumentConfig{Type: graphql.NewList(graphql.NewNonNull(graphql.String))},
+					"since": &graphql.ArgumentConfig{Type: uint64Type},
+				},
+				Subscribe: func(p graphql.ResolveParams) (interface{}, error) {
+					q := &model.EventQuery{}
+					types, _ := p.Args["types"].([]interface{})
+					for _, t := range types {
+						q.Types = append(q.Types, t.(string))
+					}
+					q.Since, _ = p.Args["since"].(uint64)
+					resp, err := eps.WatchEndpoint(p.Context, endpoints.WatchRequest{Query: q})
+					if err != nil {
+						return nil, encodeGraphQLError(err)
+					}
+					return graphQLEvents(p.Context, resp.(endpoints.WatchResponse).Events), nil
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source, nil
+				},
+			},
+		},
+	})
+
+	return graphql.NewSchema(graphql.SchemaConfig{
+		Query:        query,
+		Mutation:     mutation,
+		Subscription: subscription,
+	})
+}
+
+// ShowTodo godoc
+// @Summary GraphQL
+// @Description Runs a GraphQL query, mutation or subscription, mutations are only run over POST.
+// @Tags TODO
+// @Accept json
+// @Produce json,text/event-stream
+// @Router /graphql [post]
+func GraphQLHandler(m *bone.Mux, endpoints endpoints.Endpoints, options []httptransport.ServerOption, otTracer stdopentracing.Tracer, logger log.Logger) {
+	schema, err := NewGraphQLSchema(endpoints)
+	if err != nil {
+		panic(err)
+	}
+	h := withFlusher(httptransport.NewServer(
+		makeGraphQLEndpoint(schema),
+		decodeHTTPGraphQLRequest,
+		encodeHTTPGraphQLResponse,
+		append(options, httptransport.ServerBefore(opentracing.HTTPToContext(otTracer, "GraphQL", logger), kitjwt.HTTPToContext(), queryTokenToContext()))...,
+	))
+	m.Get("/graphql", h)
+	m.Post("/graphql", h)
+}
+
+// makeGraphQLEndpoint returns an endpoint running GraphQL operations against
+// schema, subscriptions result in a graphQLStream.
+func makeGraphQLEndpoint(schema graphql.Schema) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(graphQLRequest)
+		params := graphql.Params{
+			Schema:         schema,
+			RequestString:  req.Query,
+			VariableValues: req.Variables,
+			OperationName:  req.OperationName,
+			Context:        withTodoBudget(ctx),
+		}
+
+		switch operationType(req) {
+		case ast.OperationTypeMutation:
+			// GET requests must be safe, they can be sent cross-site
+			if req.method == http.MethodGet {
+				return nil, service.ErrMalformedEntity
+			}
+		case ast.OperationTypeSubscription:
+			return graphQLStream(graphql.Subscribe(params)), nil
+		}
+		return graphql.Do(params), nil
+	}
+}
+
+// operationType returns the type of the operation req runs, it is empty if the
+// query is invalid, the error is reported when the operation runs.
+func operationType(req graphQLRequest) string {
+	doc, err := parser.Parse(parser.ParseParams{Source: req.Query})
+	if err != nil {
+		return ""
+	}
+	for _, def := range doc.Definitions {
+		op, ok := def.(*ast.OperationDefinition)
+		if !ok {
+			continue
+		}
+		if req.OperationName == "" || (op.Name != nil && op.Name.Value == req.OperationName) {
+			return op.Operation
+		}
+	}
+	return ""
+}
+
+// selectedFields returns the todo fields selected by the query, so that only
+// those are loaded. All fields are loaded if the selection uses fragments.
+func selectedFields(info graphql.ResolveInfo) []string {
+	var fields []string
+	for _, f := range info.FieldASTs {
+		if f.SelectionSet == nil {
+			continue
+		}
+		for _, s := range f.SelectionSet.Selections {
+			field, ok := s.(*ast.Field)
+			if !ok {
+				return nil
+			}
+			if _, ok := model.TodoFields[field.Name.Value]; ok {
+				fields = append(fields, field.Name.Value)
+			}
+		}
+	}
+	return fields
+}
+
+// todoReq returns the todo request made of the text and completed arguments.
+func todoReq(args map[string]interface{}) *model.TodoReq {
+	req := &model.TodoReq{}
+	if text, ok := args["text"].(string); ok {
+		req.Text = &text
+	}
+	if completed, ok := args["completed"].(bool); ok {
+		req.Completed = &completed
+	}
+	return req
+}
+
+// graphQLEvents passes the todo events to a subscription until ctx is done.
+func graphQLEvents(ctx context.Context, events <-chan *model.TodoEvent) chan interface{} {
+	out := make(chan interface{})
+	go func() {
+		defer close(out)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case ev, ok := <-events:
+				if !ok {
+					return
+				}
+				select {
+				case out <- ev:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return out
+}
+
+// encodeGraphQLError maps err to its HTTP status, as CustomErrorEncoder does.
+func encodeGraphQLError(err error) error {
+	code := CustomErrorEncoder(errors.Cast(err))
+	if code == 0 {
+		return graphQLError{code: http.StatusInternalServerError, msg: http.StatusText(http.StatusInternalServerError)}
+	}
+	return graphQLError{code: code, msg: err.Error()}
+}
+
+// decodeHTTPGraphQLRequest is a transport/http.DecodeRequestFunc that decodes a
+// GraphQL operation from the JSON-encoded body of a POST request, or from the
+// query parameters of a GET request. Primarily useful in a server.
+func decodeHTTPGraphQLRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	req := graphQLRequest{method: r.Method}
+	if r.Method == http.MethodGet {
+		q := r.URL.Query()
+		req.Query, req.OperationName = q.Get("query"), q.Get("operationName")
+		if v := q.Get("variables"); v != "" {
+			if err := json.Unmarshal([]byte(v), &req.Variables); err != nil {
+				return nil, errors.Wrap(service.ErrMalformedEntity, err)
+			}
+		}
+	} else if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, errors.Wrap(service.ErrMalformedEntity, err)
+	}
+	if req.Query == "" {
+		return nil, service.ErrMalformedEntity
+	}
+	return req, nil
+}
+
+// encodeHTTPGraphQLResponse is a transport/http.EncodeResponseFunc that writes
+// the result of a query or a mutation as JSON, and the results of a
+// subscription as text/event-stream.
+func encodeHTTPGraphQLResponse(ctx context.Context, w http.ResponseWriter, response interface{}) error {
+	if stream, ok := response.(graphQLStream); ok {
+		return encodeHTTPGraphQLStream(ctx, w, stream)
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	return json.NewEncoder(w).Encode(response)
+}
+
+// encodeHTTPGraphQLStream writes each result of a subscription as a next event,
+// followed by a complete event once the subscription ends. It ends without one
+// once ctx is done or the streams end, see WithStreamContext.
+func encodeHTTPGraphQLStream(ctx context.Context, w http.ResponseWriter, stream graphQLStream) error {
+	// the subscription blocks until its results are read, it ends along
+	// with the request
+	defer func() {
+		go func() {
+			for range stream {
+			}
+		}()
+	}()
+
+	flusher, ok := ctx.Value(flusherKey{}).(http.Flusher)
+	if !ok {
+		return errors.New("streaming unsupported")
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no") // disable the nginx ingress buffering
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(HeartbeatInterval)
+	defer heartbeat.Stop()
+
+	streams := streamsDone(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-streams:
+			// the client subscribes again to another server, without a
+			// complete event
+			return nil
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": heartbeat\n\n"); err != nil {
+				return err
+			}
+		case res, ok := <-stream:
+			if !ok {
+				_, err := fmt.Fprint(w, "event: complete\ndata:\n\n")
+				flusher.Flush()
+				return err
+			}
+			data, err := json.Marshal(res)
+			if err != nil {
+				return err
+			}
+			if _, err := fmt.Fprintf(w, "event: next\ndata: %s\n\n", data); err != nil {
+				return err
+			}
+		}
+		flusher.Flush()
+	}
+}
@@ -1,11 +1,18 @@
 package transports
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"io/ioutil"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
 
 	kitjwt "github.com/go-kit/kit/auth/jwt"
+	"github.com/go-kit/kit/endpoint"
 	"github.com/go-kit/kit/log"
 	"github.com/go-kit/kit/tracing/opentracing"
 	"github.com/go-kit/kit/tracing/zipkin"
@@ -16,8 +23,13 @@ import (
 	"github.com/rs/cors"
 
 	"github.com/cage1016/gokit-todo/internal/app/todo/endpoints"
+	"github.com/cage1016/gokit-todo/internal/app/todo/model"
 	"github.com/cage1016/gokit-todo/internal/app/todo/service"
+	"github.com/cage1016/gokit-todo/internal/pkg/authn"
+	"github.com/cage1016/gokit-todo/internal/pkg/authz"
 	"github.com/cage1016/gokit-todo/internal/pkg/errors"
+	"github.com/cage1016/gokit-todo/internal/pkg/instrumenting"
+	"github.com/cage1016/gokit-todo/internal/pkg/ratelimit"
 	"github.com/cage1016/gokit-todo/internal/pkg/responses"
 )
 
@@ -27,7 +39,7 @@ const (
 
 // ShowTodo godoc
 // @Summary Add
-// @Description TODO
+// @Description Creates a todo from its text, it is active unless completed is set.
 // @Tags TODO
 // @Accept json
 // @Produce json
@@ -43,7 +55,7 @@ func AddHandler(m *bone.Mux, endpoints endpoints.Endpoints, options []httptransp
 
 // ShowTodo godoc
 // @Summary Delete
-// @Description TODO
+// @Description Deletes a todo, offline clients learn about it from the tombstones of a sync.
 // @Tags TODO
 // @Accept json
 // @Produce json
@@ -59,11 +71,11 @@ func DeleteHandler(m *bone.Mux, endpoints endpoints.Endpoints, options []httptra
 
 // ShowTodo godoc
 // @Summary Update
-// @Description TODO
+// @Description Updates the text or the completion of a todo, the fields left out are kept.
 // @Tags TODO
 // @Accept json
 // @Produce json
-// @Router /items/:id [put]
+// @Router /items/:id [patch]
 func UpdateHandler(m *bone.Mux, endpoints endpoints.Endpoints, options []httptransport.ServerOption, otTracer stdopentracing.Tracer, logger log.Logger) {
 	m.Patch("/items/:id", httptransport.NewServer(
 		endpoints.UpdateEndpoint,
@@ -75,7 +87,7 @@ func UpdateHandler(m *bone.Mux, endpoints endpoints.Endpoints, options []httptra
 
 // ShowTodo godoc
 // @Summary List
-// @Description TODO
+// @Description Lists the todos of the caller, or the ones shared with them, archived and snoozed todos are left out unless asked for.
 // @Tags TODO
 // @Accept json
 // @Produce json
@@ -89,9 +101,276 @@ func ListHandler(m *bone.Mux, endpoints endpoints.Endpoints, options []httptrans
 	))
 }
 
+// ShowTodo godoc
+// @Summary Get
+// @Description Returns a todo, archived todos included.
+// @Tags TODO
+// @Accept json
+// @Produce json
+// @Router /items/:id [get]
+func GetHandler(m *bone.Mux, endpoints endpoints.Endpoints, options []httptransport.ServerOption, otTracer stdopentracing.Tracer, logger log.Logger) {
+	m.Get("/items/:id", httptransport.NewServer(
+		endpoints.GetEndpoint,
+		decodeHTTPGetRequest,
+		responses.EncodeJSONResponse,
+		append(options, httptransport.ServerBefore(opentracing.HTTPToContext(otTracer, "Get", logger), kitjwt.HTTPToContext()))...,
+	))
+}
+
+// ShowTodo godoc
+// @Summary Stats
+// @Description Returns the number of todos by status and the completions of the last days.
+// @Tags TODO
+// @Accept json
+// @Produce json
+// @Router /stats [get]
+func StatsHandler(m *bone.Mux, endpoints endpoints.Endpoints, options []httptransport.ServerOption, otTracer stdopentracing.Tracer, logger log.Logger) {
+	m.Get("/stats", httptransport.NewServer(
+		endpoints.StatsEndpoint,
+		decodeHTTPStatsRequest,
+		responses.EncodeJSONResponse,
+		append(options, httptransport.ServerBefore(opentracing.HTTPToContext(otTracer, "Stats", logger), kitjwt.HTTPToContext()))...,
+	))
+}
+
+// ShowTodo godoc
+// @Summary Unarchive
+// @Description Brings an archived todo back to the listings.
+// @Tags TODO
+// @Accept json
+// @Produce json
+// @Router /items/:id/unarchive [post]
+func UnarchiveHandler(m *bone.Mux, endpoints endpoints.Endpoints, options []httptransport.ServerOption, otTracer stdopentracing.Tracer, logger log.Logger) {
+	m.Post("/items/:id/unarchive", httptransport.NewServer(
+		endpoints.UnarchiveEndpoint,
+		decodeHTTPUnarchiveRequest,
+		responses.EncodeJSONResponse,
+		append(options, httptransport.ServerBefore(opentracing.HTTPToContext(otTracer, "Unarchive", logger), kitjwt.HTTPToContext()))...,
+	))
+}
+
+// ShowTodo godoc
+// @Summary Snooze
+// @Description Hides a todo from the listings until the given time, or shows it again without one.
+// @Tags TODO
+// @Accept json
+// @Produce json
+// @Router /items/:id/snooze [post]
+func SnoozeHandler(m *bone.Mux, endpoints endpoints.Endpoints, options []httptransport.ServerOption, otTracer stdopentracing.Tracer, logger log.Logger) {
+	m.Post("/items/:id/snooze", httptransport.NewServer(
+		endpoints.SnoozeEndpoint,
+		decodeHTTPSnoozeRequest,
+		responses.EncodeJSONResponse,
+		append(options, httptransport.ServerBefore(opentracing.HTTPToContext(otTracer, "Snooze", logger), kitjwt.HTTPToContext()))...,
+	))
+}
+
+// ShowTodo godoc
+// @Summary Sync
+// @Description Returns the changes made to the todos of the caller since a sync token, and the token of the next sync. The todos shared with the caller are left out.
+// @Tags TODO
+// @Accept json
+// @Produce json
+// @Router /sync [get]
+func SyncHandler(m *bone.Mux, endpoints endpoints.Endpoints, options []httptransport.ServerOption, otTracer stdopentracing.Tracer, logger log.Logger) {
+	m.Get("/sync", httptransport.NewServer(
+		endpoints.SyncEndpoint,
+		decodeHTTPSyncRequest,
+		responses.EncodeJSONResponse,
+		append(options, httptransport.ServerBefore(opentracing.HTTPToContext(otTracer, "Sync", logger), kitjwt.HTTPToContext()))...,
+	))
+}
+
+// ShowTodo godoc
+// @Summary Push
+// @Description Applies the mutations made by a client while offline, one by one and in order, and returns the result of each: applied, conflict or rejected. A server error fails the push while the mutations before it stay applied, they conflict with their own result when pushed again.
+// @Tags TODO
+// @Accept json
+// @Produce json
+// @Router /sync [post]
+func PushHandler(m *bone.Mux, endpoints endpoints.Endpoints, options []httptransport.ServerOption, otTracer stdopentracing.Tracer, logger log.Logger) {
+	m.Post("/sync", httptransport.NewServer(
+		endpoints.PushEndpoint,
+		decodeHTTPPushRequest,
+		responses.EncodeJSONResponse,
+		append(options, httptransport.ServerBefore(opentracing.HTTPToContext(otTracer, "Push", logger), kitjwt.HTTPToContext()))...,
+	))
+}
+
+// ShowTodo godoc
+// @Summary AddShare
+// @Description Shares a todo, or all the todos of the caller when todoId is left out, with a user of their tenant as a viewer or an editor.
+// @Tags TODO
+// @Accept json
+// @Produce json
+// @Router /shares [post]
+func AddShareHandler(m *bone.Mux, endpoints endpoints.Endpoints, options []httptransport.ServerOption, otTracer stdopentracing.Tracer, logger log.Logger) {
+	m.Post("/shares", httptransport.NewServer(
+		endpoints.AddShareEndpoint,
+		decodeHTTPAddShareRequest,
+		responses.EncodeJSONResponse,
+		append(options, httptransport.ServerBefore(opentracing.HTTPToContext(otTracer, "AddShare", logger), kitjwt.HTTPToContext()))...,
+	))
+}
+
+// ShowTodo godoc
+// @Summary DeleteShare
+// @Description Revokes a share of the caller.
+// @Tags TODO
+// @Accept json
+// @Produce json
+// @Router /shares/:id [delete]
+func DeleteShareHandler(m *bone.Mux, endpoints endpoints.Endpoints, options []httptransport.ServerOption, otTracer stdopentracing.Tracer, logger log.Logger) {
+	m.Delete("/shares/:id", httptransport.NewServer(
+		endpoints.DeleteShareEndpoint,
+		decodeHTTPDeleteShareRequest,
+		responses.EncodeJSONResponse,
+		append(options, httptransport.ServerBefore(opentracing.HTTPToContext(otTracer, "DeleteShare", logger), kitjwt.HTTPToContext()))...,
+	))
+}
+
+// ShowTodo godoc
+// @Summary ListShares
+// @Description Lists the shares made by the caller and the ones made with them.
+// @Tags TODO
+// @Accept json
+// @Produce json
+// @Router /shares [get]
+func ListSharesHandler(m *bone.Mux, endpoints endpoints.Endpoints, options []httptransport.ServerOption, otTracer stdopentracing.Tracer, logger log.Logger) {
+	m.Get("/shares", httptransport.NewServer(
+		endpoints.ListSharesEndpoint,
+		decodeHTTPListSharesRequest,
+		responses.EncodeJSONResponse,
+		append(options, httptransport.ServerBefore(opentracing.HTTPToContext(otTracer, "ListShares", logger), kitjwt.HTTPToContext()))...,
+	))
+}
+
+// ShowTodo godoc
+// @Summary CreateAPIKey
+// @Description Creates an API key authenticating a service-to-service caller, the key is only returned once.
+// @Tags TODO
+// @Accept json
+// @Produce json
+// @Router /admin/api-keys [post]
+func CreateAPIKeyHandler(m *bone.Mux, endpoints endpoints.Endpoints, options []httptransport.ServerOption, otTracer stdopentracing.Tracer, logger log.Logger) {
+	m.Post("/admin/api-keys", httptransport.NewServer(
+		endpoints.CreateAPIKeyEndpoint,
+		decodeHTTPCreateAPIKeyRequest,
+		responses.EncodeJSONResponse,
+		append(options, httptransport.ServerBefore(opentracing.HTTPToContext(otTracer, "CreateAPIKey", logger), kitjwt.HTTPToContext()))...,
+	))
+}
+
+// ShowTodo godoc
+// @Summary RevokeAPIKey
+// @Description Revokes an API key of the tenant of the caller.
+// @Tags TODO
+// @Accept json
+// @Produce json
+// @Router /admin/api-keys/:id [delete]
+func RevokeAPIKeyHandler(m *bone.Mux, endpoints endpoints.Endpoints, options []httptransport.ServerOption, otTracer stdopentracing.Tracer, logger log.Logger) {
+	m.Delete("/admin/api-keys/:id", httptransport.NewServer(
+		endpoints.RevokeAPIKeyEndpoint,
+		decodeHTTPRevokeAPIKeyRequest,
+		responses.EncodeJSONResponse,
+		append(options, httptransport.ServerBefore(opentracing.HTTPToContext(otTracer, "RevokeAPIKey", logger), kitjwt.HTTPToContext()))...,
+	))
+}
+
+// ShowTodo godoc
+// @Summary ListAPIKeys
+// @Description Lists the API keys of the tenant of the caller, without the keys themselves.
+// @Tags TODO
+// @Accept json
+// @Produce json
+// @Router /admin/api-keys [get]
+func ListAPIKeysHandler(m *bone.Mux, endpoints endpoints.Endpoints, options []httptransport.ServerOption, otTracer stdopentracing.Tracer, logger log.Logger) {
+	m.Get("/admin/api-keys", httptransport.NewServer(
+		endpoints.ListAPIKeysEndpoint,
+		decodeHTTPListAPIKeysRequest,
+		responses.EncodeJSONResponse,
+		append(options, httptransport.ServerBefore(opentracing.HTTPToContext(otTracer, "ListAPIKeys", logger), kitjwt.HTTPToContext()))...,
+	))
+}
+
+// ShowTodo godoc
+// @Summary Usage
+// @Description Returns the todos of the caller and of its tenant against their quotas.
+// @Tags TODO
+// @Accept json
+// @Produce json
+// @Router /usage [get]
+func UsageHandler(m *bone.Mux, endpoints endpoints.Endpoints, options []httptransport.ServerOption, otTracer stdopentracing.Tracer, logger log.Logger) {
+	m.Get("/usage", httptransport.NewServer(
+		endpoints.UsageEndpoint,
+		decodeHTTPUsageRequest,
+		responses.EncodeJSONResponse,
+		append(options, httptransport.ServerBefore(opentracing.HTTPToContext(otTracer, "Usage", logger), kitjwt.HTTPToContext()))...,
+	))
+}
+
+// ShowTodo godoc
+// @Summary Events
+// @Description Streams the changes of the todos of the caller as server-sent events, the id of each event resumes the stream after it. The todos shared with the caller are left out, GET /items?shared=true lists them.
+// @Tags TODO
+// @Produce text/event-stream
+// @Router /items/events [get]
+func EventsHandler(m *bone.Mux, endpoints endpoints.Endpoints, options []httptransport.ServerOption, otTracer stdopentracing.Tracer, logger log.Logger) {
+	m.Get("/items/events", withFlusher(httptransport.NewServer(
+		resumeOrReset(endpoints.WatchEndpoint),
+		decodeHTTPWatchRequest,
+		encodeHTTPEventStream,
+		append(options, httptransport.ServerBefore(opentracing.HTTPToContext(otTracer, "Watch", logger), kitjwt.HTTPToContext(), queryTokenToContext()))...,
+	)))
+}
+
+// handlerOptions are the optional settings of NewHTTPHandler.
+type handlerOptions struct {
+	streams context.Context
+	origins []string
+}
+
+func newHandlerOptions(opts []Option) (o handlerOptions) {
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// Option sets an optional setting of NewHTTPHandler.
+type Option func(*handlerOptions)
+
+// WithStreamContext makes the event streams, over server-sent events and
+// WebSocket, end once ctx is done, such as when the server shuts down. The
+// other requests are left to complete.
+func WithStreamContext(ctx context.Context) Option {
+	return func(o *handlerOptions) {
+		o.streams = ctx
+	}
+}
+
+// WithAllowedOrigins makes the WebSocket accept the upgrades from the pages
+// of origins, such as https://todo.example.com, along with the same-origin
+// ones. The origin "*" allows every page.
+func WithAllowedOrigins(origins ...string) Option {
+	return func(o *handlerOptions) {
+		o.origins = origins
+	}
+}
+
 // NewHTTPHandler returns a handler that makes a set of endpoints available on
 // predefined paths.
-func NewHTTPHandler(endpoints endpoints.Endpoints, otTracer stdopentracing.Tracer, zipkinTracer *stdzipkin.Tracer, logger log.Logger) http.Handler { // Zipkin HTTP Server Trace can either be instantiated per endpoint with a
+func NewHTTPHandler(endpoints endpoints.Endpoints, otTracer stdopentracing.Tracer, zipkinTracer *stdzipkin.Tracer, logger log.Logger, opts ...Option) http.Handler {
+	o := newHandlerOptions(opts)
+	var h http.Handler = newHTTPMux(endpoints, otTracer, zipkinTracer, logger, opts...)
+	if o.streams != nil {
+		h = withStreams(o.streams, h)
+	}
+	return cors.AllowAll().Handler(h)
+}
+
+// newHTTPMux routes the paths of NewHTTPHandler.
+func newHTTPMux(endpoints endpoints.Endpoints, otTracer stdopentracing.Tracer, zipkinTracer *stdzipkin.Tracer, logger log.Logger, opts ...Option) *bone.Mux { // Zipkin HTTP Server Trace can either be instantiated per endpoint with a
 	// provided operation name or a global tracing service can be instantiated
 	// without an operation name and fed to each Go kit endpoint as ServerOption.
 	// In the latter case, the operation name will be the endpoint's http method.
@@ -101,15 +380,35 @@ func NewHTTPHandler(endpoints endpoints.Endpoints, otTracer stdopentracing.Trace
 	options := []httptransport.ServerOption{
 		httptransport.ServerErrorEncoder(responses.ErrorEncodeJSONResponse(CustomErrorEncoder)),
 		httptransport.ServerErrorLogger(logger),
+		httptransport.ServerBefore(authn.HTTPAPIKeyToContext(), ratelimit.HTTPClientToContext(), instrumenting.HTTPToContext(instrumenting.HTTP)),
 		zipkinServer,
 	}
 
+	o := newHandlerOptions(opts)
 	m := bone.New()
 	AddHandler(m, endpoints, options, otTracer, logger)
+	EventsHandler(m, endpoints, options, otTracer, logger)
+	WebSocketHandler(m, endpoints, o.origins, otTracer, logger)
 	DeleteHandler(m, endpoints, options, otTracer, logger)
 	UpdateHandler(m, endpoints, options, otTracer, logger)
 	ListHandler(m, endpoints, options, otTracer, logger)
-	return cors.AllowAll().Handler(m)
+	GetHandler(m, endpoints, options, otTracer, logger)
+	StatsHandler(m, endpoints, options, otTracer, logger)
+	UnarchiveHandler(m, endpoints, options, otTracer, logger)
+	SnoozeHandler(m, endpoints, options, otTracer, logger)
+	SyncHandler(m, endpoints, options, otTracer, logger)
+	PushHandler(m, endpoints, options, otTracer, logger)
+	AddShareHandler(m, endpoints, options, otTracer, logger)
+	DeleteShareHandler(m, endpoints, options, otTracer, logger)
+	ListSharesHandler(m, endpoints, options, otTracer, logger)
+	CreateAPIKeyHandler(m, endpoints, options, otTracer, logger)
+	RevokeAPIKeyHandler(m, endpoints, options, otTracer, logger)
+	ListAPIKeysHandler(m, endpoints, options, otTracer, logger)
+	UsageHandler(m, endpoints, options, otTracer, logger)
+	GraphQLHandler(m, endpoints, options, otTracer, logger)
+	GatewayHandler(m, endpoints, otTracer, zipkinTracer, logger)
+	OpenAPIHandler(m)
+	return m
 }
 
 // decodeHTTPAddRequest is a transport/http.DecodeRequestFunc that decodes a
@@ -141,16 +440,856 @@ func decodeHTTPUpdateRequest(_ context.Context, r *http.Request) (interface{}, e
 // JSON-encoded request from the HTTP request body. Primarily useful in a server.
 func decodeHTTPListRequest(_ context.Context, r *http.Request) (interface{}, error) {
 	var req endpoints.ListRequest
+	req.Query = &model.TodoQuery{
+		Fields: parseFields(r),
+		Sort:   r.URL.Query().Get("sort"),
+		Status: r.URL.Query().Get("status"),
+	}
+
+	for _, include := range strings.Split(r.URL.Query().Get("include"), ",") {
+		switch strings.TrimSpace(include) {
+		case "":
+		case "archived":
+			req.Query.IncludeArchived = true
+		case "snoozed":
+			req.Query.IncludeSnoozed = true
+		default:
+			return nil, service.ErrInvalidQueryParams
+		}
+	}
+
+	var err error
+	if req.Query.CompletedAfter, err = parseTime(r, "completedAfter"); err != nil {
+		return nil, err
+	}
+	if req.Query.CompletedBefore, err = parseTime(r, "completedBefore"); err != nil {
+		return nil, err
+	}
+	if req.Query.Offset, err = parseInt(r, "offset"); err != nil {
+		return nil, err
+	}
+	if req.Query.Limit, err = parseInt(r, "limit"); err != nil {
+		return nil, err
+	}
+	if v := r.URL.Query().Get("shared"); v != "" {
+		if req.Query.Shared, err = strconv.ParseBool(v); err != nil {
+			return nil, errors.Wrap(service.ErrInvalidQueryParams, err)
+		}
+	}
+	return req, nil
+}
+
+// decodeHTTPGetRequest is a transport/http.DecodeRequestFunc that decodes a
+// JSON-encoded request from the HTTP request body. Primarily useful in a server.
+func decodeHTTPGetRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	var req endpoints.GetRequest
+	req.Id = bone.GetValue(r, "id")
+	req.Fields = parseFields(r)
+	return req, nil
+}
+
+// decodeHTTPStatsRequest is a transport/http.DecodeRequestFunc that decodes a
+// JSON-encoded request from the HTTP request body. Primarily useful in a server.
+func decodeHTTPStatsRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	var req endpoints.StatsRequest
+	if v := r.URL.Query().Get("days"); v != "" {
+		days, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, errors.Wrap(service.ErrInvalidQueryParams, err)
+		}
+		req.Days = days
+	}
+	return req, nil
+}
+
+// decodeHTTPUnarchiveRequest is a transport/http.DecodeRequestFunc that decodes a
+// JSON-encoded request from the HTTP request body. Primarily useful in a server.
+func decodeHTTPUnarchiveRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	var req endpoints.UnarchiveRequest
+	req.Id = bone.GetValue(r, "id")
+	return req, nil
+}
+
+// decodeHTTPSnoozeRequest is a transport/http.DecodeRequestFunc that decodes a
+// JSON-encoded request from the HTTP request body. Primarily useful in a server.
+func decodeHTTPSnoozeRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	var req endpoints.SnoozeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, errors.Wrap(service.ErrMalformedEntity, err)
+	}
+	req.Id = bone.GetValue(r, "id")
+	return req, nil
+}
+
+// decodeHTTPSyncRequest is a transport/http.DecodeRequestFunc that decodes the
+// sync token from the since query parameter. Primarily useful in a server.
+func decodeHTTPSyncRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	return endpoints.SyncRequest{Token: r.URL.Query().Get("since")}, nil
+}
+
+// decodeHTTPPushRequest is a transport/http.DecodeRequestFunc that decodes a
+// JSON-encoded request from the HTTP request body. Primarily useful in a server.
+func decodeHTTPPushRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	var req endpoints.PushRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, errors.Wrap(service.ErrMalformedEntity, err)
+	}
+	return req, nil
+}
+
+// decodeHTTPAddShareRequest is a transport/http.DecodeRequestFunc that decodes a
+// JSON-encoded request from the HTTP request body. Primarily useful in a server.
+func decodeHTTPAddShareRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	var req endpoints.AddShareRequest
+	if err := json.NewDecoder(r.Body).Decode(&req.Share); err != nil {
+		return nil, errors.Wrap(service.ErrMalformedEntity, err)
+	}
 	return req, nil
 }
 
+// decodeHTTPDeleteShareRequest is a transport/http.DecodeRequestFunc that
+// decodes the id of the share from the request path. Primarily useful in a
+// server.
+func decodeHTTPDeleteShareRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	return endpoints.DeleteShareRequest{Id: bone.GetValue(r, "id")}, nil
+}
+
+// decodeHTTPListSharesRequest is a transport/http.DecodeRequestFunc for the
+// ListShares request, which has no parameters. Primarily useful in a server.
+func decodeHTTPListSharesRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	return endpoints.ListSharesRequest{}, nil
+}
+
+// decodeHTTPCreateAPIKeyRequest is a transport/http.DecodeRequestFunc that decodes a
+// JSON-encoded request from the HTTP request body. Primarily useful in a server.
+func decodeHTTPCreateAPIKeyRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	var req endpoints.CreateAPIKeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req.Key); err != nil {
+		return nil, errors.Wrap(service.ErrMalformedEntity, err)
+	}
+	return req, nil
+}
+
+// decodeHTTPRevokeAPIKeyRequest is a transport/http.DecodeRequestFunc that
+// decodes the id of the API key from the request path. Primarily useful in a
+// server.
+func decodeHTTPRevokeAPIKeyRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	return endpoints.RevokeAPIKeyRequest{Id: bone.GetValue(r, "id")}, nil
+}
+
+// decodeHTTPListAPIKeysRequest is a transport/http.DecodeRequestFunc for the
+// ListAPIKeys request, which has no parameters. Primarily useful in a server.
+func decodeHTTPListAPIKeysRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	return endpoints.ListAPIKeysRequest{}, nil
+}
+
+// decodeHTTPUsageRequest is a transport/http.DecodeRequestFunc for the Usage
+// request, which has no parameters. Primarily useful in a server.
+func decodeHTTPUsageRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	return endpoints.UsageRequest{}, nil
+}
+
+// queryTokenToContext moves a JWT from the access_token query parameter to
+// context, for the browsers unable to set the Authorization header of an
+// EventSource or a WebSocket. The header is preferred.
+func queryTokenToContext() httptransport.RequestFunc {
+	return func(ctx context.Context, r *http.Request) context.Context {
+		if _, ok := ctx.Value(kitjwt.JWTTokenContextKey).(string); ok {
+			return ctx
+		}
+		if token := r.URL.Query().Get("access_token"); token != "" {
+			return context.WithValue(ctx, kitjwt.JWTTokenContextKey, token)
+		}
+		return ctx
+	}
+}
+
+// parseInt reads an optional integer from the key query parameter.
+func parseInt(r *http.Request, key string) (int, error) {
+	v := r.URL.Query().Get(key)
+	if v == "" {
+		return 0, nil
+	}
+	i, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, errors.Wrap(service.ErrInvalidQueryParams, err)
+	}
+	return i, nil
+}
+
+// parseTime reads an optional RFC3339 timestamp from the key query parameter.
+func parseTime(r *http.Request, key string) (*time.Time, error) {
+	v := r.URL.Query().Get(key)
+	if v == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		return nil, errors.Wrap(service.ErrInvalidQueryParams, err)
+	}
+	return &t, nil
+}
+
+// parseFields reads the comma separated sparse fieldset from the fields query
+// parameter.
+func parseFields(r *http.Request) (fields []string) {
+	for _, f := range strings.Split(r.URL.Query().Get("fields"), ",") {
+		if f = strings.TrimSpace(f); f != "" {
+			fields = append(fields, f)
+		}
+	}
+	return
+}
+
 func CustomErrorEncoder(errorVal errors.Error) (code int) {
 	switch {
+	case errors.Contains(errorVal, authn.ErrUnauthorized):
+		code = http.StatusUnauthorized
+	case errors.Contains(errorVal, authz.ErrForbidden):
+		code = http.StatusForbidden
+	case errors.Contains(errorVal, ratelimit.ErrLimited):
+		code = http.StatusTooManyRequests
+	case errors.Contains(errorVal, service.ErrQuotaExceeded):
+		code = http.StatusForbidden
 	case errors.Contains(errorVal, service.ErrInvalidQueryParams),
 		errors.Contains(errorVal, service.ErrMalformedEntity):
 		code = http.StatusBadRequest
 	case errors.Contains(errorVal, service.ErrNotFound):
 		code = http.StatusNotFound
+	case errors.Contains(errorVal, service.ErrConflict):
+		code = http.StatusConflict
 	}
 	return
 }
+
+// NewHTTPClient returns a TodoService backed by an HTTP server living at the
+// remote instance. We expect instance to come from a service discovery system,
+// so likely of the form "host:port". We bake-in certain middlewares,
+// implementing the client library pattern.
+func NewHTTPClient(instance string, otTracer stdopentracing.Tracer, zipkinTracer *stdzipkin.Tracer, logger log.Logger) (service.TodoService, error) {
+	// Quickly sanitize the instance string.
+	if !strings.HasPrefix(instance, "http") {
+		instance = "http://" + instance
+	}
+	u, err := url.Parse(instance)
+	if err != nil {
+		return nil, err
+	}
+
+	// Zipkin HTTP Client Trace can either be instantiated per endpoint with a
+	// provided operation name or a global tracing client can be instantiated
+	// without an operation name and fed to each Go kit endpoint as ClientOption.
+	// In the latter case, the operation name will be the endpoint's http method.
+	zipkinClient := zipkin.HTTPClientTrace(zipkinTracer)
+
+	// global client middlewares
+	options := []httptransport.ClientOption{
+		zipkinClient,
+		httptransport.ClientBefore(opentracing.ContextToHTTP(otTracer, logger), kitjwt.ContextToHTTP()),
+	}
+
+	var addEndpoint endpoint.Endpoint
+	{
+		addEndpoint = httptransport.NewClient(
+			http.MethodPost,
+			copyURL(u, "/items"),
+			encodeHTTPAddRequest,
+			decodeHTTPAddResponse,
+			options...,
+		).Endpoint()
+		addEndpoint = opentracing.TraceClient(otTracer, "Add")(addEndpoint)
+	}
+
+	var deleteEndpoint endpoint.Endpoint
+	{
+		deleteEndpoint = httptransport.NewClient(
+			http.MethodDelete,
+			copyURL(u, "/items"),
+			encodeHTTPDeleteRequest,
+			decodeHTTPDeleteResponse,
+			options...,
+		).Endpoint()
+		deleteEndpoint = opentracing.TraceClient(otTracer, "Delete")(deleteEndpoint)
+	}
+
+	var updateEndpoint endpoint.Endpoint
+	{
+		updateEndpoint = httptransport.NewClient(
+			http.MethodPatch,
+			copyURL(u, "/items"),
+			encodeHTTPUpdateRequest,
+			decodeHTTPUpdateResponse,
+			options...,
+		).Endpoint()
+		updateEndpoint = opentracing.TraceClient(otTracer, "Update")(updateEndpoint)
+	}
+
+	var listEndpoint endpoint.Endpoint
+	{
+		listEndpoint = httptransport.NewClient(
+			http.MethodGet,
+			copyURL(u, "/items"),
+			encodeHTTPListRequest,
+			decodeHTTPListResponse,
+			options...,
+		).Endpoint()
+		listEndpoint = opentracing.TraceClient(otTracer, "List")(listEndpoint)
+	}
+
+	var getEndpoint endpoint.Endpoint
+	{
+		getEndpoint = httptransport.NewClient(
+			http.MethodGet,
+			copyURL(u, "/items"),
+			encodeHTTPGetRequest,
+			decodeHTTPGetResponse,
+			options...,
+		).Endpoint()
+		getEndpoint = opentracing.TraceClient(otTracer, "Get")(getEndpoint)
+	}
+
+	var statsEndpoint endpoint.Endpoint
+	{
+		statsEndpoint = httptransport.NewClient(
+			http.MethodGet,
+			copyURL(u, "/stats"),
+			encodeHTTPStatsRequest,
+			decodeHTTPStatsResponse,
+			options...,
+		).Endpoint()
+		statsEndpoint = opentracing.TraceClient(otTracer, "Stats")(statsEndpoint)
+	}
+
+	var unarchiveEndpoint endpoint.Endpoint
+	{
+		unarchiveEndpoint = httptransport.NewClient(
+			http.MethodPost,
+			copyURL(u, "/items"),
+			encodeHTTPUnarchiveRequest,
+			decodeHTTPUnarchiveResponse,
+			options...,
+		).Endpoint()
+		unarchiveEndpoint = opentracing.TraceClient(otTracer, "Unarchive")(unarchiveEndpoint)
+	}
+
+	var snoozeEndpoint endpoint.Endpoint
+	{
+		snoozeEndpoint = httptransport.NewClient(
+			http.MethodPost,
+			copyURL(u, "/items"),
+			encodeHTTPSnoozeRequest,
+			decodeHTTPSnoozeResponse,
+			options...,
+		).Endpoint()
+		snoozeEndpoint = opentracing.TraceClient(otTracer, "Snooze")(snoozeEndpoint)
+	}
+
+	// The Watch endpoint reads the event stream after it returns, so its
+	// response body is left open.
+	var watchEndpoint endpoint.Endpoint
+	{
+		watchEndpoint = httptransport.NewClient(
+			http.MethodGet,
+			copyURL(u, "/items/events"),
+			encodeHTTPWatchRequest,
+			decodeHTTPWatchResponse,
+			append(options, httptransport.BufferedStream(true))...,
+		).Endpoint()
+		watchEndpoint = opentracing.TraceClient(otTracer, "Watch")(watchEndpoint)
+	}
+
+	var syncEndpoint endpoint.Endpoint
+	{
+		syncEndpoint = httptransport.NewClient(
+			http.MethodGet,
+			copyURL(u, "/sync"),
+			encodeHTTPSyncRequest,
+			decodeHTTPSyncResponse,
+			options...,
+		).Endpoint()
+		syncEndpoint = opentracing.TraceClient(otTracer, "Sync")(syncEndpoint)
+	}
+
+	var pushEndpoint endpoint.Endpoint
+	{
+		pushEndpoint = httptransport.NewClient(
+			http.MethodPost,
+			copyURL(u, "/sync"),
+			encodeHTTPPushRequest,
+			decodeHTTPPushResponse,
+			options...,
+		).Endpoint()
+		pushEndpoint = opentracing.TraceClient(otTracer, "Push")(pushEndpoint)
+	}
+
+	var addShareEndpoint endpoint.Endpoint
+	{
+		addShareEndpoint = httptransport.NewClient(
+			http.MethodPost,
+			copyURL(u, "/shares"),
+			encodeHTTPAddShareRequest,
+			decodeHTTPAddShareResponse,
+			options...,
+		).Endpoint()
+		addShareEndpoint = opentracing.TraceClient(otTracer, "AddShare")(addShareEndpoint)
+	}
+
+	var deleteShareEndpoint endpoint.Endpoint
+	{
+		deleteShareEndpoint = httptransport.NewClient(
+			http.MethodDelete,
+			copyURL(u, "/shares"),
+			encodeHTTPDeleteShareRequest,
+			decodeHTTPDeleteShareResponse,
+			options...,
+		).Endpoint()
+		deleteShareEndpoint = opentracing.TraceClient(otTracer, "DeleteShare")(deleteShareEndpoint)
+	}
+
+	var listSharesEndpoint endpoint.Endpoint
+	{
+		listSharesEndpoint = httptransport.NewClient(
+			http.MethodGet,
+			copyURL(u, "/shares"),
+			encodeHTTPListSharesRequest,
+			decodeHTTPListSharesResponse,
+			options...,
+		).Endpoint()
+		listSharesEndpoint = opentracing.TraceClient(otTracer, "ListShares")(listSharesEndpoint)
+	}
+
+	var createAPIKeyEndpoint endpoint.Endpoint
+	{
+		createAPIKeyEndpoint = httptransport.NewClient(
+			http.MethodPost,
+			copyURL(u, "/admin/api-keys"),
+			encodeHTTPCreateAPIKeyRequest,
+			decodeHTTPCreateAPIKeyResponse,
+			options...,
+		).Endpoint()
+		createAPIKeyEndpoint = opentracing.TraceClient(otTracer, "CreateAPIKey")(createAPIKeyEndpoint)
+	}
+
+	var revokeAPIKeyEndpoint endpoint.Endpoint
+	{
+		revokeAPIKeyEndpoint = httptransport.NewClient(
+			http.MethodDelete,
+			copyURL(u, "/admin/api-keys"),
+			encodeHTTPRevokeAPIKeyRequest,
+			decodeHTTPRevokeAPIKeyResponse,
+			options...,
+		).Endpoint()
+		revokeAPIKeyEndpoint = opentracing.TraceClient(otTracer, "RevokeAPIKey")(revokeAPIKeyEndpoint)
+	}
+
+	var listAPIKeysEndpoint endpoint.Endpoint
+	{
+		listAPIKeysEndpoint = httptransport.NewClient(
+			http.MethodGet,
+			copyURL(u, "/admin/api-keys"),
+			encodeHTTPListAPIKeysRequest,
+			decodeHTTPListAPIKeysResponse,
+			options...,
+		).Endpoint()
+		listAPIKeysEndpoint = opentracing.TraceClient(otTracer, "ListAPIKeys")(listAPIKeysEndpoint)
+	}
+
+	var usageEndpoint endpoint.Endpoint
+	{
+		usageEndpoint = httptransport.NewClient(
+			http.MethodGet,
+			copyURL(u, "/usage"),
+			encodeHTTPUsageRequest,
+			decodeHTTPUsageResponse,
+			options...,
+		).Endpoint()
+		usageEndpoint = opentracing.TraceClient(otTracer, "Usage")(usageEndpoint)
+	}
+
+	return endpoints.Endpoints{
+		AddEndpoint:          addEndpoint,
+		DeleteEndpoint:       deleteEndpoint,
+		UpdateEndpoint:       updateEndpoint,
+		ListEndpoint:         listEndpoint,
+		GetEndpoint:          getEndpoint,
+		StatsEndpoint:        statsEndpoint,
+		UnarchiveEndpoint:    unarchiveEndpoint,
+		SnoozeEndpoint:       snoozeEndpoint,
+		WatchEndpoint:        watchEndpoint,
+		SyncEndpoint:         syncEndpoint,
+		PushEndpoint:         pushEndpoint,
+		AddShareEndpoint:     addShareEndpoint,
+		DeleteShareEndpoint:  deleteShareEndpoint,
+		ListSharesEndpoint:   listSharesEndpoint,
+		CreateAPIKeyEndpoint: createAPIKeyEndpoint,
+		RevokeAPIKeyEndpoint: revokeAPIKeyEndpoint,
+		ListAPIKeysEndpoint:  listAPIKeysEndpoint,
+		UsageEndpoint:        usageEndpoint,
+	}, nil
+}
+
+func copyURL(base *url.URL, path string) *url.URL {
+	next := *base
+	next.Path = strings.TrimSuffix(next.Path, "/") + path
+	return &next
+}
+
+// withPath appends the escaped segments to the path of r.
+func withPath(r *http.Request, segments ...string) {
+	escaped := r.URL.EscapedPath()
+	for _, segment := range segments {
+		r.URL.Path += "/" + segment
+		escaped += "/" + url.PathEscape(segment)
+	}
+	r.URL.RawPath = escaped
+}
+
+// encodeJSONBody sets v as the JSON-encoded body of r.
+func encodeJSONBody(r *http.Request, v interface{}) error {
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(v); err != nil {
+		return err
+	}
+	r.Header.Set("Content-Type", contentType)
+	r.Body = ioutil.NopCloser(&buf)
+	r.ContentLength = int64(buf.Len())
+	return nil
+}
+
+// encodeHTTPAddRequest is a transport/http.EncodeRequestFunc that
+// JSON-encodes the todo of a user-domain Add request into the request body.
+// Primarily useful in a client.
+func encodeHTTPAddRequest(_ context.Context, r *http.Request, request interface{}) error {
+	req := request.(endpoints.AddRequest)
+	return encodeJSONBody(r, req.Todo)
+}
+
+// decodeHTTPAddResponse is a transport/http.DecodeResponseFunc that decodes a
+// JSON-encoded Add response from the HTTP response body. Primarily useful in a
+// client.
+func decodeHTTPAddResponse(_ context.Context, r *http.Response) (interface{}, error) {
+	var res endpoints.AddResponse
+	err := responses.DecodeJSONResponse(r, &res.Res)
+	return res, err
+}
+
+// encodeHTTPDeleteRequest is a transport/http.EncodeRequestFunc that puts the
+// id of a user-domain Delete request in the request path. Primarily useful in
+// a client.
+func encodeHTTPDeleteRequest(_ context.Context, r *http.Request, request interface{}) error {
+	req := request.(endpoints.DeleteRequest)
+	withPath(r, req.Id)
+	return nil
+}
+
+// decodeHTTPDeleteResponse is a transport/http.DecodeResponseFunc that decodes
+// a Delete response, which has no content. Primarily useful in a client.
+func decodeHTTPDeleteResponse(_ context.Context, r *http.Response) (interface{}, error) {
+	var res endpoints.DeleteResponse
+	err := responses.DecodeJSONResponse(r, nil)
+	return res, err
+}
+
+// encodeHTTPUpdateRequest is a transport/http.EncodeRequestFunc that puts the
+// id of a user-domain Update request in the request path, and JSON-encodes
+// its todo into the request body. Primarily useful in a client.
+func encodeHTTPUpdateRequest(_ context.Context, r *http.Request, request interface{}) error {
+	req := request.(endpoints.UpdateRequest)
+	withPath(r, req.Id)
+	return encodeJSONBody(r, req.Todo)
+}
+
+// decodeHTTPUpdateResponse is a transport/http.DecodeResponseFunc that decodes
+// a JSON-encoded Update response from the HTTP response body. Primarily useful
+// in a client.
+func decodeHTTPUpdateResponse(_ context.Context, r *http.Response) (interface{}, error) {
+	var res endpoints.UpdateResponse
+	err := responses.DecodeJSONResponse(r, &res.Res)
+	return res, err
+}
+
+// encodeHTTPListRequest is a transport/http.EncodeRequestFunc that encodes the
+// query of a user-domain List request as query parameters. Primarily useful
+// in a client.
+func encodeHTTPListRequest(_ context.Context, r *http.Request, request interface{}) error {
+	req := request.(endpoints.ListRequest)
+	if req.Query == nil {
+		return nil
+	}
+
+	q := r.URL.Query()
+	setQuery(q, "fields", strings.Join(req.Query.Fields, ","))
+	setQuery(q, "sort", req.Query.Sort)
+	setQuery(q, "status", req.Query.Status)
+	var include []string
+	if req.Query.IncludeArchived {
+		include = append(include, "archived")
+	}
+	if req.Query.IncludeSnoozed {
+		include = append(include, "snoozed")
+	}
+	setQuery(q, "include", strings.Join(include, ","))
+	if req.Query.CompletedAfter != nil {
+		q.Set("completedAfter", req.Query.CompletedAfter.Format(time.RFC3339Nano))
+	}
+	if req.Query.CompletedBefore != nil {
+		q.Set("completedBefore", req.Query.CompletedBefore.Format(time.RFC3339Nano))
+	}
+	if req.Query.Offset != 0 {
+		q.Set("offset", strconv.Itoa(req.Query.Offset))
+	}
+	if req.Query.Limit != 0 {
+		q.Set("limit", strconv.Itoa(req.Query.Limit))
+	}
+	if req.Query.Shared {
+		q.Set("shared", "true")
+	}
+	r.URL.RawQuery = q.Encode()
+	return nil
+}
+
+// decodeHTTPListResponse is a transport/http.DecodeResponseFunc that decodes a
+// JSON-encoded List response from the HTTP response body. Primarily useful in
+// a client.
+func decodeHTTPListResponse(_ context.Context, r *http.Response) (interface{}, error) {
+	var res endpoints.ListResponse
+	err := responses.DecodeJSONResponse(r, &res.Res)
+	return res, err
+}
+
+// encodeHTTPGetRequest is a transport/http.EncodeRequestFunc that puts the id
+// of a user-domain Get request in the request path. Primarily useful in a
+// client.
+func encodeHTTPGetRequest(_ context.Context, r *http.Request, request interface{}) error {
+	req := request.(endpoints.GetRequest)
+	withPath(r, req.Id)
+	q := r.URL.Query()
+	setQuery(q, "fields", strings.Join(req.Fields, ","))
+	r.URL.RawQuery = q.Encode()
+	return nil
+}
+
+// decodeHTTPGetResponse is a transport/http.DecodeResponseFunc that decodes a
+// JSON-encoded Get response from the HTTP response body. Primarily useful in a
+// client.
+func decodeHTTPGetResponse(_ context.Context, r *http.Response) (interface{}, error) {
+	var res endpoints.GetResponse
+	err := responses.DecodeJSONResponse(r, &res.Res)
+	return res, err
+}
+
+// encodeHTTPStatsRequest is a transport/http.EncodeRequestFunc that encodes
+// the days of a user-domain Stats request as a query parameter. Primarily
+// useful in a client.
+func encodeHTTPStatsRequest(_ context.Context, r *http.Request, request interface{}) error {
+	req := request.(endpoints.StatsRequest)
+	if req.Days != 0 {
+		r.URL.RawQuery = url.Values{"days": {strconv.Itoa(req.Days)}}.Encode()
+	}
+	return nil
+}
+
+// decodeHTTPStatsResponse is a transport/http.DecodeResponseFunc that decodes
+// a JSON-encoded Stats response from the HTTP response body. Primarily useful
+// in a client.
+func decodeHTTPStatsResponse(_ context.Context, r *http.Response) (interface{}, error) {
+	var res endpoints.StatsResponse
+	err := responses.DecodeJSONResponse(r, &res.Res)
+	return res, err
+}
+
+// encodeHTTPUnarchiveRequest is a transport/http.EncodeRequestFunc that puts
+// the id of a user-domain Unarchive request in the request path. Primarily
+// useful in a client.
+func encodeHTTPUnarchiveRequest(_ context.Context, r *http.Request, request interface{}) error {
+	req := request.(endpoints.UnarchiveRequest)
+	withPath(r, req.Id, "unarchive")
+	return nil
+}
+
+// decodeHTTPUnarchiveResponse is a transport/http.DecodeResponseFunc that
+// decodes a JSON-encoded Unarchive response from the HTTP response body.
+// Primarily useful in a client.
+func decodeHTTPUnarchiveResponse(_ context.Context, r *http.Response) (interface{}, error) {
+	var res endpoints.UnarchiveResponse
+	err := responses.DecodeJSONResponse(r, &res.Res)
+	return res, err
+}
+
+// encodeHTTPSnoozeRequest is a transport/http.EncodeRequestFunc that puts the
+// id of a user-domain Snooze request in the request path, and JSON-encodes the
+// request into the request body. Primarily useful in a client.
+func encodeHTTPSnoozeRequest(_ context.Context, r *http.Request, request interface{}) error {
+	req := request.(endpoints.SnoozeRequest)
+	withPath(r, req.Id, "snooze")
+	return encodeJSONBody(r, req)
+}
+
+// decodeHTTPSnoozeResponse is a transport/http.DecodeResponseFunc that decodes
+// a JSON-encoded Snooze response from the HTTP response body. Primarily useful
+// in a client.
+func decodeHTTPSnoozeResponse(_ context.Context, r *http.Response) (interface{}, error) {
+	var res endpoints.SnoozeResponse
+	err := responses.DecodeJSONResponse(r, &res.Res)
+	return res, err
+}
+
+// encodeHTTPSyncRequest is a transport/http.EncodeRequestFunc that encodes the
+// token of a user-domain Sync request as the since query parameter. Primarily
+// useful in a client.
+func encodeHTTPSyncRequest(_ context.Context, r *http.Request, request interface{}) error {
+	req := request.(endpoints.SyncRequest)
+	if req.Token != "" {
+		r.URL.RawQuery = url.Values{"since": {req.Token}}.Encode()
+	}
+	return nil
+}
+
+// decodeHTTPSyncResponse is a transport/http.DecodeResponseFunc that decodes a
+// JSON-encoded Sync response from the HTTP response body. Primarily useful in
+// a client.
+func decodeHTTPSyncResponse(_ context.Context, r *http.Response) (interface{}, error) {
+	var res endpoints.SyncResponse
+	err := responses.DecodeJSONResponse(r, &res.Res)
+	return res, err
+}
+
+// encodeHTTPPushRequest is a transport/http.EncodeRequestFunc that
+// JSON-encodes a user-domain Push request into the request body. Primarily
+// useful in a client.
+func encodeHTTPPushRequest(_ context.Context, r *http.Request, request interface{}) error {
+	return encodeJSONBody(r, request.(endpoints.PushRequest))
+}
+
+// decodeHTTPPushResponse is a transport/http.DecodeResponseFunc that decodes a
+// JSON-encoded Push response from the HTTP response body. Primarily useful in
+// a client.
+func decodeHTTPPushResponse(_ context.Context, r *http.Response) (interface{}, error) {
+	var res endpoints.PushResponse
+	err := responses.DecodeJSONResponse(r, &res.Res)
+	return res, err
+}
+
+// encodeHTTPAddShareRequest is a transport/http.EncodeRequestFunc that
+// JSON-encodes the share of a user-domain AddShare request into the request
+// body. Primarily useful in a client.
+func encodeHTTPAddShareRequest(_ context.Context, r *http.Request, request interface{}) error {
+	req := request.(endpoints.AddShareRequest)
+	return encodeJSONBody(r, req.Share)
+}
+
+// decodeHTTPAddShareResponse is a transport/http.DecodeResponseFunc that
+// decodes a JSON-encoded AddShare response from the HTTP response body.
+// Primarily useful in a client.
+func decodeHTTPAddShareResponse(_ context.Context, r *http.Response) (interface{}, error) {
+	var res endpoints.AddShareResponse
+	err := responses.DecodeJSONResponse(r, &res.Res)
+	return res, err
+}
+
+// encodeHTTPDeleteShareRequest is a transport/http.EncodeRequestFunc that puts
+// the id of a user-domain DeleteShare request in the request path. Primarily
+// useful in a client.
+func encodeHTTPDeleteShareRequest(_ context.Context, r *http.Request, request interface{}) error {
+	req := request.(endpoints.DeleteShareRequest)
+	withPath(r, req.Id)
+	return nil
+}
+
+// decodeHTTPDeleteShareResponse is a transport/http.DecodeResponseFunc that
+// decodes a DeleteShare response, which has no content. Primarily useful in a
+// client.
+func decodeHTTPDeleteShareResponse(_ context.Context, r *http.Response) (interface{}, error) {
+	var res endpoints.DeleteShareResponse
+	err := responses.DecodeJSONResponse(r, nil)
+	return res, err
+}
+
+// encodeHTTPListSharesRequest is a transport/http.EncodeRequestFunc for the
+// ListShares request, which has no parameters. Primarily useful in a client.
+func encodeHTTPListSharesRequest(_ context.Context, r *http.Request, request interface{}) error {
+	_ = request.(endpoints.ListSharesRequest)
+	return nil
+}
+
+// decodeHTTPListSharesResponse is a transport/http.DecodeResponseFunc that
+// decodes a JSON-encoded ListShares response from the HTTP response body.
+// Primarily useful in a client.
+func decodeHTTPListSharesResponse(_ context.Context, r *http.Response) (interface{}, error) {
+	var res endpoints.ListSharesResponse
+	err := responses.DecodeJSONResponse(r, &res.Res)
+	return res, err
+}
+
+// encodeHTTPCreateAPIKeyRequest is a transport/http.EncodeRequestFunc that
+// JSON-encodes the key of a user-domain CreateAPIKey request into the request
+// body. Primarily useful in a client.
+func encodeHTTPCreateAPIKeyRequest(_ context.Context, r *http.Request, request interface{}) error {
+	req := request.(endpoints.CreateAPIKeyRequest)
+	return encodeJSONBody(r, req.Key)
+}
+
+// decodeHTTPCreateAPIKeyResponse is a transport/http.DecodeResponseFunc that
+// decodes a JSON-encoded CreateAPIKey response from the HTTP response body.
+// Primarily useful in a client.
+func decodeHTTPCreateAPIKeyResponse(_ context.Context, r *http.Response) (interface{}, error) {
+	var res endpoints.CreateAPIKeyResponse
+	err := responses.DecodeJSONResponse(r, &res.Res)
+	return res, err
+}
+
+// encodeHTTPRevokeAPIKeyRequest is a transport/http.EncodeRequestFunc that puts
+// the id of a user-domain RevokeAPIKey request in the request path. Primarily
+// useful in a client.
+func encodeHTTPRevokeAPIKeyRequest(_ context.Context, r *http.Request, request interface{}) error {
+	req := request.(endpoints.RevokeAPIKeyRequest)
+	withPath(r, req.Id)
+	return nil
+}
+
+// decodeHTTPRevokeAPIKeyResponse is a transport/http.DecodeResponseFunc that
+// decodes a RevokeAPIKey response, which has no content. Primarily useful in a
+// client.
+func decodeHTTPRevokeAPIKeyResponse(_ context.Context, r *http.Response) (interface{}, error) {
+	var res endpoints.RevokeAPIKeyResponse
+	err := responses.DecodeJSONResponse(r, nil)
+	return res, err
+}
+
+// encodeHTTPListAPIKeysRequest is a transport/http.EncodeRequestFunc for the
+// ListAPIKeys request, which has no parameters. Primarily useful in a client.
+func encodeHTTPListAPIKeysRequest(_ context.Context, r *http.Request, request interface{}) error {
+	_ = request.(endpoints.ListAPIKeysRequest)
+	return nil
+}
+
+// decodeHTTPListAPIKeysResponse is a transport/http.DecodeResponseFunc that
+// decodes a JSON-encoded ListAPIKeys response from the HTTP response body.
+// Primarily useful in a client.
+func decodeHTTPListAPIKeysResponse(_ context.Context, r *http.Response) (interface{}, error) {
+	var res endpoints.ListAPIKeysResponse
+	err := responses.DecodeJSONResponse(r, &res.Res)
+	return res, err
+}
+
+// encodeHTTPUsageRequest is a transport/http.EncodeRequestFunc for the Usage
+// request, which has no parameters. Primarily useful in a client.
+func encodeHTTPUsageRequest(_ context.Context, r *http.Request, request interface{}) error {
+	_ = request.(endpoints.UsageRequest)
+	return nil
+}
+
+// decodeHTTPUsageResponse is a transport/http.DecodeResponseFunc that decodes
+// a JSON-encoded Usage response from the HTTP response body. Primarily useful
+// in a client.
+func decodeHTTPUsageResponse(_ context.Context, r *http.Response) (interface{}, error) {
+	var res endpoints.UsageResponse
+	err := responses.DecodeJSONResponse(r, &res.Res)
+	return res, err
+}
+
+func setQuery(q url.Values, key, value string) {
+	if value != "" {
+		q.Set(key, value)
+	}
+}
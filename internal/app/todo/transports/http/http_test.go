@@ -3,25 +3,50 @@
 package transports_test
 
 import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"math/big"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"regexp"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
+	jwt "github.com/dgrijalva/jwt-go"
+	kitjwt "github.com/go-kit/kit/auth/jwt"
+	"github.com/go-kit/kit/endpoint"
 	"github.com/go-kit/kit/log"
+	kitprometheus "github.com/go-kit/kit/metrics/prometheus"
 	"github.com/golang/mock/gomock"
+	"github.com/gorilla/websocket"
 	"github.com/opentracing/opentracing-go"
 	"github.com/openzipkin/zipkin-go"
+	stdprometheus "github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoregistry"
 
 	"github.com/cage1016/gokit-todo/internal/app/todo/endpoints"
 	"github.com/cage1016/gokit-todo/internal/app/todo/model"
+	"github.com/cage1016/gokit-todo/internal/app/todo/service"
 	transports "github.com/cage1016/gokit-todo/internal/app/todo/transports/http"
 	automocks "github.com/cage1016/gokit-todo/internal/mocks/app/todo/service"
+	"github.com/cage1016/gokit-todo/internal/pkg/authn"
+	"github.com/cage1016/gokit-todo/internal/pkg/authz"
+	"github.com/cage1016/gokit-todo/internal/pkg/errors"
+	"github.com/cage1016/gokit-todo/internal/pkg/instrumenting"
+	"github.com/cage1016/gokit-todo/internal/pkg/ratelimit"
 	test "github.com/cage1016/gokit-todo/test/util"
 )
 
@@ -65,6 +90,22 @@ func TestAddHandler(t *testing.T) {
 				assert.Equal(t, http.StatusCreated, res.StatusCode, fmt.Sprintf("status should be 204: got %d", res.StatusCode))
 			},
 		},
+		{
+			name: "add todo past the quota",
+			prepare: func(f *fields) {
+				f.svc.EXPECT().Add(gomock.Any(), gomock.Any()).Return(nil, errors.Wrap(service.ErrQuotaExceeded, errors.New("more than 10 todos")))
+			},
+			wantErr: false,
+			args: args{
+				method: http.MethodPost,
+				url:    "/items",
+				body:   `{"text":"aa"}`,
+			},
+			checkFunc: func(res *http.Response, err error, body []byte) {
+				assert.Nil(t, err, fmt.Sprintf("unexpected error %s", err))
+				assert.Equal(t, http.StatusForbidden, res.StatusCode, fmt.Sprintf("status should be 403: got %d", res.StatusCode))
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -106,3 +147,2728 @@ func TestAddHandler(t *testing.T) {
 		})
 	}
 }
+
+func TestListHandler(t *testing.T) {
+	type fields struct {
+		svc *automocks.MockTodoService
+	}
+	type args struct {
+		method, url string
+	}
+
+	tests := []struct {
+		name      string
+		prepare   func(f *fields)
+		args      args
+		wantErr   bool
+		checkFunc func(res *http.Response, err error, body []byte)
+	}{
+		{
+			name: "list todo with fields",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.svc.EXPECT().List(gomock.Any(), &model.TodoQuery{Fields: []string{"id", "completed"}}).Return([]*model.TodoRes{{
+						ID:        "iKe0KxpurIn0E_6vzUDAr",
+						CreatedAt: time.Now(),
+						UpdatedAt: time.Now(),
+						Text:      "aa",
+						Completed: false,
+					}}, nil),
+				)
+			},
+			args: args{
+				method: http.MethodGet,
+				url:    "/items?fields=id,completed",
+			},
+			checkFunc: func(res *http.Response, err error, body []byte) {
+				assert.Equal(t, http.StatusOK, res.StatusCode, fmt.Sprintf("status should be 200: got %d", res.StatusCode))
+				assert.JSONEq(t, `{"apiVersion":"","data":[{"id":"iKe0KxpurIn0E_6vzUDAr","completed":false}]}`, string(body))
+			},
+		},
+		{
+			name: "list todo with unknown field",
+			args: args{
+				method: http.MethodGet,
+				url:    "/items?fields=id,owner",
+			},
+			checkFunc: func(res *http.Response, err error, body []byte) {
+				assert.Equal(t, http.StatusBadRequest, res.StatusCode, fmt.Sprintf("status should be 400: got %d", res.StatusCode))
+			},
+		},
+		{
+			name: "list todo including archived",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.svc.EXPECT().List(gomock.Any(), &model.TodoQuery{IncludeArchived: true}).Return([]*model.TodoRes{}, nil),
+				)
+			},
+			args: args{
+				method: http.MethodGet,
+				url:    "/items?include=archived",
+			},
+			checkFunc: func(res *http.Response, err error, body []byte) {
+				assert.Equal(t, http.StatusOK, res.StatusCode, fmt.Sprintf("status should be 200: got %d", res.StatusCode))
+			},
+		},
+		{
+			name: "list todo including archived and snoozed",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.svc.EXPECT().List(gomock.Any(), &model.TodoQuery{IncludeArchived: true, IncludeSnoozed: true}).Return([]*model.TodoRes{}, nil),
+				)
+			},
+			args: args{
+				method: http.MethodGet,
+				url:    "/items?include=archived,snoozed",
+			},
+			checkFunc: func(res *http.Response, err error, body []byte) {
+				assert.Equal(t, http.StatusOK, res.StatusCode, fmt.Sprintf("status should be 200: got %d", res.StatusCode))
+			},
+		},
+		{
+			name: "list todo with unknown status",
+			args: args{
+				method: http.MethodGet,
+				url:    "/items?status=snoozed",
+			},
+			checkFunc: func(res *http.Response, err error, body []byte) {
+				assert.Equal(t, http.StatusBadRequest, res.StatusCode, fmt.Sprintf("status should be 400: got %d", res.StatusCode))
+			},
+		},
+		{
+			name: "list todo with unknown include",
+			args: args{
+				method: http.MethodGet,
+				url:    "/items?include=deleted",
+			},
+			checkFunc: func(res *http.Response, err error, body []byte) {
+				assert.Equal(t, http.StatusBadRequest, res.StatusCode, fmt.Sprintf("status should be 400: got %d", res.StatusCode))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			f := fields{
+				svc: automocks.NewMockTodoService(ctrl),
+			}
+			if tt.prepare != nil {
+				tt.prepare(&f)
+			}
+
+			logger := log.NewLogfmtLogger(os.Stderr)
+			zkt, _ := zipkin.NewTracer(nil, zipkin.WithNoopTracer(true))
+			tracer := opentracing.GlobalTracer()
+
+			eps := endpoints.New(f.svc, logger, tracer, zkt)
+			ts := httptest.NewServer(transports.NewHTTPHandler(eps, tracer, zkt, logger))
+			defer ts.Close()
+
+			req := test.TestRequest{
+				Client: ts.Client(),
+				Method: tt.args.method,
+				URL:    fmt.Sprintf("%s%s", ts.URL, tt.args.url),
+			}
+
+			if res, err := req.Make(); (err != nil) != tt.wantErr {
+				t.Errorf("%s: unexpected error %s", tt.name, err)
+			} else {
+				body, _ := ioutil.ReadAll(res.Body)
+				if tt.checkFunc != nil {
+					tt.checkFunc(res, err, body)
+				}
+			}
+		})
+	}
+}
+
+func TestGetHandler(t *testing.T) {
+	type fields struct {
+		svc *automocks.MockTodoService
+	}
+	type args struct {
+		method, url string
+	}
+
+	tests := []struct {
+		name      string
+		prepare   func(f *fields)
+		args      args
+		wantErr   bool
+		checkFunc func(res *http.Response, err error, body []byte)
+	}{
+		{
+			name: "get todo with fields",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.svc.EXPECT().Get(gomock.Any(), "iKe0KxpurIn0E_6vzUDAr").Return(&model.TodoRes{
+						ID:        "iKe0KxpurIn0E_6vzUDAr",
+						CreatedAt: time.Now(),
+						UpdatedAt: time.Now(),
+						Text:      "aa",
+						Completed: true,
+					}, nil),
+				)
+			},
+			args: args{
+				method: http.MethodGet,
+				url:    "/items/iKe0KxpurIn0E_6vzUDAr?fields=completed",
+			},
+			checkFunc: func(res *http.Response, err error, body []byte) {
+				assert.Equal(t, http.StatusOK, res.StatusCode, fmt.Sprintf("status should be 200: got %d", res.StatusCode))
+				assert.JSONEq(t, `{"apiVersion":"","data":{"completed":true}}`, string(body))
+			},
+		},
+		{
+			name: "get todo not found",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.svc.EXPECT().Get(gomock.Any(), "iKe0KxpurIn0E_6vzUDAr").Return(nil, service.ErrNotFound),
+				)
+			},
+			args: args{
+				method: http.MethodGet,
+				url:    "/items/iKe0KxpurIn0E_6vzUDAr",
+			},
+			checkFunc: func(res *http.Response, err error, body []byte) {
+				assert.Equal(t, http.StatusNotFound, res.StatusCode, fmt.Sprintf("status should be 404: got %d", res.StatusCode))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			f := fields{
+				svc: automocks.NewMockTodoService(ctrl),
+			}
+			if tt.prepare != nil {
+				tt.prepare(&f)
+			}
+
+			logger := log.NewLogfmtLogger(os.Stderr)
+			zkt, _ := zipkin.NewTracer(nil, zipkin.WithNoopTracer(true))
+			tracer := opentracing.GlobalTracer()
+
+			eps := endpoints.New(f.svc, logger, tracer, zkt)
+			ts := httptest.NewServer(transports.NewHTTPHandler(eps, tracer, zkt, logger))
+			defer ts.Close()
+
+			req := test.TestRequest{
+				Client: ts.Client(),
+				Method: tt.args.method,
+				URL:    fmt.Sprintf("%s%s", ts.URL, tt.args.url),
+			}
+
+			if res, err := req.Make(); (err != nil) != tt.wantErr {
+				t.Errorf("%s: unexpected error %s", tt.name, err)
+			} else {
+				body, _ := ioutil.ReadAll(res.Body)
+				if tt.checkFunc != nil {
+					tt.checkFunc(res, err, body)
+				}
+			}
+		})
+	}
+}
+
+func TestSyncHandler(t *testing.T) {
+	type fields struct {
+		svc *automocks.MockTodoService
+	}
+	type args struct {
+		method, url string
+		body        string
+	}
+
+	tests := []struct {
+		name      string
+		prepare   func(f *fields)
+		args      args
+		wantErr   bool
+		checkFunc func(res *http.Response, err error, body []byte)
+	}{
+		{
+			name: "sync changes since token",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.svc.EXPECT().Sync(gomock.Any(), "3").Return(&model.Changes{
+						Todos:   []*model.TodoRes{{ID: "iKe0KxpurIn0E_6vzUDAr", Text: "aa", Version: 4}},
+						Deleted: []*model.Tombstone{{ID: "b5z2zC5c9O6~Ns_qLVmn~", Version: 5}},
+						Token:   "5",
+					}, nil),
+				)
+			},
+			args: args{
+				method: http.MethodGet,
+				url:    "/sync?since=3",
+			},
+			checkFunc: func(res *http.Response, err error, body []byte) {
+				assert.Equal(t, http.StatusOK, res.StatusCode, fmt.Sprintf("status should be 200: got %d", res.StatusCode))
+				var v struct {
+					Data model.Changes `json:"data"`
+				}
+				assert.Nil(t, json.Unmarshal(body, &v))
+				assert.Equal(t, "5", v.Data.Token)
+				assert.Len(t, v.Data.Todos, 1)
+				assert.Len(t, v.Data.Deleted, 1)
+			},
+		},
+		{
+			name: "sync with malformed token",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.svc.EXPECT().Sync(gomock.Any(), "abc").Return(nil, service.ErrInvalidQueryParams),
+				)
+			},
+			args: args{
+				method: http.MethodGet,
+				url:    "/sync?since=abc",
+			},
+			checkFunc: func(res *http.Response, err error, body []byte) {
+				assert.Equal(t, http.StatusBadRequest, res.StatusCode, fmt.Sprintf("status should be 400: got %d", res.StatusCode))
+			},
+		},
+		{
+			name: "push mutations",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.svc.EXPECT().Push(gomock.Any(), gomock.Any()).DoAndReturn(func(_ interface{}, mutations []*model.Mutation) ([]*model.MutationResult, error) {
+						assert.Len(t, mutations, 1)
+						assert.Equal(t, model.MutationUpdate, mutations[0].Op)
+						assert.Equal(t, uint64(4), mutations[0].Version)
+						return []*model.MutationResult{{ID: mutations[0].ID, Status: model.MutationConflict}}, nil
+					}),
+				)
+			},
+			args: args{
+				method: http.MethodPost,
+				url:    "/sync",
+				body:   `{"mutations":[{"op":"update","id":"iKe0KxpurIn0E_6vzUDAr","version":4,"todo":{"text":"bb"}}]}`,
+			},
+			checkFunc: func(res *http.Response, err error, body []byte) {
+				assert.Equal(t, http.StatusOK, res.StatusCode, fmt.Sprintf("status should be 200: got %d", res.StatusCode))
+				assert.JSONEq(t, `{"apiVersion":"","data":[{"id":"iKe0KxpurIn0E_6vzUDAr","status":"conflict","todo":null}]}`, string(body))
+			},
+		},
+		{
+			name: "push malformed body",
+			args: args{
+				method: http.MethodPost,
+				url:    "/sync",
+				body:   `{"mutations":`,
+			},
+			checkFunc: func(res *http.Response, err error, body []byte) {
+				assert.Equal(t, http.StatusBadRequest, res.StatusCode, fmt.Sprintf("status should be 400: got %d", res.StatusCode))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			f := fields{
+				svc: automocks.NewMockTodoService(ctrl),
+			}
+			if tt.prepare != nil {
+				tt.prepare(&f)
+			}
+
+			logger := log.NewLogfmtLogger(os.Stderr)
+			zkt, _ := zipkin.NewTracer(nil, zipkin.WithNoopTracer(true))
+			tracer := opentracing.GlobalTracer()
+
+			eps := endpoints.New(f.svc, logger, tracer, zkt)
+			ts := httptest.NewServer(transports.NewHTTPHandler(eps, tracer, zkt, logger))
+			defer ts.Close()
+
+			req := test.TestRequest{
+				Client:      ts.Client(),
+				Method:      tt.args.method,
+				URL:         fmt.Sprintf("%s%s", ts.URL, tt.args.url),
+				ContentType: "application/json",
+				Body:        strings.NewReader(tt.args.body),
+			}
+
+			if res, err := req.Make(); (err != nil) != tt.wantErr {
+				t.Errorf("%s: unexpected error %s", tt.name, err)
+			} else {
+				body, _ := ioutil.ReadAll(res.Body)
+				if tt.checkFunc != nil {
+					tt.checkFunc(res, err, body)
+				}
+			}
+		})
+	}
+}
+
+func TestGraphQLHandler(t *testing.T) {
+	type fields struct {
+		svc *automocks.MockTodoService
+	}
+	type args struct {
+		method, url string
+		body        string
+	}
+
+	todos := func(n int) (res []*model.TodoRes) {
+		for i := 0; i < n; i++ {
+			res = append(res, &model.TodoRes{ID: fmt.Sprintf("todo%d", i)})
+		}
+		return res
+	}
+	streams, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name      string
+		prepare   func(f *fields)
+		opts      []transports.Option
+		args      args
+		wantErr   bool
+		checkFunc func(res *http.Response, err error, body []byte)
+	}{
+		{
+			name: "query todos",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.svc.EXPECT().List(gomock.Any(), gomock.Any()).DoAndReturn(func(_ interface{}, query *model.TodoQuery) ([]*model.TodoRes, error) {
+						assert.Equal(t, []string{"id", "text", "version"}, query.Fields)
+						assert.Equal(t, service.ACTIVE, query.Status)
+						assert.Equal(t, 1, query.Offset)
+						assert.Equal(t, 2, query.Limit)
+						return []*model.TodoRes{{ID: "iKe0KxpurIn0E_6vzUDAr", Text: "aa", Version: 4}}, nil
+					}),
+				)
+			},
+			args: args{
+				method: http.MethodPost,
+				url:    "/graphql",
+				body:   `{"query":"query($limit: Int) { todos(status: \"active\", offset: 1, limit: $limit) { id text version } }","variables":{"limit":2}}`,
+			},
+			checkFunc: func(res *http.Response, err error, body []byte) {
+				assert.Equal(t, http.StatusOK, res.StatusCode, fmt.Sprintf("status should be 200: got %d", res.StatusCode))
+				assert.JSONEq(t, `{"data":{"todos":[{"id":"iKe0KxpurIn0E_6vzUDAr","text":"aa","version":"4"}]}}`, string(body))
+			},
+		},
+		{
+			name: "query todos past the limit",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.svc.EXPECT().List(gomock.Any(), gomock.Any()).DoAndReturn(func(_ interface{}, query *model.TodoQuery) ([]*model.TodoRes, error) {
+						assert.Equal(t, transports.MaxGraphQLTodos, query.Limit)
+						return []*model.TodoRes{}, nil
+					}),
+					f.svc.EXPECT().List(gomock.Any(), gomock.Any()).DoAndReturn(func(_ interface{}, query *model.TodoQuery) ([]*model.TodoRes, error) {
+						assert.Equal(t, transports.MaxGraphQLTodos, query.Limit)
+						return []*model.TodoRes{}, nil
+					}),
+				)
+			},
+			args: args{
+				method: http.MethodPost,
+				url:    "/graphql",
+				body:   `{"query":"query($limit: Int) { all: todos { id } many: todos(limit: $limit) { id } }","variables":{"limit":100000}}`,
+			},
+			checkFunc: func(res *http.Response, err error, body []byte) {
+				assert.Equal(t, http.StatusOK, res.StatusCode, fmt.Sprintf("status should be 200: got %d", res.StatusCode))
+				assert.JSONEq(t, `{"data":{"all":[],"many":[]}}`, string(body))
+			},
+		},
+		{
+			name: "query todos past the limit of the operation",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.svc.EXPECT().List(gomock.Any(), gomock.Any()).DoAndReturn(func(_ interface{}, query *model.TodoQuery) ([]*model.TodoRes, error) {
+						assert.Equal(t, transports.MaxGraphQLTodos, query.Limit)
+						return todos(60), nil
+					}),
+					f.svc.EXPECT().List(gomock.Any(), gomock.Any()).DoAndReturn(func(_ interface{}, query *model.TodoQuery) ([]*model.TodoRes, error) {
+						assert.Equal(t, transports.MaxGraphQLTodos-60, query.Limit)
+						return todos(transports.MaxGraphQLTodos - 60), nil
+					}),
+				)
+			},
+			args: args{
+				method: http.MethodPost,
+				url:    "/graphql",
+				body:   `{"query":"{ a: todos { id } b: todos { id } c: todos { id } }"}`,
+			},
+			checkFunc: func(res *http.Response, err error, body []byte) {
+				assert.Equal(t, http.StatusOK, res.StatusCode, fmt.Sprintf("status should be 200: got %d", res.StatusCode))
+				var v struct {
+					Errors []struct {
+						Extensions map[string]interface{} `json:"extensions"`
+					} `json:"errors"`
+				}
+				assert.Nil(t, json.Unmarshal(body, &v))
+				// the field finding no todos left fails, the others list them
+				// all
+				if assert.Len(t, v.Errors, 1) {
+					assert.Equal(t, float64(http.StatusBadRequest), v.Errors[0].Extensions["code"])
+				}
+			},
+		},
+		{
+			name: "query todo not found",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.svc.EXPECT().Get(gomock.Any(), "iKe0KxpurIn0E_6vzUDAr").Return(nil, service.ErrNotFound),
+				)
+			},
+			args: args{
+				method: http.MethodGet,
+				url:    `/graphql?query={todo(id:"iKe0KxpurIn0E_6vzUDAr"){text}}`,
+			},
+			checkFunc: func(res *http.Response, err error, body []byte) {
+				assert.Equal(t, http.StatusOK, res.StatusCode, fmt.Sprintf("status should be 200: got %d", res.StatusCode))
+				assert.JSONEq(t, `{"data":{"todo":null}}`, string(body))
+			},
+		},
+		{
+			name: "mutation add todo",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.svc.EXPECT().Add(gomock.Any(), &model.TodoReq{Text: func() *string { s := "aa"; return &s }()}).Return(&model.TodoRes{
+						ID:   "iKe0KxpurIn0E_6vzUDAr",
+						Text: "aa",
+					}, nil),
+				)
+			},
+			args: args{
+				method: http.MethodPost,
+				url:    "/graphql",
+				body:   `{"query":"mutation { addTodo(text: \"aa\") { id completed } }"}`,
+			},
+			checkFunc: func(res *http.Response, err error, body []byte) {
+				assert.Equal(t, http.StatusOK, res.StatusCode, fmt.Sprintf("status should be 200: got %d", res.StatusCode))
+				assert.JSONEq(t, `{"data":{"addTodo":{"id":"iKe0KxpurIn0E_6vzUDAr","completed":false}}}`, string(body))
+			},
+		},
+		{
+			name: "mutation toggle all",
+			prepare: func(f *fields) {
+				completed := true
+				gomock.InOrder(
+					f.svc.EXPECT().List(gomock.Any(), &model.TodoQuery{Fields: []string{"id"}, Status: service.ACTIVE, Limit: transports.MaxGraphQLTodos}).Return([]*model.TodoRes{
+						{ID: "iKe0KxpurIn0E_6vzUDAr"},
+						{ID: "zIYPEK0zEpUc7CoQWIGB2"},
+					}, nil),
+					f.svc.EXPECT().Update(gomock.Any(), "iKe0KxpurIn0E_6vzUDAr", &model.TodoReq{Completed: &completed}).Return(&model.TodoRes{ID: "iKe0KxpurIn0E_6vzUDAr", Completed: true}, nil),
+					f.svc.EXPECT().Update(gomock.Any(), "zIYPEK0zEpUc7CoQWIGB2", &model.TodoReq{Completed: &completed}).Return(&model.TodoRes{ID: "zIYPEK0zEpUc7CoQWIGB2", Completed: true}, nil),
+				)
+			},
+			args: args{
+				method: http.MethodPost,
+				url:    "/graphql",
+				body:   `{"query":"mutation { toggleAll(completed: true) { id completed } }"}`,
+			},
+			checkFunc: func(res *http.Response, err error, body []byte) {
+				assert.Equal(t, http.StatusOK, res.StatusCode, fmt.Sprintf("status should be 200: got %d", res.StatusCode))
+				assert.JSONEq(t, `{"data":{"toggleAll":[{"id":"iKe0KxpurIn0E_6vzUDAr","completed":true},{"id":"zIYPEK0zEpUc7CoQWIGB2","completed":true}]}}`, string(body))
+			},
+		},
+		{
+			name: "mutation toggle all past the limit of the operation",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.svc.EXPECT().List(gomock.Any(), &model.TodoQuery{Fields: []string{"id"}, Status: service.ACTIVE, Limit: transports.MaxGraphQLTodos}).Return(todos(transports.MaxGraphQLTodos), nil),
+					f.svc.EXPECT().Update(gomock.Any(), gomock.Any(), gomock.Any()).Return(&model.TodoRes{Completed: true}, nil).Times(transports.MaxGraphQLTodos),
+				)
+			},
+			args: args{
+				method: http.MethodPost,
+				url:    "/graphql",
+				body:   `{"query":"mutation { a: toggleAll(completed: true) { id } b: toggleAll(completed: true) { id } }"}`,
+			},
+			checkFunc: func(res *http.Response, err error, body []byte) {
+				assert.Equal(t, http.StatusOK, res.StatusCode, fmt.Sprintf("status should be 200: got %d", res.StatusCode))
+				var v struct {
+					Errors []struct {
+						Path       []string               `json:"path"`
+						Extensions map[string]interface{} `json:"extensions"`
+					} `json:"errors"`
+				}
+				assert.Nil(t, json.Unmarshal(body, &v))
+				if assert.Len(t, v.Errors, 1) {
+					assert.Equal(t, []string{"b"}, v.Errors[0].Path)
+					assert.Equal(t, float64(http.StatusBadRequest), v.Errors[0].Extensions["code"])
+				}
+			},
+		},
+		{
+			name: "mutation update todo not found",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.svc.EXPECT().Update(gomock.Any(), "iKe0KxpurIn0E_6vzUDAr", gomock.Any()).Return(nil, service.ErrNotFound),
+				)
+			},
+			args: args{
+				method: http.MethodPost,
+				url:    "/graphql",
+				body:   `{"query":"mutation { updateTodo(id: \"iKe0KxpurIn0E_6vzUDAr\", completed: true) { id } }"}`,
+			},
+			checkFunc: func(res *http.Response, err error, body []byte) {
+				assert.Equal(t, http.StatusOK, res.StatusCode, fmt.Sprintf("status should be 200: got %d", res.StatusCode))
+				var v struct {
+					Errors []struct {
+						Message    string                 `json:"message"`
+						Extensions map[string]interface{} `json:"extensions"`
+					} `json:"errors"`
+				}
+				assert.Nil(t, json.Unmarshal(body, &v))
+				if assert.Len(t, v.Errors, 1) {
+					assert.Equal(t, service.ErrNotFound.Error(), v.Errors[0].Message)
+					assert.Equal(t, float64(http.StatusNotFound), v.Errors[0].Extensions["code"])
+				}
+			},
+		},
+		{
+			name: "mutation over get",
+			args: args{
+				method: http.MethodGet,
+				url:    `/graphql?query=mutation{deleteTodo(id:"iKe0KxpurIn0E_6vzUDAr")}`,
+			},
+			checkFunc: func(res *http.Response, err error, body []byte) {
+				assert.Equal(t, http.StatusBadRequest, res.StatusCode, fmt.Sprintf("status should be 400: got %d", res.StatusCode))
+			},
+		},
+		{
+			name: "subscription todo events",
+			prepare: func(f *fields) {
+				events := make(chan *model.TodoEvent, 1)
+				events <- &model.TodoEvent{Seq: 3, Type: model.EventCreated, Todo: &model.TodoRes{ID: "iKe0KxpurIn0E_6vzUDAr"}}
+				close(events)
+				gomock.InOrder(
+					f.svc.EXPECT().Watch(gomock.Any(), &model.EventQuery{Types: []string{model.EventCreated}}).Return(events, nil),
+				)
+			},
+			args: args{
+				method: http.MethodPost,
+				url:    "/graphql",
+				body:   `{"query":"subscription { todoEvents(types: [\"created\"]) { seq type todo { id } } }"}`,
+			},
+			checkFunc: func(res *http.Response, err error, body []byte) {
+				assert.Equal(t, http.StatusOK, res.StatusCode, fmt.Sprintf("status should be 200: got %d", res.StatusCode))
+				assert.Equal(t, "text/event-stream", res.Header.Get("Content-Type"))
+				assert.Equal(t, "event: next\ndata: {\"data\":{\"todoEvents\":{\"seq\":\"3\",\"todo\":{\"id\":\"iKe0KxpurIn0E_6vzUDAr\"},\"type\":\"created\"}}}\n\nevent: complete\ndata:\n\n", string(body))
+			},
+		},
+		{
+			name: "subscription ended when the streams end",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.svc.EXPECT().Watch(gomock.Any(), gomock.Any()).Return(make(<-chan *model.TodoEvent), nil),
+				)
+			},
+			opts: []transports.Option{transports.WithStreamContext(streams)},
+			args: args{
+				method: http.MethodPost,
+				url:    "/graphql",
+				body:   `{"query":"subscription { todoEvents { seq } }"}`,
+			},
+			checkFunc: func(res *http.Response, err error, body []byte) {
+				assert.Equal(t, http.StatusOK, res.StatusCode, fmt.Sprintf("status should be 200: got %d", res.StatusCode))
+				assert.Equal(t, "text/event-stream", res.Header.Get("Content-Type"))
+				assert.Empty(t, body)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			f := fields{
+				svc: automocks.NewMockTodoService(ctrl),
+			}
+			if tt.prepare != nil {
+				tt.prepare(&f)
+			}
+
+			logger := log.NewLogfmtLogger(os.Stderr)
+			zkt, _ := zipkin.NewTracer(nil, zipkin.WithNoopTracer(true))
+			tracer := opentracing.GlobalTracer()
+
+			eps := endpoints.New(f.svc, logger, tracer, zkt)
+			ts := httptest.NewServer(transports.NewHTTPHandler(eps, tracer, zkt, logger, tt.opts...))
+			defer ts.Close()
+
+			req := test.TestRequest{
+				Client:      ts.Client(),
+				Method:      tt.args.method,
+				URL:         fmt.Sprintf("%s%s", ts.URL, tt.args.url),
+				ContentType: "application/json",
+				Body:        strings.NewReader(tt.args.body),
+			}
+
+			if res, err := req.Make(); (err != nil) != tt.wantErr {
+				t.Errorf("%s: unexpected error %s", tt.name, err)
+			} else {
+				body, _ := ioutil.ReadAll(res.Body)
+				if tt.checkFunc != nil {
+					tt.checkFunc(res, err, body)
+				}
+			}
+		})
+	}
+}
+
+func TestGatewayHandler(t *testing.T) {
+	type fields struct {
+		svc *automocks.MockTodoService
+	}
+	type args struct {
+		method, url string
+		body        string
+	}
+
+	tests := []struct {
+		name      string
+		prepare   func(f *fields)
+		args      args
+		wantErr   bool
+		opts      []endpoints.Option
+		checkFunc func(res *http.Response, err error, body []byte)
+	}{
+		{
+			name: "get todo",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.svc.EXPECT().Get(gomock.Any(), "iKe0KxpurIn0E_6vzUDAr").Return(&model.TodoRes{
+						ID:        "iKe0KxpurIn0E_6vzUDAr",
+						CreatedAt: time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC),
+						UpdatedAt: time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC),
+						Text:      "aa",
+						Version:   4,
+					}, nil),
+				)
+			},
+			args: args{
+				method: http.MethodGet,
+				url:    "/v1/items/iKe0KxpurIn0E_6vzUDAr",
+			},
+			checkFunc: func(res *http.Response, err error, body []byte) {
+				assert.Equal(t, http.StatusOK, res.StatusCode, fmt.Sprintf("status should be 200: got %d", res.StatusCode))
+				assert.JSONEq(t, `{"apiVersion":"","data":{"id":"iKe0KxpurIn0E_6vzUDAr","createdAt":"2021-01-01T00:00:00Z","updatedAt":"2021-01-01T00:00:00Z","text":"aa","completed":false,"completedAt":"","archivedAt":"","snoozedUntil":"","version":"4","ownerId":"","tenantId":""}}`, string(body))
+			},
+		},
+		{
+			name: "get todo not found",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.svc.EXPECT().Get(gomock.Any(), "iKe0KxpurIn0E_6vzUDAr").Return(nil, service.ErrNotFound),
+				)
+			},
+			args: args{
+				method: http.MethodGet,
+				url:    "/v1/items/iKe0KxpurIn0E_6vzUDAr",
+			},
+			checkFunc: func(res *http.Response, err error, body []byte) {
+				assert.Equal(t, http.StatusNotFound, res.StatusCode, fmt.Sprintf("status should be 404: got %d", res.StatusCode))
+				assert.JSONEq(t, `{"error":{"code":404,"message":"non-existent entity","errors":[{"message":"non-existent entity"}]}}`, string(body))
+			},
+		},
+		{
+			name: "delete todo",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.svc.EXPECT().Delete(gomock.Any(), "iKe0KxpurIn0E_6vzUDAr").Return(nil),
+				)
+			},
+			args: args{
+				method: http.MethodDelete,
+				url:    "/v1/items/iKe0KxpurIn0E_6vzUDAr",
+			},
+			checkFunc: func(res *http.Response, err error, body []byte) {
+				assert.Equal(t, http.StatusNoContent, res.StatusCode, fmt.Sprintf("status should be 204: got %d", res.StatusCode))
+				assert.Empty(t, body)
+			},
+		},
+		{
+			name: "list todos",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.svc.EXPECT().List(gomock.Any(), gomock.Any()).Return([]*model.TodoRes{{ID: "iKe0KxpurIn0E_6vzUDAr", Text: "aa"}}, nil),
+				)
+			},
+			args: args{
+				method: http.MethodGet,
+				url:    "/v1/items",
+			},
+			checkFunc: func(res *http.Response, err error, body []byte) {
+				assert.Equal(t, http.StatusOK, res.StatusCode, fmt.Sprintf("status should be 200: got %d", res.StatusCode))
+				var data struct {
+					Data []map[string]interface{} `json:"data"`
+				}
+				assert.Nil(t, json.Unmarshal(body, &data))
+				assert.Equal(t, 1, len(data.Data))
+				assert.Equal(t, "aa", data.Data[0]["text"])
+			},
+		},
+		{
+			name: "add todo past the rate limit",
+			args: args{
+				method: http.MethodPost,
+				url:    "/v1/items",
+				body:   `{"text":"aa"}`,
+			},
+			opts: []endpoints.Option{endpoints.WithRateLimit(ratelimit.NewLimiter(ratelimit.Limits{"add": {Rate: 1}}).Middleware)},
+			checkFunc: func(res *http.Response, err error, body []byte) {
+				assert.Equal(t, http.StatusTooManyRequests, res.StatusCode, fmt.Sprintf("status should be 429: got %d", res.StatusCode))
+				assert.Equal(t, "1", res.Header.Get("Retry-After"))
+				assert.JSONEq(t, `{"error":{"code":429,"message":"rate limit exceeded","errors":[{"message":"rate limit exceeded"}]}}`, string(body))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			f := fields{
+				svc: automocks.NewMockTodoService(ctrl),
+			}
+			if tt.prepare != nil {
+				tt.prepare(&f)
+			}
+
+			logger := log.NewLogfmtLogger(os.Stderr)
+			zkt, _ := zipkin.NewTracer(nil, zipkin.WithNoopTracer(true))
+			tracer := opentracing.GlobalTracer()
+
+			eps := endpoints.New(f.svc, logger, tracer, zkt, tt.opts...)
+			ts := httptest.NewServer(transports.NewHTTPHandler(eps, tracer, zkt, logger))
+			defer ts.Close()
+
+			req := test.TestRequest{
+				Client:      ts.Client(),
+				Method:      tt.args.method,
+				URL:         fmt.Sprintf("%s%s", ts.URL, tt.args.url),
+				ContentType: "application/json",
+				Body:        strings.NewReader(tt.args.body),
+			}
+
+			if res, err := req.Make(); (err != nil) != tt.wantErr {
+				t.Errorf("%s: unexpected error %s", tt.name, err)
+			} else {
+				body, _ := ioutil.ReadAll(res.Body)
+				if tt.checkFunc != nil {
+					tt.checkFunc(res, err, body)
+				}
+			}
+		})
+	}
+}
+
+// TestGatewayRoutes checks that every REST route of NewHTTPHandler is also
+// served by the gateway with the same response, and that every HTTP binding of
+// todo.proto is a REST route.
+func TestGatewayRoutes(t *testing.T) {
+	type route struct {
+		body    string
+		prepare func(svc *automocks.MockTodoService)
+	}
+
+	id := "iKe0KxpurIn0E_6vzUDAr"
+	// the routes the gateway does not serve: the event streams, GraphQL and
+	// the documentation
+	rest := map[string]bool{
+		"GET /items/events": true,
+		"GET /ws":           true,
+		"GET /graphql":      true,
+		"POST /graphql":     true,
+		"GET /openapi.json": true,
+		"GET /docs":         true,
+	}
+
+	routes := map[string]route{
+		"POST /items": {
+			body: `{"text":"aa"}`,
+			prepare: func(svc *automocks.MockTodoService) {
+				svc.EXPECT().Add(gomock.Any(), gomock.Any()).Return(&model.TodoRes{ID: id}, nil).Times(2)
+			},
+		},
+		"DELETE /items/:id": {
+			prepare: func(svc *automocks.MockTodoService) { svc.EXPECT().Delete(gomock.Any(), id).Return(nil).Times(2) },
+		},
+		"PATCH /items/:id": {
+			body: `{"completed":true}`,
+			prepare: func(svc *automocks.MockTodoService) {
+				svc.EXPECT().Update(gomock.Any(), id, gomock.Any()).Return(&model.TodoRes{ID: id}, nil).Times(2)
+			},
+		},
+		"GET /items": {
+			prepare: func(svc *automocks.MockTodoService) {
+				svc.EXPECT().List(gomock.Any(), gomock.Any()).Return(nil, nil).Times(2)
+			},
+		},
+		"GET /items/:id": {
+			prepare: func(svc *automocks.MockTodoService) {
+				svc.EXPECT().Get(gomock.Any(), id).Return(&model.TodoRes{ID: id}, nil).Times(2)
+			},
+		},
+		"GET /stats": {
+			prepare: func(svc *automocks.MockTodoService) {
+				svc.EXPECT().Stats(gomock.Any(), gomock.Any()).Return(&model.TodoStats{}, nil).Times(2)
+			},
+		},
+		"POST /items/:id/unarchive": {
+			prepare: func(svc *automocks.MockTodoService) {
+				svc.EXPECT().Unarchive(gomock.Any(), id).Return(&model.TodoRes{ID: id}, nil).Times(2)
+			},
+		},
+		"POST /items/:id/snooze": {
+			body: `{"until":"2030-01-01T09:00:00Z"}`,
+			prepare: func(svc *automocks.MockTodoService) {
+				svc.EXPECT().Snooze(gomock.Any(), id, time.Date(2030, 1, 1, 9, 0, 0, 0, time.UTC)).Return(&model.TodoRes{ID: id}, nil).Times(2)
+			},
+		},
+		"GET /sync": {
+			prepare: func(svc *automocks.MockTodoService) {
+				svc.EXPECT().Sync(gomock.Any(), "").Return(&model.Changes{}, nil).Times(2)
+			},
+		},
+		"POST /sync": {
+			body: `{"mutations":[]}`,
+			prepare: func(svc *automocks.MockTodoService) {
+				svc.EXPECT().Push(gomock.Any(), gomock.Any()).Return(nil, nil).Times(2)
+			},
+		},
+		"POST /shares": {
+			body: `{"todoId":"iKe0KxpurIn0E_6vzUDAr","granteeId":"bob","role":"viewer"}`,
+			prepare: func(svc *automocks.MockTodoService) {
+				svc.EXPECT().AddShare(gomock.Any(), gomock.Any()).Return(&model.Share{ID: "dlyW8C0ypdYd0tiJ0KbcN"}, nil).Times(2)
+			},
+		},
+		"GET /shares": {
+			prepare: func(svc *automocks.MockTodoService) { svc.EXPECT().ListShares(gomock.Any()).Return(nil, nil).Times(2) },
+		},
+		"DELETE /shares/:id": {
+			prepare: func(svc *automocks.MockTodoService) { svc.EXPECT().DeleteShare(gomock.Any(), id).Return(nil).Times(2) },
+		},
+		"POST /admin/api-keys": {
+			body: `{"name":"ci","scopes":["editor"]}`,
+			prepare: func(svc *automocks.MockTodoService) {
+				svc.EXPECT().CreateAPIKey(gomock.Any(), gomock.Any()).Return(&model.CreatedAPIKey{APIKey: model.APIKey{ID: id}, Key: "todo_key"}, nil).Times(2)
+			},
+		},
+		"GET /admin/api-keys": {
+			prepare: func(svc *automocks.MockTodoService) { svc.EXPECT().ListAPIKeys(gomock.Any()).Return(nil, nil).Times(2) },
+		},
+		"DELETE /admin/api-keys/:id": {
+			prepare: func(svc *automocks.MockTodoService) { svc.EXPECT().RevokeAPIKey(gomock.Any(), id).Return(nil).Times(2) },
+		},
+		"GET /usage": {
+			prepare: func(svc *automocks.MockTodoService) {
+				svc.EXPECT().Usage(gomock.Any()).Return(&model.Usage{Todos: 1, Quota: model.Quota{Todos: 10}}, nil).Times(2)
+			},
+		},
+	}
+
+	// serve runs the request on the REST API and on the gateway, and checks
+	// that both responses are the same
+	serve := func(t *testing.T, method, path, body string, prepare func(svc *automocks.MockTodoService)) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		svc := automocks.NewMockTodoService(ctrl)
+		if prepare != nil {
+			prepare(svc)
+		}
+
+		logger := log.NewLogfmtLogger(os.Stderr)
+		zkt, _ := zipkin.NewTracer(nil, zipkin.WithNoopTracer(true))
+		tracer := opentracing.GlobalTracer()
+
+		eps := endpoints.New(svc, logger, tracer, zkt)
+		ts := httptest.NewServer(transports.NewHTTPHandler(eps, tracer, zkt, logger))
+		defer ts.Close()
+
+		var codes []int
+		var bodies [][]byte
+		for _, prefix := range []string{"", transports.GatewayPrefix} {
+			req := test.TestRequest{
+				Client:      ts.Client(),
+				Method:      method,
+				URL:         fmt.Sprintf("%s%s%s", ts.URL, prefix, path),
+				ContentType: "application/json",
+				Body:        strings.NewReader(body),
+			}
+			res, err := req.Make()
+			if err != nil {
+				t.Fatal(err)
+			}
+			b, _ := ioutil.ReadAll(res.Body)
+			codes, bodies = append(codes, res.StatusCode), append(bodies, b)
+		}
+
+		assert.Equal(t, codes[0], codes[1], fmt.Sprintf("%s %s: the gateway status should be %d: got %d %s", method, path, codes[0], codes[1], bodies[1]))
+		if len(bodies[0]) == 0 {
+			assert.Empty(t, bodies[1], fmt.Sprintf("%s %s: the gateway should have no content", method, path))
+			return
+		}
+		// the envelope is the same, the gateway writes the zero values of
+		// the todos as proto does
+		var rest, gw struct {
+			APIVersion string          `json:"apiVersion"`
+			Data       json.RawMessage `json:"data"`
+			Error      json.RawMessage `json:"error"`
+		}
+		assert.Nil(t, json.Unmarshal(bodies[0], &rest), string(bodies[0]))
+		assert.Nil(t, json.Unmarshal(bodies[1], &gw), string(bodies[1]))
+		assert.Equal(t, rest.APIVersion, gw.APIVersion, fmt.Sprintf("%s %s: apiVersion", method, path))
+		assert.Equal(t, len(rest.Data) == 0, len(gw.Data) == 0, fmt.Sprintf("%s %s: data should be %s: got %s", method, path, rest.Data, gw.Data))
+		if len(rest.Error) > 0 {
+			assert.JSONEq(t, string(rest.Error), string(gw.Error), fmt.Sprintf("%s %s: error", method, path))
+		}
+	}
+
+	zkt, _ := zipkin.NewTracer(nil, zipkin.WithNoopTracer(true))
+	m := transports.NewHTTPMux(endpoints.Endpoints{}, opentracing.GlobalTracer(), zkt, log.NewNopLogger())
+
+	t.Run("bindings", func(t *testing.T) {
+		known := map[string]bool{}
+		for method, rs := range m.Routes {
+			for _, r := range rs {
+				known[method+" "+r.Path] = true
+			}
+		}
+
+		fd, err := protoregistry.GlobalFiles.FindFileByPath("todo.proto")
+		if err != nil {
+			t.Fatal(err)
+		}
+		methods := fd.Services().ByName("Todo").Methods()
+		for i := 0; i < methods.Len(); i++ {
+			rule, _ := proto.GetExtension(methods.Get(i).Options(), annotations.E_Http).(*annotations.HttpRule)
+			if rule == nil {
+				continue
+			}
+			var method, path string
+			switch p := rule.Pattern.(type) {
+			case *annotations.HttpRule_Get:
+				method, path = http.MethodGet, p.Get
+			case *annotations.HttpRule_Post:
+				method, path = http.MethodPost, p.Post
+			case *annotations.HttpRule_Patch:
+				method, path = http.MethodPatch, p.Patch
+			case *annotations.HttpRule_Put:
+				method, path = http.MethodPut, p.Put
+			case *annotations.HttpRule_Delete:
+				method, path = http.MethodDelete, p.Delete
+			}
+			path = strings.Replace(path, "{id}", ":id", 1)
+			assert.True(t, known[method+" "+path], fmt.Sprintf("%s: %s %s is not a REST route", methods.Get(i).Name(), method, path))
+		}
+	})
+
+	for method, rs := range m.Routes {
+		for _, rt := range rs {
+			// the wildcard routes serve the gateway and the files of the UI
+			key := method + " " + rt.Path
+			if strings.HasSuffix(rt.Path, "/*") || rest[key] {
+				continue
+			}
+			r, ok := routes[key]
+			t.Run(key, func(t *testing.T) {
+				if !ok {
+					t.Fatalf("%s has no gateway case", key)
+				}
+				serve(t, method, strings.Replace(rt.Path, ":id", id, 1), r.body, r.prepare)
+			})
+		}
+	}
+
+	t.Run("errors", func(t *testing.T) {
+		serve(t, http.MethodGet, "/items/"+id, "", func(svc *automocks.MockTodoService) {
+			svc.EXPECT().Get(gomock.Any(), id).Return(nil, service.ErrNotFound).Times(2)
+		})
+		serve(t, http.MethodPost, "/items", `{"text":"aa"}`, func(svc *automocks.MockTodoService) {
+			svc.EXPECT().Add(gomock.Any(), gomock.Any()).Return(nil, errors.Wrap(service.ErrQuotaExceeded, errors.New("100 todos"))).Times(2)
+		})
+		serve(t, http.MethodPost, "/items", `{"text":`, nil)
+	})
+}
+
+func TestOpenAPIHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	logger := log.NewLogfmtLogger(os.Stderr)
+	zkt, _ := zipkin.NewTracer(nil, zipkin.WithNoopTracer(true))
+	tracer := opentracing.GlobalTracer()
+
+	eps := endpoints.New(automocks.NewMockTodoService(ctrl), logger, tracer, zkt)
+	ts := httptest.NewServer(transports.NewHTTPHandler(eps, tracer, zkt, logger))
+	defer ts.Close()
+
+	get := func(path string) (*http.Response, []byte) {
+		res, err := ts.Client().Get(ts.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer res.Body.Close()
+		body, _ := ioutil.ReadAll(res.Body)
+		return res, body
+	}
+
+	t.Run("document", func(t *testing.T) {
+		res, body := get("/openapi.json")
+		assert.Equal(t, http.StatusOK, res.StatusCode, fmt.Sprintf("status should be 200: got %d", res.StatusCode))
+		assert.Equal(t, "application/json", res.Header.Get("Content-Type"))
+
+		var doc struct {
+			OpenAPI    string                                       `json:"openapi"`
+			Paths      map[string]map[string]map[string]interface{} `json:"paths"`
+			Components map[string]map[string]interface{}            `json:"components"`
+		}
+		if err := json.Unmarshal(body, &doc); err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, "3.0.3", doc.OpenAPI)
+		for _, name := range []string{"DataRes", "ErrorRes", "TodoRes", "TodoReq", "TodoEvent", "Changes", "MutationResult"} {
+			assert.Contains(t, doc.Components["schemas"], name)
+		}
+
+		// every reference resolves to a component
+		for _, ref := range regexp.MustCompile(`"\$ref":"#/components/(\w+)/(\w+)"`).FindAllStringSubmatch(string(body), -1) {
+			assert.Contains(t, doc.Components[ref[1]], ref[2], fmt.Sprintf("%s/%s is not a component", ref[1], ref[2]))
+		}
+
+		add := doc.Paths["/items"]["post"]
+		assert.Equal(t, `{"$ref":"#/components/schemas/TodoReq"}`, jsonString(add["requestBody"].(map[string]interface{})["content"].(map[string]interface{})["application/json"].(map[string]interface{})["schema"]))
+		assert.Contains(t, jsonString(add["responses"]), `"201"`)
+		assert.Contains(t, jsonString(add["responses"]), `{"$ref":"#/components/responses/Error"}`)
+		assert.Contains(t, doc.Paths, "/items/{id}")
+	})
+
+	t.Run("ui", func(t *testing.T) {
+		res, body := get("/docs")
+		assert.Equal(t, http.StatusOK, res.StatusCode, fmt.Sprintf("status should be 200: got %d", res.StatusCode))
+		assert.Contains(t, string(body), "swagger-ui-bundle.js")
+
+		res, body = get("/docs/swagger-initializer.js")
+		assert.Equal(t, http.StatusOK, res.StatusCode, fmt.Sprintf("status should be 200: got %d", res.StatusCode))
+		assert.Contains(t, string(body), `url: "/openapi.json"`)
+
+		res, _ = get("/docs/swagger-ui-bundle.js")
+		assert.Equal(t, http.StatusOK, res.StatusCode, fmt.Sprintf("status should be 200: got %d", res.StatusCode))
+	})
+}
+
+// TestOpenAPIRoutes checks that every route of NewHTTPHandler is in the
+// OpenAPI document, and that every operation of the document is a route.
+func TestOpenAPIRoutes(t *testing.T) {
+	logger := log.NewNopLogger()
+	zkt, _ := zipkin.NewTracer(nil, zipkin.WithNoopTracer(true))
+	tracer := opentracing.GlobalTracer()
+	m := transports.NewHTTPMux(endpoints.Endpoints{}, tracer, zkt, logger)
+
+	b, err := json.Marshal(transports.NewOpenAPI())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc struct {
+		Paths map[string]map[string]interface{} `json:"paths"`
+	}
+	if err := json.Unmarshal(b, &doc); err != nil {
+		t.Fatal(err)
+	}
+
+	routes := map[string]bool{}
+	for method, rs := range m.Routes {
+		for _, r := range rs {
+			// the wildcard routes serve the gateway, documented by
+			// todo.proto, and the files of the UI
+			if strings.HasSuffix(r.Path, "/*") {
+				continue
+			}
+			path := regexp.MustCompile(`:(\w+)`).ReplaceAllString(r.Path, "{$1}")
+			routes[strings.ToLower(method)+" "+path] = true
+			assert.Contains(t, doc.Paths[path], strings.ToLower(method), fmt.Sprintf("%s %s has no OpenAPI operation", method, r.Path))
+		}
+	}
+	for path, item := range doc.Paths {
+		for method := range item {
+			assert.True(t, routes[method+" "+path], fmt.Sprintf("%s %s is not a route", method, path))
+		}
+	}
+}
+
+func jsonString(v interface{}) string {
+	b, _ := json.Marshal(v)
+	return string(b)
+}
+
+func TestEventsHandler(t *testing.T) {
+	type fields struct {
+		svc *automocks.MockTodoService
+	}
+	type args struct {
+		method, url string
+	}
+
+	events := func(evs ...*model.TodoEvent) <-chan *model.TodoEvent {
+		ch := make(chan *model.TodoEvent, len(evs))
+		for _, ev := range evs {
+			ch <- ev
+		}
+		close(ch)
+		return ch
+	}
+	at := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	streams, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name      string
+		prepare   func(f *fields)
+		opts      []transports.Option
+		args      args
+		wantErr   bool
+		checkFunc func(res *http.Response, err error, body []byte)
+	}{
+		{
+			name: "stream events from the last event id",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.svc.EXPECT().Watch(gomock.Any(), &model.EventQuery{Types: []string{"created", "deleted"}, Since: 2}).Return(events(
+						&model.TodoEvent{Seq: 3, Type: model.EventCreated, Todo: &model.TodoRes{ID: "iKe0KxpurIn0E_6vzUDAr"}, Time: at},
+						&model.TodoEvent{Seq: 4, Type: model.EventDeleted, Todo: &model.TodoRes{ID: "iKe0KxpurIn0E_6vzUDAr"}, Time: at},
+					), nil),
+				)
+			},
+			args: args{
+				method: http.MethodGet,
+				url:    "/items/events?types=created,deleted&lastEventId=2",
+			},
+			checkFunc: func(res *http.Response, err error, body []byte) {
+				assert.Equal(t, http.StatusOK, res.StatusCode, fmt.Sprintf("status should be 200: got %d", res.StatusCode))
+				assert.Equal(t, "text/event-stream", res.Header.Get("Content-Type"))
+				assert.Contains(t, string(body), "id: 3\nevent: created\ndata: {\"seq\":3,\"type\":\"created\"")
+				assert.Contains(t, string(body), "id: 4\nevent: deleted\n")
+			},
+		},
+		{
+			name: "stream events from an expired event id",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.svc.EXPECT().Watch(gomock.Any(), &model.EventQuery{Since: 2}).Return(nil, service.ErrSequenceExpired),
+					f.svc.EXPECT().Watch(gomock.Any(), &model.EventQuery{}).Return(events(), nil),
+				)
+			},
+			args: args{
+				method: http.MethodGet,
+				url:    "/items/events?lastEventId=2",
+			},
+			checkFunc: func(res *http.Response, err error, body []byte) {
+				assert.Equal(t, http.StatusOK, res.StatusCode, fmt.Sprintf("status should be 200: got %d", res.StatusCode))
+				assert.Equal(t, "id\nevent: reset\ndata: {}\n\n", string(body))
+			},
+		},
+		{
+			name: "stream events with a malformed event id",
+			args: args{
+				method: http.MethodGet,
+				url:    "/items/events?lastEventId=abc",
+			},
+			checkFunc: func(res *http.Response, err error, body []byte) {
+				assert.Equal(t, http.StatusBadRequest, res.StatusCode, fmt.Sprintf("status should be 400: got %d", res.StatusCode))
+			},
+		},
+		{
+			name: "stream heartbeats while idle",
+			prepare: func(f *fields) {
+				ch := make(chan *model.TodoEvent)
+				time.AfterFunc(100*time.Millisecond, func() { close(ch) })
+				gomock.InOrder(
+					f.svc.EXPECT().Watch(gomock.Any(), gomock.Any()).Return((<-chan *model.TodoEvent)(ch), nil),
+				)
+			},
+			args: args{
+				method: http.MethodGet,
+				url:    "/items/events",
+			},
+			checkFunc: func(res *http.Response, err error, body []byte) {
+				assert.Contains(t, string(body), ": heartbeat\n\n")
+			},
+		},
+		{
+			name: "end the stream when the streams end",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.svc.EXPECT().Watch(gomock.Any(), gomock.Any()).Return(make(<-chan *model.TodoEvent), nil),
+				)
+			},
+			opts: []transports.Option{transports.WithStreamContext(streams)},
+			args: args{
+				method: http.MethodGet,
+				url:    "/items/events",
+			},
+			checkFunc: func(res *http.Response, err error, body []byte) {
+				assert.Equal(t, http.StatusOK, res.StatusCode, fmt.Sprintf("status should be 200: got %d", res.StatusCode))
+				assert.Equal(t, ": watching\n\n", string(body))
+			},
+		},
+	}
+
+	heartbeat := transports.HeartbeatInterval
+	transports.HeartbeatInterval = 10 * time.Millisecond
+	defer func() { transports.HeartbeatInterval = heartbeat }()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			f := fields{
+				svc: automocks.NewMockTodoService(ctrl),
+			}
+			if tt.prepare != nil {
+				tt.prepare(&f)
+			}
+
+			logger := log.NewLogfmtLogger(os.Stderr)
+			zkt, _ := zipkin.NewTracer(nil, zipkin.WithNoopTracer(true))
+			tracer := opentracing.GlobalTracer()
+
+			eps := endpoints.New(f.svc, logger, tracer, zkt)
+			ts := httptest.NewServer(transports.NewHTTPHandler(eps, tracer, zkt, logger, tt.opts...))
+			defer ts.Close()
+
+			req := test.TestRequest{
+				Client: ts.Client(),
+				Method: tt.args.method,
+				URL:    fmt.Sprintf("%s%s", ts.URL, tt.args.url),
+			}
+
+			if res, err := req.Make(); (err != nil) != tt.wantErr {
+				t.Errorf("%s: unexpected error %s", tt.name, err)
+			} else {
+				body, _ := ioutil.ReadAll(res.Body)
+				if tt.checkFunc != nil {
+					tt.checkFunc(res, err, body)
+				}
+			}
+		})
+	}
+}
+
+func TestWebSocketHandler(t *testing.T) {
+	type fields struct {
+		svc    *automocks.MockTodoService
+		events chan *model.TodoEvent
+	}
+
+	type message struct {
+		ID    string          `json:"id"`
+		Type  string          `json:"type"`
+		Data  json.RawMessage `json:"data"`
+		Error *struct {
+			Code int `json:"code"`
+		} `json:"error"`
+	}
+
+	tests := []struct {
+		name      string
+		prepare   func(f *fields)
+		send      string
+		checkFunc func(f *fields, conn *websocket.Conn)
+	}{
+		{
+			name: "websocket add todo",
+			prepare: func(f *fields) {
+				f.svc.EXPECT().Add(gomock.Any(), gomock.Any()).Return(&model.TodoRes{
+					ID:   "iKe0KxpurIn0E_6vzUDAr",
+					Text: "aa",
+				}, nil)
+			},
+			send: `{"id":"1","type":"add","data":{"todo":{"text":"aa"}}}`,
+			checkFunc: func(f *fields, conn *websocket.Conn) {
+				var msg message
+				assert.Nil(t, conn.ReadJSON(&msg))
+				assert.Equal(t, "1", msg.ID)
+				assert.Equal(t, "add", msg.Type)
+				assert.Contains(t, string(msg.Data), `"text":"aa"`)
+
+				f.events <- &model.TodoEvent{Seq: 1, Type: model.EventCreated, Todo: &model.TodoRes{ID: "iKe0KxpurIn0E_6vzUDAr"}}
+				var ev message
+				assert.Nil(t, conn.ReadJSON(&ev))
+				assert.Equal(t, "", ev.ID)
+				assert.Equal(t, "event", ev.Type)
+				assert.Contains(t, string(ev.Data), `"seq":1`)
+			},
+		},
+		{
+			name: "websocket delete todo without id",
+			send: `{"id":"2","type":"delete","data":{}}`,
+			checkFunc: func(f *fields, conn *websocket.Conn) {
+				var msg message
+				assert.Nil(t, conn.ReadJSON(&msg))
+				assert.Equal(t, "2", msg.ID)
+				assert.Equal(t, http.StatusBadRequest, msg.Error.Code)
+			},
+		},
+		{
+			name: "websocket unknown command",
+			send: `{"id":"3","type":"archive","data":{}}`,
+			checkFunc: func(f *fields, conn *websocket.Conn) {
+				var msg message
+				assert.Nil(t, conn.ReadJSON(&msg))
+				assert.Equal(t, "3", msg.ID)
+				assert.Equal(t, http.StatusBadRequest, msg.Error.Code)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			f := fields{
+				svc:    automocks.NewMockTodoService(ctrl),
+				events: make(chan *model.TodoEvent, 1),
+			}
+			f.svc.EXPECT().Watch(gomock.Any(), gomock.Any()).Return((<-chan *model.TodoEvent)(f.events), nil)
+			if tt.prepare != nil {
+				tt.prepare(&f)
+			}
+
+			logger := log.NewLogfmtLogger(os.Stderr)
+			zkt, _ := zipkin.NewTracer(nil, zipkin.WithNoopTracer(true))
+			tracer := opentracing.GlobalTracer()
+
+			eps := endpoints.New(f.svc, logger, tracer, zkt)
+			ts := httptest.NewServer(transports.NewHTTPHandler(eps, tracer, zkt, logger))
+			defer ts.Close()
+
+			conn, _, err := websocket.DefaultDialer.Dial(strings.Replace(ts.URL, "http", "ws", 1)+"/ws", nil)
+			if err != nil {
+				t.Fatalf("unable to Dial: %+v", err)
+			}
+			defer conn.Close()
+
+			if err := conn.WriteMessage(websocket.TextMessage, []byte(tt.send)); err != nil {
+				t.Fatalf("unable to write: %+v", err)
+			}
+			tt.checkFunc(&f, conn)
+		})
+	}
+}
+
+func TestWebSocketOrigins(t *testing.T) {
+	tests := []struct {
+		name       string
+		opts       []transports.Option
+		origin     string
+		wantStatus int
+	}{
+		{
+			name:       "websocket without origin",
+			wantStatus: http.StatusSwitchingProtocols,
+		},
+		{
+			name:       "websocket from the same origin",
+			origin:     "http://{host}",
+			wantStatus: http.StatusSwitchingProtocols,
+		},
+		{
+			name:       "websocket from another origin",
+			origin:     "https://evil.example.com",
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "websocket from an allowed origin",
+			opts:       []transports.Option{transports.WithAllowedOrigins("https://todo.example.com/")},
+			origin:     "https://TODO.example.com",
+			wantStatus: http.StatusSwitchingProtocols,
+		},
+		{
+			name:       "websocket from any origin",
+			opts:       []transports.Option{transports.WithAllowedOrigins("*")},
+			origin:     "https://evil.example.com",
+			wantStatus: http.StatusSwitchingProtocols,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			svc := automocks.NewMockTodoService(ctrl)
+			svc.EXPECT().Watch(gomock.Any(), gomock.Any()).Return(make(<-chan *model.TodoEvent), nil).AnyTimes()
+
+			logger := log.NewLogfmtLogger(os.Stderr)
+			zkt, _ := zipkin.NewTracer(nil, zipkin.WithNoopTracer(true))
+			tracer := opentracing.GlobalTracer()
+
+			eps := endpoints.New(svc, logger, tracer, zkt)
+			ts := httptest.NewServer(transports.NewHTTPHandler(eps, tracer, zkt, logger, tt.opts...))
+			defer ts.Close()
+
+			header := http.Header{}
+			if tt.origin != "" {
+				header.Set("Origin", strings.Replace(tt.origin, "{host}", strings.TrimPrefix(ts.URL, "http://"), 1))
+			}
+			conn, res, err := websocket.DefaultDialer.Dial(strings.Replace(ts.URL, "http", "ws", 1)+"/ws", header)
+			if conn != nil {
+				conn.Close()
+			}
+			if res == nil {
+				t.Fatalf("unable to Dial: %+v", err)
+			}
+			assert.Equal(t, tt.wantStatus, res.StatusCode, fmt.Sprintf("status should be %d: got %d", tt.wantStatus, res.StatusCode))
+		})
+	}
+}
+
+func TestHTTPClient(t *testing.T) {
+	type fields struct {
+		svc *automocks.MockTodoService
+	}
+
+	id := "iKe0KxpurIn0E_6vzUDAr"
+	created := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	todo := &model.TodoRes{ID: id, CreatedAt: created, UpdatedAt: created, Text: "aa", Version: 4}
+	text, completed := "aa", true
+
+	tests := []struct {
+		name      string
+		prepare   func(f *fields)
+		checkFunc func(ctx context.Context, client service.TodoService)
+	}{
+		{
+			name: "add todo",
+			prepare: func(f *fields) {
+				f.svc.EXPECT().Add(gomock.Any(), &model.TodoReq{Text: &text}).Return(todo, nil)
+			},
+			checkFunc: func(ctx context.Context, client service.TodoService) {
+				res, err := client.Add(ctx, &model.TodoReq{Text: &text})
+				assert.Nil(t, err)
+				assert.Equal(t, todo, res)
+			},
+		},
+		{
+			name: "add todo with the jwt of the context",
+			prepare: func(f *fields) {
+				f.svc.EXPECT().Add(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, _ *model.TodoReq) (*model.TodoRes, error) {
+					assert.Equal(t, "token", ctx.Value(kitjwt.JWTTokenContextKey))
+					return todo, nil
+				})
+			},
+			checkFunc: func(ctx context.Context, client service.TodoService) {
+				_, err := client.Add(context.WithValue(ctx, kitjwt.JWTTokenContextKey, "token"), &model.TodoReq{Text: &text})
+				assert.Nil(t, err)
+			},
+		},
+		{
+			name: "add todo with a wrapped error",
+			prepare: func(f *fields) {
+				f.svc.EXPECT().Add(gomock.Any(), gomock.Any()).Return(nil, errors.Wrap(service.ErrMalformedEntity, errors.New("text is required")))
+			},
+			checkFunc: func(ctx context.Context, client service.TodoService) {
+				_, err := client.Add(ctx, &model.TodoReq{Text: &text})
+				assert.True(t, errors.Contains(errors.Cast(err), service.ErrMalformedEntity), fmt.Sprintf("error should contain %s: got %v", service.ErrMalformedEntity, err))
+				assert.Equal(t, "malformed entity specification → text is required", err.Error())
+			},
+		},
+		{
+			name: "delete todo",
+			prepare: func(f *fields) {
+				f.svc.EXPECT().Delete(gomock.Any(), id).Return(nil)
+			},
+			checkFunc: func(ctx context.Context, client service.TodoService) {
+				assert.Nil(t, client.Delete(ctx, id))
+			},
+		},
+		{
+			name: "update todo conflict",
+			prepare: func(f *fields) {
+				f.svc.EXPECT().Update(gomock.Any(), id, &model.TodoReq{Completed: &completed}).Return(nil, service.ErrConflict)
+			},
+			checkFunc: func(ctx context.Context, client service.TodoService) {
+				_, err := client.Update(ctx, id, &model.TodoReq{Completed: &completed})
+				assert.True(t, errors.Contains(errors.Cast(err), service.ErrConflict), fmt.Sprintf("error should contain %s: got %v", service.ErrConflict, err))
+			},
+		},
+		{
+			name: "list todos",
+			prepare: func(f *fields) {
+				f.svc.EXPECT().List(gomock.Any(), &model.TodoQuery{
+					Fields:          []string{"id", "text"},
+					Sort:            "-createdAt",
+					Status:          service.ACTIVE,
+					IncludeArchived: true,
+					IncludeSnoozed:  true,
+					CompletedAfter:  &created,
+					Offset:          10,
+					Limit:           5,
+				}).Return([]*model.TodoRes{todo}, nil)
+			},
+			checkFunc: func(ctx context.Context, client service.TodoService) {
+				res, err := client.List(ctx, &model.TodoQuery{
+					Fields:          []string{"id", "text"},
+					Sort:            "-createdAt",
+					Status:          service.ACTIVE,
+					IncludeArchived: true,
+					IncludeSnoozed:  true,
+					CompletedAfter:  &created,
+					Offset:          10,
+					Limit:           5,
+				})
+				assert.Nil(t, err)
+				assert.Equal(t, []*model.TodoRes{{ID: id, Text: "aa"}}, res)
+			},
+		},
+		{
+			name: "get todo not found",
+			prepare: func(f *fields) {
+				f.svc.EXPECT().Get(gomock.Any(), "a/b").Return(nil, service.ErrNotFound)
+			},
+			checkFunc: func(ctx context.Context, client service.TodoService) {
+				_, err := client.Get(ctx, "a/b")
+				assert.True(t, errors.Contains(errors.Cast(err), service.ErrNotFound), fmt.Sprintf("error should contain %s: got %v", service.ErrNotFound, err))
+			},
+		},
+		{
+			name: "stats",
+			prepare: func(f *fields) {
+				f.svc.EXPECT().Stats(gomock.Any(), 7).Return(&model.TodoStats{Total: 3, Active: 1, Completed: 2}, nil)
+			},
+			checkFunc: func(ctx context.Context, client service.TodoService) {
+				res, err := client.Stats(ctx, 7)
+				assert.Nil(t, err)
+				assert.Equal(t, &model.TodoStats{Total: 3, Active: 1, Completed: 2}, res)
+			},
+		},
+		{
+			name: "unarchive and snooze todo",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.svc.EXPECT().Unarchive(gomock.Any(), id).Return(todo, nil),
+					f.svc.EXPECT().Snooze(gomock.Any(), id, created).Return(todo, nil),
+					f.svc.EXPECT().Snooze(gomock.Any(), id, time.Time{}).Return(todo, nil),
+				)
+			},
+			checkFunc: func(ctx context.Context, client service.TodoService) {
+				res, err := client.Unarchive(ctx, id)
+				assert.Nil(t, err)
+				assert.Equal(t, todo, res)
+				res, err = client.Snooze(ctx, id, created)
+				assert.Nil(t, err)
+				assert.Equal(t, todo, res)
+				res, err = client.Snooze(ctx, id, time.Time{})
+				assert.Nil(t, err)
+				assert.Equal(t, todo, res)
+			},
+		},
+		{
+			name: "share todo",
+			prepare: func(f *fields) {
+				share := &model.Share{ID: "dlyW8C0ypdYd0tiJ0KbcN", OwnerID: "alice", TodoID: id, GranteeID: "bob", Role: model.RoleViewer, CreatedAt: created}
+				gomock.InOrder(
+					f.svc.EXPECT().AddShare(gomock.Any(), &model.ShareReq{TodoID: id, GranteeID: "bob", Role: model.RoleViewer}).Return(share, nil),
+					f.svc.EXPECT().ListShares(gomock.Any()).Return([]*model.Share{share}, nil),
+					f.svc.EXPECT().List(gomock.Any(), &model.TodoQuery{Shared: true}).Return([]*model.TodoRes{todo}, nil),
+					f.svc.EXPECT().DeleteShare(gomock.Any(), "dlyW8C0ypdYd0tiJ0KbcN").Return(nil),
+				)
+			},
+			checkFunc: func(ctx context.Context, client service.TodoService) {
+				share := &model.Share{ID: "dlyW8C0ypdYd0tiJ0KbcN", OwnerID: "alice", TodoID: id, GranteeID: "bob", Role: model.RoleViewer, CreatedAt: created}
+				res, err := client.AddShare(ctx, &model.ShareReq{TodoID: id, GranteeID: "bob", Role: model.RoleViewer})
+				assert.Nil(t, err)
+				assert.Equal(t, share, res)
+				shares, err := client.ListShares(ctx)
+				assert.Nil(t, err)
+				assert.Equal(t, []*model.Share{share}, shares)
+				todos, err := client.List(ctx, &model.TodoQuery{Shared: true})
+				assert.Nil(t, err)
+				assert.Equal(t, []*model.TodoRes{todo}, todos)
+				assert.Nil(t, client.DeleteShare(ctx, "dlyW8C0ypdYd0tiJ0KbcN"))
+			},
+		},
+		{
+			name: "sync and push",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.svc.EXPECT().Sync(gomock.Any(), "4").Return(&model.Changes{Todos: []*model.TodoRes{todo}, Deleted: []*model.Tombstone{}, Token: "5"}, nil),
+					f.svc.EXPECT().Push(gomock.Any(), []*model.Mutation{{Op: model.MutationDelete, ID: id, Version: 4}}).Return([]*model.MutationResult{{ID: id, Status: "applied"}}, nil),
+				)
+			},
+			checkFunc: func(ctx context.Context, client service.TodoService) {
+				changes, err := client.Sync(ctx, "4")
+				assert.Nil(t, err)
+				assert.Equal(t, &model.Changes{Todos: []*model.TodoRes{todo}, Deleted: []*model.Tombstone{}, Token: "5"}, changes)
+				results, err := client.Push(ctx, []*model.Mutation{{Op: model.MutationDelete, ID: id, Version: 4}})
+				assert.Nil(t, err)
+				assert.Equal(t, []*model.MutationResult{{ID: id, Status: "applied"}}, results)
+			},
+		},
+		{
+			name: "watch events",
+			prepare: func(f *fields) {
+				events := make(chan *model.TodoEvent, 2)
+				events <- &model.TodoEvent{Seq: 5, Type: model.EventCreated, Todo: todo, Time: created}
+				events <- &model.TodoEvent{Seq: 6, Type: model.EventDeleted, Todo: &model.TodoRes{ID: id}, Time: created}
+				close(events)
+				f.svc.EXPECT().Watch(gomock.Any(), &model.EventQuery{Types: []string{model.EventCreated, model.EventDeleted}, Since: 4}).Return(events, nil)
+			},
+			checkFunc: func(ctx context.Context, client service.TodoService) {
+				events, err := client.Watch(ctx, &model.EventQuery{Types: []string{model.EventCreated, model.EventDeleted}, Since: 4})
+				if !assert.Nil(t, err) {
+					return
+				}
+				var seqs []uint64
+				for ev := range events {
+					seqs = append(seqs, ev.Seq)
+				}
+				assert.Equal(t, []uint64{5, 6}, seqs)
+			},
+		},
+		{
+			name: "watch expired events",
+			prepare: func(f *fields) {
+				events := make(chan *model.TodoEvent)
+				close(events)
+				gomock.InOrder(
+					f.svc.EXPECT().Watch(gomock.Any(), &model.EventQuery{Since: 4}).Return(nil, service.ErrSequenceExpired),
+					f.svc.EXPECT().Watch(gomock.Any(), &model.EventQuery{}).Return(events, nil),
+				)
+			},
+			checkFunc: func(ctx context.Context, client service.TodoService) {
+				_, err := client.Watch(ctx, &model.EventQuery{Since: 4})
+				assert.True(t, errors.Contains(errors.Cast(err), service.ErrSequenceExpired), fmt.Sprintf("error should contain %s: got %v", service.ErrSequenceExpired, err))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			f := fields{
+				svc: automocks.NewMockTodoService(ctrl),
+			}
+			if tt.prepare != nil {
+				tt.prepare(&f)
+			}
+
+			logger := log.NewNopLogger()
+			zkt, _ := zipkin.NewTracer(nil, zipkin.WithNoopTracer(true))
+			tracer := opentracing.GlobalTracer()
+
+			eps := endpoints.New(f.svc, logger, tracer, zkt)
+			ts := httptest.NewServer(transports.NewHTTPHandler(eps, tracer, zkt, logger))
+			defer ts.Close()
+
+			client, err := transports.NewHTTPClient(ts.URL, tracer, zkt, logger)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			tt.checkFunc(ctx, client)
+		})
+	}
+}
+
+func TestAuthn(t *testing.T) {
+	type fields struct {
+		svc *automocks.MockTodoService
+	}
+	type args struct {
+		method, url string
+		token       string
+		apiKey      string
+	}
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	jwks, _ := json.Marshal(map[string]interface{}{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": "rsa-1",
+			"alg": "RS256",
+			"n":   base64.RawURLEncoding.EncodeToString(rsaKey.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(rsaKey.E)).Bytes()),
+		}},
+	})
+	jwksFile, err := ioutil.TempFile("", "jwks")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(jwksFile.Name())
+	_, _ = jwksFile.Write(jwks)
+	_ = jwksFile.Close()
+
+	keys, err := authn.LoadKeys("secret", "", jwksFile.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sign := func(method jwt.SigningMethod, kid string, key interface{}, claims jwt.StandardClaims) string {
+		token := jwt.NewWithClaims(method, claims)
+		if kid != "" {
+			token.Header["kid"] = kid
+		}
+		s, err := token.SignedString(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return s
+	}
+	alice := jwt.StandardClaims{Subject: "alice", ExpiresAt: time.Now().Add(time.Hour).Unix()}
+	apiKeys := apiKeys{"todo_alice": {StandardClaims: jwt.StandardClaims{Subject: "alice"}}}
+	getAsAlice := func(f *fields) {
+		gomock.InOrder(
+			f.svc.EXPECT().Get(gomock.Any(), "iKe0KxpurIn0E_6vzUDAr").DoAndReturn(func(ctx context.Context, id string) (*model.TodoRes, error) {
+				claims, ok := authn.FromContext(ctx)
+				assert.True(t, ok, "claims should be on the context")
+				assert.Equal(t, "alice", claims.Subject)
+				return &model.TodoRes{ID: id}, nil
+			}),
+		)
+	}
+
+	tests := []struct {
+		name      string
+		prepare   func(f *fields)
+		args      args
+		wantErr   bool
+		opts      []authn.ParserOption
+		checkFunc func(res *http.Response, err error, body []byte)
+	}{
+		{
+			name: "request without a token",
+			args: args{
+				method: http.MethodGet,
+				url:    "/items/iKe0KxpurIn0E_6vzUDAr",
+			},
+			checkFunc: func(res *http.Response, err error, body []byte) {
+				assert.Equal(t, http.StatusUnauthorized, res.StatusCode, fmt.Sprintf("status should be 401: got %d", res.StatusCode))
+			},
+		},
+		{
+			name:    "request with an HS256 token",
+			prepare: getAsAlice,
+			args: args{
+				method: http.MethodGet,
+				url:    "/items/iKe0KxpurIn0E_6vzUDAr",
+				token:  sign(jwt.SigningMethodHS256, "", []byte("secret"), alice),
+			},
+			checkFunc: func(res *http.Response, err error, body []byte) {
+				assert.Equal(t, http.StatusOK, res.StatusCode, fmt.Sprintf("status should be 200: got %d", res.StatusCode))
+			},
+		},
+		{
+			name:    "request with an RS256 token of the JWKS",
+			prepare: getAsAlice,
+			args: args{
+				method: http.MethodGet,
+				url:    "/items/iKe0KxpurIn0E_6vzUDAr",
+				token:  sign(jwt.SigningMethodRS256, "rsa-1", rsaKey, alice),
+			},
+			checkFunc: func(res *http.Response, err error, body []byte) {
+				assert.Equal(t, http.StatusOK, res.StatusCode, fmt.Sprintf("status should be 200: got %d", res.StatusCode))
+			},
+		},
+		{
+			name: "request with a token of another secret",
+			args: args{
+				method: http.MethodGet,
+				url:    "/items/iKe0KxpurIn0E_6vzUDAr",
+				token:  sign(jwt.SigningMethodHS256, "", []byte("other"), alice),
+			},
+			checkFunc: func(res *http.Response, err error, body []byte) {
+				assert.Equal(t, http.StatusUnauthorized, res.StatusCode, fmt.Sprintf("status should be 401: got %d", res.StatusCode))
+			},
+		},
+		{
+			name: "request with a token signed with the public key as a secret",
+			args: args{
+				method: http.MethodGet,
+				url:    "/items/iKe0KxpurIn0E_6vzUDAr",
+				token:  sign(jwt.SigningMethodHS256, "rsa-1", rsaKey.N.Bytes(), alice),
+			},
+			checkFunc: func(res *http.Response, err error, body []byte) {
+				assert.Equal(t, http.StatusUnauthorized, res.StatusCode, fmt.Sprintf("status should be 401: got %d", res.StatusCode))
+			},
+		},
+		{
+			name: "request with an expired token",
+			args: args{
+				method: http.MethodGet,
+				url:    "/items/iKe0KxpurIn0E_6vzUDAr",
+				token:  sign(jwt.SigningMethodHS256, "", []byte("secret"), jwt.StandardClaims{Subject: "alice", ExpiresAt: time.Now().Add(-time.Hour).Unix()}),
+			},
+			checkFunc: func(res *http.Response, err error, body []byte) {
+				assert.Equal(t, http.StatusUnauthorized, res.StatusCode, fmt.Sprintf("status should be 401: got %d", res.StatusCode))
+				assert.Contains(t, string(body), kitjwt.ErrTokenExpired.Error())
+			},
+		},
+		{
+			name: "request with a token without a subject",
+			args: args{
+				method: http.MethodGet,
+				url:    "/items/iKe0KxpurIn0E_6vzUDAr",
+				token:  sign(jwt.SigningMethodHS256, "", []byte("secret"), jwt.StandardClaims{ExpiresAt: time.Now().Add(time.Hour).Unix()}),
+			},
+			checkFunc: func(res *http.Response, err error, body []byte) {
+				assert.Equal(t, http.StatusUnauthorized, res.StatusCode, fmt.Sprintf("status should be 401: got %d", res.StatusCode))
+				assert.Contains(t, string(body), authn.ErrNoSubject.Error())
+			},
+		},
+		{
+			name: "request with a token without an expiry",
+			args: args{
+				method: http.MethodGet,
+				url:    "/items/iKe0KxpurIn0E_6vzUDAr",
+				token:  sign(jwt.SigningMethodHS256, "", []byte("secret"), jwt.StandardClaims{Subject: "alice"}),
+			},
+			checkFunc: func(res *http.Response, err error, body []byte) {
+				assert.Equal(t, http.StatusUnauthorized, res.StatusCode, fmt.Sprintf("status should be 401: got %d", res.StatusCode))
+				assert.Contains(t, string(body), authn.ErrNoExpiry.Error())
+			},
+		},
+		{
+			name:    "request with a token without an expiry accepted",
+			prepare: getAsAlice,
+			args: args{
+				method: http.MethodGet,
+				url:    "/items/iKe0KxpurIn0E_6vzUDAr",
+				token:  sign(jwt.SigningMethodHS256, "", []byte("secret"), jwt.StandardClaims{Subject: "alice"}),
+			},
+			opts: []authn.ParserOption{authn.WithoutExpiry()},
+			checkFunc: func(res *http.Response, err error, body []byte) {
+				assert.Equal(t, http.StatusOK, res.StatusCode, fmt.Sprintf("status should be 200: got %d", res.StatusCode))
+			},
+		},
+		{
+			name:    "request with an API key as the token",
+			prepare: getAsAlice,
+			args: args{
+				method: http.MethodGet,
+				url:    "/items/iKe0KxpurIn0E_6vzUDAr",
+				token:  "todo_alice",
+			},
+			checkFunc: func(res *http.Response, err error, body []byte) {
+				assert.Equal(t, http.StatusOK, res.StatusCode, fmt.Sprintf("status should be 200: got %d", res.StatusCode))
+			},
+		},
+		{
+			name:    "request with an API key header",
+			prepare: getAsAlice,
+			args: args{
+				method: http.MethodGet,
+				url:    "/items/iKe0KxpurIn0E_6vzUDAr",
+				apiKey: "todo_alice",
+			},
+			checkFunc: func(res *http.Response, err error, body []byte) {
+				assert.Equal(t, http.StatusOK, res.StatusCode, fmt.Sprintf("status should be 200: got %d", res.StatusCode))
+			},
+		},
+		{
+			name:    "gateway request with an API key header",
+			prepare: getAsAlice,
+			args: args{
+				method: http.MethodGet,
+				url:    transports.GatewayPrefix + "/items/iKe0KxpurIn0E_6vzUDAr",
+				apiKey: "todo_alice",
+			},
+			checkFunc: func(res *http.Response, err error, body []byte) {
+				assert.Equal(t, http.StatusOK, res.StatusCode, fmt.Sprintf("status should be 200: got %d", res.StatusCode))
+			},
+		},
+		{
+			name: "request with an unknown API key",
+			args: args{
+				method: http.MethodGet,
+				url:    "/items/iKe0KxpurIn0E_6vzUDAr",
+				apiKey: "todo_bob",
+			},
+			checkFunc: func(res *http.Response, err error, body []byte) {
+				assert.Equal(t, http.StatusUnauthorized, res.StatusCode, fmt.Sprintf("status should be 401: got %d", res.StatusCode))
+			},
+		},
+		{
+			name: "stream events with a token in the query",
+			prepare: func(f *fields) {
+				ch := make(chan *model.TodoEvent)
+				close(ch)
+				gomock.InOrder(
+					f.svc.EXPECT().Watch(gomock.Any(), gomock.Any()).Return((<-chan *model.TodoEvent)(ch), nil),
+				)
+			},
+			args: args{
+				method: http.MethodGet,
+				url:    "/items/events?access_token=" + sign(jwt.SigningMethodHS256, "", []byte("secret"), alice),
+			},
+			checkFunc: func(res *http.Response, err error, body []byte) {
+				assert.Equal(t, http.StatusOK, res.StatusCode, fmt.Sprintf("status should be 200: got %d", res.StatusCode))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			f := fields{
+				svc: automocks.NewMockTodoService(ctrl),
+			}
+			if tt.prepare != nil {
+				tt.prepare(&f)
+			}
+
+			logger := log.NewLogfmtLogger(os.Stderr)
+			zkt, _ := zipkin.NewTracer(nil, zipkin.WithNoopTracer(true))
+			tracer := opentracing.GlobalTracer()
+
+			eps := endpoints.AuthnMiddleware(authn.NewParser(keys, append(tt.opts, authn.WithAPIKeys(apiKeys))...), endpoints.New(f.svc, logger, tracer, zkt))
+			ts := httptest.NewServer(transports.NewHTTPHandler(eps, tracer, zkt, logger))
+			defer ts.Close()
+
+			req := test.TestRequest{
+				Client: ts.Client(),
+				Method: tt.args.method,
+				URL:    fmt.Sprintf("%s%s", ts.URL, tt.args.url),
+				Token:  tt.args.token,
+			}
+			if tt.args.apiKey != "" {
+				req.Header = http.Header{authn.APIKeyHeader: {tt.args.apiKey}}
+			}
+
+			if res, err := req.Make(); (err != nil) != tt.wantErr {
+				t.Errorf("%s: unexpected error %s", tt.name, err)
+			} else {
+				body, _ := ioutil.ReadAll(res.Body)
+				if tt.checkFunc != nil {
+					tt.checkFunc(res, err, body)
+				}
+			}
+		})
+	}
+}
+
+// apiKeys authenticates the API keys it maps to their claims.
+type apiKeys map[string]*authn.Claims
+
+func (k apiKeys) Authenticate(_ context.Context, key string) (*authn.Claims, error) {
+	if claims, ok := k[key]; ok {
+		return claims, nil
+	}
+	return nil, service.ErrNotFound
+}
+
+func TestAuthz(t *testing.T) {
+	type fields struct {
+		svc *automocks.MockTodoService
+	}
+	type args struct {
+		method, url string
+		body        string
+		roles       []string
+		tenant      string
+	}
+
+	policyFile, err := ioutil.TempFile("", "policy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(policyFile.Name())
+	_, _ = policyFile.WriteString(`{"roles": {"viewer": ["todo:list", "todo:get"], "editor": ["todo:*"]}, "subjects": {"acme/alice": ["editor"]}}`)
+	_ = policyFile.Close()
+
+	keys, _ := authn.LoadKeys("secret", "", "")
+	policy, err := authz.NewFileEngine(policyFile.Name(), log.NewNopLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		prepare   func(f *fields)
+		args      args
+		wantErr   bool
+		checkFunc func(res *http.Response, err error, body []byte)
+	}{
+		{
+			name: "list todos as a viewer",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.svc.EXPECT().List(gomock.Any(), gomock.Any()).Return([]*model.TodoRes{}, nil),
+				)
+			},
+			args: args{
+				method: http.MethodGet,
+				url:    "/items",
+				roles:  []string{"viewer"},
+			},
+			checkFunc: func(res *http.Response, err error, body []byte) {
+				assert.Equal(t, http.StatusOK, res.StatusCode, fmt.Sprintf("status should be 200: got %d", res.StatusCode))
+			},
+		},
+		{
+			name: "add todo as a viewer",
+			args: args{
+				method: http.MethodPost,
+				url:    "/items",
+				body:   `{"text":"aa"}`,
+				roles:  []string{"viewer"},
+			},
+			checkFunc: func(res *http.Response, err error, body []byte) {
+				assert.Equal(t, http.StatusForbidden, res.StatusCode, fmt.Sprintf("status should be 403: got %d", res.StatusCode))
+			},
+		},
+		{
+			name: "add todo as an editor",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.svc.EXPECT().Add(gomock.Any(), gomock.Any()).Return(&model.TodoRes{ID: "iKe0KxpurIn0E_6vzUDAr", Text: "aa"}, nil),
+				)
+			},
+			args: args{
+				method: http.MethodPost,
+				url:    "/items",
+				body:   `{"text":"aa"}`,
+				roles:  []string{"viewer", "editor"},
+			},
+			checkFunc: func(res *http.Response, err error, body []byte) {
+				assert.Equal(t, http.StatusCreated, res.StatusCode, fmt.Sprintf("status should be 201: got %d", res.StatusCode))
+			},
+		},
+		{
+			name: "add todo as an editor of the policy",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.svc.EXPECT().Add(gomock.Any(), gomock.Any()).Return(&model.TodoRes{ID: "iKe0KxpurIn0E_6vzUDAr", Text: "aa"}, nil),
+				)
+			},
+			args: args{
+				method: http.MethodPost,
+				url:    "/items",
+				body:   `{"text":"aa"}`,
+				tenant: "acme",
+			},
+			checkFunc: func(res *http.Response, err error, body []byte) {
+				assert.Equal(t, http.StatusCreated, res.StatusCode, fmt.Sprintf("status should be 201: got %d", res.StatusCode))
+			},
+		},
+		{
+			name: "add todo as the same subject of another tenant",
+			args: args{
+				method: http.MethodPost,
+				url:    "/items",
+				body:   `{"text":"aa"}`,
+				tenant: "globex",
+			},
+			checkFunc: func(res *http.Response, err error, body []byte) {
+				assert.Equal(t, http.StatusForbidden, res.StatusCode, fmt.Sprintf("status should be 403: got %d", res.StatusCode))
+			},
+		},
+		{
+			name: "list todos without a role",
+			args: args{
+				method: http.MethodGet,
+				url:    "/items",
+			},
+			checkFunc: func(res *http.Response, err error, body []byte) {
+				assert.Equal(t, http.StatusForbidden, res.StatusCode, fmt.Sprintf("status should be 403: got %d", res.StatusCode))
+			},
+		},
+	}
+
+	run := func(t *testing.T, prepare func(f *fields), args args, checkFunc func(res *http.Response, err error, body []byte)) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		f := fields{
+			svc: automocks.NewMockTodoService(ctrl),
+		}
+		if prepare != nil {
+			prepare(&f)
+		}
+
+		logger := log.NewLogfmtLogger(os.Stderr)
+		zkt, _ := zipkin.NewTracer(nil, zipkin.WithNoopTracer(true))
+		tracer := opentracing.GlobalTracer()
+
+		eps := endpoints.New(f.svc, logger, tracer, zkt)
+		eps = endpoints.AuthzMiddleware(authz.NewAuthorizer(policy), eps)
+		eps = endpoints.AuthnMiddleware(authn.NewParser(keys), eps)
+		ts := httptest.NewServer(transports.NewHTTPHandler(eps, tracer, zkt, logger))
+		defer ts.Close()
+
+		token, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, &authn.Claims{StandardClaims: jwt.StandardClaims{Subject: "alice", ExpiresAt: time.Now().Add(time.Hour).Unix()}, Tenant: args.tenant, Roles: args.roles}).SignedString([]byte("secret"))
+		req := test.TestRequest{
+			Client:      ts.Client(),
+			Method:      args.method,
+			URL:         fmt.Sprintf("%s%s", ts.URL, args.url),
+			ContentType: "application/json",
+			Token:       token,
+			Body:        strings.NewReader(args.body),
+		}
+
+		res, err := req.Make()
+		if err != nil {
+			t.Fatalf("unexpected error %s", err)
+		}
+		body, _ := ioutil.ReadAll(res.Body)
+		checkFunc(res, err, body)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			run(t, tt.prepare, tt.args, tt.checkFunc)
+		})
+	}
+
+	t.Run("add todo as a viewer once the policy is reloaded", func(t *testing.T) {
+		if err := ioutil.WriteFile(policyFile.Name(), []byte(`{"roles": {"viewer": ["todo:list", "todo:get", "todo:add"]}}`), 0644); err != nil {
+			t.Fatal(err)
+		}
+		later := time.Now().Add(time.Minute)
+		_ = os.Chtimes(policyFile.Name(), later, later)
+		reloaded, err := policy.Reload()
+		assert.NoError(t, err)
+		assert.True(t, reloaded, "the policy should be reloaded")
+
+		run(t, func(f *fields) {
+			gomock.InOrder(
+				f.svc.EXPECT().Add(gomock.Any(), gomock.Any()).Return(&model.TodoRes{ID: "iKe0KxpurIn0E_6vzUDAr", Text: "aa"}, nil),
+			)
+		}, args{method: http.MethodPost, url: "/items", body: `{"text":"aa"}`, roles: []string{"viewer"}}, func(res *http.Response, err error, body []byte) {
+			assert.Equal(t, http.StatusCreated, res.StatusCode, fmt.Sprintf("status should be 201: got %d", res.StatusCode))
+		})
+	})
+
+	t.Run("keep the policy when the reloaded one is invalid", func(t *testing.T) {
+		if err := ioutil.WriteFile(policyFile.Name(), []byte(`{"roles": {"viewer": ["todo"]}}`), 0644); err != nil {
+			t.Fatal(err)
+		}
+		later := time.Now().Add(2 * time.Minute)
+		_ = os.Chtimes(policyFile.Name(), later, later)
+		_, err := policy.Reload()
+		assert.Error(t, err)
+
+		run(t, func(f *fields) {
+			gomock.InOrder(
+				f.svc.EXPECT().List(gomock.Any(), gomock.Any()).Return([]*model.TodoRes{}, nil),
+			)
+		}, args{method: http.MethodGet, url: "/items", roles: []string{"viewer"}}, func(res *http.Response, err error, body []byte) {
+			assert.Equal(t, http.StatusOK, res.StatusCode, fmt.Sprintf("status should be 200: got %d", res.StatusCode))
+		})
+	})
+
+	t.Run("reject a subject without its tenant", func(t *testing.T) {
+		_, err := authz.ParsePolicy([]byte(`{"roles": {"editor": ["todo:*"]}, "subjects": {"alice": ["editor"]}}`))
+		assert.Error(t, err)
+	})
+}
+
+func TestRateLimit(t *testing.T) {
+	type fields struct {
+		svc *automocks.MockTodoService
+	}
+	type request struct {
+		method, url string
+		body        string
+		subject     string
+		token       string
+	}
+	type args struct {
+		requests []request
+	}
+
+	var (
+		aliceAdd = request{method: http.MethodPost, url: "/items", body: `{"text":"aa"}`, subject: "alice"}
+		bobAdd   = request{method: http.MethodPost, url: "/items", body: `{"text":"aa"}`, subject: "bob"}
+		aliceGet = request{method: http.MethodGet, url: "/items/iKe0KxpurIn0E_6vzUDAr", subject: "alice"}
+		anonAdd  = request{method: http.MethodPost, url: "/items", body: `{"text":"aa"}`}
+		guessGet = request{method: http.MethodGet, url: "/items/iKe0KxpurIn0E_6vzUDAr", token: "guess"}
+		// the callers may add a todo a day
+		limits = ratelimit.Limits{"add": {Rate: 1.0 / 86400, Burst: 1}}
+		// an address may fail authentication once a day
+		failures = ratelimit.Limits{ratelimit.DefaultMethod: {Rate: 1.0 / 86400, Burst: 1}}
+	)
+
+	keys, _ := authn.LoadKeys("secret", "", "")
+
+	tests := []struct {
+		name      string
+		prepare   func(f *fields)
+		args      args
+		authn     bool
+		failures  bool
+		checkFunc func(res *http.Response, body []byte)
+	}{
+		{
+			name: "add todos past the limit",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.svc.EXPECT().Add(gomock.Any(), gomock.Any()).Return(&model.TodoRes{ID: "iKe0KxpurIn0E_6vzUDAr", Text: "aa"}, nil),
+				)
+			},
+			args:  args{requests: []request{aliceAdd, aliceAdd}},
+			authn: true,
+			checkFunc: func(res *http.Response, body []byte) {
+				assert.Equal(t, http.StatusTooManyRequests, res.StatusCode, fmt.Sprintf("status should be 429: got %d", res.StatusCode))
+				assert.Equal(t, "86400", res.Header.Get("Retry-After"), fmt.Sprintf("Retry-After should be 86400: got %s", res.Header.Get("Retry-After")))
+				assert.Equal(t, "1", res.Header.Get("RateLimit-Limit"), fmt.Sprintf("RateLimit-Limit should be 1: got %s", res.Header.Get("RateLimit-Limit")))
+				assert.Equal(t, "0", res.Header.Get("RateLimit-Remaining"), fmt.Sprintf("RateLimit-Remaining should be 0: got %s", res.Header.Get("RateLimit-Remaining")))
+				assert.Contains(t, string(body), ratelimit.ErrLimited.Error())
+			},
+		},
+		{
+			name: "add todos of other callers",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.svc.EXPECT().Add(gomock.Any(), gomock.Any()).Return(&model.TodoRes{ID: "iKe0KxpurIn0E_6vzUDAr", Text: "aa"}, nil).Times(2),
+				)
+			},
+			args:  args{requests: []request{aliceAdd, bobAdd}},
+			authn: true,
+			checkFunc: func(res *http.Response, body []byte) {
+				assert.Equal(t, http.StatusCreated, res.StatusCode, fmt.Sprintf("status should be 201: got %d", res.StatusCode))
+			},
+		},
+		{
+			name: "get todo past the limit of add",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.svc.EXPECT().Add(gomock.Any(), gomock.Any()).Return(&model.TodoRes{ID: "iKe0KxpurIn0E_6vzUDAr", Text: "aa"}, nil),
+					f.svc.EXPECT().Get(gomock.Any(), "iKe0KxpurIn0E_6vzUDAr").Return(&model.TodoRes{ID: "iKe0KxpurIn0E_6vzUDAr", Text: "aa"}, nil),
+				)
+			},
+			args:  args{requests: []request{aliceAdd, aliceGet}},
+			authn: true,
+			checkFunc: func(res *http.Response, body []byte) {
+				assert.Equal(t, http.StatusOK, res.StatusCode, fmt.Sprintf("status should be 200: got %d", res.StatusCode))
+			},
+		},
+		{
+			name: "add todos anonymously past the limit of the address",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.svc.EXPECT().Add(gomock.Any(), gomock.Any()).Return(&model.TodoRes{ID: "iKe0KxpurIn0E_6vzUDAr", Text: "aa"}, nil),
+				)
+			},
+			args: args{requests: []request{anonAdd, anonAdd}},
+			checkFunc: func(res *http.Response, body []byte) {
+				assert.Equal(t, http.StatusTooManyRequests, res.StatusCode, fmt.Sprintf("status should be 429: got %d", res.StatusCode))
+			},
+		},
+		{
+			name: "add todos through the gateway past the limit",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.svc.EXPECT().Add(gomock.Any(), gomock.Any()).Return(&model.TodoRes{ID: "iKe0KxpurIn0E_6vzUDAr", Text: "aa"}, nil),
+				)
+			},
+			args: args{requests: []request{
+				{method: http.MethodPost, url: transports.GatewayPrefix + "/items", body: `{"text":"aa"}`},
+				{method: http.MethodPost, url: transports.GatewayPrefix + "/items", body: `{"text":"aa"}`},
+			}},
+			checkFunc: func(res *http.Response, body []byte) {
+				assert.Equal(t, http.StatusTooManyRequests, res.StatusCode, fmt.Sprintf("status should be 429: got %d", res.StatusCode))
+			},
+		},
+		{
+			name:     "guess credentials past the failure limit",
+			args:     args{requests: []request{guessGet, guessGet}},
+			authn:    true,
+			failures: true,
+			checkFunc: func(res *http.Response, body []byte) {
+				assert.Equal(t, http.StatusTooManyRequests, res.StatusCode, fmt.Sprintf("status should be 429: got %d", res.StatusCode))
+				assert.Equal(t, "86400", res.Header.Get("Retry-After"), fmt.Sprintf("Retry-After should be 86400: got %s", res.Header.Get("Retry-After")))
+			},
+		},
+		{
+			name:     "get todo from an address past the failure limit",
+			args:     args{requests: []request{guessGet, aliceGet}},
+			authn:    true,
+			failures: true,
+			checkFunc: func(res *http.Response, body []byte) {
+				assert.Equal(t, http.StatusTooManyRequests, res.StatusCode, fmt.Sprintf("status should be 429: got %d", res.StatusCode))
+			},
+		},
+		{
+			name: "get todos authenticated under the failure limit",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.svc.EXPECT().Get(gomock.Any(), "iKe0KxpurIn0E_6vzUDAr").Return(&model.TodoRes{ID: "iKe0KxpurIn0E_6vzUDAr", Text: "aa"}, nil).Times(2),
+				)
+			},
+			args:     args{requests: []request{aliceGet, aliceGet}},
+			authn:    true,
+			failures: true,
+			checkFunc: func(res *http.Response, body []byte) {
+				assert.Equal(t, http.StatusOK, res.StatusCode, fmt.Sprintf("status should be 200: got %d", res.StatusCode))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			f := fields{
+				svc: automocks.NewMockTodoService(ctrl),
+			}
+			if tt.prepare != nil {
+				tt.prepare(&f)
+			}
+
+			logger := log.NewLogfmtLogger(os.Stderr)
+			zkt, _ := zipkin.NewTracer(nil, zipkin.WithNoopTracer(true))
+			tracer := opentracing.GlobalTracer()
+
+			eps := endpoints.New(f.svc, logger, tracer, zkt, endpoints.WithRateLimit(ratelimit.NewLimiter(limits).Middleware))
+			if tt.authn {
+				parser := authn.NewParser(keys)
+				if tt.failures {
+					parser = endpoint.Chain(ratelimit.NewLimiter(failures).FailureMiddleware(authn.Failed), parser)
+				}
+				eps = endpoints.AuthnMiddleware(parser, eps)
+			}
+			ts := httptest.NewServer(transports.NewHTTPHandler(eps, tracer, zkt, logger))
+			defer ts.Close()
+
+			var (
+				res  *http.Response
+				body []byte
+			)
+			for _, r := range tt.args.requests {
+				req := test.TestRequest{
+					Client:      ts.Client(),
+					Method:      r.method,
+					URL:         fmt.Sprintf("%s%s", ts.URL, r.url),
+					ContentType: "application/json",
+					Body:        strings.NewReader(r.body),
+				}
+				if r.token != "" {
+					req.Token = r.token
+				}
+				if r.subject != "" {
+					req.Token, _ = jwt.NewWithClaims(jwt.SigningMethodHS256, &authn.Claims{StandardClaims: jwt.StandardClaims{Subject: r.subject, ExpiresAt: time.Now().Add(time.Hour).Unix()}}).SignedString([]byte("secret"))
+				}
+
+				var err error
+				if res, err = req.Make(); err != nil {
+					t.Fatalf("unexpected error %s", err)
+				}
+				body, _ = ioutil.ReadAll(res.Body)
+				res.Body.Close()
+			}
+			tt.checkFunc(res, body)
+		})
+	}
+}
+
+func TestAuthnFailureLimit(t *testing.T) {
+	type fields struct {
+		svc *automocks.MockTodoService
+	}
+	type request struct {
+		token     string
+		forwarded string
+	}
+	type args struct {
+		requests   []request
+		concurrent bool
+	}
+
+	keys, _ := authn.LoadKeys("secret", "", "")
+	valid, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, &authn.Claims{StandardClaims: jwt.StandardClaims{Subject: "alice", ExpiresAt: time.Now().Add(time.Hour).Unix()}}).SignedString([]byte("secret"))
+
+	var (
+		// an address may fail authentication once a day
+		limits   = ratelimit.Limits{ratelimit.DefaultMethod: {Rate: 1.0 / 86400, Burst: 1}}
+		loopback = ratelimit.Proxies{{IP: net.IPv4(127, 0, 0, 0), Mask: net.CIDRMask(8, 32)}}
+		others   = ratelimit.Proxies{{IP: net.IPv4(192, 0, 2, 0), Mask: net.CIDRMask(24, 32)}}
+		inFlight = 11
+	)
+
+	tests := []struct {
+		name      string
+		prepare   func(f *fields)
+		args      args
+		proxies   ratelimit.Proxies
+		checkFunc func(statuses []int)
+	}{
+		{
+			name: "get todos concurrently under the failure limit",
+			prepare: func(f *fields) {
+				var started sync.WaitGroup
+				started.Add(inFlight)
+				all := make(chan struct{})
+				go func() { started.Wait(); close(all) }()
+				gomock.InOrder(
+					f.svc.EXPECT().Get(gomock.Any(), "iKe0KxpurIn0E_6vzUDAr").DoAndReturn(func(context.Context, string) (*model.TodoRes, error) {
+						// every request is in flight at once
+						started.Done()
+						select {
+						case <-all:
+						case <-time.After(time.Second):
+						}
+						return &model.TodoRes{ID: "iKe0KxpurIn0E_6vzUDAr", Text: "aa"}, nil
+					}).MaxTimes(inFlight),
+				)
+			},
+			args: args{requests: func() (requests []request) {
+				for i := 0; i < inFlight; i++ {
+					requests = append(requests, request{token: valid})
+				}
+				return requests
+			}(), concurrent: true},
+			checkFunc: func(statuses []int) {
+				for _, status := range statuses {
+					assert.Equal(t, http.StatusOK, status, fmt.Sprintf("status should be 200: got %d", status))
+				}
+			},
+		},
+		{
+			name: "get todo behind a trusted proxy failing for another client",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.svc.EXPECT().Get(gomock.Any(), "iKe0KxpurIn0E_6vzUDAr").Return(&model.TodoRes{ID: "iKe0KxpurIn0E_6vzUDAr", Text: "aa"}, nil),
+				)
+			},
+			args: args{requests: []request{
+				{token: "guess", forwarded: "203.0.113.1"},
+				{token: "guess", forwarded: "203.0.113.1"},
+				{token: valid, forwarded: "203.0.113.2"},
+			}},
+			proxies: loopback,
+			checkFunc: func(statuses []int) {
+				assert.Equal(t, []int{http.StatusUnauthorized, http.StatusTooManyRequests, http.StatusOK}, statuses, fmt.Sprintf("statuses should be 401, 429, 200: got %v", statuses))
+			},
+		},
+		{
+			name: "get todo behind an untrusted proxy failing for another client",
+			args: args{requests: []request{
+				{token: "guess", forwarded: "203.0.113.1"},
+				{token: valid, forwarded: "203.0.113.2"},
+			}},
+			checkFunc: func(statuses []int) {
+				assert.Equal(t, []int{http.StatusUnauthorized, http.StatusTooManyRequests}, statuses, fmt.Sprintf("statuses should be 401, 429: got %v", statuses))
+			},
+		},
+		{
+			name: "guess credentials forging the forwarded address",
+			args: args{requests: []request{
+				{token: "guess", forwarded: "203.0.113.1"},
+				{token: "guess", forwarded: "203.0.113.2"},
+			}},
+			proxies: others,
+			checkFunc: func(statuses []int) {
+				assert.Equal(t, []int{http.StatusUnauthorized, http.StatusTooManyRequests}, statuses, fmt.Sprintf("statuses should be 401, 429: got %v", statuses))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			f := fields{
+				svc: automocks.NewMockTodoService(ctrl),
+			}
+			if tt.prepare != nil {
+				tt.prepare(&f)
+			}
+
+			logger := log.NewLogfmtLogger(os.Stderr)
+			zkt, _ := zipkin.NewTracer(nil, zipkin.WithNoopTracer(true))
+			tracer := opentracing.GlobalTracer()
+
+			failures := ratelimit.NewLimiter(limits, ratelimit.WithTrustedProxies(tt.proxies))
+			eps := endpoints.New(f.svc, logger, tracer, zkt)
+			eps = endpoints.AuthnMiddleware(endpoint.Chain(failures.FailureMiddleware(authn.Failed), authn.NewParser(keys)), eps)
+			ts := httptest.NewServer(transports.NewHTTPHandler(eps, tracer, zkt, logger))
+			defer ts.Close()
+
+			statuses := make([]int, len(tt.args.requests))
+			do := func(i int, r request) {
+				req := test.TestRequest{
+					Client: ts.Client(),
+					Method: http.MethodGet,
+					URL:    fmt.Sprintf("%s/items/iKe0KxpurIn0E_6vzUDAr", ts.URL),
+					Token:  r.token,
+				}
+				if r.forwarded != "" {
+					req.Header = http.Header{"X-Forwarded-For": {r.forwarded}}
+				}
+				res, err := req.Make()
+				if err != nil {
+					t.Errorf("unexpected error %s", err)
+					return
+				}
+				res.Body.Close()
+				statuses[i] = res.StatusCode
+			}
+
+			var wg sync.WaitGroup
+			for i, r := range tt.args.requests {
+				if !tt.args.concurrent {
+					do(i, r)
+					continue
+				}
+				wg.Add(1)
+				go func(i int, r request) {
+					defer wg.Done()
+					do(i, r)
+				}(i, r)
+			}
+			wg.Wait()
+			tt.checkFunc(statuses)
+		})
+	}
+}
+
+func TestInstrumenting(t *testing.T) {
+	type fields struct {
+		svc *automocks.MockTodoService
+	}
+	type request struct {
+		method, url string
+		body        string
+	}
+	type args struct {
+		requests []request
+	}
+	type vecs struct {
+		requests, errors *stdprometheus.CounterVec
+		duration         *stdprometheus.HistogramVec
+	}
+
+	id := "iKe0KxpurIn0E_6vzUDAr"
+	keys, _ := authn.LoadKeys("secret", "", "")
+
+	tests := []struct {
+		name      string
+		prepare   func(f *fields)
+		args      args
+		authn     bool
+		checkFunc func(v vecs)
+	}{
+		{
+			name: "add todo",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.svc.EXPECT().Add(gomock.Any(), gomock.Any()).Return(&model.TodoRes{ID: id}, nil),
+				)
+			},
+			args: args{requests: []request{{method: http.MethodPost, url: "/items", body: `{"text":"aa"}`}}},
+			checkFunc: func(v vecs) {
+				assert.Equal(t, 1.0, testutil.ToFloat64(v.requests.WithLabelValues("add", instrumenting.HTTP)), "requests: expected 1")
+				assert.Equal(t, 0.0, testutil.ToFloat64(v.errors.WithLabelValues("add", instrumenting.HTTP)), "errors: expected 0")
+			},
+		},
+		{
+			name: "get todo not found",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.svc.EXPECT().Get(gomock.Any(), id).Return(nil, service.ErrNotFound),
+				)
+			},
+			args: args{requests: []request{{method: http.MethodGet, url: "/items/" + id}}},
+			checkFunc: func(v vecs) {
+				assert.Equal(t, 1.0, testutil.ToFloat64(v.requests.WithLabelValues("get", instrumenting.HTTP)), "requests: expected 1")
+				assert.Equal(t, 1.0, testutil.ToFloat64(v.errors.WithLabelValues("get", instrumenting.HTTP)), "errors: expected 1")
+			},
+		},
+		{
+			name: "add todo through the gateway",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.svc.EXPECT().Add(gomock.Any(), gomock.Any()).Return(&model.TodoRes{ID: id}, nil),
+				)
+			},
+			args: args{requests: []request{{method: http.MethodPost, url: transports.GatewayPrefix + "/items", body: `{"text":"aa"}`}}},
+			checkFunc: func(v vecs) {
+				assert.Equal(t, 1.0, testutil.ToFloat64(v.requests.WithLabelValues("add", instrumenting.Gateway)), "requests: expected 1")
+				assert.Equal(t, 0.0, testutil.ToFloat64(v.requests.WithLabelValues("add", instrumenting.GRPC)), "requests: expected the gateway only")
+			},
+		},
+		{
+			name:  "add todo unauthenticated",
+			args:  args{requests: []request{{method: http.MethodPost, url: "/items", body: `{"text":"aa"}`}}},
+			authn: true,
+			checkFunc: func(v vecs) {
+				assert.Equal(t, 1.0, testutil.ToFloat64(v.errors.WithLabelValues("add", instrumenting.HTTP)), "errors: expected 1")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			f := fields{
+				svc: automocks.NewMockTodoService(ctrl),
+			}
+			if tt.prepare != nil {
+				tt.prepare(&f)
+			}
+
+			logger := log.NewLogfmtLogger(os.Stderr)
+			zkt, _ := zipkin.NewTracer(nil, zipkin.WithNoopTracer(true))
+			tracer := opentracing.GlobalTracer()
+
+			v := vecs{
+				requests: stdprometheus.NewCounterVec(stdprometheus.CounterOpts{Name: "requests_total"}, []string{"method", "transport"}),
+				errors:   stdprometheus.NewCounterVec(stdprometheus.CounterOpts{Name: "request_errors_total"}, []string{"method", "transport"}),
+				duration: stdprometheus.NewHistogramVec(stdprometheus.HistogramOpts{Name: "request_duration_seconds"}, []string{"method", "transport"}),
+			}
+			m := instrumenting.Endpoint{
+				Requests: kitprometheus.NewCounter(v.requests),
+				Errors:   kitprometheus.NewCounter(v.errors),
+				Duration: kitprometheus.NewHistogram(v.duration),
+			}
+
+			eps := endpoints.New(f.svc, logger, tracer, zkt)
+			if tt.authn {
+				eps = endpoints.AuthnMiddleware(authn.NewParser(keys), eps)
+			}
+			eps = endpoints.InstrumentingMiddleware(m.Middleware, eps)
+			ts := httptest.NewServer(transports.NewHTTPHandler(eps, tracer, zkt, logger))
+			defer ts.Close()
+
+			for _, r := range tt.args.requests {
+				req := test.TestRequest{
+					Client:      ts.Client(),
+					Method:      r.method,
+					URL:         fmt.Sprintf("%s%s", ts.URL, r.url),
+					ContentType: "application/json",
+					Body:        strings.NewReader(r.body),
+				}
+				res, err := req.Make()
+				if err != nil {
+					t.Fatalf("unexpected error %s", err)
+				}
+				res.Body.Close()
+			}
+			tt.checkFunc(v)
+		})
+	}
+}
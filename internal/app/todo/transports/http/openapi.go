@@ -0,0 +1,617 @@
+package transports
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-zoo/bone"
+	swaggerFiles "github.com/swaggo/files"
+
+	"github.com/cage1016/gokit-todo/internal/app/todo/endpoints"
+	"github.com/cage1016/gokit-todo/internal/app/todo/model"
+	"github.com/cage1016/gokit-todo/internal/app/todo/service"
+	"github.com/cage1016/gokit-todo/internal/pkg/authn"
+	"github.com/cage1016/gokit-todo/internal/pkg/responses"
+)
+
+// object is a JSON object of the OpenAPI document.
+type object = map[string]interface{}
+
+// operation describes a route of NewHTTPHandler in the OpenAPI document.
+type operation struct {
+	Method, Path         string
+	Summary, Description string
+	Params               []parameter
+	// Body is a value of the JSON request body, nil if there is none.
+	Body interface{}
+	// Status is the status of a successful response, Data a value of the
+	// data of its DataRes envelope, nil if it has no content.
+	Status int
+	Data   interface{}
+	// Response replaces the DataRes envelope for the routes that do not
+	// answer with JSON.
+	Response object
+	// Errors are the statuses answered with an ErrorRes.
+	Errors []int
+	// Public is true for the routes served without a token.
+	Public bool
+}
+
+// parameter is a path, query or header parameter of an operation.
+type parameter struct {
+	Name, In, Description string
+	Schema                object
+}
+
+var (
+	idParam = parameter{"id", "path", "The id of the todo.", object{"type": "string"}}
+
+	fieldsParam = parameter{"fields", "query", "The comma separated todo fields to return, all of them if empty.", object{"type": "string"}}
+
+	accessTokenParam = parameter{"access_token", "query", "The bearer token, for the clients unable to set the Authorization header.", object{"type": "string"}}
+)
+
+// operations are the routes of NewHTTPHandler, TestOpenAPIRoutes fails if a
+// route is left out.
+var operations = []operation{
+	{
+		Method:      http.MethodPost,
+		Path:        "/items",
+		Summary:     "Add",
+		Description: "Creates a todo from its text, it is active unless completed is set.",
+		Body:        model.TodoReq{},
+		Status:      http.StatusCreated,
+		Data:        model.TodoRes{},
+		Errors:      []int{http.StatusBadRequest},
+	},
+	{
+		Method:      http.MethodGet,
+		Path:        "/items",
+		Summary:     "List",
+		Description: "Lists the todos, archived and snoozed todos are left out unless asked for.",
+		Params: []parameter{
+			fieldsParam,
+			{"sort", "query", "The field to sort by, descending when prefixed with -.", object{"type": "string", "enum": sortParams()}},
+			{"status", "query", "Restricts the todos to the active or the completed ones.", object{"type": "string", "enum": []string{service.ALL, service.ACTIVE, service.COMPLETE}}},
+			{"include", "query", "The comma separated archived and snoozed todos to list as well, the todos snoozed until a later time being snoozed.", object{"type": "string"}},
+			{"shared", "query", "Set to true to list the todos the others share with the caller instead of the todos of the caller.", object{"type": "boolean"}},
+			{"completedAfter", "query", "Restricts the todos to the ones completed after the time.", object{"type": "string", "format": "date-time"}},
+			{"completedBefore", "query", "Restricts the todos to the ones completed before the time.", object{"type": "string", "format": "date-time"}},
+			{"offset", "query", "The number of todos to skip.", object{"type": "integer", "minimum": 0}},
+			{"limit", "query", "The largest number of todos to return, all of them if 0.", object{"type": "integer", "minimum": 0}},
+		},
+		Status: http.StatusOK,
+		Data:   []*model.TodoRes{},
+		Errors: []int{http.StatusBadRequest},
+	},
+	{
+		Method:      http.MethodGet,
+		Path:        "/items/:id",
+		Summary:     "Get",
+		Description: "Returns a todo, archived todos included.",
+		Params:      []parameter{idParam, fieldsParam},
+		Status:      http.StatusOK,
+		Data:        model.TodoRes{},
+		Errors:      []int{http.StatusBadRequest, http.StatusNotFound},
+	},
+	{
+		Method:      http.MethodPatch,
+		Path:        "/items/:id",
+		Summary:     "Update",
+		Description: "Updates the text or the completion of a todo, the fields left out are kept.",
+		Params:      []parameter{idParam},
+		Body:        model.TodoReq{},
+		Status:      http.StatusOK,
+		Data:        model.TodoRes{},
+		Errors:      []int{http.StatusBadRequest, http.StatusNotFound, http.StatusConflict},
+	},
+	{
+		Method:      http.MethodDelete,
+		Path:        "/items/:id",
+		Summary:     "Delete",
+		Description: "Deletes a todo, offline clients learn about it from the tombstones of a sync.",
+		Params:      []parameter{idParam},
+		Status:      http.StatusNoContent,
+		Errors:      []int{http.StatusNotFound, http.StatusConflict},
+	},
+	{
+		Method:      http.MethodPost,
+		Path:        "/items/:id/unarchive",
+		Summary:     "Unarchive",
+		Description: "Brings an archived todo back to the listings.",
+		Params:      []parameter{idParam},
+		Status:      http.StatusOK,
+		Data:        model.TodoRes{},
+		Errors:      []int{http.StatusBadRequest, http.StatusNotFound},
+	},
+	{
+		Method:      http.MethodPost,
+		Path:        "/items/:id/snooze",
+		Summary:     "Snooze",
+		Description: "Hides a todo from the listings until the given time, or shows it again without one.",
+		Params:      []parameter{idParam},
+		Body: struct {
+			Until time.Time `json:"until"`
+		}{},
+		Status: http.StatusOK,
+		Data:   model.TodoRes{},
+		Errors: []int{http.StatusBadRequest, http.StatusNotFound},
+	},
+	{
+		Method:      http.MethodGet,
+		Path:        "/items/events",
+		Summary:     "Events",
+		Description: "Streams the changes of the todos of the caller as server-sent events, the id of each event resumes the stream after it. The todos shared with the caller are left out, GET /items?shared=true lists them.",
+		Params: []parameter{
+			{"types", "query", "The comma separated event types to stream, all of them if empty.", object{"type": "string"}},
+			{"lastEventId", "query", "The id of the last event received, for the clients unable to set Last-Event-ID.", object{"type": "integer", "minimum": 0}},
+			{"Last-Event-ID", "header", "The id of the last event received.", object{"type": "integer", "minimum": 0}},
+			accessTokenParam,
+		},
+		Response: object{
+			"description": "An event stream, the data of each event is a TodoEvent.",
+			"content": object{
+				"text/event-stream": object{"schema": object{"$ref": "#/components/schemas/TodoEvent"}},
+			},
+		},
+		Errors: []int{http.StatusBadRequest},
+	},
+	{
+		Method:      http.MethodGet,
+		Path:        "/ws",
+		Summary:     "WebSocket",
+		Description: "Upgrades to a WebSocket streaming the changes of the todos of the caller as TodoEvent messages, and running the add, update and delete commands sent by the client. The todos shared with the caller are left out.",
+		Params: []parameter{
+			{"types", "query", "The comma separated event types to stream, all of them if empty.", object{"type": "string"}},
+			{"lastEventId", "query", "The id of the last event received.", object{"type": "integer", "minimum": 0}},
+			accessTokenParam,
+		},
+		Response: object{"description": "Switching Protocols"},
+		Status:   http.StatusSwitchingProtocols,
+	},
+	{
+		Method:      http.MethodGet,
+		Path:        "/stats",
+		Summary:     "Stats",
+		Description: "Returns the number of todos by status and the completions of the last days.",
+		Params: []parameter{
+			{"days", "query", "The number of days of completions to return.", object{"type": "integer", "minimum": 0}},
+		},
+		Status: http.StatusOK,
+		Data:   model.TodoStats{},
+		Errors: []int{http.StatusBadRequest},
+	},
+	{
+		Method:      http.MethodGet,
+		Path:        "/sync",
+		Summary:     "Sync",
+		Description: "Returns the changes made to the todos of the caller since a sync token, and the token of the next sync. The todos shared with the caller are left out.",
+		Params: []parameter{
+			{"since", "query", "The opaque token of the previous sync, all the todos are returned if empty.", object{"type": "string"}},
+		},
+		Status: http.StatusOK,
+		Data:   model.Changes{},
+		Errors: []int{http.StatusBadRequest},
+	},
+	{
+		Method:      http.MethodPost,
+		Path:        "/sync",
+		Summary:     "Push",
+		Description: "Applies the mutations made by a client while offline, one by one and in order, and returns the result of each: applied, conflict or rejected. A server error fails the push while the mutations before it stay applied, they conflict with their own result when pushed again.",
+		Body:        endpoints.PushRequest{},
+		Status:      http.StatusOK,
+		Data:        []*model.MutationResult{},
+		Errors:      []int{http.StatusBadRequest},
+	},
+	{
+		Method:      http.MethodPost,
+		Path:        "/admin/api-keys",
+		Summary:     "CreateAPIKey",
+		Description: "Creates an API key authenticating a service-to-service caller as the subject, or the caller if left out, of the tenant of the caller, with the scopes as roles. The key is only returned once.",
+		Body:        model.APIKeyReq{},
+		Status:      http.StatusCreated,
+		Data:        model.CreatedAPIKey{},
+		Errors:      []int{http.StatusBadRequest},
+	},
+	{
+		Method:      http.MethodGet,
+		Path:        "/admin/api-keys",
+		Summary:     "ListAPIKeys",
+		Description: "Lists the API keys of the tenant of the caller, without the keys themselves.",
+		Status:      http.StatusOK,
+		Data:        []*model.APIKey{},
+	},
+	{
+		Method:      http.MethodGet,
+		Path:        "/usage",
+		Summary:     "Usage",
+		Description: "Returns the todos of the caller and of its tenant against their quotas, a quota of 0 being unlimited. Adding a todo past a quota is forbidden.",
+		Status:      http.StatusOK,
+		Data:        model.Usage{},
+	},
+	{
+		Method:      http.MethodDelete,
+		Path:        "/admin/api-keys/:id",
+		Summary:     "RevokeAPIKey",
+		Description: "Revokes an API key of the tenant of the caller.",
+		Params:      []parameter{{"id", "path", "The id of the API key.", object{"type": "string"}}},
+		Status:      http.StatusNoContent,
+		Errors:      []int{http.StatusNotFound},
+	},
+	{
+		Method:      http.MethodPost,
+		Path:        "/shares",
+		Summary:     "AddShare",
+		Description: "Shares a todo of the caller, or all of them when todoId is empty, with a user of its tenant as a viewer or an editor. Sharing it again changes the role.",
+		Body:        model.ShareReq{},
+		Status:      http.StatusCreated,
+		Data:        model.Share{},
+		Errors:      []int{http.StatusBadRequest, http.StatusNotFound},
+	},
+	{
+		Method:      http.MethodGet,
+		Path:        "/shares",
+		Summary:     "ListShares",
+		Description: "Lists the shares made by the caller and the ones made with the caller.",
+		Status:      http.StatusOK,
+		Data:        []*model.Share{},
+	},
+	{
+		Method:      http.MethodDelete,
+		Path:        "/shares/:id",
+		Summary:     "DeleteShare",
+		Description: "Revokes a share made by the caller.",
+		Params:      []parameter{{"id", "path", "The id of the share.", object{"type": "string"}}},
+		Status:      http.StatusNoContent,
+		Errors:      []int{http.StatusNotFound},
+	},
+	{
+		Method:      http.MethodGet,
+		Path:        "/graphql",
+		Summary:     "GraphQL",
+		Description: "Runs a GraphQL query or subscription, mutations are only run over POST.",
+		Params: []parameter{
+			{"query", "query", "The GraphQL document.", object{"type": "string"}},
+			{"operationName", "query", "The operation of the document to run.", object{"type": "string"}},
+			{"variables", "query", "The JSON-encoded variables of the operation.", object{"type": "string"}},
+			accessTokenParam,
+		},
+		Response: graphQLResponse,
+		Errors:   []int{http.StatusBadRequest},
+	},
+	{
+		Method:      http.MethodPost,
+		Path:        "/graphql",
+		Summary:     "GraphQL",
+		Description: "Runs a GraphQL query, mutation or subscription.",
+		Body:        graphQLRequest{},
+		Response:    graphQLResponse,
+		Errors:      []int{http.StatusBadRequest},
+	},
+	{
+		Method:      http.MethodGet,
+		Path:        "/openapi.json",
+		Summary:     "OpenAPI",
+		Description: "Returns this document.",
+		Response: object{
+			"description": "The OpenAPI document.",
+			"content":     object{"application/json": object{"schema": object{"type": "object"}}},
+		},
+		Public: true,
+	},
+	{
+		Method:      http.MethodGet,
+		Path:        "/docs",
+		Summary:     "Docs",
+		Description: "Serves a Swagger UI browsing this document.",
+		Response: object{
+			"description": "The Swagger UI.",
+			"content":     object{"text/html": object{"schema": object{"type": "string"}}},
+		},
+		Public: true,
+	},
+}
+
+var graphQLResponse = object{
+	"description": "The result of a query or a mutation, or the results of a subscription as next events.",
+	"content": object{
+		"application/json":  object{"schema": object{"type": "object"}},
+		"text/event-stream": object{"schema": object{"type": "object"}},
+	},
+}
+
+// sortParams returns the values of the sort parameter, in both orders.
+func sortParams() (sorts []string) {
+	for field := range model.TodoSortFields {
+		sorts = append(sorts, field, "-"+field)
+	}
+	sort.Strings(sorts)
+	return
+}
+
+// NewOpenAPI returns the OpenAPI 3 document of the routes of NewHTTPHandler.
+func NewOpenAPI() map[string]interface{} {
+	s := schemas{}
+	errorRes := object{"$ref": "#/components/responses/Error"}
+	limitedRes := object{"$ref": "#/components/responses/RateLimited"}
+
+	paths := object{}
+	for _, op := range operations {
+		path := openAPIPath(op.Path)
+		item, ok := paths[path].(object)
+		if !ok {
+			item = object{}
+			paths[path] = item
+		}
+
+		o := object{
+			"summary":     op.Summary,
+			"description": op.Description,
+			"operationId": strings.ToLower(op.Method) + strings.Replace(strings.Title(strings.NewReplacer("/", " ", ":", "", ".", " ").Replace(op.Path)), " ", "", -1),
+			"tags":        []string{"TODO"},
+		}
+		var params []object
+		for _, p := range op.Params {
+			params = append(params, object{
+				"name":        p.Name,
+				"in":          p.In,
+				"description": p.Description,
+				"required":    p.In == "path",
+				"schema":      p.Schema,
+			})
+		}
+		if len(params) > 0 {
+			o["parameters"] = params
+		}
+		if op.Body != nil {
+			o["requestBody"] = object{
+				"required": true,
+				"content":  object{"application/json": object{"schema": s.of(reflect.TypeOf(op.Body))}},
+			}
+		}
+
+		res := object{}
+		switch {
+		case op.Response != nil:
+			status := op.Status
+			if status == 0 {
+				status = http.StatusOK
+			}
+			res[strconv.Itoa(status)] = op.Response
+		case op.Data == nil:
+			res[strconv.Itoa(op.Status)] = object{"description": http.StatusText(op.Status)}
+		default:
+			res[strconv.Itoa(op.Status)] = object{
+				"description": http.StatusText(op.Status),
+				"content":     object{"application/json": object{"schema": s.data(reflect.TypeOf(op.Data))}},
+			}
+		}
+		for _, code := range op.Errors {
+			res[strconv.Itoa(code)] = errorRes
+		}
+		if !op.Public {
+			res[strconv.Itoa(http.StatusUnauthorized)] = errorRes
+			res[strconv.Itoa(http.StatusForbidden)] = errorRes
+			res[strconv.Itoa(http.StatusTooManyRequests)] = limitedRes
+			o["security"] = []object{{"bearer": []string{}}, {"apiKey": []string{}}}
+		}
+		if len(op.Errors) > 0 {
+			res["default"] = errorRes
+		}
+		o["responses"] = res
+		item[strings.ToLower(op.Method)] = o
+	}
+
+	version := service.Version
+	if version == "" {
+		version = "dev"
+	}
+	return object{
+		"openapi": "3.0.3",
+		"info": object{
+			"title":   "gokit-todo",
+			"version": version,
+		},
+		"paths": paths,
+		"components": object{
+			// TodoEvent is the data of the events of /items/events
+			"schemas": s.with(reflect.TypeOf(responses.ErrorRes{})).with(reflect.TypeOf(model.TodoEvent{})),
+			"securitySchemes": object{
+				"bearer": object{
+					"type":         "http",
+					"scheme":       "bearer",
+					"bearerFormat": "JWT",
+					"description":  "An HS256 or RS256 JWT, or an API key, required when the server is configured with keys. Its roles claim, or the scopes of the API key, grants permissions when the server is configured with a policy.",
+				},
+				"apiKey": object{
+					"type":        "apiKey",
+					"in":          "header",
+					"name":        authn.APIKeyHeader,
+					"description": "An API key created by an administrator, it may be sent as the bearer token instead.",
+				},
+			},
+			"responses": object{
+				"Error": object{
+					"description": "An error, its code is the HTTP status.",
+					"content":     object{"application/json": object{"schema": object{"$ref": "#/components/schemas/ErrorRes"}}},
+				},
+				"RateLimited": object{
+					"description": "The caller made more requests than the rate limit of the operation.",
+					"headers": object{
+						"Retry-After":         object{"description": "Seconds until the next request is accepted.", "schema": object{"type": "integer"}},
+						"RateLimit-Limit":     object{"description": "Requests a caller can make at once.", "schema": object{"type": "integer"}},
+						"RateLimit-Remaining": object{"description": "Requests the caller can make now.", "schema": object{"type": "integer"}},
+						"RateLimit-Reset":     object{"description": "Seconds until the limit is fully available again.", "schema": object{"type": "integer"}},
+					},
+					"content": object{"application/json": object{"schema": object{"$ref": "#/components/schemas/ErrorRes"}}},
+				},
+			},
+		},
+	}
+}
+
+// openAPIPath converts the parameters of a bone path to OpenAPI templates.
+func openAPIPath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") {
+			segments[i] = "{" + segment[1:] + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// schemas collects the component schemas of the named types, reflected from
+// their JSON encoding.
+type schemas map[string]interface{}
+
+// with adds the schema of t and returns s.
+func (s schemas) with(t reflect.Type) schemas {
+	s.of(t)
+	return s
+}
+
+// data returns the schema of a DataRes envelope whose data is of type t.
+func (s schemas) data(t reflect.Type) object {
+	return object{
+		"allOf": []interface{}{
+			s.of(reflect.TypeOf(responses.DataRes{})),
+			object{"type": "object", "properties": object{"data": s.of(t)}},
+		},
+	}
+}
+
+// of returns the schema of t, a reference for the named structs.
+func (s schemas) of(t reflect.Type) object {
+	if t == reflect.TypeOf(time.Time{}) {
+		return object{"type": "string", "format": "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		schema := s.of(t.Elem())
+		if _, ok := schema["$ref"]; !ok {
+			schema["nullable"] = true
+		}
+		return schema
+	case reflect.Bool:
+		return object{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32:
+		return object{"type": "integer", "format": "int32"}
+	case reflect.Int64:
+		return object{"type": "integer", "format": "int64"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return object{"type": "integer", "format": "int64", "minimum": 0}
+	case reflect.Float32, reflect.Float64:
+		return object{"type": "number", "format": "double"}
+	case reflect.String:
+		return object{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		return object{"type": "array", "items": s.of(t.Elem())}
+	case reflect.Map:
+		return object{"type": "object", "additionalProperties": s.of(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return s.object(t)
+		}
+		if _, ok := s[t.Name()]; !ok {
+			s[t.Name()] = object{} // recursive types refer to themselves
+			s[t.Name()] = s.object(t)
+		}
+		return object{"$ref": "#/components/schemas/" + t.Name()}
+	}
+	return object{} // interface{} holds any value
+}
+
+// object returns the schema of the struct t, the fields without omitempty
+// nor a pointer type are always present. The fields of the embedded structs
+// are inlined, as encoding/json does.
+func (s schemas) object(t reflect.Type) object {
+	properties, required := object{}, []string{}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+		tag := strings.Split(f.Tag.Get("json"), ",")
+		name := tag[0]
+		if name == "-" {
+			continue
+		}
+		if name == "" && f.Anonymous && f.Type.Kind() == reflect.Struct {
+			embedded := s.object(f.Type)
+			for k, v := range embedded["properties"].(object) {
+				properties[k] = v
+			}
+			if r, ok := embedded["required"].([]string); ok {
+				required = append(required, r...)
+			}
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		properties[name] = s.of(f.Type)
+
+		omitempty := false
+		for _, opt := range tag[1:] {
+			omitempty = omitempty || opt == "omitempty"
+		}
+		if !omitempty && f.Type.Kind() != reflect.Ptr {
+			required = append(required, name)
+		}
+	}
+
+	schema := object{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+// OpenAPIHandler serves the OpenAPI document at /openapi.json, and a Swagger
+// UI browsing it at /docs.
+func OpenAPIHandler(m *bone.Mux) {
+	doc, err := json.Marshal(NewOpenAPI())
+	if err != nil {
+		panic(err)
+	}
+	m.Get("/openapi.json", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", contentType)
+		_, _ = w.Write(doc)
+	}))
+
+	files := http.StripPrefix("/docs", http.FileServer(swaggerFiles.HTTP))
+	m.Get("/docs", http.RedirectHandler("/docs/", http.StatusMovedPermanently))
+	m.Get("/docs/*", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// the initializer of the bundle loads the Petstore example
+		if r.URL.Path == "/docs/swagger-initializer.js" {
+			w.Header().Set("Content-Type", "application/javascript")
+			_, _ = w.Write([]byte(swaggerInitializer))
+			return
+		}
+		files.ServeHTTP(w, r)
+	}))
+}
+
+const swaggerInitializer = `window.onload = function() {
+  window.ui = SwaggerUIBundle({
+    url: "/openapi.json",
+    dom_id: "#swagger-ui",
+    deepLinking: true,
+    presets: [
+      SwaggerUIBundle.presets.apis,
+      SwaggerUIStandalonePreset
+    ],
+    plugins: [
+      SwaggerUIBundle.plugins.DownloadUrl
+    ],
+    layout: "StandaloneLayout"
+  });
+};
+`
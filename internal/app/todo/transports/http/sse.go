@@ -0,0 +1,271 @@
+package transports
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-kit/kit/endpoint"
+
+	"github.com/cage1016/gokit-todo/internal/app/todo/endpoints"
+	"github.com/cage1016/gokit-todo/internal/app/todo/model"
+	"github.com/cage1016/gokit-todo/internal/app/todo/service"
+	"github.com/cage1016/gokit-todo/internal/pkg/errors"
+	"github.com/cage1016/gokit-todo/internal/pkg/responses"
+)
+
+// HeartbeatInterval is how often an idle event stream sends a comment, which
+// keeps proxies from closing the connection.
+var HeartbeatInterval = 15 * time.Second
+
+type flusherKey struct{}
+
+type streamsKey struct{}
+
+// eventStream is the response of the events endpoint, reset tells the client
+// the events it missed are no longer available and it has to reload.
+type eventStream struct {
+	events <-chan *model.TodoEvent
+	reset  bool
+}
+
+// withFlusher keeps the http.Flusher of w in the request context, go-kit
+// hides it behind its own writer when the server has finalizers.
+func withFlusher(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if f, ok := w.(http.Flusher); ok {
+			r = r.WithContext(context.WithValue(r.Context(), flusherKey{}, f))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// withStreams keeps ctx in the request context, the event streams of the
+// request end once it is done.
+func withStreams(ctx context.Context, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), streamsKey{}, ctx)))
+	})
+}
+
+// streamsDone returns the channel closed once the event streams of the
+// request have to end, nil if they only end with the request.
+func streamsDone(ctx context.Context) <-chan struct{} {
+	if streams, ok := ctx.Value(streamsKey{}).(context.Context); ok {
+		return streams.Done()
+	}
+	return nil
+}
+
+// resumeOrReset wraps the Watch endpoint so that a client resuming from an
+// event no longer available watches the new events and is told to reload.
+func resumeOrReset(next endpoint.Endpoint) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		resp, err := next(ctx, request)
+		if err == nil {
+			return eventStream{events: resp.(endpoints.WatchResponse).Events}, nil
+		}
+		req := request.(endpoints.WatchRequest)
+		if !errors.Contains(errors.Cast(err), service.ErrSequenceExpired) || req.Query == nil {
+			return nil, err
+		}
+		resp, err = next(ctx, endpoints.WatchRequest{Query: &model.EventQuery{Types: req.Query.Types}})
+		if err != nil {
+			return nil, err
+		}
+		return eventStream{events: resp.(endpoints.WatchResponse).Events, reset: true}, nil
+	}
+}
+
+// decodeHTTPWatchRequest is a transport/http.DecodeRequestFunc that decodes the
+// event types and the Last-Event-ID of an event stream request. Primarily
+// useful in a server.
+func decodeHTTPWatchRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	req := endpoints.WatchRequest{Query: &model.EventQuery{}}
+	for _, t := range strings.Split(r.URL.Query().Get("types"), ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			req.Query.Types = append(req.Query.Types, t)
+		}
+	}
+
+	// EventSource polyfills unable to set headers pass it as a parameter
+	id := r.Header.Get("Last-Event-ID")
+	if id == "" {
+		id = r.URL.Query().Get("lastEventId")
+	}
+	if id != "" {
+		since, err := strconv.ParseUint(id, 10, 64)
+		if err != nil {
+			return nil, errors.Wrap(service.ErrInvalidQueryParams, err)
+		}
+		req.Query.Since = since
+	}
+	return req, nil
+}
+
+// encodeHTTPEventStream is a transport/http.EncodeResponseFunc that writes the
+// events of an eventStream as text/event-stream until the events end, the
+// request is done or the streams end, see WithStreamContext.
+func encodeHTTPEventStream(ctx context.Context, w http.ResponseWriter, response interface{}) error {
+	stream := response.(eventStream)
+	flusher, ok := ctx.Value(flusherKey{}).(http.Flusher)
+	if !ok {
+		return errors.New("streaming unsupported")
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no") // disable the nginx ingress buffering
+	w.WriteHeader(http.StatusOK)
+
+	if stream.reset {
+		if _, err := fmt.Fprint(w, "id\nevent: reset\ndata: {}\n\n"); err != nil {
+			return err
+		}
+	} else if _, err := fmt.Fprint(w, ": watching\n\n"); err != nil {
+		// tells the clients the watch has started, as the gRPC header does
+		return err
+	}
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(HeartbeatInterval)
+	defer heartbeat.Stop()
+
+	streams := streamsDone(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-streams:
+			// the client reconnects to another server and resumes from its
+			// last event
+			return nil
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": heartbeat\n\n"); err != nil {
+				return err
+			}
+		case ev, ok := <-stream.events:
+			if !ok {
+				// the client reconnects and resumes from its last event
+				return nil
+			}
+			data, err := json.Marshal(ev)
+			if err != nil {
+				return err
+			}
+			if _, err := fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", ev.Seq, ev.Type, data); err != nil {
+				return err
+			}
+		}
+		flusher.Flush()
+	}
+}
+
+// encodeHTTPWatchRequest is a transport/http.EncodeRequestFunc that encodes
+// the query of a user-domain Watch request as the event types and the
+// Last-Event-ID of an event stream request. Primarily useful in a client.
+func encodeHTTPWatchRequest(_ context.Context, r *http.Request, request interface{}) error {
+	req := request.(endpoints.WatchRequest)
+	r.Header.Set("Accept", "text/event-stream")
+	if req.Query == nil {
+		return nil
+	}
+	if len(req.Query.Types) > 0 {
+		r.URL.RawQuery = url.Values{"types": {strings.Join(req.Query.Types, ",")}}.Encode()
+	}
+	if req.Query.Since != 0 {
+		r.Header.Set("Last-Event-ID", strconv.FormatUint(req.Query.Since, 10))
+	}
+	return nil
+}
+
+// decodeHTTPWatchResponse is a transport/http.DecodeResponseFunc that relays
+// the events of an event stream until the stream or the request ends. A
+// stream starting with a reset, the events after Last-Event-ID being no
+// longer available, is service.ErrSequenceExpired as it is for the service.
+// Primarily useful in a client.
+func decodeHTTPWatchResponse(ctx context.Context, r *http.Response) (interface{}, error) {
+	if r.StatusCode != http.StatusOK {
+		defer r.Body.Close()
+		return nil, responses.JSONErrorDecoder(r)
+	}
+
+	stream := bufio.NewReader(r.Body)
+	msg, err := readEvent(stream)
+	if err != nil {
+		r.Body.Close()
+		return nil, err
+	}
+	if msg.event == "reset" {
+		r.Body.Close()
+		return nil, service.ErrSequenceExpired
+	}
+
+	events := make(chan *model.TodoEvent)
+	go func() {
+		defer close(events)
+		defer r.Body.Close()
+		for {
+			// the comments, heartbeats included, have no data
+			if msg.data != "" {
+				var ev model.TodoEvent
+				if err := json.Unmarshal([]byte(msg.data), &ev); err != nil {
+					return
+				}
+				select {
+				case events <- &ev:
+				case <-ctx.Done():
+					return
+				}
+			}
+			if msg, err = readEvent(stream); err != nil {
+				return
+			}
+		}
+	}()
+	return endpoints.WatchResponse{Events: events}, nil
+}
+
+// sseEvent is an event of an event stream.
+type sseEvent struct {
+	event, data string
+}
+
+// readEvent reads the next event of an event stream, up to its blank line.
+func readEvent(r *bufio.Reader) (ev sseEvent, err error) {
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			if err == io.EOF && line != "" {
+				err = io.ErrUnexpectedEOF
+			}
+			return ev, err
+		}
+		line = strings.TrimRight(line, "\r\n")
+		if line == "" {
+			return ev, nil
+		}
+
+		field, value := line, ""
+		if i := strings.IndexByte(line, ':'); i >= 0 {
+			field, value = line[:i], strings.TrimPrefix(line[i+1:], " ")
+		}
+		switch field {
+		case "event":
+			ev.event = value
+		case "data":
+			if ev.data != "" {
+				ev.data += "\n"
+			}
+			ev.data += value
+		}
+	}
+}
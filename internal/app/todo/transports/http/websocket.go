@@ -0,0 +1,321 @@
+package transports
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	kitjwt "github.com/go-kit/kit/auth/jwt"
+	"github.com/go-kit/kit/endpoint"
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/go-kit/kit/tracing/opentracing"
+	"github.com/go-zoo/bone"
+	"github.com/gorilla/websocket"
+	stdopentracing "github.com/opentracing/opentracing-go"
+
+	"github.com/cage1016/gokit-todo/internal/app/todo/endpoints"
+	"github.com/cage1016/gokit-todo/internal/app/todo/service"
+	"github.com/cage1016/gokit-todo/internal/pkg/authn"
+	"github.com/cage1016/gokit-todo/internal/pkg/authz"
+	"github.com/cage1016/gokit-todo/internal/pkg/errors"
+	"github.com/cage1016/gokit-todo/internal/pkg/instrumenting"
+	"github.com/cage1016/gokit-todo/internal/pkg/ratelimit"
+	"github.com/cage1016/gokit-todo/internal/pkg/responses"
+)
+
+const (
+	// wsWriteWait is the time allowed to write a message to the client.
+	wsWriteWait = 10 * time.Second
+	// wsPongWait is the time allowed to read the next pong from the client.
+	wsPongWait = 60 * time.Second
+	// wsPingInterval is how often the client is pinged, it has to be lower
+	// than wsPongWait.
+	wsPingInterval = wsPongWait * 9 / 10
+	// wsMaxMessageSize is the largest command accepted from the client.
+	wsMaxMessageSize = 64 << 10
+
+	wsEventType = "event"
+	wsResetType = "reset"
+)
+
+// checkOrigin returns whether the upgrade requested by r comes from a page
+// allowed to use the WebSocket: the requests without an Origin, such as
+// the ones of the non-browser clients, the same-origin ones and the ones from
+// origins are allowed, "*" allowing every origin. Browsers do not restrict the
+// WebSocket origins, unlike CORS, and send the cookies of the server along.
+func checkOrigin(origins []string) func(*http.Request) bool {
+	allowed := make(map[string]bool, len(origins))
+	for _, o := range origins {
+		allowed[strings.ToLower(strings.TrimSuffix(o, "/"))] = true
+	}
+	return func(r *http.Request) bool {
+		origin := r.Header.Get("Origin")
+		if origin == "" || allowed["*"] || allowed[strings.ToLower(origin)] {
+			return true
+		}
+		u, err := url.Parse(origin)
+		return err == nil && strings.EqualFold(u.Host, r.Host)
+	}
+}
+
+// wsMessage is a command sent by the client, Data holds the JSON-encoded
+// request of the endpoint named by Type.
+type wsMessage struct {
+	ID   string          `json:"id"`
+	Type string          `json:"type"`
+	Data json.RawMessage `json:"data"`
+}
+
+// wsReply is either the reply to the command with the same ID, or a todo
+// event pushed to the client.
+type wsReply struct {
+	ID    string      `json:"id,omitempty"`
+	Type  string      `json:"type"`
+	Data  interface{} `json:"data,omitempty"`
+	Error *wsError    `json:"error,omitempty"`
+}
+
+type wsError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// wsCommand decodes the request of a command, invokes its endpoint and
+// returns the data of its reply.
+type wsCommand struct {
+	endpoint endpoint.Endpoint
+	decode   func(data json.RawMessage) (interface{}, error)
+	encode   func(response interface{}) interface{}
+}
+
+func wsCommands(eps endpoints.Endpoints) map[string]wsCommand {
+	return map[string]wsCommand{
+		"add": {
+			endpoint: eps.AddEndpoint,
+			decode:   decodeWSAddRequest,
+			encode:   func(response interface{}) interface{} { return response.(endpoints.AddResponse).Res },
+		},
+		"update": {
+			endpoint: eps.UpdateEndpoint,
+			decode:   decodeWSUpdateRequest,
+			encode:   func(response interface{}) interface{} { return response.(endpoints.UpdateResponse).Res },
+		},
+		"delete": {
+			endpoint: eps.DeleteEndpoint,
+			decode:   decodeWSDeleteRequest,
+			encode:   func(response interface{}) interface{} { return nil },
+		},
+	}
+}
+
+// ShowTodo godoc
+// @Summary WebSocket
+// @Description Upgrades to a WebSocket streaming the changes of the todos of the caller as TodoEvent messages, and running the add, update and delete commands sent by the client. The todos shared with the caller are left out.
+// @Tags TODO
+// @Router /ws [get]
+func WebSocketHandler(m *bone.Mux, endpoints endpoints.Endpoints, origins []string, otTracer stdopentracing.Tracer, logger log.Logger) {
+	upgrader := websocket.Upgrader{CheckOrigin: checkOrigin(origins)}
+	commands := wsCommands(endpoints)
+	events := resumeOrReset(endpoints.WatchEndpoint)
+	before := []func(context.Context, *http.Request) context.Context{
+		opentracing.HTTPToContext(otTracer, "WebSocket", logger),
+		kitjwt.HTTPToContext(),
+		queryTokenToContext(),
+		authn.HTTPAPIKeyToContext(),
+		ratelimit.HTTPClientToContext(),
+		instrumenting.HTTPToContext(instrumenting.WebSocket),
+	}
+
+	m.Get("/ws", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		for _, f := range before {
+			ctx = f(ctx, r)
+		}
+
+		if !upgrader.CheckOrigin(r) {
+			// checked before watching, which the upgrader does last
+			responses.ErrorEncodeJSONResponse(CustomErrorEncoder)(ctx, authz.ErrForbidden, w)
+			return
+		}
+		req, err := decodeHTTPWatchRequest(ctx, r)
+		if err != nil {
+			responses.ErrorEncodeJSONResponse(CustomErrorEncoder)(ctx, err, w)
+			return
+		}
+		resp, err := events(ctx, req)
+		if err != nil {
+			responses.ErrorEncodeJSONResponse(CustomErrorEncoder)(ctx, err, w)
+			return
+		}
+
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			// the upgrader already replied with an HTTP error
+			level.Info(logger).Log("transport", "WebSocket", "err", err)
+			return
+		}
+		serveWS(ctx, conn, commands, resp.(eventStream), logger)
+	}))
+}
+
+// serveWS runs the commands read from conn and writes their replies, along
+// with the todo events, until either side closes the connection or ctx is
+// done.
+func serveWS(ctx context.Context, conn *websocket.Conn, commands map[string]wsCommand, stream eventStream, logger log.Logger) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	out := make(chan *wsReply)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		defer conn.Close()
+		defer cancel()
+		if err := writeWS(ctx, conn, out, stream); err != nil {
+			level.Info(logger).Log("transport", "WebSocket", "err", err)
+		}
+	}()
+
+	conn.SetReadLimit(wsMaxMessageSize)
+	_ = conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	})
+
+	for {
+		var msg wsMessage
+		if err := conn.ReadJSON(&msg); err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+				level.Info(logger).Log("transport", "WebSocket", "err", err)
+			}
+			break
+		}
+
+		reply := runWSCommand(ctx, commands, &msg)
+		select {
+		case out <- reply:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	cancel()
+	<-done
+}
+
+// writeWS is the only writer of conn, it writes the replies, the events and
+// the pings until ctx is done, the events end or the streams end, see
+// WithStreamContext.
+func writeWS(ctx context.Context, conn *websocket.Conn, out <-chan *wsReply, stream eventStream) error {
+	ping := time.NewTicker(wsPingInterval)
+	defer ping.Stop()
+
+	write := func(reply *wsReply) error {
+		_ = conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+		return conn.WriteJSON(reply)
+	}
+	closeWith := func(code int, text string) error {
+		msg := websocket.FormatCloseMessage(code, text)
+		return conn.WriteControl(websocket.CloseMessage, msg, time.Now().Add(wsWriteWait))
+	}
+
+	if stream.reset {
+		if err := write(&wsReply{Type: wsResetType}); err != nil {
+			return err
+		}
+	}
+
+	streams := streamsDone(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return closeWith(websocket.CloseGoingAway, "")
+		case <-streams:
+			return closeWith(websocket.CloseGoingAway, "")
+		case reply := <-out:
+			if err := write(reply); err != nil {
+				return err
+			}
+		case ev, ok := <-stream.events:
+			if !ok {
+				// the client reconnects and resumes from its last event
+				return closeWith(websocket.CloseTryAgainLater, "resume from the last event")
+			}
+			if err := write(&wsReply{Type: wsEventType, Data: ev}); err != nil {
+				return err
+			}
+		case <-ping.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(wsWriteWait)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// runWSCommand invokes the endpoint of msg and returns its reply, correlated
+// by the ID of msg.
+func runWSCommand(ctx context.Context, commands map[string]wsCommand, msg *wsMessage) *wsReply {
+	reply := &wsReply{ID: msg.ID, Type: msg.Type}
+
+	cmd, ok := commands[msg.Type]
+	if !ok {
+		reply.Error = encodeWSError(service.ErrMalformedEntity)
+		return reply
+	}
+	req, err := cmd.decode(msg.Data)
+	if err != nil {
+		reply.Error = encodeWSError(err)
+		return reply
+	}
+	resp, err := cmd.endpoint(ctx, req)
+	if err != nil {
+		reply.Error = encodeWSError(err)
+		return reply
+	}
+	reply.Data = cmd.encode(resp)
+	return reply
+}
+
+// encodeWSError maps err to its HTTP status, as CustomErrorEncoder does.
+func encodeWSError(err error) *wsError {
+	code := CustomErrorEncoder(errors.Cast(err))
+	if code == 0 {
+		return &wsError{Code: http.StatusInternalServerError, Message: http.StatusText(http.StatusInternalServerError)}
+	}
+	return &wsError{Code: code, Message: err.Error()}
+}
+
+func decodeWSAddRequest(data json.RawMessage) (interface{}, error) {
+	var req endpoints.AddRequest
+	if err := json.Unmarshal(data, &req); err != nil {
+		return nil, errors.Wrap(service.ErrMalformedEntity, err)
+	}
+	if req.Todo == nil {
+		return nil, service.ErrMalformedEntity
+	}
+	return req, nil
+}
+
+func decodeWSUpdateRequest(data json.RawMessage) (interface{}, error) {
+	var req endpoints.UpdateRequest
+	if err := json.Unmarshal(data, &req); err != nil {
+		return nil, errors.Wrap(service.ErrMalformedEntity, err)
+	}
+	if req.Id == "" || req.Todo == nil {
+		return nil, service.ErrMalformedEntity
+	}
+	return req, nil
+}
+
+func decodeWSDeleteRequest(data json.RawMessage) (interface{}, error) {
+	var req endpoints.DeleteRequest
+	if err := json.Unmarshal(data, &req); err != nil {
+		return nil, errors.Wrap(service.ErrMalformedEntity, err)
+	}
+	return req, nil
+}
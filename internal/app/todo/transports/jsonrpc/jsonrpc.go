@@ -0,0 +1,329 @@
+package transports
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+
+	kitjwt "github.com/go-kit/kit/auth/jwt"
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/tracing/opentracing"
+	"github.com/go-kit/kit/transport/http/jsonrpc"
+	stdopentracing "github.com/opentracing/opentracing-go"
+	stdzipkin "github.com/openzipkin/zipkin-go"
+	zipkinhttp "github.com/openzipkin/zipkin-go/middleware/http"
+
+	"github.com/cage1016/gokit-todo/internal/app/todo/endpoints"
+	"github.com/cage1016/gokit-todo/internal/app/todo/service"
+	"github.com/cage1016/gokit-todo/internal/pkg/authn"
+	"github.com/cage1016/gokit-todo/internal/pkg/authz"
+	"github.com/cage1016/gokit-todo/internal/pkg/errors"
+	"github.com/cage1016/gokit-todo/internal/pkg/instrumenting"
+	"github.com/cage1016/gokit-todo/internal/pkg/ratelimit"
+	"github.com/cage1016/gokit-todo/internal/pkg/responses"
+)
+
+// Error codes of the service errors, within the range JSON-RPC reserves for
+// implementation-defined server errors.
+const (
+	NotFoundError      = -32001
+	ConflictError      = -32002
+	UnauthorizedError  = -32003
+	ForbiddenError     = -32004
+	RateLimitedError   = -32005
+	QuotaExceededError = -32006
+)
+
+// MaxBatchSize is the largest number of requests accepted in a batch.
+const MaxBatchSize = 100
+
+// NewJSONRPCHandler returns a handler that makes a set of endpoints available
+// as JSON-RPC 2.0 methods, single requests as well as batches.
+func NewJSONRPCHandler(endpoints endpoints.Endpoints, otTracer stdopentracing.Tracer, zipkinTracer *stdzipkin.Tracer, logger log.Logger) http.Handler {
+	ecm := jsonrpc.EndpointCodecMap{
+		"todo.add": jsonrpc.EndpointCodec{
+			Endpoint: endpoints.AddEndpoint,
+			Decode:   decodeJSONRPCAddRequest,
+			Encode:   encodeJSONRPCAddResponse,
+		},
+		"todo.delete": jsonrpc.EndpointCodec{
+			Endpoint: endpoints.DeleteEndpoint,
+			Decode:   decodeJSONRPCDeleteRequest,
+			Encode:   encodeJSONRPCDeleteResponse,
+		},
+		"todo.update": jsonrpc.EndpointCodec{
+			Endpoint: endpoints.UpdateEndpoint,
+			Decode:   decodeJSONRPCUpdateRequest,
+			Encode:   encodeJSONRPCUpdateResponse,
+		},
+		"todo.list": jsonrpc.EndpointCodec{
+			Endpoint: endpoints.ListEndpoint,
+			Decode:   decodeJSONRPCListRequest,
+			Encode:   encodeJSONRPCListResponse,
+		},
+	}
+
+	server := jsonrpc.NewServer(
+		ecm,
+		jsonrpc.ServerErrorEncoder(errorEncoder),
+		jsonrpc.ServerErrorLogger(logger),
+		jsonrpc.ServerBefore(opentracing.HTTPToContext(otTracer, "JSON-RPC", logger), kitjwt.HTTPToContext(), authn.HTTPAPIKeyToContext(), ratelimit.HTTPClientToContext(), instrumenting.HTTPToContext(instrumenting.JSONRPC)),
+	)
+	return zipkinhttp.NewServerMiddleware(zipkinTracer, zipkinhttp.SpanName("JSON-RPC"))(batch(server))
+}
+
+// rpcResponse is a jsonrpc.Response keeping the request ID as it was sent.
+type rpcResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *jsonrpc.Error  `json:"error,omitempty"`
+	ID      json.RawMessage `json:"id"`
+}
+
+// rpcRequest holds the members telling the requests from the notifications,
+// which have no ID, and from the invalid requests, which have no method.
+type rpcRequest struct {
+	Method *string         `json:"method"`
+	ID     json.RawMessage `json:"id"`
+}
+
+// notification reports whether req is run without being replied to.
+func (req rpcRequest) notification() bool {
+	return req.Method != nil && req.ID == nil
+}
+
+// batch serves the batch requests by passing each of their requests to next,
+// and fills the ID of the error responses, which next leaves out. The
+// notifications, on their own or in a batch, are not replied to.
+func batch(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			writeJSONRPC(w, errorResponse(jsonrpc.ParseError, err.Error()))
+			return
+		}
+		body = bytes.TrimSpace(body)
+		if len(body) == 0 || body[0] != '[' {
+			// next replies to the malformed requests
+			var req rpcRequest
+			_ = json.Unmarshal(body, &req)
+			resp := serveJSONRPC(next, r, body, req.ID)
+			if req.notification() {
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+			writeJSONRPC(w, resp)
+			return
+		}
+
+		var reqs []json.RawMessage
+		if err := json.Unmarshal(body, &reqs); err != nil {
+			writeJSONRPC(w, errorResponse(jsonrpc.ParseError, err.Error()))
+			return
+		}
+		if len(reqs) == 0 || len(reqs) > MaxBatchSize {
+			writeJSONRPC(w, errorResponse(jsonrpc.InvalidRequestError, jsonrpc.ErrorMessage(jsonrpc.InvalidRequestError)))
+			return
+		}
+
+		res := make([]*rpcResponse, 0, len(reqs))
+		for _, body := range reqs {
+			var req rpcRequest
+			if err := json.Unmarshal(body, &req); err != nil || req.Method == nil {
+				// not a request, such as a number, replied to with the ID
+				// it may have
+				resp := errorResponse(jsonrpc.InvalidRequestError, jsonrpc.ErrorMessage(jsonrpc.InvalidRequestError))
+				if err == nil && req.ID != nil {
+					resp.ID = req.ID
+				}
+				res = append(res, resp)
+				continue
+			}
+			resp := serveJSONRPC(next, r, body, req.ID)
+			if !req.notification() {
+				res = append(res, resp)
+			}
+		}
+		if len(res) == 0 {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		writeJSONRPC(w, res)
+	})
+}
+
+// serveJSONRPC runs the single request in body and returns its response with
+// the given ID, null if nil.
+func serveJSONRPC(next http.Handler, r *http.Request, body []byte, id json.RawMessage) *rpcResponse {
+	rr := r.Clone(r.Context())
+	rr.Body = ioutil.NopCloser(bytes.NewReader(body))
+	rr.ContentLength = int64(len(body))
+	rec := &recorder{header: http.Header{}}
+	next.ServeHTTP(rec, rr)
+
+	var resp rpcResponse
+	if err := json.Unmarshal(rec.body.Bytes(), &resp); err != nil {
+		resp = *errorResponse(jsonrpc.InternalError, err.Error())
+	}
+	resp.ID = id
+	if resp.ID == nil {
+		resp.ID = json.RawMessage("null")
+	}
+	return &resp
+}
+
+func errorResponse(code int, msg string) *rpcResponse {
+	return &rpcResponse{
+		JSONRPC: jsonrpc.Version,
+		Error:   &jsonrpc.Error{Code: code, Message: msg},
+		ID:      json.RawMessage("null"),
+	}
+}
+
+func writeJSONRPC(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", jsonrpc.ContentType)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// recorder is an http.ResponseWriter keeping the response of a request of a
+// batch.
+type recorder struct {
+	header http.Header
+	body   bytes.Buffer
+}
+
+func (r *recorder) Header() http.Header         { return r.header }
+func (r *recorder) Write(b []byte) (int, error) { return r.body.Write(b) }
+func (r *recorder) WriteHeader(int)             {}
+
+// errorEncoder writes err as a JSON-RPC error, the code of the service errors
+// is derived as CustomErrorEncoder derives their HTTP status.
+func errorEncoder(_ context.Context, err error, w http.ResponseWriter) {
+	e := &jsonrpc.Error{Code: jsonrpc.InternalError, Message: err.Error()}
+	switch errorVal := err.(type) {
+	case jsonrpc.ErrorCoder:
+		e.Code = errorVal.ErrorCode()
+	case errors.Error:
+		e.Code = ErrorCode(errorVal)
+		if errorVal.Msg() != "" {
+			e.Message, e.Data = errorVal.Msg(), errorVal.Errors()
+		}
+	}
+	writeJSONRPC(w, rpcResponse{JSONRPC: jsonrpc.Version, Error: e, ID: json.RawMessage("null")})
+}
+
+// ErrorCode returns the JSON-RPC error code of errorVal.
+func ErrorCode(errorVal errors.Error) (code int) {
+	switch {
+	case errors.Contains(errorVal, authn.ErrUnauthorized):
+		code = UnauthorizedError
+	case errors.Contains(errorVal, authz.ErrForbidden):
+		code = ForbiddenError
+	case errors.Contains(errorVal, ratelimit.ErrLimited):
+		code = RateLimitedError
+	case errors.Contains(errorVal, service.ErrQuotaExceeded):
+		code = QuotaExceededError
+	case errors.Contains(errorVal, service.ErrInvalidQueryParams),
+		errors.Contains(errorVal, service.ErrMalformedEntity):
+		code = jsonrpc.InvalidParamsError
+	case errors.Contains(errorVal, service.ErrNotFound):
+		code = NotFoundError
+	case errors.Contains(errorVal, service.ErrConflict):
+		code = ConflictError
+	default:
+		code = jsonrpc.InternalError
+	}
+	return
+}
+
+// decodeJSONRPCAddRequest is a jsonrpc.DecodeRequestFunc that decodes the
+// params of a todo.add call. Primarily useful in a server.
+func decodeJSONRPCAddRequest(_ context.Context, params json.RawMessage) (interface{}, error) {
+	var req endpoints.AddRequest
+	if err := unmarshalParams(params, &req); err != nil {
+		return nil, err
+	}
+	if req.Todo == nil {
+		return nil, service.ErrMalformedEntity
+	}
+	return req, nil
+}
+
+// decodeJSONRPCDeleteRequest is a jsonrpc.DecodeRequestFunc that decodes the
+// params of a todo.delete call. Primarily useful in a server.
+func decodeJSONRPCDeleteRequest(_ context.Context, params json.RawMessage) (interface{}, error) {
+	var req endpoints.DeleteRequest
+	if err := unmarshalParams(params, &req); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+// decodeJSONRPCUpdateRequest is a jsonrpc.DecodeRequestFunc that decodes the
+// params of a todo.update call. Primarily useful in a server.
+func decodeJSONRPCUpdateRequest(_ context.Context, params json.RawMessage) (interface{}, error) {
+	var req endpoints.UpdateRequest
+	if err := unmarshalParams(params, &req); err != nil {
+		return nil, err
+	}
+	if req.Id == "" || req.Todo == nil {
+		return nil, service.ErrMalformedEntity
+	}
+	return req, nil
+}
+
+// decodeJSONRPCListRequest is a jsonrpc.DecodeRequestFunc that decodes the
+// params of a todo.list call, which are all optional. Primarily useful in a
+// server.
+func decodeJSONRPCListRequest(_ context.Context, params json.RawMessage) (interface{}, error) {
+	var req endpoints.ListRequest
+	if len(params) == 0 {
+		return req, nil
+	}
+	if err := unmarshalParams(params, &req); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+func unmarshalParams(params json.RawMessage, v interface{}) error {
+	if len(params) == 0 {
+		return service.ErrMalformedEntity
+	}
+	if err := json.Unmarshal(params, v); err != nil {
+		return errors.Wrap(service.ErrMalformedEntity, err)
+	}
+	return nil
+}
+
+// encodeJSONRPCAddResponse is a jsonrpc.EncodeResponseFunc that encodes the
+// result of a todo.add call. Primarily useful in a server.
+func encodeJSONRPCAddResponse(_ context.Context, response interface{}) (json.RawMessage, error) {
+	return json.Marshal(response.(endpoints.AddResponse).Res)
+}
+
+// encodeJSONRPCDeleteResponse is a jsonrpc.EncodeResponseFunc that encodes the
+// result of a todo.delete call. Primarily useful in a server.
+func encodeJSONRPCDeleteResponse(_ context.Context, _ interface{}) (json.RawMessage, error) {
+	return json.RawMessage("null"), nil
+}
+
+// encodeJSONRPCUpdateResponse is a jsonrpc.EncodeResponseFunc that encodes the
+// result of a todo.update call. Primarily useful in a server.
+func encodeJSONRPCUpdateResponse(_ context.Context, response interface{}) (json.RawMessage, error) {
+	return json.Marshal(response.(endpoints.UpdateResponse).Res)
+}
+
+// encodeJSONRPCListResponse is a jsonrpc.EncodeResponseFunc that encodes the
+// result of a todo.list call, restricted to the requested fields. Primarily
+// useful in a server.
+func encodeJSONRPCListResponse(_ context.Context, response interface{}) (json.RawMessage, error) {
+	return json.Marshal(response.(endpoints.ListResponse).Response().(responses.DataRes).Data)
+}
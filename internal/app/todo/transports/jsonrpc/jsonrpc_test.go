@@ -0,0 +1,257 @@
+// +build !integration
+
+package transports_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/go-kit/kit/log"
+	"github.com/golang/mock/gomock"
+	"github.com/opentracing/opentracing-go"
+	"github.com/openzipkin/zipkin-go"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/cage1016/gokit-todo/internal/app/todo/endpoints"
+	"github.com/cage1016/gokit-todo/internal/app/todo/model"
+	"github.com/cage1016/gokit-todo/internal/app/todo/service"
+	transports "github.com/cage1016/gokit-todo/internal/app/todo/transports/jsonrpc"
+	automocks "github.com/cage1016/gokit-todo/internal/mocks/app/todo/service"
+	test "github.com/cage1016/gokit-todo/test/util"
+)
+
+func TestJSONRPCHandler(t *testing.T) {
+	type fields struct {
+		svc *automocks.MockTodoService
+	}
+	type args struct {
+		method string
+		body   string
+	}
+
+	text := "aa"
+
+	tests := []struct {
+		name      string
+		prepare   func(f *fields)
+		args      args
+		wantErr   bool
+		checkFunc func(res *http.Response, err error, body []byte)
+	}{
+		{
+			name: "add todo",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.svc.EXPECT().Add(gomock.Any(), &model.TodoReq{Text: &text}).Return(&model.TodoRes{ID: "iKe0KxpurIn0E_6vzUDAr", Text: "aa"}, nil),
+				)
+			},
+			args: args{
+				method: http.MethodPost,
+				body:   `{"jsonrpc":"2.0","method":"todo.add","params":{"todo":{"text":"aa"}},"id":1}`,
+			},
+			checkFunc: func(res *http.Response, err error, body []byte) {
+				assert.Equal(t, http.StatusOK, res.StatusCode, fmt.Sprintf("status should be 200: got %d", res.StatusCode))
+				assert.JSONEq(t, `{"jsonrpc":"2.0","id":1,"result":{"id":"iKe0KxpurIn0E_6vzUDAr","text":"aa","completed":false,"createdAt":"0001-01-01T00:00:00Z","updatedAt":"0001-01-01T00:00:00Z","completedAt":null,"archivedAt":null,"snoozedUntil":null,"version":0}}`, string(body))
+			},
+		},
+		{
+			name: "list todos with fields",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.svc.EXPECT().List(gomock.Any(), &model.TodoQuery{Fields: []string{"id"}, Limit: 1}).Return([]*model.TodoRes{{ID: "iKe0KxpurIn0E_6vzUDAr", Text: "aa"}}, nil),
+				)
+			},
+			args: args{
+				method: http.MethodPost,
+				body:   `{"jsonrpc":"2.0","method":"todo.list","params":{"query":{"fields":["id"],"limit":1}},"id":"a"}`,
+			},
+			checkFunc: func(res *http.Response, err error, body []byte) {
+				assert.JSONEq(t, `{"jsonrpc":"2.0","id":"a","result":[{"id":"iKe0KxpurIn0E_6vzUDAr"}]}`, string(body))
+			},
+		},
+		{
+			name: "update todo not found",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.svc.EXPECT().Update(gomock.Any(), "iKe0KxpurIn0E_6vzUDAr", gomock.Any()).Return(nil, service.ErrNotFound),
+				)
+			},
+			args: args{
+				method: http.MethodPost,
+				body:   `{"jsonrpc":"2.0","method":"todo.update","params":{"id":"iKe0KxpurIn0E_6vzUDAr","todo":{"completed":true}},"id":2}`,
+			},
+			checkFunc: func(res *http.Response, err error, body []byte) {
+				assert.JSONEq(t, `{"jsonrpc":"2.0","id":2,"error":{"code":-32001,"message":"non-existent entity","data":[{"message":"non-existent entity"}]}}`, string(body))
+			},
+		},
+		{
+			name: "add todo without params",
+			args: args{
+				method: http.MethodPost,
+				body:   `{"jsonrpc":"2.0","method":"todo.add","id":3}`,
+			},
+			checkFunc: func(res *http.Response, err error, body []byte) {
+				assert.Contains(t, string(body), `"code":-32602`)
+				assert.Contains(t, string(body), `"id":3`)
+			},
+		},
+		{
+			name: "batch",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.svc.EXPECT().Add(gomock.Any(), &model.TodoReq{Text: &text}).Return(&model.TodoRes{ID: "iKe0KxpurIn0E_6vzUDAr", Text: "aa"}, nil),
+					f.svc.EXPECT().Delete(gomock.Any(), "zIYPEK0zEpUc7CoQWIGB2").Return(nil),
+					f.svc.EXPECT().Delete(gomock.Any(), "iKe0KxpurIn0E_6vzUDAr").Return(nil),
+				)
+			},
+			args: args{
+				method: http.MethodPost,
+				body: `[
+					{"jsonrpc":"2.0","method":"todo.add","params":{"todo":{"text":"aa"}},"id":1},
+					{"jsonrpc":"2.0","method":"todo.delete","params":{"id":"zIYPEK0zEpUc7CoQWIGB2"}},
+					{"jsonrpc":"2.0","method":"todo.delete","params":{"id":"iKe0KxpurIn0E_6vzUDAr"},"id":2},
+					{"jsonrpc":"2.0","method":"todo.rename","id":3}
+				]`,
+			},
+			checkFunc: func(res *http.Response, err error, body []byte) {
+				assert.Equal(t, http.StatusOK, res.StatusCode, fmt.Sprintf("status should be 200: got %d", res.StatusCode))
+				var v []struct {
+					ID     int             `json:"id"`
+					Result json.RawMessage `json:"result"`
+					Error  *struct {
+						Code int `json:"code"`
+					} `json:"error"`
+				}
+				assert.Nil(t, json.Unmarshal(body, &v))
+				if assert.Len(t, v, 3) {
+					assert.Equal(t, 1, v[0].ID)
+					assert.Contains(t, string(v[0].Result), `"id":"iKe0KxpurIn0E_6vzUDAr"`)
+					assert.Equal(t, 2, v[1].ID)
+					assert.Equal(t, "null", string(v[1].Result))
+					assert.Equal(t, 3, v[2].ID)
+					assert.Equal(t, -32601, v[2].Error.Code)
+				}
+			},
+		},
+		{
+			name: "batch of invalid requests",
+			args: args{
+				method: http.MethodPost,
+				body:   `[1, 2, {"jsonrpc":"2.0","id":4}]`,
+			},
+			checkFunc: func(res *http.Response, err error, body []byte) {
+				assert.Equal(t, http.StatusOK, res.StatusCode, fmt.Sprintf("status should be 200: got %d", res.StatusCode))
+				assert.JSONEq(t, `[
+					{"jsonrpc":"2.0","id":null,"error":{"code":-32600,"message":"The JSON sent is not a valid Request object."}},
+					{"jsonrpc":"2.0","id":null,"error":{"code":-32600,"message":"The JSON sent is not a valid Request object."}},
+					{"jsonrpc":"2.0","id":4,"error":{"code":-32600,"message":"The JSON sent is not a valid Request object."}}
+				]`, string(body))
+			},
+		},
+		{
+			name: "notification",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.svc.EXPECT().Delete(gomock.Any(), "zIYPEK0zEpUc7CoQWIGB2").Return(nil),
+				)
+			},
+			args: args{
+				method: http.MethodPost,
+				body:   `{"jsonrpc":"2.0","method":"todo.delete","params":{"id":"zIYPEK0zEpUc7CoQWIGB2"}}`,
+			},
+			checkFunc: func(res *http.Response, err error, body []byte) {
+				assert.Equal(t, http.StatusNoContent, res.StatusCode, fmt.Sprintf("status should be 204: got %d", res.StatusCode))
+				assert.Empty(t, body)
+			},
+		},
+		{
+			name: "batch of notifications",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.svc.EXPECT().Delete(gomock.Any(), "zIYPEK0zEpUc7CoQWIGB2").Return(nil),
+				)
+			},
+			args: args{
+				method: http.MethodPost,
+				body:   `[{"jsonrpc":"2.0","method":"todo.delete","params":{"id":"zIYPEK0zEpUc7CoQWIGB2"}}]`,
+			},
+			checkFunc: func(res *http.Response, err error, body []byte) {
+				assert.Equal(t, http.StatusNoContent, res.StatusCode, fmt.Sprintf("status should be 204: got %d", res.StatusCode))
+			},
+		},
+		{
+			name: "empty batch",
+			args: args{
+				method: http.MethodPost,
+				body:   `[]`,
+			},
+			checkFunc: func(res *http.Response, err error, body []byte) {
+				assert.Contains(t, string(body), `"code":-32600`)
+			},
+		},
+		{
+			name: "malformed request",
+			args: args{
+				method: http.MethodPost,
+				body:   `{"jsonrpc":"2.0",`,
+			},
+			checkFunc: func(res *http.Response, err error, body []byte) {
+				assert.Contains(t, string(body), `"code":-32700`)
+			},
+		},
+		{
+			name: "get request",
+			args: args{
+				method: http.MethodGet,
+			},
+			checkFunc: func(res *http.Response, err error, body []byte) {
+				assert.Equal(t, http.StatusMethodNotAllowed, res.StatusCode, fmt.Sprintf("status should be 405: got %d", res.StatusCode))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			f := fields{
+				svc: automocks.NewMockTodoService(ctrl),
+			}
+			if tt.prepare != nil {
+				tt.prepare(&f)
+			}
+
+			logger := log.NewLogfmtLogger(os.Stderr)
+			zkt, _ := zipkin.NewTracer(nil, zipkin.WithNoopTracer(true))
+			tracer := opentracing.GlobalTracer()
+
+			eps := endpoints.New(f.svc, logger, tracer, zkt)
+			ts := httptest.NewServer(transports.NewJSONRPCHandler(eps, tracer, zkt, logger))
+			defer ts.Close()
+
+			req := test.TestRequest{
+				Client:      ts.Client(),
+				Method:      tt.args.method,
+				URL:         fmt.Sprintf("%s/rpc", ts.URL),
+				ContentType: "application/json",
+				Body:        strings.NewReader(tt.args.body),
+			}
+
+			if res, err := req.Make(); (err != nil) != tt.wantErr {
+				t.Errorf("%s: unexpected error %s", tt.name, err)
+			} else {
+				body, _ := ioutil.ReadAll(res.Body)
+				if tt.checkFunc != nil {
+					tt.checkFunc(res, err, body)
+				}
+			}
+		})
+	}
+}
@@ -9,6 +9,7 @@ import (
 	model "github.com/cage1016/gokit-todo/internal/app/todo/model"
 	gomock "github.com/golang/mock/gomock"
 	reflect "reflect"
+	time "time"
 )
 
 // MockTodoRepository is a mock of TodoRepository interface
@@ -34,6 +35,21 @@ func (m *MockTodoRepository) EXPECT() *MockTodoRepositoryMockRecorder {
 	return m.recorder
 }
 
+// APIKey mocks base method
+func (m *MockTodoRepository) APIKey(arg0 context.Context, arg1 string) (*model.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "APIKey", arg0, arg1)
+	ret0, _ := ret[0].(*model.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// APIKey indicates an expected call of APIKey
+func (mr *MockTodoRepositoryMockRecorder) APIKey(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "APIKey", reflect.TypeOf((*MockTodoRepository)(nil).APIKey), arg0, arg1)
+}
+
 // Add mocks base method
 func (m *MockTodoRepository) Add(arg0 context.Context, arg1 *model.Todo) error {
 	m.ctrl.T.Helper()
@@ -48,48 +64,223 @@ func (mr *MockTodoRepositoryMockRecorder) Add(arg0, arg1 interface{}) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Add", reflect.TypeOf((*MockTodoRepository)(nil).Add), arg0, arg1)
 }
 
+// AddAPIKey mocks base method
+func (m *MockTodoRepository) AddAPIKey(arg0 context.Context, arg1 *model.APIKey) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddAPIKey", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddAPIKey indicates an expected call of AddAPIKey
+func (mr *MockTodoRepositoryMockRecorder) AddAPIKey(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddAPIKey", reflect.TypeOf((*MockTodoRepository)(nil).AddAPIKey), arg0, arg1)
+}
+
+// AddShare mocks base method
+func (m *MockTodoRepository) AddShare(arg0 context.Context, arg1 *model.Share) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddShare", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddShare indicates an expected call of AddShare
+func (mr *MockTodoRepositoryMockRecorder) AddShare(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddShare", reflect.TypeOf((*MockTodoRepository)(nil).AddShare), arg0, arg1)
+}
+
+// AddWithin mocks base method
+func (m *MockTodoRepository) AddWithin(arg0 context.Context, arg1 *model.Todo, arg2 model.Quota) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddWithin", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddWithin indicates an expected call of AddWithin
+func (mr *MockTodoRepositoryMockRecorder) AddWithin(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddWithin", reflect.TypeOf((*MockTodoRepository)(nil).AddWithin), arg0, arg1, arg2)
+}
+
+// Archive mocks base method
+func (m *MockTodoRepository) Archive(arg0 context.Context, arg1, arg2 time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Archive", arg0, arg1, arg2)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Archive indicates an expected call of Archive
+func (mr *MockTodoRepositoryMockRecorder) Archive(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Archive", reflect.TypeOf((*MockTodoRepository)(nil).Archive), arg0, arg1, arg2)
+}
+
+// Changes mocks base method
+func (m *MockTodoRepository) Changes(arg0 context.Context, arg1 model.Owner, arg2 model.ChangeCursor, arg3 int) ([]*model.Todo, []*model.Tombstone, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Changes", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].([]*model.Todo)
+	ret1, _ := ret[1].([]*model.Tombstone)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Changes indicates an expected call of Changes
+func (mr *MockTodoRepositoryMockRecorder) Changes(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Changes", reflect.TypeOf((*MockTodoRepository)(nil).Changes), arg0, arg1, arg2, arg3)
+}
+
 // Delete mocks base method
-func (m *MockTodoRepository) Delete(arg0 context.Context, arg1 string) error {
+func (m *MockTodoRepository) Delete(arg0 context.Context, arg1 model.Owner, arg2 string, arg3 uint64) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Delete", arg0, arg1)
+	ret := m.ctrl.Call(m, "Delete", arg0, arg1, arg2, arg3)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Delete indicates an expected call of Delete
-func (mr *MockTodoRepositoryMockRecorder) Delete(arg0, arg1 interface{}) *gomock.Call {
+func (mr *MockTodoRepositoryMockRecorder) Delete(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockTodoRepository)(nil).Delete), arg0, arg1, arg2, arg3)
+}
+
+// DeleteAPIKey mocks base method
+func (m *MockTodoRepository) DeleteAPIKey(arg0 context.Context, arg1, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteAPIKey", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteAPIKey indicates an expected call of DeleteAPIKey
+func (mr *MockTodoRepositoryMockRecorder) DeleteAPIKey(arg0, arg1, arg2 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockTodoRepository)(nil).Delete), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAPIKey", reflect.TypeOf((*MockTodoRepository)(nil).DeleteAPIKey), arg0, arg1, arg2)
+}
+
+// DeleteShare mocks base method
+func (m *MockTodoRepository) DeleteShare(arg0 context.Context, arg1 model.Owner, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteShare", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteShare indicates an expected call of DeleteShare
+func (mr *MockTodoRepositoryMockRecorder) DeleteShare(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteShare", reflect.TypeOf((*MockTodoRepository)(nil).DeleteShare), arg0, arg1, arg2)
 }
 
 // Get mocks base method
-func (m *MockTodoRepository) Get(arg0 context.Context, arg1 string) (*model.Todo, error) {
+func (m *MockTodoRepository) Get(arg0 context.Context, arg1 model.Owner, arg2 string) (*model.Todo, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Get", arg0, arg1)
+	ret := m.ctrl.Call(m, "Get", arg0, arg1, arg2)
 	ret0, _ := ret[0].(*model.Todo)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Get indicates an expected call of Get
-func (mr *MockTodoRepositoryMockRecorder) Get(arg0, arg1 interface{}) *gomock.Call {
+func (mr *MockTodoRepositoryMockRecorder) Get(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockTodoRepository)(nil).Get), arg0, arg1, arg2)
+}
+
+// Grants mocks base method
+func (m *MockTodoRepository) Grants(arg0 context.Context, arg1 model.Owner, arg2 string) ([]*model.Share, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Grants", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]*model.Share)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Grants indicates an expected call of Grants
+func (mr *MockTodoRepositoryMockRecorder) Grants(arg0, arg1, arg2 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockTodoRepository)(nil).Get), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Grants", reflect.TypeOf((*MockTodoRepository)(nil).Grants), arg0, arg1, arg2)
 }
 
 // List mocks base method
-func (m *MockTodoRepository) List(arg0 context.Context) ([]*model.Todo, error) {
+func (m *MockTodoRepository) List(arg0 context.Context, arg1 *model.TodoQuery) ([]*model.Todo, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "List", arg0)
+	ret := m.ctrl.Call(m, "List", arg0, arg1)
 	ret0, _ := ret[0].([]*model.Todo)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // List indicates an expected call of List
-func (mr *MockTodoRepositoryMockRecorder) List(arg0 interface{}) *gomock.Call {
+func (mr *MockTodoRepositoryMockRecorder) List(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockTodoRepository)(nil).List), arg0, arg1)
+}
+
+// ListAPIKeys mocks base method
+func (m *MockTodoRepository) ListAPIKeys(arg0 context.Context, arg1 string) ([]*model.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAPIKeys", arg0, arg1)
+	ret0, _ := ret[0].([]*model.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAPIKeys indicates an expected call of ListAPIKeys
+func (mr *MockTodoRepositoryMockRecorder) ListAPIKeys(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockTodoRepository)(nil).List), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAPIKeys", reflect.TypeOf((*MockTodoRepository)(nil).ListAPIKeys), arg0, arg1)
+}
+
+// ListShares mocks base method
+func (m *MockTodoRepository) ListShares(arg0 context.Context, arg1 model.Owner) ([]*model.Share, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListShares", arg0, arg1)
+	ret0, _ := ret[0].([]*model.Share)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListShares indicates an expected call of ListShares
+func (mr *MockTodoRepositoryMockRecorder) ListShares(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListShares", reflect.TypeOf((*MockTodoRepository)(nil).ListShares), arg0, arg1)
+}
+
+// Stats mocks base method
+func (m *MockTodoRepository) Stats(arg0 context.Context, arg1 model.Owner, arg2 time.Time) (*model.TodoStats, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Stats", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*model.TodoStats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Stats indicates an expected call of Stats
+func (mr *MockTodoRepositoryMockRecorder) Stats(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stats", reflect.TypeOf((*MockTodoRepository)(nil).Stats), arg0, arg1, arg2)
+}
+
+// TouchAPIKey mocks base method
+func (m *MockTodoRepository) TouchAPIKey(arg0 context.Context, arg1 string, arg2 time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TouchAPIKey", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// TouchAPIKey indicates an expected call of TouchAPIKey
+func (mr *MockTodoRepositoryMockRecorder) TouchAPIKey(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TouchAPIKey", reflect.TypeOf((*MockTodoRepository)(nil).TouchAPIKey), arg0, arg1, arg2)
 }
 
 // Update mocks base method
@@ -105,3 +296,18 @@ func (mr *MockTodoRepositoryMockRecorder) Update(arg0, arg1 interface{}) *gomock
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockTodoRepository)(nil).Update), arg0, arg1)
 }
+
+// Usage mocks base method
+func (m *MockTodoRepository) Usage(arg0 context.Context, arg1 model.Owner) (*model.Usage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Usage", arg0, arg1)
+	ret0, _ := ret[0].(*model.Usage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Usage indicates an expected call of Usage
+func (mr *MockTodoRepositoryMockRecorder) Usage(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Usage", reflect.TypeOf((*MockTodoRepository)(nil).Usage), arg0, arg1)
+}
@@ -9,6 +9,7 @@ import (
 	model "github.com/cage1016/gokit-todo/internal/app/todo/model"
 	gomock "github.com/golang/mock/gomock"
 	reflect "reflect"
+	time "time"
 )
 
 // MockTodoService is a mock of TodoService interface
@@ -49,6 +50,36 @@ func (mr *MockTodoServiceMockRecorder) Add(arg0, arg1 interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Add", reflect.TypeOf((*MockTodoService)(nil).Add), arg0, arg1)
 }
 
+// AddShare mocks base method
+func (m *MockTodoService) AddShare(arg0 context.Context, arg1 *model.ShareReq) (*model.Share, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddShare", arg0, arg1)
+	ret0, _ := ret[0].(*model.Share)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddShare indicates an expected call of AddShare
+func (mr *MockTodoServiceMockRecorder) AddShare(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddShare", reflect.TypeOf((*MockTodoService)(nil).AddShare), arg0, arg1)
+}
+
+// CreateAPIKey mocks base method
+func (m *MockTodoService) CreateAPIKey(arg0 context.Context, arg1 *model.APIKeyReq) (*model.CreatedAPIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAPIKey", arg0, arg1)
+	ret0, _ := ret[0].(*model.CreatedAPIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateAPIKey indicates an expected call of CreateAPIKey
+func (mr *MockTodoServiceMockRecorder) CreateAPIKey(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAPIKey", reflect.TypeOf((*MockTodoService)(nil).CreateAPIKey), arg0, arg1)
+}
+
 // Delete mocks base method
 func (m *MockTodoService) Delete(arg0 context.Context, arg1 string) error {
 	m.ctrl.T.Helper()
@@ -63,19 +94,167 @@ func (mr *MockTodoServiceMockRecorder) Delete(arg0, arg1 interface{}) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockTodoService)(nil).Delete), arg0, arg1)
 }
 
+// DeleteShare mocks base method
+func (m *MockTodoService) DeleteShare(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteShare", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteShare indicates an expected call of DeleteShare
+func (mr *MockTodoServiceMockRecorder) DeleteShare(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteShare", reflect.TypeOf((*MockTodoService)(nil).DeleteShare), arg0, arg1)
+}
+
+// Get mocks base method
+func (m *MockTodoService) Get(arg0 context.Context, arg1 string) (*model.TodoRes, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", arg0, arg1)
+	ret0, _ := ret[0].(*model.TodoRes)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get
+func (mr *MockTodoServiceMockRecorder) Get(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockTodoService)(nil).Get), arg0, arg1)
+}
+
 // List mocks base method
-func (m *MockTodoService) List(arg0 context.Context) ([]*model.TodoRes, error) {
+func (m *MockTodoService) List(arg0 context.Context, arg1 *model.TodoQuery) ([]*model.TodoRes, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "List", arg0)
+	ret := m.ctrl.Call(m, "List", arg0, arg1)
 	ret0, _ := ret[0].([]*model.TodoRes)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // List indicates an expected call of List
-func (mr *MockTodoServiceMockRecorder) List(arg0 interface{}) *gomock.Call {
+func (mr *MockTodoServiceMockRecorder) List(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockTodoService)(nil).List), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockTodoService)(nil).List), arg0, arg1)
+}
+
+// ListAPIKeys mocks base method
+func (m *MockTodoService) ListAPIKeys(arg0 context.Context) ([]*model.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAPIKeys", arg0)
+	ret0, _ := ret[0].([]*model.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAPIKeys indicates an expected call of ListAPIKeys
+func (mr *MockTodoServiceMockRecorder) ListAPIKeys(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAPIKeys", reflect.TypeOf((*MockTodoService)(nil).ListAPIKeys), arg0)
+}
+
+// ListShares mocks base method
+func (m *MockTodoService) ListShares(arg0 context.Context) ([]*model.Share, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListShares", arg0)
+	ret0, _ := ret[0].([]*model.Share)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListShares indicates an expected call of ListShares
+func (mr *MockTodoServiceMockRecorder) ListShares(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListShares", reflect.TypeOf((*MockTodoService)(nil).ListShares), arg0)
+}
+
+// Push mocks base method
+func (m *MockTodoService) Push(arg0 context.Context, arg1 []*model.Mutation) ([]*model.MutationResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Push", arg0, arg1)
+	ret0, _ := ret[0].([]*model.MutationResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Push indicates an expected call of Push
+func (mr *MockTodoServiceMockRecorder) Push(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Push", reflect.TypeOf((*MockTodoService)(nil).Push), arg0, arg1)
+}
+
+// RevokeAPIKey mocks base method
+func (m *MockTodoService) RevokeAPIKey(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeAPIKey", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeAPIKey indicates an expected call of RevokeAPIKey
+func (mr *MockTodoServiceMockRecorder) RevokeAPIKey(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeAPIKey", reflect.TypeOf((*MockTodoService)(nil).RevokeAPIKey), arg0, arg1)
+}
+
+// Snooze mocks base method
+func (m *MockTodoService) Snooze(arg0 context.Context, arg1 string, arg2 time.Time) (*model.TodoRes, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Snooze", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*model.TodoRes)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Snooze indicates an expected call of Snooze
+func (mr *MockTodoServiceMockRecorder) Snooze(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Snooze", reflect.TypeOf((*MockTodoService)(nil).Snooze), arg0, arg1, arg2)
+}
+
+// Stats mocks base method
+func (m *MockTodoService) Stats(arg0 context.Context, arg1 int) (*model.TodoStats, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Stats", arg0, arg1)
+	ret0, _ := ret[0].(*model.TodoStats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Stats indicates an expected call of Stats
+func (mr *MockTodoServiceMockRecorder) Stats(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stats", reflect.TypeOf((*MockTodoService)(nil).Stats), arg0, arg1)
+}
+
+// Sync mocks base method
+func (m *MockTodoService) Sync(arg0 context.Context, arg1 string) (*model.Changes, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Sync", arg0, arg1)
+	ret0, _ := ret[0].(*model.Changes)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Sync indicates an expected call of Sync
+func (mr *MockTodoServiceMockRecorder) Sync(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Sync", reflect.TypeOf((*MockTodoService)(nil).Sync), arg0, arg1)
+}
+
+// Unarchive mocks base method
+func (m *MockTodoService) Unarchive(arg0 context.Context, arg1 string) (*model.TodoRes, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Unarchive", arg0, arg1)
+	ret0, _ := ret[0].(*model.TodoRes)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Unarchive indicates an expected call of Unarchive
+func (mr *MockTodoServiceMockRecorder) Unarchive(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Unarchive", reflect.TypeOf((*MockTodoService)(nil).Unarchive), arg0, arg1)
 }
 
 // Update mocks base method
@@ -92,3 +271,33 @@ func (mr *MockTodoServiceMockRecorder) Update(arg0, arg1, arg2 interface{}) *gom
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockTodoService)(nil).Update), arg0, arg1, arg2)
 }
+
+// Usage mocks base method
+func (m *MockTodoService) Usage(arg0 context.Context) (*model.Usage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Usage", arg0)
+	ret0, _ := ret[0].(*model.Usage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Usage indicates an expected call of Usage
+func (mr *MockTodoServiceMockRecorder) Usage(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Usage", reflect.TypeOf((*MockTodoService)(nil).Usage), arg0)
+}
+
+// Watch mocks base method
+func (m *MockTodoService) Watch(arg0 context.Context, arg1 *model.EventQuery) (<-chan *model.TodoEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Watch", arg0, arg1)
+	ret0, _ := ret[0].(<-chan *model.TodoEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Watch indicates an expected call of Watch
+func (mr *MockTodoServiceMockRecorder) Watch(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Watch", reflect.TypeOf((*MockTodoService)(nil).Watch), arg0, arg1)
+}
@@ -0,0 +1,86 @@
+package authn
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"net/http"
+	"strings"
+
+	grpctransport "github.com/go-kit/kit/transport/grpc"
+	httptransport "github.com/go-kit/kit/transport/http"
+	"google.golang.org/grpc/metadata"
+)
+
+// APIKeyPrefix starts every API key, telling them apart from the JWTs when
+// they are sent as bearer tokens.
+const APIKeyPrefix = "todo_"
+
+// APIKeyHeader is the HTTP header, and lower-cased the gRPC metadata key,
+// carrying an API key.
+const APIKeyHeader = "X-API-Key"
+
+// APIKeys authenticates the callers presenting an API key.
+type APIKeys interface {
+	// Authenticate returns the claims of the caller presenting key, or an
+	// error if the key is unknown, revoked or expired.
+	Authenticate(ctx context.Context, key string) (*Claims, error)
+}
+
+type apiKeyContextKey struct{}
+
+// NewAPIKey returns a new random API key, only its HashAPIKey is meant to be
+// stored.
+func NewAPIKey() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return APIKeyPrefix + base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// HashAPIKey returns the hash API keys are stored and looked up by. The keys
+// are random enough for a fast hash not to be brute forced.
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// IsAPIKey tells whether the bearer token is an API key rather than a JWT.
+func IsAPIKey(token string) bool {
+	return strings.HasPrefix(token, APIKeyPrefix)
+}
+
+// apiKeyFromContext returns the API key of the APIKeyHeader put on the
+// context by HTTPAPIKeyToContext or GRPCAPIKeyToContext, or the bearer token
+// if it is an API key.
+func apiKeyFromContext(ctx context.Context, token string) (string, bool) {
+	if key, ok := ctx.Value(apiKeyContextKey{}).(string); ok {
+		return key, true
+	}
+	return token, IsAPIKey(token)
+}
+
+// HTTPAPIKeyToContext moves the API key of the APIKeyHeader of a request to
+// the context, where NewParser finds it.
+func HTTPAPIKeyToContext() httptransport.RequestFunc {
+	return func(ctx context.Context, r *http.Request) context.Context {
+		if key := r.Header.Get(APIKeyHeader); key != "" {
+			return context.WithValue(ctx, apiKeyContextKey{}, key)
+		}
+		return ctx
+	}
+}
+
+// GRPCAPIKeyToContext moves the API key of the APIKeyHeader metadata of a
+// request to the context, where NewParser finds it.
+func GRPCAPIKeyToContext() grpctransport.ServerRequestFunc {
+	return func(ctx context.Context, md metadata.MD) context.Context {
+		if keys := md.Get(strings.ToLower(APIKeyHeader)); len(keys) > 0 && keys[0] != "" {
+			return context.WithValue(ctx, apiKeyContextKey{}, keys[0])
+		}
+		return ctx
+	}
+}
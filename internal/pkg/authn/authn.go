@@ -0,0 +1,153 @@
+package authn
+
+import (
+	"context"
+
+	jwt "github.com/dgrijalva/jwt-go"
+	kitjwt "github.com/go-kit/kit/auth/jwt"
+	"github.com/go-kit/kit/endpoint"
+
+	"github.com/cage1016/gokit-todo/internal/pkg/errors"
+)
+
+var (
+	// ErrUnauthorized wraps the errors of the requests failing authentication.
+	ErrUnauthorized = errors.New("missing or invalid credentials")
+
+	// ErrNoSubject rejects the tokens without a sub claim, which identifies
+	// the caller.
+	ErrNoSubject = errors.New("token has no subject")
+
+	// ErrNoExpiry rejects the tokens without an exp claim, unless
+	// WithoutExpiry is given.
+	ErrNoExpiry = errors.New("token has no expiry")
+)
+
+// Claims are the claims of a verified token, the subject identifies the
+// caller within its tenant, if any, and the roles are granted their
+// permissions by the authorization policy.
+type Claims struct {
+	jwt.StandardClaims
+	Tenant string   `json:"tenant,omitempty"`
+	Roles  []string `json:"roles,omitempty"`
+	// APIKey marks the claims of an API key, whose roles are its scopes and
+	// bound what it may do. It is never read from a token.
+	APIKey bool `json:"-"`
+}
+
+// NewContext returns a copy of ctx carrying claims, as NewParser does.
+func NewContext(ctx context.Context, claims *Claims) context.Context {
+	return context.WithValue(ctx, kitjwt.JWTClaimsContextKey, claims)
+}
+
+// FromContext returns the claims of the token verified by NewParser.
+func FromContext(ctx context.Context) (*Claims, bool) {
+	claims, ok := ctx.Value(kitjwt.JWTClaimsContextKey).(*Claims)
+	return claims, ok
+}
+
+// Failed reports whether err is an error of NewParser, one of a request
+// failing authentication.
+func Failed(err error) bool {
+	return err != nil && errors.Contains(errors.Cast(err), ErrUnauthorized)
+}
+
+// ParserOption configures the middleware returned by NewParser.
+type ParserOption func(*parser)
+
+// WithAPIKeys makes the middleware authenticate the callers presenting an
+// API key with apiKeys, the API keys are rejected without it.
+func WithAPIKeys(apiKeys APIKeys) ParserOption {
+	return func(p *parser) {
+		p.apiKeys = apiKeys
+	}
+}
+
+// WithoutExpiry makes the middleware accept the tokens without an exp claim,
+// which never expire.
+func WithoutExpiry() ParserOption {
+	return func(p *parser) {
+		p.noExpiry = true
+	}
+}
+
+type parser struct {
+	keys     *Keys
+	apiKeys  APIKeys
+	noExpiry bool
+}
+
+// NewParser returns an endpoint middleware that verifies the token put on the
+// context by kitjwt.HTTPToContext or kitjwt.GRPCToContext with the keys, and
+// puts its claims on the context. The API keys, sent as the token or put on
+// the context by HTTPAPIKeyToContext or GRPCAPIKeyToContext, are
+// authenticated instead if WithAPIKeys is given. The tokens need a subject,
+// and an expiry unless WithoutExpiry is given. The errors are wrapped by
+// ErrUnauthorized.
+func NewParser(keys *Keys, opts ...ParserOption) endpoint.Middleware {
+	p := &parser{keys: keys}
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	return func(next endpoint.Endpoint) endpoint.Endpoint {
+		return func(ctx context.Context, request interface{}) (interface{}, error) {
+			tokenString, ok := ctx.Value(kitjwt.JWTTokenContextKey).(string)
+			if key, isKey := apiKeyFromContext(ctx, tokenString); isKey {
+				claims, err := p.authenticate(ctx, key)
+				if err != nil {
+					return nil, errors.Wrap(ErrUnauthorized, err)
+				}
+				return next(NewContext(ctx, claims), request)
+			}
+			if !ok {
+				return nil, errors.Wrap(ErrUnauthorized, kitjwt.ErrTokenContextMissing)
+			}
+
+			claims := &Claims{}
+			token, err := jwt.ParseWithClaims(tokenString, claims, p.keys.Keyfunc)
+			if err != nil {
+				return nil, errors.Wrap(ErrUnauthorized, parseError(err))
+			}
+			if !token.Valid {
+				return nil, errors.Wrap(ErrUnauthorized, kitjwt.ErrTokenInvalid)
+			}
+			if claims.Subject == "" {
+				return nil, errors.Wrap(ErrUnauthorized, ErrNoSubject)
+			}
+			if claims.ExpiresAt == 0 && !p.noExpiry {
+				return nil, errors.Wrap(ErrUnauthorized, ErrNoExpiry)
+			}
+
+			return next(NewContext(ctx, claims), request)
+		}
+	}
+}
+
+// authenticate returns the claims of the caller presenting the API key.
+func (p *parser) authenticate(ctx context.Context, key string) (*Claims, error) {
+	if p.apiKeys == nil {
+		return nil, errors.New("API keys are not accepted")
+	}
+	return p.apiKeys.Authenticate(ctx, key)
+}
+
+// parseError converts the validation errors of jwt-go to the errors of
+// kitjwt, as kitjwt.NewParser does.
+func parseError(err error) error {
+	e, ok := err.(*jwt.ValidationError)
+	if !ok {
+		return err
+	}
+	switch {
+	case e.Errors&jwt.ValidationErrorMalformed != 0:
+		return kitjwt.ErrTokenMalformed
+	case e.Errors&jwt.ValidationErrorExpired != 0:
+		return kitjwt.ErrTokenExpired
+	case e.Errors&jwt.ValidationErrorNotValidYet != 0:
+		return kitjwt.ErrTokenNotActive
+	case e.Inner != nil:
+		return e.Inner
+	}
+	return err
+}
@@ -0,0 +1,128 @@
+package authn
+
+import (
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+
+	jwt "github.com/dgrijalva/jwt-go"
+	kitjwt "github.com/go-kit/kit/auth/jwt"
+)
+
+// Keys verify the tokens signed with HS256 or RS256. The keys are looked up
+// by the kid of the tokens, the keys without an id verify the tokens without
+// a kid.
+type Keys struct {
+	hmac map[string][]byte
+	rsa  map[string]*rsa.PublicKey
+}
+
+// LoadKeys returns the keys made of the HS256 secret, the PEM-encoded RS256
+// public key of the publicKeyFile and the keys of the local JWKS file, each
+// of them being optional.
+func LoadKeys(secret, publicKeyFile, jwksFile string) (*Keys, error) {
+	keys := &Keys{hmac: map[string][]byte{}, rsa: map[string]*rsa.PublicKey{}}
+	if secret != "" {
+		keys.hmac[""] = []byte(secret)
+	}
+	if publicKeyFile != "" {
+		b, err := ioutil.ReadFile(publicKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		key, err := jwt.ParseRSAPublicKeyFromPEM(b)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", publicKeyFile, err)
+		}
+		keys.rsa[""] = key
+	}
+	if jwksFile != "" {
+		b, err := ioutil.ReadFile(jwksFile)
+		if err != nil {
+			return nil, err
+		}
+		if err := keys.addJWKS(b); err != nil {
+			return nil, fmt.Errorf("%s: %v", jwksFile, err)
+		}
+	}
+	return keys, nil
+}
+
+// Empty tells whether there are no keys, so that no token can be verified.
+func (k *Keys) Empty() bool {
+	return k == nil || len(k.hmac)+len(k.rsa) == 0
+}
+
+// Keyfunc is a jwt.Keyfunc returning the key of token, the key type has to
+// match the signing method so that a public key is never used as a secret.
+func (k *Keys) Keyfunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	switch token.Method {
+	case jwt.SigningMethodHS256:
+		if key, ok := k.hmac[kid]; ok {
+			return key, nil
+		}
+	case jwt.SigningMethodRS256:
+		if key, ok := k.rsa[kid]; ok {
+			return key, nil
+		}
+	default:
+		return nil, kitjwt.ErrUnexpectedSigningMethod
+	}
+	return nil, fmt.Errorf("unknown key %q", kid)
+}
+
+// jwk is a JSON Web Key, RFC 7517.
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	// the members of the RSA keys
+	N string `json:"n"`
+	E string `json:"e"`
+	// the member of the symmetric keys
+	K string `json:"k"`
+}
+
+// addJWKS adds the RSA and the symmetric keys of the JSON Web Key Set b,
+// the keys meant for encryption or other algorithms are skipped.
+func (k *Keys) addJWKS(b []byte) error {
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(b, &set); err != nil {
+		return err
+	}
+
+	for _, key := range set.Keys {
+		if key.Use != "" && key.Use != "sig" {
+			continue
+		}
+		switch {
+		case key.Kty == "RSA" && (key.Alg == "" || key.Alg == jwt.SigningMethodRS256.Alg()):
+			n, err := base64.RawURLEncoding.DecodeString(key.N)
+			if err != nil {
+				return fmt.Errorf("key %q: %v", key.Kid, err)
+			}
+			e, err := base64.RawURLEncoding.DecodeString(key.E)
+			if err != nil {
+				return fmt.Errorf("key %q: %v", key.Kid, err)
+			}
+			k.rsa[key.Kid] = &rsa.PublicKey{
+				N: new(big.Int).SetBytes(n),
+				E: int(new(big.Int).SetBytes(e).Int64()),
+			}
+		case key.Kty == "oct" && (key.Alg == "" || key.Alg == jwt.SigningMethodHS256.Alg()):
+			secret, err := base64.RawURLEncoding.DecodeString(key.K)
+			if err != nil {
+				return fmt.Errorf("key %q: %v", key.Kid, err)
+			}
+			k.hmac[key.Kid] = secret
+		}
+	}
+	return nil
+}
@@ -0,0 +1,35 @@
+package authz
+
+import (
+	"context"
+
+	"github.com/go-kit/kit/endpoint"
+
+	"github.com/cage1016/gokit-todo/internal/pkg/errors"
+)
+
+// ErrForbidden wraps the errors of the requests the caller is not allowed to
+// make.
+var ErrForbidden = errors.New("permission denied")
+
+// Engine decides whether the caller of ctx may perform action on resource,
+// the built-in one being the role based Policy. It returns an error wrapped
+// by ErrForbidden if not.
+type Engine interface {
+	Authorize(ctx context.Context, action, resource string) error
+}
+
+// NewAuthorizer returns the authorization func of endpoints.AuthzMiddleware,
+// which makes an endpoint middleware authorizing action on resource with e.
+func NewAuthorizer(e Engine) func(action, resource string) endpoint.Middleware {
+	return func(action, resource string) endpoint.Middleware {
+		return func(next endpoint.Endpoint) endpoint.Endpoint {
+			return func(ctx context.Context, request interface{}) (interface{}, error) {
+				if err := e.Authorize(ctx, action, resource); err != nil {
+					return nil, err
+				}
+				return next(ctx, request)
+			}
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package authz
+
+import (
+	"context"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+)
+
+var _ Engine = (*FileEngine)(nil)
+
+// FileEngine evaluates the Policy of a file, which is reloaded when it
+// changes so that permissions are updated without a restart.
+type FileEngine struct {
+	file   string
+	logger log.Logger
+
+	mu      sync.RWMutex
+	policy  *Policy
+	modTime time.Time
+	size    int64
+}
+
+// NewFileEngine returns a FileEngine evaluating the policy of file, which
+// has to be valid.
+func NewFileEngine(file string, logger log.Logger) (*FileEngine, error) {
+	e := &FileEngine{file: file, logger: logger}
+	if _, err := e.Reload(); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// Authorize implements Engine with the last valid policy.
+func (e *FileEngine) Authorize(ctx context.Context, action, resource string) error {
+	e.mu.RLock()
+	p := e.policy
+	e.mu.RUnlock()
+	return p.Authorize(ctx, action, resource)
+}
+
+// RolesOf returns the roles of the caller of ctx with the last valid policy.
+func (e *FileEngine) RolesOf(ctx context.Context) []string {
+	e.mu.RLock()
+	p := e.policy
+	e.mu.RUnlock()
+	return p.RolesOf(ctx)
+}
+
+// Reload reads the policy file again if it changed since it was last read,
+// and reports whether it did. The current policy is kept if the file is
+// invalid.
+func (e *FileEngine) Reload() (bool, error) {
+	fi, err := os.Stat(e.file)
+	if err != nil {
+		return false, err
+	}
+
+	e.mu.RLock()
+	unchanged := e.policy != nil && fi.ModTime().Equal(e.modTime) && fi.Size() == e.size
+	e.mu.RUnlock()
+	if unchanged {
+		return false, nil
+	}
+
+	p, err := LoadPolicy(e.file)
+	if err != nil {
+		return false, err
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.policy, e.modTime, e.size = p, fi.ModTime(), fi.Size()
+	return true, nil
+}
+
+// Run reloads the policy file every interval until ctx is cancelled.
+func (e *FileEngine) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if reloaded, err := e.Reload(); err != nil {
+			level.Error(e.logger).Log("policy", e.file, "err", err, "msg", "keeping the current policy")
+		} else if reloaded {
+			level.Info(e.logger).Log("policy", e.file, "msg", "reloaded")
+		}
+	}
+}
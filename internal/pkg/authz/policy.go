@@ -0,0 +1,118 @@
+package authz
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/cage1016/gokit-todo/internal/pkg/authn"
+	"github.com/cage1016/gokit-todo/internal/pkg/errors"
+)
+
+// Policy grants permissions to roles, the roles of a caller being the ones
+// of its token, the ones the policy assigns to its subject and the default
+// ones, and the scopes alone of an API key. A permission is a "resource:action" pair, either of them may be "*".
+// The subjects are named "tenant/subject", as the same subject may belong to
+// several tenants, and "/subject" without a tenant.
+//
+// A policy letting viewers list the todos and editors change them reads:
+//
+//	{
+//	  "roles": {
+//	    "viewer": ["todo:list", "todo:get"],
+//	    "editor": ["todo:*"]
+//	  },
+//	  "subjects": {"acme/alice": ["editor"]},
+//	  "default": ["viewer"]
+//	}
+type Policy struct {
+	Roles    map[string][]string `json:"roles"`
+	Subjects map[string][]string `json:"subjects"`
+	Default  []string            `json:"default"`
+}
+
+// ParsePolicy parses and validates the JSON policy b.
+func ParsePolicy(b []byte) (*Policy, error) {
+	p := &Policy{}
+	if err := json.Unmarshal(b, p); err != nil {
+		return nil, err
+	}
+	for role, perms := range p.Roles {
+		for _, perm := range perms {
+			if strings.Count(perm, ":") != 1 {
+				return nil, fmt.Errorf("role %q: malformed permission %q, want resource:action", role, perm)
+			}
+		}
+	}
+	for subject, roles := range p.Subjects {
+		if !strings.Contains(subject, "/") {
+			return nil, fmt.Errorf("subject %q: malformed subject, want tenant/subject", subject)
+		}
+		for _, role := range roles {
+			if _, ok := p.Roles[role]; !ok {
+				return nil, fmt.Errorf("subject %q: unknown role %q", subject, role)
+			}
+		}
+	}
+	for _, role := range p.Default {
+		if _, ok := p.Roles[role]; !ok {
+			return nil, fmt.Errorf("default: unknown role %q", role)
+		}
+	}
+	return p, nil
+}
+
+// LoadPolicy reads the JSON policy of file.
+func LoadPolicy(file string) (*Policy, error) {
+	b, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	p, err := ParsePolicy(b)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", file, err)
+	}
+	return p, nil
+}
+
+// RolesOf returns the roles of the caller of ctx: the default ones, the ones
+// of its token and the ones of its subject. The roles of an API key are only
+// its scopes, so that a key scoped down from its subject stays so.
+func (p *Policy) RolesOf(ctx context.Context) []string {
+	claims, ok := authn.FromContext(ctx)
+	if ok && claims.APIKey {
+		return append([]string{}, claims.Roles...)
+	}
+	roles := append([]string{}, p.Default...)
+	if ok {
+		roles = append(roles, claims.Roles...)
+		roles = append(roles, p.Subjects[claims.Tenant+"/"+claims.Subject]...)
+	}
+	return roles
+}
+
+// Authorize implements Engine.
+func (p *Policy) Authorize(ctx context.Context, action, resource string) error {
+	var subject string
+	if claims, ok := authn.FromContext(ctx); ok {
+		subject = claims.Subject
+	}
+
+	for _, role := range p.RolesOf(ctx) {
+		for _, perm := range p.Roles[role] {
+			if matches(perm, resource, action) {
+				return nil
+			}
+		}
+	}
+	return errors.Wrap(ErrForbidden, fmt.Errorf("%q may not %s %s", subject, action, resource))
+}
+
+// matches reports whether the permission perm grants action on resource.
+func matches(perm, resource, action string) bool {
+	i := strings.IndexByte(perm, ':')
+	r, a := perm[:i], perm[i+1:]
+	return (r == "*" || r == resource) && (a == "*" || a == action)
+}
@@ -0,0 +1,82 @@
+// +build !integration
+
+package authz_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	jwt "github.com/dgrijalva/jwt-go"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/cage1016/gokit-todo/internal/pkg/authn"
+	"github.com/cage1016/gokit-todo/internal/pkg/authz"
+	"github.com/cage1016/gokit-todo/internal/pkg/errors"
+)
+
+func TestPolicy_Authorize(t *testing.T) {
+	policy, err := authz.ParsePolicy([]byte(`{
+		"roles": {"viewer": ["todo:list", "todo:get"], "editor": ["todo:*"], "writer": ["todo:add"]},
+		"subjects": {"acme/alice": ["editor"]},
+		"default": ["writer"]
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type args struct {
+		claims *authn.Claims
+		action string
+	}
+
+	tests := []struct {
+		name    string
+		args    args
+		roles   []string
+		wantErr bool
+	}{
+		{
+			name:  "editor of the policy",
+			args:  args{claims: &authn.Claims{StandardClaims: jwt.StandardClaims{Subject: "alice"}, Tenant: "acme"}, action: "delete"},
+			roles: []string{"writer", "editor"},
+		},
+		{
+			name:    "default roles of an unknown subject",
+			args:    args{claims: &authn.Claims{StandardClaims: jwt.StandardClaims{Subject: "bob"}, Tenant: "acme", Roles: []string{"viewer"}}, action: "delete"},
+			roles:   []string{"writer", "viewer"},
+			wantErr: true,
+		},
+		{
+			name:  "API key scoped as its subject",
+			args:  args{claims: &authn.Claims{StandardClaims: jwt.StandardClaims{Subject: "alice"}, Tenant: "acme", Roles: []string{"editor"}, APIKey: true}, action: "delete"},
+			roles: []string{"editor"},
+		},
+		{
+			name:    "API key scoped down from an editor subject",
+			args:    args{claims: &authn.Claims{StandardClaims: jwt.StandardClaims{Subject: "alice"}, Tenant: "acme", Roles: []string{"viewer"}, APIKey: true}, action: "add"},
+			roles:   []string{"viewer"},
+			wantErr: true,
+		},
+		{
+			name:  "API key listing within its scopes",
+			args:  args{claims: &authn.Claims{StandardClaims: jwt.StandardClaims{Subject: "alice"}, Tenant: "acme", Roles: []string{"viewer"}, APIKey: true}, action: "list"},
+			roles: []string{"viewer"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := authn.NewContext(context.Background(), tt.args.claims)
+
+			assert.ElementsMatch(t, tt.roles, policy.RolesOf(ctx), fmt.Sprintf("roles: expected %v got %v", tt.roles, policy.RolesOf(ctx)))
+			err := policy.Authorize(ctx, tt.args.action, "todo")
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Authorize(ctx context.Context, action, resource string) error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				assert.True(t, errors.Contains(errors.Cast(err), authz.ErrForbidden), fmt.Sprintf("err: expected authz.ErrForbidden got %v", err))
+			}
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package instrumenting
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/go-kit/kit/endpoint"
+	"github.com/go-kit/kit/metrics"
+	grpctransport "github.com/go-kit/kit/transport/grpc"
+	httptransport "github.com/go-kit/kit/transport/http"
+	"google.golang.org/grpc/metadata"
+)
+
+// The transports labelling the metrics of the requests.
+const (
+	HTTP      = "http"
+	JSONRPC   = "jsonrpc"
+	WebSocket = "websocket"
+	GRPC      = "grpc"
+	Gateway   = "gateway"
+)
+
+// Endpoint counts the requests of the endpoints, and the failed ones, and
+// observes their duration in seconds, labelled with their method and
+// transport.
+type Endpoint struct {
+	Requests metrics.Counter
+	Errors   metrics.Counter
+	Duration metrics.Histogram
+}
+
+// Middleware returns the middleware instrumenting the requests of method.
+// Their transport is the one put on the context by HTTPToContext or
+// GRPCToContext, or "unknown".
+func (e Endpoint) Middleware(method string) endpoint.Middleware {
+	return func(next endpoint.Endpoint) endpoint.Endpoint {
+		return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+			defer func(begin time.Time) {
+				lvs := []string{"method", method, "transport", transportOf(ctx)}
+				e.Requests.With(lvs...).Add(1)
+				if err != nil {
+					e.Errors.With(lvs...).Add(1)
+				}
+				e.Duration.With(lvs...).Observe(time.Since(begin).Seconds())
+			}(time.Now())
+			return next(ctx, request)
+		}
+	}
+}
+
+type transportContextKey struct{}
+
+// transportOf returns the transport of the request of ctx.
+func transportOf(ctx context.Context) string {
+	if transport, ok := ctx.Value(transportContextKey{}).(string); ok {
+		return transport
+	}
+	return "unknown"
+}
+
+// NewContext returns a context carrying the transport of its request, which
+// is kept if ctx already carries one: the requests of the gateway are served
+// by the gRPC server in process, but they are labelled with Gateway.
+func NewContext(ctx context.Context, transport string) context.Context {
+	if _, ok := ctx.Value(transportContextKey{}).(string); ok {
+		return ctx
+	}
+	return context.WithValue(ctx, transportContextKey{}, transport)
+}
+
+// HTTPToContext puts the transport of the requests of an HTTP server on the
+// context.
+func HTTPToContext(transport string) httptransport.RequestFunc {
+	return func(ctx context.Context, _ *http.Request) context.Context {
+		return NewContext(ctx, transport)
+	}
+}
+
+// GRPCToContext puts the GRPC transport on the context of the requests of a
+// gRPC server.
+func GRPCToContext() grpctransport.ServerRequestFunc {
+	return func(ctx context.Context, _ metadata.MD) context.Context {
+		return NewContext(ctx, GRPC)
+	}
+}
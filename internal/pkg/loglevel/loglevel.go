@@ -0,0 +1,109 @@
+package loglevel
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+)
+
+// The levels of the logs, from the most verbose to the least.
+const (
+	Debug = "debug"
+	Info  = "info"
+	Warn  = "warn"
+	Error = "error"
+)
+
+var levels = []struct {
+	name  string
+	allow level.Option
+}{
+	{Debug, level.AllowDebug()},
+	{Info, level.AllowInfo()},
+	{Warn, level.AllowWarn()},
+	{Error, level.AllowError()},
+}
+
+// index returns the index of the level named lvl in levels.
+func index(lvl string) (int32, error) {
+	for i, l := range levels {
+		if l.name == lvl {
+			return int32(i), nil
+		}
+	}
+	return 0, fmt.Errorf("%q: the level has to be one of debug, info, warn or error", lvl)
+}
+
+// Logger drops the logs below its level, as level.NewFilter does, but its
+// level can be changed at runtime. The logs without a level are kept.
+type Logger struct {
+	filters []log.Logger
+	current int32
+}
+
+// New returns a Logger of next logging at lvl and above.
+func New(next log.Logger, lvl string) (*Logger, error) {
+	i, err := index(lvl)
+	if err != nil {
+		return nil, err
+	}
+	l := &Logger{current: i}
+	for _, lv := range levels {
+		l.filters = append(l.filters, level.NewFilter(next, lv.allow))
+	}
+	return l, nil
+}
+
+// Log implements log.Logger.
+func (l *Logger) Log(keyvals ...interface{}) error {
+	return l.filters[atomic.LoadInt32(&l.current)].Log(keyvals...)
+}
+
+// Level returns the current level.
+func (l *Logger) Level() string {
+	return levels[atomic.LoadInt32(&l.current)].name
+}
+
+// SetLevel changes the level to lvl.
+func (l *Logger) SetLevel(lvl string) error {
+	i, err := index(lvl)
+	if err != nil {
+		return err
+	}
+	atomic.StoreInt32(&l.current, i)
+	return nil
+}
+
+type levelBody struct {
+	Level string `json:"level"`
+}
+
+// Handler returns the handler of the level: GET returns it as
+// {"level":"info"}, and PUT changes it to the one of the same body.
+func (l *Logger) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPut:
+			var body levelBody
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			if err := l.SetLevel(body.Level); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+		default:
+			w.Header().Set("Allow", "GET, PUT")
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		json.NewEncoder(w).Encode(levelBody{Level: l.Level()})
+	})
+}
@@ -0,0 +1,169 @@
+// +build !integration
+
+package loglevel_test
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/cage1016/gokit-todo/internal/pkg/loglevel"
+)
+
+func TestLogger_SetLevel(t *testing.T) {
+	tests := []struct {
+		name    string
+		level   string
+		wantErr bool
+		want    string
+		logged  []string
+	}{
+		{
+			name:   "debug",
+			level:  loglevel.Debug,
+			want:   loglevel.Debug,
+			logged: []string{"debug", "info", "warn", "error", "none"},
+		},
+		{
+			name:   "warn",
+			level:  loglevel.Warn,
+			want:   loglevel.Warn,
+			logged: []string{"warn", "error", "none"},
+		},
+		{
+			name:   "error",
+			level:  loglevel.Error,
+			want:   loglevel.Error,
+			logged: []string{"error", "none"},
+		},
+		{
+			name:    "unknown level",
+			level:   "verbose",
+			wantErr: true,
+			want:    loglevel.Info,
+			logged:  []string{"info", "warn", "error", "none"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			logger, err := loglevel.New(log.NewLogfmtLogger(&buf), loglevel.Info)
+			if err != nil {
+				t.Fatalf("unexpected error %s", err)
+			}
+
+			if err := logger.SetLevel(tt.level); (err != nil) != tt.wantErr {
+				t.Errorf("SetLevel(lvl string) error = %v, wantErr %v", err, tt.wantErr)
+			}
+			assert.Equal(t, tt.want, logger.Level(), fmt.Sprintf("level: expected %s got %s", tt.want, logger.Level()))
+
+			level.Debug(logger).Log("msg", "debug")
+			level.Info(logger).Log("msg", "info")
+			level.Warn(logger).Log("msg", "warn")
+			level.Error(logger).Log("msg", "error")
+			logger.Log("msg", "none")
+
+			var logged []string
+			for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+				logged = append(logged, line[strings.Index(line, "msg=")+len("msg="):])
+			}
+			assert.Equal(t, tt.logged, logged, fmt.Sprintf("logs: expected %v got %v", tt.logged, logged))
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	if _, err := loglevel.New(log.NewNopLogger(), "verbose"); err == nil {
+		t.Errorf("New(next log.Logger, lvl string) error = nil, want an unknown level error")
+	}
+}
+
+func TestLogger_Handler(t *testing.T) {
+	type args struct {
+		method string
+		body   string
+	}
+
+	tests := []struct {
+		name      string
+		args      args
+		want      string
+		checkFunc func(res *http.Response, body []byte)
+	}{
+		{
+			name: "get level",
+			args: args{method: http.MethodGet},
+			want: loglevel.Info,
+			checkFunc: func(res *http.Response, body []byte) {
+				assert.Equal(t, http.StatusOK, res.StatusCode, fmt.Sprintf("status should be 200: got %d", res.StatusCode))
+				assert.Equal(t, "application/json; charset=utf-8", res.Header.Get("Content-Type"), "Content-Type should be JSON")
+				assert.JSONEq(t, `{"level":"info"}`, string(body))
+			},
+		},
+		{
+			name: "put level",
+			args: args{method: http.MethodPut, body: `{"level":"debug"}`},
+			want: loglevel.Debug,
+			checkFunc: func(res *http.Response, body []byte) {
+				assert.Equal(t, http.StatusOK, res.StatusCode, fmt.Sprintf("status should be 200: got %d", res.StatusCode))
+				assert.JSONEq(t, `{"level":"debug"}`, string(body))
+			},
+		},
+		{
+			name: "put unknown level",
+			args: args{method: http.MethodPut, body: `{"level":"verbose"}`},
+			want: loglevel.Info,
+			checkFunc: func(res *http.Response, body []byte) {
+				assert.Equal(t, http.StatusBadRequest, res.StatusCode, fmt.Sprintf("status should be 400: got %d", res.StatusCode))
+			},
+		},
+		{
+			name: "put malformed body",
+			args: args{method: http.MethodPut, body: `debug`},
+			want: loglevel.Info,
+			checkFunc: func(res *http.Response, body []byte) {
+				assert.Equal(t, http.StatusBadRequest, res.StatusCode, fmt.Sprintf("status should be 400: got %d", res.StatusCode))
+			},
+		},
+		{
+			name: "post level",
+			args: args{method: http.MethodPost, body: `{"level":"debug"}`},
+			want: loglevel.Info,
+			checkFunc: func(res *http.Response, body []byte) {
+				assert.Equal(t, http.StatusMethodNotAllowed, res.StatusCode, fmt.Sprintf("status should be 405: got %d", res.StatusCode))
+				assert.Equal(t, "GET, PUT", res.Header.Get("Allow"), fmt.Sprintf("Allow should be GET, PUT: got %s", res.Header.Get("Allow")))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger, err := loglevel.New(log.NewNopLogger(), loglevel.Info)
+			if err != nil {
+				t.Fatalf("unexpected error %s", err)
+			}
+			ts := httptest.NewServer(logger.Handler())
+			defer ts.Close()
+
+			req, _ := http.NewRequest(tt.args.method, ts.URL, strings.NewReader(tt.args.body))
+			res, err := ts.Client().Do(req)
+			if err != nil {
+				t.Fatalf("unexpected error %s", err)
+			}
+			body, _ := ioutil.ReadAll(res.Body)
+			res.Body.Close()
+
+			tt.checkFunc(res, body)
+			assert.Equal(t, tt.want, logger.Level(), fmt.Sprintf("level: expected %s got %s", tt.want, logger.Level()))
+		})
+	}
+}
@@ -0,0 +1,444 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-kit/kit/endpoint"
+	grpctransport "github.com/go-kit/kit/transport/grpc"
+	httptransport "github.com/go-kit/kit/transport/http"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+
+	"github.com/cage1016/gokit-todo/internal/pkg/authn"
+	"github.com/cage1016/gokit-todo/internal/pkg/errors"
+)
+
+// ErrLimited indicates that the caller made more requests than its limit.
+var ErrLimited = errors.New("rate limit exceeded")
+
+// DefaultMethod is the method of Limits applying to the methods without a
+// limit of their own.
+const DefaultMethod = "*"
+
+// sweepInterval is how often the buckets refilled since are dropped, so that
+// the callers seen once do not hold memory.
+const sweepInterval = time.Minute
+
+// Limit lets a caller make Burst requests at once, and Rate requests per
+// second on average.
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+// Limits are the limits of the methods, by their endpoint name.
+type Limits map[string]Limit
+
+// ParseLimits parses the comma-separated method=rate:burst limits of s, such
+// as "*=10:20,add=1:5". The rate is in requests per second.
+func ParseLimits(s string) (Limits, error) {
+	limits := Limits{}
+	for _, l := range strings.Split(s, ",") {
+		if l = strings.TrimSpace(l); l == "" {
+			continue
+		}
+		kv := strings.SplitN(l, "=", 2)
+		if len(kv) != 2 || kv[0] == "" {
+			return nil, fmt.Errorf("%q: expected method=rate:burst", l)
+		}
+		limit, err := ParseLimit(kv[1])
+		if err != nil {
+			return nil, fmt.Errorf("%q: %v", l, err)
+		}
+		limits[kv[0]] = limit
+	}
+	return limits, nil
+}
+
+// ParseLimit parses the rate:burst limit of s, such as "1:5". The rate is in
+// requests per second.
+func ParseLimit(s string) (Limit, error) {
+	rb := strings.SplitN(s, ":", 2)
+	if len(rb) != 2 {
+		return Limit{}, fmt.Errorf("expected rate:burst")
+	}
+	rate, err := strconv.ParseFloat(rb[0], 64)
+	if err != nil || rate <= 0 {
+		return Limit{}, fmt.Errorf("the rate has to be a positive number")
+	}
+	burst, err := strconv.Atoi(rb[1])
+	if err != nil || burst < 1 {
+		return Limit{}, fmt.Errorf("the burst has to be a positive integer")
+	}
+	return Limit{Rate: rate, Burst: burst}, nil
+}
+
+var _ errors.Error = (*Error)(nil)
+
+// Error is the ErrLimited error of a rejected request, telling the caller
+// when to retry.
+type Error struct {
+	// Limit is the burst of the method.
+	Limit int
+	// RetryAfter is how long until the next request is accepted.
+	RetryAfter time.Duration
+	// Reset is how long until the whole burst is available again.
+	Reset time.Duration
+}
+
+func (e *Error) Errors() []errors.Errors { return ErrLimited.Errors() }
+func (e *Error) Error() string           { return ErrLimited.Error() }
+func (e *Error) Msg() string             { return ErrLimited.Msg() }
+func (e *Error) Err() errors.Error       { return nil }
+
+// Headers implements httptransport.Headerer with the Retry-After header and
+// the RateLimit headers of the IETF draft.
+func (e *Error) Headers() http.Header {
+	return http.Header{
+		"Retry-After":         {seconds(e.RetryAfter)},
+		"Ratelimit-Limit":     {strconv.Itoa(e.Limit)},
+		"Ratelimit-Remaining": {"0"},
+		"Ratelimit-Reset":     {seconds(e.Reset)},
+	}
+}
+
+// StatusCode implements httptransport.StatusCoder.
+func (e *Error) StatusCode() int {
+	return http.StatusTooManyRequests
+}
+
+// seconds rounds d up to whole seconds, a caller retrying after them is not
+// rejected again.
+func seconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
+
+// bucket is the token bucket of a caller of a method.
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// refill adds the tokens earned since the bucket was last used at now.
+func (b *bucket) refill(limit Limit, now time.Time) {
+	b.tokens = math.Min(float64(limit.Burst), b.tokens+now.Sub(b.last).Seconds()*limit.Rate)
+	b.last = now
+}
+
+type key struct {
+	method string
+	caller string
+}
+
+// Limiter rate limits the callers of each method with token buckets.
+type Limiter struct {
+	limits  Limits
+	proxies Proxies
+	now     func() time.Time
+
+	mu        sync.Mutex
+	buckets   map[key]*bucket
+	lastSweep time.Time
+}
+
+// Option configures the Limiter returned by NewLimiter.
+type Option func(*Limiter)
+
+// WithTrustedProxies makes the Limiter tell the anonymous callers apart by
+// the address their trusted proxies forward, rather than by the address of
+// the last proxy.
+func WithTrustedProxies(proxies Proxies) Option {
+	return func(l *Limiter) {
+		l.proxies = proxies
+	}
+}
+
+// NewLimiter returns a Limiter enforcing limits, the methods without a limit
+// nor a DefaultMethod one are not limited.
+func NewLimiter(limits Limits, opts ...Option) *Limiter {
+	l := &Limiter{limits: limits, now: time.Now, buckets: map[key]*bucket{}}
+	for _, opt := range opts {
+		opt(l)
+	}
+	return l
+}
+
+// Middleware returns the middleware rate limiting the callers of method,
+// which rejects their requests with an *Error past their limit. Callers are
+// told apart by their authenticated subject, or by their address when they
+// are anonymous, so it has to be applied before the authentication
+// middleware.
+func (l *Limiter) Middleware(method string) endpoint.Middleware {
+	limit, ok := l.limits[method]
+	if !ok {
+		limit, ok = l.limits[DefaultMethod]
+	}
+	return func(next endpoint.Endpoint) endpoint.Endpoint {
+		if !ok {
+			return next
+		}
+		return func(ctx context.Context, request interface{}) (interface{}, error) {
+			if err := l.take(key{method: method, caller: l.callerOf(ctx)}, limit); err != nil {
+				return nil, err
+			}
+			return next(ctx, request)
+		}
+	}
+}
+
+// FailureMiddleware returns the middleware rate limiting the requests of
+// every address which fail with an error matched by failed, with the limit of
+// DefaultMethod. Only the failures are counted, but all the requests of an
+// address are rejected with an *Error once its failures are past the limit.
+// It is meant to be applied after the authentication middleware, so that its
+// failures are limited before the callers are told apart by their subject.
+func (l *Limiter) FailureMiddleware(failed func(error) bool) endpoint.Middleware {
+	limit, ok := l.limits[DefaultMethod]
+	return func(next endpoint.Endpoint) endpoint.Endpoint {
+		if !ok {
+			return next
+		}
+		return func(ctx context.Context, request interface{}) (interface{}, error) {
+			k := key{method: DefaultMethod, caller: l.addressOf(ctx)}
+			if err := l.check(k, limit); err != nil {
+				return nil, err
+			}
+			response, err := next(ctx, request)
+			if err != nil && failed(err) {
+				l.charge(k, limit)
+			}
+			return response, err
+		}
+	}
+}
+
+// take takes a token of the bucket of k, returning an *Error if it is empty.
+func (l *Limiter) take(k key, limit Limit) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+
+	b, ok := l.buckets[k]
+	if !ok {
+		b = &bucket{tokens: float64(limit.Burst), last: now}
+		l.buckets[k] = b
+	}
+	b.refill(limit, now)
+	if b.tokens < 1 {
+		return limitError(b, limit)
+	}
+	b.tokens--
+	return nil
+}
+
+// check returns an *Error if the bucket of k is empty, without taking a
+// token. The callers without a bucket are not limited.
+func (l *Limiter) check(k key, limit Limit) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	b, ok := l.buckets[k]
+	if !ok {
+		return nil
+	}
+	if b.refill(limit, l.now()); b.tokens < 1 {
+		return limitError(b, limit)
+	}
+	return nil
+}
+
+// charge takes a token of the bucket of k for a request which already ran.
+// The bucket goes below zero when the concurrent requests passing check fail
+// together, which delays the next request as much.
+func (l *Limiter) charge(k key, limit Limit) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+
+	b, ok := l.buckets[k]
+	if !ok {
+		b = &bucket{tokens: float64(limit.Burst), last: now}
+		l.buckets[k] = b
+	}
+	b.refill(limit, now)
+	b.tokens--
+}
+
+// limitError returns the *Error of the requests rejected by the bucket b.
+func limitError(b *bucket, limit Limit) *Error {
+	return &Error{
+		Limit:      limit.Burst,
+		RetryAfter: time.Duration((1 - b.tokens) / limit.Rate * float64(time.Second)),
+		Reset:      time.Duration((float64(limit.Burst) - b.tokens) / limit.Rate * float64(time.Second)),
+	}
+}
+
+// sweep drops the buckets which are full again at now every sweepInterval,
+// a new bucket being full.
+func (l *Limiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < sweepInterval {
+		return
+	}
+	l.lastSweep = now
+	for k, b := range l.buckets {
+		limit, ok := l.limits[k.method]
+		if !ok {
+			limit = l.limits[DefaultMethod]
+		}
+		if b.refill(limit, now); b.tokens >= float64(limit.Burst) {
+			delete(l.buckets, k)
+		}
+	}
+}
+
+// callerOf returns the caller of the request of ctx: its authenticated
+// subject, or its address.
+func (l *Limiter) callerOf(ctx context.Context) string {
+	if claims, ok := authn.FromContext(ctx); ok {
+		return "subject:" + claims.Tenant + "/" + claims.Subject
+	}
+	return l.addressOf(ctx)
+}
+
+// addressOf returns the address of the caller of the request of ctx, from
+// the client put on the context by HTTPClientToContext or
+// GRPCClientToContext.
+func (l *Limiter) addressOf(ctx context.Context) string {
+	if c, ok := ctx.Value(clientContextKey{}).(client); ok {
+		return "address:" + l.proxies.client(c)
+	}
+	return ""
+}
+
+// Proxies are the networks of the reverse proxies trusted to append the
+// address of their clients to the X-Forwarded-For header.
+type Proxies []*net.IPNet
+
+// ParseProxies parses the comma-separated addresses and CIDR networks of s,
+// such as "10.0.0.0/8,192.168.1.10".
+func ParseProxies(s string) (Proxies, error) {
+	var proxies Proxies
+	for _, p := range strings.Split(s, ",") {
+		if p = strings.TrimSpace(p); p == "" {
+			continue
+		}
+		if !strings.Contains(p, "/") {
+			ip := net.ParseIP(p)
+			if ip == nil {
+				return nil, fmt.Errorf("%q: expected an address or a CIDR network", p)
+			}
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			proxies = append(proxies, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, network, err := net.ParseCIDR(p)
+		if err != nil {
+			return nil, fmt.Errorf("%q: expected an address or a CIDR network", p)
+		}
+		proxies = append(proxies, network)
+	}
+	return proxies, nil
+}
+
+// trusts reports whether addr is the address of a trusted proxy.
+func (p Proxies) trusts(addr string) bool {
+	ip := net.ParseIP(addr)
+	if ip == nil {
+		return false
+	}
+	for _, network := range p {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// client returns the address of c: walking back from its peer through the
+// addresses forwarded, the first one which is not a trusted proxy. A peer
+// in process, such as the gateway, is trusted.
+func (p Proxies) client(c client) string {
+	addrs := c.forwarded
+	if c.peer != "" {
+		addrs = append(addrs[:len(addrs):len(addrs)], c.peer)
+	}
+	for i := len(addrs) - 1; i > 0; i-- {
+		if !p.trusts(addrs[i]) {
+			return addrs[i]
+		}
+	}
+	if len(addrs) == 0 {
+		return ""
+	}
+	return addrs[0]
+}
+
+type clientContextKey struct{}
+
+// client is the peer of a request and the addresses forwarded by its
+// proxies, the one of the nearest proxy last.
+type client struct {
+	peer      string
+	forwarded []string
+}
+
+// HTTPClientToContext puts the address of the client of a request on the
+// context, along with the addresses of its X-Forwarded-For headers which
+// the Limiter looks at behind the trusted proxies.
+func HTTPClientToContext() httptransport.RequestFunc {
+	return func(ctx context.Context, r *http.Request) context.Context {
+		return context.WithValue(ctx, clientContextKey{}, client{peer: host(r.RemoteAddr), forwarded: forwarded(r.Header.Values("X-Forwarded-For"))})
+	}
+}
+
+// GRPCClientToContext puts the address of the peer of a request on the
+// context, along with the addresses of its x-forwarded-for metadata. The
+// requests of the gateway, served in process, have no peer but the gateway
+// appends the address of their client to the x-forwarded-for metadata.
+func GRPCClientToContext() grpctransport.ServerRequestFunc {
+	return func(ctx context.Context, md metadata.MD) context.Context {
+		c := client{forwarded: forwarded(md.Get("x-forwarded-for"))}
+		if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+			c.peer = host(p.Addr.String())
+		}
+		if c.peer == "" && len(c.forwarded) == 0 {
+			return ctx
+		}
+		return context.WithValue(ctx, clientContextKey{}, c)
+	}
+}
+
+// forwarded returns the addresses of the X-Forwarded-For values, in order.
+func forwarded(values []string) (addrs []string) {
+	for _, v := range values {
+		for _, addr := range strings.Split(v, ",") {
+			if addr = strings.TrimSpace(addr); addr != "" {
+				addrs = append(addrs, host(addr))
+			}
+		}
+	}
+	return addrs
+}
+
+// host returns the host of addr, which may have no port.
+func host(addr string) string {
+	if h, _, err := net.SplitHostPort(addr); err == nil {
+		return h
+	}
+	return addr
+}
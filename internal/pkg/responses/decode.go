@@ -18,16 +18,44 @@ const (
 	contentType string = "application/json"
 )
 
+// JSONErrorDecoder decodes the ErrorRes written by ErrorEncodeJSONResponse.
+// The errors it lists are wrapped back into an errors.Error, so that
+// errors.Contains matches the errors of the server.
 func JSONErrorDecoder(r *http.Response) error {
 	contentType := r.Header.Get("Content-Type")
 	if !strings.Contains(contentType, "application/json") {
 		return fmt.Errorf("expected JSON formatted error, got Content-Type %s", contentType)
 	}
-	var w ErrorWrapper
-	if err := json.NewDecoder(r.Body).Decode(&w); err != nil {
+	var res ErrorRes
+	if err := json.NewDecoder(r.Body).Decode(&res); err != nil {
 		return err
 	}
-	return errors.New(w.Error)
+
+	errs := res.Error.Errors
+	if len(errs) == 0 {
+		if res.Error.Message == "" {
+			return errors.New(http.StatusText(r.StatusCode))
+		}
+		return errors.New(res.Error.Message)
+	}
+	err := errors.New(errs[len(errs)-1].Message)
+	for i := len(errs) - 2; i >= 0; i-- {
+		err = errors.Wrap(errors.New(errs[i].Message), err)
+	}
+	return err
+}
+
+// DecodeJSONResponse decodes the data of the DataRes written by
+// EncodeJSONResponse into data, which is left untouched by the responses
+// without content. The error responses are decoded by JSONErrorDecoder.
+func DecodeJSONResponse(r *http.Response, data interface{}) error {
+	if r.StatusCode < 200 || r.StatusCode > 299 {
+		return JSONErrorDecoder(r)
+	}
+	if r.StatusCode == http.StatusNoContent || data == nil {
+		return nil
+	}
+	return json.NewDecoder(r.Body).Decode(&DataRes{Data: data})
 }
 
 func EncodeJSONResponse(_ context.Context, w http.ResponseWriter, response interface{}) error {
@@ -65,7 +93,9 @@ func ErrorEncodeJSONResponse(f func(errorVal errors.Error) (code int)) func(_ co
 			// HTTP
 			switch errorVal := err.(type) {
 			case errors.Error:
-				code = f(errorVal)
+				if code = f(errorVal); code == 0 {
+					code = http.StatusInternalServerError
+				}
 
 				if errorVal.Msg() != "" {
 					message, errs = errorVal.Msg(), errorVal.Errors()
@@ -91,6 +121,13 @@ func ErrorEncodeJSONResponse(f func(errorVal errors.Error) (code int)) func(_ co
 			message = errs[0].Message
 		}
 
+		if headerer, ok := err.(httptransport.Headerer); ok {
+			for k, values := range headerer.Headers() {
+				for _, v := range values {
+					w.Header().Add(k, v)
+				}
+			}
+		}
 		w.WriteHeader(code)
 		json.NewEncoder(w).Encode(ErrorRes{ErrorResItem{code, message, errs}})
 	}
@@ -4,10 +4,6 @@ import (
 	"github.com/cage1016/gokit-todo/internal/pkg/errors"
 )
 
-type ErrorWrapper struct {
-	Error string `json:"error"`
-}
-
 type ErrorResItem struct {
 	Code    int             `json:"code"`
 	Message string          `json:"message"`
@@ -0,0 +1,192 @@
+package tlsconfig
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+)
+
+// Config is the PEM-encoded files of the TLS settings shared by the servers
+// and their clients.
+type Config struct {
+	// CertFile and KeyFile are the certificate presented to the peers, the
+	// one of the server or the client certificate of a client.
+	CertFile string
+	KeyFile  string
+	// CAFile verifies the certificates of the peers: the client certificates
+	// of a server, which enables mutual TLS, and the server certificates of a
+	// client instead of the system roots. It is optional.
+	CAFile string
+}
+
+// Enabled tells whether a certificate is configured, TLS is disabled
+// otherwise.
+func (c Config) Enabled() bool {
+	return c.CertFile != "" || c.KeyFile != ""
+}
+
+// stamp tells whether a file changed since it was last read.
+type stamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// Reloader holds the certificate and the CA of a Config, which are reloaded
+// when their files change so that certificates are rotated without a
+// restart.
+type Reloader struct {
+	config Config
+	logger log.Logger
+
+	mu     sync.RWMutex
+	cert   *tls.Certificate
+	pool   *x509.CertPool
+	stamps map[string]stamp
+}
+
+// NewReloader returns a Reloader of config, whose files have to be valid.
+func NewReloader(config Config, logger log.Logger) (*Reloader, error) {
+	if config.CertFile == "" || config.KeyFile == "" {
+		return nil, fmt.Errorf("both a certificate and a key are required")
+	}
+	r := &Reloader{config: config, logger: logger}
+	if _, err := r.Reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Reload reads the files again if any of them changed since they were last
+// read, and reports whether they did. The current certificate and CA are kept
+// if the files are invalid, a certificate being renewed may have its key
+// written after it.
+func (r *Reloader) Reload() (bool, error) {
+	files := []string{r.config.CertFile, r.config.KeyFile}
+	if r.config.CAFile != "" {
+		files = append(files, r.config.CAFile)
+	}
+	stamps := make(map[string]stamp, len(files))
+	for _, file := range files {
+		fi, err := os.Stat(file)
+		if err != nil {
+			return false, err
+		}
+		stamps[file] = stamp{modTime: fi.ModTime(), size: fi.Size()}
+	}
+
+	r.mu.RLock()
+	unchanged := r.cert != nil
+	for file, s := range stamps {
+		unchanged = unchanged && r.stamps[file] == s
+	}
+	r.mu.RUnlock()
+	if unchanged {
+		return false, nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.config.CertFile, r.config.KeyFile)
+	if err != nil {
+		return false, err
+	}
+	var pool *x509.CertPool
+	if r.config.CAFile != "" {
+		if pool, err = loadPool(r.config.CAFile); err != nil {
+			return false, err
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cert, r.pool, r.stamps = &cert, pool, stamps
+	return true, nil
+}
+
+// Run reloads the files every interval until ctx is cancelled.
+func (r *Reloader) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if reloaded, err := r.Reload(); err != nil {
+			level.Error(r.logger).Log("certificate", r.config.CertFile, "err", err, "msg", "keeping the current certificate")
+		} else if reloaded {
+			level.Info(r.logger).Log("certificate", r.config.CertFile, "msg", "reloaded")
+		}
+	}
+}
+
+// ServerConfig returns the config of a server negotiating nextProtos, the
+// clients have to present a certificate signed by the CA when there is one.
+// Every handshake uses the last valid files.
+func (r *Reloader) ServerConfig(nextProtos ...string) *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		NextProtos: nextProtos,
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			r.mu.RLock()
+			defer r.mu.RUnlock()
+
+			c := &tls.Config{
+				MinVersion:   tls.VersionTLS12,
+				NextProtos:   nextProtos,
+				Certificates: []tls.Certificate{*r.cert},
+			}
+			if r.pool != nil {
+				c.ClientCAs = r.pool
+				c.ClientAuth = tls.RequireAndVerifyClientCert
+			}
+			return c, nil
+		},
+	}
+}
+
+// ClientConfig returns the config of a client of serverName. The certificate
+// is optional for the clients, which present none without it.
+func ClientConfig(config Config, serverName string) (*tls.Config, error) {
+	c := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ServerName: serverName,
+	}
+	if config.Enabled() {
+		cert, err := tls.LoadX509KeyPair(config.CertFile, config.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		c.Certificates = []tls.Certificate{cert}
+	}
+	if config.CAFile != "" {
+		pool, err := loadPool(config.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		c.RootCAs = pool
+	}
+	return c, nil
+}
+
+// loadPool returns the pool of the PEM-encoded certificates of file.
+func loadPool(file string) (*x509.CertPool, error) {
+	b, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(b) {
+		return nil, fmt.Errorf("%s: no PEM-encoded certificate", file)
+	}
+	return pool, nil
+}
@@ -7,8 +7,13 @@
 #
 # Update protoc Go bindings via
 #  go get -u github.com/golang/protobuf/{proto,protoc-gen-go}
+#  go get -u github.com/grpc-ecosystem/grpc-gateway/protoc-gen-grpc-gateway
 #
 # See also
 #  https://github.com/grpc/grpc-go/tree/master/examples
+#  https://github.com/grpc-ecosystem/grpc-gateway/tree/v1
 
-protoc todo.proto --go_out=plugins=grpc:.
\ No newline at end of file
+# google/api/annotations.proto ships with the grpc-gateway module
+GOOGLEAPIS=$(go list -m -f '{{.Dir}}' github.com/grpc-ecosystem/grpc-gateway)/third_party/googleapis
+
+protoc -I . -I ${GOOGLEAPIS} todo.proto --go_out=plugins=grpc:. --grpc-gateway_out=logtostderr=true:.
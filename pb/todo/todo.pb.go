@@ -7,9 +7,11 @@ import (
 	context "context"
 	fmt "fmt"
 	proto "github.com/golang/protobuf/proto"
+	_ "google.golang.org/genproto/googleapis/api/annotations"
 	grpc "google.golang.org/grpc"
 	codes "google.golang.org/grpc/codes"
 	status "google.golang.org/grpc/status"
+	fieldmaskpb "google.golang.org/protobuf/types/known/fieldmaskpb"
 	math "math"
 )
 
@@ -77,6 +79,12 @@ type ModelTodoRes struct {
 	UpdatedAt            string   `protobuf:"bytes,3,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
 	Text                 string   `protobuf:"bytes,4,opt,name=text,proto3" json:"text,omitempty"`
 	Completed            bool     `protobuf:"varint,5,opt,name=completed,proto3" json:"completed,omitempty"`
+	CompletedAt          string   `protobuf:"bytes,6,opt,name=completed_at,json=completedAt,proto3" json:"completed_at,omitempty"`
+	ArchivedAt           string   `protobuf:"bytes,7,opt,name=archived_at,json=archivedAt,proto3" json:"archived_at,omitempty"`
+	SnoozedUntil         string   `protobuf:"bytes,8,opt,name=snoozed_until,json=snoozedUntil,proto3" json:"snoozed_until,omitempty"`
+	Version              uint64   `protobuf:"varint,9,opt,name=version,proto3" json:"version,omitempty"`
+	OwnerId              string   `protobuf:"bytes,10,opt,name=owner_id,json=ownerId,proto3" json:"owner_id,omitempty"`
+	TenantId             string   `protobuf:"bytes,11,opt,name=tenant_id,json=tenantId,proto3" json:"tenant_id,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -142,6 +150,845 @@ func (m *ModelTodoRes) GetCompleted() bool {
 	return false
 }
 
+func (m *ModelTodoRes) GetCompletedAt() string {
+	if m != nil {
+		return m.CompletedAt
+	}
+	return ""
+}
+
+func (m *ModelTodoRes) GetArchivedAt() string {
+	if m != nil {
+		return m.ArchivedAt
+	}
+	return ""
+}
+
+func (m *ModelTodoRes) GetSnoozedUntil() string {
+	if m != nil {
+		return m.SnoozedUntil
+	}
+	return ""
+}
+
+func (m *ModelTodoRes) GetVersion() uint64 {
+	if m != nil {
+		return m.Version
+	}
+	return 0
+}
+
+func (m *ModelTodoRes) GetOwnerId() string {
+	if m != nil {
+		return m.OwnerId
+	}
+	return ""
+}
+
+func (m *ModelTodoRes) GetTenantId() string {
+	if m != nil {
+		return m.TenantId
+	}
+	return ""
+}
+
+type ModelDayCount struct {
+	Day                  string   `protobuf:"bytes,1,opt,name=day,proto3" json:"day,omitempty"`
+	Count                int64    `protobuf:"varint,2,opt,name=count,proto3" json:"count,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ModelDayCount) Reset()         { *m = ModelDayCount{} }
+func (m *ModelDayCount) String() string { return proto.CompactTextString(m) }
+func (*ModelDayCount) ProtoMessage()    {}
+func (*ModelDayCount) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0e4b95d0c4e09639, []int{2}
+}
+
+func (m *ModelDayCount) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ModelDayCount.Unmarshal(m, b)
+}
+func (m *ModelDayCount) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ModelDayCount.Marshal(b, m, deterministic)
+}
+func (m *ModelDayCount) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ModelDayCount.Merge(m, src)
+}
+func (m *ModelDayCount) XXX_Size() int {
+	return xxx_messageInfo_ModelDayCount.Size(m)
+}
+func (m *ModelDayCount) XXX_DiscardUnknown() {
+	xxx_messageInfo_ModelDayCount.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ModelDayCount proto.InternalMessageInfo
+
+func (m *ModelDayCount) GetDay() string {
+	if m != nil {
+		return m.Day
+	}
+	return ""
+}
+
+func (m *ModelDayCount) GetCount() int64 {
+	if m != nil {
+		return m.Count
+	}
+	return 0
+}
+
+type ModelTodoStats struct {
+	Total                int64            `protobuf:"varint,1,opt,name=total,proto3" json:"total,omitempty"`
+	Active               int64            `protobuf:"varint,2,opt,name=active,proto3" json:"active,omitempty"`
+	Completed            int64            `protobuf:"varint,3,opt,name=completed,proto3" json:"completed,omitempty"`
+	CompletedPerDay      []*ModelDayCount `protobuf:"bytes,4,rep,name=completed_per_day,json=completedPerDay,proto3" json:"completed_per_day,omitempty"`
+	AvgTimeToComplete    float64          `protobuf:"fixed64,5,opt,name=avg_time_to_complete,json=avgTimeToComplete,proto3" json:"avg_time_to_complete,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}         `json:"-"`
+	XXX_unrecognized     []byte           `json:"-"`
+	XXX_sizecache        int32            `json:"-"`
+}
+
+func (m *ModelTodoStats) Reset()         { *m = ModelTodoStats{} }
+func (m *ModelTodoStats) String() string { return proto.CompactTextString(m) }
+func (*ModelTodoStats) ProtoMessage()    {}
+func (*ModelTodoStats) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0e4b95d0c4e09639, []int{3}
+}
+
+func (m *ModelTodoStats) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ModelTodoStats.Unmarshal(m, b)
+}
+func (m *ModelTodoStats) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ModelTodoStats.Marshal(b, m, deterministic)
+}
+func (m *ModelTodoStats) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ModelTodoStats.Merge(m, src)
+}
+func (m *ModelTodoStats) XXX_Size() int {
+	return xxx_messageInfo_ModelTodoStats.Size(m)
+}
+func (m *ModelTodoStats) XXX_DiscardUnknown() {
+	xxx_messageInfo_ModelTodoStats.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ModelTodoStats proto.InternalMessageInfo
+
+func (m *ModelTodoStats) GetTotal() int64 {
+	if m != nil {
+		return m.Total
+	}
+	return 0
+}
+
+func (m *ModelTodoStats) GetActive() int64 {
+	if m != nil {
+		return m.Active
+	}
+	return 0
+}
+
+func (m *ModelTodoStats) GetCompleted() int64 {
+	if m != nil {
+		return m.Completed
+	}
+	return 0
+}
+
+func (m *ModelTodoStats) GetCompletedPerDay() []*ModelDayCount {
+	if m != nil {
+		return m.CompletedPerDay
+	}
+	return nil
+}
+
+func (m *ModelTodoStats) GetAvgTimeToComplete() float64 {
+	if m != nil {
+		return m.AvgTimeToComplete
+	}
+	return 0
+}
+
+type ModelQuota struct {
+	Todos                int64    `protobuf:"varint,1,opt,name=todos,proto3" json:"todos,omitempty"`
+	TenantTodos          int64    `protobuf:"varint,2,opt,name=tenant_todos,json=tenantTodos,proto3" json:"tenant_todos,omitempty"`
+	TextLength           int32    `protobuf:"varint,3,opt,name=text_length,json=textLength,proto3" json:"text_length,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ModelQuota) Reset()         { *m = ModelQuota{} }
+func (m *ModelQuota) String() string { return proto.CompactTextString(m) }
+func (*ModelQuota) ProtoMessage()    {}
+func (*ModelQuota) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0e4b95d0c4e09639, []int{4}
+}
+
+func (m *ModelQuota) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ModelQuota.Unmarshal(m, b)
+}
+func (m *ModelQuota) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ModelQuota.Marshal(b, m, deterministic)
+}
+func (m *ModelQuota) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ModelQuota.Merge(m, src)
+}
+func (m *ModelQuota) XXX_Size() int {
+	return xxx_messageInfo_ModelQuota.Size(m)
+}
+func (m *ModelQuota) XXX_DiscardUnknown() {
+	xxx_messageInfo_ModelQuota.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ModelQuota proto.InternalMessageInfo
+
+func (m *ModelQuota) GetTodos() int64 {
+	if m != nil {
+		return m.Todos
+	}
+	return 0
+}
+
+func (m *ModelQuota) GetTenantTodos() int64 {
+	if m != nil {
+		return m.TenantTodos
+	}
+	return 0
+}
+
+func (m *ModelQuota) GetTextLength() int32 {
+	if m != nil {
+		return m.TextLength
+	}
+	return 0
+}
+
+type ModelUsage struct {
+	Todos                int64       `protobuf:"varint,1,opt,name=todos,proto3" json:"todos,omitempty"`
+	TenantTodos          int64       `protobuf:"varint,2,opt,name=tenant_todos,json=tenantTodos,proto3" json:"tenant_todos,omitempty"`
+	Quota                *ModelQuota `protobuf:"bytes,3,opt,name=quota,proto3" json:"quota,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}    `json:"-"`
+	XXX_unrecognized     []byte      `json:"-"`
+	XXX_sizecache        int32       `json:"-"`
+}
+
+func (m *ModelUsage) Reset()         { *m = ModelUsage{} }
+func (m *ModelUsage) String() string { return proto.CompactTextString(m) }
+func (*ModelUsage) ProtoMessage()    {}
+func (*ModelUsage) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0e4b95d0c4e09639, []int{5}
+}
+
+func (m *ModelUsage) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ModelUsage.Unmarshal(m, b)
+}
+func (m *ModelUsage) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ModelUsage.Marshal(b, m, deterministic)
+}
+func (m *ModelUsage) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ModelUsage.Merge(m, src)
+}
+func (m *ModelUsage) XXX_Size() int {
+	return xxx_messageInfo_ModelUsage.Size(m)
+}
+func (m *ModelUsage) XXX_DiscardUnknown() {
+	xxx_messageInfo_ModelUsage.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ModelUsage proto.InternalMessageInfo
+
+func (m *ModelUsage) GetTodos() int64 {
+	if m != nil {
+		return m.Todos
+	}
+	return 0
+}
+
+func (m *ModelUsage) GetTenantTodos() int64 {
+	if m != nil {
+		return m.TenantTodos
+	}
+	return 0
+}
+
+func (m *ModelUsage) GetQuota() *ModelQuota {
+	if m != nil {
+		return m.Quota
+	}
+	return nil
+}
+
+type ModelTombstone struct {
+	Id                   string   `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Version              uint64   `protobuf:"varint,2,opt,name=version,proto3" json:"version,omitempty"`
+	DeletedAt            string   `protobuf:"bytes,3,opt,name=deleted_at,json=deletedAt,proto3" json:"deleted_at,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ModelTombstone) Reset()         { *m = ModelTombstone{} }
+func (m *ModelTombstone) String() string { return proto.CompactTextString(m) }
+func (*ModelTombstone) ProtoMessage()    {}
+func (*ModelTombstone) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0e4b95d0c4e09639, []int{6}
+}
+
+func (m *ModelTombstone) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ModelTombstone.Unmarshal(m, b)
+}
+func (m *ModelTombstone) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ModelTombstone.Marshal(b, m, deterministic)
+}
+func (m *ModelTombstone) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ModelTombstone.Merge(m, src)
+}
+func (m *ModelTombstone) XXX_Size() int {
+	return xxx_messageInfo_ModelTombstone.Size(m)
+}
+func (m *ModelTombstone) XXX_DiscardUnknown() {
+	xxx_messageInfo_ModelTombstone.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ModelTombstone proto.InternalMessageInfo
+
+func (m *ModelTombstone) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *ModelTombstone) GetVersion() uint64 {
+	if m != nil {
+		return m.Version
+	}
+	return 0
+}
+
+func (m *ModelTombstone) GetDeletedAt() string {
+	if m != nil {
+		return m.DeletedAt
+	}
+	return ""
+}
+
+type ModelChanges struct {
+	Todos                []*ModelTodoRes   `protobuf:"bytes,1,rep,name=todos,proto3" json:"todos,omitempty"`
+	Deleted              []*ModelTombstone `protobuf:"bytes,2,rep,name=deleted,proto3" json:"deleted,omitempty"`
+	Token                string            `protobuf:"bytes,3,opt,name=token,proto3" json:"token,omitempty"`
+	More                 bool              `protobuf:"varint,4,opt,name=more,proto3" json:"more,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
+	XXX_unrecognized     []byte            `json:"-"`
+	XXX_sizecache        int32             `json:"-"`
+}
+
+func (m *ModelChanges) Reset()         { *m = ModelChanges{} }
+func (m *ModelChanges) String() string { return proto.CompactTextString(m) }
+func (*ModelChanges) ProtoMessage()    {}
+func (*ModelChanges) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0e4b95d0c4e09639, []int{7}
+}
+
+func (m *ModelChanges) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ModelChanges.Unmarshal(m, b)
+}
+func (m *ModelChanges) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ModelChanges.Marshal(b, m, deterministic)
+}
+func (m *ModelChanges) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ModelChanges.Merge(m, src)
+}
+func (m *ModelChanges) XXX_Size() int {
+	return xxx_messageInfo_ModelChanges.Size(m)
+}
+func (m *ModelChanges) XXX_DiscardUnknown() {
+	xxx_messageInfo_ModelChanges.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ModelChanges proto.InternalMessageInfo
+
+func (m *ModelChanges) GetTodos() []*ModelTodoRes {
+	if m != nil {
+		return m.Todos
+	}
+	return nil
+}
+
+func (m *ModelChanges) GetDeleted() []*ModelTombstone {
+	if m != nil {
+		return m.Deleted
+	}
+	return nil
+}
+
+func (m *ModelChanges) GetToken() string {
+	if m != nil {
+		return m.Token
+	}
+	return ""
+}
+
+func (m *ModelChanges) GetMore() bool {
+	if m != nil {
+		return m.More
+	}
+	return false
+}
+
+type ModelShareReq struct {
+	TodoId               string   `protobuf:"bytes,1,opt,name=todo_id,json=todoId,proto3" json:"todo_id,omitempty"`
+	GranteeId            string   `protobuf:"bytes,2,opt,name=grantee_id,json=granteeId,proto3" json:"grantee_id,omitempty"`
+	Role                 string   `protobuf:"bytes,3,opt,name=role,proto3" json:"role,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ModelShareReq) Reset()         { *m = ModelShareReq{} }
+func (m *ModelShareReq) String() string { return proto.CompactTextString(m) }
+func (*ModelShareReq) ProtoMessage()    {}
+func (*ModelShareReq) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0e4b95d0c4e09639, []int{8}
+}
+
+func (m *ModelShareReq) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ModelShareReq.Unmarshal(m, b)
+}
+func (m *ModelShareReq) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ModelShareReq.Marshal(b, m, deterministic)
+}
+func (m *ModelShareReq) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ModelShareReq.Merge(m, src)
+}
+func (m *ModelShareReq) XXX_Size() int {
+	return xxx_messageInfo_ModelShareReq.Size(m)
+}
+func (m *ModelShareReq) XXX_DiscardUnknown() {
+	xxx_messageInfo_ModelShareReq.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ModelShareReq proto.InternalMessageInfo
+
+func (m *ModelShareReq) GetTodoId() string {
+	if m != nil {
+		return m.TodoId
+	}
+	return ""
+}
+
+func (m *ModelShareReq) GetGranteeId() string {
+	if m != nil {
+		return m.GranteeId
+	}
+	return ""
+}
+
+func (m *ModelShareReq) GetRole() string {
+	if m != nil {
+		return m.Role
+	}
+	return ""
+}
+
+type ModelShare struct {
+	Id                   string   `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	OwnerId              string   `protobuf:"bytes,2,opt,name=owner_id,json=ownerId,proto3" json:"owner_id,omitempty"`
+	TenantId             string   `protobuf:"bytes,3,opt,name=tenant_id,json=tenantId,proto3" json:"tenant_id,omitempty"`
+	TodoId               string   `protobuf:"bytes,4,opt,name=todo_id,json=todoId,proto3" json:"todo_id,omitempty"`
+	GranteeId            string   `protobuf:"bytes,5,opt,name=grantee_id,json=granteeId,proto3" json:"grantee_id,omitempty"`
+	Role                 string   `protobuf:"bytes,6,opt,name=role,proto3" json:"role,omitempty"`
+	CreatedAt            string   `protobuf:"bytes,7,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ModelShare) Reset()         { *m = ModelShare{} }
+func (m *ModelShare) String() string { return proto.CompactTextString(m) }
+func (*ModelShare) ProtoMessage()    {}
+func (*ModelShare) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0e4b95d0c4e09639, []int{9}
+}
+
+func (m *ModelShare) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ModelShare.Unmarshal(m, b)
+}
+func (m *ModelShare) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ModelShare.Marshal(b, m, deterministic)
+}
+func (m *ModelShare) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ModelShare.Merge(m, src)
+}
+func (m *ModelShare) XXX_Size() int {
+	return xxx_messageInfo_ModelShare.Size(m)
+}
+func (m *ModelShare) XXX_DiscardUnknown() {
+	xxx_messageInfo_ModelShare.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ModelShare proto.InternalMessageInfo
+
+func (m *ModelShare) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *ModelShare) GetOwnerId() string {
+	if m != nil {
+		return m.OwnerId
+	}
+	return ""
+}
+
+func (m *ModelShare) GetTenantId() string {
+	if m != nil {
+		return m.TenantId
+	}
+	return ""
+}
+
+func (m *ModelShare) GetTodoId() string {
+	if m != nil {
+		return m.TodoId
+	}
+	return ""
+}
+
+func (m *ModelShare) GetGranteeId() string {
+	if m != nil {
+		return m.GranteeId
+	}
+	return ""
+}
+
+func (m *ModelShare) GetRole() string {
+	if m != nil {
+		return m.Role
+	}
+	return ""
+}
+
+func (m *ModelShare) GetCreatedAt() string {
+	if m != nil {
+		return m.CreatedAt
+	}
+	return ""
+}
+
+type ModelAPIKeyReq struct {
+	Name                 string   `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Subject              string   `protobuf:"bytes,2,opt,name=subject,proto3" json:"subject,omitempty"`
+	Scopes               []string `protobuf:"bytes,3,rep,name=scopes,proto3" json:"scopes,omitempty"`
+	ExpiresAt            string   `protobuf:"bytes,4,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ModelAPIKeyReq) Reset()         { *m = ModelAPIKeyReq{} }
+func (m *ModelAPIKeyReq) String() string { return proto.CompactTextString(m) }
+func (*ModelAPIKeyReq) ProtoMessage()    {}
+func (*ModelAPIKeyReq) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0e4b95d0c4e09639, []int{10}
+}
+
+func (m *ModelAPIKeyReq) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ModelAPIKeyReq.Unmarshal(m, b)
+}
+func (m *ModelAPIKeyReq) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ModelAPIKeyReq.Marshal(b, m, deterministic)
+}
+func (m *ModelAPIKeyReq) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ModelAPIKeyReq.Merge(m, src)
+}
+func (m *ModelAPIKeyReq) XXX_Size() int {
+	return xxx_messageInfo_ModelAPIKeyReq.Size(m)
+}
+func (m *ModelAPIKeyReq) XXX_DiscardUnknown() {
+	xxx_messageInfo_ModelAPIKeyReq.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ModelAPIKeyReq proto.InternalMessageInfo
+
+func (m *ModelAPIKeyReq) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *ModelAPIKeyReq) GetSubject() string {
+	if m != nil {
+		return m.Subject
+	}
+	return ""
+}
+
+func (m *ModelAPIKeyReq) GetScopes() []string {
+	if m != nil {
+		return m.Scopes
+	}
+	return nil
+}
+
+func (m *ModelAPIKeyReq) GetExpiresAt() string {
+	if m != nil {
+		return m.ExpiresAt
+	}
+	return ""
+}
+
+type ModelAPIKey struct {
+	Id         string   `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	OwnerId    string   `protobuf:"bytes,2,opt,name=owner_id,json=ownerId,proto3" json:"owner_id,omitempty"`
+	TenantId   string   `protobuf:"bytes,3,opt,name=tenant_id,json=tenantId,proto3" json:"tenant_id,omitempty"`
+	Name       string   `protobuf:"bytes,4,opt,name=name,proto3" json:"name,omitempty"`
+	Subject    string   `protobuf:"bytes,5,opt,name=subject,proto3" json:"subject,omitempty"`
+	Scopes     []string `protobuf:"bytes,6,rep,name=scopes,proto3" json:"scopes,omitempty"`
+	ExpiresAt  string   `protobuf:"bytes,7,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	LastUsedAt string   `protobuf:"bytes,8,opt,name=last_used_at,json=lastUsedAt,proto3" json:"last_used_at,omitempty"`
+	CreatedAt  string   `protobuf:"bytes,9,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	// key is only set on creation
+	Key                  string   `protobuf:"bytes,10,opt,name=key,proto3" json:"key,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ModelAPIKey) Reset()         { *m = ModelAPIKey{} }
+func (m *ModelAPIKey) String() string { return proto.CompactTextString(m) }
+func (*ModelAPIKey) ProtoMessage()    {}
+func (*ModelAPIKey) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0e4b95d0c4e09639, []int{11}
+}
+
+func (m *ModelAPIKey) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ModelAPIKey.Unmarshal(m, b)
+}
+func (m *ModelAPIKey) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ModelAPIKey.Marshal(b, m, deterministic)
+}
+func (m *ModelAPIKey) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ModelAPIKey.Merge(m, src)
+}
+func (m *ModelAPIKey) XXX_Size() int {
+	return xxx_messageInfo_ModelAPIKey.Size(m)
+}
+func (m *ModelAPIKey) XXX_DiscardUnknown() {
+	xxx_messageInfo_ModelAPIKey.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ModelAPIKey proto.InternalMessageInfo
+
+func (m *ModelAPIKey) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *ModelAPIKey) GetOwnerId() string {
+	if m != nil {
+		return m.OwnerId
+	}
+	return ""
+}
+
+func (m *ModelAPIKey) GetTenantId() string {
+	if m != nil {
+		return m.TenantId
+	}
+	return ""
+}
+
+func (m *ModelAPIKey) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *ModelAPIKey) GetSubject() string {
+	if m != nil {
+		return m.Subject
+	}
+	return ""
+}
+
+func (m *ModelAPIKey) GetScopes() []string {
+	if m != nil {
+		return m.Scopes
+	}
+	return nil
+}
+
+func (m *ModelAPIKey) GetExpiresAt() string {
+	if m != nil {
+		return m.ExpiresAt
+	}
+	return ""
+}
+
+func (m *ModelAPIKey) GetLastUsedAt() string {
+	if m != nil {
+		return m.LastUsedAt
+	}
+	return ""
+}
+
+func (m *ModelAPIKey) GetCreatedAt() string {
+	if m != nil {
+		return m.CreatedAt
+	}
+	return ""
+}
+
+func (m *ModelAPIKey) GetKey() string {
+	if m != nil {
+		return m.Key
+	}
+	return ""
+}
+
+type ModelMutation struct {
+	Op                   string        `protobuf:"bytes,1,opt,name=op,proto3" json:"op,omitempty"`
+	Id                   string        `protobuf:"bytes,2,opt,name=id,proto3" json:"id,omitempty"`
+	Version              uint64        `protobuf:"varint,3,opt,name=version,proto3" json:"version,omitempty"`
+	UpdatedAt            string        `protobuf:"bytes,4,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	Todo                 *ModelTodoReq `protobuf:"bytes,5,opt,name=todo,proto3" json:"todo,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}      `json:"-"`
+	XXX_unrecognized     []byte        `json:"-"`
+	XXX_sizecache        int32         `json:"-"`
+}
+
+func (m *ModelMutation) Reset()         { *m = ModelMutation{} }
+func (m *ModelMutation) String() string { return proto.CompactTextString(m) }
+func (*ModelMutation) ProtoMessage()    {}
+func (*ModelMutation) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0e4b95d0c4e09639, []int{12}
+}
+
+func (m *ModelMutation) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ModelMutation.Unmarshal(m, b)
+}
+func (m *ModelMutation) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ModelMutation.Marshal(b, m, deterministic)
+}
+func (m *ModelMutation) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ModelMutation.Merge(m, src)
+}
+func (m *ModelMutation) XXX_Size() int {
+	return xxx_messageInfo_ModelMutation.Size(m)
+}
+func (m *ModelMutation) XXX_DiscardUnknown() {
+	xxx_messageInfo_ModelMutation.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ModelMutation proto.InternalMessageInfo
+
+func (m *ModelMutation) GetOp() string {
+	if m != nil {
+		return m.Op
+	}
+	return ""
+}
+
+func (m *ModelMutation) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *ModelMutation) GetVersion() uint64 {
+	if m != nil {
+		return m.Version
+	}
+	return 0
+}
+
+func (m *ModelMutation) GetUpdatedAt() string {
+	if m != nil {
+		return m.UpdatedAt
+	}
+	return ""
+}
+
+func (m *ModelMutation) GetTodo() *ModelTodoReq {
+	if m != nil {
+		return m.Todo
+	}
+	return nil
+}
+
+type ModelMutationResult struct {
+	Id                   string        `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Status               string        `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"`
+	Todo                 *ModelTodoRes `protobuf:"bytes,3,opt,name=todo,proto3" json:"todo,omitempty"`
+	Error                string        `protobuf:"bytes,4,opt,name=error,proto3" json:"error,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}      `json:"-"`
+	XXX_unrecognized     []byte        `json:"-"`
+	XXX_sizecache        int32         `json:"-"`
+}
+
+func (m *ModelMutationResult) Reset()         { *m = ModelMutationResult{} }
+func (m *ModelMutationResult) String() string { return proto.CompactTextString(m) }
+func (*ModelMutationResult) ProtoMessage()    {}
+func (*ModelMutationResult) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0e4b95d0c4e09639, []int{13}
+}
+
+func (m *ModelMutationResult) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ModelMutationResult.Unmarshal(m, b)
+}
+func (m *ModelMutationResult) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ModelMutationResult.Marshal(b, m, deterministic)
+}
+func (m *ModelMutationResult) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ModelMutationResult.Merge(m, src)
+}
+func (m *ModelMutationResult) XXX_Size() int {
+	return xxx_messageInfo_ModelMutationResult.Size(m)
+}
+func (m *ModelMutationResult) XXX_DiscardUnknown() {
+	xxx_messageInfo_ModelMutationResult.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ModelMutationResult proto.InternalMessageInfo
+
+func (m *ModelMutationResult) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *ModelMutationResult) GetStatus() string {
+	if m != nil {
+		return m.Status
+	}
+	return ""
+}
+
+func (m *ModelMutationResult) GetTodo() *ModelTodoRes {
+	if m != nil {
+		return m.Todo
+	}
+	return nil
+}
+
+func (m *ModelMutationResult) GetError() string {
+	if m != nil {
+		return m.Error
+	}
+	return ""
+}
+
 type AddRequest struct {
 	Todo                 *ModelTodoReq `protobuf:"bytes,1,opt,name=todo,proto3" json:"todo,omitempty"`
 	XXX_NoUnkeyedLiteral struct{}      `json:"-"`
@@ -149,329 +996,1614 @@ type AddRequest struct {
 	XXX_sizecache        int32         `json:"-"`
 }
 
-func (m *AddRequest) Reset()         { *m = AddRequest{} }
-func (m *AddRequest) String() string { return proto.CompactTextString(m) }
-func (*AddRequest) ProtoMessage()    {}
-func (*AddRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_0e4b95d0c4e09639, []int{2}
+func (m *AddRequest) Reset()         { *m = AddRequest{} }
+func (m *AddRequest) String() string { return proto.CompactTextString(m) }
+func (*AddRequest) ProtoMessage()    {}
+func (*AddRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0e4b95d0c4e09639, []int{14}
+}
+
+func (m *AddRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_AddRequest.Unmarshal(m, b)
+}
+func (m *AddRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_AddRequest.Marshal(b, m, deterministic)
+}
+func (m *AddRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_AddRequest.Merge(m, src)
+}
+func (m *AddRequest) XXX_Size() int {
+	return xxx_messageInfo_AddRequest.Size(m)
+}
+func (m *AddRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_AddRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_AddRequest proto.InternalMessageInfo
+
+func (m *AddRequest) GetTodo() *ModelTodoReq {
+	if m != nil {
+		return m.Todo
+	}
+	return nil
+}
+
+type AddResponse struct {
+	Res                  *ModelTodoRes `protobuf:"bytes,1,opt,name=res,proto3" json:"res,omitempty"`
+	Err                  string        `protobuf:"bytes,2,opt,name=err,proto3" json:"err,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}      `json:"-"`
+	XXX_unrecognized     []byte        `json:"-"`
+	XXX_sizecache        int32         `json:"-"`
+}
+
+func (m *AddResponse) Reset()         { *m = AddResponse{} }
+func (m *AddResponse) String() string { return proto.CompactTextString(m) }
+func (*AddResponse) ProtoMessage()    {}
+func (*AddResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0e4b95d0c4e09639, []int{15}
+}
+
+func (m *AddResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_AddResponse.Unmarshal(m, b)
+}
+func (m *AddResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_AddResponse.Marshal(b, m, deterministic)
+}
+func (m *AddResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_AddResponse.Merge(m, src)
+}
+func (m *AddResponse) XXX_Size() int {
+	return xxx_messageInfo_AddResponse.Size(m)
+}
+func (m *AddResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_AddResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_AddResponse proto.InternalMessageInfo
+
+func (m *AddResponse) GetRes() *ModelTodoRes {
+	if m != nil {
+		return m.Res
+	}
+	return nil
+}
+
+func (m *AddResponse) GetErr() string {
+	if m != nil {
+		return m.Err
+	}
+	return ""
+}
+
+type DeleteRequest struct {
+	Id                   string   `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *DeleteRequest) Reset()         { *m = DeleteRequest{} }
+func (m *DeleteRequest) String() string { return proto.CompactTextString(m) }
+func (*DeleteRequest) ProtoMessage()    {}
+func (*DeleteRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0e4b95d0c4e09639, []int{16}
+}
+
+func (m *DeleteRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_DeleteRequest.Unmarshal(m, b)
+}
+func (m *DeleteRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_DeleteRequest.Marshal(b, m, deterministic)
+}
+func (m *DeleteRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DeleteRequest.Merge(m, src)
+}
+func (m *DeleteRequest) XXX_Size() int {
+	return xxx_messageInfo_DeleteRequest.Size(m)
+}
+func (m *DeleteRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_DeleteRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DeleteRequest proto.InternalMessageInfo
+
+func (m *DeleteRequest) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+type DeleteResponse struct {
+	Err                  string   `protobuf:"bytes,1,opt,name=err,proto3" json:"err,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *DeleteResponse) Reset()         { *m = DeleteResponse{} }
+func (m *DeleteResponse) String() string { return proto.CompactTextString(m) }
+func (*DeleteResponse) ProtoMessage()    {}
+func (*DeleteResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0e4b95d0c4e09639, []int{17}
+}
+
+func (m *DeleteResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_DeleteResponse.Unmarshal(m, b)
+}
+func (m *DeleteResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_DeleteResponse.Marshal(b, m, deterministic)
+}
+func (m *DeleteResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DeleteResponse.Merge(m, src)
+}
+func (m *DeleteResponse) XXX_Size() int {
+	return xxx_messageInfo_DeleteResponse.Size(m)
+}
+func (m *DeleteResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_DeleteResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DeleteResponse proto.InternalMessageInfo
+
+func (m *DeleteResponse) GetErr() string {
+	if m != nil {
+		return m.Err
+	}
+	return ""
+}
+
+type UpdateRequest struct {
+	Id                   string        `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Todo                 *ModelTodoReq `protobuf:"bytes,2,opt,name=todo,proto3" json:"todo,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}      `json:"-"`
+	XXX_unrecognized     []byte        `json:"-"`
+	XXX_sizecache        int32         `json:"-"`
+}
+
+func (m *UpdateRequest) Reset()         { *m = UpdateRequest{} }
+func (m *UpdateRequest) String() string { return proto.CompactTextString(m) }
+func (*UpdateRequest) ProtoMessage()    {}
+func (*UpdateRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0e4b95d0c4e09639, []int{18}
+}
+
+func (m *UpdateRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_UpdateRequest.Unmarshal(m, b)
+}
+func (m *UpdateRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_UpdateRequest.Marshal(b, m, deterministic)
+}
+func (m *UpdateRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_UpdateRequest.Merge(m, src)
+}
+func (m *UpdateRequest) XXX_Size() int {
+	return xxx_messageInfo_UpdateRequest.Size(m)
+}
+func (m *UpdateRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_UpdateRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_UpdateRequest proto.InternalMessageInfo
+
+func (m *UpdateRequest) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *UpdateRequest) GetTodo() *ModelTodoReq {
+	if m != nil {
+		return m.Todo
+	}
+	return nil
+}
+
+type UpdateResponse struct {
+	Res                  *ModelTodoRes `protobuf:"bytes,1,opt,name=res,proto3" json:"res,omitempty"`
+	Err                  string        `protobuf:"bytes,2,opt,name=err,proto3" json:"err,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}      `json:"-"`
+	XXX_unrecognized     []byte        `json:"-"`
+	XXX_sizecache        int32         `json:"-"`
+}
+
+func (m *UpdateResponse) Reset()         { *m = UpdateResponse{} }
+func (m *UpdateResponse) String() string { return proto.CompactTextString(m) }
+func (*UpdateResponse) ProtoMessage()    {}
+func (*UpdateResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0e4b95d0c4e09639, []int{19}
+}
+
+func (m *UpdateResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_UpdateResponse.Unmarshal(m, b)
+}
+func (m *UpdateResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_UpdateResponse.Marshal(b, m, deterministic)
+}
+func (m *UpdateResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_UpdateResponse.Merge(m, src)
+}
+func (m *UpdateResponse) XXX_Size() int {
+	return xxx_messageInfo_UpdateResponse.Size(m)
+}
+func (m *UpdateResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_UpdateResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_UpdateResponse proto.InternalMessageInfo
+
+func (m *UpdateResponse) GetRes() *ModelTodoRes {
+	if m != nil {
+		return m.Res
+	}
+	return nil
+}
+
+func (m *UpdateResponse) GetErr() string {
+	if m != nil {
+		return m.Err
+	}
+	return ""
+}
+
+type ListRequest struct {
+	FieldMask            *fieldmaskpb.FieldMask `protobuf:"bytes,1,opt,name=field_mask,json=fieldMask,proto3" json:"field_mask,omitempty"`
+	CompletedAfter       string                 `protobuf:"bytes,2,opt,name=completed_after,json=completedAfter,proto3" json:"completed_after,omitempty"`
+	CompletedBefore      string                 `protobuf:"bytes,3,opt,name=completed_before,json=completedBefore,proto3" json:"completed_before,omitempty"`
+	Sort                 string                 `protobuf:"bytes,4,opt,name=sort,proto3" json:"sort,omitempty"`
+	IncludeArchived      bool                   `protobuf:"varint,5,opt,name=include_archived,json=includeArchived,proto3" json:"include_archived,omitempty"`
+	Status               string                 `protobuf:"bytes,6,opt,name=status,proto3" json:"status,omitempty"`
+	Offset               int32                  `protobuf:"varint,7,opt,name=offset,proto3" json:"offset,omitempty"`
+	Limit                int32                  `protobuf:"varint,8,opt,name=limit,proto3" json:"limit,omitempty"`
+	Shared               bool                   `protobuf:"varint,9,opt,name=shared,proto3" json:"shared,omitempty"`
+	IncludeSnoozed       bool                   `protobuf:"varint,10,opt,name=include_snoozed,json=includeSnoozed,proto3" json:"include_snoozed,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}               `json:"-"`
+	XXX_unrecognized     []byte                 `json:"-"`
+	XXX_sizecache        int32                  `json:"-"`
+}
+
+func (m *ListRequest) Reset()         { *m = ListRequest{} }
+func (m *ListRequest) String() string { return proto.CompactTextString(m) }
+func (*ListRequest) ProtoMessage()    {}
+func (*ListRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0e4b95d0c4e09639, []int{20}
+}
+
+func (m *ListRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListRequest.Unmarshal(m, b)
+}
+func (m *ListRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListRequest.Marshal(b, m, deterministic)
+}
+func (m *ListRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListRequest.Merge(m, src)
+}
+func (m *ListRequest) XXX_Size() int {
+	return xxx_messageInfo_ListRequest.Size(m)
+}
+func (m *ListRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListRequest proto.InternalMessageInfo
+
+func (m *ListRequest) GetFieldMask() *fieldmaskpb.FieldMask {
+	if m != nil {
+		return m.FieldMask
+	}
+	return nil
+}
+
+func (m *ListRequest) GetCompletedAfter() string {
+	if m != nil {
+		return m.CompletedAfter
+	}
+	return ""
+}
+
+func (m *ListRequest) GetCompletedBefore() string {
+	if m != nil {
+		return m.CompletedBefore
+	}
+	return ""
+}
+
+func (m *ListRequest) GetSort() string {
+	if m != nil {
+		return m.Sort
+	}
+	return ""
+}
+
+func (m *ListRequest) GetIncludeArchived() bool {
+	if m != nil {
+		return m.IncludeArchived
+	}
+	return false
+}
+
+func (m *ListRequest) GetStatus() string {
+	if m != nil {
+		return m.Status
+	}
+	return ""
+}
+
+func (m *ListRequest) GetOffset() int32 {
+	if m != nil {
+		return m.Offset
+	}
+	return 0
+}
+
+func (m *ListRequest) GetLimit() int32 {
+	if m != nil {
+		return m.Limit
+	}
+	return 0
+}
+
+func (m *ListRequest) GetShared() bool {
+	if m != nil {
+		return m.Shared
+	}
+	return false
+}
+
+func (m *ListRequest) GetIncludeSnoozed() bool {
+	if m != nil {
+		return m.IncludeSnoozed
+	}
+	return false
+}
+
+type ListResponse struct {
+	Res                  []*ModelTodoRes `protobuf:"bytes,1,rep,name=res,proto3" json:"res,omitempty"`
+	Err                  string          `protobuf:"bytes,2,opt,name=err,proto3" json:"err,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}        `json:"-"`
+	XXX_unrecognized     []byte          `json:"-"`
+	XXX_sizecache        int32           `json:"-"`
+}
+
+func (m *ListResponse) Reset()         { *m = ListResponse{} }
+func (m *ListResponse) String() string { return proto.CompactTextString(m) }
+func (*ListResponse) ProtoMessage()    {}
+func (*ListResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0e4b95d0c4e09639, []int{21}
+}
+
+func (m *ListResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListResponse.Unmarshal(m, b)
+}
+func (m *ListResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListResponse.Marshal(b, m, deterministic)
+}
+func (m *ListResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListResponse.Merge(m, src)
+}
+func (m *ListResponse) XXX_Size() int {
+	return xxx_messageInfo_ListResponse.Size(m)
+}
+func (m *ListResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListResponse proto.InternalMessageInfo
+
+func (m *ListResponse) GetRes() []*ModelTodoRes {
+	if m != nil {
+		return m.Res
+	}
+	return nil
+}
+
+func (m *ListResponse) GetErr() string {
+	if m != nil {
+		return m.Err
+	}
+	return ""
+}
+
+type GetRequest struct {
+	Id                   string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	FieldMask            *fieldmaskpb.FieldMask `protobuf:"bytes,2,opt,name=field_mask,json=fieldMask,proto3" json:"field_mask,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}               `json:"-"`
+	XXX_unrecognized     []byte                 `json:"-"`
+	XXX_sizecache        int32                  `json:"-"`
+}
+
+func (m *GetRequest) Reset()         { *m = GetRequest{} }
+func (m *GetRequest) String() string { return proto.CompactTextString(m) }
+func (*GetRequest) ProtoMessage()    {}
+func (*GetRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0e4b95d0c4e09639, []int{22}
+}
+
+func (m *GetRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetRequest.Unmarshal(m, b)
+}
+func (m *GetRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetRequest.Marshal(b, m, deterministic)
+}
+func (m *GetRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetRequest.Merge(m, src)
+}
+func (m *GetRequest) XXX_Size() int {
+	return xxx_messageInfo_GetRequest.Size(m)
+}
+func (m *GetRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetRequest proto.InternalMessageInfo
+
+func (m *GetRequest) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *GetRequest) GetFieldMask() *fieldmaskpb.FieldMask {
+	if m != nil {
+		return m.FieldMask
+	}
+	return nil
+}
+
+type GetResponse struct {
+	Res                  *ModelTodoRes `protobuf:"bytes,1,opt,name=res,proto3" json:"res,omitempty"`
+	Err                  string        `protobuf:"bytes,2,opt,name=err,proto3" json:"err,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}      `json:"-"`
+	XXX_unrecognized     []byte        `json:"-"`
+	XXX_sizecache        int32         `json:"-"`
+}
+
+func (m *GetResponse) Reset()         { *m = GetResponse{} }
+func (m *GetResponse) String() string { return proto.CompactTextString(m) }
+func (*GetResponse) ProtoMessage()    {}
+func (*GetResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0e4b95d0c4e09639, []int{23}
+}
+
+func (m *GetResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetResponse.Unmarshal(m, b)
+}
+func (m *GetResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetResponse.Marshal(b, m, deterministic)
+}
+func (m *GetResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetResponse.Merge(m, src)
+}
+func (m *GetResponse) XXX_Size() int {
+	return xxx_messageInfo_GetResponse.Size(m)
+}
+func (m *GetResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetResponse proto.InternalMessageInfo
+
+func (m *GetResponse) GetRes() *ModelTodoRes {
+	if m != nil {
+		return m.Res
+	}
+	return nil
+}
+
+func (m *GetResponse) GetErr() string {
+	if m != nil {
+		return m.Err
+	}
+	return ""
+}
+
+type StatsRequest struct {
+	Days                 int32    `protobuf:"varint,1,opt,name=days,proto3" json:"days,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *StatsRequest) Reset()         { *m = StatsRequest{} }
+func (m *StatsRequest) String() string { return proto.CompactTextString(m) }
+func (*StatsRequest) ProtoMessage()    {}
+func (*StatsRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0e4b95d0c4e09639, []int{24}
+}
+
+func (m *StatsRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_StatsRequest.Unmarshal(m, b)
+}
+func (m *StatsRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_StatsRequest.Marshal(b, m, deterministic)
+}
+func (m *StatsRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_StatsRequest.Merge(m, src)
+}
+func (m *StatsRequest) XXX_Size() int {
+	return xxx_messageInfo_StatsRequest.Size(m)
+}
+func (m *StatsRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_StatsRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_StatsRequest proto.InternalMessageInfo
+
+func (m *StatsRequest) GetDays() int32 {
+	if m != nil {
+		return m.Days
+	}
+	return 0
+}
+
+type StatsResponse struct {
+	Res                  *ModelTodoStats `protobuf:"bytes,1,opt,name=res,proto3" json:"res,omitempty"`
+	Err                  string          `protobuf:"bytes,2,opt,name=err,proto3" json:"err,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}        `json:"-"`
+	XXX_unrecognized     []byte          `json:"-"`
+	XXX_sizecache        int32           `json:"-"`
+}
+
+func (m *StatsResponse) Reset()         { *m = StatsResponse{} }
+func (m *StatsResponse) String() string { return proto.CompactTextString(m) }
+func (*StatsResponse) ProtoMessage()    {}
+func (*StatsResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0e4b95d0c4e09639, []int{25}
+}
+
+func (m *StatsResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_StatsResponse.Unmarshal(m, b)
+}
+func (m *StatsResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_StatsResponse.Marshal(b, m, deterministic)
+}
+func (m *StatsResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_StatsResponse.Merge(m, src)
+}
+func (m *StatsResponse) XXX_Size() int {
+	return xxx_messageInfo_StatsResponse.Size(m)
+}
+func (m *StatsResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_StatsResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_StatsResponse proto.InternalMessageInfo
+
+func (m *StatsResponse) GetRes() *ModelTodoStats {
+	if m != nil {
+		return m.Res
+	}
+	return nil
+}
+
+func (m *StatsResponse) GetErr() string {
+	if m != nil {
+		return m.Err
+	}
+	return ""
+}
+
+type UnarchiveRequest struct {
+	Id                   string   `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *UnarchiveRequest) Reset()         { *m = UnarchiveRequest{} }
+func (m *UnarchiveRequest) String() string { return proto.CompactTextString(m) }
+func (*UnarchiveRequest) ProtoMessage()    {}
+func (*UnarchiveRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0e4b95d0c4e09639, []int{26}
+}
+
+func (m *UnarchiveRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_UnarchiveRequest.Unmarshal(m, b)
+}
+func (m *UnarchiveRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_UnarchiveRequest.Marshal(b, m, deterministic)
+}
+func (m *UnarchiveRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_UnarchiveRequest.Merge(m, src)
+}
+func (m *UnarchiveRequest) XXX_Size() int {
+	return xxx_messageInfo_UnarchiveRequest.Size(m)
+}
+func (m *UnarchiveRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_UnarchiveRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_UnarchiveRequest proto.InternalMessageInfo
+
+func (m *UnarchiveRequest) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+type UnarchiveResponse struct {
+	Res                  *ModelTodoRes `protobuf:"bytes,1,opt,name=res,proto3" json:"res,omitempty"`
+	Err                  string        `protobuf:"bytes,2,opt,name=err,proto3" json:"err,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}      `json:"-"`
+	XXX_unrecognized     []byte        `json:"-"`
+	XXX_sizecache        int32         `json:"-"`
+}
+
+func (m *UnarchiveResponse) Reset()         { *m = UnarchiveResponse{} }
+func (m *UnarchiveResponse) String() string { return proto.CompactTextString(m) }
+func (*UnarchiveResponse) ProtoMessage()    {}
+func (*UnarchiveResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0e4b95d0c4e09639, []int{27}
+}
+
+func (m *UnarchiveResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_UnarchiveResponse.Unmarshal(m, b)
+}
+func (m *UnarchiveResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_UnarchiveResponse.Marshal(b, m, deterministic)
+}
+func (m *UnarchiveResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_UnarchiveResponse.Merge(m, src)
+}
+func (m *UnarchiveResponse) XXX_Size() int {
+	return xxx_messageInfo_UnarchiveResponse.Size(m)
+}
+func (m *UnarchiveResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_UnarchiveResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_UnarchiveResponse proto.InternalMessageInfo
+
+func (m *UnarchiveResponse) GetRes() *ModelTodoRes {
+	if m != nil {
+		return m.Res
+	}
+	return nil
+}
+
+func (m *UnarchiveResponse) GetErr() string {
+	if m != nil {
+		return m.Err
+	}
+	return ""
+}
+
+type SnoozeRequest struct {
+	Id string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	// wakes the todo up when empty
+	Until                string   `protobuf:"bytes,2,opt,name=until,proto3" json:"until,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *SnoozeRequest) Reset()         { *m = SnoozeRequest{} }
+func (m *SnoozeRequest) String() string { return proto.CompactTextString(m) }
+func (*SnoozeRequest) ProtoMessage()    {}
+func (*SnoozeRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0e4b95d0c4e09639, []int{28}
+}
+
+func (m *SnoozeRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SnoozeRequest.Unmarshal(m, b)
+}
+func (m *SnoozeRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SnoozeRequest.Marshal(b, m, deterministic)
+}
+func (m *SnoozeRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SnoozeRequest.Merge(m, src)
+}
+func (m *SnoozeRequest) XXX_Size() int {
+	return xxx_messageInfo_SnoozeRequest.Size(m)
+}
+func (m *SnoozeRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_SnoozeRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SnoozeRequest proto.InternalMessageInfo
+
+func (m *SnoozeRequest) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *SnoozeRequest) GetUntil() string {
+	if m != nil {
+		return m.Until
+	}
+	return ""
+}
+
+type SnoozeResponse struct {
+	Res                  *ModelTodoRes `protobuf:"bytes,1,opt,name=res,proto3" json:"res,omitempty"`
+	Err                  string        `protobuf:"bytes,2,opt,name=err,proto3" json:"err,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}      `json:"-"`
+	XXX_unrecognized     []byte        `json:"-"`
+	XXX_sizecache        int32         `json:"-"`
+}
+
+func (m *SnoozeResponse) Reset()         { *m = SnoozeResponse{} }
+func (m *SnoozeResponse) String() string { return proto.CompactTextString(m) }
+func (*SnoozeResponse) ProtoMessage()    {}
+func (*SnoozeResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0e4b95d0c4e09639, []int{29}
+}
+
+func (m *SnoozeResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SnoozeResponse.Unmarshal(m, b)
+}
+func (m *SnoozeResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SnoozeResponse.Marshal(b, m, deterministic)
+}
+func (m *SnoozeResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SnoozeResponse.Merge(m, src)
+}
+func (m *SnoozeResponse) XXX_Size() int {
+	return xxx_messageInfo_SnoozeResponse.Size(m)
+}
+func (m *SnoozeResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_SnoozeResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SnoozeResponse proto.InternalMessageInfo
+
+func (m *SnoozeResponse) GetRes() *ModelTodoRes {
+	if m != nil {
+		return m.Res
+	}
+	return nil
+}
+
+func (m *SnoozeResponse) GetErr() string {
+	if m != nil {
+		return m.Err
+	}
+	return ""
+}
+
+type WatchRequest struct {
+	Types                []string `protobuf:"bytes,1,rep,name=types,proto3" json:"types,omitempty"`
+	Since                uint64   `protobuf:"varint,2,opt,name=since,proto3" json:"since,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *WatchRequest) Reset()         { *m = WatchRequest{} }
+func (m *WatchRequest) String() string { return proto.CompactTextString(m) }
+func (*WatchRequest) ProtoMessage()    {}
+func (*WatchRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0e4b95d0c4e09639, []int{30}
+}
+
+func (m *WatchRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_WatchRequest.Unmarshal(m, b)
+}
+func (m *WatchRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_WatchRequest.Marshal(b, m, deterministic)
+}
+func (m *WatchRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_WatchRequest.Merge(m, src)
+}
+func (m *WatchRequest) XXX_Size() int {
+	return xxx_messageInfo_WatchRequest.Size(m)
+}
+func (m *WatchRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_WatchRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_WatchRequest proto.InternalMessageInfo
+
+func (m *WatchRequest) GetTypes() []string {
+	if m != nil {
+		return m.Types
+	}
+	return nil
+}
+
+func (m *WatchRequest) GetSince() uint64 {
+	if m != nil {
+		return m.Since
+	}
+	return 0
+}
+
+type TodoEvent struct {
+	Seq                  uint64        `protobuf:"varint,1,opt,name=seq,proto3" json:"seq,omitempty"`
+	Type                 string        `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"`
+	Todo                 *ModelTodoRes `protobuf:"bytes,3,opt,name=todo,proto3" json:"todo,omitempty"`
+	Time                 string        `protobuf:"bytes,4,opt,name=time,proto3" json:"time,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}      `json:"-"`
+	XXX_unrecognized     []byte        `json:"-"`
+	XXX_sizecache        int32         `json:"-"`
+}
+
+func (m *TodoEvent) Reset()         { *m = TodoEvent{} }
+func (m *TodoEvent) String() string { return proto.CompactTextString(m) }
+func (*TodoEvent) ProtoMessage()    {}
+func (*TodoEvent) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0e4b95d0c4e09639, []int{31}
 }
 
-func (m *AddRequest) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_AddRequest.Unmarshal(m, b)
+func (m *TodoEvent) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_TodoEvent.Unmarshal(m, b)
 }
-func (m *AddRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_AddRequest.Marshal(b, m, deterministic)
+func (m *TodoEvent) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_TodoEvent.Marshal(b, m, deterministic)
 }
-func (m *AddRequest) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_AddRequest.Merge(m, src)
+func (m *TodoEvent) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TodoEvent.Merge(m, src)
 }
-func (m *AddRequest) XXX_Size() int {
-	return xxx_messageInfo_AddRequest.Size(m)
+func (m *TodoEvent) XXX_Size() int {
+	return xxx_messageInfo_TodoEvent.Size(m)
 }
-func (m *AddRequest) XXX_DiscardUnknown() {
-	xxx_messageInfo_AddRequest.DiscardUnknown(m)
+func (m *TodoEvent) XXX_DiscardUnknown() {
+	xxx_messageInfo_TodoEvent.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_AddRequest proto.InternalMessageInfo
+var xxx_messageInfo_TodoEvent proto.InternalMessageInfo
 
-func (m *AddRequest) GetTodo() *ModelTodoReq {
+func (m *TodoEvent) GetSeq() uint64 {
+	if m != nil {
+		return m.Seq
+	}
+	return 0
+}
+
+func (m *TodoEvent) GetType() string {
+	if m != nil {
+		return m.Type
+	}
+	return ""
+}
+
+func (m *TodoEvent) GetTodo() *ModelTodoRes {
 	if m != nil {
 		return m.Todo
 	}
 	return nil
 }
 
-type AddResponse struct {
-	Res                  *ModelTodoRes `protobuf:"bytes,1,opt,name=res,proto3" json:"res,omitempty"`
+func (m *TodoEvent) GetTime() string {
+	if m != nil {
+		return m.Time
+	}
+	return ""
+}
+
+type SyncRequest struct {
+	Since                string   `protobuf:"bytes,1,opt,name=since,proto3" json:"since,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *SyncRequest) Reset()         { *m = SyncRequest{} }
+func (m *SyncRequest) String() string { return proto.CompactTextString(m) }
+func (*SyncRequest) ProtoMessage()    {}
+func (*SyncRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0e4b95d0c4e09639, []int{32}
+}
+
+func (m *SyncRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SyncRequest.Unmarshal(m, b)
+}
+func (m *SyncRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SyncRequest.Marshal(b, m, deterministic)
+}
+func (m *SyncRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SyncRequest.Merge(m, src)
+}
+func (m *SyncRequest) XXX_Size() int {
+	return xxx_messageInfo_SyncRequest.Size(m)
+}
+func (m *SyncRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_SyncRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SyncRequest proto.InternalMessageInfo
+
+func (m *SyncRequest) GetSince() string {
+	if m != nil {
+		return m.Since
+	}
+	return ""
+}
+
+type SyncResponse struct {
+	Res                  *ModelChanges `protobuf:"bytes,1,opt,name=res,proto3" json:"res,omitempty"`
+	Err                  string        `protobuf:"bytes,2,opt,name=err,proto3" json:"err,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}      `json:"-"`
+	XXX_unrecognized     []byte        `json:"-"`
+	XXX_sizecache        int32         `json:"-"`
+}
+
+func (m *SyncResponse) Reset()         { *m = SyncResponse{} }
+func (m *SyncResponse) String() string { return proto.CompactTextString(m) }
+func (*SyncResponse) ProtoMessage()    {}
+func (*SyncResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0e4b95d0c4e09639, []int{33}
+}
+
+func (m *SyncResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SyncResponse.Unmarshal(m, b)
+}
+func (m *SyncResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SyncResponse.Marshal(b, m, deterministic)
+}
+func (m *SyncResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SyncResponse.Merge(m, src)
+}
+func (m *SyncResponse) XXX_Size() int {
+	return xxx_messageInfo_SyncResponse.Size(m)
+}
+func (m *SyncResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_SyncResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SyncResponse proto.InternalMessageInfo
+
+func (m *SyncResponse) GetRes() *ModelChanges {
+	if m != nil {
+		return m.Res
+	}
+	return nil
+}
+
+func (m *SyncResponse) GetErr() string {
+	if m != nil {
+		return m.Err
+	}
+	return ""
+}
+
+type PushRequest struct {
+	Mutations            []*ModelMutation `protobuf:"bytes,1,rep,name=mutations,proto3" json:"mutations,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}         `json:"-"`
+	XXX_unrecognized     []byte           `json:"-"`
+	XXX_sizecache        int32            `json:"-"`
+}
+
+func (m *PushRequest) Reset()         { *m = PushRequest{} }
+func (m *PushRequest) String() string { return proto.CompactTextString(m) }
+func (*PushRequest) ProtoMessage()    {}
+func (*PushRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0e4b95d0c4e09639, []int{34}
+}
+
+func (m *PushRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PushRequest.Unmarshal(m, b)
+}
+func (m *PushRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PushRequest.Marshal(b, m, deterministic)
+}
+func (m *PushRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PushRequest.Merge(m, src)
+}
+func (m *PushRequest) XXX_Size() int {
+	return xxx_messageInfo_PushRequest.Size(m)
+}
+func (m *PushRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_PushRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PushRequest proto.InternalMessageInfo
+
+func (m *PushRequest) GetMutations() []*ModelMutation {
+	if m != nil {
+		return m.Mutations
+	}
+	return nil
+}
+
+type PushResponse struct {
+	Res                  []*ModelMutationResult `protobuf:"bytes,1,rep,name=res,proto3" json:"res,omitempty"`
+	Err                  string                 `protobuf:"bytes,2,opt,name=err,proto3" json:"err,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}               `json:"-"`
+	XXX_unrecognized     []byte                 `json:"-"`
+	XXX_sizecache        int32                  `json:"-"`
+}
+
+func (m *PushResponse) Reset()         { *m = PushResponse{} }
+func (m *PushResponse) String() string { return proto.CompactTextString(m) }
+func (*PushResponse) ProtoMessage()    {}
+func (*PushResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0e4b95d0c4e09639, []int{35}
+}
+
+func (m *PushResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PushResponse.Unmarshal(m, b)
+}
+func (m *PushResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PushResponse.Marshal(b, m, deterministic)
+}
+func (m *PushResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PushResponse.Merge(m, src)
+}
+func (m *PushResponse) XXX_Size() int {
+	return xxx_messageInfo_PushResponse.Size(m)
+}
+func (m *PushResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_PushResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PushResponse proto.InternalMessageInfo
+
+func (m *PushResponse) GetRes() []*ModelMutationResult {
+	if m != nil {
+		return m.Res
+	}
+	return nil
+}
+
+func (m *PushResponse) GetErr() string {
+	if m != nil {
+		return m.Err
+	}
+	return ""
+}
+
+type AddShareRequest struct {
+	Share                *ModelShareReq `protobuf:"bytes,1,opt,name=share,proto3" json:"share,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}       `json:"-"`
+	XXX_unrecognized     []byte         `json:"-"`
+	XXX_sizecache        int32          `json:"-"`
+}
+
+func (m *AddShareRequest) Reset()         { *m = AddShareRequest{} }
+func (m *AddShareRequest) String() string { return proto.CompactTextString(m) }
+func (*AddShareRequest) ProtoMessage()    {}
+func (*AddShareRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0e4b95d0c4e09639, []int{36}
+}
+
+func (m *AddShareRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_AddShareRequest.Unmarshal(m, b)
+}
+func (m *AddShareRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_AddShareRequest.Marshal(b, m, deterministic)
+}
+func (m *AddShareRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_AddShareRequest.Merge(m, src)
+}
+func (m *AddShareRequest) XXX_Size() int {
+	return xxx_messageInfo_AddShareRequest.Size(m)
+}
+func (m *AddShareRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_AddShareRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_AddShareRequest proto.InternalMessageInfo
+
+func (m *AddShareRequest) GetShare() *ModelShareReq {
+	if m != nil {
+		return m.Share
+	}
+	return nil
+}
+
+type AddShareResponse struct {
+	Res                  *ModelShare `protobuf:"bytes,1,opt,name=res,proto3" json:"res,omitempty"`
+	Err                  string      `protobuf:"bytes,2,opt,name=err,proto3" json:"err,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}    `json:"-"`
+	XXX_unrecognized     []byte      `json:"-"`
+	XXX_sizecache        int32       `json:"-"`
+}
+
+func (m *AddShareResponse) Reset()         { *m = AddShareResponse{} }
+func (m *AddShareResponse) String() string { return proto.CompactTextString(m) }
+func (*AddShareResponse) ProtoMessage()    {}
+func (*AddShareResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0e4b95d0c4e09639, []int{37}
+}
+
+func (m *AddShareResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_AddShareResponse.Unmarshal(m, b)
+}
+func (m *AddShareResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_AddShareResponse.Marshal(b, m, deterministic)
+}
+func (m *AddShareResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_AddShareResponse.Merge(m, src)
+}
+func (m *AddShareResponse) XXX_Size() int {
+	return xxx_messageInfo_AddShareResponse.Size(m)
+}
+func (m *AddShareResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_AddShareResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_AddShareResponse proto.InternalMessageInfo
+
+func (m *AddShareResponse) GetRes() *ModelShare {
+	if m != nil {
+		return m.Res
+	}
+	return nil
+}
+
+func (m *AddShareResponse) GetErr() string {
+	if m != nil {
+		return m.Err
+	}
+	return ""
+}
+
+type DeleteShareRequest struct {
+	Id                   string   `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *DeleteShareRequest) Reset()         { *m = DeleteShareRequest{} }
+func (m *DeleteShareRequest) String() string { return proto.CompactTextString(m) }
+func (*DeleteShareRequest) ProtoMessage()    {}
+func (*DeleteShareRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0e4b95d0c4e09639, []int{38}
+}
+
+func (m *DeleteShareRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_DeleteShareRequest.Unmarshal(m, b)
+}
+func (m *DeleteShareRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_DeleteShareRequest.Marshal(b, m, deterministic)
+}
+func (m *DeleteShareRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DeleteShareRequest.Merge(m, src)
+}
+func (m *DeleteShareRequest) XXX_Size() int {
+	return xxx_messageInfo_DeleteShareRequest.Size(m)
+}
+func (m *DeleteShareRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_DeleteShareRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DeleteShareRequest proto.InternalMessageInfo
+
+func (m *DeleteShareRequest) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+type DeleteShareResponse struct {
+	Err                  string   `protobuf:"bytes,1,opt,name=err,proto3" json:"err,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *DeleteShareResponse) Reset()         { *m = DeleteShareResponse{} }
+func (m *DeleteShareResponse) String() string { return proto.CompactTextString(m) }
+func (*DeleteShareResponse) ProtoMessage()    {}
+func (*DeleteShareResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0e4b95d0c4e09639, []int{39}
+}
+
+func (m *DeleteShareResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_DeleteShareResponse.Unmarshal(m, b)
+}
+func (m *DeleteShareResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_DeleteShareResponse.Marshal(b, m, deterministic)
+}
+func (m *DeleteShareResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DeleteShareResponse.Merge(m, src)
+}
+func (m *DeleteShareResponse) XXX_Size() int {
+	return xxx_messageInfo_DeleteShareResponse.Size(m)
+}
+func (m *DeleteShareResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_DeleteShareResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DeleteShareResponse proto.InternalMessageInfo
+
+func (m *DeleteShareResponse) GetErr() string {
+	if m != nil {
+		return m.Err
+	}
+	return ""
+}
+
+type ListSharesRequest struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ListSharesRequest) Reset()         { *m = ListSharesRequest{} }
+func (m *ListSharesRequest) String() string { return proto.CompactTextString(m) }
+func (*ListSharesRequest) ProtoMessage()    {}
+func (*ListSharesRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0e4b95d0c4e09639, []int{40}
+}
+
+func (m *ListSharesRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListSharesRequest.Unmarshal(m, b)
+}
+func (m *ListSharesRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListSharesRequest.Marshal(b, m, deterministic)
+}
+func (m *ListSharesRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListSharesRequest.Merge(m, src)
+}
+func (m *ListSharesRequest) XXX_Size() int {
+	return xxx_messageInfo_ListSharesRequest.Size(m)
+}
+func (m *ListSharesRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListSharesRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListSharesRequest proto.InternalMessageInfo
+
+type ListSharesResponse struct {
+	Res                  []*ModelShare `protobuf:"bytes,1,rep,name=res,proto3" json:"res,omitempty"`
 	Err                  string        `protobuf:"bytes,2,opt,name=err,proto3" json:"err,omitempty"`
 	XXX_NoUnkeyedLiteral struct{}      `json:"-"`
 	XXX_unrecognized     []byte        `json:"-"`
 	XXX_sizecache        int32         `json:"-"`
 }
 
-func (m *AddResponse) Reset()         { *m = AddResponse{} }
-func (m *AddResponse) String() string { return proto.CompactTextString(m) }
-func (*AddResponse) ProtoMessage()    {}
-func (*AddResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_0e4b95d0c4e09639, []int{3}
+func (m *ListSharesResponse) Reset()         { *m = ListSharesResponse{} }
+func (m *ListSharesResponse) String() string { return proto.CompactTextString(m) }
+func (*ListSharesResponse) ProtoMessage()    {}
+func (*ListSharesResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0e4b95d0c4e09639, []int{41}
+}
+
+func (m *ListSharesResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListSharesResponse.Unmarshal(m, b)
+}
+func (m *ListSharesResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListSharesResponse.Marshal(b, m, deterministic)
+}
+func (m *ListSharesResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListSharesResponse.Merge(m, src)
+}
+func (m *ListSharesResponse) XXX_Size() int {
+	return xxx_messageInfo_ListSharesResponse.Size(m)
+}
+func (m *ListSharesResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListSharesResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListSharesResponse proto.InternalMessageInfo
+
+func (m *ListSharesResponse) GetRes() []*ModelShare {
+	if m != nil {
+		return m.Res
+	}
+	return nil
+}
+
+func (m *ListSharesResponse) GetErr() string {
+	if m != nil {
+		return m.Err
+	}
+	return ""
+}
+
+type CreateAPIKeyRequest struct {
+	Key                  *ModelAPIKeyReq `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}        `json:"-"`
+	XXX_unrecognized     []byte          `json:"-"`
+	XXX_sizecache        int32           `json:"-"`
+}
+
+func (m *CreateAPIKeyRequest) Reset()         { *m = CreateAPIKeyRequest{} }
+func (m *CreateAPIKeyRequest) String() string { return proto.CompactTextString(m) }
+func (*CreateAPIKeyRequest) ProtoMessage()    {}
+func (*CreateAPIKeyRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0e4b95d0c4e09639, []int{42}
+}
+
+func (m *CreateAPIKeyRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CreateAPIKeyRequest.Unmarshal(m, b)
+}
+func (m *CreateAPIKeyRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CreateAPIKeyRequest.Marshal(b, m, deterministic)
+}
+func (m *CreateAPIKeyRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CreateAPIKeyRequest.Merge(m, src)
+}
+func (m *CreateAPIKeyRequest) XXX_Size() int {
+	return xxx_messageInfo_CreateAPIKeyRequest.Size(m)
+}
+func (m *CreateAPIKeyRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_CreateAPIKeyRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CreateAPIKeyRequest proto.InternalMessageInfo
+
+func (m *CreateAPIKeyRequest) GetKey() *ModelAPIKeyReq {
+	if m != nil {
+		return m.Key
+	}
+	return nil
+}
+
+type CreateAPIKeyResponse struct {
+	Res                  *ModelAPIKey `protobuf:"bytes,1,opt,name=res,proto3" json:"res,omitempty"`
+	Err                  string       `protobuf:"bytes,2,opt,name=err,proto3" json:"err,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}     `json:"-"`
+	XXX_unrecognized     []byte       `json:"-"`
+	XXX_sizecache        int32        `json:"-"`
 }
 
-func (m *AddResponse) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_AddResponse.Unmarshal(m, b)
+func (m *CreateAPIKeyResponse) Reset()         { *m = CreateAPIKeyResponse{} }
+func (m *CreateAPIKeyResponse) String() string { return proto.CompactTextString(m) }
+func (*CreateAPIKeyResponse) ProtoMessage()    {}
+func (*CreateAPIKeyResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0e4b95d0c4e09639, []int{43}
 }
-func (m *AddResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_AddResponse.Marshal(b, m, deterministic)
+
+func (m *CreateAPIKeyResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CreateAPIKeyResponse.Unmarshal(m, b)
 }
-func (m *AddResponse) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_AddResponse.Merge(m, src)
+func (m *CreateAPIKeyResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CreateAPIKeyResponse.Marshal(b, m, deterministic)
 }
-func (m *AddResponse) XXX_Size() int {
-	return xxx_messageInfo_AddResponse.Size(m)
+func (m *CreateAPIKeyResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CreateAPIKeyResponse.Merge(m, src)
 }
-func (m *AddResponse) XXX_DiscardUnknown() {
-	xxx_messageInfo_AddResponse.DiscardUnknown(m)
+func (m *CreateAPIKeyResponse) XXX_Size() int {
+	return xxx_messageInfo_CreateAPIKeyResponse.Size(m)
+}
+func (m *CreateAPIKeyResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_CreateAPIKeyResponse.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_AddResponse proto.InternalMessageInfo
+var xxx_messageInfo_CreateAPIKeyResponse proto.InternalMessageInfo
 
-func (m *AddResponse) GetRes() *ModelTodoRes {
+func (m *CreateAPIKeyResponse) GetRes() *ModelAPIKey {
 	if m != nil {
 		return m.Res
 	}
 	return nil
 }
 
-func (m *AddResponse) GetErr() string {
+func (m *CreateAPIKeyResponse) GetErr() string {
 	if m != nil {
 		return m.Err
 	}
 	return ""
 }
 
-type DeleteRequest struct {
+type RevokeAPIKeyRequest struct {
 	Id                   string   `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
 }
 
-func (m *DeleteRequest) Reset()         { *m = DeleteRequest{} }
-func (m *DeleteRequest) String() string { return proto.CompactTextString(m) }
-func (*DeleteRequest) ProtoMessage()    {}
-func (*DeleteRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_0e4b95d0c4e09639, []int{4}
+func (m *RevokeAPIKeyRequest) Reset()         { *m = RevokeAPIKeyRequest{} }
+func (m *RevokeAPIKeyRequest) String() string { return proto.CompactTextString(m) }
+func (*RevokeAPIKeyRequest) ProtoMessage()    {}
+func (*RevokeAPIKeyRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0e4b95d0c4e09639, []int{44}
 }
 
-func (m *DeleteRequest) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_DeleteRequest.Unmarshal(m, b)
+func (m *RevokeAPIKeyRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RevokeAPIKeyRequest.Unmarshal(m, b)
 }
-func (m *DeleteRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_DeleteRequest.Marshal(b, m, deterministic)
+func (m *RevokeAPIKeyRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RevokeAPIKeyRequest.Marshal(b, m, deterministic)
 }
-func (m *DeleteRequest) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_DeleteRequest.Merge(m, src)
+func (m *RevokeAPIKeyRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RevokeAPIKeyRequest.Merge(m, src)
 }
-func (m *DeleteRequest) XXX_Size() int {
-	return xxx_messageInfo_DeleteRequest.Size(m)
+func (m *RevokeAPIKeyRequest) XXX_Size() int {
+	return xxx_messageInfo_RevokeAPIKeyRequest.Size(m)
 }
-func (m *DeleteRequest) XXX_DiscardUnknown() {
-	xxx_messageInfo_DeleteRequest.DiscardUnknown(m)
+func (m *RevokeAPIKeyRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_RevokeAPIKeyRequest.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_DeleteRequest proto.InternalMessageInfo
+var xxx_messageInfo_RevokeAPIKeyRequest proto.InternalMessageInfo
 
-func (m *DeleteRequest) GetId() string {
+func (m *RevokeAPIKeyRequest) GetId() string {
 	if m != nil {
 		return m.Id
 	}
 	return ""
 }
 
-type DeleteResponse struct {
+type RevokeAPIKeyResponse struct {
 	Err                  string   `protobuf:"bytes,1,opt,name=err,proto3" json:"err,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
 }
 
-func (m *DeleteResponse) Reset()         { *m = DeleteResponse{} }
-func (m *DeleteResponse) String() string { return proto.CompactTextString(m) }
-func (*DeleteResponse) ProtoMessage()    {}
-func (*DeleteResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_0e4b95d0c4e09639, []int{5}
+func (m *RevokeAPIKeyResponse) Reset()         { *m = RevokeAPIKeyResponse{} }
+func (m *RevokeAPIKeyResponse) String() string { return proto.CompactTextString(m) }
+func (*RevokeAPIKeyResponse) ProtoMessage()    {}
+func (*RevokeAPIKeyResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0e4b95d0c4e09639, []int{45}
 }
 
-func (m *DeleteResponse) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_DeleteResponse.Unmarshal(m, b)
+func (m *RevokeAPIKeyResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RevokeAPIKeyResponse.Unmarshal(m, b)
 }
-func (m *DeleteResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_DeleteResponse.Marshal(b, m, deterministic)
+func (m *RevokeAPIKeyResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RevokeAPIKeyResponse.Marshal(b, m, deterministic)
 }
-func (m *DeleteResponse) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_DeleteResponse.Merge(m, src)
+func (m *RevokeAPIKeyResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RevokeAPIKeyResponse.Merge(m, src)
 }
-func (m *DeleteResponse) XXX_Size() int {
-	return xxx_messageInfo_DeleteResponse.Size(m)
+func (m *RevokeAPIKeyResponse) XXX_Size() int {
+	return xxx_messageInfo_RevokeAPIKeyResponse.Size(m)
 }
-func (m *DeleteResponse) XXX_DiscardUnknown() {
-	xxx_messageInfo_DeleteResponse.DiscardUnknown(m)
+func (m *RevokeAPIKeyResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_RevokeAPIKeyResponse.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_DeleteResponse proto.InternalMessageInfo
+var xxx_messageInfo_RevokeAPIKeyResponse proto.InternalMessageInfo
 
-func (m *DeleteResponse) GetErr() string {
+func (m *RevokeAPIKeyResponse) GetErr() string {
 	if m != nil {
 		return m.Err
 	}
 	return ""
 }
 
-type UpdateRequest struct {
-	Id                   string        `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
-	Todo                 *ModelTodoReq `protobuf:"bytes,2,opt,name=todo,proto3" json:"todo,omitempty"`
-	XXX_NoUnkeyedLiteral struct{}      `json:"-"`
-	XXX_unrecognized     []byte        `json:"-"`
-	XXX_sizecache        int32         `json:"-"`
+type ListAPIKeysRequest struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
-func (m *UpdateRequest) Reset()         { *m = UpdateRequest{} }
-func (m *UpdateRequest) String() string { return proto.CompactTextString(m) }
-func (*UpdateRequest) ProtoMessage()    {}
-func (*UpdateRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_0e4b95d0c4e09639, []int{6}
+func (m *ListAPIKeysRequest) Reset()         { *m = ListAPIKeysRequest{} }
+func (m *ListAPIKeysRequest) String() string { return proto.CompactTextString(m) }
+func (*ListAPIKeysRequest) ProtoMessage()    {}
+func (*ListAPIKeysRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0e4b95d0c4e09639, []int{46}
 }
 
-func (m *UpdateRequest) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_UpdateRequest.Unmarshal(m, b)
-}
-func (m *UpdateRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_UpdateRequest.Marshal(b, m, deterministic)
+func (m *ListAPIKeysRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListAPIKeysRequest.Unmarshal(m, b)
 }
-func (m *UpdateRequest) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_UpdateRequest.Merge(m, src)
+func (m *ListAPIKeysRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListAPIKeysRequest.Marshal(b, m, deterministic)
 }
-func (m *UpdateRequest) XXX_Size() int {
-	return xxx_messageInfo_UpdateRequest.Size(m)
+func (m *ListAPIKeysRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListAPIKeysRequest.Merge(m, src)
 }
-func (m *UpdateRequest) XXX_DiscardUnknown() {
-	xxx_messageInfo_UpdateRequest.DiscardUnknown(m)
+func (m *ListAPIKeysRequest) XXX_Size() int {
+	return xxx_messageInfo_ListAPIKeysRequest.Size(m)
 }
-
-var xxx_messageInfo_UpdateRequest proto.InternalMessageInfo
-
-func (m *UpdateRequest) GetId() string {
-	if m != nil {
-		return m.Id
-	}
-	return ""
+func (m *ListAPIKeysRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListAPIKeysRequest.DiscardUnknown(m)
 }
 
-func (m *UpdateRequest) GetTodo() *ModelTodoReq {
-	if m != nil {
-		return m.Todo
-	}
-	return nil
-}
+var xxx_messageInfo_ListAPIKeysRequest proto.InternalMessageInfo
 
-type UpdateResponse struct {
-	Res                  *ModelTodoRes `protobuf:"bytes,1,opt,name=res,proto3" json:"res,omitempty"`
-	Err                  string        `protobuf:"bytes,2,opt,name=err,proto3" json:"err,omitempty"`
-	XXX_NoUnkeyedLiteral struct{}      `json:"-"`
-	XXX_unrecognized     []byte        `json:"-"`
-	XXX_sizecache        int32         `json:"-"`
+type ListAPIKeysResponse struct {
+	Res                  []*ModelAPIKey `protobuf:"bytes,1,rep,name=res,proto3" json:"res,omitempty"`
+	Err                  string         `protobuf:"bytes,2,opt,name=err,proto3" json:"err,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}       `json:"-"`
+	XXX_unrecognized     []byte         `json:"-"`
+	XXX_sizecache        int32          `json:"-"`
 }
 
-func (m *UpdateResponse) Reset()         { *m = UpdateResponse{} }
-func (m *UpdateResponse) String() string { return proto.CompactTextString(m) }
-func (*UpdateResponse) ProtoMessage()    {}
-func (*UpdateResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_0e4b95d0c4e09639, []int{7}
+func (m *ListAPIKeysResponse) Reset()         { *m = ListAPIKeysResponse{} }
+func (m *ListAPIKeysResponse) String() string { return proto.CompactTextString(m) }
+func (*ListAPIKeysResponse) ProtoMessage()    {}
+func (*ListAPIKeysResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0e4b95d0c4e09639, []int{47}
 }
 
-func (m *UpdateResponse) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_UpdateResponse.Unmarshal(m, b)
+func (m *ListAPIKeysResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListAPIKeysResponse.Unmarshal(m, b)
 }
-func (m *UpdateResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_UpdateResponse.Marshal(b, m, deterministic)
+func (m *ListAPIKeysResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListAPIKeysResponse.Marshal(b, m, deterministic)
 }
-func (m *UpdateResponse) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_UpdateResponse.Merge(m, src)
+func (m *ListAPIKeysResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListAPIKeysResponse.Merge(m, src)
 }
-func (m *UpdateResponse) XXX_Size() int {
-	return xxx_messageInfo_UpdateResponse.Size(m)
+func (m *ListAPIKeysResponse) XXX_Size() int {
+	return xxx_messageInfo_ListAPIKeysResponse.Size(m)
 }
-func (m *UpdateResponse) XXX_DiscardUnknown() {
-	xxx_messageInfo_UpdateResponse.DiscardUnknown(m)
+func (m *ListAPIKeysResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListAPIKeysResponse.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_UpdateResponse proto.InternalMessageInfo
+var xxx_messageInfo_ListAPIKeysResponse proto.InternalMessageInfo
 
-func (m *UpdateResponse) GetRes() *ModelTodoRes {
+func (m *ListAPIKeysResponse) GetRes() []*ModelAPIKey {
 	if m != nil {
 		return m.Res
 	}
 	return nil
 }
 
-func (m *UpdateResponse) GetErr() string {
+func (m *ListAPIKeysResponse) GetErr() string {
 	if m != nil {
 		return m.Err
 	}
 	return ""
 }
 
-type ListRequest struct {
+type UsageRequest struct {
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
 }
 
-func (m *ListRequest) Reset()         { *m = ListRequest{} }
-func (m *ListRequest) String() string { return proto.CompactTextString(m) }
-func (*ListRequest) ProtoMessage()    {}
-func (*ListRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_0e4b95d0c4e09639, []int{8}
+func (m *UsageRequest) Reset()         { *m = UsageRequest{} }
+func (m *UsageRequest) String() string { return proto.CompactTextString(m) }
+func (*UsageRequest) ProtoMessage()    {}
+func (*UsageRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0e4b95d0c4e09639, []int{48}
 }
 
-func (m *ListRequest) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_ListRequest.Unmarshal(m, b)
+func (m *UsageRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_UsageRequest.Unmarshal(m, b)
 }
-func (m *ListRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_ListRequest.Marshal(b, m, deterministic)
+func (m *UsageRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_UsageRequest.Marshal(b, m, deterministic)
 }
-func (m *ListRequest) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_ListRequest.Merge(m, src)
+func (m *UsageRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_UsageRequest.Merge(m, src)
 }
-func (m *ListRequest) XXX_Size() int {
-	return xxx_messageInfo_ListRequest.Size(m)
+func (m *UsageRequest) XXX_Size() int {
+	return xxx_messageInfo_UsageRequest.Size(m)
 }
-func (m *ListRequest) XXX_DiscardUnknown() {
-	xxx_messageInfo_ListRequest.DiscardUnknown(m)
+func (m *UsageRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_UsageRequest.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_ListRequest proto.InternalMessageInfo
+var xxx_messageInfo_UsageRequest proto.InternalMessageInfo
 
-type ListResponse struct {
-	Res                  []*ModelTodoRes `protobuf:"bytes,1,rep,name=res,proto3" json:"res,omitempty"`
-	Err                  string          `protobuf:"bytes,2,opt,name=err,proto3" json:"err,omitempty"`
-	XXX_NoUnkeyedLiteral struct{}        `json:"-"`
-	XXX_unrecognized     []byte          `json:"-"`
-	XXX_sizecache        int32           `json:"-"`
+type UsageResponse struct {
+	Res                  *ModelUsage `protobuf:"bytes,1,opt,name=res,proto3" json:"res,omitempty"`
+	Err                  string      `protobuf:"bytes,2,opt,name=err,proto3" json:"err,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}    `json:"-"`
+	XXX_unrecognized     []byte      `json:"-"`
+	XXX_sizecache        int32       `json:"-"`
 }
 
-func (m *ListResponse) Reset()         { *m = ListResponse{} }
-func (m *ListResponse) String() string { return proto.CompactTextString(m) }
-func (*ListResponse) ProtoMessage()    {}
-func (*ListResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_0e4b95d0c4e09639, []int{9}
+func (m *UsageResponse) Reset()         { *m = UsageResponse{} }
+func (m *UsageResponse) String() string { return proto.CompactTextString(m) }
+func (*UsageResponse) ProtoMessage()    {}
+func (*UsageResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0e4b95d0c4e09639, []int{49}
 }
 
-func (m *ListResponse) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_ListResponse.Unmarshal(m, b)
+func (m *UsageResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_UsageResponse.Unmarshal(m, b)
 }
-func (m *ListResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_ListResponse.Marshal(b, m, deterministic)
+func (m *UsageResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_UsageResponse.Marshal(b, m, deterministic)
 }
-func (m *ListResponse) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_ListResponse.Merge(m, src)
+func (m *UsageResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_UsageResponse.Merge(m, src)
 }
-func (m *ListResponse) XXX_Size() int {
-	return xxx_messageInfo_ListResponse.Size(m)
+func (m *UsageResponse) XXX_Size() int {
+	return xxx_messageInfo_UsageResponse.Size(m)
 }
-func (m *ListResponse) XXX_DiscardUnknown() {
-	xxx_messageInfo_ListResponse.DiscardUnknown(m)
+func (m *UsageResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_UsageResponse.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_ListResponse proto.InternalMessageInfo
+var xxx_messageInfo_UsageResponse proto.InternalMessageInfo
 
-func (m *ListResponse) GetRes() []*ModelTodoRes {
+func (m *UsageResponse) GetRes() *ModelUsage {
 	if m != nil {
 		return m.Res
 	}
 	return nil
 }
 
-func (m *ListResponse) GetErr() string {
+func (m *UsageResponse) GetErr() string {
 	if m != nil {
 		return m.Err
 	}
@@ -481,6 +2613,18 @@ func (m *ListResponse) GetErr() string {
 func init() {
 	proto.RegisterType((*ModelTodoReq)(nil), "pb.ModelTodoReq")
 	proto.RegisterType((*ModelTodoRes)(nil), "pb.ModelTodoRes")
+	proto.RegisterType((*ModelDayCount)(nil), "pb.ModelDayCount")
+	proto.RegisterType((*ModelTodoStats)(nil), "pb.ModelTodoStats")
+	proto.RegisterType((*ModelQuota)(nil), "pb.ModelQuota")
+	proto.RegisterType((*ModelUsage)(nil), "pb.ModelUsage")
+	proto.RegisterType((*ModelTombstone)(nil), "pb.ModelTombstone")
+	proto.RegisterType((*ModelChanges)(nil), "pb.ModelChanges")
+	proto.RegisterType((*ModelShareReq)(nil), "pb.ModelShareReq")
+	proto.RegisterType((*ModelShare)(nil), "pb.ModelShare")
+	proto.RegisterType((*ModelAPIKeyReq)(nil), "pb.ModelAPIKeyReq")
+	proto.RegisterType((*ModelAPIKey)(nil), "pb.ModelAPIKey")
+	proto.RegisterType((*ModelMutation)(nil), "pb.ModelMutation")
+	proto.RegisterType((*ModelMutationResult)(nil), "pb.ModelMutationResult")
 	proto.RegisterType((*AddRequest)(nil), "pb.AddRequest")
 	proto.RegisterType((*AddResponse)(nil), "pb.AddResponse")
 	proto.RegisterType((*DeleteRequest)(nil), "pb.DeleteRequest")
@@ -489,39 +2633,169 @@ func init() {
 	proto.RegisterType((*UpdateResponse)(nil), "pb.UpdateResponse")
 	proto.RegisterType((*ListRequest)(nil), "pb.ListRequest")
 	proto.RegisterType((*ListResponse)(nil), "pb.ListResponse")
+	proto.RegisterType((*GetRequest)(nil), "pb.GetRequest")
+	proto.RegisterType((*GetResponse)(nil), "pb.GetResponse")
+	proto.RegisterType((*StatsRequest)(nil), "pb.StatsRequest")
+	proto.RegisterType((*StatsResponse)(nil), "pb.StatsResponse")
+	proto.RegisterType((*UnarchiveRequest)(nil), "pb.UnarchiveRequest")
+	proto.RegisterType((*UnarchiveResponse)(nil), "pb.UnarchiveResponse")
+	proto.RegisterType((*SnoozeRequest)(nil), "pb.SnoozeRequest")
+	proto.RegisterType((*SnoozeResponse)(nil), "pb.SnoozeResponse")
+	proto.RegisterType((*WatchRequest)(nil), "pb.WatchRequest")
+	proto.RegisterType((*TodoEvent)(nil), "pb.TodoEvent")
+	proto.RegisterType((*SyncRequest)(nil), "pb.SyncRequest")
+	proto.RegisterType((*SyncResponse)(nil), "pb.SyncResponse")
+	proto.RegisterType((*PushRequest)(nil), "pb.PushRequest")
+	proto.RegisterType((*PushResponse)(nil), "pb.PushResponse")
+	proto.RegisterType((*AddShareRequest)(nil), "pb.AddShareRequest")
+	proto.RegisterType((*AddShareResponse)(nil), "pb.AddShareResponse")
+	proto.RegisterType((*DeleteShareRequest)(nil), "pb.DeleteShareRequest")
+	proto.RegisterType((*DeleteShareResponse)(nil), "pb.DeleteShareResponse")
+	proto.RegisterType((*ListSharesRequest)(nil), "pb.ListSharesRequest")
+	proto.RegisterType((*ListSharesResponse)(nil), "pb.ListSharesResponse")
+	proto.RegisterType((*CreateAPIKeyRequest)(nil), "pb.CreateAPIKeyRequest")
+	proto.RegisterType((*CreateAPIKeyResponse)(nil), "pb.CreateAPIKeyResponse")
+	proto.RegisterType((*RevokeAPIKeyRequest)(nil), "pb.RevokeAPIKeyRequest")
+	proto.RegisterType((*RevokeAPIKeyResponse)(nil), "pb.RevokeAPIKeyResponse")
+	proto.RegisterType((*ListAPIKeysRequest)(nil), "pb.ListAPIKeysRequest")
+	proto.RegisterType((*ListAPIKeysResponse)(nil), "pb.ListAPIKeysResponse")
+	proto.RegisterType((*UsageRequest)(nil), "pb.UsageRequest")
+	proto.RegisterType((*UsageResponse)(nil), "pb.UsageResponse")
 }
 
-func init() { proto.RegisterFile("todo.proto", fileDescriptor_0e4b95d0c4e09639) }
+func init() {
+	proto.RegisterFile("todo.proto", fileDescriptor_0e4b95d0c4e09639)
+}
 
 var fileDescriptor_0e4b95d0c4e09639 = []byte{
-	// 349 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xa4, 0x53, 0x4d, 0x4b, 0x03, 0x31,
-	0x14, 0x64, 0x3f, 0x2c, 0xee, 0x6b, 0xbb, 0xd6, 0x77, 0x2a, 0x45, 0xb1, 0x04, 0x91, 0x7a, 0xa9,
-	0x50, 0xff, 0x80, 0x8b, 0xd5, 0x93, 0x5e, 0x82, 0x9e, 0xa5, 0x6d, 0xde, 0xa1, 0x50, 0x4d, 0xba,
-	0x49, 0xc1, 0x5f, 0xe1, 0x1f, 0xf2, 0xcf, 0x49, 0x3e, 0xb6, 0xdb, 0x2e, 0x54, 0x10, 0x6f, 0xc9,
-	0x9b, 0xcc, 0xbc, 0x99, 0x59, 0x16, 0xc0, 0x48, 0x21, 0xc7, 0xaa, 0x94, 0x46, 0x62, 0xac, 0xe6,
-	0xec, 0x0e, 0x3a, 0xcf, 0x52, 0xd0, 0xea, 0x45, 0x0a, 0xc9, 0x69, 0x8d, 0x08, 0xa9, 0xa1, 0x4f,
-	0xd3, 0x8f, 0x86, 0xd1, 0x28, 0xe3, 0xee, 0x8c, 0x67, 0x90, 0x2d, 0xe4, 0xbb, 0x5a, 0x91, 0x21,
-	0xd1, 0x8f, 0x87, 0xd1, 0xe8, 0x98, 0xd7, 0x03, 0xf6, 0x15, 0xed, 0x49, 0x68, 0xcc, 0x21, 0x5e,
-	0x8a, 0x20, 0x10, 0x2f, 0x05, 0x9e, 0x03, 0x2c, 0x4a, 0x9a, 0x19, 0x12, 0x6f, 0x33, 0xe3, 0xf8,
-	0x19, 0xcf, 0xc2, 0xa4, 0x30, 0x16, 0xde, 0x28, 0x51, 0xc1, 0x89, 0x87, 0xc3, 0xa4, 0x30, 0x5b,
-	0x43, 0xe9, 0x21, 0x43, 0x47, 0x4d, 0x43, 0x13, 0x80, 0x42, 0x08, 0x4e, 0xeb, 0x0d, 0x69, 0x83,
-	0x97, 0x90, 0xda, 0xc8, 0xce, 0x4f, 0x7b, 0xd2, 0x1b, 0xab, 0xf9, 0x78, 0x37, 0x30, 0x77, 0x28,
-	0xbb, 0x87, 0xb6, 0xe3, 0x68, 0x25, 0x3f, 0x34, 0x21, 0x83, 0xa4, 0x24, 0x7d, 0x80, 0xa3, 0xb9,
-	0x05, 0xb1, 0x07, 0x09, 0x95, 0x65, 0xc8, 0x63, 0x8f, 0xec, 0x02, 0xba, 0x53, 0xb2, 0x1e, 0xaa,
-	0xdd, 0x8d, 0x26, 0x18, 0x83, 0xbc, 0x7a, 0x10, 0x16, 0x05, 0x91, 0xa8, 0x16, 0x79, 0x80, 0xee,
-	0xab, 0x0b, 0x7f, 0x40, 0x64, 0x1b, 0x28, 0xfe, 0x35, 0xd0, 0x23, 0xe4, 0x95, 0xcc, 0xbf, 0x32,
-	0x75, 0xa1, 0xfd, 0xb4, 0xd4, 0x26, 0x98, 0x61, 0x53, 0xe8, 0xf8, 0x6b, 0x53, 0x34, 0xf9, 0x83,
-	0xe8, 0xe4, 0x3b, 0x82, 0xd4, 0x3e, 0xc1, 0x2b, 0x48, 0x0a, 0x21, 0x30, 0xb7, 0xc4, 0xfa, 0x9b,
-	0x0d, 0x4e, 0xb6, 0xf7, 0xb0, 0xe6, 0x06, 0x5a, 0xbe, 0x38, 0x3c, 0xb5, 0xd0, 0x5e, 0xcb, 0x03,
-	0xdc, 0x1d, 0xd5, 0x04, 0x1f, 0xdf, 0x13, 0xf6, 0x1a, 0xf5, 0x84, 0x46, 0x3b, 0xd7, 0x90, 0xda,
-	0x60, 0xe8, 0x56, 0xef, 0x24, 0x1e, 0xf4, 0xea, 0x81, 0x7f, 0x3a, 0x6f, 0xb9, 0xbf, 0xe7, 0xf6,
-	0x27, 0x00, 0x00, 0xff, 0xff, 0xba, 0x03, 0x6b, 0xe2, 0x4b, 0x03, 0x00, 0x00,
+	// 1945 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xac, 0x58, 0x5b, 0x6f, 0x1b, 0x5b,
+	0x15, 0x96, 0x3d, 0xbe, 0x2e, 0x5f, 0xb3, 0xed, 0x26, 0x3e, 0x43, 0xab, 0xe6, 0xcc, 0x29, 0x34,
+	0x27, 0x82, 0x18, 0x05, 0x21, 0x38, 0x69, 0x41, 0x98, 0xe4, 0xb4, 0xe4, 0xb4, 0x45, 0x65, 0x9a,
+	0xa8, 0x42, 0x3c, 0x58, 0x13, 0xcf, 0x4e, 0x32, 0xd8, 0x9e, 0x71, 0x66, 0x6f, 0x87, 0x63, 0x10,
+	0x2f, 0xbc, 0xc3, 0x0b, 0x7f, 0x81, 0xff, 0xc1, 0x33, 0x12, 0x6f, 0xfc, 0x03, 0xc4, 0x0f, 0x41,
+	0x7b, 0xaf, 0x35, 0x57, 0xc7, 0xd1, 0x29, 0xe5, 0x6d, 0xf6, 0xda, 0xeb, 0xf2, 0xad, 0xb5, 0xd7,
+	0xcd, 0x06, 0x90, 0x81, 0x1b, 0x1c, 0x2c, 0xc2, 0x40, 0x06, 0xac, 0xb8, 0xb8, 0x30, 0x1f, 0x5e,
+	0x05, 0xc1, 0xd5, 0x8c, 0x0f, 0x9d, 0x85, 0x37, 0x74, 0x7c, 0x3f, 0x90, 0x8e, 0xf4, 0x02, 0x5f,
+	0x20, 0x87, 0xb9, 0x4b, 0xb7, 0xfa, 0x74, 0xb1, 0xbc, 0x1c, 0x5e, 0x7a, 0x7c, 0xe6, 0x8e, 0xe7,
+	0x8e, 0x98, 0x22, 0x87, 0xf5, 0x33, 0x68, 0xbe, 0x09, 0x5c, 0x3e, 0x3b, 0x0b, 0xdc, 0xc0, 0xe6,
+	0x37, 0x8c, 0x41, 0x49, 0xf2, 0xaf, 0xe5, 0xa0, 0xb0, 0x5b, 0xd8, 0xab, 0xdb, 0xfa, 0x9b, 0x3d,
+	0x84, 0xfa, 0x24, 0x98, 0x2f, 0x66, 0x5c, 0x72, 0x77, 0x50, 0xdc, 0x2d, 0xec, 0xd5, 0xec, 0x84,
+	0x60, 0xfd, 0xa3, 0x98, 0x51, 0x21, 0x58, 0x1b, 0x8a, 0x9e, 0x4b, 0x0a, 0x8a, 0x9e, 0xcb, 0x1e,
+	0x01, 0x4c, 0x42, 0xee, 0x48, 0xee, 0x8e, 0x1d, 0xa9, 0xe5, 0xeb, 0x76, 0x9d, 0x28, 0x23, 0xa9,
+	0xae, 0x97, 0x0b, 0x37, 0xba, 0x36, 0xf0, 0x9a, 0x28, 0x23, 0x19, 0x03, 0x2a, 0x6d, 0x02, 0x54,
+	0xce, 0x01, 0x62, 0x9f, 0x42, 0x33, 0x3e, 0x28, 0x95, 0x15, 0x2d, 0xd9, 0x88, 0x69, 0x23, 0xc9,
+	0x1e, 0x43, 0xc3, 0x09, 0x27, 0xd7, 0xde, 0x2d, 0x72, 0x54, 0x35, 0x07, 0x44, 0xa4, 0x91, 0x64,
+	0x9f, 0x41, 0x4b, 0xf8, 0x41, 0xf0, 0x7b, 0xee, 0x8e, 0x97, 0xbe, 0xf4, 0x66, 0x83, 0x9a, 0x66,
+	0x69, 0x12, 0xf1, 0x5c, 0xd1, 0xd8, 0x00, 0xaa, 0xb7, 0x3c, 0x14, 0x5e, 0xe0, 0x0f, 0xea, 0xbb,
+	0x85, 0xbd, 0x92, 0x1d, 0x1d, 0xd9, 0x27, 0x50, 0x0b, 0x7e, 0xe7, 0xf3, 0x70, 0xec, 0xb9, 0x03,
+	0xd0, 0x92, 0x55, 0x7d, 0x3e, 0x75, 0xd9, 0xb7, 0xa0, 0x2e, 0xb9, 0xef, 0xf8, 0x52, 0xdd, 0x35,
+	0xf4, 0x5d, 0x0d, 0x09, 0xa7, 0xae, 0xf5, 0x23, 0x68, 0xe9, 0x50, 0x9e, 0x38, 0xab, 0xe3, 0x60,
+	0xe9, 0x4b, 0xd6, 0x05, 0xc3, 0x75, 0x56, 0x14, 0x4c, 0xf5, 0xc9, 0xfa, 0x50, 0x9e, 0xa8, 0x2b,
+	0x1d, 0x48, 0xc3, 0xc6, 0x83, 0xf5, 0xcf, 0x02, 0xb4, 0xe3, 0x47, 0x78, 0x27, 0x1d, 0x29, 0x14,
+	0xa3, 0x0c, 0xa4, 0x33, 0xd3, 0xc2, 0x86, 0x8d, 0x07, 0xb6, 0x0d, 0x15, 0x67, 0x22, 0xbd, 0x5b,
+	0x4e, 0xf2, 0x74, 0xca, 0x86, 0xd4, 0xd0, 0x57, 0x09, 0x81, 0xfd, 0x04, 0xb6, 0x92, 0x90, 0x2e,
+	0x78, 0x38, 0x56, 0xa0, 0x4a, 0xbb, 0xc6, 0x5e, 0xe3, 0x70, 0xeb, 0x60, 0x71, 0x71, 0x90, 0x01,
+	0x6d, 0x77, 0x62, 0xde, 0xb7, 0x3c, 0x3c, 0x71, 0x56, 0x6c, 0x08, 0x7d, 0xe7, 0xf6, 0x6a, 0x2c,
+	0xbd, 0x39, 0x1f, 0xcb, 0x60, 0x1c, 0x5d, 0xeb, 0xa7, 0x2b, 0xd8, 0x5b, 0xce, 0xed, 0xd5, 0x99,
+	0x37, 0xe7, 0x67, 0xc1, 0x31, 0x5d, 0x58, 0x97, 0x00, 0x5a, 0xe5, 0xaf, 0x96, 0x81, 0x74, 0xd0,
+	0x13, 0x37, 0x10, 0x89, 0x27, 0x6e, 0x20, 0xd4, 0x33, 0x53, 0x20, 0xf1, 0x12, 0xfd, 0x69, 0x20,
+	0xed, 0x4c, 0xb3, 0x3c, 0x86, 0x86, 0xca, 0x97, 0xf1, 0x8c, 0xfb, 0x57, 0xf2, 0x5a, 0xbb, 0x55,
+	0xb6, 0x41, 0x91, 0x5e, 0x6b, 0x8a, 0x35, 0x25, 0x3b, 0xe7, 0xc2, 0xb9, 0xe2, 0xff, 0xbb, 0x9d,
+	0x27, 0x50, 0xbe, 0x51, 0x48, 0xb5, 0x85, 0xc6, 0x61, 0x3b, 0x0e, 0x89, 0xc6, 0x6f, 0xe3, 0xa5,
+	0xf5, 0xeb, 0xf8, 0x89, 0xe6, 0x17, 0x42, 0x06, 0x3e, 0x5f, 0xab, 0x94, 0x54, 0x42, 0x15, 0xb3,
+	0x09, 0xf5, 0x08, 0xc0, 0xe5, 0x71, 0x46, 0x53, 0x91, 0x10, 0x65, 0x24, 0xad, 0xbf, 0x14, 0xa8,
+	0x06, 0x8f, 0xaf, 0x1d, 0xff, 0x8a, 0x0b, 0xf6, 0x9d, 0xc4, 0x15, 0xf5, 0x48, 0xdd, 0x18, 0x11,
+	0x15, 0x69, 0xe4, 0xdc, 0x77, 0xa1, 0x4a, 0x5a, 0x06, 0x45, 0xcd, 0xc9, 0x52, 0x9c, 0x04, 0xd3,
+	0x8e, 0x58, 0x30, 0x40, 0x53, 0xee, 0x13, 0x00, 0x3c, 0xa8, 0x0a, 0x9d, 0x07, 0x21, 0xd7, 0x15,
+	0x5a, 0xb3, 0xf5, 0xb7, 0xf5, 0x1b, 0x4a, 0xe4, 0x77, 0xd7, 0x4e, 0xc8, 0x55, 0x5f, 0xd9, 0x81,
+	0xaa, 0xb2, 0x38, 0x8e, 0xfd, 0xad, 0xa8, 0xe3, 0xa9, 0xee, 0x0e, 0x57, 0xa1, 0xe3, 0x4b, 0xce,
+	0xd5, 0x1d, 0x75, 0x07, 0xa2, 0x9c, 0xba, 0x4a, 0x79, 0x18, 0xcc, 0x38, 0x59, 0xd4, 0xdf, 0xd6,
+	0xdf, 0x0b, 0x00, 0x89, 0xf6, 0xb5, 0x28, 0xa6, 0x8b, 0xaf, 0x78, 0x4f, 0xf1, 0x19, 0xd9, 0xe2,
+	0x4b, 0x43, 0x2c, 0xdd, 0x03, 0xb1, 0xbc, 0x09, 0x62, 0x25, 0x81, 0x98, 0xeb, 0x79, 0xd5, 0x5c,
+	0xcf, 0xb3, 0x96, 0x94, 0x0a, 0xa3, 0xb7, 0xa7, 0xaf, 0xf8, 0x8a, 0xfa, 0xae, 0xef, 0xcc, 0x79,
+	0xd4, 0x77, 0xd5, 0xb7, 0x4a, 0x07, 0xb1, 0xbc, 0xf8, 0x2d, 0x9f, 0x44, 0x5d, 0x33, 0x3a, 0xaa,
+	0x2a, 0x16, 0x93, 0x60, 0xc1, 0xc5, 0xc0, 0xd8, 0x35, 0x14, 0x52, 0x3c, 0x29, 0xb3, 0xfc, 0xeb,
+	0x85, 0x17, 0x72, 0xa1, 0xcc, 0xa2, 0x17, 0x75, 0xa2, 0x8c, 0xa4, 0xf5, 0xe7, 0x22, 0x34, 0x52,
+	0x76, 0xff, 0x6f, 0x91, 0x8b, 0xc0, 0x97, 0xee, 0x06, 0x5f, 0xde, 0x04, 0xbe, 0x72, 0x0f, 0xf8,
+	0x6a, 0x0e, 0x3c, 0xdb, 0x85, 0xe6, 0xcc, 0x11, 0x72, 0xbc, 0x14, 0x18, 0x54, 0xec, 0xc8, 0xa0,
+	0x68, 0xe7, 0x22, 0x9a, 0x24, 0xa9, 0xa0, 0xd7, 0xf3, 0x83, 0xa6, 0x0b, 0xc6, 0x94, 0xaf, 0xa8,
+	0x1f, 0xab, 0x4f, 0x55, 0x36, 0x98, 0xa6, 0x6f, 0x96, 0x38, 0x37, 0x55, 0x44, 0x82, 0x45, 0x14,
+	0x91, 0x60, 0x41, 0x11, 0x2a, 0xde, 0x55, 0xa1, 0xc6, 0x5a, 0x85, 0xa6, 0xc6, 0x58, 0x29, 0x3f,
+	0xc6, 0x9e, 0x40, 0x49, 0x65, 0x93, 0x8e, 0xc5, 0x7a, 0x3d, 0xde, 0xd8, 0xfa, 0xd6, 0x5a, 0x41,
+	0x2f, 0x83, 0xc7, 0xe6, 0x62, 0x39, 0x93, 0x6b, 0xef, 0xa4, 0x22, 0x28, 0x1d, 0xb9, 0x14, 0x84,
+	0x8c, 0x4e, 0xb1, 0x11, 0xe3, 0x4e, 0x23, 0x02, 0x8d, 0xa8, 0x2a, 0xe6, 0x61, 0x18, 0x84, 0x04,
+	0x12, 0x0f, 0xd6, 0x21, 0xc0, 0xc8, 0x75, 0x6d, 0x7e, 0xb3, 0xe4, 0x22, 0x81, 0x5b, 0xb8, 0x17,
+	0xee, 0x31, 0x34, 0xb4, 0x8c, 0x58, 0x04, 0xbe, 0xe0, 0xcc, 0x02, 0x23, 0xe4, 0x62, 0x83, 0x8c,
+	0xb0, 0xd5, 0xa5, 0x7a, 0x04, 0x1e, 0x86, 0x84, 0x5b, 0x7d, 0x5a, 0x8f, 0xa1, 0x75, 0xa2, 0xfb,
+	0x4b, 0x64, 0x3b, 0xe7, 0xad, 0x65, 0x41, 0x3b, 0x62, 0x20, 0x43, 0xa4, 0xa4, 0x90, 0x28, 0xf9,
+	0x12, 0x5a, 0xe7, 0x3a, 0xd6, 0x1b, 0x94, 0xc4, 0x0e, 0x15, 0xef, 0x75, 0xe8, 0x05, 0xb4, 0x23,
+	0x35, 0x1f, 0xe5, 0xd3, 0xbf, 0x8b, 0xd0, 0x78, 0xed, 0x09, 0x19, 0xa1, 0xf9, 0x02, 0x20, 0xd9,
+	0xbc, 0x48, 0x99, 0x79, 0x80, 0xcb, 0xd9, 0x41, 0xb4, 0x9c, 0x1d, 0xbc, 0x50, 0x2c, 0x6f, 0x1c,
+	0x31, 0xb5, 0xeb, 0x97, 0xd1, 0x27, 0x7b, 0x0a, 0x9d, 0xd4, 0x36, 0x73, 0x29, 0x79, 0x64, 0xa8,
+	0x1d, 0x93, 0x47, 0x8a, 0xca, 0x3e, 0x87, 0x6e, 0xc2, 0x78, 0xc1, 0x2f, 0x55, 0x4b, 0xc6, 0x42,
+	0x4d, 0x14, 0xfc, 0x5c, 0x93, 0x55, 0xbd, 0x8a, 0x20, 0x8c, 0x77, 0x2a, 0xf5, 0xad, 0xc4, 0x3d,
+	0x7f, 0x32, 0x5b, 0xba, 0x7c, 0x1c, 0xed, 0x41, 0xb4, 0x5a, 0x75, 0x88, 0x3e, 0x22, 0x72, 0x2a,
+	0xfd, 0x2a, 0x99, 0xf4, 0xdb, 0x86, 0x4a, 0x70, 0x79, 0x29, 0x38, 0x16, 0x6f, 0xd9, 0xa6, 0x93,
+	0x4a, 0xb8, 0x99, 0x37, 0xf7, 0xb0, 0x64, 0xcb, 0x36, 0x1e, 0xb4, 0x16, 0xd5, 0xbf, 0x5d, 0x5d,
+	0xa9, 0x35, 0x9b, 0x4e, 0xca, 0xe1, 0x08, 0x08, 0x6d, 0x5b, 0xba, 0x64, 0x6b, 0x76, 0x9b, 0xc8,
+	0xef, 0x90, 0x6a, 0x9d, 0x40, 0x13, 0x63, 0x9c, 0x7f, 0x2a, 0xe3, 0x43, 0x9e, 0xea, 0x3d, 0xc0,
+	0x4b, 0x2e, 0x37, 0xa5, 0x4d, 0xf6, 0xe1, 0x8a, 0x1f, 0xf0, 0x70, 0xaa, 0x38, 0x5e, 0xf2, 0x3b,
+	0xd0, 0x7d, 0x50, 0x22, 0x59, 0xd0, 0xd4, 0xdb, 0x5c, 0x84, 0x8f, 0x41, 0xc9, 0x75, 0x56, 0xa8,
+	0xa6, 0x6c, 0xeb, 0x6f, 0xeb, 0x25, 0xb4, 0x88, 0x87, 0x4c, 0x3d, 0x49, 0x9b, 0x62, 0x19, 0x53,
+	0xc8, 0xb8, 0xd1, 0x58, 0xf7, 0xdc, 0xa7, 0xc7, 0xdf, 0x54, 0x8c, 0xa7, 0xb0, 0x95, 0xe2, 0xf9,
+	0x28, 0xdf, 0x7e, 0x08, 0x2d, 0x7c, 0xca, 0x4d, 0xc1, 0xef, 0x43, 0x19, 0x97, 0x6f, 0x14, 0xc2,
+	0x83, 0xaa, 0xd1, 0x48, 0xec, 0xa3, 0xcc, 0x1f, 0x41, 0xf3, 0xbd, 0x23, 0x27, 0xd7, 0x91, 0x75,
+	0xb5, 0xdc, 0xac, 0x16, 0x94, 0x40, 0x75, 0x1b, 0x0f, 0x8a, 0x2a, 0x3c, 0x7f, 0xc2, 0x69, 0x21,
+	0xc3, 0x83, 0x35, 0x85, 0xba, 0xd2, 0xfe, 0xe5, 0x2d, 0xc7, 0x1d, 0x5d, 0xf0, 0x1b, 0x6d, 0xbe,
+	0x64, 0xab, 0x4f, 0xfd, 0x9b, 0x65, 0xb5, 0xe0, 0x64, 0x4d, 0x7f, 0x7f, 0xc3, 0xde, 0xac, 0x24,
+	0xbd, 0x64, 0x92, 0xaa, 0x6f, 0xeb, 0x33, 0x68, 0xbc, 0x5b, 0xf9, 0x93, 0x14, 0x4e, 0x44, 0x84,
+	0x81, 0x22, 0x44, 0x27, 0xd0, 0x44, 0xa6, 0xfb, 0x63, 0x42, 0xfb, 0xe1, 0xa6, 0x98, 0xfc, 0x14,
+	0x1a, 0x6f, 0x97, 0x22, 0x0e, 0xc9, 0x10, 0xea, 0x73, 0x9a, 0x44, 0x51, 0x5d, 0x25, 0xeb, 0x7e,
+	0x3c, 0xa3, 0x12, 0x1e, 0xeb, 0x15, 0x34, 0x51, 0x9e, 0x50, 0x7c, 0x9e, 0x2e, 0xc9, 0x9d, 0x75,
+	0x51, 0x3d, 0xde, 0x36, 0x3f, 0x50, 0x67, 0xe4, 0xba, 0xd1, 0x06, 0xa9, 0x01, 0x3d, 0x85, 0xb2,
+	0xee, 0x12, 0xe4, 0x57, 0x02, 0x26, 0xe2, 0xb2, 0xf1, 0xde, 0x7a, 0x01, 0xdd, 0x44, 0x96, 0xc0,
+	0xec, 0xa6, 0x43, 0xd2, 0xce, 0x89, 0x6e, 0xc0, 0xf0, 0x04, 0x18, 0xce, 0x9e, 0x0c, 0x8c, 0x7c,
+	0x51, 0x3c, 0x85, 0x5e, 0x86, 0x6b, 0xe3, 0x98, 0xea, 0xc1, 0x96, 0x6a, 0x59, 0x9a, 0x2d, 0xaa,
+	0x69, 0xeb, 0x17, 0xc0, 0xd2, 0xc4, 0x3c, 0x5a, 0xe3, 0x9b, 0xa3, 0x7d, 0x06, 0xbd, 0x63, 0xbd,
+	0xee, 0xc4, 0x7b, 0x25, 0x0d, 0x73, 0xbd, 0xf8, 0xe4, 0xfb, 0x41, 0xcc, 0x84, 0xcb, 0xd0, 0x2b,
+	0xe8, 0x67, 0x85, 0x09, 0xc8, 0xa7, 0xe9, 0xb0, 0x75, 0xf2, 0xd2, 0x1b, 0x90, 0x7c, 0x1b, 0x7a,
+	0x36, 0xbf, 0x0d, 0xa6, 0x39, 0x24, 0xf9, 0xc0, 0xed, 0x41, 0x3f, 0xcb, 0xb6, 0x31, 0x72, 0x7d,
+	0x0c, 0x12, 0xf2, 0xc5, 0xa1, 0xfb, 0x0a, 0x7a, 0x19, 0x6a, 0x1e, 0xb2, 0xf1, 0x01, 0x90, 0xdb,
+	0xd0, 0xd4, 0x3f, 0x03, 0x23, 0xdd, 0xc7, 0xd0, 0xa2, 0xf3, 0xfd, 0xf9, 0x83, 0x4c, 0x77, 0x2b,
+	0x3d, 0xfc, 0x1b, 0x40, 0x49, 0x55, 0x38, 0x7b, 0x0e, 0xc6, 0xc8, 0x75, 0x99, 0x16, 0x4b, 0xf6,
+	0x2c, 0xb3, 0x13, 0x9f, 0xd1, 0x88, 0xd5, 0xff, 0xd3, 0xbf, 0xfe, 0xf3, 0xd7, 0x62, 0xdb, 0xaa,
+	0x0c, 0x3d, 0xc9, 0xe7, 0xe2, 0x08, 0xdb, 0xc2, 0x09, 0x54, 0x30, 0xc1, 0x98, 0x4e, 0xf9, 0xcc,
+	0xbe, 0x64, 0xb2, 0x34, 0x89, 0xd4, 0xf4, 0xb4, 0x9a, 0xd6, 0x7e, 0x03, 0xd5, 0x0c, 0xff, 0xe0,
+	0xb9, 0x7f, 0x64, 0x5f, 0x41, 0x05, 0xb7, 0x1b, 0xd4, 0x92, 0x59, 0x98, 0x4c, 0x96, 0x26, 0x91,
+	0x96, 0x4f, 0xb4, 0x96, 0xde, 0x61, 0x5a, 0x0b, 0x21, 0xfa, 0x02, 0x4a, 0x2a, 0xf2, 0x4c, 0x3b,
+	0x90, 0x5a, 0x75, 0xcc, 0x6e, 0x42, 0x20, 0x2d, 0x6d, 0xad, 0xa5, 0xc6, 0xc8, 0x25, 0xf6, 0x0c,
+	0x8c, 0x97, 0x5c, 0x62, 0x28, 0x92, 0xd1, 0x6b, 0x76, 0xe2, 0x73, 0xd6, 0x07, 0x96, 0xf1, 0xe1,
+	0x39, 0x94, 0xf1, 0xef, 0x0d, 0x6d, 0x27, 0x3d, 0x1b, 0xcd, 0xad, 0x14, 0x65, 0xcd, 0xb4, 0xd0,
+	0x42, 0xef, 0xa1, 0x1e, 0x4f, 0x2f, 0xd6, 0xd7, 0x1e, 0xe7, 0x06, 0x9e, 0xf9, 0x20, 0x47, 0x25,
+	0x4d, 0x8f, 0xb4, 0xa6, 0x1d, 0xeb, 0x41, 0x0a, 0xcc, 0x70, 0x19, 0xeb, 0xfa, 0x25, 0x54, 0x70,
+	0x28, 0x61, 0x68, 0x33, 0x73, 0xcd, 0x64, 0x69, 0x52, 0x4e, 0x1f, 0x4b, 0xeb, 0xc3, 0x7d, 0xe7,
+	0xa8, 0xb0, 0xcf, 0xf6, 0xa1, 0xac, 0x87, 0x13, 0xba, 0x99, 0x9e, 0x53, 0x66, 0x4b, 0x51, 0xe2,
+	0xe9, 0xf3, 0xfd, 0x02, 0xfb, 0x31, 0x94, 0x54, 0xeb, 0xc7, 0xa7, 0x48, 0x4d, 0x0a, 0xb3, 0x9b,
+	0x10, 0xc8, 0x6a, 0x4b, 0x5b, 0xad, 0xb2, 0xf2, 0x50, 0x28, 0x89, 0x67, 0x50, 0x52, 0xed, 0x1a,
+	0x25, 0x53, 0x8d, 0xdf, 0xec, 0x26, 0x04, 0x92, 0xec, 0x6a, 0x49, 0xb0, 0x50, 0x52, 0x41, 0x7c,
+	0x03, 0xb5, 0xa8, 0xc5, 0xb2, 0x1e, 0xa5, 0x71, 0xba, 0x4b, 0x9a, 0xfd, 0x2c, 0x91, 0x14, 0x6d,
+	0x6b, 0x45, 0x5d, 0xab, 0x3a, 0xd4, 0x9d, 0x5a, 0x1c, 0x61, 0xc7, 0x66, 0x67, 0xd0, 0x48, 0xf5,
+	0x50, 0xb6, 0x9d, 0x24, 0x75, 0x46, 0xe9, 0xce, 0x1a, 0x3d, 0x5b, 0x38, 0xfb, 0x4d, 0xd2, 0x8b,
+	0xe9, 0xf2, 0x1a, 0x20, 0xe9, 0xad, 0xec, 0x41, 0x94, 0x9b, 0x99, 0x06, 0x6c, 0x6e, 0xe7, 0xc9,
+	0xa4, 0xb2, 0xa3, 0x55, 0xd6, 0x59, 0x04, 0x95, 0x8d, 0xa1, 0x99, 0x6e, 0x91, 0x4c, 0x83, 0xb9,
+	0xa3, 0xe3, 0x9a, 0x83, 0xf5, 0x0b, 0xd2, 0xf9, 0x50, 0xeb, 0xdc, 0x3e, 0xd2, 0x2d, 0xb7, 0x33,
+	0x74, 0xdc, 0xb9, 0xe7, 0xab, 0x3f, 0x6f, 0xbf, 0x37, 0xe5, 0x2b, 0x6d, 0x20, 0xdd, 0x0f, 0xd1,
+	0xc0, 0x1d, 0x8d, 0xd4, 0x1c, 0xac, 0x5f, 0x64, 0x0d, 0xec, 0xf7, 0x73, 0xba, 0x31, 0x1e, 0xef,
+	0xf1, 0x77, 0x09, 0xca, 0x08, 0x16, 0x7b, 0x9e, 0xed, 0xab, 0xe6, 0xce, 0x1a, 0x9d, 0xb4, 0xef,
+	0x68, 0xed, 0x5b, 0x6c, 0x0d, 0xf9, 0x73, 0x28, 0xe3, 0x9f, 0x68, 0x3a, 0x75, 0xd2, 0x8d, 0xd4,
+	0xdc, 0x4a, 0x51, 0xd6, 0xea, 0x72, 0xa9, 0xe8, 0x17, 0x15, 0xbd, 0x4a, 0xff, 0xe0, 0xbf, 0x03,
+	0x00, 0xed, 0x91, 0x3f, 0x89, 0xde, 0x16, 0x00, 0x00,
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
 var _ context.Context
-var _ grpc.ClientConn
+var _ *grpc.ClientConn
 
 // This is a compile-time assertion to ensure that this generated file
 // is compatible with the grpc package it is being compiled against.
@@ -535,46 +2809,209 @@ type TodoClient interface {
 	Delete(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*DeleteResponse, error)
 	Update(ctx context.Context, in *UpdateRequest, opts ...grpc.CallOption) (*UpdateResponse, error)
 	List(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (*ListResponse, error)
+	Get(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (*GetResponse, error)
+	Stats(ctx context.Context, in *StatsRequest, opts ...grpc.CallOption) (*StatsResponse, error)
+	Unarchive(ctx context.Context, in *UnarchiveRequest, opts ...grpc.CallOption) (*UnarchiveResponse, error)
+	Snooze(ctx context.Context, in *SnoozeRequest, opts ...grpc.CallOption) (*SnoozeResponse, error)
+	Watch(ctx context.Context, in *WatchRequest, opts ...grpc.CallOption) (Todo_WatchClient, error)
+	Sync(ctx context.Context, in *SyncRequest, opts ...grpc.CallOption) (*SyncResponse, error)
+	Push(ctx context.Context, in *PushRequest, opts ...grpc.CallOption) (*PushResponse, error)
+	AddShare(ctx context.Context, in *AddShareRequest, opts ...grpc.CallOption) (*AddShareResponse, error)
+	DeleteShare(ctx context.Context, in *DeleteShareRequest, opts ...grpc.CallOption) (*DeleteShareResponse, error)
+	ListShares(ctx context.Context, in *ListSharesRequest, opts ...grpc.CallOption) (*ListSharesResponse, error)
+	CreateAPIKey(ctx context.Context, in *CreateAPIKeyRequest, opts ...grpc.CallOption) (*CreateAPIKeyResponse, error)
+	RevokeAPIKey(ctx context.Context, in *RevokeAPIKeyRequest, opts ...grpc.CallOption) (*RevokeAPIKeyResponse, error)
+	ListAPIKeys(ctx context.Context, in *ListAPIKeysRequest, opts ...grpc.CallOption) (*ListAPIKeysResponse, error)
+	Usage(ctx context.Context, in *UsageRequest, opts ...grpc.CallOption) (*UsageResponse, error)
+}
+
+type todoClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewTodoClient(cc *grpc.ClientConn) TodoClient {
+	return &todoClient{cc}
+}
+
+func (c *todoClient) Add(ctx context.Context, in *AddRequest, opts ...grpc.CallOption) (*AddResponse, error) {
+	out := new(AddResponse)
+	err := c.cc.Invoke(ctx, "/pb.Todo/Add", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *todoClient) Delete(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*DeleteResponse, error) {
+	out := new(DeleteResponse)
+	err := c.cc.Invoke(ctx, "/pb.Todo/Delete", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *todoClient) Update(ctx context.Context, in *UpdateRequest, opts ...grpc.CallOption) (*UpdateResponse, error) {
+	out := new(UpdateResponse)
+	err := c.cc.Invoke(ctx, "/pb.Todo/Update", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *todoClient) List(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (*ListResponse, error) {
+	out := new(ListResponse)
+	err := c.cc.Invoke(ctx, "/pb.Todo/List", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *todoClient) Get(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (*GetResponse, error) {
+	out := new(GetResponse)
+	err := c.cc.Invoke(ctx, "/pb.Todo/Get", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *todoClient) Stats(ctx context.Context, in *StatsRequest, opts ...grpc.CallOption) (*StatsResponse, error) {
+	out := new(StatsResponse)
+	err := c.cc.Invoke(ctx, "/pb.Todo/Stats", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *todoClient) Unarchive(ctx context.Context, in *UnarchiveRequest, opts ...grpc.CallOption) (*UnarchiveResponse, error) {
+	out := new(UnarchiveResponse)
+	err := c.cc.Invoke(ctx, "/pb.Todo/Unarchive", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *todoClient) Snooze(ctx context.Context, in *SnoozeRequest, opts ...grpc.CallOption) (*SnoozeResponse, error) {
+	out := new(SnoozeResponse)
+	err := c.cc.Invoke(ctx, "/pb.Todo/Snooze", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *todoClient) Watch(ctx context.Context, in *WatchRequest, opts ...grpc.CallOption) (Todo_WatchClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_Todo_serviceDesc.Streams[0], "/pb.Todo/Watch", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &todoWatchClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Todo_WatchClient interface {
+	Recv() (*TodoEvent, error)
+	grpc.ClientStream
+}
+
+type todoWatchClient struct {
+	grpc.ClientStream
+}
+
+func (x *todoWatchClient) Recv() (*TodoEvent, error) {
+	m := new(TodoEvent)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *todoClient) Sync(ctx context.Context, in *SyncRequest, opts ...grpc.CallOption) (*SyncResponse, error) {
+	out := new(SyncResponse)
+	err := c.cc.Invoke(ctx, "/pb.Todo/Sync", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *todoClient) Push(ctx context.Context, in *PushRequest, opts ...grpc.CallOption) (*PushResponse, error) {
+	out := new(PushResponse)
+	err := c.cc.Invoke(ctx, "/pb.Todo/Push", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
 }
 
-type todoClient struct {
-	cc *grpc.ClientConn
+func (c *todoClient) AddShare(ctx context.Context, in *AddShareRequest, opts ...grpc.CallOption) (*AddShareResponse, error) {
+	out := new(AddShareResponse)
+	err := c.cc.Invoke(ctx, "/pb.Todo/AddShare", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
 }
 
-func NewTodoClient(cc *grpc.ClientConn) TodoClient {
-	return &todoClient{cc}
+func (c *todoClient) DeleteShare(ctx context.Context, in *DeleteShareRequest, opts ...grpc.CallOption) (*DeleteShareResponse, error) {
+	out := new(DeleteShareResponse)
+	err := c.cc.Invoke(ctx, "/pb.Todo/DeleteShare", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
 }
 
-func (c *todoClient) Add(ctx context.Context, in *AddRequest, opts ...grpc.CallOption) (*AddResponse, error) {
-	out := new(AddResponse)
-	err := c.cc.Invoke(ctx, "/pb.Todo/Add", in, out, opts...)
+func (c *todoClient) ListShares(ctx context.Context, in *ListSharesRequest, opts ...grpc.CallOption) (*ListSharesResponse, error) {
+	out := new(ListSharesResponse)
+	err := c.cc.Invoke(ctx, "/pb.Todo/ListShares", in, out, opts...)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-func (c *todoClient) Delete(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*DeleteResponse, error) {
-	out := new(DeleteResponse)
-	err := c.cc.Invoke(ctx, "/pb.Todo/Delete", in, out, opts...)
+func (c *todoClient) CreateAPIKey(ctx context.Context, in *CreateAPIKeyRequest, opts ...grpc.CallOption) (*CreateAPIKeyResponse, error) {
+	out := new(CreateAPIKeyResponse)
+	err := c.cc.Invoke(ctx, "/pb.Todo/CreateAPIKey", in, out, opts...)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-func (c *todoClient) Update(ctx context.Context, in *UpdateRequest, opts ...grpc.CallOption) (*UpdateResponse, error) {
-	out := new(UpdateResponse)
-	err := c.cc.Invoke(ctx, "/pb.Todo/Update", in, out, opts...)
+func (c *todoClient) RevokeAPIKey(ctx context.Context, in *RevokeAPIKeyRequest, opts ...grpc.CallOption) (*RevokeAPIKeyResponse, error) {
+	out := new(RevokeAPIKeyResponse)
+	err := c.cc.Invoke(ctx, "/pb.Todo/RevokeAPIKey", in, out, opts...)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-func (c *todoClient) List(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (*ListResponse, error) {
-	out := new(ListResponse)
-	err := c.cc.Invoke(ctx, "/pb.Todo/List", in, out, opts...)
+func (c *todoClient) ListAPIKeys(ctx context.Context, in *ListAPIKeysRequest, opts ...grpc.CallOption) (*ListAPIKeysResponse, error) {
+	out := new(ListAPIKeysResponse)
+	err := c.cc.Invoke(ctx, "/pb.Todo/ListAPIKeys", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *todoClient) Usage(ctx context.Context, in *UsageRequest, opts ...grpc.CallOption) (*UsageResponse, error) {
+	out := new(UsageResponse)
+	err := c.cc.Invoke(ctx, "/pb.Todo/Usage", in, out, opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -587,6 +3024,20 @@ type TodoServer interface {
 	Delete(context.Context, *DeleteRequest) (*DeleteResponse, error)
 	Update(context.Context, *UpdateRequest) (*UpdateResponse, error)
 	List(context.Context, *ListRequest) (*ListResponse, error)
+	Get(context.Context, *GetRequest) (*GetResponse, error)
+	Stats(context.Context, *StatsRequest) (*StatsResponse, error)
+	Unarchive(context.Context, *UnarchiveRequest) (*UnarchiveResponse, error)
+	Snooze(context.Context, *SnoozeRequest) (*SnoozeResponse, error)
+	Watch(*WatchRequest, Todo_WatchServer) error
+	Sync(context.Context, *SyncRequest) (*SyncResponse, error)
+	Push(context.Context, *PushRequest) (*PushResponse, error)
+	AddShare(context.Context, *AddShareRequest) (*AddShareResponse, error)
+	DeleteShare(context.Context, *DeleteShareRequest) (*DeleteShareResponse, error)
+	ListShares(context.Context, *ListSharesRequest) (*ListSharesResponse, error)
+	CreateAPIKey(context.Context, *CreateAPIKeyRequest) (*CreateAPIKeyResponse, error)
+	RevokeAPIKey(context.Context, *RevokeAPIKeyRequest) (*RevokeAPIKeyResponse, error)
+	ListAPIKeys(context.Context, *ListAPIKeysRequest) (*ListAPIKeysResponse, error)
+	Usage(context.Context, *UsageRequest) (*UsageResponse, error)
 }
 
 // UnimplementedTodoServer can be embedded to have forward compatible implementations.
@@ -605,6 +3056,48 @@ func (*UnimplementedTodoServer) Update(ctx context.Context, req *UpdateRequest)
 func (*UnimplementedTodoServer) List(ctx context.Context, req *ListRequest) (*ListResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method List not implemented")
 }
+func (*UnimplementedTodoServer) Get(ctx context.Context, req *GetRequest) (*GetResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Get not implemented")
+}
+func (*UnimplementedTodoServer) Stats(ctx context.Context, req *StatsRequest) (*StatsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Stats not implemented")
+}
+func (*UnimplementedTodoServer) Unarchive(ctx context.Context, req *UnarchiveRequest) (*UnarchiveResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Unarchive not implemented")
+}
+func (*UnimplementedTodoServer) Snooze(ctx context.Context, req *SnoozeRequest) (*SnoozeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Snooze not implemented")
+}
+func (*UnimplementedTodoServer) Watch(req *WatchRequest, srv Todo_WatchServer) error {
+	return status.Errorf(codes.Unimplemented, "method Watch not implemented")
+}
+func (*UnimplementedTodoServer) Sync(ctx context.Context, req *SyncRequest) (*SyncResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Sync not implemented")
+}
+func (*UnimplementedTodoServer) Push(ctx context.Context, req *PushRequest) (*PushResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Push not implemented")
+}
+func (*UnimplementedTodoServer) AddShare(ctx context.Context, req *AddShareRequest) (*AddShareResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddShare not implemented")
+}
+func (*UnimplementedTodoServer) DeleteShare(ctx context.Context, req *DeleteShareRequest) (*DeleteShareResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteShare not implemented")
+}
+func (*UnimplementedTodoServer) ListShares(ctx context.Context, req *ListSharesRequest) (*ListSharesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListShares not implemented")
+}
+func (*UnimplementedTodoServer) CreateAPIKey(ctx context.Context, req *CreateAPIKeyRequest) (*CreateAPIKeyResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateAPIKey not implemented")
+}
+func (*UnimplementedTodoServer) RevokeAPIKey(ctx context.Context, req *RevokeAPIKeyRequest) (*RevokeAPIKeyResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RevokeAPIKey not implemented")
+}
+func (*UnimplementedTodoServer) ListAPIKeys(ctx context.Context, req *ListAPIKeysRequest) (*ListAPIKeysResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListAPIKeys not implemented")
+}
+func (*UnimplementedTodoServer) Usage(ctx context.Context, req *UsageRequest) (*UsageResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Usage not implemented")
+}
 
 func RegisterTodoServer(s *grpc.Server, srv TodoServer) {
 	s.RegisterService(&_Todo_serviceDesc, srv)
@@ -682,6 +3175,261 @@ func _Todo_List_Handler(srv interface{}, ctx context.Context, dec func(interface
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Todo_Get_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TodoServer).Get(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.Todo/Get",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TodoServer).Get(ctx, req.(*GetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Todo_Stats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TodoServer).Stats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.Todo/Stats",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TodoServer).Stats(ctx, req.(*StatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Todo_Unarchive_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UnarchiveRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TodoServer).Unarchive(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.Todo/Unarchive",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TodoServer).Unarchive(ctx, req.(*UnarchiveRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Todo_Snooze_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SnoozeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TodoServer).Snooze(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.Todo/Snooze",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TodoServer).Snooze(ctx, req.(*SnoozeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Todo_Watch_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(TodoServer).Watch(m, &todoWatchServer{stream})
+}
+
+type Todo_WatchServer interface {
+	Send(*TodoEvent) error
+	grpc.ServerStream
+}
+
+type todoWatchServer struct {
+	grpc.ServerStream
+}
+
+func (x *todoWatchServer) Send(m *TodoEvent) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Todo_Sync_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SyncRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TodoServer).Sync(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.Todo/Sync",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TodoServer).Sync(ctx, req.(*SyncRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Todo_Push_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PushRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TodoServer).Push(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.Todo/Push",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TodoServer).Push(ctx, req.(*PushRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Todo_AddShare_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddShareRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TodoServer).AddShare(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.Todo/AddShare",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TodoServer).AddShare(ctx, req.(*AddShareRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Todo_DeleteShare_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteShareRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TodoServer).DeleteShare(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.Todo/DeleteShare",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TodoServer).DeleteShare(ctx, req.(*DeleteShareRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Todo_ListShares_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListSharesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TodoServer).ListShares(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.Todo/ListShares",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TodoServer).ListShares(ctx, req.(*ListSharesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Todo_CreateAPIKey_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateAPIKeyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TodoServer).CreateAPIKey(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.Todo/CreateAPIKey",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TodoServer).CreateAPIKey(ctx, req.(*CreateAPIKeyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Todo_RevokeAPIKey_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RevokeAPIKeyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TodoServer).RevokeAPIKey(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.Todo/RevokeAPIKey",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TodoServer).RevokeAPIKey(ctx, req.(*RevokeAPIKeyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Todo_ListAPIKeys_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListAPIKeysRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TodoServer).ListAPIKeys(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.Todo/ListAPIKeys",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TodoServer).ListAPIKeys(ctx, req.(*ListAPIKeysRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Todo_Usage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UsageRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TodoServer).Usage(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.Todo/Usage",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TodoServer).Usage(ctx, req.(*UsageRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _Todo_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "pb.Todo",
 	HandlerType: (*TodoServer)(nil),
@@ -702,7 +3450,65 @@ var _Todo_serviceDesc = grpc.ServiceDesc{
 			MethodName: "List",
 			Handler:    _Todo_List_Handler,
 		},
+		{
+			MethodName: "Get",
+			Handler:    _Todo_Get_Handler,
+		},
+		{
+			MethodName: "Stats",
+			Handler:    _Todo_Stats_Handler,
+		},
+		{
+			MethodName: "Unarchive",
+			Handler:    _Todo_Unarchive_Handler,
+		},
+		{
+			MethodName: "Snooze",
+			Handler:    _Todo_Snooze_Handler,
+		},
+		{
+			MethodName: "Sync",
+			Handler:    _Todo_Sync_Handler,
+		},
+		{
+			MethodName: "Push",
+			Handler:    _Todo_Push_Handler,
+		},
+		{
+			MethodName: "AddShare",
+			Handler:    _Todo_AddShare_Handler,
+		},
+		{
+			MethodName: "DeleteShare",
+			Handler:    _Todo_DeleteShare_Handler,
+		},
+		{
+			MethodName: "ListShares",
+			Handler:    _Todo_ListShares_Handler,
+		},
+		{
+			MethodName: "CreateAPIKey",
+			Handler:    _Todo_CreateAPIKey_Handler,
+		},
+		{
+			MethodName: "RevokeAPIKey",
+			Handler:    _Todo_RevokeAPIKey_Handler,
+		},
+		{
+			MethodName: "ListAPIKeys",
+			Handler:    _Todo_ListAPIKeys_Handler,
+		},
+		{
+			MethodName: "Usage",
+			Handler:    _Todo_Usage_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Watch",
+			Handler:       _Todo_Watch_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "todo.proto",
 }
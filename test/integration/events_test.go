@@ -0,0 +1,60 @@
+// +build integration
+
+package integration
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"gotest.tools/assert"
+
+	"github.com/cage1016/gokit-todo/internal/app/todo/model"
+	"github.com/cage1016/gokit-todo/internal/app/todo/postgres"
+	"github.com/cage1016/gokit-todo/internal/app/todo/service"
+)
+
+func Test_Listen_Todo_Events(t *testing.T) {
+	t.Cleanup(func() {
+		if err := Truncate(a.DB); err != nil {
+			t.Errorf("error truncating test database tables: %v", err)
+		}
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	bus := service.NewEventBus(service.DefaultEventBacklog)
+	events, err := bus.Subscribe(ctx, 0)
+	if err != nil {
+		t.Fatalf("error subscribing: %v", err)
+	}
+
+	listener := postgres.NewEventListener(a.DBConfig, bus, a.Repo, log.NewLogfmtLogger(os.Stderr))
+	go listener.Run(ctx)
+	// let the listener connect before writing
+	time.Sleep(time.Second)
+
+	body := strings.NewReader(`{"text":"aa"}`)
+	req, err := http.NewRequest(http.MethodPost, "/items", body)
+	if err != nil {
+		t.Errorf("error creating request: %v", err)
+	}
+
+	w := httptest.NewRecorder()
+	a.handler.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	select {
+	case ev := <-events:
+		assert.Equal(t, model.EventCreated, ev.Type)
+		assert.Equal(t, "aa", ev.Todo.Text)
+	case <-ctx.Done():
+		t.Fatal("no event notified")
+	}
+}
@@ -13,6 +13,7 @@ import (
 	"gorm.io/gorm"
 
 	"github.com/cage1016/gokit-todo/internal/app/todo/endpoints"
+	"github.com/cage1016/gokit-todo/internal/app/todo/model"
 	"github.com/cage1016/gokit-todo/internal/app/todo/postgres"
 	"github.com/cage1016/gokit-todo/internal/app/todo/service"
 	transports "github.com/cage1016/gokit-todo/internal/app/todo/transports/http"
@@ -40,12 +41,14 @@ const (
 var a *Application
 
 type Application struct {
-	DB      *gorm.DB
-	handler http.Handler
+	DB       *gorm.DB
+	DBConfig postgres.Config
+	Repo     model.TodoRepository
+	handler  http.Handler
 }
 
 func Truncate(dbc *gorm.DB) error {
-	stmt := "TRUNCATE TABLE todos"
+	stmt := "TRUNCATE TABLE todos, shares, api_keys"
 
 	if err := dbc.Exec(stmt).Error; err != nil {
 		return errors.Wrap(errors.New("truncate test database tables"), err)
@@ -61,7 +64,7 @@ func TestMain(m *testing.M) {
 func testMain(m *testing.M) int {
 	logger := log.NewLogfmtLogger(os.Stderr)
 
-	db, err := postgres.Connect(postgres.Config{
+	cfg := postgres.Config{
 		Host:        databaseHost,
 		Port:        databasePort,
 		User:        databaseUser,
@@ -71,7 +74,9 @@ func testMain(m *testing.M) int {
 		SSLCert:     "",
 		SSLKey:      "",
 		SSLRootCert: "",
-	})
+		Events:      true,
+	}
+	db, err := postgres.Connect(cfg)
 	if err != nil {
 		logger.Log("err", err)
 		return 1
@@ -85,8 +90,10 @@ func testMain(m *testing.M) int {
 	eps := endpoints.New(svc, logger, tracer, zkt)
 
 	a = &Application{
-		DB:      db,
-		handler: transports.NewHTTPHandler(eps, tracer, zkt, logger),
+		DB:       db,
+		DBConfig: cfg,
+		Repo:     repo,
+		handler:  transports.NewHTTPHandler(eps, tracer, zkt, logger),
 	}
 
 	return m.Run()
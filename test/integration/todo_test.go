@@ -3,13 +3,20 @@
 package integration
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"os"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/go-kit/kit/log"
 	"gotest.tools/assert"
+
+	"github.com/cage1016/gokit-todo/internal/app/todo/model"
+	"github.com/cage1016/gokit-todo/internal/app/todo/service"
 )
 
 func Test_Add_Todo(t *testing.T) {
@@ -30,3 +37,151 @@ func Test_Add_Todo(t *testing.T) {
 
 	assert.Equal(t, http.StatusCreated, w.Code, fmt.Sprintf("status: excpet 200, got %d", w.Code))
 }
+
+func Test_Owner_Isolation(t *testing.T) {
+	t.Cleanup(func() {
+		if err := Truncate(a.DB); err != nil {
+			t.Errorf("error truncating test database tables: %v", err)
+		}
+	})
+
+	ctx := context.Background()
+	alice := model.Owner{ID: "alice", TenantID: "acme"}
+	bob := model.Owner{ID: "bob", TenantID: "acme"}
+
+	todo := &model.Todo{ID: "iKe0KxpurIn0E_6vzUDAr", OwnerID: alice.ID, TenantID: alice.TenantID, Text: "aa"}
+	if err := a.Repo.Add(ctx, todo); err != nil {
+		t.Fatalf("error adding todo: %v", err)
+	}
+
+	todos, err := a.Repo.List(ctx, &model.TodoQuery{Owner: bob})
+	assert.NilError(t, err)
+	assert.Equal(t, 0, len(todos), fmt.Sprintf("list: expect no todo of alice, got %d", len(todos)))
+
+	_, err = a.Repo.Get(ctx, bob, todo.ID)
+	assert.Equal(t, service.ErrNotFound, err, fmt.Sprintf("get: expect not found, got %v", err))
+
+	err = a.Repo.Update(ctx, &model.Todo{ID: todo.ID, OwnerID: bob.ID, TenantID: bob.TenantID, Text: "bb"})
+	assert.Equal(t, service.ErrNotFound, err, fmt.Sprintf("update: expect not found, got %v", err))
+
+	err = a.Repo.Delete(ctx, bob, todo.ID, 0)
+	assert.Equal(t, service.ErrNotFound, err, fmt.Sprintf("delete: expect not found, got %v", err))
+
+	res, err := a.Repo.Get(ctx, alice, todo.ID)
+	assert.NilError(t, err)
+	assert.Equal(t, "aa", res.Text, fmt.Sprintf("get: expect the todo of alice unchanged, got %q", res.Text))
+}
+
+func Test_Share(t *testing.T) {
+	t.Cleanup(func() {
+		if err := Truncate(a.DB); err != nil {
+			t.Errorf("error truncating test database tables: %v", err)
+		}
+	})
+
+	ctx := context.Background()
+	alice := model.Owner{ID: "alice", TenantID: "acme"}
+	bob := model.Owner{ID: "bob", TenantID: "acme"}
+
+	todo := &model.Todo{ID: "iKe0KxpurIn0E_6vzUDAr", OwnerID: alice.ID, TenantID: alice.TenantID, Text: "aa"}
+	if err := a.Repo.Add(ctx, todo); err != nil {
+		t.Fatalf("error adding todo: %v", err)
+	}
+
+	share := &model.Share{ID: "dlyW8C0ypdYd0tiJ0KbcN", OwnerID: alice.ID, TenantID: alice.TenantID, TodoID: todo.ID, GranteeID: bob.ID, Role: model.RoleViewer}
+	assert.NilError(t, a.Repo.AddShare(ctx, share))
+
+	again := &model.Share{ID: "x8Ogcqzw0Oo1DR3TzVDIF", OwnerID: alice.ID, TenantID: alice.TenantID, TodoID: todo.ID, GranteeID: bob.ID, Role: model.RoleEditor}
+	assert.NilError(t, a.Repo.AddShare(ctx, again))
+	assert.Equal(t, share.ID, again.ID, fmt.Sprintf("share again: expect the id %s kept, got %s", share.ID, again.ID))
+
+	grants, err := a.Repo.Grants(ctx, bob, todo.ID)
+	assert.NilError(t, err)
+	assert.Equal(t, 1, len(grants), fmt.Sprintf("grants: expect 1 share, got %d", len(grants)))
+	assert.Equal(t, model.RoleEditor, grants[0].Role, fmt.Sprintf("grants: expect the role changed, got %s", grants[0].Role))
+
+	todos, err := a.Repo.List(ctx, &model.TodoQuery{Owner: bob, Shared: true})
+	assert.NilError(t, err)
+	assert.Equal(t, 1, len(todos), fmt.Sprintf("list: expect the todo of alice, got %d", len(todos)))
+
+	err = a.Repo.DeleteShare(ctx, bob, share.ID)
+	assert.Equal(t, service.ErrNotFound, err, fmt.Sprintf("delete share: expect not found, got %v", err))
+
+	assert.NilError(t, a.Repo.DeleteShare(ctx, alice, share.ID))
+	todos, err = a.Repo.List(ctx, &model.TodoQuery{Owner: bob, Shared: true})
+	assert.NilError(t, err)
+	assert.Equal(t, 0, len(todos), fmt.Sprintf("list: expect no todo once revoked, got %d", len(todos)))
+}
+
+func Test_Archive_Unarchive(t *testing.T) {
+	t.Cleanup(func() {
+		if err := Truncate(a.DB); err != nil {
+			t.Errorf("error truncating test database tables: %v", err)
+		}
+	})
+
+	ctx := context.Background()
+	logger := log.NewLogfmtLogger(os.Stderr)
+	now := time.Now().Add(-72 * time.Hour).Truncate(time.Second)
+	clock := func() time.Time { return now }
+	svc := service.New(a.Repo, logger, service.WithClock(clock))
+	archiver := service.NewArchiver(a.Repo, 24*time.Hour, time.Hour, logger, service.WithArchiverClock(clock))
+
+	text, completed := "aa", true
+	todo, err := svc.Add(ctx, &model.TodoReq{Text: &text, Completed: &completed})
+	assert.NilError(t, err)
+
+	now = now.Add(25 * time.Hour)
+	n, err := archiver.Archive(ctx)
+	assert.NilError(t, err)
+	assert.Equal(t, int64(1), n, fmt.Sprintf("archive: expect the completed todo archived, got %d", n))
+
+	res, err := svc.Unarchive(ctx, todo.ID)
+	assert.NilError(t, err)
+	assert.Assert(t, res.ArchivedAt == nil, "unarchive: expect no archivedAt")
+
+	now = now.Add(time.Hour)
+	n, err = archiver.Archive(ctx)
+	assert.NilError(t, err)
+	assert.Equal(t, int64(0), n, fmt.Sprintf("archive: expect the unarchived todo kept, got %d", n))
+
+	now = now.Add(24 * time.Hour)
+	n, err = archiver.Archive(ctx)
+	assert.NilError(t, err)
+	assert.Equal(t, int64(1), n, fmt.Sprintf("archive: expect the todo archived a window after it was unarchived, got %d", n))
+}
+
+func Test_Changes_Commit_Order(t *testing.T) {
+	t.Cleanup(func() {
+		if err := Truncate(a.DB); err != nil {
+			t.Errorf("error truncating test database tables: %v", err)
+		}
+	})
+
+	ctx := context.Background()
+	alice := model.Owner{ID: "alice", TenantID: "acme"}
+	bob := model.Owner{ID: "bob", TenantID: "acme"}
+
+	// the first todo is written first but committed last
+	tx := a.DB.Begin()
+	if err := tx.Create(&model.Todo{ID: "iKe0KxpurIn0E_6vzUDAr", OwnerID: alice.ID, TenantID: alice.TenantID, Text: "aa"}).Error; err != nil {
+		tx.Rollback()
+		t.Fatalf("error adding todo: %v", err)
+	}
+	if err := a.Repo.Add(ctx, &model.Todo{ID: "zIYPEK0zEpUc7CoQWIGB2", OwnerID: alice.ID, TenantID: alice.TenantID, Text: "bb"}); err != nil {
+		tx.Rollback()
+		t.Fatalf("error adding todo: %v", err)
+	}
+
+	todos, _, err := a.Repo.Changes(ctx, alice, model.ChangeCursor{}, 10)
+	assert.NilError(t, err)
+	assert.Equal(t, 0, len(todos), fmt.Sprintf("changes: expect none while the first todo is written, got %d", len(todos)))
+
+	assert.NilError(t, tx.Commit().Error)
+	todos, _, err = a.Repo.Changes(ctx, alice, model.ChangeCursor{}, 10)
+	assert.NilError(t, err)
+	assert.Equal(t, 2, len(todos), fmt.Sprintf("changes: expect both todos once committed, got %d", len(todos)))
+
+	err = a.Repo.Add(ctx, &model.Todo{ID: "zIYPEK0zEpUc7CoQWIGB2", OwnerID: bob.ID, TenantID: bob.TenantID, Text: "cc"})
+	assert.Equal(t, service.ErrConflict, err, fmt.Sprintf("add: expect the id of alice to be taken, got %v", err))
+}
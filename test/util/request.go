@@ -12,6 +12,7 @@ type TestRequest struct {
 	URL         string
 	ContentType string
 	Token       string
+	Header      http.Header
 	Body        io.Reader
 }
 
@@ -23,6 +24,9 @@ func (tr TestRequest) Make() (*http.Response, error) {
 	if tr.Token != "" {
 		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", tr.Token))
 	}
+	for k, v := range tr.Header {
+		req.Header[k] = v
+	}
 	if tr.ContentType != "" {
 		req.Header.Set("Content-Type", tr.ContentType)
 	}